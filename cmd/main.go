@@ -13,21 +13,67 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	"ena/internal/app"
 	"ena/internal/core"
+	"ena/internal/daemon"
+	"ena/internal/settings"
 	"ena/pkg/commands"
 )
 
 func main() {
+	// Move state older versions left in the home directory before managers load it
+	if migrates(os.Args[1:]) {
+		if result, err := settings.Migrate(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ Failed to migrate old state files: %v\n", err)
+		} else {
+			for _, move := range result.Moved {
+				fmt.Fprintf(os.Stderr, "📦 Moved %s to %s\n", move.From, move.To)
+			}
+		}
+	}
+
+	// Build every manager once from config.yaml so all commands share them
+	services := app.New(settings.Get())
+	if err := services.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ %v\n", err)
+	}
+
 	// Initialize Ena's core engine - the heart of our virtual assistant
-	Assistant := core.NewAssistant()
+	Assistant := core.NewAssistant(services)
+
+	// Forward commands to a running daemon so long-lived managers are shared
+	if client := daemon.Connect(); client != nil {
+		Assistant.Forwarder = client
+	}
 
 	// Set up the command-line interface for user interaction
-	RootCmd := commands.SetupRootCommand(Assistant)
+	RootCmd := commands.SetupRootCommand(services, Assistant)
 
-	// Execute the root command with proper error handling
-	if err := RootCmd.Execute(); err != nil {
-		os.Exit(1)
+	// Execute the root command; failures and --output errors set the exit code
+	code := commands.Execute(RootCmd)
+	if err := services.Stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ %v\n", err)
+	}
+	os.Exit(code)
+}
+
+// migrates reports whether a command line may move old state files; shell
+// completion and help only read
+func migrates(args []string) bool {
+	if len(args) > 0 && (strings.HasPrefix(args[0], "__complete") || args[0] == "completion" || args[0] == "help") {
+		return false
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "-h" || arg == "--help" {
+			return false
+		}
 	}
+	return true
 }
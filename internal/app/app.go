@@ -0,0 +1,225 @@
+/**
+ * Service Container
+ *
+ * Builds every long-lived manager from one Config, wiring their dependencies
+ * explicitly, and owns their background work through Start and Stop. Each
+ * App has its own managers, but several in one process are not isolated:
+ * they share the XDG directories, the policy loaded by policy.Get, the config
+ * loaded by settings.Get, the audit log from audit.Default and the command it
+ * attributes entries to, and the per-command output state of pkg/commands.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: app.go
+ * Description: Injectable service registry with lifecycle hooks
+ */
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"ena/internal/appdetect"
+	"ena/internal/backup"
+	"ena/internal/batch"
+	"ena/internal/diskusage"
+	"ena/internal/dupes"
+	"ena/internal/events"
+	"ena/internal/notifications"
+	"ena/internal/organizer"
+	"ena/internal/output"
+	"ena/internal/patterns"
+	"ena/internal/policy"
+	"ena/internal/schedule"
+	"ena/internal/settings"
+	"ena/internal/sinks"
+	"ena/internal/suggestions"
+	"ena/internal/theme"
+	"ena/internal/trash"
+	"ena/internal/undo"
+	"ena/internal/vfs"
+	"ena/pkg/system"
+)
+
+// Hook is a named lifecycle step
+type Hook struct {
+	Name string
+	Run  func() error
+}
+
+// App holds one instance of every manager
+type App struct {
+	Config        *settings.Config
+	FS            vfs.FS
+	Trash         *trash.Can
+	Events        *events.Bus
+	Sinks         *sinks.Manager
+	Policy        *policy.Engine
+	Analytics     *suggestions.UsageAnalytics
+	Themes        *theme.ThemeManager
+	Notifications *notifications.NotificationManager
+	Batch         *batch.BatchManager
+	Undo          *undo.UndoManager
+	Organizer     *organizer.FileOrganizer
+	Patterns      *patterns.PatternEngine
+	Backups       *backup.BackupEngine
+	Dupes         *dupes.Finder
+	DiskUsage     *diskusage.Analyzer
+	AppScanner    *appdetect.AppScanner
+	Scheduler     *schedule.Scheduler
+	Files         *system.FileManager
+	Terminal      *system.TerminalManager
+	Apps          *system.AppManager
+
+	mutex   sync.Mutex
+	onStart []Hook
+	onStop  []Hook
+	started bool
+	stopped bool
+}
+
+// New constructs every manager from config, working on the real filesystem
+func New(config *settings.Config) *App {
+	return NewWithFS(config, vfs.OS{})
+}
+
+// NewWithFS constructs every manager from config, with all file access going
+// through fs. Deletes, moves and overwrites by the file, batch, undo, organizer,
+// pattern, duplicate and disk usage managers are checked first against the
+// policy from policy.Get, which every App in the process shares, and what they
+// delete, undo aside, goes to the trash unless a permanent delete is asked
+// for. Every manager publishes its events on one bus feeding the configured
+// sinks and desktop notifications. Work that needs an explicit start, such as
+// notification cleanup, waits for Start.
+func NewWithFS(config *settings.Config, fs vfs.FS) *App {
+	analytics := suggestions.NewUsageAnalytics()
+	rules := policy.NewEngine(policy.Get(), fs)
+	can := trash.NewCan(fs)
+	trashed := trash.Wrap(fs, can)
+
+	notificationManager := notifications.NewNotificationManager()
+	notificationConfig := config.Notifications
+	if notificationConfig.IconPath == "" {
+		// Keep the icon detected for this platform
+		notificationConfig.IconPath = notificationManager.GetConfig().IconPath
+	}
+	notificationManager.SetConfig(&notificationConfig)
+
+	a := &App{
+		Config:        config,
+		FS:            fs,
+		Trash:         can,
+		Events:        events.NewBus(),
+		Sinks:         sinks.NewManager(config.Sinks),
+		Policy:        rules,
+		Analytics:     analytics,
+		Themes:        theme.NewThemeManager(),
+		Notifications: notificationManager,
+		Batch:         batch.NewBatchManagerWithFS(policy.Guard(trashed, rules, "batch"), analytics, config.Batch),
+		Undo:          undo.NewUndoManagerWithFS(policy.Guard(fs, rules, "undo"), analytics, config.Undo),
+		Organizer:     organizer.NewFileOrganizerWithFS(policy.Guard(trashed, rules, "organizer"), analytics),
+		Patterns:      patterns.NewPatternEngineWithFS(policy.Guard(trashed, rules, "pattern"), analytics),
+		Backups:       backup.NewBackupEngineWithFS(fs, analytics, config.Backup),
+		AppScanner:    appdetect.NewAppScanner(analytics),
+		Scheduler:     schedule.NewScheduler(),
+		Files:         system.NewFileManagerWithFS(policy.Guard(trashed, rules, "file")),
+		Terminal:      system.NewTerminalManager(),
+		Apps:          system.NewAppManager(),
+	}
+
+	a.Dupes = dupes.NewFinder(policy.Guard(trashed, rules, "dupes"), can, a.Undo)
+	a.DiskUsage = diskusage.NewAnalyzer(policy.Guard(trashed, rules, "du"), can, a.Undo)
+
+	a.Batch.SetEventBus(a.Events)
+	a.Undo.SetEventBus(a.Events)
+	a.Organizer.SetEventBus(a.Events)
+	a.Patterns.SetEventBus(a.Events)
+	a.Backups.SetEventBus(a.Events)
+	a.AppScanner.SetEventBus(a.Events)
+	if err := a.Sinks.Attach(a.Events); err != nil {
+		output.Fprintf(os.Stderr, "⚠️ %v\n", err)
+	}
+	if err := a.notifyOnEvents(); err != nil {
+		output.Fprintf(os.Stderr, "⚠️ %v\n", err)
+	}
+
+	// Registered first so it closes last, delivering what the others publish while stopping
+	a.OnStop("events", a.Events.Close)
+	a.OnStop("sinks", a.Sinks.Stop)
+
+	a.OnStart("notifications", func() error {
+		a.Notifications.StartCleanupRoutine()
+		return nil
+	})
+	a.OnStop("apps", func() error {
+		a.Apps.StopMonitoring()
+		return nil
+	})
+	a.OnStop("organizer", func() error {
+		// An organizer that was never watching has nothing to stop
+		a.Organizer.StopWatching()
+		return nil
+	})
+	a.OnStop("backups", a.Backups.Shutdown)
+	// Only the daemon starts the scheduler, so one-off commands never fire jobs
+	a.OnStop("scheduler", a.Scheduler.Stop)
+
+	return a
+}
+
+// OnStart registers a hook run by Start, in registration order
+func (a *App) OnStart(name string, run func() error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.onStart = append(a.onStart, Hook{Name: name, Run: run})
+}
+
+// OnStop registers a hook run by Stop, in reverse registration order
+func (a *App) OnStop(name string, run func() error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.onStop = append(a.onStop, Hook{Name: name, Run: run})
+}
+
+// Start runs the start hooks once. If one fails, the hooks already run are
+// not undone; call Stop to release what did start.
+func (a *App) Start() error {
+	a.mutex.Lock()
+	if a.started {
+		a.mutex.Unlock()
+		return nil
+	}
+	a.started = true
+	hooks := append([]Hook{}, a.onStart...)
+	a.mutex.Unlock()
+
+	for _, hook := range hooks {
+		if err := hook.Run(); err != nil {
+			return fmt.Errorf("Failed to start %s: %w", hook.Name, err)
+		}
+	}
+	return nil
+}
+
+// Stop runs every stop hook once, newest first, and reports all failures
+func (a *App) Stop() error {
+	a.mutex.Lock()
+	if a.stopped {
+		a.mutex.Unlock()
+		return nil
+	}
+	a.stopped = true
+	hooks := append([]Hook{}, a.onStop...)
+	a.mutex.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].Run(); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to stop %s: %w", hooks[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
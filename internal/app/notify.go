@@ -0,0 +1,101 @@
+/**
+ * Automatic Notifications
+ *
+ * Turns the bus events that mark finished work into desktop notifications:
+ * batch jobs, execute-all pattern runs, backups, downloads and app scans.
+ * Each kind can be switched off under notifications.automatic in config.yaml.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: notify.go
+ * Description: Desktop notifications for work announced on the event bus
+ */
+
+package app
+
+import (
+	"fmt"
+
+	"ena/internal/appdetect"
+	"ena/internal/backup"
+	"ena/internal/batch"
+	"ena/internal/events"
+	"ena/internal/notifications"
+	"ena/internal/patterns"
+	"ena/internal/progress"
+)
+
+// notifyOnEvents subscribes the notification manager to the bus. It drops
+// events rather than hold up a publisher while a notification is shown.
+func (a *App) notifyOnEvents() error {
+	options := events.DefaultSubscribeOptions()
+	options.Name = "notifications"
+	options.Overflow = events.OverflowDrop
+
+	if _, err := a.Events.SubscribeWithOptions("*", a.notify, options); err != nil {
+		return fmt.Errorf("Failed to attach notifications: %w", err)
+	}
+	return nil
+}
+
+// notify shows a notification for an event that finishes a piece of work
+func (a *App) notify(event events.Event) {
+	n := a.Notifications
+
+	switch event.Topic {
+	case batch.TopicJobCompleted:
+		payload, _ := event.Payload.(batch.BatchEvent)
+		status, _ := payload.Data["status"].(string)
+		succeeded, _ := payload.Data["success_count"].(int)
+		failed, _ := payload.Data["error_count"].(int)
+
+		switch {
+		case status == "cancelled":
+			n.Notify(notifications.CategoryBatch, n.CreateWarningNotification("Batch job cancelled", payload.JobName))
+		case status == "failed" || failed > 0:
+			n.Notify(notifications.CategoryBatch, n.CreateErrorNotification("Batch job failed",
+				fmt.Sprintf("%s: %d succeeded, %d failed", payload.JobName, succeeded, failed)))
+		default:
+			n.Notify(notifications.CategoryBatch, n.CreateTaskNotification("Batch job finished",
+				fmt.Sprintf("%s: %d succeeded", payload.JobName, succeeded)))
+		}
+
+	case patterns.TopicAllCompleted:
+		payload, _ := event.Payload.(patterns.PatternEvent)
+		if ran, _ := payload.Data["operations"].(int); ran == 0 {
+			return
+		}
+		if failed, _ := payload.Data["operations_failed"].(int); failed > 0 {
+			n.Notify(notifications.CategoryPatterns, n.CreateErrorNotification("Pattern operations failed",
+				fmt.Sprintf("%s, %d with errors", event.Message, failed)))
+			return
+		}
+		n.Notify(notifications.CategoryPatterns, n.CreateTaskNotification("Pattern operations finished", event.Message))
+
+	case backup.TopicBackupCreated:
+		// The safety backup taken before a delete belongs to that delete
+		payload, _ := event.Payload.(backup.BackupEvent)
+		tags, _ := payload.Data["tags"].([]string)
+		for _, tag := range tags {
+			if tag == "auto" {
+				return
+			}
+		}
+		n.Notify(notifications.CategoryBackups, n.CreateSuccessNotification("Backup created", event.Message))
+
+	case backup.TopicBackupFailed, backup.TopicBackupCorrupted:
+		n.Notify(notifications.CategoryBackups, n.CreateErrorNotification("Backup failed", event.Message))
+
+	case progress.TopicDownloadCompleted:
+		n.Notify(notifications.CategoryDownloads, n.CreateSuccessNotification("Download finished", event.Message))
+
+	case progress.TopicDownloadFailed:
+		n.Notify(notifications.CategoryDownloads, n.CreateErrorNotification("Download failed", event.Message))
+
+	case appdetect.TopicScanCompleted:
+		n.Notify(notifications.CategoryAppScans, n.CreateInfoNotification("App scan finished", event.Message))
+
+	case appdetect.TopicScanFailed:
+		n.Notify(notifications.CategoryAppScans, n.CreateErrorNotification("App scan failed", event.Message))
+	}
+}
@@ -26,6 +26,9 @@ import (
 	"sync"
 	"time"
 
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/paths"
 	"ena/internal/suggestions"
 )
 
@@ -105,9 +108,8 @@ type AppScanner struct {
 	analytics       *suggestions.UsageAnalytics
 	apps            map[string]*AppInfo
 	mutex           sync.RWMutex
-	configFile      string
 	appsFile        string
-	eventCallbacks  map[string][]AppEventCallback
+	events          *events.Bus
 	scanPaths       []string
 	excludePaths    []string
 	includePatterns []string
@@ -117,16 +119,18 @@ type AppScanner struct {
 	isScanning      bool
 }
 
-// AppEventCallback is a function that gets called on app events
-type AppEventCallback func(event AppEvent)
+// Topics the app scanner publishes; every payload is an AppEvent
+var (
+	TopicScanStarted      = events.Define("apps.scan_started", "An application scan started")
+	TopicScanCompleted    = events.Define("apps.scan_completed", "An application scan finished")
+	TopicScanFailed       = events.Define("apps.scan_failed", "An application scan failed")
+	TopicAppStatusChanged = events.Define("apps.app_status_changed", "A detected application changed status")
+)
 
-// AppEvent represents an event that occurred during app scanning
+// AppEvent is the payload of app scanner events
 type AppEvent struct {
-	Type      string                 `json:"type"`
-	AppID     string                 `json:"app_id,omitempty"`
-	Message   string                 `json:"message"`
-	Data      map[string]interface{} `json:"data,omitempty"`
-	Timestamp time.Time              `json:"timestamp"`
+	AppID string                 `json:"app_id,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
 }
 
 // NewAppScanner creates a new application scanner instance
@@ -134,9 +138,7 @@ func NewAppScanner(analytics *suggestions.UsageAnalytics) *AppScanner {
 	as := &AppScanner{
 		analytics:       analytics,
 		apps:            make(map[string]*AppInfo),
-		configFile:      "app_scanner_config.json",
-		appsFile:        "detected_apps.json",
-		eventCallbacks:  make(map[string][]AppEventCallback),
+		appsFile:        paths.DataFile("detected_apps.json"),
 		platform:        runtime.GOOS,
 		scanPaths:       getDefaultScanPaths(),
 		excludePaths:    getDefaultExcludePaths(),
@@ -175,11 +177,7 @@ func (as *AppScanner) ScanForApps(deepScan bool) (*AppDetectionResult, error) {
 		Apps:            make([]AppInfo, 0),
 	}
 
-	as.triggerEvent(AppEvent{
-		Type:      "scan_started",
-		Message:   "Started application detection scan",
-		Timestamp: time.Now(),
-	})
+	as.publish(TopicScanStarted, "Started application detection scan", AppEvent{})
 
 	// Perform platform-specific scanning
 	var apps []AppInfo
@@ -198,10 +196,8 @@ func (as *AppScanner) ScanForApps(deepScan bool) (*AppDetectionResult, error) {
 
 	if err != nil {
 		result.Errors = append(result.Errors, err.Error())
-		as.triggerEvent(AppEvent{
-			Type:      "scan_failed",
-			Message:   fmt.Sprintf("Scan failed: %v", err),
-			Timestamp: time.Now(),
+		as.publish(TopicScanFailed, fmt.Sprintf("Scan failed: %v", err), AppEvent{
+			Data: map[string]interface{}{"error": err.Error()},
 		})
 		return result, err
 	}
@@ -250,16 +246,13 @@ func (as *AppScanner) ScanForApps(deepScan bool) (*AppDetectionResult, error) {
 	// Save updated app data
 	as.saveApps()
 
-	as.triggerEvent(AppEvent{
-		Type:    "scan_completed",
-		Message: fmt.Sprintf("Scan completed: found %d apps", result.AppsFound),
+	as.publish(TopicScanCompleted, fmt.Sprintf("Scan completed: found %d apps", result.AppsFound), AppEvent{
 		Data: map[string]interface{}{
 			"apps_found":    result.AppsFound,
 			"apps_updated":  result.AppsUpdated,
 			"apps_removed":  result.AppsRemoved,
 			"scan_duration": result.ScanDuration.String(),
 		},
-		Timestamp: time.Now(),
 	})
 
 	return result, nil
@@ -292,7 +285,7 @@ func (as *AppScanner) GetAppByID(appID string) (*AppInfo, error) {
 
 	app, exists := as.apps[appID]
 	if !exists {
-		return nil, fmt.Errorf("app %s not found", appID)
+		return nil, fault.NotFound("app %s not found", appID)
 	}
 
 	return app, nil
@@ -326,22 +319,19 @@ func (as *AppScanner) UpdateAppStatus(appID string, status AppStatus) error {
 
 	app, exists := as.apps[appID]
 	if !exists {
-		return fmt.Errorf("app %s not found", appID)
+		return fault.NotFound("app %s not found", appID)
 	}
 
 	oldStatus := app.Status
 	app.Status = status
 	app.UpdatedAt = time.Now()
 
-	as.triggerEvent(AppEvent{
-		Type:    "app_status_changed",
-		AppID:   appID,
-		Message: fmt.Sprintf("App %s status changed from %s to %s", app.Name, oldStatus, status),
+	as.publish(TopicAppStatusChanged, fmt.Sprintf("App %s status changed from %s to %s", app.Name, oldStatus, status), AppEvent{
+		AppID: appID,
 		Data: map[string]interface{}{
 			"old_status": oldStatus,
 			"new_status": status,
 		},
-		Timestamp: time.Now(),
 	})
 
 	as.saveApps()
@@ -716,16 +706,15 @@ func (as *AppScanner) removeNonExistentApps() []string {
 }
 
 // Event handling
-func (as *AppScanner) triggerEvent(event AppEvent) {
-	callbacks := as.eventCallbacks[event.Type]
-	for _, callback := range callbacks {
-		go callback(event) // Run callbacks asynchronously
-	}
+
+// SetEventBus sets the bus app events are published on
+func (as *AppScanner) SetEventBus(bus *events.Bus) {
+	as.events = bus
 }
 
-// AddEventCallback adds a callback for app events
-func (as *AppScanner) AddEventCallback(eventType string, callback AppEventCallback) {
-	as.eventCallbacks[eventType] = append(as.eventCallbacks[eventType], callback)
+// publish announces an app scanner event on the event bus
+func (as *AppScanner) publish(topic events.Topic, message string, event AppEvent) {
+	as.events.Publish(events.New(topic, message, event))
 }
 
 // Data persistence
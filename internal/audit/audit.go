@@ -0,0 +1,273 @@
+/**
+ * Audit Log
+ *
+ * Append-only record of every action that changes files, processes or the
+ * system, kept as JSON lines in the state directory. Each entry carries the
+ * hash of the one before it, so editing or removing a line breaks the chain
+ * and shows up in "ena audit verify".
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: audit.go
+ * Description: Hash-chained audit log of mutating actions
+ */
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ena/internal/output"
+	"ena/internal/paths"
+)
+
+// FileName is the name of the audit log inside the state directory
+const FileName = "audit.jsonl"
+
+// Outcome tells whether an audited action succeeded
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Entry is one audited action
+type Entry struct {
+	Seq      int64     `json:"seq"`
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command,omitempty"` // the ena command that caused the action
+	Source   string    `json:"source"`            // component that acted, e.g. batch or backup
+	Action   string    `json:"action"`
+	Path     string    `json:"path,omitempty"`
+	Target   string    `json:"target,omitempty"` // destination of moves and copies, restore location
+	Outcome  Outcome   `json:"outcome"`
+	Error    string    `json:"error,omitempty"`
+	User     string    `json:"user,omitempty"`
+	PID      int       `json:"pid"`
+	PrevHash string    `json:"prev_hash"`
+	Hash     string    `json:"hash"`
+}
+
+// Log is an audit log file
+type Log struct {
+	path  string
+	mutex sync.Mutex
+}
+
+var (
+	defaultLog  *Log
+	defaultOnce sync.Once
+
+	command      string
+	commandMutex sync.RWMutex
+
+	warnOnce sync.Once
+)
+
+// NewLog opens the audit log at path; the file is created on first write
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Default returns the audit log in the state directory
+func Default() *Log {
+	defaultOnce.Do(func() {
+		defaultLog = NewLog(paths.StateFile(FileName))
+	})
+	return defaultLog
+}
+
+// Path returns the location of the log file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// SetCommand names the command that following actions are attributed to
+func SetCommand(name string) {
+	commandMutex.Lock()
+	defer commandMutex.Unlock()
+	command = name
+}
+
+// Command returns the command actions are currently attributed to
+func Command() string {
+	commandMutex.RLock()
+	defer commandMutex.RUnlock()
+	return command
+}
+
+// RecordFile records an action on files, making its paths absolute
+func RecordFile(source, action, path, target string, actionErr error) {
+	Record(source, action, absPath(path), absPath(target), actionErr)
+}
+
+// Record appends an action to the default log. A failed action is recorded with
+// its error. Problems writing the log are reported once on stderr and never fail
+// the action itself.
+func Record(source, action, path, target string, actionErr error) {
+	entry := Entry{
+		Source:  source,
+		Action:  action,
+		Path:    path,
+		Target:  target,
+		Outcome: OutcomeSuccess,
+	}
+	if actionErr != nil {
+		entry.Outcome = OutcomeFailure
+		entry.Error = actionErr.Error()
+	}
+
+	if _, err := Default().Append(entry); err != nil {
+		warnOnce.Do(func() {
+			output.Fprintf(os.Stderr, "⚠️ Warning: Failed to write audit log: %v\n", err)
+		})
+	}
+}
+
+// Append chains an entry onto the log and writes it. The file is locked while
+// the previous entry is read, so several Ena processes can share one log.
+func (l *Log) Append(entry Entry) (Entry, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return entry, fmt.Errorf("Failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return entry, fmt.Errorf("Failed to lock audit log: %w", err)
+	}
+	defer unlockFile(file)
+
+	previous, err := lastEntry(file)
+	if err != nil {
+		return entry, err
+	}
+	if previous != nil {
+		entry.Seq = previous.Seq + 1
+		entry.PrevHash = previous.Hash
+	} else {
+		entry.Seq = 1
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.Command == "" {
+		entry.Command = Command()
+	}
+	if entry.User == "" {
+		entry.User = os.Getenv("USER")
+	}
+	entry.PID = os.Getpid()
+	entry.Hash = entryHash(entry)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return entry, fmt.Errorf("Failed to encode audit entry: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return entry, fmt.Errorf("Failed to write audit log: %w", err)
+	}
+	return entry, nil
+}
+
+// Entries reads every entry in the log, oldest first
+func (l *Log) Entries() ([]Entry, error) {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("Failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("Failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// absPath makes a path absolute, leaving empty paths alone
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// entryHash hashes an entry with its Hash field left out
+func entryHash(entry Entry) string {
+	entry.Hash = ""
+	data, _ := json.Marshal(entry)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastEntry reads the final line of an open log, or nil when it is empty
+func lastEntry(file *os.File) (*Entry, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read audit log: %w", err)
+	}
+
+	// Read backwards in growing chunks until a whole last line is in view
+	size := info.Size()
+	for chunk := int64(4096); ; chunk *= 4 {
+		if chunk > size {
+			chunk = size
+		}
+		buf := make([]byte, chunk)
+		if _, err := file.ReadAt(buf, size-chunk); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("Failed to read audit log: %w", err)
+		}
+
+		trimmed := bytes.TrimRight(buf, "\n")
+		start := bytes.LastIndexByte(trimmed, '\n')
+		if start < 0 && chunk < size {
+			continue
+		}
+		line := trimmed[start+1:]
+		if len(bytes.TrimSpace(line)) == 0 {
+			return nil, nil
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("Failed to parse the last audit entry: %w", err)
+		}
+		return &entry, nil
+	}
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newLog builds a log in a temporary directory holding entries for the given paths
+func newLog(t *testing.T, paths ...string) *Log {
+	t.Helper()
+	log := NewLog(filepath.Join(t.TempDir(), FileName))
+	for _, path := range paths {
+		if _, err := log.Append(Entry{Source: "test", Action: "delete", Path: path, Outcome: OutcomeSuccess}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	return log
+}
+
+// readEntries reads a whole log, failing the test if it cannot
+func readEntries(t *testing.T, log *Log) []Entry {
+	t.Helper()
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	return entries
+}
+
+func TestAppendChainsEntries(t *testing.T) {
+	log := newLog(t, "/a", "/b", "/c")
+
+	// A second handle on the same file carries on the chain
+	if _, err := NewLog(log.Path()).Append(Entry{Source: "test", Action: "move", Path: "/d"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	entries := readEntries(t, log)
+	if len(entries) != 4 {
+		t.Fatalf("read %d entries, want 4", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Seq != int64(i+1) {
+			t.Errorf("entry %d has seq %d", i+1, entry.Seq)
+		}
+		if i > 0 && entry.PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d does not link to entry %d", i+1, i)
+		}
+	}
+	if entries[0].PrevHash != "" {
+		t.Errorf("first entry links to %q", entries[0].PrevHash)
+	}
+	if err := Verify(entries); err != nil {
+		t.Errorf("Verify of an intact log: %v", err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]Entry) []Entry
+		line   int // where the chain breaks
+	}{
+		{"edited path", func(e []Entry) []Entry {
+			e[1].Path = "/elsewhere"
+			return e
+		}, 2},
+		{"edited outcome", func(e []Entry) []Entry {
+			e[2].Outcome = OutcomeFailure
+			return e
+		}, 3},
+		{"edited and rehashed", func(e []Entry) []Entry {
+			e[1].Path = "/elsewhere"
+			e[1].Hash = entryHash(e[1])
+			return e
+		}, 3},
+		{"removed entry", func(e []Entry) []Entry {
+			return append(e[:1], e[2:]...)
+		}, 2},
+		{"swapped entries", func(e []Entry) []Entry {
+			e[1], e[2] = e[2], e[1]
+			return e
+		}, 2},
+		{"removed first entry", func(e []Entry) []Entry {
+			return e[1:]
+		}, 1},
+		{"renumbered and rehashed", func(e []Entry) []Entry {
+			e[3].Seq = 9
+			e[3].Hash = entryHash(e[3])
+			return e
+		}, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := test.tamper(readEntries(t, newLog(t, "/a", "/b", "/c", "/d")))
+
+			var chainErr *ChainError
+			if err := Verify(entries); !errors.As(err, &chainErr) {
+				t.Fatalf("Verify = %v, want a ChainError", err)
+			}
+			if chainErr.Line != test.line {
+				t.Errorf("broken at line %d (%s), want line %d", chainErr.Line, chainErr.Reason, test.line)
+			}
+		})
+	}
+}
+
+func TestVerifyDetectsEditedFile(t *testing.T) {
+	log := newLog(t, "/a", "/b")
+
+	data, err := os.ReadFile(log.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), `"path":"/b"`, `"path":"/c"`, 1)
+	if edited == string(data) {
+		t.Fatal("log does not hold the path to edit")
+	}
+	if err := os.WriteFile(log.Path(), []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(readEntries(t, log)); err == nil {
+		t.Error("Verify accepted an edited log file")
+	}
+}
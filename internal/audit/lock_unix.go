@@ -0,0 +1,29 @@
+//go:build !windows
+
+/**
+ * Audit Log Locking
+ *
+ * Locks the audit log with flock so several Ena processes append to it in turn.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: lock_unix.go
+ * Description: Audit log file locking on Unix
+ */
+
+package audit
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on file, waiting for other holders
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+/**
+ * Audit Log Locking
+ *
+ * Locks the audit log with LockFileEx so several Ena processes append to it
+ * in turn.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: lock_windows.go
+ * Description: Audit log file locking on Windows
+ */
+
+package audit
+
+import (
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes an exclusive lock on file, waiting for other holders
+func lockFile(file *os.File) error {
+	return windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+}
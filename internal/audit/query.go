@@ -0,0 +1,150 @@
+/**
+ * Audit Queries
+ *
+ * Filtering of audit entries by time, path, command and outcome, and
+ * verification of the hash chain that makes tampering visible.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: query.go
+ * Description: Audit log filtering and chain verification
+ */
+
+package audit
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ena/internal/fault"
+)
+
+// Filter selects audit entries; empty fields match everything
+type Filter struct {
+	Since   time.Time
+	Until   time.Time
+	Path    string // matches the path or target, or anything beneath it
+	Command string // matches commands starting with it, e.g. "batch" or "backup restore"
+	Source  string
+	Action  string
+	Outcome Outcome
+}
+
+// Match reports whether an entry passes the filter
+func (f Filter) Match(entry Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if f.Path != "" && !underPath(entry.Path, f.Path) && !underPath(entry.Target, f.Path) {
+		return false
+	}
+	if f.Command != "" && !strings.HasPrefix(entry.Command, f.Command) {
+		return false
+	}
+	if f.Source != "" && entry.Source != f.Source {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	if f.Outcome != "" && entry.Outcome != f.Outcome {
+		return false
+	}
+	return true
+}
+
+// Select returns the entries that pass the filter
+func (f Filter) Select(entries []Entry) []Entry {
+	selected := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if f.Match(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+// ChainError describes where the hash chain of a log breaks
+type ChainError struct {
+	Seq    int64  `json:"seq"`
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log broken at line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// Verify checks that every entry is intact and links to the one before it
+func Verify(entries []Entry) error {
+	var previous *Entry
+	for i := range entries {
+		entry := entries[i]
+		broken := func(reason string) error {
+			return &ChainError{Seq: entry.Seq, Line: i + 1, Reason: reason}
+		}
+
+		if entryHash(entry) != entry.Hash {
+			return broken("entry was modified")
+		}
+		if previous == nil {
+			if entry.PrevHash != "" {
+				return broken("first entry links to a missing predecessor")
+			}
+		} else {
+			if entry.PrevHash != previous.Hash {
+				return broken("entry does not link to the previous one")
+			}
+			if entry.Seq != previous.Seq+1 {
+				return broken(fmt.Sprintf("sequence jumps from %d to %d", previous.Seq, entry.Seq))
+			}
+		}
+		previous = &entries[i]
+	}
+	return nil
+}
+
+// ParseTime reads a point in time given as an age (30m, 24h, 7d, 2w), a date
+// (2006-01-02) or an RFC 3339 timestamp
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+
+	if len(value) > 1 {
+		days := map[byte]int{'d': 1, 'w': 7}
+		if perDay, ok := days[value[len(value)-1]]; ok {
+			if count, err := strconv.Atoi(value[:len(value)-1]); err == nil {
+				return now.AddDate(0, 0, -count*perDay), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fault.Invalid("invalid time %q (use an age like 24h or 7d, or a date like 2006-01-02)", value)
+}
+
+// underPath reports whether path is dir or lies beneath it
+func underPath(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
@@ -25,7 +25,12 @@ import (
 	"sync"
 	"time"
 
+	"ena/internal/audit"
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/paths"
 	"ena/internal/suggestions"
+	"ena/internal/vfs"
 )
 
 // BackupType defines the type of backup
@@ -69,18 +74,18 @@ type BackupMetadata struct {
 
 // BackupConfig defines backup configuration
 type BackupConfig struct {
-	Enabled         bool     `json:"enabled"`
-	MaxBackups      int      `json:"max_backups"`
-	RetentionDays   int      `json:"retention_days"`
-	Compression     bool     `json:"compression"`
-	Encryption      bool     `json:"encryption"`
-	BackupDirectory string   `json:"backup_directory"`
-	AutoCleanup     bool     `json:"auto_cleanup"`
-	VerifyChecksums bool     `json:"verify_checksums"`
-	ExcludePatterns []string `json:"exclude_patterns"`
-	IncludePatterns []string `json:"include_patterns"`
-	MaxBackupSize   int64    `json:"max_backup_size"`
-	MinFreeSpace    int64    `json:"min_free_space"`
+	Enabled         bool     `json:"enabled" yaml:"enabled"`
+	MaxBackups      int      `json:"max_backups" yaml:"max_backups"`
+	RetentionDays   int      `json:"retention_days" yaml:"retention_days"`
+	Compression     bool     `json:"compression" yaml:"compression"`
+	Encryption      bool     `json:"encryption" yaml:"encryption"`
+	BackupDirectory string   `json:"backup_directory" yaml:"backup_directory"`
+	AutoCleanup     bool     `json:"auto_cleanup" yaml:"auto_cleanup"`
+	VerifyChecksums bool     `json:"verify_checksums" yaml:"verify_checksums"`
+	ExcludePatterns []string `json:"exclude_patterns" yaml:"exclude_patterns"`
+	IncludePatterns []string `json:"include_patterns" yaml:"include_patterns"`
+	MaxBackupSize   int64    `json:"max_backup_size" yaml:"max_backup_size"`
+	MinFreeSpace    int64    `json:"min_free_space" yaml:"min_free_space"`
 }
 
 // BackupOperation represents a backup operation
@@ -113,61 +118,79 @@ type BackupEngine struct {
 	mutex          sync.RWMutex
 	operations     map[string]*BackupOperation
 	backups        map[string]*BackupMetadata
-	configFile     string
 	operationsFile string
 	backupsFile    string
-	eventCallbacks map[string][]BackupEventCallback
+	events         *events.Bus
 	isRunning      bool
 	stopChan       chan struct{}
 	cleanupTicker  *time.Ticker
-}
-
-// BackupEventCallback is a function that gets called on backup events
-type BackupEventCallback func(event BackupEvent)
+	fs             vfs.FS
+}
+
+// Topics the backup engine publishes; every payload is a BackupEvent
+var (
+	TopicBackupCreated            = events.Define("backup.backup_created", "A file was backed up")
+	TopicOperationBackupCompleted = events.Define("backup.operation_backup_completed", "The files about to be changed by an operation were backed up")
+	TopicBackupRestored           = events.Define("backup.backup_restored", "A backup was restored")
+	TopicBackupDeleted            = events.Define("backup.backup_deleted", "A backup was deleted")
+	TopicBackupCleanup            = events.Define("backup.backup_cleanup", "Expired backups were removed")
+	TopicBackupCorrupted          = events.Define("backup.backup_corrupted", "A backup failed checksum verification")
+	TopicBackupFailed             = events.Define("backup.backup_failed", "A file could not be backed up")
+)
 
-// BackupEvent represents an event that occurred during backup operations
+// BackupEvent is the payload of backup events
 type BackupEvent struct {
-	Type        string                 `json:"type"`
 	OperationID string                 `json:"operation_id,omitempty"`
 	BackupID    string                 `json:"backup_id,omitempty"`
 	FilePath    string                 `json:"file_path,omitempty"`
-	Message     string                 `json:"message"`
 	Data        map[string]interface{} `json:"data,omitempty"`
-	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// DefaultBackupConfig returns the settings used when nothing is configured
+func DefaultBackupConfig() BackupConfig {
+	return BackupConfig{
+		Enabled:         true,
+		MaxBackups:      100,
+		RetentionDays:   30,
+		Compression:     true,
+		Encryption:      false,
+		BackupDirectory: filepath.Join(paths.DataDir(), "backups"),
+		AutoCleanup:     true,
+		VerifyChecksums: true,
+		ExcludePatterns: []string{".git/", "node_modules/", ".DS_Store"},
+		IncludePatterns: []string{},
+		MaxBackupSize:   100 * 1024 * 1024 * 1024, // 100GB
+		MinFreeSpace:    1 * 1024 * 1024 * 1024,   // 1GB
+	}
 }
 
 // NewBackupEngine creates a new backup engine instance
 func NewBackupEngine(analytics *suggestions.UsageAnalytics) *BackupEngine {
+	return NewBackupEngineWithConfig(analytics, DefaultBackupConfig())
+}
+
+// NewBackupEngineWithConfig creates a backup engine using the given configuration
+func NewBackupEngineWithConfig(analytics *suggestions.UsageAnalytics, config BackupConfig) *BackupEngine {
+	return NewBackupEngineWithFS(vfs.OS{}, analytics, config)
+}
+
+// NewBackupEngineWithFS creates a backup engine that backs up and restores files on the given filesystem
+func NewBackupEngineWithFS(fs vfs.FS, analytics *suggestions.UsageAnalytics, config BackupConfig) *BackupEngine {
 	be := &BackupEngine{
-		config: BackupConfig{
-			Enabled:         true,
-			MaxBackups:      100,
-			RetentionDays:   30,
-			Compression:     true,
-			Encryption:      false,
-			BackupDirectory: "~/.ena/backups",
-			AutoCleanup:     true,
-			VerifyChecksums: true,
-			ExcludePatterns: []string{".git/", "node_modules/", ".DS_Store"},
-			IncludePatterns: []string{},
-			MaxBackupSize:   100 * 1024 * 1024 * 1024, // 100GB
-			MinFreeSpace:    1 * 1024 * 1024 * 1024,   // 1GB
-		},
+		config:         config,
 		analytics:      analytics,
 		operations:     make(map[string]*BackupOperation),
 		backups:        make(map[string]*BackupMetadata),
-		configFile:     "backup_config.json",
-		operationsFile: "backup_operations.json",
-		backupsFile:    "backup_metadata.json",
-		eventCallbacks: make(map[string][]BackupEventCallback),
+		operationsFile: paths.DataFile("backup_operations.json"),
+		backupsFile:    paths.DataFile("backup_metadata.json"),
 		stopChan:       make(chan struct{}),
+		fs:             fs,
 	}
 
 	// Expand backup directory path
 	be.expandBackupDirectory()
 
 	// Load existing data
-	be.loadConfig()
 	be.loadOperations()
 	be.loadBackups()
 
@@ -181,6 +204,16 @@ func NewBackupEngine(analytics *suggestions.UsageAnalytics) *BackupEngine {
 
 // CreateBackup creates a backup of the specified file or directory
 func (be *BackupEngine) CreateBackup(sourcePath, operationID, description string, tags []string) (*BackupMetadata, error) {
+	return be.createBackup(sourcePath, be.detectBackupType(sourcePath), operationID, description, tags)
+}
+
+// CreateScheduledBackup creates a backup on behalf of a scheduled job
+func (be *BackupEngine) CreateScheduledBackup(sourcePath, jobID, description string) (*BackupMetadata, error) {
+	return be.createBackup(sourcePath, BackupTypeScheduled, jobID, description, []string{"scheduled"})
+}
+
+// createBackup backs up sourcePath, recording it as the given type
+func (be *BackupEngine) createBackup(sourcePath string, backupType BackupType, operationID, description string, tags []string) (*BackupMetadata, error) {
 	be.mutex.Lock()
 	defer be.mutex.Unlock()
 
@@ -200,7 +233,7 @@ func (be *BackupEngine) CreateBackup(sourcePath, operationID, description string
 	metadata := &BackupMetadata{
 		OriginalPath: sourcePath,
 		BackupPath:   be.generateBackupPath(sourcePath, backupID),
-		Type:         be.detectBackupType(sourcePath),
+		Type:         backupType,
 		Status:       BackupStatusCreated,
 		CreatedAt:    time.Now(),
 		OperationID:  operationID,
@@ -217,13 +250,17 @@ func (be *BackupEngine) CreateBackup(sourcePath, operationID, description string
 	}
 
 	// Create backup directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(metadata.BackupPath), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create backup directory: %v", err)
+	if err := be.fs.MkdirAll(filepath.Dir(metadata.BackupPath), 0755); err != nil {
+		err = fmt.Errorf("failed to create backup directory: %w", err)
+		be.publishFailed(metadata, err)
+		return nil, err
 	}
 
 	// Perform the backup
 	if err := be.performBackup(metadata); err != nil {
-		return nil, fmt.Errorf("failed to perform backup: %v", err)
+		err = fmt.Errorf("failed to perform backup: %w", err)
+		be.publishFailed(metadata, err)
+		return nil, err
 	}
 
 	// Verify backup if enabled
@@ -232,7 +269,8 @@ func (be *BackupEngine) CreateBackup(sourcePath, operationID, description string
 			metadata.Status = BackupStatusCorrupted
 			be.backups[backupID] = metadata
 			be.saveBackups()
-			return metadata, fmt.Errorf("backup verification failed: %v", err)
+			be.publishCorrupted(metadata, backupID, err)
+			return metadata, fmt.Errorf("backup verification failed: %w", err)
 		}
 		metadata.Status = BackupStatusVerified
 	}
@@ -250,18 +288,17 @@ func (be *BackupEngine) CreateBackup(sourcePath, operationID, description string
 	be.saveOperations()
 
 	// Trigger event
-	be.triggerEvent(BackupEvent{
-		Type:        "backup_created",
+	be.publish(TopicBackupCreated, fmt.Sprintf("Backup created for %s", sourcePath), BackupEvent{
 		OperationID: operationID,
 		BackupID:    backupID,
 		FilePath:    sourcePath,
-		Message:     fmt.Sprintf("Backup created for %s", sourcePath),
 		Data: map[string]interface{}{
 			"backup_path": metadata.BackupPath,
+			"type":        metadata.Type,
+			"tags":        metadata.Tags,
 			"size":        metadata.Size,
 			"checksum":    metadata.Checksum,
 		},
-		Timestamp: time.Now(),
 	})
 
 	return metadata, nil
@@ -305,17 +342,14 @@ func (be *BackupEngine) CreateOperationBackup(operationID, operationType, source
 	result.Duration = time.Since(startTime)
 
 	// Trigger event
-	be.triggerEvent(BackupEvent{
-		Type:        "operation_backup_completed",
+	be.publish(TopicOperationBackupCompleted, fmt.Sprintf("Operation backup completed: %d backups created", result.BackupsCreated), BackupEvent{
 		OperationID: operationID,
-		Message:     fmt.Sprintf("Operation backup completed: %d backups created", result.BackupsCreated),
 		Data: map[string]interface{}{
 			"backups_created": result.BackupsCreated,
 			"backups_failed":  result.BackupsFailed,
 			"total_size":      result.TotalSize,
 			"duration":        result.Duration.String(),
 		},
-		Timestamp: time.Now(),
 	})
 
 	return result, nil
@@ -328,7 +362,7 @@ func (be *BackupEngine) RestoreBackup(backupID, destinationPath string, overwrit
 	be.mutex.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("backup %s not found", backupID)
+		return fault.NotFound("backup %s not found", backupID)
 	}
 
 	if metadata.Status == BackupStatusCorrupted {
@@ -342,7 +376,7 @@ func (be *BackupEngine) RestoreBackup(backupID, destinationPath string, overwrit
 
 	// Check if destination exists and overwrite is not allowed
 	if !overwrite {
-		if _, err := os.Stat(destinationPath); err == nil {
+		if _, err := be.fs.Stat(destinationPath); err == nil {
 			return fmt.Errorf("destination %s already exists and overwrite is disabled", destinationPath)
 		}
 	}
@@ -350,18 +384,21 @@ func (be *BackupEngine) RestoreBackup(backupID, destinationPath string, overwrit
 	// Verify backup integrity
 	if be.config.VerifyChecksums {
 		if err := be.verifyBackup(metadata); err != nil {
-			return fmt.Errorf("backup verification failed: %v", err)
+			be.publishCorrupted(metadata, backupID, err)
+			return fmt.Errorf("backup verification failed: %w", err)
 		}
 	}
 
 	// Create destination directory if needed
-	if err := os.MkdirAll(filepath.Dir(destinationPath), 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %v", err)
+	if err := be.fs.MkdirAll(filepath.Dir(destinationPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	// Perform restoration
-	if err := be.performRestore(metadata, destinationPath); err != nil {
-		return fmt.Errorf("failed to restore backup: %v", err)
+	err := be.performRestore(metadata, destinationPath)
+	audit.RecordFile("backup", "restore", metadata.BackupPath, destinationPath, err)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
 	}
 
 	// Update metadata
@@ -373,12 +410,9 @@ func (be *BackupEngine) RestoreBackup(backupID, destinationPath string, overwrit
 	be.saveBackups()
 
 	// Trigger event
-	be.triggerEvent(BackupEvent{
-		Type:      "backup_restored",
-		BackupID:  backupID,
-		FilePath:  destinationPath,
-		Message:   fmt.Sprintf("Backup restored to %s", destinationPath),
-		Timestamp: time.Now(),
+	be.publish(TopicBackupRestored, fmt.Sprintf("Backup restored to %s", destinationPath), BackupEvent{
+		BackupID: backupID,
+		FilePath: destinationPath,
 	})
 
 	return nil
@@ -404,6 +438,18 @@ func (be *BackupEngine) ListBackups(filter map[string]interface{}) []BackupMetad
 	return backups
 }
 
+// BackupIDs returns every backup keyed by the ID restore and delete take
+func (be *BackupEngine) BackupIDs() map[string]BackupMetadata {
+	be.mutex.RLock()
+	defer be.mutex.RUnlock()
+
+	backups := make(map[string]BackupMetadata, len(be.backups))
+	for id, backup := range be.backups {
+		backups[id] = *backup
+	}
+	return backups
+}
+
 // DeleteBackup deletes a backup and its associated files
 func (be *BackupEngine) DeleteBackup(backupID string) error {
 	be.mutex.Lock()
@@ -411,13 +457,15 @@ func (be *BackupEngine) DeleteBackup(backupID string) error {
 
 	metadata, exists := be.backups[backupID]
 	if !exists {
-		return fmt.Errorf("backup %s not found", backupID)
+		return fault.NotFound("backup %s not found", backupID)
 	}
 
 	// Delete backup file
-	if err := os.Remove(metadata.BackupPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete backup file: %v", err)
+	if err := be.fs.Remove(metadata.BackupPath); err != nil && !os.IsNotExist(err) {
+		audit.RecordFile("backup", "delete-backup", metadata.BackupPath, "", err)
+		return fmt.Errorf("failed to delete backup file: %w", err)
 	}
+	audit.RecordFile("backup", "delete-backup", metadata.BackupPath, "", nil)
 
 	// Remove from metadata
 	delete(be.backups, backupID)
@@ -437,12 +485,9 @@ func (be *BackupEngine) DeleteBackup(backupID string) error {
 	be.saveOperations()
 
 	// Trigger event
-	be.triggerEvent(BackupEvent{
-		Type:      "backup_deleted",
-		BackupID:  backupID,
-		FilePath:  metadata.OriginalPath,
-		Message:   fmt.Sprintf("Backup deleted for %s", metadata.OriginalPath),
-		Timestamp: time.Now(),
+	be.publish(TopicBackupDeleted, fmt.Sprintf("Backup deleted for %s", metadata.OriginalPath), BackupEvent{
+		BackupID: backupID,
+		FilePath: metadata.OriginalPath,
 	})
 
 	return nil
@@ -467,9 +512,11 @@ func (be *BackupEngine) CleanupExpiredBackups() (int, error) {
 		metadata := be.backups[backupID]
 
 		// Delete backup file
-		if err := os.Remove(metadata.BackupPath); err != nil && !os.IsNotExist(err) {
+		if err := be.fs.Remove(metadata.BackupPath); err != nil && !os.IsNotExist(err) {
+			audit.RecordFile("backup", "expire-backup", metadata.BackupPath, "", err)
 			continue // Skip if file deletion fails
 		}
+		audit.RecordFile("backup", "expire-backup", metadata.BackupPath, "", nil)
 
 		// Remove from metadata
 		delete(be.backups, backupID)
@@ -480,10 +527,8 @@ func (be *BackupEngine) CleanupExpiredBackups() (int, error) {
 		be.saveBackups()
 
 		// Trigger event
-		be.triggerEvent(BackupEvent{
-			Type:      "backup_cleanup",
-			Message:   fmt.Sprintf("Cleaned up %d expired backups", cleanedCount),
-			Timestamp: time.Now(),
+		be.publish(TopicBackupCleanup, fmt.Sprintf("Cleaned up %d expired backups", cleanedCount), BackupEvent{
+			Data: map[string]interface{}{"cleaned": cleanedCount},
 		})
 	}
 
@@ -570,7 +615,7 @@ func (be *BackupEngine) shouldBackup(sourcePath string) bool {
 	}
 
 	// Check file size
-	if info, err := os.Stat(sourcePath); err == nil {
+	if info, err := be.fs.Stat(sourcePath); err == nil {
 		if info.Size() > be.config.MaxBackupSize {
 			return false
 		}
@@ -580,7 +625,7 @@ func (be *BackupEngine) shouldBackup(sourcePath string) bool {
 }
 
 func (be *BackupEngine) detectBackupType(sourcePath string) BackupType {
-	if info, err := os.Stat(sourcePath); err == nil {
+	if info, err := be.fs.Stat(sourcePath); err == nil {
 		if info.IsDir() {
 			return BackupTypeDirectory
 		}
@@ -603,13 +648,13 @@ func (be *BackupEngine) generateBackupPath(sourcePath, backupID string) string {
 }
 
 func (be *BackupEngine) performBackup(metadata *BackupMetadata) error {
-	sourceFile, err := os.Open(metadata.OriginalPath)
+	sourceFile, err := be.fs.Open(metadata.OriginalPath)
 	if err != nil {
 		return err
 	}
 	defer sourceFile.Close()
 
-	destFile, err := os.Create(metadata.BackupPath)
+	destFile, err := be.fs.Create(metadata.BackupPath)
 	if err != nil {
 		return err
 	}
@@ -631,7 +676,7 @@ func (be *BackupEngine) performBackup(metadata *BackupMetadata) error {
 }
 
 func (be *BackupEngine) verifyBackup(metadata *BackupMetadata) error {
-	file, err := os.Open(metadata.BackupPath)
+	file, err := be.fs.Open(metadata.BackupPath)
 	if err != nil {
 		return err
 	}
@@ -651,13 +696,13 @@ func (be *BackupEngine) verifyBackup(metadata *BackupMetadata) error {
 }
 
 func (be *BackupEngine) performRestore(metadata *BackupMetadata, destinationPath string) error {
-	sourceFile, err := os.Open(metadata.BackupPath)
+	sourceFile, err := be.fs.Open(metadata.BackupPath)
 	if err != nil {
 		return err
 	}
 	defer sourceFile.Close()
 
-	destFile, err := os.Create(destinationPath)
+	destFile, err := be.fs.Create(destinationPath)
 	if err != nil {
 		return err
 	}
@@ -714,34 +759,12 @@ func (be *BackupEngine) startCleanupRoutine() {
 	}()
 }
 
-func (be *BackupEngine) loadConfig() error {
-	if _, err := os.Stat(be.configFile); os.IsNotExist(err) {
-		return nil
-	}
-
-	data, err := os.ReadFile(be.configFile)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(data, &be.config)
-}
-
-func (be *BackupEngine) saveConfig() error {
-	data, err := json.MarshalIndent(be.config, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(be.configFile, data, 0644)
-}
-
 func (be *BackupEngine) loadOperations() error {
-	if _, err := os.Stat(be.operationsFile); os.IsNotExist(err) {
+	if _, err := be.fs.Stat(be.operationsFile); os.IsNotExist(err) {
 		return nil
 	}
 
-	data, err := os.ReadFile(be.operationsFile)
+	data, err := be.fs.ReadFile(be.operationsFile)
 	if err != nil {
 		return err
 	}
@@ -769,15 +792,15 @@ func (be *BackupEngine) saveOperations() error {
 		return err
 	}
 
-	return os.WriteFile(be.operationsFile, data, 0644)
+	return be.fs.WriteFile(be.operationsFile, data, 0644)
 }
 
 func (be *BackupEngine) loadBackups() error {
-	if _, err := os.Stat(be.backupsFile); os.IsNotExist(err) {
+	if _, err := be.fs.Stat(be.backupsFile); os.IsNotExist(err) {
 		return nil
 	}
 
-	data, err := os.ReadFile(be.backupsFile)
+	data, err := be.fs.ReadFile(be.backupsFile)
 	if err != nil {
 		return err
 	}
@@ -807,28 +830,47 @@ func (be *BackupEngine) saveBackups() error {
 		return err
 	}
 
-	return os.WriteFile(be.backupsFile, data, 0644)
+	return be.fs.WriteFile(be.backupsFile, data, 0644)
 }
 
-func (be *BackupEngine) triggerEvent(event BackupEvent) {
-	be.mutex.RLock()
-	callbacks := be.eventCallbacks[event.Type]
-	be.mutex.RUnlock()
+// SetEventBus sets the bus backup events are published on
+func (be *BackupEngine) SetEventBus(bus *events.Bus) {
+	be.events = bus
+}
 
-	for _, callback := range callbacks {
-		go callback(event) // Run callbacks asynchronously
-	}
+// publish announces a backup event on the event bus
+func (be *BackupEngine) publish(topic events.Topic, message string, event BackupEvent) {
+	be.events.Publish(events.New(topic, message, event))
 }
 
-// AddEventCallback adds a callback for backup events
-func (be *BackupEngine) AddEventCallback(eventType string, callback BackupEventCallback) {
-	be.mutex.Lock()
-	defer be.mutex.Unlock()
+// publishCorrupted announces a backup whose checksum no longer matches
+func (be *BackupEngine) publishCorrupted(metadata *BackupMetadata, backupID string, err error) {
+	be.publish(TopicBackupCorrupted, fmt.Sprintf("Backup of %s is corrupted: %v", metadata.OriginalPath, err), BackupEvent{
+		OperationID: metadata.OperationID,
+		BackupID:    backupID,
+		FilePath:    metadata.OriginalPath,
+		Data: map[string]interface{}{
+			"backup_path": metadata.BackupPath,
+			"error":       err.Error(),
+		},
+	})
+}
 
-	be.eventCallbacks[eventType] = append(be.eventCallbacks[eventType], callback)
+// publishFailed announces a backup that could not be made
+func (be *BackupEngine) publishFailed(metadata *BackupMetadata, err error) {
+	be.publish(TopicBackupFailed, fmt.Sprintf("Backup of %s failed: %v", metadata.OriginalPath, err), BackupEvent{
+		OperationID: metadata.OperationID,
+		FilePath:    metadata.OriginalPath,
+		Data: map[string]interface{}{
+			"type":  metadata.Type,
+			"tags":  metadata.Tags,
+			"error": err.Error(),
+		},
+	})
 }
 
-// UpdateConfig updates the backup configuration
+// UpdateConfig updates the backup configuration of this engine; persistent
+// settings live in the backup section of config.yaml
 func (be *BackupEngine) UpdateConfig(config BackupConfig) error {
 	be.mutex.Lock()
 	defer be.mutex.Unlock()
@@ -836,7 +878,7 @@ func (be *BackupEngine) UpdateConfig(config BackupConfig) error {
 	be.config = config
 	be.expandBackupDirectory()
 
-	return be.saveConfig()
+	return nil
 }
 
 // GetConfig returns the current backup configuration
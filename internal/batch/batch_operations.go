@@ -13,15 +13,20 @@
 package batch
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"ena/internal/audit"
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/policy"
 	"ena/internal/progress"
 	"ena/internal/suggestions"
+	"ena/internal/vfs"
 )
 
 // BatchOperation represents a single operation in a batch
@@ -33,6 +38,7 @@ type BatchOperation struct {
 	Size        int64                  `json:"size"`
 	Status      string                 `json:"status"` // pending, running, completed, failed, skipped
 	Error       string                 `json:"error,omitempty"`
+	Code        int                    `json:"code,omitempty"` // exit code class of Error, e.g. 4 for a policy refusal
 	StartTime   time.Time              `json:"start_time,omitempty"`
 	EndTime     time.Time              `json:"end_time,omitempty"`
 	Duration    time.Duration          `json:"duration,omitempty"`
@@ -57,68 +63,91 @@ type BatchJob struct {
 	SkippedCount  int                    `json:"skipped_count"`
 	Config        BatchConfig            `json:"config"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Changes       []vfs.Change           `json:"changes,omitempty"` // What a dry run would have changed
 }
 
 // BatchConfig contains configuration for batch operations
 type BatchConfig struct {
-	MaxConcurrency      int           `json:"max_concurrency"`      // Maximum concurrent operations
-	SkipErrors          bool          `json:"skip_errors"`          // Continue on errors
-	DryRun              bool          `json:"dry_run"`              // Preview mode
-	ConfirmEach         bool          `json:"confirm_each"`         // Confirm each operation
-	ProgressInterval    time.Duration `json:"progress_interval"`    // Progress update interval
-	RetryCount          int           `json:"retry_count"`          // Number of retries on failure
-	RetryDelay          time.Duration `json:"retry_delay"`          // Delay between retries
-	PreserveTimestamps  bool          `json:"preserve_timestamps"`  // Preserve file timestamps
-	PreservePermissions bool          `json:"preserve_permissions"` // Preserve file permissions
-	FollowSymlinks      bool          `json:"follow_symlinks"`      // Follow symbolic links
-	ExcludePatterns     []string      `json:"exclude_patterns"`     // Patterns to exclude
-	IncludePatterns     []string      `json:"include_patterns"`     // Patterns to include
+	MaxConcurrency      int           `json:"max_concurrency" yaml:"max_concurrency"`           // Maximum concurrent operations
+	SkipErrors          bool          `json:"skip_errors" yaml:"skip_errors"`                   // Continue on errors
+	DryRun              bool          `json:"dry_run" yaml:"dry_run"`                           // Preview mode
+	Permanent           bool          `json:"permanent" yaml:"permanent"`                       // Delete instead of moving to the trash
+	ConfirmEach         bool          `json:"confirm_each" yaml:"confirm_each"`                 // Confirm each operation
+	ProgressInterval    time.Duration `json:"progress_interval" yaml:"progress_interval"`       // Progress update interval
+	RetryCount          int           `json:"retry_count" yaml:"retry_count"`                   // Number of retries on failure
+	RetryDelay          time.Duration `json:"retry_delay" yaml:"retry_delay"`                   // Delay between retries
+	PreserveTimestamps  bool          `json:"preserve_timestamps" yaml:"preserve_timestamps"`   // Preserve file timestamps
+	PreservePermissions bool          `json:"preserve_permissions" yaml:"preserve_permissions"` // Preserve file permissions
+	FollowSymlinks      bool          `json:"follow_symlinks" yaml:"follow_symlinks"`           // Follow symbolic links
+	ExcludePatterns     []string      `json:"exclude_patterns" yaml:"exclude_patterns"`         // Patterns to exclude
+	IncludePatterns     []string      `json:"include_patterns" yaml:"include_patterns"`         // Patterns to include
 }
 
 // BatchManager manages batch operations and provides the main interface
 type BatchManager struct {
-	jobs           map[string]*BatchJob
-	mutex          sync.RWMutex
-	progressBars   map[string]*progress.ProgressBar
-	analytics      *suggestions.UsageAnalytics
-	defaultConfig  BatchConfig
-	eventCallbacks map[string][]BatchEventCallback
+	jobs          map[string]*BatchJob
+	mutex         sync.RWMutex
+	progressBars  map[string]*progress.ProgressBar
+	analytics     *suggestions.UsageAnalytics
+	defaultConfig BatchConfig
+	events        *events.Bus
+	fs            vfs.FS
 }
 
-// BatchEventCallback is a function that gets called on batch events
-type BatchEventCallback func(event BatchEvent)
+// Topics the batch manager publishes; every payload is a BatchEvent
+var (
+	TopicJobStarted         = events.Define("batch.job_started", "A batch job started")
+	TopicJobCompleted       = events.Define("batch.job_completed", "A batch job finished, successfully or not")
+	TopicJobCancelled       = events.Define("batch.job_cancelled", "A running batch job was cancelled")
+	TopicOperationStarted   = events.Define("batch.operation_started", "A batch job started one operation")
+	TopicOperationCompleted = events.Define("batch.operation_completed", "A batch job finished one operation")
+)
 
-// BatchEvent represents an event that occurred during batch processing
+// BatchEvent is the payload of batch events
 type BatchEvent struct {
-	Type      string                 `json:"type"` // job_started, job_completed, operation_started, operation_completed, error, progress
 	JobID     string                 `json:"job_id"`
-	Operation *BatchOperation        `json:"operation,omitempty"`
-	Message   string                 `json:"message"`
+	JobName   string                 `json:"job_name"`
+	Operation *BatchOperation        `json:"operation,omitempty"` // a copy taken when the event was published
 	Data      map[string]interface{} `json:"data,omitempty"`
-	Timestamp time.Time              `json:"timestamp"`
+}
+
+// DefaultBatchConfig returns the settings used when nothing is configured
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxConcurrency:      4,
+		SkipErrors:          true,
+		DryRun:              false,
+		Permanent:           false,
+		ConfirmEach:         false,
+		ProgressInterval:    500 * time.Millisecond,
+		RetryCount:          2,
+		RetryDelay:          1 * time.Second,
+		PreserveTimestamps:  true,
+		PreservePermissions: true,
+		FollowSymlinks:      false,
+		ExcludePatterns:     []string{},
+		IncludePatterns:     []string{},
+	}
 }
 
 // NewBatchManager creates a new batch manager instance
 func NewBatchManager(analytics *suggestions.UsageAnalytics) *BatchManager {
+	return NewBatchManagerWithConfig(analytics, DefaultBatchConfig())
+}
+
+// NewBatchManagerWithConfig creates a batch manager whose jobs fall back to the given defaults
+func NewBatchManagerWithConfig(analytics *suggestions.UsageAnalytics, config BatchConfig) *BatchManager {
+	return NewBatchManagerWithFS(vfs.OS{}, analytics, config)
+}
+
+// NewBatchManagerWithFS creates a batch manager whose jobs run on the given filesystem
+func NewBatchManagerWithFS(fs vfs.FS, analytics *suggestions.UsageAnalytics, config BatchConfig) *BatchManager {
 	return &BatchManager{
-		jobs:         make(map[string]*BatchJob),
-		progressBars: make(map[string]*progress.ProgressBar),
-		analytics:    analytics,
-		defaultConfig: BatchConfig{
-			MaxConcurrency:      4,
-			SkipErrors:          true,
-			DryRun:              false,
-			ConfirmEach:         false,
-			ProgressInterval:    500 * time.Millisecond,
-			RetryCount:          2,
-			RetryDelay:          1 * time.Second,
-			PreserveTimestamps:  true,
-			PreservePermissions: true,
-			FollowSymlinks:      false,
-			ExcludePatterns:     []string{},
-			IncludePatterns:     []string{},
-		},
-		eventCallbacks: make(map[string][]BatchEventCallback),
+		jobs:          make(map[string]*BatchJob),
+		progressBars:  make(map[string]*progress.ProgressBar),
+		analytics:     analytics,
+		defaultConfig: config,
+		fs:            fs,
 	}
 }
 
@@ -169,7 +198,7 @@ func (bm *BatchManager) ExecuteBatchJob(jobID string) error {
 	job, exists := bm.jobs[jobID]
 	if !exists {
 		bm.mutex.Unlock()
-		return fmt.Errorf("batch job %s not found", jobID)
+		return fault.NotFound("batch job %s not found", jobID)
 	}
 	bm.mutex.Unlock()
 
@@ -187,36 +216,47 @@ func (bm *BatchManager) ExecuteBatchJob(jobID string) error {
 	// Start job
 	job.Status = "running"
 	job.StartTime = time.Now()
-	bm.triggerEvent(BatchEvent{
-		Type:      "job_started",
-		JobID:     jobID,
-		Message:   fmt.Sprintf("Started batch job: %s", job.Name),
-		Timestamp: time.Now(),
+	bm.publish(TopicJobStarted, fmt.Sprintf("Started batch job: %s", job.Name), BatchEvent{
+		JobID:   jobID,
+		JobName: job.Name,
 	})
 
+	// A dry run goes through an overlay, so it fails where the real run would
+	// and records what it would have changed
+	files := bm.fs
+	var overlay *vfs.Overlay
+	if job.Config.DryRun {
+		overlay = vfs.NewOverlay(bm.fs)
+		files = overlay
+	}
+
 	// Execute operations
-	err := bm.executeOperations(job, pb)
+	err := bm.executeOperations(job, files, pb)
+	if overlay != nil {
+		job.Changes = overlay.Changes()
+	}
 
-	// Complete job
-	job.Status = "completed"
-	if err != nil {
-		job.Status = "failed"
+	// Complete job, keeping a cancellation visible
+	if job.Status != "cancelled" {
+		job.Status = "completed"
+		if err != nil {
+			job.Status = "failed"
+		}
+		job.EndTime = time.Now()
+		job.Duration = job.EndTime.Sub(job.StartTime)
+		job.Progress = 1.0
 	}
-	job.EndTime = time.Now()
-	job.Duration = job.EndTime.Sub(job.StartTime)
-	job.Progress = 1.0
 
-	bm.triggerEvent(BatchEvent{
-		Type:    "job_completed",
+	bm.publish(TopicJobCompleted, fmt.Sprintf("Completed batch job: %s", job.Name), BatchEvent{
 		JobID:   jobID,
-		Message: fmt.Sprintf("Completed batch job: %s", job.Name),
+		JobName: job.Name,
 		Data: map[string]interface{}{
+			"status":        job.Status,
 			"success_count": job.SuccessCount,
 			"error_count":   job.ErrorCount,
 			"skipped_count": job.SkippedCount,
 			"duration":      job.Duration.String(),
 		},
-		Timestamp: time.Now(),
 	})
 
 	// Clean up progress bar
@@ -235,12 +275,12 @@ func (bm *BatchManager) BatchDelete(paths []string, config BatchConfig) (*BatchJ
 
 	for _, path := range paths {
 		// Check if path exists
-		info, err := os.Stat(path)
+		info, err := bm.fs.Stat(path)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue // Skip non-existent paths
 			}
-			return nil, fmt.Errorf("error checking path %s: %v", path, err)
+			return nil, fmt.Errorf("error checking path %s: %w", path, err)
 		}
 
 		// Create delete operation
@@ -261,7 +301,7 @@ func (bm *BatchManager) BatchDelete(paths []string, config BatchConfig) (*BatchJ
 	}
 
 	if len(operations) == 0 {
-		return nil, fmt.Errorf("no valid paths to delete")
+		return nil, fmt.Errorf("no valid paths to delete: %w", os.ErrNotExist)
 	}
 
 	job := bm.CreateBatchJob(
@@ -278,14 +318,16 @@ func (bm *BatchManager) BatchDelete(paths []string, config BatchConfig) (*BatchJ
 func (bm *BatchManager) BatchCopy(sourcePaths []string, destination string, config BatchConfig) (*BatchJob, error) {
 	var operations []BatchOperation
 
-	// Ensure destination exists
-	if err := os.MkdirAll(destination, 0755); err != nil {
-		return nil, fmt.Errorf("error creating destination directory: %v", err)
+	// Ensure destination exists; a dry run creates it when the job runs
+	if !config.DryRun {
+		if err := bm.fs.MkdirAll(destination, 0755); err != nil {
+			return nil, fmt.Errorf("error creating destination directory: %w", err)
+		}
 	}
 
 	for _, sourcePath := range sourcePaths {
 		// Walk through source path recursively
-		err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		err := vfs.Walk(bm.fs, sourcePath, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -301,7 +343,11 @@ func (bm *BatchManager) BatchCopy(sourcePaths []string, destination string, conf
 				return err
 			}
 
+			// A single file goes into the destination, like BatchMove
 			destPath := filepath.Join(destination, relPath)
+			if path == sourcePath && !info.IsDir() {
+				destPath = filepath.Join(destination, filepath.Base(sourcePath))
+			}
 
 			// Create copy operation
 			operation := BatchOperation{
@@ -324,7 +370,7 @@ func (bm *BatchManager) BatchCopy(sourcePaths []string, destination string, conf
 		})
 
 		if err != nil {
-			return nil, fmt.Errorf("error walking source path %s: %v", sourcePath, err)
+			return nil, fmt.Errorf("error walking source path %s: %w", sourcePath, err)
 		}
 	}
 
@@ -346,18 +392,20 @@ func (bm *BatchManager) BatchCopy(sourcePaths []string, destination string, conf
 func (bm *BatchManager) BatchMove(sourcePaths []string, destination string, config BatchConfig) (*BatchJob, error) {
 	var operations []BatchOperation
 
-	// Ensure destination exists
-	if err := os.MkdirAll(destination, 0755); err != nil {
-		return nil, fmt.Errorf("error creating destination directory: %v", err)
+	// Ensure destination exists; a dry run creates it when the job runs
+	if !config.DryRun {
+		if err := bm.fs.MkdirAll(destination, 0755); err != nil {
+			return nil, fmt.Errorf("error creating destination directory: %w", err)
+		}
 	}
 
 	for _, sourcePath := range sourcePaths {
-		info, err := os.Stat(sourcePath)
+		info, err := bm.fs.Stat(sourcePath)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue
 			}
-			return nil, fmt.Errorf("error checking source path %s: %v", sourcePath, err)
+			return nil, fmt.Errorf("error checking source path %s: %w", sourcePath, err)
 		}
 
 		// Calculate destination path
@@ -382,7 +430,7 @@ func (bm *BatchManager) BatchMove(sourcePaths []string, destination string, conf
 	}
 
 	if len(operations) == 0 {
-		return nil, fmt.Errorf("no valid paths to move")
+		return nil, fmt.Errorf("no valid paths to move: %w", os.ErrNotExist)
 	}
 
 	job := bm.CreateBatchJob(
@@ -402,7 +450,7 @@ func (bm *BatchManager) GetJobStatus(jobID string) (*BatchJob, error) {
 
 	job, exists := bm.jobs[jobID]
 	if !exists {
-		return nil, fmt.Errorf("batch job %s not found", jobID)
+		return nil, fault.NotFound("batch job %s not found", jobID)
 	}
 
 	return job, nil
@@ -428,7 +476,7 @@ func (bm *BatchManager) CancelJob(jobID string) error {
 
 	job, exists := bm.jobs[jobID]
 	if !exists {
-		return fmt.Errorf("batch job %s not found", jobID)
+		return fault.NotFound("batch job %s not found", jobID)
 	}
 
 	if job.Status != "running" {
@@ -445,11 +493,9 @@ func (bm *BatchManager) CancelJob(jobID string) error {
 		delete(bm.progressBars, jobID)
 	}
 
-	bm.triggerEvent(BatchEvent{
-		Type:      "job_cancelled",
-		JobID:     jobID,
-		Message:   fmt.Sprintf("Cancelled batch job: %s", job.Name),
-		Timestamp: time.Now(),
+	bm.publish(TopicJobCancelled, fmt.Sprintf("Cancelled batch job: %s", job.Name), BatchEvent{
+		JobID:   jobID,
+		JobName: job.Name,
 	})
 
 	return nil
@@ -457,7 +503,7 @@ func (bm *BatchManager) CancelJob(jobID string) error {
 
 // Private helper methods
 
-func (bm *BatchManager) executeOperations(job *BatchJob, pb *progress.ProgressBar) error {
+func (bm *BatchManager) executeOperations(job *BatchJob, files vfs.FS, pb *progress.ProgressBar) error {
 	semaphore := make(chan struct{}, job.Config.MaxConcurrency)
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
@@ -470,7 +516,17 @@ func (bm *BatchManager) executeOperations(job *BatchJob, pb *progress.ProgressBa
 			semaphore <- struct{}{}        // Acquire semaphore
 			defer func() { <-semaphore }() // Release semaphore
 
-			bm.executeOperation(job, &op, index, pb)
+			if job.Status == "cancelled" {
+				// Job was cancelled while queued - leave the rest untouched
+				op.Status = "skipped"
+				mutex.Lock()
+				job.SkippedCount++
+				job.Operations[index] = op
+				mutex.Unlock()
+				return
+			}
+
+			bm.executeOperation(job, files, &op, index, pb)
 
 			mutex.Lock()
 			job.Operations[index] = op
@@ -486,36 +542,29 @@ func (bm *BatchManager) executeOperations(job *BatchJob, pb *progress.ProgressBa
 	return nil
 }
 
-func (bm *BatchManager) executeOperation(job *BatchJob, operation *BatchOperation, index int, pb *progress.ProgressBar) {
+func (bm *BatchManager) executeOperation(job *BatchJob, files vfs.FS, operation *BatchOperation, index int, pb *progress.ProgressBar) {
 	operation.Status = "running"
 	operation.StartTime = time.Now()
 
-	bm.triggerEvent(BatchEvent{
-		Type:      "operation_started",
-		JobID:     job.ID,
-		Operation: operation,
-		Message:   fmt.Sprintf("Started %s: %s", operation.Type, operation.Source),
-		Timestamp: time.Now(),
-	})
+	bm.publishOperation(TopicOperationStarted, fmt.Sprintf("Started %s: %s", operation.Type, operation.Source), job, operation)
 
 	var err error
-
-	if job.Config.DryRun {
-		// Dry run - just simulate
-		time.Sleep(100 * time.Millisecond) // Simulate work
-		operation.Status = "completed"
-	} else {
-		// Execute actual operation
-		switch operation.Type {
-		case "delete":
-			err = bm.executeDelete(operation)
-		case "copy":
-			err = bm.executeCopy(operation, job.Config)
-		case "move":
-			err = bm.executeMove(operation, job.Config)
-		default:
-			err = fmt.Errorf("unknown operation type: %s", operation.Type)
+	switch operation.Type {
+	case "delete":
+		err = bm.executeDelete(files, operation, job.Config)
+	case "copy":
+		err = bm.executeCopy(files, operation, job.Config)
+	case "move":
+		err = bm.executeMove(files, operation, job.Config)
+	default:
+		err = fmt.Errorf("unknown operation type: %s", operation.Type)
+	}
+	if !job.Config.DryRun {
+		action := operation.Type
+		if action == "delete" && !job.Config.Permanent {
+			action = "trash"
 		}
+		audit.RecordFile("batch", action, operation.Source, operation.Destination, err)
 	}
 
 	operation.EndTime = time.Now()
@@ -524,6 +573,7 @@ func (bm *BatchManager) executeOperation(job *BatchJob, operation *BatchOperatio
 	if err != nil {
 		operation.Status = "failed"
 		operation.Error = err.Error()
+		operation.Code = fault.Code(err)
 		job.ErrorCount++
 	} else {
 		operation.Status = "completed"
@@ -535,90 +585,73 @@ func (bm *BatchManager) executeOperation(job *BatchJob, operation *BatchOperatio
 	job.Progress = float64(job.ProcessedSize) / float64(job.TotalSize)
 	pb.Update(int64(job.SuccessCount + job.ErrorCount))
 
-	bm.triggerEvent(BatchEvent{
-		Type:      "operation_completed",
-		JobID:     job.ID,
-		Operation: operation,
-		Message:   fmt.Sprintf("Completed %s: %s", operation.Type, operation.Source),
-		Timestamp: time.Now(),
-	})
+	bm.publishOperation(TopicOperationCompleted, fmt.Sprintf("Completed %s: %s", operation.Type, operation.Source), job, operation)
 }
 
-func (bm *BatchManager) executeDelete(operation *BatchOperation) error {
-	return os.RemoveAll(operation.Source)
+func (bm *BatchManager) executeDelete(files vfs.FS, operation *BatchOperation, config BatchConfig) error {
+	if config.Permanent {
+		return files.RemoveAll(operation.Source)
+	}
+	return vfs.Trash(files, operation.Source)
 }
 
-func (bm *BatchManager) executeCopy(operation *BatchOperation, config BatchConfig) error {
+func (bm *BatchManager) executeCopy(files vfs.FS, operation *BatchOperation, config BatchConfig) error {
 	sourceInfo := operation.Metadata["is_directory"].(bool)
 
 	if sourceInfo {
 		// Copy directory
-		return bm.copyDirectory(operation.Source, operation.Destination, config)
+		return bm.copyDirectory(files, operation.Source, operation.Destination, config)
 	} else {
 		// Copy file
-		return bm.copyFile(operation.Source, operation.Destination, config)
+		return bm.copyFile(files, operation.Source, operation.Destination, config)
 	}
 }
 
-func (bm *BatchManager) executeMove(operation *BatchOperation, config BatchConfig) error {
-	// Try rename first (fastest for same filesystem)
-	err := os.Rename(operation.Source, operation.Destination)
-	if err == nil {
-		return nil
-	}
-
-	// Fallback to copy + delete
-	err = bm.executeCopy(operation, config)
-	if err != nil {
+func (bm *BatchManager) executeMove(files vfs.FS, operation *BatchOperation, config BatchConfig) error {
+	if err := files.MkdirAll(filepath.Dir(operation.Destination), 0755); err != nil {
 		return err
 	}
 
-	return os.RemoveAll(operation.Source)
-}
-
-func (bm *BatchManager) copyFile(src, dst string, config BatchConfig) error {
-	// Create destination directory if needed
-	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+	// Try rename first (fastest for same filesystem); a policy refusal is final
+	err := files.Rename(operation.Source, operation.Destination)
+	if err == nil || errors.Is(err, policy.ErrBlocked) {
 		return err
 	}
 
-	sourceFile, err := os.Open(src)
+	// Fallback to copy + delete
+	err = bm.executeCopy(files, operation, config)
 	if err != nil {
 		return err
 	}
-	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
+	return files.RemoveAll(operation.Source)
+}
 
-	_, err = io.Copy(destFile, sourceFile)
-	if err != nil {
+func (bm *BatchManager) copyFile(files vfs.FS, src, dst string, config BatchConfig) error {
+	if err := vfs.CopyFile(files, src, dst); err != nil {
 		return err
 	}
 
 	// Preserve permissions and timestamps
 	if config.PreservePermissions {
-		sourceInfo, err := os.Stat(src)
+		sourceInfo, err := files.Stat(src)
 		if err == nil {
-			os.Chmod(dst, sourceInfo.Mode())
+			files.Chmod(dst, sourceInfo.Mode())
 		}
 	}
 
 	if config.PreserveTimestamps {
-		sourceInfo, err := os.Stat(src)
+		sourceInfo, err := files.Stat(src)
 		if err == nil {
-			os.Chtimes(dst, sourceInfo.ModTime(), sourceInfo.ModTime())
+			files.Chtimes(dst, sourceInfo.ModTime(), sourceInfo.ModTime())
 		}
 	}
 
 	return nil
 }
 
-func (bm *BatchManager) copyDirectory(src, dst string, config BatchConfig) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+func (bm *BatchManager) copyDirectory(files vfs.FS, src, dst string, config BatchConfig) error {
+	return vfs.Walk(files, src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -631,9 +664,9 @@ func (bm *BatchManager) copyDirectory(src, dst string, config BatchConfig) error
 		dstPath := filepath.Join(dst, relPath)
 
 		if info.IsDir() {
-			return os.MkdirAll(dstPath, info.Mode())
+			return files.MkdirAll(dstPath, info.Mode())
 		} else {
-			return bm.copyFile(path, dstPath, config)
+			return bm.copyFile(files, path, dstPath, config)
 		}
 	})
 }
@@ -656,20 +689,18 @@ func (bm *BatchManager) shouldExclude(path string, patterns []string) bool {
 	return false
 }
 
-func (bm *BatchManager) triggerEvent(event BatchEvent) {
-	bm.mutex.RLock()
-	callbacks := bm.eventCallbacks[event.Type]
-	bm.mutex.RUnlock()
-
-	for _, callback := range callbacks {
-		go callback(event) // Run callbacks asynchronously
-	}
+// SetEventBus sets the bus batch events are published on
+func (bm *BatchManager) SetEventBus(bus *events.Bus) {
+	bm.events = bus
 }
 
-// AddEventCallback adds a callback for batch events
-func (bm *BatchManager) AddEventCallback(eventType string, callback BatchEventCallback) {
-	bm.mutex.Lock()
-	defer bm.mutex.Unlock()
+// publish announces a batch event on the event bus
+func (bm *BatchManager) publish(topic events.Topic, message string, event BatchEvent) {
+	bm.events.Publish(events.New(topic, message, event))
+}
 
-	bm.eventCallbacks[eventType] = append(bm.eventCallbacks[eventType], callback)
+// publishOperation announces an operation event, copying the operation since the job keeps changing it
+func (bm *BatchManager) publishOperation(topic events.Topic, message string, job *BatchJob, operation *BatchOperation) {
+	snapshot := *operation
+	bm.publish(topic, message, BatchEvent{JobID: job.ID, JobName: job.Name, Operation: &snapshot})
 }
@@ -2,7 +2,8 @@
  * Interactive File Browser
  *
  * Provides an interactive file browser with arrow key navigation,
- * file selection, and preview capabilities.
+ * file selection, and preview capabilities. Callers can supply their own
+ * listing and commands on the selected item, as the disk usage view does.
  *
  * Author: KleaSCM
  * Email: KleaSCM@gmail.com
@@ -22,6 +23,8 @@ import (
 
 	"github.com/chzyer/readline"
 	"github.com/fatih/color"
+
+	"ena/internal/output"
 )
 
 // FileItem represents a file or directory in the browser
@@ -32,6 +35,22 @@ type FileItem struct {
 	Size     int64
 	ModTime  time.Time
 	IsHidden bool
+	Detail   string // shown before the name in place of the size, when set
+}
+
+// Action is a command the user can run on the selected item
+type Action struct {
+	Key   string
+	Label string
+	Run   func(fb *FileBrowser, item FileItem) (string, error) // returns a status message
+}
+
+// Options changes what a browser lists and what it can do
+type Options struct {
+	Title   string                                // header line
+	Root    string                                // the browser does not go above this directory
+	List    func(path string) ([]FileItem, error) // items of a directory, in display order
+	Actions []Action                              // commands besides navigation
 }
 
 // FileBrowser handles interactive file browsing
@@ -41,12 +60,23 @@ type FileBrowser struct {
 	selectedIndex int
 	scrollOffset  int
 	maxItems      int
+	options       Options
+	status        string
 	rl            *readline.Instance
 }
 
 // NewFileBrowser creates a new file browser instance
 func NewFileBrowser(startPath string) (*FileBrowser, error) {
+	return NewFileBrowserWithOptions(startPath, Options{})
+}
+
+// NewFileBrowserWithOptions creates a file browser with its own listing and
+// actions, such as a disk usage view sorted by size
+func NewFileBrowserWithOptions(startPath string, options Options) (*FileBrowser, error) {
 	// Initialize file browser
+	if options.Title == "" {
+		options.Title = "🌸 Ena File Browser 🌸"
+	}
 	if startPath == "" {
 		var err error
 		startPath, err = os.Getwd()
@@ -60,6 +90,7 @@ func NewFileBrowser(startPath string) (*FileBrowser, error) {
 		selectedIndex: 0,
 		scrollOffset:  0,
 		maxItems:      20, // Show 20 items at a time
+		options:       options,
 	}
 
 	// Initialize readline for keyboard input
@@ -83,7 +114,7 @@ func NewFileBrowser(startPath string) (*FileBrowser, error) {
 
 	rl, err := readline.NewEx(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize readline: %v", err)
+		return nil, fmt.Errorf("failed to initialize readline: %w", err)
 	}
 
 	fb.rl = rl
@@ -95,7 +126,7 @@ func (fb *FileBrowser) Start() (string, error) {
 	// Start interactive file browsing
 	for {
 		if err := fb.loadDirectory(); err != nil {
-			return "", fmt.Errorf("failed to load directory: %v", err)
+			return "", fmt.Errorf("failed to load directory: %w", err)
 		}
 
 		fb.displayBrowser()
@@ -132,7 +163,7 @@ func (fb *FileBrowser) Start() (string, error) {
 		case "h", "H":
 			// Go to parent directory
 			parent := filepath.Dir(fb.currentPath)
-			if parent != fb.currentPath {
+			if parent != fb.currentPath && fb.currentPath != fb.options.Root {
 				fb.currentPath = parent
 				fb.selectedIndex = 0
 				fb.scrollOffset = 0
@@ -159,13 +190,66 @@ func (fb *FileBrowser) Start() (string, error) {
 		case "f", "F":
 			// Toggle hidden files
 			fb.toggleHiddenFiles()
+		default:
+			fb.runAction(line)
 		}
 	}
 }
 
+// runAction runs the action bound to key on the selected item
+func (fb *FileBrowser) runAction(key string) {
+	if fb.selectedIndex >= len(fb.items) {
+		return
+	}
+	item := fb.items[fb.selectedIndex]
+	if strings.HasPrefix(item.Name, "..") {
+		return
+	}
+
+	for _, action := range fb.options.Actions {
+		if action.Key != key {
+			continue
+		}
+		message, err := action.Run(fb, item)
+		if err != nil {
+			fb.status = fmt.Sprintf("❌ %v", err)
+		} else {
+			fb.status = message
+		}
+		return
+	}
+}
+
+// Confirm asks a yes/no question below the listing
+func (fb *FileBrowser) Confirm(question string) bool {
+	fb.rl.SetPrompt(question + " (y/N): ")
+	defer fb.rl.SetPrompt("")
+
+	line, err := fb.rl.Readline()
+	if err != nil {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
 // loadDirectory loads the current directory contents
 func (fb *FileBrowser) loadDirectory() error {
 	// Load directory contents
+	if fb.options.List != nil {
+		items, err := fb.options.List(fb.currentPath)
+		if err != nil {
+			return err
+		}
+		fb.items = make([]FileItem, 0, len(items)+1)
+		if fb.currentPath != fb.options.Root {
+			fb.items = append(fb.items, FileItem{Name: ".. (parent)", Path: filepath.Dir(fb.currentPath), IsDir: true})
+		}
+		fb.items = append(fb.items, items...)
+		fb.clampSelection()
+		return nil
+	}
+
 	entries, err := os.ReadDir(fb.currentPath)
 	if err != nil {
 		return err
@@ -174,7 +258,7 @@ func (fb *FileBrowser) loadDirectory() error {
 	fb.items = make([]FileItem, 0)
 
 	// Add parent directory if not at root
-	if fb.currentPath != "/" && fb.currentPath != "" {
+	if fb.currentPath != "/" && fb.currentPath != "" && fb.currentPath != fb.options.Root {
 		parent := filepath.Dir(fb.currentPath)
 		fb.items = append(fb.items, FileItem{
 			Name:  ".. (parent)",
@@ -227,15 +311,21 @@ func (fb *FileBrowser) loadDirectory() error {
 		return strings.ToLower(fb.items[i].Name) < strings.ToLower(fb.items[j].Name)
 	})
 
-	// Adjust selected index if out of bounds
+	fb.clampSelection()
+	return nil
+}
+
+// clampSelection keeps the selection within the items, as after a deletion
+func (fb *FileBrowser) clampSelection() {
 	if fb.selectedIndex >= len(fb.items) {
 		fb.selectedIndex = len(fb.items) - 1
 		if fb.selectedIndex < 0 {
 			fb.selectedIndex = 0
 		}
 	}
-
-	return nil
+	if fb.scrollOffset > fb.selectedIndex {
+		fb.scrollOffset = fb.selectedIndex
+	}
 }
 
 // displayBrowser displays the file browser interface
@@ -244,10 +334,14 @@ func (fb *FileBrowser) displayBrowser() {
 	fmt.Print("\033[2J\033[H")
 
 	// Header
-	color.New(color.FgMagenta, color.Bold).Println("🌸 Ena File Browser 🌸")
-	color.New(color.FgCyan).Printf("📁 %s\n", fb.currentPath)
+	color.New(color.FgMagenta, color.Bold).Println(fb.options.Title)
+	color.New(color.FgCyan).Printf(output.Prose("📁 %s\n"), fb.currentPath)
 	color.New(color.FgYellow).Printf("Items: %d | Selected: %d\n", len(fb.items), fb.selectedIndex+1)
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if fb.status != "" {
+		fmt.Println(fb.status)
+		fb.status = ""
+	}
+	output.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 
 	// Display items
 	start := fb.scrollOffset
@@ -262,16 +356,20 @@ func (fb *FileBrowser) displayBrowser() {
 
 		// Selection indicator
 		if isSelected {
-			color.New(color.FgYellow, color.Bold).Print("▶ ")
+			color.New(color.FgYellow, color.Bold).Print(output.Prose("▶ "))
 		} else {
 			fmt.Print("  ")
 		}
 
+		if item.Detail != "" {
+			fmt.Print(item.Detail + " ")
+		}
+
 		// File type indicator
 		if item.IsDir {
-			color.New(color.FgBlue).Print("📁 ")
+			color.New(color.FgBlue).Print(output.Prose("📁 "))
 		} else {
-			color.New(color.FgGreen).Print("📄 ")
+			color.New(color.FgGreen).Print(output.Prose("📄 "))
 		}
 
 		// Name
@@ -282,7 +380,7 @@ func (fb *FileBrowser) displayBrowser() {
 		}
 
 		// Size (for files)
-		if !item.IsDir && item.Size > 0 {
+		if item.Detail == "" && !item.IsDir && item.Size > 0 {
 			fmt.Printf(" (%s)", fb.formatSize(item.Size))
 		}
 
@@ -295,10 +393,17 @@ func (fb *FileBrowser) displayBrowser() {
 	}
 
 	// Footer
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	output.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	color.New(color.FgCyan).Println("Controls:")
 	color.New(color.FgWhite).Print("  ↑/k: Up  ↓/j: Down  Enter: Select  h: Parent  r: Refresh  f: Toggle hidden  q: Quit")
 	fmt.Println()
+	if len(fb.options.Actions) > 0 {
+		var labels []string
+		for _, action := range fb.options.Actions {
+			labels = append(labels, fmt.Sprintf("%s: %s", action.Key, action.Label))
+		}
+		color.New(color.FgWhite).Println("  " + strings.Join(labels, "  "))
+	}
 }
 
 // formatSize formats file size in human readable format
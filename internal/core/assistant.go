@@ -13,35 +13,51 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"ena/internal/app"
+	"ena/internal/fault"
 	"ena/internal/health"
 	"ena/internal/hooks"
+	"ena/internal/output"
 )
 
+// ErrForwardUnavailable reports that a forwarder could not reach its backend
+var ErrForwardUnavailable = fault.Unavailable("forwarding target unavailable")
+
+// CommandForwarder executes commands somewhere other than this process, e.g. a running daemon
+type CommandForwarder interface {
+	ForwardCommand(command string, args []string) (string, error)
+}
+
 // Assistant represents the main virtual assistant instance
 type Assistant struct {
 	Name        string
 	Version     string
+	App         *app.App // the managers this assistant works with
 	SystemHooks *hooks.SystemHooks
 	Health      *health.SystemHealth
+	Forwarder   CommandForwarder
 	IsRunning   bool
 	StartTime   time.Time
 }
 
-// NewAssistant creates a new instance of the Ena virtual assistant
-func NewAssistant() *Assistant {
+// NewAssistant creates a new instance of the Ena virtual assistant using the
+// managers of services
+func NewAssistant(services *app.App) *Assistant {
 	// Initialize Ena's core components with love and care ✨
 	assistant := &Assistant{
 		Name:      "Ena",
 		Version:   "1.0.0",
+		App:       services,
 		IsRunning: true,
 		StartTime: time.Now(),
 	}
 
 	// Initialize system hooks for comprehensive system operations
-	assistant.SystemHooks = hooks.NewSystemHooks()
+	assistant.SystemHooks = hooks.NewSystemHooks(services)
 
 	// Add system health monitoring capabilities
 	assistant.Health = health.NewSystemHealth()
@@ -71,6 +87,23 @@ func (a *Assistant) GetStatus() map[string]interface{} {
 
 // ProcessCommand handles incoming commands and delegates to appropriate handlers
 func (a *Assistant) ProcessCommand(command string, args []string) (string, error) {
+	// Hand the command to a running daemon when one is attached; policy
+	// overrides only hold in this process
+	if a.Forwarder != nil && !isLocalCommand(command, args) && !a.App.Policy.Overridden() {
+		result, err := a.Forwarder.ForwardCommand(command, args)
+		if !errors.Is(err, ErrForwardUnavailable) {
+			return result, err
+		}
+
+		// Daemon went away - carry on in-process
+		a.Forwarder = nil
+	}
+
+	return a.processLocally(command, args)
+}
+
+// processLocally routes a command to the in-process handlers
+func (a *Assistant) processLocally(command string, args []string) (string, error) {
 	// Command processing logic - route commands to appropriate handlers
 	switch command {
 	case "file":
@@ -124,9 +157,25 @@ func (a *Assistant) ProcessCommand(command string, args []string) (string, error
 	}
 }
 
+// isLocalCommand reports whether a command needs this process's terminal and must not be forwarded
+func isLocalCommand(command string, args []string) bool {
+	switch command {
+	case "browse", "download", "multi", "pause":
+		// Interactive browser and progress bar demos draw on the caller's terminal
+		return true
+	case "terminal":
+		// Shell commands and cd act on the caller's working directory
+		return true
+	case "folder":
+		// Safe-mode folder deletion prompts for confirmation on stdin
+		return len(args) > 0 && args[0] == "delete"
+	}
+	return false
+}
+
 // Shutdown gracefully shuts down the assistant
 func (a *Assistant) Shutdown() {
 	// Gracefully shut down the assistant
 	a.IsRunning = false
-	fmt.Println("Ena says goodbye! ✨ (╹◡╹)♡")
+	output.Printf("Ena says goodbye! ✨ (╹◡╹)♡\n")
 }
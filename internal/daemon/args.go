@@ -0,0 +1,103 @@
+/**
+ * Forwarded Arguments
+ *
+ * The daemon runs in its own working directory, so before a command is
+ * forwarded the client turns the relative paths in it into absolute ones,
+ * the way the user's shell sees them.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: args.go
+ * Description: Client-side path resolution for forwarded commands
+ */
+
+package daemon
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// absoluteArgs returns a command's arguments with every path made absolute;
+// commands that default to the working directory are given it explicitly
+func absoluteArgs(command string, args []string) []string {
+	resolved := append([]string{}, args...)
+	operation := ""
+	if len(args) > 0 {
+		operation = args[0]
+	}
+
+	switch command {
+	case "file":
+		switch operation {
+		case "copy", "move":
+			absolute(resolved, 1, 2)
+		case "delete":
+			absolutePaths(resolved, 1)
+		default:
+			// write is followed by content, not paths
+			absolute(resolved, 1)
+		}
+	case "folder", "search":
+		absolute(resolved, 1)
+	case "delete":
+		absolutePaths(resolved, 0)
+	case "backup":
+		if operation == "create" {
+			absolute(resolved, 1)
+		}
+	case "theme":
+		if operation == "load" {
+			absolute(resolved, 1)
+		}
+	case "watch":
+		switch operation {
+		case "start":
+			if len(resolved) == 1 {
+				resolved = append(resolved, ".")
+			}
+			absolutePaths(resolved, 1)
+		case "add", "remove":
+			absolute(resolved, 1)
+		}
+	case "pattern":
+		if operation == "find" {
+			if len(resolved) == 2 {
+				resolved = append(resolved, ".")
+			}
+			absolutePaths(resolved, 2)
+		}
+	}
+	return resolved
+}
+
+// absolute makes the arguments at the given positions absolute, where present
+func absolute(args []string, positions ...int) {
+	for _, i := range positions {
+		if i < len(args) {
+			args[i] = absolutePath(args[i])
+		}
+	}
+}
+
+// absolutePaths makes every argument from start on absolute, skipping flags
+func absolutePaths(args []string, start int) {
+	for i := start; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			args[i] = absolutePath(args[i])
+		}
+	}
+}
+
+// absolutePath resolves path against the working directory, leaving it alone
+// when that fails
+func absolutePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
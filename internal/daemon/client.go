@@ -0,0 +1,166 @@
+/**
+ * Ena Daemon Client
+ *
+ * Talks to a running Ena daemon over its Unix socket. The client doubles as
+ * the assistant's command forwarder so CLI commands run inside the daemon
+ * whenever one is available.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: client.go
+ * Description: JSON-RPC client for the Ena daemon and command forwarder
+ */
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"ena/internal/batch"
+	"ena/internal/core"
+)
+
+// dialTimeout bounds how long the CLI waits before falling back to in-process execution
+const dialTimeout = 500 * time.Millisecond
+
+// Client is a connection to a running Ena daemon
+type Client struct {
+	rpc        *rpc.Client
+	socketPath string
+}
+
+// Dial connects to the daemon listening on socketPath
+func Dial(socketPath string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		rpc:        jsonrpc.NewClient(conn),
+		socketPath: socketPath,
+	}, nil
+}
+
+// Connect dials the default socket, returning nil when no daemon is running
+func Connect() *Client {
+	client, err := Dial(SocketPath())
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// Close closes the connection to the daemon
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// SocketPath returns the socket this client is connected to
+func (c *Client) SocketPath() string {
+	return c.socketPath
+}
+
+// call invokes a daemon method, marking transport failures as unavailable
+func (c *Client) call(method string, args interface{}, reply interface{}) error {
+	err := c.rpc.Call(ServiceName+"."+method, args, reply)
+	if err == nil {
+		return nil
+	}
+
+	var serverErr rpc.ServerError
+	if errors.As(err, &serverErr) {
+		// Handlers report classified errors in their reply; anything left is a plain failure
+		return errors.New(string(serverErr))
+	}
+	return fmt.Errorf("%w: %v", core.ErrForwardUnavailable, err)
+}
+
+// ForwardCommand runs a command inside the daemon, implementing core.CommandForwarder
+func (c *Client) ForwardCommand(command string, args []string) (string, error) {
+	var reply CommandReply
+	if err := c.call("ProcessCommand", &CommandArgs{Command: command, Args: absoluteArgs(command, args)}, &reply); err != nil {
+		return "", err
+	}
+	return reply.Output, reply.err()
+}
+
+// Status returns the daemon status
+func (c *Client) Status() (*StatusReply, error) {
+	var reply StatusReply
+	if err := c.call("Status", &Empty{}, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListJobs returns all batch jobs hosted by the daemon
+func (c *Client) ListJobs() ([]*batch.BatchJob, error) {
+	var reply JobsReply
+	if err := c.call("ListJobs", &Empty{}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Jobs, nil
+}
+
+// GetJob returns a single batch job from the daemon
+func (c *Client) GetJob(jobID string) (*batch.BatchJob, error) {
+	var reply JobReply
+	if err := c.call("GetJob", &JobArgs{JobID: jobID}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Job, reply.err()
+}
+
+// CancelJob cancels a running batch job in the daemon
+func (c *Client) CancelJob(jobID string) (*batch.BatchJob, error) {
+	var reply JobReply
+	if err := c.call("CancelJob", &JobArgs{JobID: jobID}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Job, reply.err()
+}
+
+// SubmitJob hands a batch job to the daemon, which runs it in the background
+func (c *Client) SubmitJob(jobType string, sources []string, destination string, config batch.BatchConfig) (*batch.BatchJob, error) {
+	args := &SubmitJobArgs{
+		Type:        jobType,
+		Sources:     sources,
+		Destination: destination,
+		Config:      config,
+	}
+
+	var reply JobReply
+	if err := c.call("SubmitJob", args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Job, reply.err()
+}
+
+// WatcherStatus returns the state of the daemon's file watcher
+func (c *Client) WatcherStatus() (*WatcherReply, error) {
+	var reply WatcherReply
+	if err := c.call("WatcherStatus", &Empty{}, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// BackupStats returns backup statistics from the daemon
+func (c *Client) BackupStats() (map[string]interface{}, error) {
+	var reply StatsReply
+	if err := c.call("BackupStats", &Empty{}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Stats, nil
+}
+
+// Shutdown asks the daemon to stop
+func (c *Client) Shutdown() error {
+	return c.call("Shutdown", &Empty{}, &Empty{})
+}
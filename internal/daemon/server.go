@@ -0,0 +1,391 @@
+/**
+ * Ena Daemon Server
+ *
+ * Keeps a single assistant and all of its managers alive in the background
+ * and exposes them over a JSON-RPC API on a local Unix socket, so file
+ * watchers, batch jobs and backup cleanup survive between CLI invocations.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: server.go
+ * Description: JSON-RPC Unix-socket server hosting the long-lived Ena managers
+ */
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ena/internal/batch"
+	"ena/internal/core"
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/paths"
+	"ena/internal/watcher"
+)
+
+// ServiceName is the JSON-RPC service name all daemon methods are registered under
+const ServiceName = "Ena"
+
+// Accept errors are retried after a delay that doubles between these bounds
+const (
+	minAcceptDelay = 5 * time.Millisecond
+	maxAcceptDelay = time.Second
+)
+
+// SocketPath returns the Unix socket path used by the daemon
+func SocketPath() string {
+	// Explicit override wins, then the per-user runtime directory
+	if path := os.Getenv("ENA_SOCKET"); path != "" {
+		return path
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "ena.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("ena-%d.sock", os.Getuid()))
+}
+
+// LogPath returns the log file used when the daemon runs in the background
+func LogPath() string {
+	return paths.StateFile(strings.TrimSuffix(filepath.Base(SocketPath()), ".sock") + "-daemon.log")
+}
+
+// Server hosts an assistant and serves it over a Unix socket
+type Server struct {
+	assistant  *core.Assistant
+	socketPath string
+	listener   net.Listener
+	rpcServer  *rpc.Server
+	startTime  time.Time
+	commandMu  sync.Mutex // serialises commands; the hooks expect one caller at a time
+	stopOnce   sync.Once
+	done       chan struct{}
+}
+
+// NewServer creates a daemon server for the given assistant
+func NewServer(assistant *core.Assistant, socketPath string) *Server {
+	return &Server{
+		assistant:  assistant,
+		socketPath: socketPath,
+		rpcServer:  rpc.NewServer(),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start binds the socket and begins serving requests in the background
+func (s *Server) Start() error {
+	// Refuse to start twice, but clean up sockets left behind by a crash
+	if _, err := os.Stat(s.socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", s.socketPath, dialTimeout); err == nil {
+			conn.Close()
+			return fmt.Errorf("Ena daemon is already running on %s", s.socketPath)
+		}
+		if err := os.Remove(s.socketPath); err != nil {
+			return fmt.Errorf("Failed to remove stale socket: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), 0700); err != nil {
+		return fmt.Errorf("Failed to create socket directory: %w", err)
+	}
+
+	if err := s.rpcServer.RegisterName(ServiceName, &Service{server: s}); err != nil {
+		return fmt.Errorf("Failed to register daemon service: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("Failed to listen on %s: %w", s.socketPath, err)
+	}
+
+	// Only the owning user may talk to the daemon
+	if err := os.Chmod(s.socketPath, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("Failed to secure socket: %w", err)
+	}
+
+	s.listener = listener
+	s.startTime = time.Now()
+
+	go s.acceptConnections()
+	return nil
+}
+
+// acceptConnections serves each client connection with a JSON-RPC codec
+func (s *Server) acceptConnections() {
+	delay := minAcceptDelay
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// Failures such as running out of file descriptors last a while, so back off
+			select {
+			case <-s.done:
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxAcceptDelay)
+			continue
+		}
+
+		delay = minAcceptDelay
+		go s.rpcServer.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Done returns a channel that is closed once the server has stopped
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stop closes the socket and stops the watchers; the app shuts down the rest
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+
+		if s.listener != nil {
+			s.listener.Close()
+		}
+		os.Remove(s.socketPath)
+
+		hooks := s.assistant.SystemHooks
+		if hooks.FileWatcher != nil {
+			hooks.FileWatcher.Stop()
+		}
+		if hooks.FileOrganizer != nil {
+			hooks.FileOrganizer.StopWatching()
+		}
+	})
+}
+
+// Empty is used for methods that take or return nothing
+type Empty struct{}
+
+// CommandArgs carries a command to run through Assistant.ProcessCommand
+type CommandArgs struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"` // paths in them are absolute
+}
+
+// Failure carries a handler's error back with its exit code, since net/rpc
+// sends only the message of an error the handler returns
+type Failure struct {
+	Error string `json:"error,omitempty"`
+	Code  int    `json:"code,omitempty"`
+}
+
+// fail records err for the client
+func (f *Failure) fail(err error) {
+	f.Error = err.Error()
+	f.Code = fault.Code(err)
+}
+
+// err rebuilds the handler's error, or returns nil if it succeeded
+func (f *Failure) err() error {
+	if f.Error == "" {
+		return nil
+	}
+	return fault.FromCode(f.Code, f.Error)
+}
+
+// CommandReply carries the output of a processed command
+type CommandReply struct {
+	Output string `json:"output"`
+	Failure
+}
+
+// StatusReply describes the running daemon
+type StatusReply struct {
+	PID         int                    `json:"pid"`
+	SocketPath  string                 `json:"socket_path"`
+	StartTime   time.Time              `json:"start_time"`
+	Uptime      time.Duration          `json:"uptime"`
+	Assistant   map[string]interface{} `json:"assistant"`
+	Watching    bool                   `json:"watching"`
+	JobCount    int                    `json:"job_count"`
+	RunningJobs int                    `json:"running_jobs"`
+	Events      events.BusStats        `json:"events"`
+}
+
+// JobArgs identifies a batch job
+type JobArgs struct {
+	JobID string `json:"job_id"`
+}
+
+// JobReply carries a single batch job
+type JobReply struct {
+	Job *batch.BatchJob `json:"job"`
+	Failure
+}
+
+// JobsReply carries all batch jobs known to the daemon
+type JobsReply struct {
+	Jobs []*batch.BatchJob `json:"jobs"`
+}
+
+// SubmitJobArgs describes a batch job for the daemon to build and run
+type SubmitJobArgs struct {
+	Type        string            `json:"type"` // delete, copy, move
+	Sources     []string          `json:"sources"`
+	Destination string            `json:"destination,omitempty"`
+	Config      batch.BatchConfig `json:"config"`
+}
+
+// WatcherReply describes the daemon's file watcher
+type WatcherReply struct {
+	Running bool                    `json:"running"`
+	Paths   []string                `json:"paths"`
+	Stats   map[string]interface{}  `json:"stats,omitempty"`
+	Metrics *watcher.WatcherMetrics `json:"metrics,omitempty"`
+}
+
+// StatsReply carries a free-form statistics map
+type StatsReply struct {
+	Stats map[string]interface{} `json:"stats"`
+}
+
+// Service implements the JSON-RPC methods exposed by the daemon
+type Service struct {
+	server *Server
+}
+
+// ProcessCommand runs a command through the hosted assistant
+func (svc *Service) ProcessCommand(args *CommandArgs, reply *CommandReply) error {
+	s := svc.server
+	s.commandMu.Lock()
+	defer s.commandMu.Unlock()
+
+	output, err := s.assistant.ProcessCommand(args.Command, args.Args)
+	reply.Output = output
+	if err != nil {
+		reply.fail(err)
+	}
+	return nil
+}
+
+// Status reports daemon and assistant status
+func (svc *Service) Status(args *Empty, reply *StatusReply) error {
+	s := svc.server
+	hooks := s.assistant.SystemHooks
+
+	reply.PID = os.Getpid()
+	reply.SocketPath = s.socketPath
+	reply.StartTime = s.startTime
+	reply.Uptime = time.Since(s.startTime)
+	reply.Assistant = s.assistant.GetStatus()
+	reply.Watching = hooks.FileWatcher != nil && hooks.FileWatcher.IsRunning()
+	reply.Events = s.assistant.App.Events.Stats()
+
+	for _, job := range hooks.BatchManager.ListJobs() {
+		reply.JobCount++
+		if job.Status == "running" {
+			reply.RunningJobs++
+		}
+	}
+
+	return nil
+}
+
+// ListJobs returns all batch jobs hosted by the daemon
+func (svc *Service) ListJobs(args *Empty, reply *JobsReply) error {
+	reply.Jobs = svc.server.assistant.SystemHooks.BatchManager.ListJobs()
+	return nil
+}
+
+// GetJob returns a single batch job
+func (svc *Service) GetJob(args *JobArgs, reply *JobReply) error {
+	job, err := svc.server.assistant.SystemHooks.BatchManager.GetJobStatus(args.JobID)
+	if err != nil {
+		reply.fail(err)
+		return nil
+	}
+
+	reply.Job = job
+	return nil
+}
+
+// CancelJob cancels a running batch job
+func (svc *Service) CancelJob(args *JobArgs, reply *JobReply) error {
+	batchManager := svc.server.assistant.SystemHooks.BatchManager
+	if err := batchManager.CancelJob(args.JobID); err != nil {
+		reply.fail(err)
+		return nil
+	}
+
+	job, err := batchManager.GetJobStatus(args.JobID)
+	if err != nil {
+		reply.fail(err)
+		return nil
+	}
+
+	reply.Job = job
+	return nil
+}
+
+// SubmitJob builds a batch job and runs it in the background
+func (svc *Service) SubmitJob(args *SubmitJobArgs, reply *JobReply) error {
+	batchManager := svc.server.assistant.SystemHooks.BatchManager
+
+	var job *batch.BatchJob
+	var err error
+
+	switch args.Type {
+	case "delete":
+		job, err = batchManager.BatchDelete(args.Sources, args.Config)
+	case "copy":
+		job, err = batchManager.BatchCopy(args.Sources, args.Destination, args.Config)
+	case "move":
+		job, err = batchManager.BatchMove(args.Sources, args.Destination, args.Config)
+	default:
+		err = fault.Invalid("Unknown batch job type: %s", args.Type)
+	}
+	if err != nil {
+		reply.fail(err)
+		return nil
+	}
+
+	go batchManager.ExecuteBatchJob(job.ID)
+
+	reply.Job = job
+	return nil
+}
+
+// WatcherStatus reports the state of the daemon's file watcher
+func (svc *Service) WatcherStatus(args *Empty, reply *WatcherReply) error {
+	fileWatcher := svc.server.assistant.SystemHooks.FileWatcher
+	if fileWatcher == nil {
+		return nil
+	}
+
+	reply.Running = fileWatcher.IsRunning()
+	reply.Paths = fileWatcher.GetWatchedPaths()
+	reply.Stats = fileWatcher.GetStats()
+	reply.Metrics = fileWatcher.GetMetrics()
+	return nil
+}
+
+// BackupStats returns backup engine statistics
+func (svc *Service) BackupStats(args *Empty, reply *StatsReply) error {
+	reply.Stats = svc.server.assistant.SystemHooks.BackupEngine.GetBackupStats()
+	return nil
+}
+
+// Shutdown stops the daemon once the reply has been sent
+func (svc *Service) Shutdown(args *Empty, reply *Empty) error {
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		svc.server.Stop()
+	}()
+	return nil
+}
@@ -0,0 +1,357 @@
+/**
+ * File Diff
+ *
+ * Compares two files line by line and describes the difference as unified
+ * diff hunks, the format of diff -u and git diff. Lines are matched with
+ * Myers' algorithm after trimming what the files share at either end; files
+ * so different that the search would take too long are shown as one
+ * replacement instead. Files holding NUL bytes are treated as binary and only
+ * reported as equal or not.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: diff.go
+ * Description: Line diffs of text files in unified format
+ */
+
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"ena/internal/vfs"
+)
+
+// DefaultContext is how many unchanged lines surround each change, as in diff -u
+const DefaultContext = 3
+
+// binarySniffLength is how much of a file is checked for NUL bytes, as git does
+const binarySniffLength = 8000
+
+// maxEdits bounds the line diff search; beyond it the differing middle of the
+// files is shown as one replacement
+const maxEdits = 4000
+
+// noNewline marks a last line without a line break
+const noNewline = `\ No newline at end of file`
+
+// Hunk is one block of changes with the unchanged lines around it. Each line
+// starts with ' ', '-' or '+'.
+type Hunk struct {
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Lines    []string `json:"lines"`
+}
+
+// Header returns the hunk's @@ line
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+// hunkRange formats a start and length, leaving out a length of one as diff does
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// FileDiff is the difference between an old and a new file
+type FileDiff struct {
+	Old        string     `json:"old"`
+	New        string     `json:"new"`
+	OldModTime *time.Time `json:"old_mod_time,omitempty"`
+	NewModTime *time.Time `json:"new_mod_time,omitempty"`
+	Identical  bool       `json:"identical"`
+	Binary     bool       `json:"binary"`
+	Added      int        `json:"added"`
+	Removed    int        `json:"removed"`
+	Hunks      []Hunk     `json:"hunks,omitempty"`
+}
+
+// Unified returns the diff as the lines of a unified diff, without line breaks
+func (d *FileDiff) Unified() []string {
+	if d.Identical {
+		return nil
+	}
+	if d.Binary {
+		return []string{fmt.Sprintf("Binary files %s and %s differ", d.Old, d.New)}
+	}
+
+	lines := []string{
+		"--- " + label(d.Old, d.OldModTime),
+		"+++ " + label(d.New, d.NewModTime),
+	}
+	for _, hunk := range d.Hunks {
+		lines = append(lines, hunk.Header())
+		lines = append(lines, hunk.Lines...)
+	}
+	return lines
+}
+
+// label formats a file header, with its modification time when known
+func label(name string, modTime *time.Time) string {
+	if modTime == nil {
+		return name
+	}
+	return name + "\t" + modTime.Format("2006-01-02 15:04:05.000000000 -0700")
+}
+
+// Files compares the files at oldPath and newPath
+func Files(fsys vfs.FS, oldPath, newPath string, context int) (*FileDiff, error) {
+	oldInfo, err := fsys.Stat(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", oldPath, err)
+	}
+	newInfo, err := fsys.Stat(newPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", newPath, err)
+	}
+	if oldInfo.IsDir() || newInfo.IsDir() {
+		return nil, fmt.Errorf("Failed to compare %s and %s: not both files", oldPath, newPath)
+	}
+
+	oldData, err := fsys.ReadFile(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", oldPath, err)
+	}
+	newData, err := fsys.ReadFile(newPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", newPath, err)
+	}
+
+	diff := Bytes(oldPath, oldData, newPath, newData, context)
+	oldModTime, newModTime := oldInfo.ModTime(), newInfo.ModTime()
+	diff.OldModTime, diff.NewModTime = &oldModTime, &newModTime
+	return diff, nil
+}
+
+// Bytes compares two contents, naming them oldName and newName
+func Bytes(oldName string, oldData []byte, newName string, newData []byte, context int) *FileDiff {
+	diff := &FileDiff{Old: oldName, New: newName}
+	if bytes.Equal(oldData, newData) {
+		diff.Identical = true
+		return diff
+	}
+	if isBinary(oldData) || isBinary(newData) {
+		diff.Binary = true
+		return diff
+	}
+
+	script := lineDiff(splitLines(oldData), splitLines(newData))
+	for _, e := range script {
+		switch e.kind {
+		case '-':
+			diff.Removed++
+		case '+':
+			diff.Added++
+		}
+	}
+	diff.Hunks = hunks(script, context)
+	return diff
+}
+
+// isBinary reports whether data looks like a binary file
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binarySniffLength)], 0) >= 0
+}
+
+// splitLines splits data into lines, each keeping its line break
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edit is one step of an edit script: an unchanged (' '), removed ('-') or
+// added ('+') line, with how many old and new lines come before it
+type edit struct {
+	kind byte
+	old  int
+	new  int
+	text string
+}
+
+// lineDiff returns an edit script turning a into b
+func lineDiff(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	script := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		script = append(script, edit{' ', i, i, a[i]})
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	middle, ok := myers(middleA, middleB)
+	if !ok {
+		middle = replaceAll(middleA, middleB)
+	}
+	for _, e := range middle {
+		e.old += prefix
+		e.new += prefix
+		script = append(script, e)
+	}
+
+	for i := 0; i < suffix; i++ {
+		old, new := len(a)-suffix+i, len(b)-suffix+i
+		script = append(script, edit{' ', old, new, a[old]})
+	}
+	return script
+}
+
+// replaceAll returns an edit script removing all of a and adding all of b
+func replaceAll(a, b []string) []edit {
+	script := make([]edit, 0, len(a)+len(b))
+	for i, line := range a {
+		script = append(script, edit{'-', i, 0, line})
+	}
+	for i, line := range b {
+		script = append(script, edit{'+', len(a), i, line})
+	}
+	return script
+}
+
+// myers finds a shortest edit script turning a into b, giving up once it
+// would take more than maxEdits removals and additions
+func myers(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] holds the furthest x reached on each diagonal -d..d after d edits
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace), true
+			}
+		}
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+	}
+	return nil, false
+}
+
+// backtrack walks the trace from the end of both files back to their start
+func backtrack(a, b []string, trace [][]int) []edit {
+	x, y := len(a), len(b)
+	var reversed []edit
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		k := x - y
+		prevX, prevY := 0, 0
+		if d > 0 {
+			previous := trace[d-1]
+			prevK := k - 1
+			if k == -d || (k != d && previous[k-1+d-1] < previous[k+1+d-1]) {
+				prevK = k + 1
+			}
+			prevX = previous[prevK+d-1]
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, edit{' ', x, y, a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, edit{'+', x, y, b[y]})
+		} else {
+			x--
+			reversed = append(reversed, edit{'-', x, y, a[x]})
+		}
+	}
+
+	script := make([]edit, len(reversed))
+	for i, e := range reversed {
+		script[len(reversed)-1-i] = e
+	}
+	return script
+}
+
+// hunks groups the changes of a script with context unchanged lines around
+// them, merging changes whose context would touch
+func hunks(script []edit, context int) []Hunk {
+	var result []Hunk
+	for i := 0; i < len(script); {
+		first := i
+		for first < len(script) && script[first].kind == ' ' {
+			first++
+		}
+		if first == len(script) {
+			break
+		}
+
+		last := first
+		for j := first; j < len(script) && j-last <= 2*context; j++ {
+			if script[j].kind != ' ' {
+				last = j
+			}
+		}
+
+		start := max(first-context, i)
+		end := min(last+context+1, len(script))
+		result = append(result, newHunk(script[start:end]))
+		i = end
+	}
+	return result
+}
+
+// newHunk builds a hunk from a stretch of an edit script
+func newHunk(script []edit) Hunk {
+	hunk := Hunk{OldStart: script[0].old, NewStart: script[0].new}
+	for _, e := range script {
+		if e.kind != '+' {
+			hunk.OldLines++
+		}
+		if e.kind != '-' {
+			hunk.NewLines++
+		}
+		hunk.Lines = append(hunk.Lines, string(e.kind)+strings.TrimSuffix(e.text, "\n"))
+		if !strings.HasSuffix(e.text, "\n") {
+			hunk.Lines = append(hunk.Lines, noNewline)
+		}
+	}
+
+	// Ranges count from 1, except that an empty one names the line before it
+	if hunk.OldLines > 0 {
+		hunk.OldStart++
+	}
+	if hunk.NewLines > 0 {
+		hunk.NewStart++
+	}
+	return hunk
+}
@@ -0,0 +1,230 @@
+/**
+ * Directory Diff
+ *
+ * Compares two directory trees and lists what was added, removed or changed
+ * between them, diff -rq style. A directory only on one side is listed once,
+ * not with everything in it. Files count as changed when their size or
+ * modification time differ, or, when asked, only when their SHA-256 hashes
+ * differ, which ignores touched but identical files at the cost of reading
+ * every same-sized pair.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: dirs.go
+ * Description: Recursive directory tree comparison
+ */
+
+package diff
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ena/internal/vfs"
+)
+
+// Change is how an entry differs between the old and the new tree
+type Change string
+
+const (
+	ChangeAdded    Change = "added"
+	ChangeRemoved  Change = "removed"
+	ChangeModified Change = "modified"
+	ChangeType     Change = "type" // a file became a directory or the other way round
+)
+
+// Entry is one path that differs, relative to both trees
+type Entry struct {
+	Path       string     `json:"path"`
+	Change     Change     `json:"change"`
+	IsDir      bool       `json:"is_dir"`
+	OldSize    int64      `json:"old_size,omitempty"`
+	NewSize    int64      `json:"new_size,omitempty"`
+	OldModTime *time.Time `json:"old_mod_time,omitempty"`
+	NewModTime *time.Time `json:"new_mod_time,omitempty"`
+}
+
+// DirDiff is the difference between an old and a new directory tree
+type DirDiff struct {
+	Old       string        `json:"old"`
+	New       string        `json:"new"`
+	Compare   string        `json:"compare"` // "size+mtime" or "sha256"
+	Entries   []Entry       `json:"entries"`
+	Added     int           `json:"added"`
+	Removed   int           `json:"removed"`
+	Modified  int           `json:"modified"`
+	Unchanged int           `json:"unchanged"` // files found equal
+	Errors    []string      `json:"errors,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Dirs compares the trees under oldRoot and newRoot, by hash when hash is set
+// and by size and modification time otherwise
+func Dirs(fsys vfs.FS, oldRoot, newRoot string, hash bool) (*DirDiff, error) {
+	startTime := time.Now()
+	result := &DirDiff{Old: oldRoot, New: newRoot, Compare: "size+mtime", Entries: []Entry{}}
+	if hash {
+		result.Compare = "sha256"
+	}
+
+	oldTree, err := readTree(fsys, oldRoot, result)
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := readTree(fsys, newRoot, result)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(oldTree)+len(newTree))
+	for path := range oldTree {
+		paths = append(paths, path)
+	}
+	for path := range newTree {
+		if _, ok := oldTree[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	// A directory on one side only stands for everything beneath it
+	covered := make(map[string]bool)
+	for _, path := range paths {
+		if coveredBy(path, covered) {
+			continue
+		}
+
+		oldInfo, inOld := oldTree[path]
+		newInfo, inNew := newTree[path]
+		entry := Entry{Path: path}
+		if inOld {
+			entry.IsDir = oldInfo.IsDir()
+			modTime := oldInfo.ModTime()
+			entry.OldModTime = &modTime
+			if !oldInfo.IsDir() {
+				entry.OldSize = oldInfo.Size()
+			}
+		}
+		if inNew {
+			entry.IsDir = newInfo.IsDir()
+			modTime := newInfo.ModTime()
+			entry.NewModTime = &modTime
+			if !newInfo.IsDir() {
+				entry.NewSize = newInfo.Size()
+			}
+		}
+
+		switch {
+		case !inNew:
+			entry.Change = ChangeRemoved
+			result.Removed++
+		case !inOld:
+			entry.Change = ChangeAdded
+			result.Added++
+		case oldInfo.Mode().Type() != newInfo.Mode().Type():
+			entry.Change = ChangeType
+			result.Modified++
+		case newInfo.IsDir():
+			continue
+		default:
+			same, err := sameFile(fsys, filepath.Join(oldRoot, path), filepath.Join(newRoot, path), oldInfo, newInfo, hash)
+			if err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			if same {
+				result.Unchanged++
+				continue
+			}
+			entry.Change = ChangeModified
+			result.Modified++
+		}
+
+		if entry.Change == ChangeType || (entry.Change != ChangeModified && entry.IsDir) {
+			covered[path] = true
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// coveredBy reports whether one of path's parent directories is in covered
+func coveredBy(path string, covered map[string]bool) bool {
+	for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+		if covered[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// readTree lists every path under root, relative to it, noting the ones it
+// could not read in result
+func readTree(fsys vfs.FS, root string, result *DirDiff) (map[string]os.FileInfo, error) {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("Failed to compare %s: not a directory", root)
+	}
+
+	tree := make(map[string]os.FileInfo)
+	vfs.Walk(fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil {
+			tree[rel] = info
+		}
+		return nil
+	})
+	return tree, nil
+}
+
+// sameFile reports whether two files present on both sides are unchanged
+func sameFile(fsys vfs.FS, oldPath, newPath string, oldInfo, newInfo os.FileInfo, hash bool) (bool, error) {
+	if oldInfo.Size() != newInfo.Size() {
+		return false, nil
+	}
+	if !hash {
+		return oldInfo.ModTime().Equal(newInfo.ModTime()), nil
+	}
+
+	oldSum, err := hashFile(fsys, oldPath)
+	if err != nil {
+		return false, err
+	}
+	newSum, err := hashFile(fsys, newPath)
+	if err != nil {
+		return false, err
+	}
+	return oldSum == newSum, nil
+}
+
+// hashFile returns the SHA-256 of a file's contents
+func hashFile(fsys vfs.FS, path string) (string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,421 @@
+/**
+ * Disk Usage
+ *
+ * Walks a directory tree concurrently and adds up how much space each
+ * directory takes, ncdu style: total size, space allocated on disk, file and
+ * directory counts and the newest change beneath it. The scan also breaks the
+ * tree down by file type and by age. Entries can be trashed or deleted from
+ * the scanned tree, which keeps its totals right, and every removal is
+ * recorded so it can be undone.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: diskusage.go
+ * Description: Concurrent disk usage scanning, breakdowns and undoable removal
+ */
+
+package diskusage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ena/internal/audit"
+	"ena/internal/trash"
+	"ena/internal/undo"
+	"ena/internal/vfs"
+)
+
+// Node is a file or directory with the totals of everything beneath it
+type Node struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`      // apparent size
+	DiskSize int64     `json:"disk_size"` // space allocated on disk
+	Files    int       `json:"files"`
+	Dirs     int       `json:"dirs"`
+	ModTime  time.Time `json:"mod_time"` // newest change beneath
+	Children []*Node   `json:"children,omitempty"`
+	Error    string    `json:"error,omitempty"` // why the directory could not be read
+
+	parent *Node
+}
+
+// TypeUsage is the space taken by one file extension
+type TypeUsage struct {
+	Type  string `json:"type"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// AgeUsage is the space taken by files last changed within one age range
+type AgeUsage struct {
+	Age   string `json:"age"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// Report is the outcome of a scan
+type Report struct {
+	Root     *Node         `json:"root"`
+	Types    []TypeUsage   `json:"types"`
+	Ages     []AgeUsage    `json:"ages"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Options controls a scan
+type Options struct {
+	OneFileSystem bool // stay on the root's filesystem, like du -x
+	Workers       int  // directories read at once; 0 means two per CPU
+}
+
+// ageRanges are the buckets of the age breakdown, youngest first
+var ageRanges = []struct {
+	label string
+	limit time.Duration
+}{
+	{"< 1 day", 24 * time.Hour},
+	{"< 1 week", 7 * 24 * time.Hour},
+	{"< 1 month", 30 * 24 * time.Hour},
+	{"< 1 year", 365 * 24 * time.Hour},
+	{"older", 0},
+}
+
+// Analyzer scans directory trees and removes entries from them
+type Analyzer struct {
+	fs   vfs.FS
+	can  *trash.Can
+	undo *undo.UndoManager
+}
+
+// NewAnalyzer creates an analyzer that removes through fs, trashes into can
+// and records removals with undoManager
+func NewAnalyzer(fs vfs.FS, can *trash.Can, undoManager *undo.UndoManager) *Analyzer {
+	return &Analyzer{fs: fs, can: can, undo: undoManager}
+}
+
+// scan is the state shared by the goroutines of one scan
+type scan struct {
+	fs      vfs.FS
+	options Options
+	device  uint64
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	seen    map[[2]uint64]bool
+	types   map[string]*TypeUsage
+	ages    []AgeUsage
+	errors  int
+	now     time.Time
+}
+
+// Scan walks the tree under root and adds up its usage
+func (a *Analyzer) Scan(root string, options Options) (*Report, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to scan %s: %w", root, err)
+	}
+	info, err := a.fs.Lstat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to scan %s: %w", root, err)
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = 2 * runtime.NumCPU()
+	}
+
+	startTime := time.Now()
+	s := &scan{
+		fs:      a.fs,
+		options: options,
+		slots:   make(chan struct{}, workers),
+		seen:    make(map[[2]uint64]bool),
+		types:   make(map[string]*TypeUsage),
+		ages:    make([]AgeUsage, len(ageRanges)),
+		now:     startTime,
+	}
+	for i, r := range ageRanges {
+		s.ages[i].Age = r.label
+	}
+	if sys, ok := vfs.Sys(info); ok {
+		s.device = sys.Device
+	}
+
+	rootNode := &Node{Name: filepath.Base(abs), Path: abs, IsDir: info.IsDir(), ModTime: info.ModTime()}
+	if info.IsDir() {
+		s.wg.Add(1)
+		s.readDir(rootNode)
+		s.wg.Wait()
+		rootNode.total()
+	} else {
+		s.addFile(rootNode, info)
+	}
+
+	report := &Report{Root: rootNode, Ages: s.ages, Errors: s.errors}
+	for _, usage := range s.types {
+		report.Types = append(report.Types, *usage)
+	}
+	sort.Slice(report.Types, func(i, j int) bool {
+		if report.Types[i].Size != report.Types[j].Size {
+			return report.Types[i].Size > report.Types[j].Size
+		}
+		return report.Types[i].Type < report.Types[j].Type
+	})
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
+
+// readDir reads one directory, handing subdirectories to other goroutines
+// while there are free slots and reading them itself otherwise
+func (s *scan) readDir(node *Node) {
+	defer s.wg.Done()
+
+	entries, err := s.fs.ReadDir(node.Path)
+	if err != nil {
+		node.Error = err.Error()
+		s.mutex.Lock()
+		s.errors++
+		s.mutex.Unlock()
+		return
+	}
+
+	node.Children = make([]*Node, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(node.Path, entry.Name())
+		info, err := s.fs.Lstat(path)
+		if err != nil {
+			s.mutex.Lock()
+			s.errors++
+			s.mutex.Unlock()
+			continue
+		}
+
+		child := &Node{Name: entry.Name(), Path: path, IsDir: info.IsDir(), ModTime: info.ModTime(), parent: node}
+		node.Children = append(node.Children, child)
+
+		if !info.IsDir() {
+			s.addFile(child, info)
+			continue
+		}
+		if s.options.OneFileSystem {
+			if sys, ok := vfs.Sys(info); ok && sys.Device != s.device {
+				continue
+			}
+		}
+
+		s.wg.Add(1)
+		select {
+		case s.slots <- struct{}{}:
+			go func() {
+				defer func() { <-s.slots }()
+				s.readDir(child)
+			}()
+		default:
+			s.readDir(child)
+		}
+	}
+}
+
+// addFile fills in a file's usage and adds it to the breakdowns; further hard
+// links of a file take no more space
+func (s *scan) addFile(node *Node, info os.FileInfo) {
+	node.Size = info.Size()
+	node.DiskSize = info.Size()
+	node.Files = 1
+
+	sys, ok := vfs.Sys(info)
+	if ok {
+		node.DiskSize = sys.Blocks * 512
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if ok && sys.Links > 1 {
+		inode := [2]uint64{sys.Device, sys.Inode}
+		if s.seen[inode] {
+			node.Size, node.DiskSize = 0, 0
+			return
+		}
+		s.seen[inode] = true
+	}
+
+	fileType := strings.ToLower(filepath.Ext(node.Name))
+	if fileType == "" || info.Mode()&os.ModeSymlink != 0 {
+		fileType = "(none)"
+	}
+	usage := s.types[fileType]
+	if usage == nil {
+		usage = &TypeUsage{Type: fileType}
+		s.types[fileType] = usage
+	}
+	usage.Files++
+	usage.Size += node.Size
+
+	age := s.now.Sub(info.ModTime())
+	for i, r := range ageRanges {
+		if r.limit == 0 || age < r.limit {
+			s.ages[i].Files++
+			s.ages[i].Size += node.Size
+			break
+		}
+	}
+}
+
+// total adds up a directory's children, biggest first, once they are all read
+func (n *Node) total() {
+	for _, child := range n.Children {
+		if child.IsDir {
+			child.total()
+			n.Dirs += child.Dirs + 1
+		}
+		n.Size += child.Size
+		n.DiskSize += child.DiskSize
+		n.Files += child.Files
+		if child.ModTime.After(n.ModTime) {
+			n.ModTime = child.ModTime
+		}
+	}
+	sortNodes(n.Children)
+}
+
+// sortNodes orders nodes biggest first, then by name
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].DiskSize != nodes[j].DiskSize {
+			return nodes[i].DiskSize > nodes[j].DiskSize
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+// Find returns the node at path, or nil when the scan does not hold it
+func (n *Node) Find(path string) *Node {
+	if path == n.Path {
+		return n
+	}
+	if !strings.HasPrefix(path, n.Path+string(filepath.Separator)) && n.Path != "/" {
+		return nil
+	}
+	for _, child := range n.Children {
+		if found := child.Find(path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Summary returns a copy of the tree cut off below depth, keeping the top
+// biggest children of each directory; 0 keeps them all
+func (n *Node) Summary(depth, top int) *Node {
+	summary := *n
+	summary.Children = nil
+	if depth <= 0 {
+		return &summary
+	}
+	for i, child := range n.Children {
+		if top > 0 && i >= top {
+			break
+		}
+		summary.Children = append(summary.Children, child.Summary(depth-1, top))
+	}
+	return &summary
+}
+
+// detach takes a removed node out of the tree and its totals out of every
+// directory above it
+func (n *Node) detach() {
+	parent := n.parent
+	if parent == nil {
+		return
+	}
+	for i, child := range parent.Children {
+		if child == n {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			break
+		}
+	}
+
+	dirs := n.Dirs
+	if n.IsDir {
+		dirs++
+	}
+	for dir := parent; dir != nil; dir = dir.parent {
+		dir.Size -= n.Size
+		dir.DiskSize -= n.DiskSize
+		dir.Files -= n.Files
+		dir.Dirs -= dirs
+	}
+}
+
+// Trash moves a scanned entry to the trash and returns the ID of the undo
+// session it was tracked in, joining a session that is already open
+func (a *Analyzer) Trash(node *Node) (string, error) {
+	if node.parent == nil {
+		return "", fmt.Errorf("the scanned directory itself cannot be trashed")
+	}
+	err := vfs.Trash(a.fs, node.Path)
+	audit.RecordFile("du", "trash", node.Path, "", err)
+	if err != nil {
+		return "", err
+	}
+
+	session, _, end := a.undo.Begin("du trash "+node.Path, fmt.Sprintf("Moved %s to the trash", node.Path))
+	items, err := a.can.Find(node.Path)
+	if err == nil {
+		err = a.undo.TrackOperation(undo.OpTrash, node.Path, items[0].Location())
+	}
+	if endErr := end(); err == nil {
+		err = endErr
+	}
+
+	node.detach()
+	if err != nil {
+		return "", fmt.Errorf("moved to the trash, but it cannot be undone: %w", err)
+	}
+	return session.ID, nil
+}
+
+// Delete removes a scanned entry for good. A file is copied aside first so the
+// returned undo session can bring it back; a directory cannot be undone and
+// gets no session.
+func (a *Analyzer) Delete(node *Node) (string, error) {
+	if node.parent == nil {
+		return "", fmt.Errorf("the scanned directory itself cannot be deleted")
+	}
+	if node.IsDir {
+		err := a.fs.RemoveAll(node.Path)
+		audit.RecordFile("du", "delete", node.Path, "", err)
+		if err != nil {
+			return "", err
+		}
+		node.detach()
+		return "", nil
+	}
+
+	session, _, end := a.undo.Begin("du delete "+node.Path, fmt.Sprintf("Deleted %s", node.Path))
+	remove := func(path string) error {
+		err := a.fs.Remove(path)
+		audit.RecordFile("du", "delete", path, "", err)
+		return err
+	}
+	if err := a.undo.TrackDeletion(node.Path, remove); err != nil {
+		end()
+		return "", err
+	}
+
+	node.detach()
+	if err := end(); err != nil {
+		return "", fmt.Errorf("deleted, but it cannot be undone: %w", err)
+	}
+	return session.ID, nil
+}
@@ -0,0 +1,524 @@
+/**
+ * Duplicate Finder
+ *
+ * Finds files with identical contents and deduplicates them. Candidates are
+ * narrowed in three passes so most files are never read in full: files are
+ * grouped by size, then by a SHA-256 of their first block, and only files
+ * still sharing a group are hashed whole. Files that are already hard links
+ * of each other count once. A plan keeps one copy of each group and deletes,
+ * links or moves the rest, and applying it records every change in an undo
+ * session.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: dupes.go
+ * Description: Duplicate detection by size and hash, deduplication plans and their execution
+ */
+
+package dupes
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"ena/internal/audit"
+	"ena/internal/fault"
+	"ena/internal/trash"
+	"ena/internal/undo"
+	"ena/internal/vfs"
+)
+
+// partialHashLength is how much of each file the second pass hashes
+const partialHashLength = 16 * 1024
+
+// Keep chooses which file of a group stays
+type Keep string
+
+const (
+	KeepNewest   Keep = "newest"   // the most recently modified file
+	KeepOldest   Keep = "oldest"   // the least recently modified file
+	KeepShortest Keep = "shortest" // the file with the shortest path
+)
+
+// Action is what happens to the files of a group that are not kept
+type Action string
+
+const (
+	ActionDelete   Action = "delete"   // move to the trash, or delete outright
+	ActionHardlink Action = "hardlink" // replace with a hard link to the kept file
+	ActionReflink  Action = "reflink"  // replace with a copy-on-write clone of the kept file
+	ActionMove     Action = "move"     // move into a folder
+)
+
+// Options controls which files a scan considers
+type Options struct {
+	MinSize int64 // smaller files are ignored; empty files always are
+	Hidden  bool  // scan hidden files and directories too
+	Workers int   // files hashed at once; 0 means one per CPU
+}
+
+// File is one copy of some contents
+type File struct {
+	Path    string    `json:"path"`
+	Links   []string  `json:"links,omitempty"` // other scanned paths hard linked to it
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Group is a set of files with identical contents
+type Group struct {
+	Size  int64  `json:"size"`
+	Hash  string `json:"hash"`
+	Files []File `json:"files"`
+}
+
+// Reclaimable is the space freed by keeping only one file of the group
+func (g Group) Reclaimable() int64 {
+	return g.Size * int64(len(g.Files)-1)
+}
+
+// Keeper returns the file the strategy keeps
+func (g Group) Keeper(keep Keep) File {
+	best := g.Files[0]
+	for _, file := range g.Files[1:] {
+		if better(file, best, keep) {
+			best = file
+		}
+	}
+	return best
+}
+
+// Extras returns every file of the group but the one the strategy keeps
+func (g Group) Extras(keep Keep) []File {
+	keeper := g.Keeper(keep)
+	extras := make([]File, 0, len(g.Files)-1)
+	for _, file := range g.Files {
+		if file.Path != keeper.Path {
+			extras = append(extras, file)
+		}
+	}
+	return extras
+}
+
+// better reports whether a is kept rather than b; ties go to the shorter path
+func better(a, b File, keep Keep) bool {
+	switch keep {
+	case KeepNewest:
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.After(b.ModTime)
+		}
+	case KeepOldest:
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.Before(b.ModTime)
+		}
+	}
+	if len(a.Path) != len(b.Path) {
+		return len(a.Path) < len(b.Path)
+	}
+	return a.Path < b.Path
+}
+
+// Report is the outcome of a scan, with the groups wasting the most space first
+type Report struct {
+	Groups       []Group       `json:"groups"`
+	Keep         Keep          `json:"keep,omitempty"` // copy of each group the caller would keep
+	FilesScanned int           `json:"files_scanned"`
+	FilesHashed  int           `json:"files_hashed"`
+	Duplicates   int           `json:"duplicates"`
+	Reclaimable  int64         `json:"reclaimable"`
+	Errors       []string      `json:"errors,omitempty"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// Step is one change a plan makes
+type Step struct {
+	Action Action `json:"action"`
+	Path   string `json:"path"`
+	Keep   string `json:"keep"`
+	Target string `json:"target,omitempty"` // where a moved file goes
+	Size   int64  `json:"size"`             // space freed; 0 for further links of a file
+}
+
+// Plan lists the changes that deduplicate a report's groups
+type Plan struct {
+	Action      Action `json:"action"`
+	Keep        Keep   `json:"keep"`
+	Permanent   bool   `json:"permanent,omitempty"`
+	Steps       []Step `json:"steps"`
+	Reclaimable int64  `json:"reclaimable"`
+}
+
+// StepResult is how one step of a plan went
+type StepResult struct {
+	Step
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the outcome of applying a plan
+type Result struct {
+	Plan          *Plan        `json:"plan"`
+	Steps         []StepResult `json:"steps"`
+	Succeeded     int          `json:"succeeded"`
+	Failed        int          `json:"failed"`
+	Reclaimed     int64        `json:"reclaimed"`
+	UndoSessionID string       `json:"undo_session_id,omitempty"`
+}
+
+// Finder finds duplicate files and carries out deduplication plans
+type Finder struct {
+	fs   vfs.FS
+	can  *trash.Can
+	undo *undo.UndoManager
+}
+
+// NewFinder creates a finder working on fs that trashes into can and records
+// its changes with undoManager
+func NewFinder(fs vfs.FS, can *trash.Can, undoManager *undo.UndoManager) *Finder {
+	return &Finder{fs: fs, can: can, undo: undoManager}
+}
+
+// Find scans roots for files with identical contents
+func (f *Finder) Find(roots []string, options Options) (*Report, error) {
+	for _, root := range roots {
+		if _, err := f.fs.Lstat(root); err != nil {
+			return nil, fmt.Errorf("Failed to scan %s: %w", root, err)
+		}
+	}
+
+	startTime := time.Now()
+	report := &Report{Groups: []Group{}}
+
+	// Pass 1: group by size, counting hard links of one file once
+	bySize := make(map[int64][]File)
+	seen := make(map[[2]uint64]int)
+	for _, root := range roots {
+		vfs.Walk(f.fs, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", path, err))
+				return nil
+			}
+			if path != root && !options.Hidden && strings.HasPrefix(info.Name(), ".") {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() || info.Size() == 0 || info.Size() < options.MinSize {
+				return nil
+			}
+
+			files := bySize[info.Size()]
+			if sys, ok := vfs.Sys(info); ok {
+				inode := [2]uint64{sys.Device, sys.Inode}
+				if index, ok := seen[inode]; ok {
+					files[index].Links = append(files[index].Links, path)
+					return nil
+				}
+				seen[inode] = len(files)
+			}
+
+			report.FilesScanned++
+			bySize[info.Size()] = append(files, File{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+			return nil
+		})
+	}
+
+	// Pass 2: group files of one size by the hash of their first block
+	var candidates []File
+	for _, files := range bySize {
+		if len(files) > 1 {
+			candidates = append(candidates, files...)
+		}
+	}
+	partial := f.hashAll(candidates, options.Workers, true, report)
+	byPartial := group(candidates, partial)
+
+	// Pass 3: hash whole files that still look alike; small ones were hashed whole already
+	candidates = candidates[:0]
+	for _, files := range byPartial {
+		if len(files) > 1 && files[0].Size > partialHashLength {
+			candidates = append(candidates, files...)
+		}
+	}
+	full := f.hashAll(candidates, options.Workers, false, report)
+
+	for _, files := range group(candidates, full) {
+		if len(files) > 1 {
+			report.Groups = append(report.Groups, newGroup(files, full[files[0].Path]))
+		}
+	}
+	for _, files := range byPartial {
+		if len(files) > 1 && files[0].Size <= partialHashLength {
+			report.Groups = append(report.Groups, newGroup(files, partial[files[0].Path]))
+		}
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Reclaimable() != b.Reclaimable() {
+			return a.Reclaimable() > b.Reclaimable()
+		}
+		return a.Files[0].Path < b.Files[0].Path
+	})
+	for _, g := range report.Groups {
+		report.Duplicates += len(g.Files) - 1
+		report.Reclaimable += g.Reclaimable()
+	}
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
+
+// newGroup makes a group of files sorted by path
+func newGroup(files []File, hash string) Group {
+	sorted := append([]File{}, files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	return Group{Size: sorted[0].Size, Hash: hash, Files: sorted}
+}
+
+// group splits files by size and hash; files that could not be hashed are left out
+func group(files []File, hashes map[string]string) map[string][]File {
+	groups := make(map[string][]File)
+	for _, file := range files {
+		if hash, ok := hashes[file.Path]; ok {
+			key := fmt.Sprintf("%d:%s", file.Size, hash)
+			groups[key] = append(groups[key], file)
+		}
+	}
+	return groups
+}
+
+// hashAll hashes files in parallel, the first block only when partial is set
+func (f *Finder) hashAll(files []File, workers int, partial bool, report *Report) map[string]string {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	hashes := make(map[string]string, len(files))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan File)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range queue {
+				hash, err := f.hash(file.Path, partial)
+				mutex.Lock()
+				if err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", file.Path, err))
+				} else {
+					hashes[file.Path] = hash
+					report.FilesHashed++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, file := range files {
+		queue <- file
+	}
+	close(queue)
+	wg.Wait()
+
+	return hashes
+}
+
+// hash returns the SHA-256 of a file, or of its first block when partial is set
+func (f *Finder) hash(path string, partial bool) (string, error) {
+	file, err := f.fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if partial {
+		reader = io.LimitReader(file, partialHashLength)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// Plan lists the changes that keep one file of each group and apply action to
+// the rest; moved files go into dest under names that are still free
+func (f *Finder) Plan(groups []Group, action Action, keep Keep, dest string, permanent bool) (*Plan, error) {
+	switch keep {
+	case KeepNewest, KeepOldest, KeepShortest:
+	default:
+		return nil, fault.Invalid("invalid keep strategy %q: use newest, oldest or shortest", keep)
+	}
+	switch action {
+	case ActionDelete, ActionHardlink, ActionReflink:
+	case ActionMove:
+		if dest == "" {
+			return nil, fmt.Errorf("moving duplicates needs a folder to move them to")
+		}
+	default:
+		return nil, fault.Invalid("invalid action %q: use delete, hardlink, reflink or move", action)
+	}
+
+	plan := &Plan{Action: action, Keep: keep, Permanent: permanent && action == ActionDelete, Steps: []Step{}}
+	taken := make(map[string]bool)
+	for _, g := range groups {
+		keeper := g.Keeper(keep)
+		for _, file := range g.Files {
+			if file.Path == keeper.Path {
+				continue
+			}
+			// Space comes back only once every link of a file is gone
+			for i, path := range append([]string{file.Path}, file.Links...) {
+				step := Step{Action: action, Path: path, Keep: keeper.Path}
+				if action == ActionMove {
+					step.Target = f.freeName(dest, filepath.Base(path), taken)
+				} else if i == 0 {
+					step.Size = file.Size
+					plan.Reclaimable += file.Size
+				}
+				plan.Steps = append(plan.Steps, step)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// freeName returns a path in dir for name that neither exists nor is taken,
+// adding .2, .3 and so on before the extension as needed
+func (f *Finder) freeName(dir, name string, taken map[string]bool) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := filepath.Join(dir, name)
+	for n := 2; ; n++ {
+		if _, err := f.fs.Lstat(candidate); os.IsNotExist(err) && !taken[candidate] {
+			taken[candidate] = true
+			return candidate
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s.%d%s", stem, n, ext))
+	}
+}
+
+// Apply carries out a plan in one undo session, so "ena undo-session" reverses
+// all of it, or in the session already open, such as a script's. A step whose
+// change cannot be tracked is not made, except that a copy already moved to
+// the trash stays there. The error reports an undo session that could not be
+// saved.
+func (f *Finder) Apply(plan *Plan) (*Result, error) {
+	result := &Result{Plan: plan, Steps: make([]StepResult, 0, len(plan.Steps))}
+
+	session, nested, end := f.undo.Begin(fmt.Sprintf("dupes %s", plan.Action),
+		fmt.Sprintf("Deduplicated %d files, keeping the %s copy", len(plan.Steps), plan.Keep))
+
+	for _, step := range plan.Steps {
+		err := f.apply(&step, plan.Permanent)
+		stepResult := StepResult{Step: step}
+		if err != nil {
+			stepResult.Error = err.Error()
+			result.Failed++
+		} else {
+			result.Succeeded++
+			result.Reclaimed += step.Size
+		}
+		result.Steps = append(result.Steps, stepResult)
+	}
+
+	if err := end(); err != nil {
+		return result, fmt.Errorf("Failed to save undo session: %w", err)
+	}
+	// The owner of an enclosing session reports it
+	if !nested && len(session.Operations) > 0 {
+		result.UndoSessionID = session.ID
+	}
+	return result, nil
+}
+
+// apply makes one step's change after checking the kept copy is still there.
+// A reflink the filesystem cannot make becomes a hard link, and step says so.
+func (f *Finder) apply(step *Step, permanent bool) (err error) {
+	target := step.Target
+	if target == "" {
+		target = step.Keep
+	}
+	defer func() {
+		audit.RecordFile("dupes", string(step.Action), step.Path, target, err)
+	}()
+
+	keeper, err := f.fs.Stat(step.Keep)
+	if err != nil {
+		return fmt.Errorf("kept copy is gone: %w", err)
+	}
+	info, err := f.fs.Stat(step.Path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != keeper.Size() {
+		return fmt.Errorf("%s changed since it was scanned", step.Path)
+	}
+
+	switch step.Action {
+	case ActionDelete:
+		if permanent {
+			return f.undo.TrackDeletion(step.Path, f.fs.Remove)
+		}
+		// The trash keeps the copy, so undo only has to know where it went
+		if err := vfs.Trash(f.fs, step.Path); err != nil {
+			return err
+		}
+		items, err := f.can.Find(step.Path)
+		if err == nil {
+			err = f.undo.TrackOperation(undo.OpTrash, step.Path, items[0].Location())
+		}
+		if err != nil {
+			return fmt.Errorf("moved to the trash, but it cannot be undone: %w", err)
+		}
+		return nil
+
+	case ActionHardlink, ActionReflink:
+		if err := f.undo.TrackOperation(undo.OpLink, step.Path, step.Keep); err != nil {
+			return err
+		}
+		if step.Action == ActionHardlink {
+			return vfs.Link(f.fs, step.Keep, step.Path)
+		}
+		if err = vfs.Reflink(f.fs, step.Keep, step.Path); errors.Is(err, errors.ErrUnsupported) {
+			// No copy-on-write clones here, so share the kept file's data through a hard link
+			step.Action = ActionHardlink
+			return vfs.Link(f.fs, step.Keep, step.Path)
+		}
+		return err
+
+	case ActionMove:
+		if err := f.fs.MkdirAll(filepath.Dir(step.Target), 0755); err != nil {
+			return err
+		}
+		if err := f.undo.TrackOperation(undo.OpMove, step.Path, step.Target); err != nil {
+			return err
+		}
+		if err := f.fs.Rename(step.Path, step.Target); err != nil {
+			var linkErr *os.LinkError
+			if !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+				return err
+			}
+			// Another device: copy, then remove the original
+			if err := vfs.CopyFile(f.fs, step.Path, step.Target); err != nil {
+				return err
+			}
+			return f.fs.Remove(step.Path)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", step.Action)
+}
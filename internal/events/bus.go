@@ -0,0 +1,327 @@
+/**
+ * Event Bus
+ *
+ * Delivers published events to every subscription whose pattern matches the
+ * topic. Each subscription has its own queue and goroutine, so handlers run
+ * in publish order without holding up the publisher or each other. When a
+ * queue is full the publisher waits for room, up to a limit, before the
+ * event is dropped and counted; subscriptions that must never slow the
+ * publisher can drop at once instead. Publishers wait without holding the
+ * bus lock, so a slow subscriber never holds up subscribing or stats.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: bus.go
+ * Description: Topic-based publish/subscribe with async delivery and backpressure
+ */
+
+package events
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ena/internal/fault"
+	"ena/internal/output"
+)
+
+// Handler receives events from a subscription
+type Handler func(event Event)
+
+// Overflow says what happens to an event when a subscription's queue is full
+type Overflow string
+
+const (
+	// OverflowBlock makes the publisher wait for room, up to MaxWait
+	OverflowBlock Overflow = "block"
+	// OverflowDrop drops the event straight away
+	OverflowDrop Overflow = "drop"
+)
+
+// SubscribeOptions tune one subscription
+type SubscribeOptions struct {
+	Name     string        // shown in stats; defaults to the pattern
+	Buffer   int           // events queued before overflow applies
+	Overflow Overflow      // block or drop when the queue is full
+	MaxWait  time.Duration // how long a blocked publisher waits before dropping
+}
+
+// DefaultSubscribeOptions returns the options Subscribe uses
+func DefaultSubscribeOptions() SubscribeOptions {
+	return SubscribeOptions{
+		Buffer:   256,
+		Overflow: OverflowBlock,
+		MaxWait:  2 * time.Second,
+	}
+}
+
+// Handle adapts a handler for one payload type, skipping events that carry
+// any other payload
+func Handle[T any](handler func(event Event, payload T)) Handler {
+	return func(event Event) {
+		if payload, ok := event.Payload.(T); ok {
+			handler(event, payload)
+		}
+	}
+}
+
+// Subscription is a pattern with its handler and queue
+type Subscription struct {
+	pattern string
+	options SubscribeOptions
+	handler Handler
+	queue   chan Event
+	stopped chan struct{} // closed to stop taking events
+	done    chan struct{} // closed once run has returned
+	bus     *Bus
+	once    sync.Once
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+	panics    atomic.Int64
+}
+
+// SubscriptionStats counts what happened to a subscription's events
+type SubscriptionStats struct {
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`
+	Delivered int64  `json:"delivered"`
+	Dropped   int64  `json:"dropped"`
+	Pending   int    `json:"pending"`
+	Panics    int64  `json:"panics"`
+}
+
+// BusStats counts what the bus has published
+type BusStats struct {
+	Published     int64               `json:"published"`
+	Subscriptions []SubscriptionStats `json:"subscriptions"`
+}
+
+// Bus routes events from publishers to subscriptions
+type Bus struct {
+	subscriptions []*Subscription
+	mutex         sync.RWMutex
+	closed        bool
+	published     atomic.Int64
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls handler for every event whose topic matches pattern
+func (b *Bus) Subscribe(pattern string, handler Handler) (*Subscription, error) {
+	return b.SubscribeWithOptions(pattern, handler, DefaultSubscribeOptions())
+}
+
+// SubscribeWithOptions subscribes with a custom queue size and overflow behaviour
+func (b *Bus) SubscribeWithOptions(pattern string, handler Handler, options SubscribeOptions) (*Subscription, error) {
+	if !ValidPattern(pattern) {
+		return nil, fault.Invalid("invalid topic pattern %q", pattern)
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("subscription to %q has no handler", pattern)
+	}
+
+	defaults := DefaultSubscribeOptions()
+	if options.Name == "" {
+		options.Name = pattern
+	}
+	if options.Buffer <= 0 {
+		options.Buffer = defaults.Buffer
+	}
+	if options.Overflow == "" {
+		options.Overflow = defaults.Overflow
+	}
+	if options.MaxWait <= 0 {
+		options.MaxWait = defaults.MaxWait
+	}
+
+	sub := &Subscription{
+		pattern: pattern,
+		options: options,
+		handler: handler,
+		queue:   make(chan Event, options.Buffer),
+		stopped: make(chan struct{}),
+		done:    make(chan struct{}),
+		bus:     b,
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, fmt.Errorf("event bus is closed")
+	}
+	b.subscriptions = append(b.subscriptions, sub)
+	go sub.run()
+
+	return sub, nil
+}
+
+// Publish queues event for every matching subscription. Publishing on a nil
+// or closed bus does nothing, so managers work without one.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mutex.RLock()
+	if b.closed {
+		b.mutex.RUnlock()
+		return
+	}
+	b.published.Add(1)
+
+	var matched []*Subscription
+	for _, sub := range b.subscriptions {
+		if Matches(sub.pattern, event.Topic) {
+			matched = append(matched, sub)
+		}
+	}
+	b.mutex.RUnlock()
+
+	// Waiting for room happens outside the lock, so it holds up only this publisher
+	for _, sub := range matched {
+		sub.enqueue(event)
+	}
+}
+
+// Stats returns the bus counters
+func (b *Bus) Stats() BusStats {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	stats := BusStats{Published: b.published.Load()}
+	for _, sub := range b.subscriptions {
+		stats.Subscriptions = append(stats.Subscriptions, sub.Stats())
+	}
+	return stats
+}
+
+// Close stops accepting events and waits for queued ones to be handled
+func (b *Bus) Close() error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return nil
+	}
+	b.closed = true
+	subscriptions := b.subscriptions
+	b.subscriptions = nil
+	b.mutex.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.stop()
+	}
+	for _, sub := range subscriptions {
+		<-sub.done
+	}
+	return nil
+}
+
+// Unsubscribe stops the subscription. Events already queued are still
+// handled, after it returns, so a handler can unsubscribe itself.
+func (s *Subscription) Unsubscribe() {
+	s.bus.mutex.Lock()
+	for i, sub := range s.bus.subscriptions {
+		if sub == s {
+			s.bus.subscriptions = append(s.bus.subscriptions[:i], s.bus.subscriptions[i+1:]...)
+			break
+		}
+	}
+	s.bus.mutex.Unlock()
+
+	s.stop()
+}
+
+// Stats returns the subscription counters
+func (s *Subscription) Stats() SubscriptionStats {
+	return SubscriptionStats{
+		Name:      s.options.Name,
+		Pattern:   s.pattern,
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+		Pending:   len(s.queue),
+		Panics:    s.panics.Load(),
+	}
+}
+
+// enqueue adds event to the queue, applying the overflow behaviour when it is
+// full. Events for a stopped subscription are ignored.
+func (s *Subscription) enqueue(event Event) {
+	select {
+	case <-s.stopped:
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- event:
+		return
+	default:
+	}
+
+	if s.options.Overflow == OverflowBlock {
+		timer := time.NewTimer(s.options.MaxWait)
+		defer timer.Stop()
+
+		select {
+		case s.queue <- event:
+			return
+		case <-s.stopped:
+			return
+		case <-timer.C:
+		}
+	}
+	s.dropped.Add(1)
+}
+
+// run hands queued events to the handler one at a time, finishing the queue
+// once the subscription is stopped
+func (s *Subscription) run() {
+	defer close(s.done)
+
+	for {
+		select {
+		case event := <-s.queue:
+			s.deliver(event)
+		case <-s.stopped:
+			for {
+				select {
+				case event := <-s.queue:
+					s.deliver(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver calls the handler, surviving a panic so one bad handler cannot stop the bus
+func (s *Subscription) deliver(event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.panics.Add(1)
+			output.Fprintf(os.Stderr, "⚠️  Event handler %s panicked on %s: %v\n", s.options.Name, event.Topic, r)
+		}
+	}()
+
+	s.handler(event)
+	s.delivered.Add(1)
+}
+
+// stop ends the subscription without waiting for its queue to be handled
+func (s *Subscription) stop() {
+	s.once.Do(func() {
+		close(s.stopped)
+	})
+}
@@ -0,0 +1,232 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+const testTopic Topic = "test.event"
+
+// waitFor fails the test unless ch is closed or sends within a second
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestBusDeliversInOrder(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	if _, err := bus.Subscribe("test.*", func(event Event) { got = append(got, event.Message) }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Subscribe("other.*", func(event Event) { t.Errorf("unmatched subscription got %s", event.Topic) }); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, message := range []string{"one", "two", "three"} {
+		bus.Publish(New(testTopic, message, nil))
+	}
+	bus.Close()
+
+	if len(got) != 3 || got[0] != "one" || got[1] != "two" || got[2] != "three" {
+		t.Errorf("handler got %v, want [one two three]", got)
+	}
+	if stats := bus.Stats(); stats.Published != 3 {
+		t.Errorf("published %d, want 3", stats.Published)
+	}
+}
+
+func TestBusDropsForSlowSubscriber(t *testing.T) {
+	for _, overflow := range []Overflow{OverflowBlock, OverflowDrop} {
+		t.Run(string(overflow), func(t *testing.T) {
+			bus := NewBus()
+			started := make(chan struct{}, 1)
+			release := make(chan struct{})
+			options := SubscribeOptions{Buffer: 1, Overflow: overflow, MaxWait: 50 * time.Millisecond}
+			sub, err := bus.SubscribeWithOptions("*", func(event Event) {
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+				<-release
+			}, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The first event is in the handler and the second fills the queue
+			bus.Publish(New(testTopic, "first", nil))
+			waitFor(t, started, "the handler")
+			bus.Publish(New(testTopic, "second", nil))
+
+			begin := time.Now()
+			bus.Publish(New(testTopic, "third", nil))
+			waited := time.Since(begin)
+
+			switch overflow {
+			case OverflowBlock:
+				if waited < options.MaxWait {
+					t.Errorf("publisher waited %v, want at least %v", waited, options.MaxWait)
+				}
+			case OverflowDrop:
+				if waited >= options.MaxWait {
+					t.Errorf("publisher waited %v for a dropping subscriber", waited)
+				}
+			}
+
+			close(release)
+			bus.Close()
+			stats := sub.Stats()
+			if stats.Dropped != 1 || stats.Delivered != 2 {
+				t.Errorf("stats count %d delivered and %d dropped, want 2 and 1", stats.Delivered, stats.Dropped)
+			}
+		})
+	}
+}
+
+func TestBusWaitingPublisherHoldsNoLock(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	options := SubscribeOptions{Name: "slow", Buffer: 1, Overflow: OverflowBlock, MaxWait: 5 * time.Second}
+	if _, err := bus.SubscribeWithOptions("test.*", func(event Event) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}, options); err != nil {
+		t.Fatal(err)
+	}
+
+	fast := make(chan struct{}, 10)
+	if _, err := bus.Subscribe("test.*", func(event Event) { fast <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Publish(New(testTopic, "first", nil))
+	waitFor(t, started, "the slow handler")
+	bus.Publish(New(testTopic, "second", nil))
+
+	// This publisher waits on the slow subscriber's full queue
+	go bus.Publish(New(testTopic, "third", nil))
+	time.Sleep(20 * time.Millisecond)
+
+	// Subscribing, reading stats and other publishers must not wait with it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := bus.Subscribe("unrelated.*", func(Event) {}); err != nil {
+			t.Error(err)
+		}
+		bus.Stats()
+		bus.Publish(New("unrelated.event", "other", nil))
+	}()
+	waitFor(t, done, "subscribe and stats while a publisher waits")
+	for i := 0; i < 2; i++ {
+		waitFor(t, fast, "the fast subscriber")
+	}
+}
+
+func TestBusUnsubscribeFromOwnHandler(t *testing.T) {
+	bus := NewBus()
+
+	var mutex sync.Mutex
+	var got []string
+	unsubscribed := make(chan struct{})
+	var sub *Subscription
+	var err error
+	sub, err = bus.Subscribe("*", func(event Event) {
+		mutex.Lock()
+		got = append(got, event.Message)
+		mutex.Unlock()
+		if event.Message == "stop" {
+			sub.Unsubscribe()
+			close(unsubscribed)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Publish(New(testTopic, "stop", nil))
+	waitFor(t, unsubscribed, "Unsubscribe to return inside the handler")
+
+	bus.Publish(New(testTopic, "after", nil))
+	closed := make(chan struct{})
+	go func() {
+		bus.Close()
+		close(closed)
+	}()
+	waitFor(t, closed, "Close")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(got) != 1 || got[0] != "stop" {
+		t.Errorf("handler got %v, want [stop]", got)
+	}
+	if stats := bus.Stats(); len(stats.Subscriptions) != 0 {
+		t.Errorf("bus still lists %d subscriptions", len(stats.Subscriptions))
+	}
+}
+
+func TestBusUnsubscribeHandlesQueuedEvents(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	release := make(chan struct{})
+	handled := make(chan string, 3)
+	sub, err := bus.Subscribe("*", func(event Event) {
+		<-release
+		handled <- event.Message
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Publish(New(testTopic, "one", nil))
+	bus.Publish(New(testTopic, "two", nil))
+	sub.Unsubscribe()
+	bus.Publish(New(testTopic, "three", nil))
+	close(release)
+
+	for _, want := range []string{"one", "two"} {
+		select {
+		case got := <-handled:
+			if got != want {
+				t.Errorf("handled %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("queued event %q was not handled", want)
+		}
+	}
+	select {
+	case got := <-handled:
+		t.Errorf("handled %q published after Unsubscribe", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestBusPublishAfterClose(t *testing.T) {
+	bus := NewBus()
+	if _, err := bus.Subscribe("*", func(Event) { t.Error("handler ran after Close") }); err != nil {
+		t.Fatal(err)
+	}
+	bus.Close()
+	bus.Publish(New(testTopic, "late", nil))
+
+	var nilBus *Bus
+	nilBus.Publish(New(testTopic, "nowhere", nil))
+
+	if _, err := bus.Subscribe("*", func(Event) {}); err == nil {
+		t.Error("Subscribe on a closed bus succeeded")
+	}
+}
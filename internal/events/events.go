@@ -0,0 +1,106 @@
+/**
+ * Events
+ *
+ * The events managers announce on the bus. Every event has a topic of the
+ * form "source.name", such as "batch.job_completed", and a payload whose type
+ * is fixed by the topic. Packages define their topics once with Define, which
+ * also lists them for "ena events".
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: events.go
+ * Description: Event and topic types with the topic catalog
+ */
+
+package events
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Topic names a kind of event as "source.name"
+type Topic string
+
+// Source returns the component part of the topic, e.g. batch
+func (t Topic) Source() string {
+	source, _, _ := strings.Cut(string(t), ".")
+	return source
+}
+
+// Name returns the part of the topic after the source, e.g. job_completed
+func (t Topic) Name() string {
+	_, name, _ := strings.Cut(string(t), ".")
+	return name
+}
+
+// Event is one announcement on the bus
+type Event struct {
+	Topic     Topic       `json:"topic"`
+	Message   string      `json:"message,omitempty"`
+	Payload   interface{} `json:"payload,omitempty"` // the publishing package's event struct
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// New creates an event stamped with the current time
+func New(topic Topic, message string, payload interface{}) Event {
+	return Event{Topic: topic, Message: message, Payload: payload, Timestamp: time.Now()}
+}
+
+// TopicInfo describes a defined topic
+type TopicInfo struct {
+	Topic       Topic  `json:"topic"`
+	Description string `json:"description"`
+}
+
+var (
+	catalog      = make(map[Topic]string)
+	catalogMutex sync.RWMutex
+)
+
+// Define registers a topic with a description and returns it
+func Define(topic Topic, description string) Topic {
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+
+	catalog[topic] = description
+	return topic
+}
+
+// Topics lists the defined topics matching pattern, sorted; an empty pattern lists them all
+func Topics(pattern string) []TopicInfo {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	var topics []TopicInfo
+	for topic, description := range catalog {
+		if pattern == "" || Matches(pattern, topic) {
+			topics = append(topics, TopicInfo{Topic: topic, Description: description})
+		}
+	}
+
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Topic < topics[j].Topic
+	})
+	return topics
+}
+
+// Matches reports whether a subscription pattern selects topic. Patterns use
+// shell wildcards: "*" selects everything, "batch.*" every batch event and
+// "*.job_completed" that event from any source.
+func Matches(pattern string, topic Topic) bool {
+	matched, err := path.Match(pattern, string(topic))
+	return err == nil && matched
+}
+
+// ValidPattern reports whether pattern is well formed
+func ValidPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
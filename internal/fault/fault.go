@@ -0,0 +1,150 @@
+/**
+ * Error Classes
+ *
+ * Sorts errors into the few classes a script cares about - invalid input, a
+ * missing thing, a refusal, a partial batch, an absent service - so the CLI
+ * can pick an exit code with errors.Is rather than by reading the message.
+ * The class survives %w wrapping and, as a code, the trip through the daemon.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: fault.go
+ * Description: Sentinel errors and the exit codes they map to
+ */
+
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Exit codes, documented by "ena exit-codes"; never renumber them
+const (
+	CodeOK          = 0 // the command did what was asked
+	CodeFailure     = 1 // the command failed
+	CodeUsage       = 2 // unknown command or flag, or invalid arguments
+	CodeNotFound    = 3 // a file, backup, job or other named thing does not exist
+	CodeDenied      = 4 // refused by the safety policy or by file permissions
+	CodePartial     = 5 // some items of a batch failed and the rest succeeded
+	CodeUnavailable = 6 // the daemon or another service the command needs is not running
+)
+
+// Sentinel errors, one per class; match them with errors.Is
+var (
+	ErrInvalid     = errors.New("invalid argument")
+	ErrNotFound    = errors.New("not found")
+	ErrPermission  = errors.New("permission denied")
+	ErrPartial     = errors.New("partly failed")
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// classes pairs each code with its sentinel, most specific first
+var classes = []struct {
+	code int
+	err  error
+}{
+	{CodeUsage, ErrInvalid},
+	{CodeDenied, ErrPermission},
+	{CodeDenied, os.ErrPermission},
+	{CodeNotFound, ErrNotFound},
+	{CodeNotFound, os.ErrNotExist},
+	{CodePartial, ErrPartial},
+	{CodeUnavailable, ErrUnavailable},
+}
+
+// classed is an error that belongs to a class but keeps its own message
+type classed struct {
+	class error
+	err   error
+}
+
+func (e *classed) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes both the class and whatever the message itself wraps
+func (e *classed) Unwrap() []error {
+	return []error{e.class, e.err}
+}
+
+// coded is an error known only by its message and exit code, as relayed by the daemon
+type coded struct {
+	code    int
+	message string
+}
+
+func (e *coded) Error() string {
+	return e.message
+}
+
+// ExitCode returns the code the error was sent with
+func (e *coded) ExitCode() int {
+	return e.code
+}
+
+// Is matches the sentinel of the error's code
+func (e *coded) Is(target error) bool {
+	for _, class := range classes {
+		if class.code == e.code && class.err == target {
+			return true
+		}
+	}
+	return false
+}
+
+// New formats an error of the given class; %w in format still wraps
+func New(class error, format string, args ...interface{}) error {
+	return &classed{class: class, err: fmt.Errorf(format, args...)}
+}
+
+// Invalid formats an error for bad input
+func Invalid(format string, args ...interface{}) error {
+	return New(ErrInvalid, format, args...)
+}
+
+// NotFound formats an error for a missing file, job or other named thing
+func NotFound(format string, args ...interface{}) error {
+	return New(ErrNotFound, format, args...)
+}
+
+// Denied formats an error for a refused operation
+func Denied(format string, args ...interface{}) error {
+	return New(ErrPermission, format, args...)
+}
+
+// Partial formats an error for a batch in which only some items failed
+func Partial(format string, args ...interface{}) error {
+	return New(ErrPartial, format, args...)
+}
+
+// Unavailable formats an error for a service that is not running
+func Unavailable(format string, args ...interface{}) error {
+	return New(ErrUnavailable, format, args...)
+}
+
+// FromCode rebuilds an error that crossed a process boundary as a message and a code
+func FromCode(code int, message string) error {
+	return &coded{code: code, message: message}
+}
+
+// Code returns the exit code for an error: an explicit ExitCode wins, then
+// the first class the error wraps, then CodeFailure
+func Code(err error) int {
+	if err == nil {
+		return CodeOK
+	}
+
+	var exit interface{ ExitCode() int }
+	if errors.As(err, &exit) {
+		return exit.ExitCode()
+	}
+
+	for _, class := range classes {
+		if errors.Is(err, class.err) {
+			return class.code
+		}
+	}
+	return CodeFailure
+}
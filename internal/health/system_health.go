@@ -28,6 +28,43 @@ type SystemHealth struct {
 	LastCheck time.Time
 }
 
+// HealthSnapshot is a machine-readable view of the health report
+type HealthSnapshot struct {
+	CPU       *CPUStatus    `json:"cpu,omitempty"`
+	Memory    *UsageStatus  `json:"memory,omitempty"`
+	Disk      *UsageStatus  `json:"disk,omitempty"`
+	Runtime   RuntimeStatus `json:"runtime"`
+	Healthy   bool          `json:"healthy"`
+	Issues    []string      `json:"issues"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// CPUStatus describes processor load
+type CPUStatus struct {
+	Model        string  `json:"model"`
+	Cores        int     `json:"cores"`
+	UsagePercent float64 `json:"usage_percent"`
+	Status       string  `json:"status"` // normal, warning, high_load
+}
+
+// UsageStatus describes memory or disk capacity in bytes
+type UsageStatus struct {
+	Total        uint64  `json:"total"`
+	Used         uint64  `json:"used"`
+	Free         uint64  `json:"free"`
+	UsagePercent float64 `json:"usage_percent"`
+	Status       string  `json:"status"` // normal, warning, critical
+}
+
+// RuntimeStatus describes the Go runtime of this process
+type RuntimeStatus struct {
+	Alloc      uint64 `json:"alloc"`
+	TotalAlloc uint64 `json:"total_alloc"`
+	Sys        uint64 `json:"sys"`
+	NumGC      uint32 `json:"num_gc"`
+	Goroutines int    `json:"goroutines"`
+}
+
 // NewSystemHealth creates a new system health monitor
 func NewSystemHealth() *SystemHealth {
 	// Initialize system health monitoring with care ✨
@@ -84,7 +121,7 @@ func (sh *SystemHealth) GetCPUInfo() (string, error) {
 	// Check CPU status thoroughly - monitoring load levels
 	percentages, err := cpu.Percent(time.Second, false)
 	if err != nil {
-		return "", fmt.Errorf("Failed to get CPU information: %v", err)
+		return "", fmt.Errorf("Failed to get CPU information: %w", err)
 	}
 
 	cpuUsage := percentages[0]
@@ -95,7 +132,7 @@ func (sh *SystemHealth) GetCPUInfo() (string, error) {
 	// Get detailed CPU information if available
 	info, err := cpu.Info()
 	if err != nil {
-		return "", fmt.Errorf("Failed to get detailed CPU information: %v", err)
+		return "", fmt.Errorf("Failed to get detailed CPU information: %w", err)
 	}
 
 	cpuModel := "Unknown"
@@ -122,7 +159,7 @@ func (sh *SystemHealth) GetMemoryInfo() (string, error) {
 	// Check memory status - monitoring for insufficient resources
 	vmStat, err := mem.VirtualMemory()
 	if err != nil {
-		return "", fmt.Errorf("Failed to get memory information: %v", err)
+		return "", fmt.Errorf("Failed to get memory information: %w", err)
 	}
 
 	totalGB := float64(vmStat.Total) / (1024 * 1024 * 1024)
@@ -150,7 +187,7 @@ func (sh *SystemHealth) GetDiskInfo() (string, error) {
 	// Check disk status - monitoring available space
 	diskStat, err := disk.Usage("/")
 	if err != nil {
-		return "", fmt.Errorf("Failed to get disk information: %v", err)
+		return "", fmt.Errorf("Failed to get disk information: %w", err)
 	}
 
 	totalGB := float64(diskStat.Total) / (1024 * 1024 * 1024)
@@ -248,3 +285,78 @@ func (sh *SystemHealth) GetOverallHealthStatus() string {
 
 	return strings.Join(report, "\n")
 }
+
+// GetHealthSnapshot collects the same information as GetHealthReport as typed data
+func (sh *SystemHealth) GetHealthSnapshot() *HealthSnapshot {
+	snapshot := &HealthSnapshot{Healthy: true, Issues: []string{}}
+
+	if percentages, err := cpu.Percent(time.Second, false); err == nil && len(percentages) > 0 {
+		cpuStatus := &CPUStatus{Model: "Unknown", Cores: runtime.NumCPU(), UsagePercent: percentages[0], Status: "normal"}
+		if info, err := cpu.Info(); err == nil && len(info) > 0 {
+			cpuStatus.Model = info[0].ModelName
+		}
+		if cpuStatus.UsagePercent > 80 {
+			cpuStatus.Status = "high_load"
+		} else if cpuStatus.UsagePercent > 60 {
+			cpuStatus.Status = "warning"
+		}
+		if cpuStatus.UsagePercent > 90 {
+			snapshot.Healthy = false
+			snapshot.Issues = append(snapshot.Issues, "CPU usage is very high")
+		}
+		snapshot.CPU = cpuStatus
+	}
+
+	if vmStat, err := mem.VirtualMemory(); err == nil {
+		snapshot.Memory = &UsageStatus{
+			Total:        vmStat.Total,
+			Used:         vmStat.Used,
+			Free:         vmStat.Free,
+			UsagePercent: vmStat.UsedPercent,
+			Status:       usageLevel(vmStat.UsedPercent, 80, 90),
+		}
+		if vmStat.UsedPercent > 95 {
+			snapshot.Healthy = false
+			snapshot.Issues = append(snapshot.Issues, "Memory usage is at critical level")
+		}
+	}
+
+	if diskStat, err := disk.Usage("/"); err == nil {
+		usagePercent := (float64(diskStat.Used) / float64(diskStat.Total)) * 100
+		snapshot.Disk = &UsageStatus{
+			Total:        diskStat.Total,
+			Used:         diskStat.Used,
+			Free:         diskStat.Free,
+			UsagePercent: usagePercent,
+			Status:       usageLevel(usagePercent, 85, 95),
+		}
+		if usagePercent > 95 {
+			snapshot.Healthy = false
+			snapshot.Issues = append(snapshot.Issues, "Disk usage is above 95%")
+		}
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	snapshot.Runtime = RuntimeStatus{
+		Alloc:      m.Alloc,
+		TotalAlloc: m.TotalAlloc,
+		Sys:        m.Sys,
+		NumGC:      m.NumGC,
+		Goroutines: runtime.NumGoroutine(),
+	}
+
+	sh.LastCheck = time.Now()
+	snapshot.CheckedAt = sh.LastCheck
+	return snapshot
+}
+
+// usageLevel classifies a usage percentage against warning and critical thresholds
+func usageLevel(percent, warning, critical float64) string {
+	if percent > critical {
+		return "critical"
+	} else if percent > warning {
+		return "warning"
+	}
+	return "normal"
+}
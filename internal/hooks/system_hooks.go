@@ -21,14 +21,20 @@ import (
 	"strings"
 	"time"
 
+	"ena/internal/app"
 	"ena/internal/appdetect"
+	"ena/internal/audit"
 	"ena/internal/backup"
 	"ena/internal/batch"
 	"ena/internal/browser"
+	"ena/internal/events"
+	"ena/internal/fault"
 	"ena/internal/notifications"
 	"ena/internal/organizer"
+	"ena/internal/output"
 	"ena/internal/patterns"
 	"ena/internal/progress"
+	"ena/internal/settings"
 	"ena/internal/suggestions"
 	"ena/internal/theme"
 	"ena/internal/undo"
@@ -67,6 +73,7 @@ func requireArgs(args []string, n int, context string) error {
 
 // SystemHooks handles all system-level operations
 type SystemHooks struct {
+	Config              *settings.Config
 	FileManager         *system.FileManager
 	TerminalManager     *system.TerminalManager
 	AppManager          *system.AppManager
@@ -80,125 +87,28 @@ type SystemHooks struct {
 	PatternEngine       *patterns.PatternEngine
 	BackupEngine        *backup.BackupEngine
 	AppScanner          *appdetect.AppScanner
+	Events              *events.Bus
 }
 
-// Global theme manager instance for persistence
-var globalThemeManager *theme.ThemeManager
-
-// Global notification manager instance for persistence
-var globalNotificationManager *notifications.NotificationManager
-
-// Global analytics instance for persistence
-var globalAnalytics *suggestions.UsageAnalytics
-
-// Global batch manager instance for persistence
-var globalBatchManager *batch.BatchManager
-
-// Global undo manager instance for persistence
-var globalUndoManager *undo.UndoManager
-
-// Global file organizer instance for persistence
-var globalFileOrganizer *organizer.FileOrganizer
-
-// Global pattern engine instance for persistence
-var globalPatternEngine *patterns.PatternEngine
-
-// Global backup engine instance for persistence
-var globalBackupEngine *backup.BackupEngine
-
-// Global app scanner instance for persistence
-var globalAppScanner *appdetect.AppScanner
-
-// NewSystemHooks creates a new instance of system hooks
-func NewSystemHooks() *SystemHooks {
+// NewSystemHooks creates system hooks that use the managers of services
+func NewSystemHooks(services *app.App) *SystemHooks {
 	// Initialize all system operation handlers
 	return &SystemHooks{
-		FileManager:         system.NewFileManager(),
-		TerminalManager:     system.NewTerminalManager(),
-		AppManager:          system.NewAppManager(),
-		ThemeManager:        getGlobalThemeManager(),
-		NotificationManager: getGlobalNotificationManager(),
-		UsageAnalytics:      getGlobalAnalytics(),
-		BatchManager:        getGlobalBatchManager(),
-		UndoManager:         getGlobalUndoManager(),
-		FileOrganizer:       getGlobalFileOrganizer(),
-		PatternEngine:       getGlobalPatternEngine(),
-		BackupEngine:        getGlobalBackupEngine(),
-		AppScanner:          getGlobalAppScanner(),
-	}
-}
-
-// getGlobalThemeManager returns the global theme manager instance
-func getGlobalThemeManager() *theme.ThemeManager {
-	if globalThemeManager == nil {
-		globalThemeManager = theme.NewThemeManager()
-	}
-	return globalThemeManager
-}
-
-// getGlobalNotificationManager returns the global notification manager instance
-func getGlobalNotificationManager() *notifications.NotificationManager {
-	if globalNotificationManager == nil {
-		globalNotificationManager = notifications.NewNotificationManager()
-		globalNotificationManager.StartCleanupRoutine() // Start background cleanup
-	}
-	return globalNotificationManager
-}
-
-// getGlobalAnalytics returns the global analytics instance
-func getGlobalAnalytics() *suggestions.UsageAnalytics {
-	if globalAnalytics == nil {
-		globalAnalytics = suggestions.NewUsageAnalytics()
-	}
-	return globalAnalytics
-}
-
-// getGlobalBatchManager returns the global batch manager instance
-func getGlobalBatchManager() *batch.BatchManager {
-	if globalBatchManager == nil {
-		globalBatchManager = batch.NewBatchManager(getGlobalAnalytics())
+		Config:              services.Config,
+		FileManager:         services.Files,
+		TerminalManager:     services.Terminal,
+		AppManager:          services.Apps,
+		ThemeManager:        services.Themes,
+		NotificationManager: services.Notifications,
+		UsageAnalytics:      services.Analytics,
+		BatchManager:        services.Batch,
+		UndoManager:         services.Undo,
+		FileOrganizer:       services.Organizer,
+		PatternEngine:       services.Patterns,
+		BackupEngine:        services.Backups,
+		AppScanner:          services.AppScanner,
+		Events:              services.Events,
 	}
-	return globalBatchManager
-}
-
-// getGlobalUndoManager returns the global undo manager instance
-func getGlobalUndoManager() *undo.UndoManager {
-	if globalUndoManager == nil {
-		globalUndoManager = undo.NewUndoManager(getGlobalAnalytics())
-	}
-	return globalUndoManager
-}
-
-// getGlobalFileOrganizer returns the global file organizer instance
-func getGlobalFileOrganizer() *organizer.FileOrganizer {
-	if globalFileOrganizer == nil {
-		globalFileOrganizer = organizer.NewFileOrganizer(getGlobalAnalytics())
-	}
-	return globalFileOrganizer
-}
-
-// getGlobalPatternEngine returns the global pattern engine instance
-func getGlobalPatternEngine() *patterns.PatternEngine {
-	if globalPatternEngine == nil {
-		globalPatternEngine = patterns.NewPatternEngine(getGlobalAnalytics())
-	}
-	return globalPatternEngine
-}
-
-// getGlobalBackupEngine returns the global backup engine instance
-func getGlobalBackupEngine() *backup.BackupEngine {
-	if globalBackupEngine == nil {
-		globalBackupEngine = backup.NewBackupEngine(getGlobalAnalytics())
-	}
-	return globalBackupEngine
-}
-
-// getGlobalAppScanner returns the global app scanner instance
-func getGlobalAppScanner() *appdetect.AppScanner {
-	if globalAppScanner == nil {
-		globalAppScanner = appdetect.NewAppScanner(getGlobalAnalytics())
-	}
-	return globalAppScanner
 }
 
 // HandleFileOperation processes file-related commands
@@ -220,7 +130,7 @@ func (sh *SystemHooks) HandleFileOperation(args []string) (string, error) {
 			// Track create operation
 			if trackErr := sh.UndoManager.TrackOperation(undo.OpCreate, path, ""); trackErr != nil {
 				// Log error but don't fail the operation
-				fmt.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
+				output.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
 			}
 		}
 	case OpRead:
@@ -234,7 +144,7 @@ func (sh *SystemHooks) HandleFileOperation(args []string) (string, error) {
 		if err == nil {
 			// Track update operation
 			if trackErr := sh.UndoManager.TrackOperation(undo.OpUpdate, path, ""); trackErr != nil {
-				fmt.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
+				output.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
 			}
 		}
 	case OpCopy:
@@ -246,7 +156,7 @@ func (sh *SystemHooks) HandleFileOperation(args []string) (string, error) {
 		if err == nil {
 			// Track copy operation
 			if trackErr := sh.UndoManager.TrackOperation(undo.OpCopy, path, dest); trackErr != nil {
-				fmt.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
+				output.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
 			}
 		}
 	case OpMove:
@@ -258,11 +168,13 @@ func (sh *SystemHooks) HandleFileOperation(args []string) (string, error) {
 		if err == nil {
 			// Track move operation
 			if trackErr := sh.UndoManager.TrackOperation(undo.OpMove, path, dest); trackErr != nil {
-				fmt.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
+				output.Printf("⚠️ Warning: Failed to track undo operation: %v\n", trackErr)
 			}
 		}
 	case OpInfo:
 		result, err = sh.FileManager.GetFileInfo(path)
+	case OpDelete:
+		return sh.HandleFileDeletion(args[1:])
 	default:
 		return "", fmt.Errorf("Unknown file operation: \"%s\" - I don't understand that! 😅", operation)
 	}
@@ -286,10 +198,12 @@ func (sh *SystemHooks) HandleFolderOperation(args []string) (string, error) {
 		return sh.FileManager.ListFolder(path)
 	case OpDelete:
 		// Require --force flag for safety when deleting folders
-		if len(args) < 3 || args[2] != "--force" {
+		flags, force := takeFlag(args[2:], "--force")
+		_, permanent := takeFlag(flags, "--permanent")
+		if !force {
 			return "", fmt.Errorf("Folder deletion requires --force flag for safety! 😅")
 		}
-		return sh.FileManager.DeleteFolder(path)
+		return sh.FileManager.DeleteFolder(path, permanent)
 	case OpInfo:
 		return sh.FileManager.GetFolderInfo(path)
 	default:
@@ -407,13 +321,13 @@ func (sh *SystemHooks) HandleFileBrowser(args []string) (string, error) {
 	// Create and start file browser
 	browser, err := browser.NewFileBrowser(startPath)
 	if err != nil {
-		return "", fmt.Errorf("Failed to start file browser: %v", err)
+		return "", fmt.Errorf("Failed to start file browser: %w", err)
 	}
 	defer browser.Close()
 
 	selectedPath, err := browser.Start()
 	if err != nil {
-		return "", fmt.Errorf("File browser error: %v", err)
+		return "", fmt.Errorf("File browser error: %w", err)
 	}
 
 	return fmt.Sprintf("Selected file: \"%s\" ✨", selectedPath), nil
@@ -431,8 +345,12 @@ func (sh *SystemHooks) HandleDownload(args []string) (string, error) {
 	// Download with real HTTP and progress bar
 	err := progress.DownloadFileWithProgress(url, filename, nil)
 	if err != nil {
-		return "", fmt.Errorf("Failed to download file: %v", err)
+		sh.Events.Publish(events.New(progress.TopicDownloadFailed, fmt.Sprintf("Download of %s failed: %v", url, err),
+			progress.DownloadEvent{URL: url, Path: filename, Error: err.Error()}))
+		return "", fmt.Errorf("Failed to download file: %w", err)
 	}
+	sh.Events.Publish(events.New(progress.TopicDownloadCompleted, fmt.Sprintf("Downloaded %s to %s", url, filename),
+		progress.DownloadEvent{URL: url, Path: filename}))
 
 	return fmt.Sprintf("Downloaded \"%s\" to \"%s\"! ✨", url, filename), nil
 }
@@ -457,7 +375,7 @@ func (sh *SystemHooks) HandleMultiProgress(args []string) (string, error) {
 	// Process multiple files with progress bars
 	err := progress.ProcessMultipleFilesWithProgress(files, operation)
 	if err != nil {
-		return "", fmt.Errorf("Failed to process files: %v", err)
+		return "", fmt.Errorf("Failed to process files: %w", err)
 	}
 
 	return fmt.Sprintf("Processed %d files with %s operation! ✨", len(files), operation), nil
@@ -513,7 +431,7 @@ func (sh *SystemHooks) demonstratePauseResume() (string, error) {
 		Persistent:   true,
 	})
 
-	fmt.Println("🎯 Pause/Resume Demo - Progress bar will pause at 50%")
+	output.Printf("🎯 Pause/Resume Demo - Progress bar will pause at 50%%\n")
 
 	// Simulate progress with pause
 	for i := int64(0); i <= 1000; i += 10 {
@@ -522,10 +440,10 @@ func (sh *SystemHooks) demonstratePauseResume() (string, error) {
 
 		// Pause at 50%
 		if i == 500 {
-			fmt.Println("\n⏸️ Pausing at 50%...")
+			output.Printf("\n⏸️ Pausing at 50%%...\n")
 			pb.Pause()
 			time.Sleep(2 * time.Second)
-			fmt.Println("▶️ Resuming...")
+			output.Printf("▶️ Resuming...\n")
 			pb.Resume()
 		}
 
@@ -571,7 +489,7 @@ func (sh *SystemHooks) testStatePersistence() (string, error) {
 
 	// Save state
 	if err := pb.SaveState(); err != nil {
-		return "", fmt.Errorf("failed to save state: %v", err)
+		return "", fmt.Errorf("failed to save state: %w", err)
 	}
 
 	// Create a new progress bar and load state
@@ -605,7 +523,7 @@ func (sh *SystemHooks) testAdaptiveRefresh() (string, error) {
 		AdaptiveRefresh: true,
 	})
 
-	fmt.Println("🎯 Adaptive Refresh Test - Speed will vary to test adaptive updates")
+	output.Printf("🎯 Adaptive Refresh Test - Speed will vary to test adaptive updates\n")
 
 	// Simulate varying speeds
 	for i := int64(0); i <= 1000; i += 5 {
@@ -642,7 +560,7 @@ func (sh *SystemHooks) testCustomThemes() (string, error) {
 		{"Minimal", &progress.MinimalTheme},
 	}
 
-	fmt.Println("🎨 Custom Themes Demo - Different visual styles")
+	output.Printf("🎨 Custom Themes Demo - Different visual styles\n")
 
 	for _, t := range themes {
 		fmt.Printf("\n%s Theme:\n", t.name)
@@ -674,13 +592,13 @@ func (sh *SystemHooks) testCustomThemes() (string, error) {
 
 // testEventHooks tests event callback functionality
 func (sh *SystemHooks) testEventHooks() (string, error) {
-	fmt.Println("🎯 Event Hooks Demo - Callbacks for different events")
+	output.Printf("🎯 Event Hooks Demo - Callbacks for different events\n")
 
 	// Create event callbacks
 	callbacks := map[progress.EventType][]progress.EventCallback{
 		progress.EventStart: {
 			func(event progress.EventType, pb *progress.ProgressBar, data interface{}) {
-				fmt.Println("🚀 Started!")
+				output.Printf("🚀 Started!\n")
 			},
 		},
 		progress.EventUpdate: {
@@ -688,24 +606,24 @@ func (sh *SystemHooks) testEventHooks() (string, error) {
 				if data != nil {
 					current := data.(int64)
 					if current%25 == 0 {
-						fmt.Printf("📊 Milestone: %d%%\n", current)
+						output.Printf("📊 Milestone: %d%%\n", current)
 					}
 				}
 			},
 		},
 		progress.EventPause: {
 			func(event progress.EventType, pb *progress.ProgressBar, data interface{}) {
-				fmt.Println("⏸️ Paused!")
+				output.Printf("⏸️ Paused!\n")
 			},
 		},
 		progress.EventResume: {
 			func(event progress.EventType, pb *progress.ProgressBar, data interface{}) {
-				fmt.Println("▶️ Resumed!")
+				output.Printf("▶️ Resumed!\n")
 			},
 		},
 		progress.EventComplete: {
 			func(event progress.EventType, pb *progress.ProgressBar, data interface{}) {
-				fmt.Println("🎉 Completed!")
+				output.Printf("🎉 Completed!\n")
 			},
 		},
 	}
@@ -742,7 +660,7 @@ func (sh *SystemHooks) testEventHooks() (string, error) {
 
 // testHttpDownload tests real HTTP download functionality
 func (sh *SystemHooks) testHttpDownload() (string, error) {
-	fmt.Println("🌐 Real HTTP Download Demo")
+	output.Printf("🌐 Real HTTP Download Demo\n")
 
 	// Use a small test file
 	testURL := "https://httpbin.org/bytes/1024" // 1KB test file
@@ -761,7 +679,7 @@ func (sh *SystemHooks) testHttpDownload() (string, error) {
 	})
 
 	if err != nil {
-		return "", fmt.Errorf("HTTP download failed: %v", err)
+		return "", fmt.Errorf("HTTP download failed: %w", err)
 	}
 
 	// Clean up
@@ -821,52 +739,55 @@ func (sh *SystemHooks) startFileWatching(paths []string) (string, error) {
 		paths = []string{"."}
 	}
 
-	// Create file watcher configuration
-	config := &watcher.WatchConfig{
-		Paths:            paths,
-		Recursive:        true,
-		IncludeHidden:    false,
-		DebounceTime:     100 * time.Millisecond,
-		EventCallbacks:   make(map[watcher.EventType][]watcher.EventCallback),
-		DebugMode:        false,
-		LogIgnoredEvents: false,
+	// Watch absolute paths so events stay meaningful if the working directory changes
+	for i, path := range paths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			paths[i] = absPath
+		}
 	}
 
+	// Create file watcher configuration from the watch section of config.yaml
+	watchConfig := sh.Config.Watch
+	config := &watchConfig
+	config.Paths = paths
+	config.EventCallbacks = make(map[watcher.EventType][]watcher.EventCallback)
+
 	// Add event callbacks
 	config.EventCallbacks[watcher.EventCreate] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("📁 Created: %s\n", event.Path)
+			output.Printf("📁 Created: %s\n", event.Path)
 		},
 	}
 	config.EventCallbacks[watcher.EventModify] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("✏️ Modified: %s\n", event.Path)
+			output.Printf("✏️ Modified: %s\n", event.Path)
 		},
 	}
 	config.EventCallbacks[watcher.EventDelete] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🗑️ Deleted: %s\n", event.Path)
+			output.Printf("🗑️ Deleted: %s\n", event.Path)
 		},
 	}
 
 	// Create and start file watcher
+	config.Events = sh.Events
 	fileWatcher, err := watcher.NewFileWatcher(config)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create file watcher: %v", err)
+		return "", fmt.Errorf("Failed to create file watcher: %w", err)
 	}
 
 	// Add paths to watcher
 	for _, path := range paths {
 		err = fileWatcher.AddPath(path)
 		if err != nil {
-			return "", fmt.Errorf("Failed to add path %s: %v", path, err)
+			return "", fmt.Errorf("Failed to add path %s: %w", path, err)
 		}
 	}
 
 	// Start watching
 	err = fileWatcher.Start()
 	if err != nil {
-		return "", fmt.Errorf("Failed to start file watcher: %v", err)
+		return "", fmt.Errorf("Failed to start file watcher: %w", err)
 	}
 
 	// Store watcher instance
@@ -883,7 +804,7 @@ func (sh *SystemHooks) stopFileWatching() (string, error) {
 
 	err := sh.FileWatcher.Stop()
 	if err != nil {
-		return "", fmt.Errorf("Failed to stop file watcher: %v", err)
+		return "", fmt.Errorf("Failed to stop file watcher: %w", err)
 	}
 
 	sh.FileWatcher = nil
@@ -918,13 +839,13 @@ func (sh *SystemHooks) getFileWatchingStatus() (string, error) {
 
 // demonstrateFileWatching demonstrates file watching functionality
 func (sh *SystemHooks) demonstrateFileWatching() (string, error) {
-	fmt.Println("👀 File Watching Demo - Watch for file changes in /tmp")
+	output.Printf("👀 File Watching Demo - Watch for file changes in /tmp\n")
 
 	// Create demo directory
 	demoDir := "/tmp/ena_watch_demo"
 	err := os.MkdirAll(demoDir, 0755)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create demo directory: %v", err)
+		return "", fmt.Errorf("Failed to create demo directory: %w", err)
 	}
 
 	// Create file watcher configuration
@@ -942,42 +863,43 @@ func (sh *SystemHooks) demonstrateFileWatching() (string, error) {
 	config.EventCallbacks[watcher.EventCreate] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
 			if !event.IsDir {
-				fmt.Printf("📁 Created: %s (%.2f KB)\n", event.Path, float64(event.Size)/1024)
+				output.Printf("📁 Created: %s (%.2f KB)\n", event.Path, float64(event.Size)/1024)
 			}
 		},
 	}
 	config.EventCallbacks[watcher.EventModify] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
 			if !event.IsDir {
-				fmt.Printf("✏️ Modified: %s (%.2f KB)\n", event.Path, float64(event.Size)/1024)
+				output.Printf("✏️ Modified: %s (%.2f KB)\n", event.Path, float64(event.Size)/1024)
 			}
 		},
 	}
 	config.EventCallbacks[watcher.EventDelete] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
 			if !event.IsDir {
-				fmt.Printf("🗑️ Deleted: %s\n", event.Path)
+				output.Printf("🗑️ Deleted: %s\n", event.Path)
 			}
 		},
 	}
 
 	// Create and start file watcher
+	config.Events = sh.Events
 	fileWatcher, err := watcher.NewFileWatcher(config)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create file watcher: %v", err)
+		return "", fmt.Errorf("Failed to create file watcher: %w", err)
 	}
 
 	err = fileWatcher.AddPath(demoDir)
 	if err != nil {
-		return "", fmt.Errorf("Failed to add demo directory: %v", err)
+		return "", fmt.Errorf("Failed to add demo directory: %w", err)
 	}
 
 	err = fileWatcher.Start()
 	if err != nil {
-		return "", fmt.Errorf("Failed to start file watcher: %v", err)
+		return "", fmt.Errorf("Failed to start file watcher: %w", err)
 	}
 
-	fmt.Println("🎯 Creating test files...")
+	output.Printf("🎯 Creating test files...\n")
 
 	// Create some test files
 	testFiles := []string{
@@ -1006,7 +928,7 @@ func (sh *SystemHooks) demonstrateFileWatching() (string, error) {
 		time.Sleep(200 * time.Millisecond)
 	}
 
-	fmt.Println("🎯 Modifying files...")
+	output.Printf("🎯 Modifying files...\n")
 
 	// Modify files
 	for _, filename := range testFiles {
@@ -1021,7 +943,7 @@ func (sh *SystemHooks) demonstrateFileWatching() (string, error) {
 		time.Sleep(200 * time.Millisecond)
 	}
 
-	fmt.Println("🎯 Deleting files...")
+	output.Printf("🎯 Deleting files...\n")
 
 	// Delete files
 	for _, filename := range testFiles {
@@ -1041,13 +963,13 @@ func (sh *SystemHooks) demonstrateFileWatching() (string, error) {
 
 // testDebugMode tests the enhanced debug features
 func (sh *SystemHooks) testDebugMode() (string, error) {
-	fmt.Println("🔍 Debug Mode Test - Enhanced file watching with detailed logging")
+	output.Printf("🔍 Debug Mode Test - Enhanced file watching with detailed logging\n")
 
 	// Create test directory
 	testDir := "/tmp/ena_debug_test"
 	err := os.MkdirAll(testDir, 0755)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create test directory: %v", err)
+		return "", fmt.Errorf("Failed to create test directory: %w", err)
 	}
 
 	// Create file watcher configuration with debug enabled
@@ -1066,42 +988,43 @@ func (sh *SystemHooks) testDebugMode() (string, error) {
 	// Add debug event callbacks
 	config.EventCallbacks[watcher.EventCreate] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🔍 DEBUG CREATE: %s (size: %d)\n", event.Path, event.Size)
+			output.Printf("🔍 DEBUG CREATE: %s (size: %d)\n", event.Path, event.Size)
 		},
 	}
 	config.EventCallbacks[watcher.EventModify] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🔍 DEBUG MODIFY: %s (size: %d)\n", event.Path, event.Size)
+			output.Printf("🔍 DEBUG MODIFY: %s (size: %d)\n", event.Path, event.Size)
 		},
 	}
 	config.EventCallbacks[watcher.EventDelete] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🔍 DEBUG DELETE: %s\n", event.Path)
+			output.Printf("🔍 DEBUG DELETE: %s\n", event.Path)
 		},
 	}
 	config.EventCallbacks[watcher.EventMove] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🔍 DEBUG MOVE: %s (size: %d)\n", event.Path, event.Size)
+			output.Printf("🔍 DEBUG MOVE: %s (size: %d)\n", event.Path, event.Size)
 		},
 	}
 
 	// Create and start file watcher
+	config.Events = sh.Events
 	fileWatcher, err := watcher.NewFileWatcher(config)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create file watcher: %v", err)
+		return "", fmt.Errorf("Failed to create file watcher: %w", err)
 	}
 
 	err = fileWatcher.AddPath(testDir)
 	if err != nil {
-		return "", fmt.Errorf("Failed to add test directory: %v", err)
+		return "", fmt.Errorf("Failed to add test directory: %w", err)
 	}
 
 	err = fileWatcher.Start()
 	if err != nil {
-		return "", fmt.Errorf("Failed to start file watcher: %v", err)
+		return "", fmt.Errorf("Failed to start file watcher: %w", err)
 	}
 
-	fmt.Println("🎯 Testing file extension filtering...")
+	output.Printf("🎯 Testing file extension filtering...\n")
 
 	// Create files with different extensions
 	testFiles := []string{
@@ -1122,7 +1045,7 @@ func (sh *SystemHooks) testDebugMode() (string, error) {
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	fmt.Println("🎯 Testing move detection...")
+	output.Printf("🎯 Testing move detection...\n")
 
 	// Test move operation
 	sourceFile := filepath.Join(testDir, "move_test.txt")
@@ -1140,7 +1063,7 @@ func (sh *SystemHooks) testDebugMode() (string, error) {
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	fmt.Println("🎯 Testing debounce functionality...")
+	output.Printf("🎯 Testing debounce functionality...\n")
 
 	// Rapid modifications to test debounce
 	debounceFile := filepath.Join(testDir, "debounce_test.txt")
@@ -1165,13 +1088,13 @@ func (sh *SystemHooks) testDebugMode() (string, error) {
 
 // testAdvancedFeatures tests all advanced file watcher features
 func (sh *SystemHooks) testAdvancedFeatures() (string, error) {
-	fmt.Println("🚀 Advanced Features Test - Enterprise-grade file watching capabilities")
+	output.Printf("🚀 Advanced Features Test - Enterprise-grade file watching capabilities\n")
 
 	// Create test directory
 	testDir := "/tmp/ena_advanced_test"
 	err := os.MkdirAll(testDir, 0755)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create test directory: %v", err)
+		return "", fmt.Errorf("Failed to create test directory: %w", err)
 	}
 
 	// Create advanced configuration
@@ -1202,37 +1125,38 @@ func (sh *SystemHooks) testAdvancedFeatures() (string, error) {
 	// Add event callbacks with metrics
 	config.EventCallbacks[watcher.EventCreate] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🚀 ADVANCED CREATE: %s (size: %d)\n", event.Path, event.Size)
+			output.Printf("🚀 ADVANCED CREATE: %s (size: %d)\n", event.Path, event.Size)
 		},
 	}
 	config.EventCallbacks[watcher.EventModify] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🚀 ADVANCED MODIFY: %s (size: %d)\n", event.Path, event.Size)
+			output.Printf("🚀 ADVANCED MODIFY: %s (size: %d)\n", event.Path, event.Size)
 		},
 	}
 	config.EventCallbacks[watcher.EventDelete] = []watcher.EventCallback{
 		func(event watcher.FileEvent) {
-			fmt.Printf("🚀 ADVANCED DELETE: %s\n", event.Path)
+			output.Printf("🚀 ADVANCED DELETE: %s\n", event.Path)
 		},
 	}
 
 	// Create and start enhanced file watcher
+	config.Events = sh.Events
 	fileWatcher, err := watcher.NewFileWatcher(config)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create file watcher: %v", err)
+		return "", fmt.Errorf("Failed to create file watcher: %w", err)
 	}
 
 	err = fileWatcher.AddPath(testDir)
 	if err != nil {
-		return "", fmt.Errorf("Failed to add test directory: %v", err)
+		return "", fmt.Errorf("Failed to add test directory: %w", err)
 	}
 
 	err = fileWatcher.StartEnhanced()
 	if err != nil {
-		return "", fmt.Errorf("Failed to start enhanced watcher: %v", err)
+		return "", fmt.Errorf("Failed to start enhanced watcher: %w", err)
 	}
 
-	fmt.Println("🎯 Testing event batching and prioritization...")
+	output.Printf("🎯 Testing event batching and prioritization...\n")
 
 	// Create multiple files rapidly to test batching
 	for i := 0; i < 8; i++ {
@@ -1247,7 +1171,7 @@ func (sh *SystemHooks) testAdvancedFeatures() (string, error) {
 		time.Sleep(50 * time.Millisecond) // Rapid creation
 	}
 
-	fmt.Println("🎯 Testing dynamic path management...")
+	output.Printf("🎯 Testing dynamic path management...\n")
 
 	// Create subdirectory and add it dynamically
 	subDir := filepath.Join(testDir, "dynamic_subdir")
@@ -1257,7 +1181,7 @@ func (sh *SystemHooks) testAdvancedFeatures() (string, error) {
 		if err != nil {
 			fmt.Printf("Failed to add dynamic path: %v\n", err)
 		} else {
-			fmt.Printf("✅ Dynamically added path: %s\n", subDir)
+			output.Printf("✅ Dynamically added path: %s\n", subDir)
 		}
 	}
 
@@ -1270,11 +1194,11 @@ func (sh *SystemHooks) testAdvancedFeatures() (string, error) {
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	fmt.Println("🎯 Testing metrics collection...")
+	output.Printf("🎯 Testing metrics collection...\n")
 
 	// Show metrics
 	metrics := fileWatcher.GetMetrics()
-	fmt.Printf("📊 Metrics:\n")
+	output.Printf("📊 Metrics:\n")
 	fmt.Printf("  Events Processed: %d\n", metrics.EventsProcessed)
 	fmt.Printf("  Events Batched: %d\n", metrics.EventsBatched)
 	fmt.Printf("  Paths Watched: %d\n", metrics.PathsWatched)
@@ -1282,12 +1206,12 @@ func (sh *SystemHooks) testAdvancedFeatures() (string, error) {
 	fmt.Printf("  Retries Attempted: %d\n", metrics.RetriesAttempted)
 	fmt.Printf("  Uptime: %v\n", time.Since(metrics.StartTime))
 
-	fmt.Println("🎯 Testing error recovery...")
+	output.Printf("🎯 Testing error recovery...\n")
 
 	// Simulate error by removing a path that doesn't exist
 	err = fileWatcher.RemovePathDynamic("/nonexistent/path")
 	if err != nil {
-		fmt.Printf("✅ Error recovery handled: %v\n", err)
+		output.Printf("✅ Error recovery handled: %v\n", err)
 	}
 
 	// Stop watcher
@@ -1310,9 +1234,12 @@ func (sh *SystemHooks) addPathDynamic(args []string) (string, error) {
 	}
 
 	path := args[0]
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
 	err := sh.FileWatcher.AddPathDynamic(path)
 	if err != nil {
-		return "", fmt.Errorf("Failed to add path dynamically: %v", err)
+		return "", fmt.Errorf("Failed to add path dynamically: %w", err)
 	}
 
 	return fmt.Sprintf("Dynamically added path: %s ✨", path), nil
@@ -1329,9 +1256,12 @@ func (sh *SystemHooks) removePathDynamic(args []string) (string, error) {
 	}
 
 	path := args[0]
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
 	err := sh.FileWatcher.RemovePathDynamic(path)
 	if err != nil {
-		return "", fmt.Errorf("Failed to remove path dynamically: %v", err)
+		return "", fmt.Errorf("Failed to remove path dynamically: %w", err)
 	}
 
 	return fmt.Sprintf("Dynamically removed path: %s ✨", path), nil
@@ -1373,7 +1303,7 @@ func (sh *SystemHooks) reloadConfig() (string, error) {
 
 	err := sh.FileWatcher.ReloadConfig()
 	if err != nil {
-		return "", fmt.Errorf("Failed to reload configuration: %v", err)
+		return "", fmt.Errorf("Failed to reload configuration: %w", err)
 	}
 
 	return "Configuration reloaded successfully ✨", nil
@@ -1498,7 +1428,7 @@ func (sh *SystemHooks) setTheme(args []string) (string, error) {
 	themeName := args[0]
 	err := sh.ThemeManager.SetTheme(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to set theme: %v", err)
+		return "", fmt.Errorf("Failed to set theme: %w", err)
 	}
 
 	theme := sh.ThemeManager.GetCurrentColorScheme()
@@ -1522,7 +1452,7 @@ func (sh *SystemHooks) previewTheme(args []string) (string, error) {
 	themeName := args[0]
 	preview, err := sh.ThemeManager.PreviewTheme(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to preview theme: %v", err)
+		return "", fmt.Errorf("Failed to preview theme: %w", err)
 	}
 
 	return preview, nil
@@ -1537,7 +1467,7 @@ func (sh *SystemHooks) showThemeInfo(args []string) (string, error) {
 	themeName := args[0]
 	info, err := sh.ThemeManager.GetThemeInfo(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to get theme info: %v", err)
+		return "", fmt.Errorf("Failed to get theme info: %w", err)
 	}
 
 	result := fmt.Sprintf("🎨 Theme Information: %s\n", sh.ThemeManager.Colorize("primary", info["name"].(string)))
@@ -1557,7 +1487,7 @@ func (sh *SystemHooks) exportTheme(args []string) (string, error) {
 	themeName := args[0]
 	export, err := sh.ThemeManager.ExportTheme(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to export theme: %v", err)
+		return "", fmt.Errorf("Failed to export theme: %w", err)
 	}
 
 	return export, nil
@@ -1565,13 +1495,13 @@ func (sh *SystemHooks) exportTheme(args []string) (string, error) {
 
 // demonstrateThemes demonstrates all available themes
 func (sh *SystemHooks) demonstrateThemes() (string, error) {
-	fmt.Println("🎨 Theme Demonstration - Showcasing all available themes")
+	output.Printf("🎨 Theme Demonstration - Showcasing all available themes\n")
 
 	themes := sh.ThemeManager.GetAvailableThemes()
 	currentTheme := sh.ThemeManager.GetCurrentTheme()
 
 	for _, themeName := range themes {
-		fmt.Printf("\n🎨 Theme: %s\n", themeName)
+		output.Printf("\n🎨 Theme: %s\n", themeName)
 
 		// Temporarily set theme for demonstration
 		originalTheme := sh.ThemeManager.GetCurrentTheme()
@@ -1626,7 +1556,7 @@ func (sh *SystemHooks) toggleTheme() (string, error) {
 
 	err := sh.ThemeManager.SetTheme(newTheme)
 	if err != nil {
-		return "", fmt.Errorf("Failed to toggle theme: %v", err)
+		return "", fmt.Errorf("Failed to toggle theme: %w", err)
 	}
 
 	newScheme := sh.ThemeManager.GetCurrentColorScheme()
@@ -1703,7 +1633,7 @@ func (sh *SystemHooks) createCustomTheme(args []string) (string, error) {
 
 	err := sh.ThemeManager.CreateCustomTheme(name, description, isDark, colors)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create theme: %v", err)
+		return "", fmt.Errorf("Failed to create theme: %w", err)
 	}
 
 	return fmt.Sprintf("Custom theme '%s' created successfully! ✨",
@@ -1719,7 +1649,7 @@ func (sh *SystemHooks) deleteCustomTheme(args []string) (string, error) {
 	themeName := args[0]
 	err := sh.ThemeManager.DeleteTheme(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to delete theme: %v", err)
+		return "", fmt.Errorf("Failed to delete theme: %w", err)
 	}
 
 	return fmt.Sprintf("Theme '%s' deleted successfully ✨",
@@ -1735,7 +1665,7 @@ func (sh *SystemHooks) saveTheme(args []string) (string, error) {
 	themeName := args[0]
 	err := sh.ThemeManager.SaveTheme(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to save theme: %v", err)
+		return "", fmt.Errorf("Failed to save theme: %w", err)
 	}
 
 	return fmt.Sprintf("Theme '%s' saved to disk successfully ✨",
@@ -1751,7 +1681,7 @@ func (sh *SystemHooks) loadTheme(args []string) (string, error) {
 	filename := args[0]
 	err := sh.ThemeManager.LoadTheme(filename)
 	if err != nil {
-		return "", fmt.Errorf("Failed to load theme: %v", err)
+		return "", fmt.Errorf("Failed to load theme: %w", err)
 	}
 
 	return fmt.Sprintf("Theme loaded from '%s' successfully ✨",
@@ -1770,7 +1700,7 @@ func (sh *SystemHooks) setThemeColor(args []string) (string, error) {
 
 	err := sh.ThemeManager.SetColor(themeName, colorElement, hexValue)
 	if err != nil {
-		return "", fmt.Errorf("Failed to set color: %v", err)
+		return "", fmt.Errorf("Failed to set color: %w", err)
 	}
 
 	return fmt.Sprintf("Color '%s' set to %s in theme '%s' ✨",
@@ -1788,7 +1718,7 @@ func (sh *SystemHooks) validateTheme(args []string) (string, error) {
 	themeName := args[0]
 	theme, err := sh.ThemeManager.GetTheme(themeName)
 	if err != nil {
-		return "", fmt.Errorf("Theme not found: %v", err)
+		return "", fault.NotFound("Theme not found: %w", err)
 	}
 
 	err = sh.ThemeManager.ValidateTheme(theme)
@@ -1835,30 +1765,22 @@ func min(a, b int) int {
 
 // HandleFileDeletion handles file deletion with safety checks
 func (sh *SystemHooks) HandleFileDeletion(args []string) (string, error) {
+	// Flags may come before or after the path
+	args, force := takeFlag(args, "--force")
+	args, permanent := takeFlag(args, "--permanent")
 	if err := requireArgs(args, 1, "File deletion"); err != nil {
 		return "", err
 	}
 
 	path := args[0]
-	force := false
-
-	// Check for force flag for safety
-	for i, arg := range args {
-		if arg == "--force" {
-			force = true
-			// Remove the --force flag from args
-			args = append(args[:i], args[i+1:]...)
-			break
-		}
-	}
 
 	if !force {
 		// Require --force flag for safety
 		return "", fmt.Errorf("File deletion requires --force flag for safety! 😅")
 	}
 
-	// Create backup before deletion if enabled
-	if sh.BackupEngine != nil {
+	// Create backup before a permanent deletion if enabled; trashed files can be restored from the trash
+	if permanent && sh.BackupEngine != nil {
 		config := sh.BackupEngine.GetConfig()
 		if config.Enabled {
 			// Check if file exists before creating backup
@@ -1869,15 +1791,29 @@ func (sh *SystemHooks) HandleFileDeletion(args []string) (string, error) {
 					[]string{"auto", "delete"})
 				if backupErr != nil {
 					// Log backup error but don't fail the operation
-					fmt.Printf("⚠️ Warning: Failed to create backup before deletion: %v\n", backupErr)
+					output.Printf("⚠️ Warning: Failed to create backup before deletion: %v\n", backupErr)
 				} else {
-					fmt.Printf("✅ Backup created before deletion\n")
+					output.Printf("✅ Backup created before deletion\n")
 				}
 			}
 		}
 	}
 
-	return sh.FileManager.DeleteFile(path, force)
+	return sh.FileManager.DeleteFile(path, force, permanent)
+}
+
+// takeFlag removes every occurrence of flag from args, reporting whether there was one
+func takeFlag(args []string, flag string) ([]string, bool) {
+	var rest []string
+	found := false
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
 }
 
 // RestartSystem restarts the system
@@ -1885,8 +1821,9 @@ func (sh *SystemHooks) RestartSystem() (string, error) {
 	// Restart the system with proper safety warnings
 	cmd := exec.Command("sudo", "reboot")
 	err := cmd.Run()
+	audit.Record("system", "restart", "", "", err)
 	if err != nil {
-		return "", fmt.Errorf("Restart failed: %v", err)
+		return "", fmt.Errorf("Restart failed: %w", err)
 	}
 
 	return "⚠️  System restarting... Are you sure? Thank you for using Ena! ✨ (╹◡╹)", nil
@@ -1897,8 +1834,9 @@ func (sh *SystemHooks) ShutdownSystem() (string, error) {
 	// Shutdown the system with proper safety warnings
 	cmd := exec.Command("sudo", "shutdown", "now")
 	err := cmd.Run()
+	audit.Record("system", "shutdown", "", "", err)
 	if err != nil {
-		return "", fmt.Errorf("Shutdown failed: %v", err)
+		return "", fmt.Errorf("Shutdown failed: %w", err)
 	}
 
 	return "⚠️  System shutting down... Are you sure? Thank you for using Ena! ✨ (╹◡╹)", nil
@@ -1909,8 +1847,9 @@ func (sh *SystemHooks) SleepSystem() (string, error) {
 	// Put the system to sleep gently
 	cmd := exec.Command("systemctl", "suspend")
 	err := cmd.Run()
+	audit.Record("system", "sleep", "", "", err)
 	if err != nil {
-		return "", fmt.Errorf("Sleep failed: %v", err)
+		return "", fmt.Errorf("Sleep failed: %w", err)
 	}
 
 	return "System is going to sleep... Good night! ✨ (╹◡╹)♡", nil
@@ -2002,7 +1941,7 @@ func (sh *SystemHooks) HandleNotificationOperation(args []string) (string, error
 func (sh *SystemHooks) testNotification() (string, error) {
 	err := sh.NotificationManager.TestNotification()
 	if err != nil {
-		return "", fmt.Errorf("Failed to send test notification: %v", err)
+		return "", fmt.Errorf("Failed to send test notification: %w", err)
 	}
 
 	return "Test notification sent! Check your desktop ✨", nil
@@ -2040,7 +1979,7 @@ func (sh *SystemHooks) sendCustomNotification(args []string) (string, error) {
 
 	err := sh.NotificationManager.SendNotification(notification)
 	if err != nil {
-		return "", fmt.Errorf("Failed to send notification: %v", err)
+		return "", fmt.Errorf("Failed to send notification: %w", err)
 	}
 
 	return fmt.Sprintf("Notification sent successfully! ✨"), nil
@@ -2142,12 +2081,21 @@ func (sh *SystemHooks) showNotificationConfig() (string, error) {
 	result += fmt.Sprintf("Icon Path: %s\n", config.IconPath)
 	result += fmt.Sprintf("Timeout: %s\n", config.Timeout)
 
+	automatic := config.Automatic
+	result += "\nAutomatic:\n"
+	result += fmt.Sprintf("  Batch Jobs: %t\n", automatic.Batch)
+	result += fmt.Sprintf("  Pattern Runs: %t\n", automatic.Patterns)
+	result += fmt.Sprintf("  Backups: %t\n", automatic.Backups)
+	result += fmt.Sprintf("  Downloads: %t\n", automatic.Downloads)
+	result += fmt.Sprintf("  App Scans: %t\n", automatic.AppScans)
+	result += fmt.Sprintf("  Long Commands: %t (after %s)\n", automatic.LongCommands, config.LongCommandThreshold)
+
 	return result, nil
 }
 
 // demonstrateNotifications demonstrates different notification types
 func (sh *SystemHooks) demonstrateNotifications() (string, error) {
-	fmt.Println("🔔 Notification Demonstration - Sending different types of notifications")
+	output.Printf("🔔 Notification Demonstration - Sending different types of notifications\n")
 
 	// Send different types of notifications
 	notifications := []struct {
@@ -2267,12 +2215,12 @@ func (sh *SystemHooks) HandleFeedbackOperation(args []string) (string, error) {
 	// Validate feedback
 	validFeedback := []string{"helpful", "not_helpful", "dismiss"}
 	if !contains(validFeedback, feedback) {
-		return "", fmt.Errorf("Invalid feedback. Must be one of: %s", strings.Join(validFeedback, ", "))
+		return "", fault.Invalid("Invalid feedback. Must be one of: %s", strings.Join(validFeedback, ", "))
 	}
 
 	err := sh.UsageAnalytics.ProvideFeedback(suggestionID, feedback)
 	if err != nil {
-		return "", fmt.Errorf("Error providing feedback: %v", err)
+		return "", fmt.Errorf("Error providing feedback: %w", err)
 	}
 
 	return "🌸 Thank you for the feedback! I'll use this to improve my suggestions. (╹◡╹)♡", nil
@@ -2363,7 +2311,7 @@ func (sh *SystemHooks) HandlePatternOperation(args []string) (string, error) {
 
 		result, err := sh.PatternEngine.ExecuteOperation(tempOp.ID, true) // dry run
 		if err != nil {
-			return "", fmt.Errorf("Failed to execute pattern search: %v", err)
+			return "", fmt.Errorf("Failed to execute pattern search: %w", err)
 		}
 
 		if result.FilesMatched == 0 {
@@ -2411,7 +2359,7 @@ func (sh *SystemHooks) HandlePatternOperation(args []string) (string, error) {
 
 		err := sh.PatternEngine.AddOperation(operation)
 		if err != nil {
-			return "", fmt.Errorf("Failed to create operation: %v", err)
+			return "", fmt.Errorf("Failed to create operation: %w", err)
 		}
 		return fmt.Sprintf("✅ Successfully created pattern operation: %s", name), nil
 
@@ -2460,14 +2408,14 @@ func (sh *SystemHooks) HandleBackupOperation(args []string) (string, error) {
 
 		// Check if file exists
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return "", fmt.Errorf("File does not exist: %s", filePath)
+			return "", fault.NotFound("File does not exist: %s", filePath)
 		}
 
 		operationID := fmt.Sprintf("manual_%d", time.Now().UnixNano())
 		metadata, err := sh.BackupEngine.CreateBackup(filePath, operationID,
 			fmt.Sprintf("Manual backup of %s", filepath.Base(filePath)), []string{"manual"})
 		if err != nil {
-			return "", fmt.Errorf("Failed to create backup: %v", err)
+			return "", fmt.Errorf("Failed to create backup: %w", err)
 		}
 
 		return fmt.Sprintf("✅ Backup created successfully!\n🆔 Backup ID: %s\n📂 Path: %s\n📊 Size: %s",
@@ -2510,7 +2458,7 @@ func (sh *SystemHooks) HandleBackupOperation(args []string) (string, error) {
 	case "cleanup":
 		cleanedCount, err := sh.BackupEngine.CleanupExpiredBackups()
 		if err != nil {
-			return "", fmt.Errorf("Failed to cleanup backups: %v", err)
+			return "", fmt.Errorf("Failed to cleanup backups: %w", err)
 		}
 
 		if cleanedCount == 0 {
@@ -2536,7 +2484,7 @@ func (sh *SystemHooks) HandleAppDetectionOperation(args []string) (string, error
 
 		result, err := sh.AppScanner.ScanForApps(deepScan)
 		if err != nil {
-			return "", fmt.Errorf("Failed to scan applications: %v", err)
+			return "", fmt.Errorf("Failed to scan applications: %w", err)
 		}
 
 		output := fmt.Sprintf("✅ Scan completed in %s!\n", result.ScanDuration.String())
@@ -0,0 +1,302 @@
+/**
+ * Shell-Style Lexer
+ *
+ * Splits interactive input into words the way a POSIX shell would: single
+ * and double quotes, backslash escapes, ~ and $VAR expansion, and # comments.
+ * Optionally the control operators |, &&, || and ; become tokens of their own.
+ * Unfinished input is still tokenised so completion can work mid-quote.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: lexer.go
+ * Description: POSIX-like tokenizer for REPL input and tab completion
+ */
+
+package input
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError reports malformed input such as an unbalanced quote
+type SyntaxError struct {
+	Pos int // byte offset of the offending character
+	Msg string
+}
+
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Pos+1)
+}
+
+// Token is a single shell word
+type Token struct {
+	Value string // text after quote removal and expansion
+	Start int    // byte offset where the word begins in the line
+	End   int    // byte offset just past the word
+	Open  rune   // quote still open at the end of input, 0 if none
+	Op    string // control operator such as "|" or "&&", empty for words
+}
+
+// Lexer splits input lines into shell-style words
+type Lexer struct {
+	// Lookup resolves $NAME references; unset names expand to nothing
+	Lookup func(name string) (string, bool)
+	// HomeDir resolves a leading ~
+	HomeDir func() (string, error)
+	// Operators makes unquoted |, &&, || and ; separate tokens instead of text
+	Operators bool
+}
+
+// NewLexer creates a lexer that expands variables from the environment
+func NewLexer() *Lexer {
+	return &Lexer{
+		Lookup:  os.LookupEnv,
+		HomeDir: os.UserHomeDir,
+	}
+}
+
+// Split splits a line into words using the default lexer
+func Split(line string) ([]string, error) {
+	return NewLexer().Split(line)
+}
+
+// Split splits a line into word values
+func (l *Lexer) Split(line string) ([]string, error) {
+	tokens, err := l.Lex(line)
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = token.Value
+	}
+	return words, nil
+}
+
+// Lex splits a line into tokens. On a syntax error the tokens read so far,
+// including the unfinished one, are returned together with the error.
+func (l *Lexer) Lex(line string) ([]Token, error) {
+	var tokens []Token
+	var word strings.Builder
+	inWord := false
+	quoted := false // quoted words survive even when empty
+	start := 0
+
+	emit := func(end int, open rune) {
+		if word.Len() > 0 || quoted || open != 0 {
+			tokens = append(tokens, Token{Value: word.String(), Start: start, End: end, Open: open})
+		}
+		word.Reset()
+		inWord = false
+		quoted = false
+	}
+
+	i := 0
+	for i < len(line) {
+		c := line[i]
+
+		// Quotes and escapes are consumed whole, so an operator here is unquoted
+		if op := l.operatorAt(line, i); op != "" {
+			if inWord {
+				emit(i, 0)
+			}
+			tokens = append(tokens, Token{Value: op, Start: i, End: i + len(op), Op: op})
+			i += len(op)
+			continue
+		}
+
+		if !inWord {
+			if isBlank(c) {
+				i++
+				continue
+			}
+			if c == '#' {
+				// Comment runs to the end of the line
+				break
+			}
+
+			inWord = true
+			start = i
+
+			if c == '~' && (i+1 == len(line) || line[i+1] == '/' || isBlank(line[i+1]) || l.operatorAt(line, i+1) != "") {
+				if home, err := l.HomeDir(); err == nil {
+					word.WriteString(home)
+					i++
+					continue
+				}
+			}
+		}
+
+		switch {
+		case isBlank(c):
+			emit(i, 0)
+			i++
+
+		case c == '\\':
+			if i+1 == len(line) {
+				emit(i, 0)
+				return tokens, &SyntaxError{Pos: i, Msg: "trailing backslash"}
+			}
+			_, size := utf8.DecodeRuneInString(line[i+1:])
+			word.WriteString(line[i+1 : i+1+size])
+			i += 1 + size
+
+		case c == '\'':
+			quoted = true
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				word.WriteString(line[i+1:])
+				emit(len(line), '\'')
+				return tokens, &SyntaxError{Pos: i, Msg: "unterminated single quote"}
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			i += end + 2
+
+		case c == '"':
+			quoted = true
+			next, err := l.lexDoubleQuoted(line, i, &word)
+			if err != nil {
+				emit(len(line), '"')
+				return tokens, err
+			}
+			i = next
+
+		case c == '$':
+			next, err := l.expandVariable(line, i, &word)
+			if err != nil {
+				emit(len(line), 0)
+				return tokens, err
+			}
+			i = next
+
+		default:
+			word.WriteByte(c)
+			i++
+		}
+	}
+
+	if inWord {
+		emit(len(line), 0)
+	}
+	return tokens, nil
+}
+
+// operatorAt returns the control operator starting at i, if operators are enabled
+func (l *Lexer) operatorAt(line string, i int) string {
+	if !l.Operators {
+		return ""
+	}
+	for _, op := range []string{"&&", "||", "|", ";"} {
+		if strings.HasPrefix(line[i:], op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// lexDoubleQuoted reads a double-quoted section starting at the opening quote
+func (l *Lexer) lexDoubleQuoted(line string, open int, word *strings.Builder) (int, error) {
+	i := open + 1
+	for i < len(line) {
+		switch c := line[i]; c {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			// Inside double quotes only a few characters can be escaped
+			if i+1 < len(line) && strings.IndexByte("\"\\$`", line[i+1]) >= 0 {
+				word.WriteByte(line[i+1])
+				i += 2
+				continue
+			}
+			word.WriteByte(c)
+			i++
+		case '$':
+			next, err := l.expandVariable(line, i, word)
+			if err != nil {
+				return 0, err
+			}
+			i = next
+		default:
+			word.WriteByte(c)
+			i++
+		}
+	}
+
+	return 0, &SyntaxError{Pos: open, Msg: "unterminated double quote"}
+}
+
+// expandVariable expands $NAME or ${NAME} starting at the dollar sign
+func (l *Lexer) expandVariable(line string, dollar int, word *strings.Builder) (int, error) {
+	i := dollar + 1
+
+	if i < len(line) && line[i] == '{' {
+		end := strings.IndexByte(line[i+1:], '}')
+		if end < 0 {
+			return 0, &SyntaxError{Pos: dollar, Msg: "unterminated ${"}
+		}
+		name := line[i+1 : i+1+end]
+		if name == "" || !isName(name) {
+			return 0, &SyntaxError{Pos: dollar, Msg: fmt.Sprintf("bad substitution ${%s}", name)}
+		}
+		word.WriteString(l.lookup(name))
+		return i + end + 2, nil
+	}
+
+	end := i
+	for end < len(line) && isNameChar(line[end]) {
+		end++
+	}
+	if end == i {
+		// A lone $ is just a dollar sign
+		word.WriteByte('$')
+		return i, nil
+	}
+
+	word.WriteString(l.lookup(line[i:end]))
+	return end, nil
+}
+
+// lookup resolves a variable name, treating unset names as empty
+func (l *Lexer) lookup(name string) string {
+	if l.Lookup == nil {
+		return ""
+	}
+	value, _ := l.Lookup(name)
+	return value
+}
+
+// Quote renders a word so that Split turns it back into the same word
+func Quote(word string) string {
+	if word == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(word, " \t\n'\"\\$#~;|&") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// isBlank reports whether c separates words
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isNameChar reports whether c may appear in a variable name
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isName reports whether s is a valid variable name
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
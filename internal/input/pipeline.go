@@ -0,0 +1,92 @@
+/**
+ * Command Lines
+ *
+ * Splits an interactive line into pipelines joined by &&, || and ;, each a
+ * run of commands joined by |. Commands are kept as source text so their
+ * words can be expanded just before they run, once earlier results exist.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: pipeline.go
+ * Description: Parsing of pipelines and command lists for the REPL
+ */
+
+package input
+
+import (
+	"fmt"
+)
+
+// Operator joins commands on a line
+type Operator string
+
+const (
+	OpPipe Operator = "|"  // feed the result of one command into the next
+	OpAnd  Operator = "&&" // run when the previous pipeline succeeded
+	OpOr   Operator = "||" // run when the previous pipeline failed
+	OpSeq  Operator = ";"  // always run
+)
+
+// Pipeline is a run of commands joined by |
+type Pipeline struct {
+	After  Operator // how it follows the previous pipeline; empty for the first
+	Stages []string // source text of each command
+}
+
+// ShouldRun reports whether the pipeline runs after one that ended with previousOK
+func (p Pipeline) ShouldRun(previousOK bool) bool {
+	switch p.After {
+	case OpAnd:
+		return previousOK
+	case OpOr:
+		return !previousOK
+	default:
+		return true
+	}
+}
+
+// ParseLine splits a line into pipelines. The lexer must have Operators set.
+func (l *Lexer) ParseLine(line string) ([]Pipeline, error) {
+	tokens, err := l.Lex(line)
+	if err != nil {
+		return nil, err
+	}
+
+	var pipelines []Pipeline
+	current := Pipeline{}
+	start, end := -1, -1 // source span of the command being read
+
+	for _, token := range tokens {
+		if token.Op == "" {
+			if start < 0 {
+				start = token.Start
+			}
+			end = token.End
+			continue
+		}
+
+		if start < 0 {
+			return nil, &SyntaxError{Pos: token.Start, Msg: fmt.Sprintf("missing command before %q", token.Op)}
+		}
+		current.Stages = append(current.Stages, line[start:end])
+		start, end = -1, -1
+
+		if Operator(token.Op) != OpPipe {
+			pipelines = append(pipelines, current)
+			current = Pipeline{After: Operator(token.Op)}
+		}
+	}
+
+	if start >= 0 {
+		current.Stages = append(current.Stages, line[start:end])
+	} else if len(tokens) > 0 && (len(current.Stages) > 0 || current.After != OpSeq) {
+		// Only a trailing ; may end a line, as in a shell
+		last := tokens[len(tokens)-1]
+		return nil, &SyntaxError{Pos: last.Start, Msg: fmt.Sprintf("missing command after %q", last.Op)}
+	}
+
+	if len(current.Stages) > 0 {
+		pipelines = append(pipelines, current)
+	}
+	return pipelines, nil
+}
@@ -0,0 +1,61 @@
+/**
+ * Standard Input
+ *
+ * One buffered reader for standard input, shared by interactive mode and
+ * every confirmation prompt. A reader of its own would buffer ahead and
+ * swallow the lines meant for the commands after the prompt when input is
+ * piped, as it is for scripts and tests.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: stdin.go
+ * Description: Shared line reader for standard input
+ */
+
+package input
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"ena/internal/fault"
+)
+
+// ErrNoAnswer is returned when standard input ends before a prompt is answered;
+// it is a usage error, since the command needed --yes or an answer
+var ErrNoAnswer = fault.Invalid("no answer: standard input ended before the prompt was answered")
+
+var (
+	stdin      = bufio.NewReader(os.Stdin)
+	stdinMutex sync.Mutex
+)
+
+// ReadLine reads the next line of standard input without its line ending.
+// A last line without a newline is returned before io.EOF.
+func ReadLine() (string, error) {
+	stdinMutex.Lock()
+	defer stdinMutex.Unlock()
+
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// Answer reads the reply to a prompt, trimmed and in lower case. At the end of
+// input it returns ErrNoAnswer, so a script that forgot --yes fails rather
+// than being taken to have said no.
+func Answer() (string, error) {
+	line, err := ReadLine()
+	if err == io.EOF {
+		return "", ErrNoAnswer
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(line)), nil
+}
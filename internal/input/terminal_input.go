@@ -15,47 +15,42 @@ package input
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/chzyer/readline"
 	"github.com/fatih/color"
+
+	"ena/internal/paths"
 )
 
 // TerminalInput handles advanced terminal input with completion and history
 type TerminalInput struct {
 	rl       *readline.Instance
+	lexer    *Lexer
 	history  []string
 	commands []string
+
+	// Arguments, when set, lists the values a command takes after words,
+	// each optionally followed by a tab and a description
+	Arguments func(words []string, prefix string) []string
 }
 
-// NewTerminalInput creates a new terminal input manager
-func NewTerminalInput() (*TerminalInput, error) {
+// NewTerminalInput creates a new terminal input manager completing the given commands
+func NewTerminalInput(commands []string) (*TerminalInput, error) {
 	// Initialize terminal input with completion support
+	lexer := NewLexer()
+	lexer.Operators = true
 	ti := &TerminalInput{
-		history: make([]string, 0),
-		commands: []string{
-			// File operations
-			"file", "file create", "file read", "file write", "file copy", "file move", "file delete", "file info",
-			// Folder operations
-			"folder", "folder create", "folder list", "folder delete", "folder info",
-			// Terminal operations
-			"terminal", "terminal open", "terminal close", "terminal execute", "terminal cd",
-			// Application operations
-			"app", "app start", "app stop", "app restart", "app list", "app info",
-			// System operations
-			"system", "system restart", "system shutdown", "system sleep", "system info",
-			// Health and search
-			"health", "search", "delete",
-			// Built-in commands
-			"help", "status", "exit",
-		},
+		lexer:    lexer,
+		history:  make([]string, 0),
+		commands: commands,
 	}
 
 	// Configure readline instance
 	config := &readline.Config{
 		Prompt:            color.New(color.FgYellow, color.Bold).Sprint("Ena> "),
-		HistoryFile:       "/tmp/ena_history",
+		HistoryFile:       paths.StateFile("history"),
 		AutoComplete:      ti,
 		InterruptPrompt:   "^C",
 		EOFPrompt:         "exit",
@@ -71,7 +66,7 @@ func NewTerminalInput() (*TerminalInput, error) {
 
 	rl, err := readline.NewEx(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize readline: %v", err)
+		return nil, fmt.Errorf("failed to initialize readline: %w", err)
 	}
 
 	ti.rl = rl
@@ -92,136 +87,128 @@ func (ti *TerminalInput) Close() error {
 	return ti.rl.Close()
 }
 
-// Do implements the readline.AutoCompleter interface
+// Do implements the readline.AutoCompleter interface. Readline expects the
+// text to insert after the word under the cursor, plus that word's length.
 func (ti *TerminalInput) Do(line []rune, pos int) (newLine [][]rune, length int) {
-	// Get the current word being typed
-	lineStr := string(line[:pos])
-	words := strings.Fields(lineStr)
+	text := string(line[:pos])
 
-	if len(words) == 0 {
-		// No words yet, suggest all commands
-		return ti.suggestCommands(""), 0
+	// Unbalanced quotes are expected mid-word, so keep whatever was lexed
+	tokens, _ := ti.lexer.Lex(text)
+
+	// Only the command after the last |, &&, || or ; matters
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Op != "" {
+			tokens = tokens[i+1:]
+			break
+		}
 	}
 
-	currentWord := words[len(words)-1]
-	prefix := strings.ToLower(currentWord)
+	// The cursor is either inside the last word or after a blank
+	words := tokens
+	current := Token{Start: len(text), End: len(text)}
+	if len(tokens) > 0 && tokens[len(tokens)-1].End == len(text) {
+		current = tokens[len(tokens)-1]
+		words = tokens[:len(tokens)-1]
+	}
 
-	// If we're at the start of a new word or command
-	if len(words) == 1 && pos == len(line) {
-		// Suggest commands
-		return ti.suggestCommands(prefix), len(currentWord)
+	raw := text[current.Start:]
+	if current.Open == 0 && (strings.HasSuffix(raw, "\"") || strings.HasSuffix(raw, "'")) {
+		// Nothing sensible to append after a closing quote
+		return nil, 0
 	}
+	length = utf8.RuneCountInString(raw)
 
-	// Check if current word looks like a file path
-	if strings.HasPrefix(currentWord, "/") || strings.HasPrefix(currentWord, "./") || strings.HasPrefix(currentWord, "~") {
-		// Suggest file paths
-		return ti.suggestFilePaths(currentWord), len(currentWord)
+	// Command words first: "file" completes to "file create" and so on
+	var typed []string
+	for _, word := range words {
+		typed = append(typed, word.Value)
+	}
+	if len(typed) < 2 {
+		if suggestions := ti.suggestCommands(typed, current.Value); len(suggestions) > 0 || len(typed) == 0 {
+			return suggestions, length
+		}
 	}
 
-	// Check if we're in a file operation context
-	if len(words) >= 2 {
-		operation := words[0]
-		if operation == "file" || operation == "folder" || operation == "search" || operation == "delete" {
-			// Suggest file paths for file operations
-			return ti.suggestFilePaths(currentWord), len(currentWord)
+	// Then values the command completes itself, such as backup or job IDs
+	if ti.Arguments != nil && len(typed) > 0 {
+		if suggestions := ti.suggestArguments(typed, current.Value, current.Open); len(suggestions) > 0 {
+			return suggestions, length
 		}
 	}
 
-	// Default: suggest commands
-	return ti.suggestCommands(prefix), len(currentWord)
+	// Then paths, either explicit or as arguments to file operations
+	value := current.Value
+	isPath := strings.HasPrefix(value, "/") || strings.HasPrefix(value, ".") || strings.HasPrefix(raw, "~")
+	if isPath || (len(typed) > 0 && isPathCommand(typed[0])) {
+		return ti.suggestFilePaths(value, current.Open), length
+	}
+
+	return nil, length
+}
+
+// isPathCommand reports whether a command takes file paths as arguments
+func isPathCommand(command string) bool {
+	switch command {
+	case "file", "folder", "search", "delete", "browse", "batch-delete", "batch-copy", "batch-move", "backup", "organize":
+		return true
+	}
+	return false
 }
 
-// suggestCommands suggests commands based on prefix and fuzzy matching
-func (ti *TerminalInput) suggestCommands(prefix string) [][]rune {
+// suggestCommands completes the next command word after the typed ones
+func (ti *TerminalInput) suggestCommands(typed []string, prefix string) [][]rune {
 	var suggestions [][]rune
-	var fuzzySuggestions [][]rune
+	seen := make(map[string]bool)
 
-	// First pass: exact prefix matches
 	for _, cmd := range ti.commands {
-		if prefix == "" || strings.HasPrefix(strings.ToLower(cmd), strings.ToLower(prefix)) {
-			suggestions = append(suggestions, []rune(cmd))
+		parts := strings.Fields(cmd)
+		if len(parts) <= len(typed) {
+			continue
 		}
-	}
-
-	// Second pass: fuzzy matches if we have few exact matches
-	if len(suggestions) < 5 && len(prefix) > 0 {
-		for _, cmd := range ti.commands {
-			cmdLower := strings.ToLower(cmd)
-			prefixLower := strings.ToLower(prefix)
-
-			// Skip if already in exact matches
-			alreadyExact := false
-			for _, exact := range suggestions {
-				if string(exact) == cmd {
-					alreadyExact = true
-					break
-				}
-			}
 
-			if alreadyExact {
-				continue
-			}
-
-			// Check fuzzy match (contains all characters in order)
-			if ti.fuzzyMatch(prefixLower, cmdLower) {
-				fuzzySuggestions = append(fuzzySuggestions, []rune(cmd))
+		// Earlier words must match exactly
+		matches := true
+		for i, word := range typed {
+			if parts[i] != word {
+				matches = false
+				break
 			}
 		}
-	}
 
-	// Combine exact matches first, then fuzzy matches
-	allSuggestions := append(suggestions, fuzzySuggestions...)
+		candidate := parts[len(typed)]
+		if !matches || seen[candidate] || !strings.HasPrefix(candidate, prefix) {
+			continue
+		}
+		seen[candidate] = true
 
-	// Limit suggestions to avoid overwhelming
-	if len(allSuggestions) > 15 {
-		allSuggestions = allSuggestions[:15]
+		suggestions = append(suggestions, []rune(candidate[len(prefix):]+" "))
 	}
 
-	return allSuggestions
+	return suggestions
 }
 
-// fuzzyMatch checks if query characters appear in order in the text
-func (ti *TerminalInput) fuzzyMatch(query, text string) bool {
-	if len(query) == 0 {
-		return true
-	}
-	if len(query) > len(text) {
-		return false
-	}
-
-	queryIndex := 0
-	for _, char := range text {
-		if queryIndex < len(query) && char == rune(query[queryIndex]) {
-			queryIndex++
+// suggestArguments completes a command's argument from the values it lists
+func (ti *TerminalInput) suggestArguments(typed []string, prefix string, quote rune) [][]rune {
+	var suggestions [][]rune
+	for _, candidate := range ti.Arguments(typed, prefix) {
+		value, _, _ := strings.Cut(candidate, "\t")
+		if strings.HasPrefix(value, prefix) {
+			suggestions = append(suggestions, []rune(escapeForQuote(value[len(prefix):], quote)+" "))
 		}
 	}
-
-	return queryIndex == len(query)
+	return suggestions
 }
 
-// suggestFilePaths suggests file paths based on current input
-func (ti *TerminalInput) suggestFilePaths(prefix string) [][]rune {
+// suggestFilePaths completes a path, escaping the result for the open quote
+func (ti *TerminalInput) suggestFilePaths(prefix string, quote rune) [][]rune {
 	var suggestions [][]rune
 
-	// Expand tilde to home directory
-	expandedPrefix := prefix
-	if strings.HasPrefix(prefix, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			expandedPrefix = strings.Replace(prefix, "~", homeDir, 1)
-		}
-	}
-
-	// Get directory and pattern
-	dir := filepath.Dir(expandedPrefix)
-	pattern := filepath.Base(expandedPrefix)
-
-	// If directory doesn't exist, return empty
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return suggestions
+	// Split into the directory to list and the name being typed
+	dir, pattern := ".", prefix
+	if slash := strings.LastIndex(prefix, "/"); slash >= 0 {
+		dir, pattern = prefix[:slash+1], prefix[slash+1:]
 	}
 
-	// Read directory contents
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return suggestions
@@ -235,25 +222,18 @@ func (ti *TerminalInput) suggestFilePaths(prefix string) [][]rune {
 			continue
 		}
 
-		// Check if name matches pattern
-		if pattern == "" || strings.HasPrefix(name, pattern) {
-			fullPath := filepath.Join(dir, name)
-
-			// Add trailing slash for directories
-			if entry.IsDir() {
-				fullPath += "/"
-			}
+		if !strings.HasPrefix(name, pattern) {
+			continue
+		}
 
-			// Convert back to original prefix format if needed
-			if strings.HasPrefix(prefix, "~") {
-				homeDir, err := os.UserHomeDir()
-				if err == nil {
-					fullPath = strings.Replace(fullPath, homeDir, "~", 1)
-				}
-			}
+		suffix := escapeForQuote(name[len(pattern):], quote)
 
-			suggestions = append(suggestions, []rune(fullPath))
+		// Add trailing slash for directories
+		if entry.IsDir() {
+			suffix += "/"
 		}
+
+		suggestions = append(suggestions, []rune(suffix))
 	}
 
 	// Limit suggestions
@@ -264,6 +244,29 @@ func (ti *TerminalInput) suggestFilePaths(prefix string) [][]rune {
 	return suggestions
 }
 
+// escapeForQuote escapes text so the lexer reads it back unchanged
+func escapeForQuote(text string, quote rune) string {
+	var special string
+	switch quote {
+	case '\'':
+		// Nothing can be escaped inside single quotes
+		return text
+	case '"':
+		special = "\"\\$`"
+	default:
+		special = " \t'\"\\$#&;|()<>*?"
+	}
+
+	var escaped strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(special, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
 // AddToHistory adds a command to history
 func (ti *TerminalInput) AddToHistory(command string) {
 	if command != "" && (len(ti.history) == 0 || ti.history[len(ti.history)-1] != command) {
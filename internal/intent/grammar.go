@@ -0,0 +1,224 @@
+/**
+ * Intent Grammar
+ *
+ * The vocabulary the natural-language parser understands: which words name
+ * an action, which describe a kind of file and which name a place. The
+ * built-in vocabulary can be extended from the intent section of config.yaml.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: grammar.go
+ * Description: Extensible vocabulary for the natural-language intent parser
+ */
+
+package intent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Action is what a request does with the files it describes
+type Action string
+
+const (
+	ActionMove   Action = "move"
+	ActionCopy   Action = "copy"
+	ActionDelete Action = "delete"
+	ActionList   Action = "list"
+)
+
+// Actions lists every action in display order
+var Actions = []Action{ActionMove, ActionCopy, ActionDelete, ActionList}
+
+// Grammar holds the words the parser maps onto actions, file kinds and places
+type Grammar struct {
+	Verbs  map[Action][]string `json:"verbs" yaml:"verbs"`   // action -> words that ask for it
+	Kinds  map[string][]string `json:"kinds" yaml:"kinds"`   // word -> file extensions
+	Places map[string]string   `json:"places" yaml:"places"` // word -> directory
+}
+
+// DefaultGrammar returns the built-in vocabulary
+func DefaultGrammar() Grammar {
+	return Grammar{
+		Verbs: map[Action][]string{
+			ActionMove:   {"move", "mv", "shift", "relocate", "put"},
+			ActionCopy:   {"copy", "cp", "duplicate"},
+			ActionDelete: {"delete", "remove", "rm", "erase", "trash", "clean"},
+			ActionList:   {"list", "find", "show", "search", "locate", "ls"},
+		},
+		Kinds: map[string][]string{
+			"pdf":          {".pdf"},
+			"pdfs":         {".pdf"},
+			"image":        {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".svg", ".heic"},
+			"images":       {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".svg", ".heic"},
+			"photo":        {".jpg", ".jpeg", ".png", ".heic"},
+			"photos":       {".jpg", ".jpeg", ".png", ".heic"},
+			"picture":      {".jpg", ".jpeg", ".png", ".gif", ".heic"},
+			"pictures":     {".jpg", ".jpeg", ".png", ".gif", ".heic"},
+			"screenshot":   {".png"},
+			"screenshots":  {".png"},
+			"video":        {".mp4", ".mkv", ".mov", ".avi", ".webm"},
+			"videos":       {".mp4", ".mkv", ".mov", ".avi", ".webm"},
+			"movie":        {".mp4", ".mkv", ".mov", ".avi"},
+			"movies":       {".mp4", ".mkv", ".mov", ".avi"},
+			"song":         {".mp3", ".flac", ".ogg", ".wav", ".m4a"},
+			"songs":        {".mp3", ".flac", ".ogg", ".wav", ".m4a"},
+			"audio":        {".mp3", ".flac", ".ogg", ".wav", ".m4a"},
+			"doc":          {".doc", ".docx", ".odt", ".rtf", ".pdf", ".txt", ".md"},
+			"docs":         {".doc", ".docx", ".odt", ".rtf", ".pdf", ".txt", ".md"},
+			"spreadsheet":  {".xls", ".xlsx", ".ods", ".csv"},
+			"spreadsheets": {".xls", ".xlsx", ".ods", ".csv"},
+			"archive":      {".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".7z", ".rar"},
+			"archives":     {".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".7z", ".rar"},
+			"zip":          {".zip"},
+			"zips":         {".zip"},
+			"log":          {".log"},
+			"logs":         {".log"},
+			"text":         {".txt", ".md"},
+			"note":         {".txt", ".md"},
+			"notes":        {".txt", ".md"},
+			"installer":    {".deb", ".rpm", ".dmg", ".pkg", ".exe", ".msi", ".appimage"},
+			"installers":   {".deb", ".rpm", ".dmg", ".pkg", ".exe", ".msi", ".appimage"},
+			"iso":          {".iso"},
+			"isos":         {".iso"},
+		},
+		Places: map[string]string{
+			"home":      "~",
+			"desktop":   "~/Desktop",
+			"documents": "~/Documents",
+			"downloads": "~/Downloads",
+			"music":     "~/Music",
+			"pictures":  "~/Pictures",
+			"videos":    "~/Videos",
+			"here":      ".",
+			"tmp":       "/tmp",
+		},
+	}
+}
+
+// Merge returns a grammar with extra's words added; kinds and places in extra
+// replace built-in ones of the same name
+func (g Grammar) Merge(extra Grammar) Grammar {
+	merged := Grammar{
+		Verbs:  make(map[Action][]string),
+		Kinds:  make(map[string][]string),
+		Places: make(map[string]string),
+	}
+
+	for action, words := range g.Verbs {
+		merged.Verbs[action] = append([]string{}, words...)
+	}
+	for action, words := range extra.Verbs {
+		merged.Verbs[action] = append(merged.Verbs[action], words...)
+	}
+
+	for _, source := range []map[string][]string{g.Kinds, extra.Kinds} {
+		for word, extensions := range source {
+			merged.Kinds[strings.ToLower(word)] = normalizeExtensions(extensions)
+		}
+	}
+	for _, source := range []map[string]string{g.Places, extra.Places} {
+		for word, dir := range source {
+			merged.Places[strings.ToLower(word)] = dir
+		}
+	}
+
+	return merged
+}
+
+// Validate rejects vocabulary the parser cannot use
+func (g Grammar) Validate() error {
+	for action := range g.Verbs {
+		if !knownAction(action) {
+			return fmt.Errorf("unknown action %q (expected one of %s)", action, actionNames())
+		}
+	}
+	for word, extensions := range g.Kinds {
+		if len(extensions) == 0 {
+			return fmt.Errorf("kind %q has no extensions", word)
+		}
+	}
+	for word, dir := range g.Places {
+		if dir == "" {
+			return fmt.Errorf("place %q has no directory", word)
+		}
+	}
+	return nil
+}
+
+// verb returns the action a word asks for
+func (g Grammar) verb(word string) (Action, bool) {
+	word = strings.ToLower(word)
+	for _, action := range Actions {
+		for _, synonym := range g.Verbs[action] {
+			if strings.ToLower(synonym) == word {
+				return action, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IsVerb reports whether a word asks for an action
+func (g Grammar) IsVerb(word string) bool {
+	_, ok := g.verb(word)
+	return ok
+}
+
+// kind returns the extensions a word describes
+func (g Grammar) kind(word string) ([]string, bool) {
+	extensions, ok := g.Kinds[strings.ToLower(word)]
+	return extensions, ok
+}
+
+// place resolves a location, expanding a known place name in its first segment
+func (g Grammar) place(location string) string {
+	first, rest, _ := strings.Cut(location, "/")
+	if dir, ok := g.Places[strings.ToLower(first)]; ok {
+		location = filepath.Join(dir, rest)
+	}
+
+	if location == "~" || strings.HasPrefix(location, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			location = filepath.Join(home, strings.TrimPrefix(location, "~"))
+		}
+	}
+	return filepath.Clean(location)
+}
+
+// knownAction reports whether an action can be compiled
+func knownAction(action Action) bool {
+	for _, known := range Actions {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
+
+// actionNames lists the actions for error messages
+func actionNames() string {
+	names := make([]string, len(Actions))
+	for i, action := range Actions {
+		names[i] = string(action)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// normalizeExtensions lowercases extensions and adds a missing leading dot
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
@@ -0,0 +1,625 @@
+/**
+ * Intent Parser
+ *
+ * Turns requests such as "move pdfs older than a month from Downloads to
+ * Documents/Archive" into a Plan, then compiles the plan into a pattern
+ * operation (for files described by kind, age, size or content) or a batch
+ * job (for files named one by one). Parsing is offline and rule based: a
+ * verb, then any mix of descriptions, sources and a destination.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: parser.go
+ * Description: Natural-language request parsing and compilation into operations
+ */
+
+package intent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ena/internal/batch"
+	"ena/internal/patterns"
+)
+
+// Plan is a parsed request
+type Plan struct {
+	Request      string        `json:"request"`
+	Action       Action        `json:"action"`
+	Kinds        []string      `json:"kinds,omitempty"`
+	Extensions   []string      `json:"extensions,omitempty"`
+	NameContains string        `json:"name_contains,omitempty"`
+	Containing   string        `json:"containing,omitempty"`
+	OlderThan    time.Duration `json:"older_than,omitempty"`
+	NewerThan    time.Duration `json:"newer_than,omitempty"`
+	LargerThan   int64         `json:"larger_than,omitempty"`
+	SmallerThan  int64         `json:"smaller_than,omitempty"`
+	Files        []string      `json:"files,omitempty"` // files named one by one
+	Sources      []string      `json:"sources"`
+	Destination  string        `json:"destination,omitempty"`
+	Recursive    bool          `json:"recursive"`
+}
+
+// fillerWords carry no meaning for the parser
+var fillerWords = map[string]bool{
+	"please": true, "ena": true, "can": true, "could": true, "would": true, "you": true,
+	"kindly": true, "all": true, "the": true, "my": true, "every": true, "any": true,
+	"of": true, "that": true, "which": true, "are": true, "is": true, "were": true,
+	"and": true, ",": true, "files": true, "file": true, "everything": true,
+	"stuff": true, "items": true, "some": true, "those": true, "these": true, "them": true,
+}
+
+// numberWords are the spelled-out quantities understood in ages and sizes
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "twelve": 12,
+}
+
+// ageUnits maps age unit words to durations
+var ageUnits = map[string]time.Duration{
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"hour": time.Hour, "hours": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour, "months": 30 * 24 * time.Hour,
+	"year": 365 * 24 * time.Hour, "years": 365 * 24 * time.Hour,
+}
+
+// compactAge matches ages like 30d, 2w or 6mo
+var compactAge = regexp.MustCompile(`^(\d+)(m|h|d|w|mo|y)$`)
+
+// compactAgeUnits are the suffixes compactAge accepts
+var compactAgeUnits = map[string]string{"m": "minute", "h": "hour", "d": "day", "w": "week", "mo": "month", "y": "year"}
+
+// sizeUnits maps size unit words to bytes
+var sizeUnits = map[string]float64{
+	"b": 1, "byte": 1, "bytes": 1,
+	"kb": 1 << 10, "k": 1 << 10, "kilobytes": 1 << 10,
+	"mb": 1 << 20, "m": 1 << 20, "megabytes": 1 << 20, "megs": 1 << 20,
+	"gb": 1 << 30, "g": 1 << 30, "gigabytes": 1 << 30, "gigs": 1 << 30,
+	"tb": 1 << 40, "terabytes": 1 << 40,
+}
+
+// compactSize matches sizes like 100MB or 1.5gb
+var compactSize = regexp.MustCompile(`^(\d+(?:\.\d+)?)([a-z]+)$`)
+
+// extensionWord matches words that can be a file extension, as in "txt files"
+var extensionWord = regexp.MustCompile(`^[a-z0-9]{1,6}$`)
+
+// parser walks the words of one request
+type parser struct {
+	grammar Grammar
+	words   []string
+	pos     int
+	plan    *Plan
+}
+
+// Parse understands a request given as words, such as command-line arguments.
+// A quoted argument stays one word, so paths may contain spaces.
+func (g Grammar) Parse(words []string) (*Plan, error) {
+	var split []string
+	for _, word := range words {
+		if strings.ContainsAny(word, " \t") && !pathLike(word) {
+			split = append(split, strings.Fields(word)...)
+		} else {
+			split = append(split, word)
+		}
+	}
+
+	p := &parser{grammar: g, words: split, plan: &Plan{Request: strings.Join(words, " ")}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	if err := p.plan.check(); err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+// parse reads the verb and then every clause
+func (p *parser) parse() error {
+	for p.more() && fillerWords[p.peek()] {
+		p.pos++
+	}
+	if !p.more() {
+		return fmt.Errorf("tell me what to do, e.g. \"move pdfs from Downloads to Documents\"")
+	}
+
+	verb := p.next()
+	action, ok := p.grammar.verb(verb)
+	if !ok {
+		return fmt.Errorf("I don't know how to %q (try %s)", verb, actionNames())
+	}
+	p.plan.Action = action
+
+	for p.more() {
+		if err := p.clause(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clause reads one description, location or option
+func (p *parser) clause() error {
+	raw := p.words[p.pos]
+	word := p.next()
+
+	switch {
+	case word == "older" || word == "newer":
+		p.accept("than")
+		age, err := p.age()
+		if err != nil {
+			return err
+		}
+		if word == "older" {
+			p.plan.OlderThan = age
+		} else {
+			p.plan.NewerThan = age
+		}
+
+	case word == "today":
+		now := time.Now()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		p.plan.NewerThan = now.Sub(midnight).Round(time.Minute)
+
+	case word == "larger" || word == "bigger" || word == "over" || word == "above":
+		p.accept("than")
+		size, err := p.size()
+		if err != nil {
+			return err
+		}
+		p.plan.LargerThan = size
+
+	case word == "smaller" || word == "below" || word == "under":
+		p.accept("than")
+		size, err := p.size()
+		if err != nil {
+			return err
+		}
+		p.plan.SmallerThan = size
+
+	case word == "containing" || word == "contain" || word == "contains" || word == "mentioning":
+		text, err := p.operand(word)
+		if err != nil {
+			return err
+		}
+		p.plan.Containing = text
+
+	case word == "named" || word == "called" || word == "matching":
+		text, err := p.operand(word)
+		if err != nil {
+			return err
+		}
+		p.plan.NameContains = text
+
+	case word == "from" || word == "in" || word == "inside" || word == "within":
+		location, err := p.operand(word)
+		if err != nil {
+			return err
+		}
+		p.plan.Sources = append(p.plan.Sources, p.grammar.place(location))
+
+		// "from Downloads and Desktop"
+		for p.more() && (p.peek() == "and" || p.peek() == ",") && p.pos+1 < len(p.words) && p.isLocation(p.words[p.pos+1]) {
+			p.pos++
+			p.plan.Sources = append(p.plan.Sources, p.grammar.place(p.words[p.pos]))
+			p.pos++
+		}
+
+	case word == "to" || word == "into" || word == "onto":
+		location, err := p.operand(word)
+		if err != nil {
+			return err
+		}
+		if p.plan.Destination != "" {
+			return fmt.Errorf("I can only send files to one place, got %s and %s", p.plan.Destination, location)
+		}
+		p.plan.Destination = p.grammar.place(location)
+
+	case word == "recursively" || word == "everywhere":
+		p.plan.Recursive = true
+
+	case word == "including" && p.more() && (p.peek() == "subfolders" || p.peek() == "subdirectories"):
+		p.pos++
+		p.plan.Recursive = true
+
+	case fillerWords[word]:
+		// Nothing to do
+
+	default:
+		if extensions, ok := p.grammar.kind(word); ok {
+			p.plan.Kinds = append(p.plan.Kinds, word)
+			p.plan.Extensions = append(p.plan.Extensions, extensions...)
+			return nil
+		}
+		if strings.HasPrefix(word, "*.") || (strings.HasPrefix(word, ".") && extensionWord.MatchString(word[1:])) {
+			p.plan.Extensions = append(p.plan.Extensions, strings.TrimPrefix(word, "*"))
+			return nil
+		}
+		if extensionWord.MatchString(word) && (p.peek() == "files" || p.peek() == "file") {
+			p.plan.Extensions = append(p.plan.Extensions, "."+word)
+			return nil
+		}
+		if pathLike(raw) {
+			p.plan.Files = append(p.plan.Files, raw)
+			return nil
+		}
+		return fmt.Errorf("I don't understand %q", raw)
+	}
+
+	return nil
+}
+
+// age reads a quantity and unit such as "a month", "3 weeks" or "30d"
+func (p *parser) age() (time.Duration, error) {
+	if !p.more() {
+		return 0, fmt.Errorf("how old? e.g. \"older than 2 weeks\"")
+	}
+
+	word := p.next()
+	if match := compactAge.FindStringSubmatch(word); match != nil {
+		count, _ := strconv.Atoi(match[1])
+		return time.Duration(count) * ageUnits[compactAgeUnits[match[2]]], nil
+	}
+
+	count, ok := numberWords[word]
+	if !ok {
+		parsed, err := strconv.Atoi(word)
+		if err != nil {
+			return 0, fmt.Errorf("I don't understand the age %q", word)
+		}
+		count = parsed
+	}
+
+	if !p.more() {
+		return 0, fmt.Errorf("%s what? e.g. \"%s days\"", word, word)
+	}
+	unit := p.next()
+	duration, ok := ageUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("I don't understand the time unit %q", unit)
+	}
+	return time.Duration(count) * duration, nil
+}
+
+// size reads a size such as "100MB", "1.5 gb" or "2 gigabytes"
+func (p *parser) size() (int64, error) {
+	if !p.more() {
+		return 0, fmt.Errorf("how big? e.g. \"larger than 100MB\"")
+	}
+
+	word := p.next()
+	number, unit := word, ""
+	if match := compactSize.FindStringSubmatch(word); match != nil {
+		number, unit = match[1], match[2]
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		count, ok := numberWords[word]
+		if !ok {
+			return 0, fmt.Errorf("I don't understand the size %q", word)
+		}
+		value = float64(count)
+	}
+
+	if unit == "" {
+		if !p.more() {
+			return 0, fmt.Errorf("%s what? e.g. \"%s MB\"", word, word)
+		}
+		unit = p.next()
+	}
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("I don't understand the size unit %q", unit)
+	}
+	return int64(value * multiplier), nil
+}
+
+// operand returns the word following a keyword, keeping its case
+func (p *parser) operand(keyword string) (string, error) {
+	if !p.more() {
+		return "", fmt.Errorf("%q needs something after it", keyword)
+	}
+	operand := p.words[p.pos]
+	p.pos++
+	return strings.Trim(operand, `"'`), nil
+}
+
+// isLocation reports whether a word names a known place or an existing directory
+func (p *parser) isLocation(word string) bool {
+	first, _, _ := strings.Cut(word, "/")
+	if _, ok := p.grammar.Places[strings.ToLower(first)]; ok {
+		return true
+	}
+	info, err := os.Stat(p.grammar.place(word))
+	return err == nil && info.IsDir()
+}
+
+// more reports whether words remain
+func (p *parser) more() bool {
+	return p.pos < len(p.words)
+}
+
+// peek returns the next word in lower case without consuming it
+func (p *parser) peek() string {
+	if !p.more() {
+		return ""
+	}
+	return strings.ToLower(p.words[p.pos])
+}
+
+// next consumes and returns the next word in lower case
+func (p *parser) next() string {
+	word := p.peek()
+	p.pos++
+	return word
+}
+
+// accept consumes the next word if it is the given one
+func (p *parser) accept(word string) {
+	if p.peek() == word {
+		p.pos++
+	}
+}
+
+// pathLike reports whether a word looks like a file name rather than English
+func pathLike(word string) bool {
+	if strings.ContainsAny(word, "/\\") {
+		return true
+	}
+	if ext := filepath.Ext(word); ext != "" && ext != word && extensionWord.MatchString(strings.ToLower(ext[1:])) {
+		return true
+	}
+	_, err := os.Stat(word)
+	return err == nil
+}
+
+// check rejects plans that are incomplete or too broad to run safely
+func (p *Plan) check() error {
+	described := p.described()
+
+	switch p.Action {
+	case ActionMove, ActionCopy:
+		if p.Destination == "" {
+			return fmt.Errorf("where should I %s them? Add \"to <folder>\"", p.Action)
+		}
+	case ActionDelete, ActionList:
+		if p.Destination != "" {
+			return fmt.Errorf("%s does not take a destination", p.Action)
+		}
+	}
+
+	if len(p.Files) > 0 {
+		if described {
+			return fmt.Errorf("either name the files or describe them, not both")
+		}
+		if p.Action == ActionList {
+			return fmt.Errorf("nothing to find - you named the files already")
+		}
+	}
+
+	if p.Action == ActionDelete && !described && len(p.Files) == 0 {
+		return fmt.Errorf("I won't delete everything - say which files, e.g. \"delete logs older than a week from tmp\"")
+	}
+
+	if len(p.Sources) == 0 {
+		p.Sources = []string{"."}
+	}
+	for i, source := range p.Sources {
+		if abs, err := filepath.Abs(source); err == nil {
+			p.Sources[i] = abs
+		}
+	}
+	if p.Destination != "" {
+		if abs, err := filepath.Abs(p.Destination); err == nil {
+			p.Destination = abs
+		}
+	}
+	return nil
+}
+
+// described reports whether the plan selects files by their properties
+func (p *Plan) described() bool {
+	return len(p.Extensions) > 0 || p.NameContains != "" || p.Containing != "" ||
+		p.OlderThan > 0 || p.NewerThan > 0 || p.LargerThan > 0 || p.SmallerThan > 0
+}
+
+// IsBatch reports whether the plan names its files and so compiles to a batch job
+func (p *Plan) IsBatch() bool {
+	return len(p.Files) > 0
+}
+
+// Summary restates the plan in plain words
+func (p *Plan) Summary() string {
+	var b strings.Builder
+	b.WriteString(string(p.Action))
+
+	if p.IsBatch() {
+		fmt.Fprintf(&b, " %s", strings.Join(p.Files, ", "))
+	} else {
+		if len(p.Extensions) > 0 {
+			fmt.Fprintf(&b, " %s files", strings.Join(unique(p.Extensions), " "))
+		} else {
+			b.WriteString(" all files")
+		}
+		if p.NameContains != "" {
+			fmt.Fprintf(&b, " named like %q", p.NameContains)
+		}
+		if p.Containing != "" {
+			fmt.Fprintf(&b, " containing %q", p.Containing)
+		}
+		if p.OlderThan > 0 {
+			fmt.Fprintf(&b, " older than %s", formatAge(p.OlderThan))
+		}
+		if p.NewerThan > 0 {
+			fmt.Fprintf(&b, " newer than %s", formatAge(p.NewerThan))
+		}
+		if p.LargerThan > 0 {
+			fmt.Fprintf(&b, " larger than %s", formatSize(p.LargerThan))
+		}
+		if p.SmallerThan > 0 {
+			fmt.Fprintf(&b, " smaller than %s", formatSize(p.SmallerThan))
+		}
+		fmt.Fprintf(&b, " in %s", strings.Join(p.Sources, ", "))
+		if p.Recursive {
+			b.WriteString(" and its subfolders")
+		}
+	}
+
+	if p.Destination != "" {
+		fmt.Fprintf(&b, " to %s", p.Destination)
+	}
+	return b.String()
+}
+
+// Operation compiles a plan that describes its files into a pattern operation
+func (p *Plan) Operation() *patterns.PatternOperation {
+	now := time.Now()
+	operation := &patterns.PatternOperation{
+		ID:          fmt.Sprintf("intent_%d", now.UnixNano()),
+		Name:        "Request: " + p.Request,
+		Description: p.Summary(),
+		Enabled:     true,
+		Priority:    1,
+		Paths:       p.Sources,
+		Recursive:   p.Recursive,
+		MaxDepth:    10,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if len(p.Extensions) > 0 {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternFileExtension,
+			Operator: patterns.OpIn,
+			Value:    unique(p.Extensions),
+		})
+	}
+	if p.NameContains != "" {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternFileName,
+			Operator: patterns.OpContains,
+			Value:    p.NameContains,
+		})
+	}
+	if p.Containing != "" {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternContent,
+			Operator: patterns.OpContains,
+			Value:    p.Containing,
+		})
+	}
+	if p.OlderThan > 0 {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternAge,
+			Operator: patterns.OpGreaterThan,
+			Value:    fmt.Sprintf("%dm", int64(p.OlderThan/time.Minute)),
+		})
+	}
+	if p.NewerThan > 0 {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternAge,
+			Operator: patterns.OpLessThan,
+			Value:    fmt.Sprintf("%dm", int64(p.NewerThan/time.Minute)),
+		})
+	}
+	if p.LargerThan > 0 {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternSize,
+			Operator: patterns.OpGreaterThan,
+			Value:    float64(p.LargerThan),
+		})
+	}
+	if p.SmallerThan > 0 {
+		operation.Filters = append(operation.Filters, patterns.FileFilter{
+			Type:     patterns.PatternSize,
+			Operator: patterns.OpLessThan,
+			Value:    float64(p.SmallerThan),
+		})
+	}
+
+	action := patterns.Action{Type: string(p.Action)}
+	if p.Destination != "" {
+		action.Destination = filepath.Join(p.Destination, "{filename}")
+	}
+	operation.Actions = []patterns.Action{action}
+
+	return operation
+}
+
+// BatchJob compiles a plan that names its files into a batch job
+func (p *Plan) BatchJob(manager *batch.BatchManager, config batch.BatchConfig) (*batch.BatchJob, error) {
+	switch p.Action {
+	case ActionMove:
+		return manager.BatchMove(p.Files, p.Destination, config)
+	case ActionCopy:
+		return manager.BatchCopy(p.Files, p.Destination, config)
+	case ActionDelete:
+		return manager.BatchDelete(p.Files, config)
+	default:
+		return nil, fmt.Errorf("cannot %s named files as a batch job", p.Action)
+	}
+}
+
+// formatAge prints a duration in the largest whole unit
+func formatAge(d time.Duration) string {
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{365 * 24 * time.Hour, "year"},
+		{30 * 24 * time.Hour, "month"},
+		{7 * 24 * time.Hour, "week"},
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+	}
+
+	for _, unit := range units {
+		if d >= unit.size && d%unit.size == 0 {
+			return plural(int64(d/unit.size), unit.name)
+		}
+	}
+	return plural(int64(d/time.Minute), "minute")
+}
+
+// formatSize prints a byte count with a binary unit
+func formatSize(bytes int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64) + units[unit]
+}
+
+// plural prints a count with its unit, adding an s when needed
+func plural(count int64, unit string) string {
+	if count == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
+// unique drops repeated values, keeping the first occurrence
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
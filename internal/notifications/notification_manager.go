@@ -2,6 +2,9 @@
  * Notification Manager Module
  *
  * Provides cross-platform desktop notifications for completed tasks and system events.
+ * Besides the ones sent on request, batch jobs, pattern runs, backups, downloads,
+ * app scans and slow interactive commands notify on their own, each kind
+ * switched on or off under the automatic section of the notifications config.
  *
  * Author: KleaSCM
  * Email: KleaSCM@gmail.com
@@ -20,6 +23,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"ena/internal/fault"
+	"ena/internal/paths"
 )
 
 // NotificationType represents different types of notifications
@@ -80,12 +86,77 @@ type NotificationManager struct {
 
 // NotificationConfig holds notification configuration
 type NotificationConfig struct {
-	Enabled         bool
-	DefaultDuration time.Duration
-	MaxHistory      int
-	SoundEnabled    bool
-	IconPath        string
-	Timeout         time.Duration
+	Enabled              bool                   `json:"enabled" yaml:"enabled"`
+	DefaultDuration      time.Duration          `json:"default_duration" yaml:"default_duration"`
+	MaxHistory           int                    `json:"max_history" yaml:"max_history"`
+	SoundEnabled         bool                   `json:"sound_enabled" yaml:"sound_enabled"`
+	IconPath             string                 `json:"icon_path" yaml:"icon_path"`
+	Timeout              time.Duration          `json:"timeout" yaml:"timeout"`
+	Automatic            NotificationCategories `json:"automatic" yaml:"automatic"`
+	LongCommandThreshold time.Duration          `json:"long_command_threshold" yaml:"long_command_threshold"` // 0 never notifies
+}
+
+// Category is a kind of work that notifies on its own when it finishes
+type Category string
+
+const (
+	CategoryBatch        Category = "batch"
+	CategoryPatterns     Category = "patterns"
+	CategoryBackups      Category = "backups"
+	CategoryDownloads    Category = "downloads"
+	CategoryAppScans     Category = "app_scans"
+	CategoryLongCommands Category = "long_commands"
+)
+
+// NotificationCategories switches automatic notifications per kind of work
+type NotificationCategories struct {
+	Batch        bool `json:"batch" yaml:"batch"`
+	Patterns     bool `json:"patterns" yaml:"patterns"`
+	Backups      bool `json:"backups" yaml:"backups"`
+	Downloads    bool `json:"downloads" yaml:"downloads"`
+	AppScans     bool `json:"app_scans" yaml:"app_scans"`
+	LongCommands bool `json:"long_commands" yaml:"long_commands"`
+}
+
+// Wants reports whether category notifies on its own
+func (c NotificationCategories) Wants(category Category) bool {
+	switch category {
+	case CategoryBatch:
+		return c.Batch
+	case CategoryPatterns:
+		return c.Patterns
+	case CategoryBackups:
+		return c.Backups
+	case CategoryDownloads:
+		return c.Downloads
+	case CategoryAppScans:
+		return c.AppScans
+	case CategoryLongCommands:
+		return c.LongCommands
+	default:
+		return false
+	}
+}
+
+// DefaultNotificationConfig returns the settings used when nothing is configured
+func DefaultNotificationConfig() *NotificationConfig {
+	return &NotificationConfig{
+		Enabled:         true,
+		DefaultDuration: 5 * time.Second,
+		MaxHistory:      100,
+		SoundEnabled:    true,
+		IconPath:        "",
+		Timeout:         10 * time.Second,
+		Automatic: NotificationCategories{
+			Batch:        true,
+			Patterns:     true,
+			Backups:      true,
+			Downloads:    true,
+			AppScans:     true,
+			LongCommands: true,
+		},
+		LongCommandThreshold: 30 * time.Second,
+	}
 }
 
 // NewNotificationManager creates a new notification manager
@@ -94,17 +165,10 @@ func NewNotificationManager() *NotificationManager {
 		enabled:       true,
 		platform:      runtime.GOOS,
 		notifications: make(map[string]*Notification),
-		config: &NotificationConfig{
-			Enabled:         true,
-			DefaultDuration: 5 * time.Second,
-			MaxHistory:      100,
-			SoundEnabled:    true,
-			IconPath:        "",
-			Timeout:         10 * time.Second,
-		},
-		history:     make([]*Notification, 0),
-		maxHistory:  100,
-		historyFile: "notifications_history.json",
+		config:        DefaultNotificationConfig(),
+		history:       make([]*Notification, 0),
+		maxHistory:    100,
+		historyFile:   paths.StateFile("notifications_history.json"),
 	}
 
 	// Detect platform capabilities
@@ -217,6 +281,33 @@ func (nm *NotificationManager) SendNotification(notification *Notification) erro
 	return nm.sendPlatformNotification(notification)
 }
 
+// Notify sends a notification nobody asked for, on behalf of category, unless
+// notifications or that category are switched off. A failure to show it is
+// not reported; it stays in the history.
+func (nm *NotificationManager) Notify(category Category, notification *Notification) {
+	config := nm.GetConfig()
+	if !nm.IsEnabled() || !config.Enabled || !config.Automatic.Wants(category) {
+		return
+	}
+	nm.SendNotification(notification)
+}
+
+// NotifyIfSlow notifies that command finished if it took at least the long
+// command threshold, so work left running in a terminal is not forgotten
+func (nm *NotificationManager) NotifyIfSlow(command string, duration time.Duration, ok bool) {
+	threshold := nm.GetConfig().LongCommandThreshold
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	took := duration.Round(time.Second)
+	if ok {
+		nm.Notify(CategoryLongCommands, nm.CreateTaskNotification("Command finished", fmt.Sprintf("%s finished after %v", command, took)))
+		return
+	}
+	nm.Notify(CategoryLongCommands, nm.CreateErrorNotification("Command failed", fmt.Sprintf("%s failed after %v", command, took)))
+}
+
 // sendPlatformNotification sends notification using platform-specific method
 func (nm *NotificationManager) sendPlatformNotification(notification *Notification) error {
 	switch nm.platform {
@@ -376,7 +467,7 @@ func (nm *NotificationManager) DismissNotification(id string) error {
 	defer nm.mutex.Unlock()
 
 	if _, exists := nm.notifications[id]; !exists {
-		return fmt.Errorf("notification '%s' not found", id)
+		return fault.NotFound("notification '%s' not found", id)
 	}
 
 	delete(nm.notifications, id)
@@ -529,11 +620,11 @@ func (nm *NotificationManager) saveNotificationHistory() error {
 
 	data, err := json.MarshalIndent(historyData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal notification history: %v", err)
+		return fmt.Errorf("failed to marshal notification history: %w", err)
 	}
 
 	if err := os.WriteFile(nm.historyFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write notification history file: %v", err)
+		return fmt.Errorf("failed to write notification history file: %w", err)
 	}
 
 	return nil
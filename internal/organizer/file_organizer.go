@@ -25,7 +25,12 @@ import (
 	"sync"
 	"time"
 
+	"ena/internal/audit"
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/paths"
 	"ena/internal/suggestions"
+	"ena/internal/vfs"
 )
 
 // FileType represents the type of a file based on extension and content
@@ -83,6 +88,7 @@ type OrganizationResult struct {
 	Errors         []string              `json:"errors"`
 	Duration       time.Duration         `json:"duration"`
 	Details        []FileOperationDetail `json:"details"`
+	Changes        []vfs.Change          `json:"changes,omitempty"` // What a dry run would have changed
 }
 
 // FileOperationDetail contains details about a specific file operation
@@ -98,40 +104,49 @@ type FileOperationDetail struct {
 
 // FileOrganizer manages file organization operations
 type FileOrganizer struct {
-	rules          map[string]*OrganizationRule
-	fileTypes      map[string]*FileType
-	analytics      *suggestions.UsageAnalytics
-	mutex          sync.RWMutex
-	configFile     string
-	rulesFile      string
-	eventCallbacks map[string][]OrganizationEventCallback
-	isRunning      bool
-	stopChan       chan struct{}
-}
-
-// OrganizationEventCallback is a function that gets called on organization events
-type OrganizationEventCallback func(event OrganizationEvent)
+	rules     map[string]*OrganizationRule
+	fileTypes map[string]*FileType
+	analytics *suggestions.UsageAnalytics
+	mutex     sync.RWMutex
+	rulesFile string
+	events    *events.Bus
+	isRunning bool
+	stopChan  chan struct{}
+	fs        vfs.FS
+}
+
+// Topics the file organizer publishes; every payload is an OrganizationEvent
+var (
+	TopicRuleAdded       = events.Define("organizer.rule_added", "An organization rule was added")
+	TopicRuleRemoved     = events.Define("organizer.rule_removed", "An organization rule was removed")
+	TopicWatchingStarted = events.Define("organizer.watching_started", "Real-time organization started")
+	TopicWatchingStopped = events.Define("organizer.watching_stopped", "Real-time organization stopped")
+	TopicFileDetected    = events.Define("organizer.file_detected", "A watched file appeared or changed")
+	TopicFileOrganized   = events.Define("organizer.file_organized", "A watched file was organized by a rule")
+	TopicOrganizeFailed  = events.Define("organizer.organize_failed", "Organizing a watched file failed")
+)
 
-// OrganizationEvent represents an event that occurred during organization
+// OrganizationEvent is the payload of organizer events
 type OrganizationEvent struct {
-	Type      string                 `json:"type"` // rule_applied, file_organized, error, etc.
-	RuleID    string                 `json:"rule_id,omitempty"`
-	FilePath  string                 `json:"file_path,omitempty"`
-	Message   string                 `json:"message"`
-	Data      map[string]interface{} `json:"data,omitempty"`
-	Timestamp time.Time              `json:"timestamp"`
+	RuleID   string                 `json:"rule_id,omitempty"`
+	FilePath string                 `json:"file_path,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
 }
 
 // NewFileOrganizer creates a new file organizer instance
 func NewFileOrganizer(analytics *suggestions.UsageAnalytics) *FileOrganizer {
+	return NewFileOrganizerWithFS(vfs.OS{}, analytics)
+}
+
+// NewFileOrganizerWithFS creates a file organizer that sorts files on the given filesystem
+func NewFileOrganizerWithFS(fs vfs.FS, analytics *suggestions.UsageAnalytics) *FileOrganizer {
 	fo := &FileOrganizer{
-		rules:          make(map[string]*OrganizationRule),
-		fileTypes:      make(map[string]*FileType),
-		analytics:      analytics,
-		configFile:     "organizer_config.json",
-		rulesFile:      "organizer_rules.json",
-		eventCallbacks: make(map[string][]OrganizationEventCallback),
-		stopChan:       make(chan struct{}),
+		rules:     make(map[string]*OrganizationRule),
+		fileTypes: make(map[string]*FileType),
+		analytics: analytics,
+		rulesFile: paths.DataFile("organizer_rules.json"),
+		stopChan:  make(chan struct{}),
+		fs:        fs,
 	}
 
 	// Initialize default file types
@@ -157,12 +172,7 @@ func (fo *FileOrganizer) AddRule(rule *OrganizationRule) error {
 
 	fo.rules[rule.ID] = rule
 
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "rule_added",
-		RuleID:    rule.ID,
-		Message:   fmt.Sprintf("Added organization rule: %s", rule.Name),
-		Timestamp: time.Now(),
-	})
+	fo.publish(TopicRuleAdded, fmt.Sprintf("Added organization rule: %s", rule.Name), OrganizationEvent{RuleID: rule.ID})
 
 	return fo.saveRules()
 }
@@ -173,17 +183,12 @@ func (fo *FileOrganizer) RemoveRule(ruleID string) error {
 	defer fo.mutex.Unlock()
 
 	if _, exists := fo.rules[ruleID]; !exists {
-		return fmt.Errorf("rule %s not found", ruleID)
+		return fault.NotFound("rule %s not found", ruleID)
 	}
 
 	delete(fo.rules, ruleID)
 
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "rule_removed",
-		RuleID:    ruleID,
-		Message:   fmt.Sprintf("Removed organization rule: %s", ruleID),
-		Timestamp: time.Now(),
-	})
+	fo.publish(TopicRuleRemoved, fmt.Sprintf("Removed organization rule: %s", ruleID), OrganizationEvent{RuleID: ruleID})
 
 	return fo.saveRules()
 }
@@ -222,6 +227,8 @@ func (fo *FileOrganizer) OrganizeFiles(sourcePaths []string, dryRun bool) ([]Org
 	}
 
 	var results []OrganizationResult
+	files, overlay := fo.target(dryRun)
+	recorded := 0
 
 	for _, rule := range rules {
 		// Check if rule applies to any of the source paths
@@ -229,11 +236,18 @@ func (fo *FileOrganizer) OrganizeFiles(sourcePaths []string, dryRun bool) ([]Org
 			continue
 		}
 
-		result, err := fo.applyRule(rule, sourcePaths, dryRun)
+		result, err := fo.applyRule(files, rule, sourcePaths, dryRun)
 		if err != nil {
 			result.Errors = append(result.Errors, err.Error())
 		}
 
+		// Later rules see what earlier ones did, so each keeps only its own changes
+		if overlay != nil {
+			changes := overlay.Changes()
+			result.Changes = changes[recorded:]
+			recorded = len(changes)
+		}
+
 		results = append(results, result)
 	}
 
@@ -263,10 +277,14 @@ func (fo *FileOrganizer) OrganizeFile(filePath string, dryRun bool) (*Organizati
 	// Find the first applicable rule
 	for _, rule := range rules {
 		if fo.ruleMatchesFile(rule, filePath) {
-			result, err := fo.applyRuleToFile(rule, filePath, dryRun)
+			files, overlay := fo.target(dryRun)
+			result, err := fo.applyRuleToFile(files, rule, filePath, dryRun)
 			if err != nil {
 				result.Errors = append(result.Errors, err.Error())
 			}
+			if overlay != nil {
+				result.Changes = overlay.Changes()
+			}
 			return &result, err
 		}
 	}
@@ -295,11 +313,7 @@ func (fo *FileOrganizer) StartWatching() error {
 	fo.isRunning = true
 	go fo.watchFiles()
 
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "watching_started",
-		Message:   "Started real-time file organization",
-		Timestamp: time.Now(),
-	})
+	fo.publish(TopicWatchingStarted, "Started real-time file organization", OrganizationEvent{})
 
 	return nil
 }
@@ -310,17 +324,13 @@ func (fo *FileOrganizer) StopWatching() error {
 	defer fo.mutex.Unlock()
 
 	if !fo.isRunning {
-		return fmt.Errorf("file organizer is not running")
+		return fault.Unavailable("file organizer is not running")
 	}
 
 	close(fo.stopChan)
 	fo.isRunning = false
 
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "watching_stopped",
-		Message:   "Stopped real-time file organization",
-		Timestamp: time.Now(),
-	})
+	fo.publish(TopicWatchingStopped, "Stopped real-time file organization", OrganizationEvent{})
 
 	return nil
 }
@@ -339,7 +349,7 @@ func (fo *FileOrganizer) GetFileType(filePath string) (*FileType, error) {
 	}
 
 	// Try to determine by MIME type
-	file, err := os.Open(filePath)
+	file, err := fo.fs.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -476,7 +486,17 @@ func (fo *FileOrganizer) ruleMatchesFile(rule *OrganizationRule, filePath string
 	return false
 }
 
-func (fo *FileOrganizer) applyRule(rule *OrganizationRule, sourcePaths []string, dryRun bool) (OrganizationResult, error) {
+// target returns the filesystem a run acts on: the real one, or for a dry run
+// an overlay that records what would change
+func (fo *FileOrganizer) target(dryRun bool) (vfs.FS, *vfs.Overlay) {
+	if !dryRun {
+		return fo.fs, nil
+	}
+	overlay := vfs.NewOverlay(fo.fs)
+	return overlay, overlay
+}
+
+func (fo *FileOrganizer) applyRule(files vfs.FS, rule *OrganizationRule, sourcePaths []string, dryRun bool) (OrganizationResult, error) {
 	result := OrganizationResult{
 		RuleID: rule.ID,
 	}
@@ -489,7 +509,7 @@ func (fo *FileOrganizer) applyRule(rule *OrganizationRule, sourcePaths []string,
 	// Collect files to process
 	var filesToProcess []string
 	for _, sourcePath := range sourcePaths {
-		err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		err := vfs.Walk(files, sourcePath, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -514,7 +534,7 @@ func (fo *FileOrganizer) applyRule(rule *OrganizationRule, sourcePaths []string,
 
 	// Process files
 	for _, filePath := range filesToProcess {
-		detail, err := fo.processFile(rule, filePath, dryRun)
+		detail, err := fo.processFile(files, rule, filePath, dryRun)
 		if err != nil {
 			detail.Error = err.Error()
 			result.Errors = append(result.Errors, err.Error())
@@ -545,7 +565,7 @@ func (fo *FileOrganizer) applyRule(rule *OrganizationRule, sourcePaths []string,
 	return result, nil
 }
 
-func (fo *FileOrganizer) applyRuleToFile(rule *OrganizationRule, filePath string, dryRun bool) (OrganizationResult, error) {
+func (fo *FileOrganizer) applyRuleToFile(files vfs.FS, rule *OrganizationRule, filePath string, dryRun bool) (OrganizationResult, error) {
 	result := OrganizationResult{
 		RuleID: rule.ID,
 	}
@@ -555,7 +575,7 @@ func (fo *FileOrganizer) applyRuleToFile(rule *OrganizationRule, filePath string
 		result.Duration = time.Since(startTime)
 	}()
 
-	detail, err := fo.processFile(rule, filePath, dryRun)
+	detail, err := fo.processFile(files, rule, filePath, dryRun)
 	if err != nil {
 		detail.Error = err.Error()
 		result.Errors = append(result.Errors, err.Error())
@@ -580,8 +600,8 @@ func (fo *FileOrganizer) applyRuleToFile(rule *OrganizationRule, filePath string
 	return result, nil
 }
 
-func (fo *FileOrganizer) processFile(rule *OrganizationRule, filePath string, dryRun bool) (FileOperationDetail, error) {
-	info, err := os.Stat(filePath)
+func (fo *FileOrganizer) processFile(files vfs.FS, rule *OrganizationRule, filePath string, dryRun bool) (FileOperationDetail, error) {
+	info, err := files.Stat(filePath)
 	if err != nil {
 		return FileOperationDetail{
 			FilePath: filePath,
@@ -602,51 +622,62 @@ func (fo *FileOrganizer) processFile(rule *OrganizationRule, filePath string, dr
 	for _, action := range rule.Actions {
 		switch action.Type {
 		case "move":
+			destPath := fo.buildDestinationPath(rule, filePath, action.Destination)
+			err = fo.moveFile(files, filePath, destPath)
 			if !dryRun {
-				destPath := fo.buildDestinationPath(rule, filePath, action.Destination)
-				err = fo.moveFile(filePath, destPath)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
-				detail.Destination = destPath
+				audit.RecordFile("organizer", "move", filePath, destPath, err)
+			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
 			}
+			detail.Destination = destPath
 			detail.Action = "move"
 			detail.Success = true
 
 		case "copy":
+			destPath := fo.buildDestinationPath(rule, filePath, action.Destination)
+			err = vfs.CopyFile(files, filePath, destPath)
 			if !dryRun {
-				destPath := fo.buildDestinationPath(rule, filePath, action.Destination)
-				err = fo.copyFile(filePath, destPath)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
-				detail.Destination = destPath
+				audit.RecordFile("organizer", "copy", filePath, destPath, err)
+			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
 			}
+			detail.Destination = destPath
 			detail.Action = "copy"
 			detail.Success = true
 
 		case "rename":
+			newName := fo.buildFileName(filePath, action.Template)
+			err = fo.renameFile(files, filePath, newName)
 			if !dryRun {
-				newName := fo.buildFileName(filePath, action.Template)
-				err = fo.renameFile(filePath, newName)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
-				detail.Destination = newName
+				audit.RecordFile("organizer", "rename", filePath, newName, err)
+			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
 			}
+			detail.Destination = newName
 			detail.Action = "rename"
 			detail.Success = true
 
 		case "delete":
+			// Files go to the trash unless the action has permanent: "true"
+			deleted := "trash"
+			if action.Parameters["permanent"] == "true" {
+				deleted = "delete"
+				err = files.Remove(filePath)
+			} else {
+				err = vfs.Trash(files, filePath)
+			}
 			if !dryRun {
-				err = os.Remove(filePath)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
+				audit.RecordFile("organizer", deleted, filePath, "", err)
+			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
 			}
 			detail.Action = "delete"
 			detail.Success = true
@@ -702,74 +733,42 @@ func (fo *FileOrganizer) buildFileName(filePath, template string) string {
 	return newName
 }
 
-func (fo *FileOrganizer) moveFile(src, dest string) error {
-	// Ensure destination directory exists
-	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
-		return err
-	}
-
-	return os.Rename(src, dest)
-}
-
-func (fo *FileOrganizer) copyFile(src, dest string) error {
+func (fo *FileOrganizer) moveFile(files vfs.FS, src, dest string) error {
 	// Ensure destination directory exists
-	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
-		return err
-	}
-
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dest)
-	if err != nil {
+	if err := files.MkdirAll(filepath.Dir(dest), 0755); err != nil {
 		return err
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+	return files.Rename(src, dest)
 }
 
-func (fo *FileOrganizer) renameFile(src, newName string) error {
+func (fo *FileOrganizer) renameFile(files vfs.FS, src, newName string) error {
 	dest := filepath.Join(filepath.Dir(src), newName)
-	return os.Rename(src, dest)
+	return files.Rename(src, dest)
 }
 
 func (fo *FileOrganizer) watchFiles() {
 	// Use the existing comprehensive file watcher system
-	// This will be called by the main file watcher when files are detected
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "watching_started",
-		Message:   "Real-time file organization ready",
-		Timestamp: time.Now(),
-	})
+	// This will be called by the main file watcher when files are detected;
+	// StartWatching and StopWatching announce the start and stop
 
 	// Keep running until stopped
 	<-fo.stopChan
-
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "watching_stopped",
-		Message:   "Real-time file organization stopped",
-		Timestamp: time.Now(),
-	})
 }
 
 func (fo *FileOrganizer) loadRules() error {
-	if _, err := os.Stat(fo.rulesFile); os.IsNotExist(err) {
+	if _, err := fo.fs.Stat(fo.rulesFile); os.IsNotExist(err) {
 		return nil // No rules file exists yet
 	}
 
-	data, err := os.ReadFile(fo.rulesFile)
+	data, err := fo.fs.ReadFile(fo.rulesFile)
 	if err != nil {
-		return fmt.Errorf("error reading rules file: %v", err)
+		return fmt.Errorf("error reading rules file: %w", err)
 	}
 
 	var rules []*OrganizationRule
 	if err := json.Unmarshal(data, &rules); err != nil {
-		return fmt.Errorf("error parsing rules file: %v", err)
+		return fmt.Errorf("error parsing rules file: %w", err)
 	}
 
 	for _, rule := range rules {
@@ -787,41 +786,31 @@ func (fo *FileOrganizer) saveRules() error {
 
 	data, err := json.MarshalIndent(rules, "", "  ")
 	if err != nil {
-		return fmt.Errorf("error marshaling rules: %v", err)
+		return fmt.Errorf("error marshaling rules: %w", err)
 	}
 
-	if err := os.WriteFile(fo.rulesFile, data, 0644); err != nil {
-		return fmt.Errorf("error writing rules file: %v", err)
+	if err := fo.fs.WriteFile(fo.rulesFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing rules file: %w", err)
 	}
 
 	return nil
 }
 
-func (fo *FileOrganizer) triggerEvent(event OrganizationEvent) {
-	fo.mutex.RLock()
-	callbacks := fo.eventCallbacks[event.Type]
-	fo.mutex.RUnlock()
-
-	for _, callback := range callbacks {
-		go callback(event) // Run callbacks asynchronously
-	}
+// SetEventBus sets the bus organizer events are published on
+func (fo *FileOrganizer) SetEventBus(bus *events.Bus) {
+	fo.events = bus
 }
 
-// AddEventCallback adds a callback for organization events
-func (fo *FileOrganizer) AddEventCallback(eventType string, callback OrganizationEventCallback) {
-	fo.mutex.Lock()
-	defer fo.mutex.Unlock()
-
-	fo.eventCallbacks[eventType] = append(fo.eventCallbacks[eventType], callback)
+// publish announces an organizer event on the event bus
+func (fo *FileOrganizer) publish(topic events.Topic, message string, event OrganizationEvent) {
+	fo.events.Publish(events.New(topic, message, event))
 }
 
 // HandleFileEvent processes file events from the existing file watcher
 func (fo *FileOrganizer) HandleFileEvent(filePath string, eventType string) {
-	fo.triggerEvent(OrganizationEvent{
-		Type:      "file_detected",
-		FilePath:  filePath,
-		Message:   fmt.Sprintf("New file detected: %s", filepath.Base(filePath)),
-		Timestamp: time.Now(),
+	fo.publish(TopicFileDetected, fmt.Sprintf("New file detected: %s", filepath.Base(filePath)), OrganizationEvent{
+		FilePath: filePath,
+		Data:     map[string]interface{}{"event": eventType},
 	})
 
 	// Only process file creation and move events for organization
@@ -829,28 +818,23 @@ func (fo *FileOrganizer) HandleFileEvent(filePath string, eventType string) {
 		// Organize the file automatically
 		result, err := fo.OrganizeFile(filePath, false) // false = not dry run
 		if err != nil {
-			fo.triggerEvent(OrganizationEvent{
-				Type:      "error",
-				FilePath:  filePath,
-				Message:   fmt.Sprintf("Failed to organize file %s: %v", filePath, err),
-				Timestamp: time.Now(),
+			fo.publish(TopicOrganizeFailed, fmt.Sprintf("Failed to organize file %s: %v", filePath, err), OrganizationEvent{
+				FilePath: filePath,
+				Data:     map[string]interface{}{"error": err.Error()},
 			})
 			return
 		}
 
 		// Report organization result
 		if result.FilesProcessed > 0 {
-			fo.triggerEvent(OrganizationEvent{
-				Type:     "file_organized",
+			fo.publish(TopicFileOrganized, fmt.Sprintf("File organized: %s", filepath.Base(filePath)), OrganizationEvent{
+				RuleID:   result.RuleID,
 				FilePath: filePath,
-				Message:  fmt.Sprintf("File organized: %s", filepath.Base(filePath)),
 				Data: map[string]interface{}{
-					"rule_id":     result.RuleID,
 					"action":      result.Details[0].Action,
 					"destination": result.Details[0].Destination,
 					"success":     result.Details[0].Success,
 				},
-				Timestamp: time.Now(),
 			})
 		}
 	}
@@ -0,0 +1,265 @@
+/**
+ * Structured Output
+ *
+ * Renders command results as JSON, YAML or plain tables so Ena can be driven
+ * from scripts. Every structured result is wrapped in the same envelope:
+ *
+ *   command  full command path, e.g. "batch-status"
+ *   ok       false when the command reported an error
+ *   data     the command's payload (typed result or {"message": ...})
+ *   error    error message, only present when ok is false
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: output.go
+ * Description: Output formats and envelope rendering for machine-readable results
+ */
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how command results are printed
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatTable Format = "table"
+)
+
+// Formats lists every supported output format
+var Formats = []Format{FormatText, FormatJSON, FormatYAML, FormatTable}
+
+// ParseFormat validates an output format name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unknown output format %q (expected %s)", name, strings.Join(names, ", "))
+}
+
+// Structured reports whether the format replaces the normal prose output
+func (f Format) Structured() bool {
+	return f != FormatText && f != ""
+}
+
+// Document is the envelope every structured command emits
+type Document struct {
+	Command string      `json:"command" yaml:"command"`
+	OK      bool        `json:"ok" yaml:"ok"`
+	Data    interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	Error   string      `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Message is the payload of commands that only produce prose
+type Message struct {
+	Message string `json:"message"`
+}
+
+// ansiPattern matches terminal colour escape sequences
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StripANSI removes terminal colour codes from captured prose
+func StripANSI(text string) string {
+	return ansiPattern.ReplaceAllString(text, "")
+}
+
+// Write renders a document in the given format
+func Write(w io.Writer, format Format, doc Document) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	}
+
+	// Other formats see the payload through JSON so field names match everywhere
+	data, err := normalize(doc.Data)
+	if err != nil {
+		return fmt.Errorf("Failed to encode result: %w", err)
+	}
+	doc.Data = data
+
+	switch format {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(doc)
+	case FormatTable:
+		return writeTable(w, doc)
+	default:
+		return fmt.Errorf("format %q is not structured", format)
+	}
+}
+
+// normalize converts typed results into plain maps, slices and scalars
+func normalize(data interface{}) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, err
+	}
+	return restoreIntegers(generic), nil
+}
+
+// restoreIntegers turns whole float64 values back into integers so sizes and
+// durations are not printed in exponent form
+func restoreIntegers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = restoreIntegers(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = restoreIntegers(v[key])
+		}
+	}
+	return value
+}
+
+// writeTable prints the payload as aligned columns; nested values are left to json/yaml
+func writeTable(w io.Writer, doc Document) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if !doc.OK {
+		fmt.Fprintf(tw, "ERROR\t%s\n", doc.Error)
+	}
+
+	switch data := doc.Data.(type) {
+	case nil:
+	case []interface{}:
+		writeRows(tw, data)
+	case map[string]interface{}:
+		// Scalar fields as key/value pairs, then a section per object or list of records
+		writeFields(tw, data)
+		for _, key := range sortedKeys(data) {
+			switch value := data[key].(type) {
+			case map[string]interface{}:
+				fmt.Fprintf(tw, "\n%s:\n", strings.ToUpper(key))
+				writeFields(tw, value)
+			case []interface{}:
+				if len(value) > 0 {
+					fmt.Fprintf(tw, "\n%s:\n", strings.ToUpper(key))
+					writeRows(tw, value)
+				}
+			}
+		}
+	default:
+		fmt.Fprintln(tw, formatScalar(data))
+	}
+
+	return tw.Flush()
+}
+
+// writeFields prints the scalar fields of a record as key/value pairs
+func writeFields(tw *tabwriter.Writer, record map[string]interface{}) {
+	for _, key := range sortedKeys(record) {
+		if isScalar(record[key]) {
+			fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(key), formatScalar(record[key]))
+		}
+	}
+}
+
+// writeRows prints a list of records with one column per scalar field
+func writeRows(tw *tabwriter.Writer, rows []interface{}) {
+	columnSet := make(map[string]interface{})
+	for _, row := range rows {
+		if record, ok := row.(map[string]interface{}); ok {
+			for key, value := range record {
+				if isScalar(value) {
+					columnSet[key] = true
+				}
+			}
+		}
+	}
+
+	// A list of plain values prints one per line
+	if len(columnSet) == 0 {
+		for _, row := range rows {
+			fmt.Fprintln(tw, formatScalar(row))
+		}
+		return
+	}
+
+	columns := sortedKeys(columnSet)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		record, _ := row.(map[string]interface{})
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = formatScalar(record[column])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+}
+
+// isScalar reports whether a normalised value fits in a single table cell
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+// formatScalar renders a normalised value for a table cell
+func formatScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		// Keep multi-line messages on one row
+		return strings.ReplaceAll(strings.TrimSpace(v), "\n", " | ")
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sortedKeys returns map keys in a stable order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
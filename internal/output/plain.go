@@ -0,0 +1,148 @@
+/**
+ * Plain Output
+ *
+ * Strips the decoration from Ena's prose so it reads well in scripts, log
+ * files and screen readers: colour and cursor codes, emoji, kaomoji, drawn
+ * progress bars and box-drawing rules. Only Ena's own message text is
+ * stripped; the file contents, paths and plugin output it prints are not.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: plain.go
+ * Description: Decoration stripping for plain text output
+ */
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+var (
+	// escapePattern matches colour and cursor control sequences
+	escapePattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+	// barPattern matches a drawn progress bar such as [████░░░░]
+	barPattern = regexp.MustCompile(`\[\p{So}+\] ?`)
+	// kaomojiPattern matches faces such as (╹◡╹)♡
+	kaomojiPattern = regexp.MustCompile(` ?\([^\sA-Za-z0-9()]*[\p{So}\p{Sk}][^\sA-Za-z0-9()]*\)\p{So}*`)
+)
+
+// Plain removes decoration from text. Lines made only of decoration are
+// dropped and spaces left at the ends of lines are trimmed.
+func Plain(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for i, line := range lines {
+		plain, keep := plainLine(line, i < len(lines)-1)
+		if keep {
+			kept = append(kept, plain)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// plainLine strips one line. A complete line that held only decoration is
+// not kept; a partial one, such as a prompt, keeps its trailing spaces.
+func plainLine(line string, complete bool) (string, bool) {
+	plain := escapePattern.ReplaceAllString(line, "")
+	plain = barPattern.ReplaceAllString(plain, "")
+	plain = kaomojiPattern.ReplaceAllString(plain, "")
+	plain = stripSymbols(plain)
+
+	if plain == line {
+		return line, true
+	}
+	if complete {
+		plain = strings.TrimRight(plain, " \t")
+		if plain == "" && strings.TrimSpace(line) != "" {
+			return "", false
+		}
+	}
+	return plain, true
+}
+
+// isDecoration reports whether r is an emoji, pictograph or drawn symbol
+func isDecoration(r rune) bool {
+	switch {
+	case r == '\uFE0F', r == '\u200D', r == '\u20E3': // emoji presentation, joiner, keycap
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tones
+		return true
+	}
+	return r >= 0x2000 && unicode.Is(unicode.So, r)
+}
+
+// stripSymbols removes decoration runes and the spaces after them, leaving one
+// space where the symbol sat between two words
+func stripSymbols(text string) string {
+	if !strings.ContainsFunc(text, isDecoration) && !strings.ContainsRune(text, '→') {
+		return text
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '→' {
+			b.WriteString("->")
+			continue
+		}
+		if !isDecoration(r) {
+			b.WriteRune(r)
+			continue
+		}
+
+		for i+1 < len(runes) && isDecoration(runes[i+1]) {
+			i++
+		}
+		spaces := 0
+		for i+1 < len(runes) && runes[i+1] == ' ' {
+			i++
+			spaces++
+		}
+		before := b.String()
+		if spaces > 0 && before != "" && !strings.HasSuffix(before, " ") && i+1 < len(runes) {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// plainMode is set while the running command prints plain prose
+var plainMode atomic.Bool
+
+// SetPlain turns plain prose on or off
+func SetPlain(plain bool) {
+	plainMode.Store(plain)
+}
+
+// PlainMode reports whether prose is printed plain
+func PlainMode() bool {
+	return plainMode.Load()
+}
+
+// Prose returns one of Ena's own messages ready to print, without its
+// decoration in plain mode. File contents, paths and other user data must
+// never go through it.
+func Prose(text string) string {
+	if !PlainMode() {
+		return text
+	}
+	return Plain(text)
+}
+
+// Printf prints a message to stdout. In plain mode the decoration in format
+// is dropped; the arguments are printed exactly as given.
+func Printf(format string, args ...interface{}) (int, error) {
+	return fmt.Printf(Prose(format), args...)
+}
+
+// Fprintf is Printf writing to w
+func Fprintf(w io.Writer, format string, args ...interface{}) (int, error) {
+	return fmt.Fprintf(w, Prose(format), args...)
+}
@@ -0,0 +1,78 @@
+/**
+ * XDG Base Directories
+ *
+ * Resolves where Ena keeps its files following the XDG Base Directory
+ * specification: settings under $XDG_CONFIG_HOME/ena, durable data such as
+ * backups and rules under $XDG_DATA_HOME/ena, and history, logs and other
+ * state under $XDG_STATE_HOME/ena.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: paths.go
+ * Description: XDG-compliant config, data and state directory resolution
+ */
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appName is the directory created inside each XDG base directory
+const appName = "ena"
+
+// ConfigDir returns $XDG_CONFIG_HOME/ena, defaulting to ~/.config/ena
+func ConfigDir() string {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataDir returns $XDG_DATA_HOME/ena, defaulting to ~/.local/share/ena
+func DataDir() string {
+	return baseDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// DataHome returns $XDG_DATA_HOME itself, defaulting to ~/.local/share, where
+// data shared with other programs, such as the trash, lives
+func DataHome() string {
+	return filepath.Dir(DataDir())
+}
+
+// StateDir returns $XDG_STATE_HOME/ena, defaulting to ~/.local/state/ena
+func StateDir() string {
+	return baseDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// ConfigFile returns the path of a file in the config directory
+func ConfigFile(name string) string {
+	return filepath.Join(ConfigDir(), name)
+}
+
+// DataFile returns the path of a file in the data directory, creating its parent
+func DataFile(name string) string {
+	return ensureParent(filepath.Join(DataDir(), name))
+}
+
+// StateFile returns the path of a file in the state directory, creating its parent
+func StateFile(name string) string {
+	return ensureParent(filepath.Join(StateDir(), name))
+}
+
+// baseDir resolves an XDG variable; relative values are ignored as the spec requires
+func baseDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(home, fallback, appName)
+}
+
+// ensureParent creates the directory holding path so managers can write straight away
+func ensureParent(path string) string {
+	os.MkdirAll(filepath.Dir(path), 0700)
+	return path
+}
@@ -25,7 +25,12 @@ import (
 	"sync"
 	"time"
 
+	"ena/internal/audit"
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/paths"
 	"ena/internal/suggestions"
+	"ena/internal/vfs"
 )
 
 // PatternType defines the type of pattern matching
@@ -110,6 +115,7 @@ type PatternResult struct {
 	Duration       time.Duration         `json:"duration"`
 	Details        []FileOperationDetail `json:"details"`
 	Summary        PatternSummary        `json:"summary"`
+	Changes        []vfs.Change          `json:"changes,omitempty"` // What a dry run would have changed
 }
 
 // FileOperationDetail contains details about a specific file operation
@@ -136,38 +142,48 @@ type PatternSummary struct {
 
 // PatternEngine manages pattern-based file operations
 type PatternEngine struct {
-	operations     map[string]*PatternOperation
-	analytics      *suggestions.UsageAnalytics
-	mutex          sync.RWMutex
-	configFile     string
-	resultsFile    string
-	eventCallbacks map[string][]PatternEventCallback
-	isRunning      bool
-	stopChan       chan struct{}
+	operations map[string]*PatternOperation
+	analytics  *suggestions.UsageAnalytics
+	mutex      sync.RWMutex
+	configFile string
+	resultsDir string
+	events     *events.Bus
+	isRunning  bool
+	stopChan   chan struct{}
+	fs         vfs.FS
 }
 
-// PatternEventCallback is a function that gets called on pattern events
-type PatternEventCallback func(event PatternEvent)
+// Topics the pattern engine publishes; every payload is a PatternEvent
+var (
+	TopicOperationAdded     = events.Define("pattern.operation_added", "A pattern operation was created")
+	TopicOperationRemoved   = events.Define("pattern.operation_removed", "A pattern operation was deleted")
+	TopicOperationStarted   = events.Define("pattern.operation_started", "A pattern operation started running")
+	TopicOperationCompleted = events.Define("pattern.operation_completed", "A pattern operation finished running")
+	TopicOperationFailed    = events.Define("pattern.operation_failed", "A pattern operation finished with files it could not process")
+	TopicAllCompleted       = events.Define("pattern.all_completed", "Every enabled pattern operation was run, as by execute-all")
+)
 
-// PatternEvent represents an event that occurred during pattern operations
+// PatternEvent is the payload of pattern events
 type PatternEvent struct {
-	Type        string                 `json:"type"`
-	OperationID string                 `json:"operation_id,omitempty"`
-	FilePath    string                 `json:"file_path,omitempty"`
-	Message     string                 `json:"message"`
-	Data        map[string]interface{} `json:"data,omitempty"`
-	Timestamp   time.Time              `json:"timestamp"`
+	OperationID   string                 `json:"operation_id"`
+	OperationName string                 `json:"operation_name,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty"`
 }
 
 // NewPatternEngine creates a new pattern engine instance
 func NewPatternEngine(analytics *suggestions.UsageAnalytics) *PatternEngine {
+	return NewPatternEngineWithFS(vfs.OS{}, analytics)
+}
+
+// NewPatternEngineWithFS creates a pattern engine that matches and changes files on the given filesystem
+func NewPatternEngineWithFS(fs vfs.FS, analytics *suggestions.UsageAnalytics) *PatternEngine {
 	pe := &PatternEngine{
-		operations:     make(map[string]*PatternOperation),
-		analytics:      analytics,
-		configFile:     "pattern_operations.json",
-		resultsFile:    "pattern_results.json",
-		eventCallbacks: make(map[string][]PatternEventCallback),
-		stopChan:       make(chan struct{}),
+		operations: make(map[string]*PatternOperation),
+		analytics:  analytics,
+		configFile: paths.DataFile("pattern_operations.json"),
+		resultsDir: filepath.Join(paths.StateDir(), "pattern_results"),
+		stopChan:   make(chan struct{}),
+		fs:         fs,
 	}
 
 	// Load existing operations
@@ -190,11 +206,9 @@ func (pe *PatternEngine) AddOperation(operation *PatternOperation) error {
 
 	pe.operations[operation.ID] = operation
 
-	pe.triggerEvent(PatternEvent{
-		Type:        "operation_added",
-		OperationID: operation.ID,
-		Message:     fmt.Sprintf("Added pattern operation: %s", operation.Name),
-		Timestamp:   time.Now(),
+	pe.publish(TopicOperationAdded, fmt.Sprintf("Added pattern operation: %s", operation.Name), PatternEvent{
+		OperationID:   operation.ID,
+		OperationName: operation.Name,
 	})
 
 	return pe.saveOperations()
@@ -206,16 +220,13 @@ func (pe *PatternEngine) RemoveOperation(operationID string) error {
 	defer pe.mutex.Unlock()
 
 	if _, exists := pe.operations[operationID]; !exists {
-		return fmt.Errorf("operation %s not found", operationID)
+		return fault.NotFound("operation %s not found", operationID)
 	}
 
 	delete(pe.operations, operationID)
 
-	pe.triggerEvent(PatternEvent{
-		Type:        "operation_removed",
+	pe.publish(TopicOperationRemoved, fmt.Sprintf("Removed pattern operation: %s", operationID), PatternEvent{
 		OperationID: operationID,
-		Message:     fmt.Sprintf("Removed pattern operation: %s", operationID),
-		Timestamp:   time.Now(),
 	})
 
 	return pe.saveOperations()
@@ -245,16 +256,16 @@ func (pe *PatternEngine) ExecuteOperation(operationID string, dryRun bool) (*Pat
 	operation, exists := pe.operations[operationID]
 	pe.mutex.RUnlock()
 
-	// For temporary operations, create a new one
-	if !exists && strings.HasPrefix(operationID, "temp_") {
-		operation = pe.CreateSampleOperation()
-		operation.ID = operationID
+	if !exists {
+		return nil, fault.NotFound("operation %s not found", operationID)
 	}
 
-	if !exists && !strings.HasPrefix(operationID, "temp_") {
-		return nil, fmt.Errorf("operation %s not found", operationID)
-	}
+	return pe.Execute(operation, dryRun)
+}
 
+// Execute runs an operation that need not be stored, such as a one-off search
+func (pe *PatternEngine) Execute(operation *PatternOperation, dryRun bool) (*PatternResult, error) {
+	operationID := operation.ID
 	if !operation.Enabled {
 		return nil, fmt.Errorf("operation %s is disabled", operationID)
 	}
@@ -273,11 +284,9 @@ func (pe *PatternEngine) ExecuteOperation(operationID string, dryRun bool) (*Pat
 		pe.saveResult(result)
 	}()
 
-	pe.triggerEvent(PatternEvent{
-		Type:        "operation_started",
-		OperationID: operationID,
-		Message:     fmt.Sprintf("Started pattern operation: %s", operation.Name),
-		Timestamp:   time.Now(),
+	pe.publish(TopicOperationStarted, fmt.Sprintf("Started pattern operation: %s", operation.Name), PatternEvent{
+		OperationID:   operationID,
+		OperationName: operation.Name,
 	})
 
 	// Collect files to process
@@ -299,9 +308,18 @@ func (pe *PatternEngine) ExecuteOperation(operationID string, dryRun bool) (*Pat
 
 	result.FilesMatched = len(matchedFiles)
 
+	// A dry run acts on an overlay, so it fails where the real run would and
+	// records what it would have changed
+	files := pe.fs
+	if dryRun {
+		overlay := vfs.NewOverlay(pe.fs)
+		defer func() { result.Changes = overlay.Changes() }()
+		files = overlay
+	}
+
 	// Process matched files
 	for _, filePath := range matchedFiles {
-		detail, err := pe.processFile(operation, filePath, dryRun)
+		detail, err := pe.processFile(files, operation, filePath, dryRun)
 		if err != nil {
 			detail.Error = err.Error()
 			result.Errors = append(result.Errors, err.Error())
@@ -321,24 +339,34 @@ func (pe *PatternEngine) ExecuteOperation(operationID string, dryRun bool) (*Pat
 	operation.LastRun = &startTime
 	pe.mutex.Unlock()
 
-	pe.triggerEvent(PatternEvent{
-		Type:        "operation_completed",
-		OperationID: operationID,
-		Message:     fmt.Sprintf("Completed pattern operation: %s (%d files processed)", operation.Name, result.FilesProcessed),
+	event := PatternEvent{
+		OperationID:   operationID,
+		OperationName: operation.Name,
 		Data: map[string]interface{}{
 			"files_matched":   result.FilesMatched,
 			"files_processed": result.FilesProcessed,
 			"files_failed":    result.FilesFailed,
-			"duration":        result.Duration.String(),
+			"duration":        time.Since(startTime).String(),
 		},
-		Timestamp: time.Now(),
-	})
+	}
+	pe.publish(TopicOperationCompleted, fmt.Sprintf("Completed pattern operation: %s (%d files processed)", operation.Name, result.FilesProcessed), event)
+	if result.FilesFailed > 0 {
+		// Handlers may still be reading the completed event, so the failure gets its own data
+		failed := make(map[string]interface{}, len(event.Data)+1)
+		for key, value := range event.Data {
+			failed[key] = value
+		}
+		failed["errors"] = result.Errors
+		event.Data = failed
+		pe.publish(TopicOperationFailed, fmt.Sprintf("Pattern operation %s failed on %d files", operation.Name, result.FilesFailed), event)
+	}
 
 	return result, nil
 }
 
 // ExecuteAllOperations executes all enabled pattern operations
 func (pe *PatternEngine) ExecuteAllOperations(dryRun bool) ([]PatternResult, error) {
+	startTime := time.Now()
 	operations := pe.GetOperations()
 	var results []PatternResult
 
@@ -357,6 +385,23 @@ func (pe *PatternEngine) ExecuteAllOperations(dryRun bool) ([]PatternResult, err
 		}
 	}
 
+	processed, failed := 0, 0
+	for _, result := range results {
+		processed += result.FilesProcessed
+		if result.FilesFailed > 0 || len(result.Errors) > 0 {
+			failed++
+		}
+	}
+	pe.publish(TopicAllCompleted, fmt.Sprintf("Ran %d pattern operations (%d files processed)", len(results), processed), PatternEvent{
+		Data: map[string]interface{}{
+			"operations":        len(results),
+			"operations_failed": failed,
+			"files_processed":   processed,
+			"dry_run":           dryRun,
+			"duration":          time.Since(startTime).String(),
+		},
+	})
+
 	return results, nil
 }
 
@@ -368,7 +413,7 @@ func (pe *PatternEngine) collectFiles(path string, operation *PatternOperation,
 		return nil
 	}
 
-	info, err := os.Stat(path)
+	info, err := pe.fs.Stat(path)
 	if err != nil {
 		return err
 	}
@@ -378,7 +423,7 @@ func (pe *PatternEngine) collectFiles(path string, operation *PatternOperation,
 			return nil
 		}
 
-		entries, err := os.ReadDir(path)
+		entries, err := pe.fs.ReadDir(path)
 		if err != nil {
 			return err
 		}
@@ -524,7 +569,7 @@ func (pe *PatternEngine) matchFilePath(filePath string, filter FileFilter) bool
 func (pe *PatternEngine) matchContent(filePath string, filter FileFilter) bool {
 	value := fmt.Sprintf("%v", filter.Value)
 
-	file, err := os.Open(filePath)
+	file, err := pe.fs.Open(filePath)
 	if err != nil {
 		return false
 	}
@@ -554,7 +599,7 @@ func (pe *PatternEngine) matchContent(filePath string, filter FileFilter) bool {
 }
 
 func (pe *PatternEngine) matchSize(filePath string, filter FileFilter) bool {
-	info, err := os.Stat(filePath)
+	info, err := pe.fs.Stat(filePath)
 	if err != nil {
 		return false
 	}
@@ -592,7 +637,7 @@ func (pe *PatternEngine) matchSize(filePath string, filter FileFilter) bool {
 }
 
 func (pe *PatternEngine) matchAge(filePath string, filter FileFilter) bool {
-	info, err := os.Stat(filePath)
+	info, err := pe.fs.Stat(filePath)
 	if err != nil {
 		return false
 	}
@@ -621,7 +666,7 @@ func (pe *PatternEngine) matchAge(filePath string, filter FileFilter) bool {
 }
 
 func (pe *PatternEngine) matchPermissions(filePath string, filter FileFilter) bool {
-	info, err := os.Stat(filePath)
+	info, err := pe.fs.Stat(filePath)
 	if err != nil {
 		return false
 	}
@@ -696,11 +741,11 @@ func (pe *PatternEngine) parseAge(ageStr string) (time.Duration, error) {
 		}
 	}
 
-	return 0, fmt.Errorf("invalid age format: %s", ageStr)
+	return 0, fault.Invalid("invalid age format: %s", ageStr)
 }
 
-func (pe *PatternEngine) processFile(operation *PatternOperation, filePath string, dryRun bool) (FileOperationDetail, error) {
-	info, err := os.Stat(filePath)
+func (pe *PatternEngine) processFile(files vfs.FS, operation *PatternOperation, filePath string, dryRun bool) (FileOperationDetail, error) {
+	info, err := files.Stat(filePath)
 	if err != nil {
 		return FileOperationDetail{
 			FilePath: filePath,
@@ -721,52 +766,63 @@ func (pe *PatternEngine) processFile(operation *PatternOperation, filePath strin
 	for _, action := range operation.Actions {
 		switch action.Type {
 		case "move":
+			destPath := pe.buildDestinationPath(filePath, action.Destination)
+			err = pe.moveFile(files, filePath, destPath)
 			if !dryRun {
-				destPath := pe.buildDestinationPath(filePath, action.Destination)
-				err = pe.moveFile(filePath, destPath)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
-				detail.Destination = destPath
+				audit.RecordFile("pattern", "move", filePath, destPath, err)
 			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
+			}
+			detail.Destination = destPath
 			detail.Action = "move"
 			detail.Success = true
 
 		case "copy":
+			destPath := pe.buildDestinationPath(filePath, action.Destination)
+			err = vfs.CopyFile(files, filePath, destPath)
 			if !dryRun {
-				destPath := pe.buildDestinationPath(filePath, action.Destination)
-				err = pe.copyFile(filePath, destPath)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
-				detail.Destination = destPath
+				audit.RecordFile("pattern", "copy", filePath, destPath, err)
 			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
+			}
+			detail.Destination = destPath
 			detail.Action = "copy"
 			detail.Success = true
 
 		case "delete":
+			// Files go to the trash unless the action has permanent: "true"
+			deleted := "trash"
+			if action.Parameters["permanent"] == "true" {
+				deleted = "delete"
+				err = files.Remove(filePath)
+			} else {
+				err = vfs.Trash(files, filePath)
+			}
 			if !dryRun {
-				err = os.Remove(filePath)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
+				audit.RecordFile("pattern", deleted, filePath, "", err)
+			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
 			}
 			detail.Action = "delete"
 			detail.Success = true
 
 		case "rename":
+			newName := pe.buildFileName(filePath, action.Template)
+			err = pe.renameFile(files, filePath, newName)
 			if !dryRun {
-				newName := pe.buildFileName(filePath, action.Template)
-				err = pe.renameFile(filePath, newName)
-				if err != nil {
-					detail.Error = err.Error()
-					return detail, err
-				}
-				detail.Destination = newName
+				audit.RecordFile("pattern", "rename", filePath, newName, err)
 			}
+			if err != nil {
+				detail.Error = err.Error()
+				return detail, err
+			}
+			detail.Destination = newName
 			detail.Action = "rename"
 			detail.Success = true
 		}
@@ -815,39 +871,17 @@ func (pe *PatternEngine) buildFileName(filePath, template string) string {
 	return newName
 }
 
-func (pe *PatternEngine) moveFile(src, dest string) error {
+func (pe *PatternEngine) moveFile(files vfs.FS, src, dest string) error {
 	// Ensure destination directory exists
-	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+	if err := files.MkdirAll(filepath.Dir(dest), 0755); err != nil {
 		return err
 	}
-	return os.Rename(src, dest)
+	return files.Rename(src, dest)
 }
 
-func (pe *PatternEngine) copyFile(src, dest string) error {
-	// Ensure destination directory exists
-	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
-		return err
-	}
-
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dest)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, sourceFile)
-	return err
-}
-
-func (pe *PatternEngine) renameFile(src, newName string) error {
+func (pe *PatternEngine) renameFile(files vfs.FS, src, newName string) error {
 	dest := filepath.Join(filepath.Dir(src), newName)
-	return os.Rename(src, dest)
+	return files.Rename(src, dest)
 }
 
 func (pe *PatternEngine) updateSummary(summary *PatternSummary, detail FileOperationDetail) {
@@ -881,18 +915,18 @@ func (pe *PatternEngine) updateSummary(summary *PatternSummary, detail FileOpera
 }
 
 func (pe *PatternEngine) loadOperations() error {
-	if _, err := os.Stat(pe.configFile); os.IsNotExist(err) {
+	if _, err := pe.fs.Stat(pe.configFile); os.IsNotExist(err) {
 		return nil // No config file exists yet
 	}
 
-	data, err := os.ReadFile(pe.configFile)
+	data, err := pe.fs.ReadFile(pe.configFile)
 	if err != nil {
-		return fmt.Errorf("error reading config file: %v", err)
+		return fmt.Errorf("error reading config file: %w", err)
 	}
 
 	var operations []*PatternOperation
 	if err := json.Unmarshal(data, &operations); err != nil {
-		return fmt.Errorf("error parsing config file: %v", err)
+		return fmt.Errorf("error parsing config file: %w", err)
 	}
 
 	for _, operation := range operations {
@@ -910,11 +944,11 @@ func (pe *PatternEngine) saveOperations() error {
 
 	data, err := json.MarshalIndent(operations, "", "  ")
 	if err != nil {
-		return fmt.Errorf("error marshaling operations: %v", err)
+		return fmt.Errorf("error marshaling operations: %w", err)
 	}
 
-	if err := os.WriteFile(pe.configFile, data, 0644); err != nil {
-		return fmt.Errorf("error writing config file: %v", err)
+	if err := pe.fs.WriteFile(pe.configFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
 	}
 
 	return nil
@@ -923,33 +957,29 @@ func (pe *PatternEngine) saveOperations() error {
 func (pe *PatternEngine) saveResult(result *PatternResult) error {
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return fmt.Errorf("error marshaling result: %v", err)
+		return fmt.Errorf("error marshaling result: %w", err)
+	}
+
+	if err := pe.fs.MkdirAll(pe.resultsDir, 0700); err != nil {
+		return fmt.Errorf("error creating results directory: %w", err)
 	}
 
-	resultFile := fmt.Sprintf("pattern_result_%s_%d.json", result.OperationID, time.Now().Unix())
-	if err := os.WriteFile(resultFile, data, 0644); err != nil {
-		return fmt.Errorf("error writing result file: %v", err)
+	resultFile := filepath.Join(pe.resultsDir, fmt.Sprintf("pattern_result_%s_%d.json", result.OperationID, time.Now().Unix()))
+	if err := pe.fs.WriteFile(resultFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing result file: %w", err)
 	}
 
 	return nil
 }
 
-func (pe *PatternEngine) triggerEvent(event PatternEvent) {
-	pe.mutex.RLock()
-	callbacks := pe.eventCallbacks[event.Type]
-	pe.mutex.RUnlock()
-
-	for _, callback := range callbacks {
-		go callback(event) // Run callbacks asynchronously
-	}
+// SetEventBus sets the bus pattern events are published on
+func (pe *PatternEngine) SetEventBus(bus *events.Bus) {
+	pe.events = bus
 }
 
-// AddEventCallback adds a callback for pattern events
-func (pe *PatternEngine) AddEventCallback(eventType string, callback PatternEventCallback) {
-	pe.mutex.Lock()
-	defer pe.mutex.Unlock()
-
-	pe.eventCallbacks[eventType] = append(pe.eventCallbacks[eventType], callback)
+// publish announces a pattern event on the event bus
+func (pe *PatternEngine) publish(topic events.Topic, message string, event PatternEvent) {
+	pe.events.Publish(events.New(topic, message, event))
 }
 
 // GetOperationByID returns a specific operation by ID
@@ -959,7 +989,7 @@ func (pe *PatternEngine) GetOperationByID(operationID string) (*PatternOperation
 
 	operation, exists := pe.operations[operationID]
 	if !exists {
-		return nil, fmt.Errorf("operation %s not found", operationID)
+		return nil, fault.NotFound("operation %s not found", operationID)
 	}
 
 	return operation, nil
@@ -0,0 +1,274 @@
+/**
+ * External Plugins
+ *
+ * Discovers commands shipped outside Ena. A plugin is either an executable
+ * named ena-<name> on PATH (like git subcommands) or a directory in
+ * $XDG_DATA_HOME/ena/plugins holding a plugin.json manifest. Plugins receive
+ * their arguments on the command line and a JSON context on stdin.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: plugin.go
+ * Description: Plugin discovery, manifests and execution
+ */
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ena/internal/paths"
+)
+
+// Prefix is the name prefix of plugin executables on PATH
+const Prefix = "ena-"
+
+// ManifestFile is the manifest name inside a plugin directory
+const ManifestFile = "plugin.json"
+
+// ContextVersion is bumped whenever Context changes incompatibly
+const ContextVersion = 1
+
+// Source says where a plugin was found
+type Source string
+
+const (
+	SourceDir  Source = "dir"  // manifest in the plugin directory
+	SourcePath Source = "path" // ena-<name> executable on PATH
+)
+
+// Manifest describes a plugin installed in the plugin directory
+type Manifest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Usage       string   `json:"usage"`      // e.g. "deploy <env>"; defaults to the name
+	Executable  string   `json:"executable"` // relative to the plugin directory
+	Aliases     []string `json:"aliases,omitempty"`
+	Args        []string `json:"args,omitempty"` // first-argument values offered by completion
+}
+
+// Plugin is a discovered external command
+type Plugin struct {
+	Manifest
+	Path   string `json:"path"`
+	Source Source `json:"source"`
+}
+
+// Context is written to a plugin's stdin as a single JSON document
+type Context struct {
+	Version    int      `json:"version"`
+	Plugin     string   `json:"plugin"`
+	Args       []string `json:"args"`
+	Cwd        string   `json:"cwd"`
+	Theme      string   `json:"theme"`
+	Color      bool     `json:"color"`
+	Output     string   `json:"output"` // text, json, yaml or table
+	Ena        string   `json:"ena"`    // path of the ena executable, for calling back
+	EnaVersion string   `json:"ena_version"`
+	Socket     string   `json:"socket"` // daemon socket, which may not be listening
+	ConfigDir  string   `json:"config_dir"`
+	DataDir    string   `json:"data_dir"`
+	StateDir   string   `json:"state_dir"`
+}
+
+// Dir returns the directory holding manifest plugins
+func Dir() string {
+	return filepath.Join(paths.DataDir(), "plugins")
+}
+
+// Discover finds every plugin, sorted by name. Manifest plugins win over PATH
+// executables of the same name, and earlier PATH entries win over later ones.
+func Discover() ([]*Plugin, []error) {
+	found := make(map[string]*Plugin)
+	var errs []error
+
+	manifests, err := discoverDir(Dir())
+	if err != nil {
+		errs = append(errs, err...)
+	}
+	for _, p := range manifests {
+		found[p.Name] = p
+	}
+
+	for _, p := range discoverPath(os.Getenv("PATH")) {
+		if _, exists := found[p.Name]; !exists {
+			found[p.Name] = p
+		}
+	}
+
+	plugins := make([]*Plugin, 0, len(found))
+	for _, p := range found {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, errs
+}
+
+// discoverDir loads the manifest of every subdirectory of dir
+func discoverDir(dir string) ([]*Plugin, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{fmt.Errorf("Failed to read plugin directory: %w", err)}
+	}
+
+	var plugins []*Plugin
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		p, err := LoadManifest(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, errs
+}
+
+// LoadManifest reads and validates the plugin in a directory
+func LoadManifest(dir string) (*Plugin, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read plugin manifest in %s: %w", dir, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("Failed to parse plugin manifest in %s: %w", dir, err)
+	}
+
+	if manifest.Name == "" {
+		manifest.Name = filepath.Base(dir)
+	}
+	if !ValidName(manifest.Name) {
+		return nil, fmt.Errorf("plugin in %s has invalid name %q", dir, manifest.Name)
+	}
+	if manifest.Executable == "" {
+		return nil, fmt.Errorf("plugin %s does not name an executable", manifest.Name)
+	}
+
+	path := manifest.Executable
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if !isExecutable(path) {
+		return nil, fmt.Errorf("plugin %s: %s is not an executable file", manifest.Name, path)
+	}
+
+	return &Plugin{Manifest: manifest, Path: path, Source: SourceDir}, nil
+}
+
+// discoverPath finds ena-<name> executables in a PATH list
+func discoverPath(pathList string) []*Plugin {
+	seen := make(map[string]bool)
+	var plugins []*Plugin
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), Prefix)
+			if !ok || seen[name] || !ValidName(name) {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			seen[name] = true
+			plugins = append(plugins, &Plugin{
+				Manifest: Manifest{Name: name},
+				Path:     path,
+				Source:   SourcePath,
+			})
+		}
+	}
+	return plugins
+}
+
+// ValidName reports whether a plugin name can be used as a command name
+func ValidName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// isExecutable reports whether path is a regular file with an execute bit
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// UsageLine returns the command usage shown in help, always starting with the name
+func (p *Plugin) UsageLine() string {
+	if p.Usage == "" {
+		return p.Name + " [args...]"
+	}
+	_, rest, _ := strings.Cut(strings.TrimSpace(p.Usage), " ")
+	return strings.TrimSpace(p.Name + " " + rest)
+}
+
+// Summary returns the one-line description shown in help
+func (p *Plugin) Summary() string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return fmt.Sprintf("Run %s%s", Prefix, p.Name)
+}
+
+// Run executes the plugin in ctx.Cwd, writing ctx to its stdin and its output
+// to stdout, and returns its exit code
+func (p *Plugin) Run(args []string, ctx Context, stdout io.Writer) (int, error) {
+	ctx.Version = ContextVersion
+	ctx.Plugin = p.Name
+	ctx.Args = args
+
+	payload, err := json.Marshal(ctx)
+	if err != nil {
+		return 1, fmt.Errorf("Failed to encode plugin context: %w", err)
+	}
+
+	cmd := exec.Command(p.Path, args...)
+	cmd.Dir = ctx.Cwd
+	cmd.Stdin = bytes.NewReader(append(payload, '\n'))
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "ENA_PLUGIN="+p.Name)
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, fmt.Errorf("Failed to run plugin %s: %w", p.Name, err)
+	}
+	return 0, nil
+}
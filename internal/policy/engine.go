@@ -0,0 +1,291 @@
+/**
+ * Policy Engine
+ *
+ * Evaluates operations against the policy, asks for confirmation where a rule
+ * wants it and records every refusal and override in the audit log. Facts
+ * that need the filesystem, such as what a directory holds or how large a
+ * tree is, are only gathered when a rule asks about them.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: engine.go
+ * Description: Rule matching, confirmation and override handling
+ */
+
+package policy
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"ena/internal/audit"
+	"ena/internal/fault"
+	"ena/internal/vfs"
+)
+
+// ErrBlocked is wrapped by every error the engine returns for a refused operation
+var ErrBlocked = fault.Denied("blocked by policy")
+
+// Request describes one operation about to be made
+type Request struct {
+	Source    string `json:"source,omitempty"` // component making it, e.g. batch
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Target    string `json:"target,omitempty"` // destination of a move
+}
+
+// Decision is the outcome of evaluating a request
+type Decision struct {
+	Request
+	Effect Effect `json:"effect"`
+	Rule   string `json:"rule,omitempty"` // the rule that matched; empty when none did
+}
+
+// Error is a refused operation
+type Error struct {
+	Decision Decision
+}
+
+func (e *Error) Error() string {
+	if e.Decision.Effect == EffectConfirm {
+		return fmt.Sprintf("Policy rule %q needs confirmation to %s %s (use --override-policy to skip it)",
+			e.Decision.Rule, e.Decision.Operation, e.Decision.Path)
+	}
+	return fmt.Sprintf("Policy rule %q forbids %s of %s (use --override-policy to allow it)",
+		e.Decision.Rule, e.Decision.Operation, e.Decision.Path)
+}
+
+func (e *Error) Unwrap() error {
+	return ErrBlocked
+}
+
+// Engine enforces a policy
+type Engine struct {
+	Confirm func(Decision) bool // asks whether a confirm rule may pass; nil refuses
+
+	policy   *Policy
+	fs       vfs.FS
+	override bool
+	mutex    sync.RWMutex
+	askMutex sync.Mutex // one question at a time, even from concurrent batch workers
+}
+
+// NewEngine creates an engine that inspects paths through fs
+func NewEngine(policy *Policy, fs vfs.FS) *Engine {
+	return &Engine{policy: policy, fs: fs}
+}
+
+// Policy returns the rules being enforced
+func (e *Engine) Policy() *Policy {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.policy
+}
+
+// SetPolicy replaces the rules being enforced
+func (e *Engine) SetPolicy(policy *Policy) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.policy = policy
+}
+
+// SetOverride lets every operation through until it is turned off again
+func (e *Engine) SetOverride(override bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.override = override
+}
+
+// Overridden reports whether the rules are currently being ignored
+func (e *Engine) Overridden() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.override
+}
+
+// Evaluate finds the first rule matching request, without acting on it
+func (e *Engine) Evaluate(request Request) Decision {
+	if abs, err := filepath.Abs(request.Path); err == nil {
+		request.Path = abs
+	}
+	if abs, err := filepath.Abs(request.Target); err == nil && request.Target != "" {
+		request.Target = abs
+	}
+
+	subject := &subject{fs: e.fs, path: request.Path}
+	for _, rule := range e.Policy().Rules {
+		if e.matches(rule, request, subject) {
+			return Decision{Request: request, Effect: rule.Effect, Rule: rule.Name}
+		}
+	}
+	return Decision{Request: request, Effect: EffectAllow}
+}
+
+// Check decides whether request may go ahead, asking the user when a rule
+// wants confirmation. Refusals are returned as *Error.
+func (e *Engine) Check(request Request) error {
+	decision := e.Evaluate(request)
+	if decision.Effect == EffectAllow {
+		return nil
+	}
+
+	if e.Overridden() {
+		audit.RecordFile("policy", "override-"+decision.Operation, decision.Path, decision.Target, nil)
+		return nil
+	}
+
+	if decision.Effect == EffectConfirm && e.ask(decision) {
+		audit.RecordFile("policy", "confirm-"+decision.Operation, decision.Path, decision.Target, nil)
+		return nil
+	}
+
+	err := &Error{Decision: decision}
+	audit.RecordFile("policy", "deny-"+decision.Operation, decision.Path, decision.Target, err)
+	return err
+}
+
+// Preview decides whether request would go ahead, for a dry run: nothing is
+// asked or audited, and a confirm rule passes only when Check could ask
+func (e *Engine) Preview(request Request) error {
+	decision := e.Evaluate(request)
+	if decision.Effect == EffectAllow || e.Overridden() {
+		return nil
+	}
+	if decision.Effect == EffectConfirm && e.Confirm != nil {
+		return nil
+	}
+	return &Error{Decision: decision}
+}
+
+// ask puts a confirm decision to the user
+func (e *Engine) ask(decision Decision) bool {
+	if e.Confirm == nil {
+		return false
+	}
+
+	e.askMutex.Lock()
+	defer e.askMutex.Unlock()
+	return e.Confirm(decision)
+}
+
+// matches reports whether every condition of rule holds for request
+func (e *Engine) matches(rule Rule, request Request, subject *subject) bool {
+	if len(rule.Operations) > 0 && !contains(rule.Operations, request.Operation) {
+		return false
+	}
+	if len(rule.Sources) > 0 && !contains(rule.Sources, request.Source) {
+		return false
+	}
+
+	if len(rule.Paths) > 0 {
+		// Deleting or moving a directory also takes whatever the glob names inside it
+		wholeTree := request.Operation != OpOverwrite && subject.isDir()
+		matched := false
+		for _, pattern := range rule.Paths {
+			if covers(expandHome(pattern), request.Path, wholeTree) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, name := range rule.Contains {
+		if !subject.holds(name) {
+			return false
+		}
+	}
+
+	if rule.MinSize > 0 || rule.MinFiles > 0 {
+		size, files := subject.measure()
+		if size < rule.MinSize || files < rule.MinFiles {
+			return false
+		}
+	}
+	return true
+}
+
+// covers reports whether pattern names path or a directory above it. With
+// wholeTree set it also reports paths that hold something pattern could name.
+func covers(pattern, path string, wholeTree bool) bool {
+	patternParts := splitPath(pattern)
+	pathParts := splitPath(path)
+	if len(pathParts) < len(patternParts) && !wholeTree {
+		return false
+	}
+
+	for i := 0; i < len(patternParts) && i < len(pathParts); i++ {
+		if matched, _ := filepath.Match(patternParts[i], pathParts[i]); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath breaks a clean absolute path into its names
+func splitPath(path string) []string {
+	path = strings.Trim(filepath.Clean(path), string(filepath.Separator))
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, string(filepath.Separator))
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// subject gathers facts about the path of one request, each at most once
+type subject struct {
+	fs       vfs.FS
+	path     string
+	info     fs.FileInfo
+	statDone bool
+	size     int64
+	files    int
+	measured bool
+}
+
+// isDir reports whether the path is a directory, without following symlinks
+func (s *subject) isDir() bool {
+	if !s.statDone {
+		s.info, _ = s.fs.Lstat(s.path)
+		s.statDone = true
+	}
+	return s.info != nil && s.info.IsDir()
+}
+
+// holds reports whether the path is a directory with an entry called name
+func (s *subject) holds(name string) bool {
+	if !s.isDir() {
+		return false
+	}
+	_, err := s.fs.Lstat(filepath.Join(s.path, name))
+	return err == nil
+}
+
+// measure totals the bytes and files in the tree at the path
+func (s *subject) measure() (int64, int) {
+	if !s.measured {
+		vfs.Walk(s.fs, s.path, func(path string, info fs.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				s.size += info.Size()
+				s.files++
+			}
+			return nil
+		})
+		s.measured = true
+	}
+	return s.size, s.files
+}
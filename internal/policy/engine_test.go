@@ -0,0 +1,184 @@
+package policy
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ena/internal/fault"
+	"ena/internal/vfs"
+)
+
+// newEngine builds an engine over a memory tree holding the given files, with
+// the home directory at /home/u
+func newEngine(t *testing.T, policy *Policy, files ...string) *Engine {
+	t.Helper()
+	t.Setenv("HOME", "/home/u")
+
+	fsys := vfs.NewMemory()
+	for _, name := range files {
+		if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewEngine(policy, fsys)
+}
+
+func TestEvaluateDefaultRules(t *testing.T) {
+	engine := newEngine(t, Default(),
+		"/home/u/.ssh/id_ed25519",
+		"/home/u/.gnupg/pubring.kbx",
+		"/home/u/code/repo/.git/HEAD",
+		"/home/u/code/repo/main.go",
+		"/home/u/code/plain/main.go",
+		"/home/u/notes.txt",
+	)
+
+	tests := []struct {
+		name      string
+		operation string
+		path      string
+		target    string
+		want      string // rule that decides; empty when the operation is allowed
+	}{
+		{"delete a key", OpDelete, "/home/u/.ssh/id_ed25519", "", "keys"},
+		{"move the key directory", OpMove, "/home/u/.ssh", "/tmp/ssh", "keys"},
+		{"overwrite a keyring", OpOverwrite, "/home/u/.gnupg/pubring.kbx", "", "keys"},
+		{"delete a tree holding keys", OpDelete, "/home/u", "", "keys"},
+		{"delete a repository root", OpDelete, "/home/u/code/repo", "", "git-repositories"},
+		{"move a repository root", OpMove, "/home/u/code/repo", "/home/u/old/repo", ""},
+		{"delete a file in a repository", OpDelete, "/home/u/code/repo/main.go", "", ""},
+		{"delete a directory without .git", OpDelete, "/home/u/code/plain", "", ""},
+		{"delete an ordinary file", OpDelete, "/home/u/notes.txt", "", ""},
+		{"overwrite beside the keys", OpOverwrite, "/home/u/.sshrc", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision := engine.Evaluate(Request{Operation: test.operation, Path: test.path, Target: test.target})
+			if decision.Rule != test.want {
+				t.Errorf("%s %s decided by %q, want %q", test.operation, test.path, decision.Rule, test.want)
+			}
+			if test.want != "" && decision.Effect == EffectAllow {
+				t.Errorf("%s %s allowed by rule %q", test.operation, test.path, decision.Rule)
+			}
+		})
+	}
+}
+
+func TestEvaluateRuleConditions(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Name: "scratch", Effect: EffectAllow, Paths: []string{"/srv/*/scratch"}},
+		{Name: "data", Effect: EffectDeny, Paths: []string{"/srv/*/data"}, Operations: []string{OpDelete}},
+		{Name: "batch-only", Effect: EffectConfirm, Paths: []string{"/batch"}, Sources: []string{"batch"}},
+		{Name: "big-trees", Effect: EffectConfirm, Paths: []string{"/big"}, MinFiles: 3},
+		{Name: "large-files", Effect: EffectConfirm, Paths: []string{"/large"}, MinSize: 2},
+	}}
+	engine := newEngine(t, policy,
+		"/srv/app/data/db",
+		"/srv/app/scratch/data/tmp",
+		"/big/a", "/big/b", "/big/c",
+		"/big/small/a",
+		"/large/a", "/large/b",
+	)
+
+	tests := []struct {
+		name    string
+		request Request
+		want    string
+	}{
+		{"glob match", Request{Operation: OpDelete, Path: "/srv/app/data/db"}, "data"},
+		{"glob covers the tree beneath", Request{Operation: OpDelete, Path: "/srv/app/data"}, "data"},
+		{"operation not listed", Request{Operation: OpMove, Path: "/srv/app/data/db"}, ""},
+		{"directory holding a match", Request{Operation: OpDelete, Path: "/srv/app"}, "scratch"},
+		{"first match wins", Request{Operation: OpDelete, Path: "/srv/app/scratch/data/tmp"}, "scratch"},
+		{"source listed", Request{Source: "batch", Operation: OpDelete, Path: "/batch"}, "batch-only"},
+		{"source not listed", Request{Source: "file", Operation: OpDelete, Path: "/batch"}, ""},
+		{"enough files", Request{Operation: OpDelete, Path: "/big"}, "big-trees"},
+		{"too few files", Request{Operation: OpDelete, Path: "/big/small"}, ""},
+		{"enough bytes", Request{Operation: OpDelete, Path: "/large"}, "large-files"},
+		{"too few bytes", Request{Operation: OpDelete, Path: "/large/a"}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := engine.Evaluate(test.request).Rule; got != test.want {
+				t.Errorf("Evaluate(%+v) decided by %q, want %q", test.request, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckAndPreview(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	policy := &Policy{Rules: []Rule{
+		{Name: "ask", Effect: EffectConfirm, Paths: []string{"/ask"}},
+		{Name: "never", Effect: EffectDeny, Paths: []string{"/never"}},
+	}}
+
+	tests := []struct {
+		name     string
+		path     string
+		confirm  func(Decision) bool
+		override bool
+		check    bool // whether Check lets it through
+		preview  bool // whether Preview lets it through
+	}{
+		{"no rule", "/free", nil, false, true, true},
+		{"deny", "/never", nil, false, false, false},
+		{"confirm with nobody to ask", "/ask", nil, false, false, false},
+		{"confirm answered yes", "/ask", func(Decision) bool { return true }, false, true, true},
+		{"confirm answered no", "/ask", func(Decision) bool { return false }, false, false, true},
+		{"deny overridden", "/never", nil, true, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := newEngine(t, policy)
+			engine.Confirm = test.confirm
+			engine.SetOverride(test.override)
+			request := Request{Operation: OpDelete, Path: test.path}
+
+			for _, call := range []struct {
+				name string
+				err  error
+				want bool
+			}{
+				{"Check", engine.Check(request), test.check},
+				{"Preview", engine.Preview(request), test.preview},
+			} {
+				if call.want && call.err != nil {
+					t.Errorf("%s refused: %v", call.name, call.err)
+				}
+				if !call.want && (!errors.Is(call.err, ErrBlocked) || fault.Code(call.err) != fault.CodeDenied) {
+					t.Errorf("%s = %v, want a policy refusal", call.name, call.err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"unknown effect", Rule{Name: "x", Effect: "maybe"}},
+		{"unknown operation", Rule{Name: "x", Effect: EffectDeny, Operations: []string{"chmod"}}},
+		{"relative path", Rule{Name: "x", Effect: EffectDeny, Paths: []string{"docs"}}},
+		{"bad glob", Rule{Name: "x", Effect: EffectDeny, Paths: []string{"/srv/[a"}}},
+		{"negative size", Rule{Name: "x", Effect: EffectDeny, MinSize: -1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := &Policy{Rules: []Rule{test.rule}}
+			if err := policy.Validate(); !errors.Is(err, fault.ErrInvalid) {
+				t.Errorf("Validate() = %v, want fault.ErrInvalid", err)
+			}
+		})
+	}
+
+	if err := Default().Validate(); err != nil {
+		t.Errorf("built-in rules are invalid: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+/**
+ * Policy Guard
+ *
+ * A filesystem wrapper that puts every delete, move and overwrite made through
+ * it to the policy engine first, so a manager given a guarded filesystem is
+ * policed without checking anything itself. Ena's own config, data and state
+ * directories are left alone, since managers rewrite their files there all
+ * the time.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: guard.go
+ * Description: Filesystem wrapper enforcing the policy on mutations
+ */
+
+package policy
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"ena/internal/paths"
+	"ena/internal/vfs"
+)
+
+// guardFS checks mutations with an engine before passing them on; reads,
+// Chmod and Chtimes go straight through
+type guardFS struct {
+	vfs.FS
+	engine *Engine
+	source string
+}
+
+// trashGuardFS is a guardFS over a filesystem that keeps a trash; moving a
+// file to the trash is checked as deleting it
+type trashGuardFS struct {
+	*guardFS
+}
+
+// Guard returns fsys with every delete, move and overwrite checked by engine on
+// behalf of source. A nil engine guards nothing.
+func Guard(fsys vfs.FS, engine *Engine, source string) vfs.FS {
+	if engine == nil {
+		return fsys
+	}
+	guard := &guardFS{FS: fsys, engine: engine, source: source}
+	if _, ok := fsys.(vfs.Trasher); ok {
+		return &trashGuardFS{guard}
+	}
+	return guard
+}
+
+func (g *guardFS) Create(name string) (vfs.File, error) {
+	if err := g.checkOverwrite(name); err != nil {
+		return nil, err
+	}
+	return g.FS.Create(name)
+}
+
+func (g *guardFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := g.checkOverwrite(name); err != nil {
+		return err
+	}
+	return g.FS.WriteFile(name, data, perm)
+}
+
+func (g *guardFS) Remove(name string) error {
+	if err := g.check(OpDelete, name, ""); err != nil {
+		return err
+	}
+	return g.FS.Remove(name)
+}
+
+func (g *guardFS) RemoveAll(path string) error {
+	if err := g.check(OpDelete, path, ""); err != nil {
+		return err
+	}
+	return g.FS.RemoveAll(path)
+}
+
+func (g *trashGuardFS) Trash(path string) error {
+	if err := g.check(OpDelete, path, ""); err != nil {
+		return err
+	}
+	return vfs.Trash(g.FS, path)
+}
+
+func (g *guardFS) Rename(oldpath, newpath string) error {
+	if err := g.check(OpMove, oldpath, newpath); err != nil {
+		return err
+	}
+	if err := g.checkOverwrite(newpath); err != nil {
+		return err
+	}
+	return g.FS.Rename(oldpath, newpath)
+}
+
+func (g *guardFS) Link(oldname, newname string) error {
+	if err := g.checkOverwrite(newname); err != nil {
+		return err
+	}
+	return vfs.Link(g.FS, oldname, newname)
+}
+
+func (g *guardFS) Reflink(src, dst string) error {
+	if err := g.checkOverwrite(dst); err != nil {
+		return err
+	}
+	return vfs.Reflink(g.FS, src, dst)
+}
+
+// Vet previews a change an overlay is about to record, so a dry run is
+// refused where the real run would be, without asking or auditing anything
+func (g *guardFS) Vet(change vfs.Change) error {
+	switch change.Op {
+	case "write":
+		if g.overwrites(change.Path) {
+			return g.preview(OpOverwrite, change.Path, "")
+		}
+	case "remove", "trash":
+		return g.preview(OpDelete, change.Path, "")
+	case "rename":
+		if err := g.preview(OpMove, change.Path, change.Target); err != nil {
+			return err
+		}
+		if g.overwrites(change.Target) {
+			return g.preview(OpOverwrite, change.Target, "")
+		}
+	}
+	return nil
+}
+
+// checkOverwrite checks writing to name when a file is already there
+func (g *guardFS) checkOverwrite(name string) error {
+	if !g.overwrites(name) {
+		return nil
+	}
+	return g.check(OpOverwrite, name, "")
+}
+
+// overwrites reports whether writing to name replaces a file
+func (g *guardFS) overwrites(name string) bool {
+	info, err := g.FS.Stat(name)
+	return err == nil && !info.IsDir()
+}
+
+// check asks the engine about one operation unless it only touches Ena's own files
+func (g *guardFS) check(operation, path, target string) error {
+	if isOwnFile(path) && (target == "" || isOwnFile(target)) {
+		return nil
+	}
+	return g.engine.Check(Request{Source: g.source, Operation: operation, Path: path, Target: target})
+}
+
+// preview is check for a dry run
+func (g *guardFS) preview(operation, path, target string) error {
+	if isOwnFile(path) && (target == "" || isOwnFile(target)) {
+		return nil
+	}
+	return g.engine.Preview(Request{Source: g.source, Operation: operation, Path: path, Target: target})
+}
+
+// isOwnFile reports whether path lies in one of Ena's directories
+func isOwnFile(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	for _, dir := range []string{paths.ConfigDir(), paths.DataDir(), paths.StateDir()} {
+		if strings.HasPrefix(abs, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
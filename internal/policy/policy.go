@@ -0,0 +1,240 @@
+/**
+ * Safety Policy
+ *
+ * Loads the declarative rules in $XDG_CONFIG_HOME/ena/policy.yaml that decide
+ * whether a delete, move or overwrite may go ahead. Each rule matches on path
+ * globs, operation types, the component asking, what a directory holds, and
+ * how large the affected tree is; the first matching rule wins. Without a
+ * policy file the built-in rules protect SSH and GnuPG keys and git
+ * repositories.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: policy.go
+ * Description: Policy file loading, validation and rendering
+ */
+
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"ena/internal/fault"
+	"ena/internal/output"
+	"ena/internal/paths"
+)
+
+// FileName is the name of the policy file inside the config directory
+const FileName = "policy.yaml"
+
+// Effect is what a matching rule does with an operation
+type Effect string
+
+const (
+	EffectAllow   Effect = "allow"
+	EffectConfirm Effect = "confirm"
+	EffectDeny    Effect = "deny"
+)
+
+// Operations the policy is consulted about
+const (
+	OpDelete    = "delete"
+	OpMove      = "move"
+	OpOverwrite = "overwrite"
+)
+
+// Rule matches a set of operations and decides their effect. Empty lists and
+// zero limits match everything.
+type Rule struct {
+	Name       string   `json:"name" yaml:"name"`
+	Effect     Effect   `json:"effect" yaml:"effect"`
+	Paths      []string `json:"paths,omitempty" yaml:"paths,omitempty"`           // globs; a match covers everything beneath it
+	Operations []string `json:"operations,omitempty" yaml:"operations,omitempty"` // delete, move or overwrite
+	Sources    []string `json:"sources,omitempty" yaml:"sources,omitempty"`       // file, batch, pattern, organizer or undo
+	Contains   []string `json:"contains,omitempty" yaml:"contains,omitempty"`     // entries a directory must hold, e.g. .git
+	MinSize    int64    `json:"min_size,omitempty" yaml:"min_size,omitempty"`     // bytes in the affected tree
+	MinFiles   int      `json:"min_files,omitempty" yaml:"min_files,omitempty"`   // files in the affected tree
+}
+
+// Policy is an ordered list of rules; operations no rule matches are allowed
+type Policy struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+var (
+	current     *Policy
+	currentOnce sync.Once
+)
+
+// Path returns the location of policy.yaml
+func Path() string {
+	return paths.ConfigFile(FileName)
+}
+
+// Default returns the rules used when there is no policy file
+func Default() *Policy {
+	return &Policy{
+		Rules: []Rule{
+			{
+				Name:       "keys",
+				Effect:     EffectDeny,
+				Paths:      []string{"~/.ssh", "~/.gnupg"},
+				Operations: []string{OpDelete, OpMove, OpOverwrite},
+			},
+			{
+				Name:       "git-repositories",
+				Effect:     EffectDeny,
+				Operations: []string{OpDelete},
+				Contains:   []string{".git"},
+			},
+		},
+	}
+}
+
+// Get returns the policy loaded from policy.yaml, reading the file once per
+// process. A broken file is reported on stderr and the built-in rules are used.
+func Get() *Policy {
+	currentOnce.Do(func() {
+		policy, err := Load(Path())
+		if err != nil {
+			output.Fprintf(os.Stderr, "⚠️ Ignoring %s: %v\n", Path(), err)
+			policy = Default()
+		}
+		current = policy
+	})
+	return current
+}
+
+// Load reads a policy file; a missing file gives the built-in rules
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read policy: %w", err)
+	}
+
+	policy := &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("Failed to parse policy: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate rejects rules that could never be applied as written
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		switch rule.Effect {
+		case EffectAllow, EffectConfirm, EffectDeny:
+		default:
+			return fault.Invalid("Invalid policy: rule %s: effect must be allow, confirm or deny", name)
+		}
+
+		for _, operation := range rule.Operations {
+			if operation != OpDelete && operation != OpMove && operation != OpOverwrite {
+				return fault.Invalid("Invalid policy: rule %s: unknown operation %q", name, operation)
+			}
+		}
+
+		for _, pattern := range rule.Paths {
+			if !filepath.IsAbs(expandHome(pattern)) {
+				return fault.Invalid("Invalid policy: rule %s: path %q must be absolute or start with ~", name, pattern)
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fault.Invalid("Invalid policy: rule %s: bad glob %q", name, pattern)
+			}
+		}
+
+		if rule.MinSize < 0 || rule.MinFiles < 0 {
+			return fault.Invalid("Invalid policy: rule %s: min_size and min_files must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// Save writes a policy file, creating its directory
+func Save(policy *Policy, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("Failed to create config directory: %w", err)
+	}
+
+	text, err := Render(policy)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return fmt.Errorf("Failed to write policy: %w", err)
+	}
+	return nil
+}
+
+// Render formats a policy as a commented policy.yaml
+func Render(policy *Policy) (string, error) {
+	data, err := yaml.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("Failed to render policy: %w", err)
+	}
+
+	return `# Ena safety policy
+#
+# Every delete, move and overwrite made by file, folder, batch, pattern,
+# organizer and undo commands is checked against these rules. The first rule
+# that matches decides:
+#   allow    go ahead
+#   confirm  ask first; refused when nobody can answer
+#   deny     refuse
+# Operations no rule matches are allowed. --override-policy lets one command
+# ignore every rule, and each override is written to the audit log.
+#
+# A rule matches when all of its conditions do:
+#   paths       globs such as ~/.ssh or /srv/*/data; a path matches everything
+#               beneath it, and deleting or moving a directory that holds a
+#               match counts too
+#   operations  delete, move, overwrite
+#   sources     file, batch, pattern, organizer, undo
+#   contains    names a directory must hold, e.g. .git for repository roots
+#   min_size    bytes in the file or directory tree
+#   min_files   files in the tree
+# For example, to ask before a batch job deletes a tree of 1000 files or more:
+#   - name: big-trees
+#     effect: confirm
+#     operations: [delete]
+#     sources: [batch]
+#     min_files: 1000
+
+` + string(data), nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
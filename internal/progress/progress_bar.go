@@ -26,6 +26,9 @@ import (
 
 	"github.com/fatih/color"
 	"golang.org/x/term"
+
+	"ena/internal/events"
+	"ena/internal/output"
 )
 
 // ProgressBarTheme defines visual styling for progress bars
@@ -142,6 +145,7 @@ type ProgressBar struct {
 	customLabel   string
 	refreshRate   time.Duration
 	lastDisplay   time.Time
+	plainShown    bool // the one plain-mode line has been printed
 	colorEnabled  bool
 	multiBarIndex int
 	errorMessage  string
@@ -544,7 +548,7 @@ func (pb *ProgressBar) SaveState() error {
 	// Serialize to JSON with proper indentation
 	jsonData, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal state to JSON: %v", err)
+		return fmt.Errorf("failed to marshal state to JSON: %w", err)
 	}
 
 	return os.WriteFile(pb.stateFile, jsonData, 0644)
@@ -605,11 +609,11 @@ func (pb *ProgressBar) loadState() error {
 		switch key {
 		case "current":
 			if val, err := fmt.Sscanf(value, "%d", &pb.current); err != nil || val != 1 {
-				return fmt.Errorf("failed to parse current value: %v", err)
+				return fmt.Errorf("failed to parse current value: %w", err)
 			}
 		case "total":
 			if val, err := fmt.Sscanf(value, "%d", &pb.total); err != nil || val != 1 {
-				return fmt.Errorf("failed to parse total value: %v", err)
+				return fmt.Errorf("failed to parse total value: %w", err)
 			}
 		case "done":
 			pb.done = (value == "true")
@@ -880,14 +884,25 @@ func (pb *ProgressBar) Display() {
 	if !shouldUpdate && !done && !errorOccurred && !paused {
 		return
 	}
+
+	// Plain output gets one line when the work ends instead of a redrawn bar
+	if output.PlainMode() {
+		pb.mutex.Lock()
+		show := (done || errorOccurred) && !pb.plainShown
+		pb.plainShown = pb.plainShown || show
+		pb.mutex.Unlock()
+		if !show {
+			return
+		}
+	}
 	pb.lastDisplay = time.Now()
 
 	// Handle error display
 	if errorOccurred {
 		if pb.colorEnabled && pb.terminalCaps.SupportsColor {
-			color.New(pb.theme.ErrorColor, color.Bold).Printf("\r\033[K❌ Error: %s", errorMessage)
+			color.New(pb.theme.ErrorColor, color.Bold).Printf(output.Prose("\r\033[K❌ Error: %s"), errorMessage)
 		} else {
-			fmt.Printf("\r\033[K❌ Error: %s", errorMessage)
+			output.Printf("\r\033[K❌ Error: %s", errorMessage)
 		}
 		return
 	}
@@ -895,9 +910,9 @@ func (pb *ProgressBar) Display() {
 	// Handle paused display
 	if paused {
 		if pb.colorEnabled && pb.terminalCaps.SupportsColor {
-			color.New(pb.theme.PauseColor, color.Bold).Printf("\r\033[K⏸️ Paused: %s", pb.customLabel)
+			color.New(pb.theme.PauseColor, color.Bold).Printf(output.Prose("\r\033[K⏸️ Paused: %s"), pb.customLabel)
 		} else {
-			fmt.Printf("\r\033[K⏸️ Paused: %s", pb.customLabel)
+			output.Printf("\r\033[K⏸️ Paused: %s", pb.customLabel)
 		}
 		return
 	}
@@ -926,6 +941,9 @@ func (pb *ProgressBar) Display() {
 
 	// Build status line
 	status := fmt.Sprintf("[%s] ", bar)
+	if output.PlainMode() {
+		status = ""
+	}
 
 	// Add percentage with color
 	if pb.showPercent {
@@ -1001,6 +1019,10 @@ func (pb *ProgressBar) Display() {
 	}
 
 	// Clear line and print status
+	if output.PlainMode() {
+		fmt.Println(strings.TrimSpace(status))
+		return
+	}
 	fmt.Print("\r\033[K" + status)
 }
 
@@ -1063,6 +1085,19 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.1fh", d.Hours())
 }
 
+// Topics published by whoever runs a download; every payload is a DownloadEvent
+var (
+	TopicDownloadCompleted = events.Define("download.completed", "A download finished")
+	TopicDownloadFailed    = events.Define("download.failed", "A download failed")
+)
+
+// DownloadEvent is the payload of download events
+type DownloadEvent struct {
+	URL   string `json:"url"`
+	Path  string `json:"path"`
+	Error string `json:"error,omitempty"`
+}
+
 // DownloadFileWithProgress downloads a file from URL with real HTTP and progress tracking
 func DownloadFileWithProgress(url, filename string, config *ProgressBarConfig) error {
 	// Create HTTP client with timeout
@@ -1073,7 +1108,7 @@ func DownloadFileWithProgress(url, filename string, config *ProgressBarConfig) e
 	// Start HTTP request
 	resp, err := client.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to start download: %v", err)
+		return fmt.Errorf("failed to start download: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -1108,7 +1143,7 @@ func DownloadFileWithProgress(url, filename string, config *ProgressBarConfig) e
 	// Create output file
 	file, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %v", err)
+		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 
@@ -1200,25 +1235,30 @@ func CopyFileWithProgress(src, dst string) error {
 	// Open source file
 	srcFile, err := os.Open(src)
 	if err != nil {
-		return fmt.Errorf("failed to open source file: %v", err)
+		return fmt.Errorf("failed to open source file: %w", err)
 	}
 	defer srcFile.Close()
 
 	// Get file info for size
 	srcInfo, err := srcFile.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to get source file info: %v", err)
+		return fmt.Errorf("failed to get source file info: %w", err)
 	}
 
 	// Create destination file
 	dstFile, err := os.Create(dst)
 	if err != nil {
-		return fmt.Errorf("failed to create destination file: %v", err)
+		return fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer dstFile.Close()
 
+	return CopyWithProgress(dstFile, srcFile, srcInfo.Size(), src, dst)
+}
+
+// CopyWithProgress copies already opened files, showing a progress bar for size bytes
+func CopyWithProgress(dstFile io.Writer, srcFile io.Reader, size int64, src, dst string) error {
 	// Create enhanced progress bar with colors
-	pb := NewProgressBar(srcInfo.Size(), &ProgressBarConfig{
+	pb := NewProgressBar(size, &ProgressBarConfig{
 		Width:        50,
 		ShowPercent:  true,
 		ShowSpeed:    true,
@@ -1232,13 +1272,13 @@ func CopyFileWithProgress(src, dst string) error {
 	progressWriter := NewProgressWriter(dstFile, pb)
 
 	// Copy with progress and error handling
-	fmt.Printf("📁 Copying file: %s → %s\n", src, dst)
-	_, err = io.Copy(progressWriter, srcFile)
+	output.Printf("📁 Copying file: %s → %s\n", src, dst)
+	_, err := io.Copy(progressWriter, srcFile)
 	if err != nil {
 		pb.SetError(fmt.Sprintf("Copy failed: %v", err))
 		pb.Display()
 		fmt.Println()
-		return fmt.Errorf("failed to copy file: %v", err)
+		return fmt.Errorf("failed to copy file: %w", err)
 	}
 
 	// Finish progress bar
@@ -1253,7 +1293,7 @@ func CopyFileWithProgress(src, dst string) error {
 func DownloadWithProgress(url, filename string) error {
 	// This would integrate with HTTP client for actual downloads
 	// For now, we'll simulate a download
-	fmt.Printf("🌐 Downloading: %s → %s\n", url, filename)
+	output.Printf("🌐 Downloading: %s → %s\n", url, filename)
 
 	// Simulate download progress
 	totalSize := int64(1024 * 1024) // 1MB simulation
@@ -1307,7 +1347,7 @@ func ProcessMultipleFilesWithProgress(files []string, operation string) error {
 		info, err := os.Stat(file)
 		if err != nil {
 			mpm.Stop()
-			return fmt.Errorf("failed to get file info for %s: %v", file, err)
+			return fmt.Errorf("failed to get file info for %s: %w", file, err)
 		}
 
 		// Create progress bar for this file
@@ -1354,7 +1394,7 @@ func ProcessWithProgress(total int64, processName string, processFunc func(pb *P
 		ColorEnabled: true,
 	})
 
-	fmt.Printf("⚙️ Processing: %s\n", processName)
+	output.Printf("⚙️ Processing: %s\n", processName)
 
 	err := processFunc(pb)
 	if err != nil {
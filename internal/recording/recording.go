@@ -0,0 +1,199 @@
+/**
+ * Session Recording
+ *
+ * Records the commands typed at the interactive prompt, with their timings,
+ * results and exit status, into a session file that can be replayed on
+ * another machine or in another directory. Each entry extends the analytics
+ * layer's CommandUsage. Which session is being recorded is kept in the state
+ * directory, so a recording started from the shell is picked up by the next
+ * prompt and survives a crash.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: recording.go
+ * Description: Session files and the active recording
+ */
+
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ena/internal/paths"
+	"ena/internal/suggestions"
+)
+
+// Version is the session file format written by this build
+const Version = 1
+
+// Entry is one command run at the prompt. Args holds the words after
+// expansion, so $last, variables and piped paths replay exactly.
+type Entry struct {
+	suggestions.CommandUsage
+	Line     string      `json:"line"` // the command as typed
+	ExitCode int         `json:"exit_code"`
+	Result   interface{} `json:"result,omitempty"`
+}
+
+// Words returns the command line the entry ran
+func (e Entry) Words() []string {
+	return append([]string{e.Command}, e.Args...)
+}
+
+// Session is a recorded sequence of commands
+type Session struct {
+	Version    int        `json:"version"`
+	Host       string     `json:"host"`
+	WorkingDir string     `json:"working_dir"` // where the recording started
+	HomeDir    string     `json:"home_dir"`
+	StartedAt  time.Time  `json:"started_at"`
+	StoppedAt  *time.Time `json:"stopped_at,omitempty"`
+	Entries    []Entry    `json:"entries"`
+}
+
+// Dir returns where session files are kept unless another path is given
+func Dir() string {
+	return filepath.Join(paths.DataDir(), "recordings")
+}
+
+// DefaultPath returns a new session file name in Dir
+func DefaultPath() string {
+	return filepath.Join(Dir(), fmt.Sprintf("session-%s.json", time.Now().Format("20060102-150405")))
+}
+
+// statePath is the file naming the session being recorded
+func statePath() string {
+	return paths.StateFile("recording")
+}
+
+// Active returns the session file being recorded, if any
+func Active() (string, bool) {
+	data, err := os.ReadFile(statePath())
+	if err != nil {
+		return "", false
+	}
+	path := strings.TrimSpace(string(data))
+	return path, path != ""
+}
+
+// Start begins recording into path, which must not exist yet
+func Start(path string) (*Session, error) {
+	if active, ok := Active(); ok {
+		return nil, fmt.Errorf("already recording to %s", active)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to resolve %s: %w", path, err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s already exists", path)
+	}
+
+	host, _ := os.Hostname()
+	workingDir, _ := os.Getwd()
+	homeDir, _ := os.UserHomeDir()
+	session := &Session{
+		Version:    Version,
+		Host:       host,
+		WorkingDir: workingDir,
+		HomeDir:    homeDir,
+		StartedAt:  time.Now(),
+		Entries:    []Entry{},
+	}
+
+	if err := session.Save(path); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(statePath(), []byte(path+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("Failed to start recording: %w", err)
+	}
+	return session, nil
+}
+
+// Record appends an entry to the active session; without one it does nothing
+func Record(entry Entry) error {
+	path, ok := Active()
+	if !ok {
+		return nil
+	}
+
+	session, err := Load(path)
+	if err != nil {
+		return err
+	}
+	session.Entries = append(session.Entries, entry)
+	return session.Save(path)
+}
+
+// Stop ends the active recording, returning the session and its file
+func Stop() (*Session, string, error) {
+	path, ok := Active()
+	if !ok {
+		return nil, "", fmt.Errorf("not recording")
+	}
+	os.Remove(statePath())
+
+	session, err := Load(path)
+	if err != nil {
+		return nil, path, err
+	}
+	now := time.Now()
+	session.StoppedAt = &now
+	return session, path, session.Save(path)
+}
+
+// Load reads a session file
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("Failed to parse session %s: %w", path, err)
+	}
+	if session.Version > Version {
+		return nil, fmt.Errorf("session %s has format %d; this Ena reads up to %d", path, session.Version, Version)
+	}
+	return &session, nil
+}
+
+// Save writes the session to a temporary file and renames it over path, so a
+// crash mid-write never leaves the session half written
+func (s *Session) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("Failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode session: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("Failed to write session: %w", err)
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("Failed to write session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+/**
+ * Session Replay
+ *
+ * Runs a recorded session again, rewriting the paths it recorded so they
+ * point at the same places on this machine. By default the recorded working
+ * and home directories map to the current ones; extra mappings come from
+ * --rebase old=new. Replay stops at the first command that fails where the
+ * recording succeeded, since later commands usually depend on it.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: replay.go
+ * Description: Path rebasing, dry-run and step-through replay of sessions
+ */
+
+package recording
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ena/internal/input"
+	"ena/internal/script"
+)
+
+// Rebase maps a recorded path prefix to the one to use instead
+type Rebase struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ParseRebase reads a mapping written as old=new
+func ParseRebase(spec string) (Rebase, error) {
+	from, to, ok := strings.Cut(spec, "=")
+	if !ok || from == "" || to == "" {
+		return Rebase{}, fmt.Errorf("--rebase expects old=new, got %q", spec)
+	}
+	return Rebase{From: filepath.Clean(from), To: filepath.Clean(to)}, nil
+}
+
+// DefaultRebases maps the recorded working and home directories to the
+// current ones, leaving out those that did not change
+func (s *Session) DefaultRebases() []Rebase {
+	workingDir, _ := os.Getwd()
+	homeDir, _ := os.UserHomeDir()
+
+	var rebases []Rebase
+	for _, rebase := range []Rebase{{s.WorkingDir, workingDir}, {s.HomeDir, homeDir}} {
+		if rebase.From != "" && rebase.To != "" && rebase.From != rebase.To {
+			rebases = append(rebases, Rebase{From: filepath.Clean(rebase.From), To: filepath.Clean(rebase.To)})
+		}
+	}
+	return rebases
+}
+
+// RebaseArgs rewrites every argument, or --flag=value, that lies under a
+// From prefix. The longest matching prefix wins.
+func RebaseArgs(args []string, rebases []Rebase) []string {
+	ordered := append([]Rebase{}, rebases...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return len(ordered[i].From) > len(ordered[j].From)
+	})
+
+	rebased := make([]string, len(args))
+	for i, arg := range args {
+		if flag, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(flag, "-") {
+			rebased[i] = flag + "=" + rebasePath(value, ordered)
+			continue
+		}
+		rebased[i] = rebasePath(arg, ordered)
+	}
+	return rebased
+}
+
+// rebasePath applies the first rebase whose prefix holds path
+func rebasePath(path string, rebases []Rebase) string {
+	for _, rebase := range rebases {
+		if path == rebase.From {
+			return rebase.To
+		}
+		if strings.HasPrefix(path, rebase.From+string(filepath.Separator)) {
+			return rebase.To + path[len(rebase.From):]
+		}
+	}
+	return path
+}
+
+// Step records what happened to one entry during a replay
+type Step struct {
+	Index            int               `json:"index"`
+	Command          string            `json:"command"` // after rebasing
+	RecordedExitCode int               `json:"recorded_exit_code"`
+	ExitCode         int               `json:"exit_code"`
+	Status           script.StepStatus `json:"status"`
+}
+
+// Result records a whole replay
+type Result struct {
+	Session       string        `json:"session"`
+	DryRun        bool          `json:"dry_run"`
+	OK            bool          `json:"ok"` // no command failed that succeeded when recorded
+	Rebases       []Rebase      `json:"rebases"`
+	UndoSessionID string        `json:"undo_session_id,omitempty"`
+	Steps         []Step        `json:"steps"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// Replayer runs a session's entries through an Executor, like a script
+type Replayer struct {
+	Executor  script.Executor
+	DryRun    bool
+	KeepGoing bool // carry on after a command fails that succeeded when recorded
+	Rebases   []Rebase
+	// Confirm is asked before each command when stepping through; returning
+	// run false skips the command and quit true skips the rest
+	Confirm    func(index int, command string) (run bool, quit bool)
+	BeforeStep func(index int, entry Entry, command string)
+	AfterStep  func(step Step)
+}
+
+// Replay runs every entry of session, which was read from path
+func (r *Replayer) Replay(session *Session, path string) *Result {
+	start := time.Now()
+	result := &Result{
+		Session: path,
+		DryRun:  r.DryRun,
+		OK:      true,
+		Rebases: r.Rebases,
+		Steps:   make([]Step, 0, len(session.Entries)),
+	}
+
+	stopped := false
+	for i, entry := range session.Entries {
+		args := RebaseArgs(entry.Words(), r.Rebases)
+		step := Step{Index: i + 1, Command: quoteArgs(args), RecordedExitCode: entry.ExitCode}
+
+		if !stopped && r.Confirm != nil {
+			run, quit := r.Confirm(step.Index, step.Command)
+			stopped = quit
+			if !run && !quit {
+				step.Status = script.StatusSkipped
+				r.finish(result, step)
+				continue
+			}
+		}
+		if stopped {
+			step.Status = script.StatusSkipped
+			r.finish(result, step)
+			continue
+		}
+
+		if r.BeforeStep != nil {
+			r.BeforeStep(step.Index, entry, step.Command)
+		}
+
+		if r.DryRun {
+			code, previewed := r.Executor.Preview(args)
+			step.ExitCode = code
+			step.Status = script.StatusPlanned
+			if previewed {
+				step.Status = script.StatusPreviewed
+			}
+		} else {
+			step.ExitCode = r.Executor.Run(args)
+			step.Status = script.StatusOK
+		}
+		if step.ExitCode != 0 {
+			step.Status = script.StatusFailed
+		}
+
+		// A command that failed when recorded too is part of the session
+		if step.ExitCode != 0 && entry.ExitCode == 0 {
+			result.OK = false
+			stopped = !r.KeepGoing
+		}
+		r.finish(result, step)
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// finish records a step and notifies the observer
+func (r *Replayer) finish(result *Result, step Step) {
+	result.Steps = append(result.Steps, step)
+	if r.AfterStep != nil {
+		r.AfterStep(step)
+	}
+}
+
+// quoteArgs renders words as a command line that splits back identically
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = input.Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,202 @@
+/**
+ * Cron Expressions
+ *
+ * Parses standard five-field cron expressions (minute, hour, day of month,
+ * month, day of week) with lists, ranges, steps and month and weekday names,
+ * plus the @hourly, @daily, @weekly, @monthly and @yearly shorthands, and
+ * finds the next time one matches. As in Vixie cron, a day field starting
+ * with * counts as unrestricted, even with a step; when both day fields are
+ * restricted a day matching either is enough, otherwise it must match both.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: cron.go
+ * Description: Cron expression parsing and next-run calculation
+ */
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ena/internal/fault"
+)
+
+// Cron is a parsed cron expression
+type Cron struct {
+	minutes  uint64 // bit n set when minute n matches
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	anyDay   bool // day of month started with *
+	anyWeek  bool // day of week started with *
+}
+
+// cronField describes the values one field accepts
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	dayField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	weekdayField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// shorthands are the @ forms cron accepts in place of five fields
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if full, ok := shorthands[strings.ToLower(expr)]; ok {
+		expr = full
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q needs 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	c := &Cron{anyDay: strings.HasPrefix(fields[2], "*"), anyWeek: strings.HasPrefix(fields[4], "*")}
+	var err error
+	if c.minutes, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.hours, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.days, err = dayField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.months, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.weekdays, err = weekdayField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+
+	// Sunday may be written as 7
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+	return c, nil
+}
+
+// parse turns one field into a bit set of the values it matches
+func (f cronField) parse(text string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(strings.ToLower(text), ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fault.Invalid("invalid step %q in %s field", stepText, f.name)
+			}
+			step = n
+		}
+
+		low, high := f.min, f.max
+		if rangeText != "*" {
+			lowText, highText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = f.value(lowText); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highText); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// 5/15 means from 5 to the end in steps of 15
+				high = f.max
+			}
+			if low > high {
+				return 0, fault.Invalid("invalid range %q in %s field", rangeText, f.name)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// value reads one number or name of a field
+func (f cronField) value(text string) (int, error) {
+	if n, ok := f.names[text]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(text)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fault.Invalid("invalid %s %q (expected %d-%d)", f.name, text, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Next returns the first matching minute after t, or the zero time when the
+// expression can never match, e.g. 30 February
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Every valid expression matches within a leap cycle
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay applies cron's rule for the two day fields
+func (c *Cron) matchesDay(t time.Time) bool {
+	dayOK := c.days&(1<<uint(t.Day())) != 0
+	weekOK := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	// A bare * sets every bit, so this only narrows for steps such as */2
+	if c.anyDay || c.anyWeek {
+		return dayOK && weekOK
+	}
+	return dayOK || weekOK
+}
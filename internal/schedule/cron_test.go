@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ena/internal/fault"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"* * * * mon-",
+		"1,,2 * * * *",
+	}
+	for _, expr := range tests {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+
+	if _, err := ParseCron("61 * * * *"); !errors.Is(err, fault.ErrInvalid) {
+		t.Errorf("out of range value = %v, want fault.ErrInvalid", err)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2026-01-01 is a Thursday
+	thursday := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []string // successive runs
+	}{
+		{"every minute", "* * * * *", thursday, []string{"2026-01-01 00:01", "2026-01-01 00:02"}},
+		{"minute step", "*/15 * * * *", thursday, []string{"2026-01-01 00:15", "2026-01-01 00:30", "2026-01-01 00:45", "2026-01-01 01:00"}},
+		{"step from a start", "5/20 * * * *", thursday, []string{"2026-01-01 00:05", "2026-01-01 00:25", "2026-01-01 00:45", "2026-01-01 01:05"}},
+		{"stepped range", "0 9-17/4 * * *", thursday, []string{"2026-01-01 09:00", "2026-01-01 13:00", "2026-01-01 17:00", "2026-01-02 09:00"}},
+		{"range", "0 22-23 * * *", thursday, []string{"2026-01-01 22:00", "2026-01-01 23:00", "2026-01-02 22:00"}},
+		{"list", "30 8,12 * * *", thursday, []string{"2026-01-01 08:30", "2026-01-01 12:30", "2026-01-02 08:30"}},
+		{"list of ranges", "0 0 1-2,15 * *", thursday, []string{"2026-01-02 00:00", "2026-01-15 00:00", "2026-02-01 00:00"}},
+		{"weekday names", "0 0 * * mon-fri", thursday, []string{"2026-01-02 00:00", "2026-01-05 00:00", "2026-01-06 00:00"}},
+		{"sunday as 7", "0 0 * * 7", thursday, []string{"2026-01-04 00:00", "2026-01-11 00:00"}},
+		{"month names", "0 0 1 jun,dec *", thursday, []string{"2026-06-01 00:00", "2026-12-01 00:00", "2027-06-01 00:00"}},
+		{"leap day", "0 0 29 2 *", thursday, []string{"2028-02-29 00:00", "2032-02-29 00:00"}},
+		{"yearly", "@yearly", thursday, []string{"2027-01-01 00:00", "2028-01-01 00:00"}},
+		{"hourly", "@HOURLY", thursday, []string{"2026-01-01 01:00", "2026-01-01 02:00"}},
+		{"weekly", "@weekly", thursday, []string{"2026-01-04 00:00", "2026-01-11 00:00"}},
+
+		// Both day fields restricted: either one is enough
+		{"day or weekday", "0 0 13 * 5", thursday, []string{"2026-01-02 00:00", "2026-01-09 00:00", "2026-01-13 00:00", "2026-01-16 00:00"}},
+		{"day range or weekday", "0 0 1-7 * 1", tuesday, []string{"2026-01-07 00:00", "2026-01-12 00:00", "2026-01-19 00:00", "2026-01-26 00:00", "2026-02-01 00:00"}},
+
+		// A day field starting with * is unrestricted, so both must match
+		{"stepped day and weekday", "0 0 */2 * 1", tuesday, []string{"2026-01-19 00:00", "2026-02-09 00:00", "2026-02-23 00:00"}},
+		{"day and stepped weekday", "0 0 1 * */3", thursday, []string{"2026-02-01 00:00", "2026-03-01 00:00", "2026-04-01 00:00", "2026-07-01 00:00"}},
+		{"stepped weekday alone", "0 0 * * */2", thursday, []string{"2026-01-03 00:00", "2026-01-04 00:00", "2026-01-06 00:00", "2026-01-08 00:00"}},
+		{"stepped day alone", "0 0 */10 * *", thursday, []string{"2026-01-11 00:00", "2026-01-21 00:00", "2026-01-31 00:00", "2026-02-01 00:00"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cron, err := ParseCron(test.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", test.expr, err)
+			}
+
+			at := test.from
+			for i, want := range test.want {
+				at = cron.Next(at)
+				if got := at.Format("2006-01-02 15:04"); got != want {
+					t.Fatalf("run %d of %q = %s, want %s", i+1, test.expr, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	cron, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := cron.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("30 February matched %v", next)
+	}
+}
+
+func TestCronNextSkipsToNextMinute(t *testing.T) {
+	cron, err := ParseCron("30 12 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 1, 12, 30, 45, 0, time.UTC)
+	if got, want := cron.Next(from), time.Date(2026, 1, 2, 12, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", from, got, want)
+	}
+}
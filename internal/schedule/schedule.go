@@ -0,0 +1,540 @@
+/**
+ * Job Scheduler
+ *
+ * Runs pattern operations, organizer runs, backups, backup cleanup and
+ * scripts on cron schedules. Jobs live in $XDG_DATA_HOME/ena/schedules.json
+ * and every run, including missed ones, is appended to the run history in the
+ * state directory. The scheduler only fires jobs while it is started, which
+ * the daemon does; runs that fell due while it was stopped are either skipped
+ * or caught up once, as each job chooses.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: schedule.go
+ * Description: Cron job storage, run history and the scheduling loop
+ */
+
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"ena/internal/fault"
+	"ena/internal/output"
+	"ena/internal/paths"
+)
+
+// Action is the kind of work a job does
+type Action string
+
+const (
+	ActionPattern       Action = "pattern"        // args: operation ID
+	ActionOrganize      Action = "organize"       // args: paths to organize
+	ActionBackup        Action = "backup"         // args: path to back up
+	ActionBackupCleanup Action = "backup-cleanup" // no args
+	ActionScript        Action = "script"         // args: script name and its arguments
+)
+
+// Actions lists every action in display order
+var Actions = []Action{ActionPattern, ActionOrganize, ActionBackup, ActionBackupCleanup, ActionScript}
+
+// MissedPolicy decides what happens to runs that fell due while the scheduler was stopped
+type MissedPolicy string
+
+const (
+	MissedSkip MissedPolicy = "skip" // record the miss and wait for the next run
+	MissedRun  MissedPolicy = "run"  // run once as soon as possible
+)
+
+// Trigger tells why a job ran
+type Trigger string
+
+const (
+	TriggerSchedule Trigger = "schedule"
+	TriggerMissed   Trigger = "missed"
+	TriggerManual   Trigger = "manual"
+)
+
+// RunStatus is the outcome of one run
+type RunStatus string
+
+const (
+	RunOK      RunStatus = "ok"
+	RunFailed  RunStatus = "failed"
+	RunSkipped RunStatus = "skipped" // a missed run the job's policy skipped
+)
+
+// maxHistory is how many runs the history keeps
+const maxHistory = 500
+
+// missedGrace is how late a run may start before it counts as missed
+const missedGrace = time.Minute
+
+// Job is a scheduled piece of work
+type Job struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Cron      string       `json:"cron"`
+	Action    Action       `json:"action"`
+	Args      []string     `json:"args,omitempty"`
+	Missed    MissedPolicy `json:"missed"`
+	CreatedAt time.Time    `json:"created_at"`
+	LastRun   *time.Time   `json:"last_run,omitempty"`
+	NextRun   time.Time    `json:"next_run"`
+}
+
+// Run records one execution of a job
+type Run struct {
+	JobID     string        `json:"job_id"`
+	JobName   string        `json:"job_name"`
+	Trigger   Trigger       `json:"trigger"`
+	Status    RunStatus     `json:"status"`
+	Scheduled *time.Time    `json:"scheduled,omitempty"` // when the run was due; unset for manual runs
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+	Summary   string        `json:"summary,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Executor carries out a job's action
+type Executor interface {
+	// Execute runs the job and describes what it did
+	Execute(job *Job) (summary string, err error)
+}
+
+// Scheduler keeps the job list and fires jobs when they fall due
+type Scheduler struct {
+	Executor Executor
+
+	jobsFile    string
+	historyFile string
+	jobs        []*Job
+	loadedAt    time.Time // modification time of the jobs file when last read
+	mutex       sync.Mutex
+	runMutex    sync.Mutex // one job at a time
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewScheduler creates a scheduler using the XDG data and state directories
+func NewScheduler() *Scheduler {
+	return NewSchedulerAt(paths.DataFile("schedules.json"), paths.StateFile("schedule_history.json"))
+}
+
+// NewSchedulerAt creates a scheduler keeping its jobs and history in the given files
+func NewSchedulerAt(jobsFile, historyFile string) *Scheduler {
+	return &Scheduler{jobsFile: jobsFile, historyFile: historyFile}
+}
+
+// Validate checks a job's expression, action and arguments
+func (j *Job) Validate() error {
+	if _, err := ParseCron(j.Cron); err != nil {
+		return err
+	}
+
+	switch j.Missed {
+	case MissedSkip, MissedRun:
+	default:
+		return fmt.Errorf("missed-run policy must be skip or run, got %q", j.Missed)
+	}
+
+	switch j.Action {
+	case ActionPattern, ActionBackup:
+		if len(j.Args) != 1 {
+			return fmt.Errorf("%s jobs take exactly one argument", j.Action)
+		}
+	case ActionOrganize, ActionScript:
+		if len(j.Args) == 0 {
+			return fmt.Errorf("%s jobs need at least one argument", j.Action)
+		}
+	case ActionBackupCleanup:
+		if len(j.Args) != 0 {
+			return fmt.Errorf("%s jobs take no arguments", j.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", j.Action)
+	}
+	return nil
+}
+
+// Add validates and stores a new job, working out when it first runs
+func (s *Scheduler) Add(job *Job) error {
+	if job.Missed == "" {
+		job.Missed = MissedSkip
+	}
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return err
+	}
+
+	job.ID = fmt.Sprintf("schedule_%d", time.Now().UnixNano())
+	if job.Name == "" {
+		job.Name = job.ID
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == job.Name {
+			return fmt.Errorf("a scheduled job named %q already exists", job.Name)
+		}
+	}
+
+	job.CreatedAt = time.Now()
+	cron, _ := ParseCron(job.Cron)
+	job.NextRun = cron.Next(job.CreatedAt)
+	if job.NextRun.IsZero() {
+		return fmt.Errorf("cron expression %q never matches", job.Cron)
+	}
+
+	s.jobs = append(s.jobs, job)
+	return s.saveLocked()
+}
+
+// Remove deletes a job by ID or name
+func (s *Scheduler) Remove(idOrName string) (*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	for i, job := range s.jobs {
+		if job.ID == idOrName || job.Name == idOrName {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return job, s.saveLocked()
+		}
+	}
+	return nil, fault.NotFound("scheduled job %s not found", idOrName)
+}
+
+// List returns every job, soonest first
+func (s *Scheduler) List() ([]*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, len(s.jobs))
+	for i, job := range s.jobs {
+		copied := *job
+		jobs[i] = &copied
+	}
+	sortJobs(jobs)
+	return jobs, nil
+}
+
+// Get finds a job by ID or name
+func (s *Scheduler) Get(idOrName string) (*Job, error) {
+	jobs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if job.ID == idOrName || job.Name == idOrName {
+			return job, nil
+		}
+	}
+	return nil, fault.NotFound("scheduled job %s not found", idOrName)
+}
+
+// RunNow runs a job straight away, leaving its schedule unchanged
+func (s *Scheduler) RunNow(idOrName string) (*Run, error) {
+	job, err := s.Get(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	return s.execute(job, TriggerManual, nil), nil
+}
+
+// History returns the most recent runs, newest last, optionally of one job
+func (s *Scheduler) History(idOrName string, limit int) ([]Run, error) {
+	runs, err := s.loadHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	if idOrName != "" {
+		filtered := runs[:0]
+		for _, run := range runs {
+			if run.JobID == idOrName || run.JobName == idOrName {
+				filtered = append(filtered, run)
+			}
+		}
+		runs = filtered
+	}
+
+	if limit > 0 && len(runs) > limit {
+		runs = runs[len(runs)-limit:]
+	}
+	return runs, nil
+}
+
+// Start begins firing jobs in the background, first dealing with runs missed
+// while the scheduler was stopped
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.loop(s.stop, s.done)
+}
+
+// Stop ends the background loop, waiting for a running job to finish
+func (s *Scheduler) Stop() error {
+	s.mutex.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mutex.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	<-done
+	return nil
+}
+
+// loop fires due jobs, waking at the start of every minute
+func (s *Scheduler) loop(stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		s.tick(time.Now())
+
+		wait := time.Until(time.Now().Truncate(time.Minute).Add(time.Minute))
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// tick runs or skips every job that is due at now
+func (s *Scheduler) tick(now time.Time) {
+	s.mutex.Lock()
+	if err := s.reloadLocked(); err != nil {
+		s.mutex.Unlock()
+		output.Fprintf(os.Stderr, "⚠️ Scheduler: %v\n", err)
+		return
+	}
+
+	var due []*Job
+	for _, job := range s.jobs {
+		if !job.NextRun.IsZero() && !job.NextRun.After(now) {
+			copied := *job
+			due = append(due, &copied)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, job := range due {
+		scheduled := job.NextRun
+		if now.Sub(scheduled) < missedGrace {
+			s.execute(job, TriggerSchedule, &scheduled)
+			continue
+		}
+
+		if job.Missed == MissedRun {
+			s.execute(job, TriggerMissed, &scheduled)
+			continue
+		}
+
+		s.record(job, Run{
+			JobID:     job.ID,
+			JobName:   job.Name,
+			Trigger:   TriggerMissed,
+			Status:    RunSkipped,
+			Scheduled: &scheduled,
+			StartTime: now,
+			Summary:   "Missed while the scheduler was not running",
+		}, false)
+	}
+}
+
+// execute runs a job, records the run and moves the job's next run on
+func (s *Scheduler) execute(job *Job, trigger Trigger, scheduled *time.Time) *Run {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	run := Run{
+		JobID:     job.ID,
+		JobName:   job.Name,
+		Trigger:   trigger,
+		Status:    RunOK,
+		Scheduled: scheduled,
+		StartTime: time.Now(),
+	}
+
+	var err error
+	if s.Executor == nil {
+		err = fmt.Errorf("no executor is set up to run scheduled jobs")
+	} else {
+		run.Summary, err = s.Executor.Execute(job)
+	}
+	run.Duration = time.Since(run.StartTime)
+	if err != nil {
+		run.Status = RunFailed
+		run.Error = err.Error()
+	}
+
+	s.record(job, run, true)
+	return &run
+}
+
+// record appends a run to the history and updates the job it belongs to
+func (s *Scheduler) record(job *Job, run Run, ran bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reloadLocked(); err == nil {
+		for _, stored := range s.jobs {
+			if stored.ID != job.ID {
+				continue
+			}
+			if ran {
+				stored.LastRun = &run.StartTime
+			}
+			// Manual runs leave the schedule alone; others move past now
+			if run.Trigger != TriggerManual {
+				cron, err := ParseCron(stored.Cron)
+				if err == nil {
+					stored.NextRun = cron.Next(time.Now())
+				}
+			}
+		}
+		if err := s.saveLocked(); err != nil {
+			output.Fprintf(os.Stderr, "⚠️ Scheduler: %v\n", err)
+		}
+	}
+
+	runs, err := s.loadHistory()
+	if err != nil {
+		runs = nil
+	}
+	runs = append(runs, run)
+	if len(runs) > maxHistory {
+		runs = runs[len(runs)-maxHistory:]
+	}
+	if err := writeJSON(s.historyFile, runs); err != nil {
+		output.Fprintf(os.Stderr, "⚠️ Scheduler: %v\n", err)
+	}
+}
+
+// reloadLocked rereads the jobs file when another process has changed it
+func (s *Scheduler) reloadLocked() error {
+	info, err := os.Stat(s.jobsFile)
+	if os.IsNotExist(err) {
+		s.jobs = nil
+		s.loadedAt = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read schedules: %w", err)
+	}
+	if s.jobs != nil && info.ModTime().Equal(s.loadedAt) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.jobsFile)
+	if err != nil {
+		return fmt.Errorf("Failed to read schedules: %w", err)
+	}
+
+	var stored struct {
+		Jobs []*Job `json:"jobs"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("Failed to parse schedules: %w", err)
+	}
+
+	s.jobs = stored.Jobs
+	if s.jobs == nil {
+		s.jobs = []*Job{}
+	}
+	s.loadedAt = info.ModTime()
+	return nil
+}
+
+// saveLocked writes the jobs file
+func (s *Scheduler) saveLocked() error {
+	stored := struct {
+		Jobs    []*Job    `json:"jobs"`
+		Version string    `json:"version"`
+		Updated time.Time `json:"updated"`
+	}{Jobs: s.jobs, Version: "1.0", Updated: time.Now()}
+
+	if err := writeJSON(s.jobsFile, stored); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.jobsFile); err == nil {
+		s.loadedAt = info.ModTime()
+	}
+	return nil
+}
+
+// loadHistory reads every recorded run, oldest first
+func (s *Scheduler) loadHistory() ([]Run, error) {
+	data, err := os.ReadFile(s.historyFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read schedule history: %w", err)
+	}
+
+	var runs []Run
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("Failed to parse schedule history: %w", err)
+	}
+	return runs, nil
+}
+
+// sortJobs orders jobs by their next run, then by name
+func sortJobs(jobs []*Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].NextRun.Equal(jobs[j].NextRun) {
+			return jobs[i].NextRun.Before(jobs[j].NextRun)
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+}
+
+// writeJSON replaces a file with the JSON encoding of value, never leaving it half written
+func writeJSON(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("Failed to create directory: %w", err)
+	}
+
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return fmt.Errorf("Failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("Failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/**
+ * Script Runner
+ *
+ * Walks a script's steps, deciding which run from the previous step's exit
+ * status, and reports each outcome. The commands themselves are executed by
+ * an Executor so the runner does not depend on the command tree.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: runner.go
+ * Description: Step sequencing, conditionals and dry-run for scripts
+ */
+
+package script
+
+import (
+	"strings"
+	"time"
+
+	"ena/internal/input"
+)
+
+// StepStatus is the outcome of a single step
+type StepStatus string
+
+const (
+	StatusOK        StepStatus = "ok"        // ran and succeeded
+	StatusFailed    StepStatus = "failed"    // ran and failed
+	StatusSkipped   StepStatus = "skipped"   // condition did not match
+	StatusPreviewed StepStatus = "previewed" // ran with --dry-run
+	StatusPlanned   StepStatus = "planned"   // would run; the command has no dry-run mode
+)
+
+// StepResult records what happened to one step
+type StepResult struct {
+	Index    int        `json:"index"`
+	Command  string     `json:"command"`
+	When     When       `json:"when"`
+	Status   StepStatus `json:"status"`
+	ExitCode int        `json:"exit_code"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// RunResult records a whole script run
+type RunResult struct {
+	Script        string            `json:"script"`
+	Params        map[string]string `json:"params"`
+	DryRun        bool              `json:"dry_run"`
+	OK            bool              `json:"ok"`
+	UndoSessionID string            `json:"undo_session_id,omitempty"`
+	Steps         []StepResult      `json:"steps"`
+	Duration      time.Duration     `json:"duration"`
+}
+
+// Executor runs expanded step commands
+type Executor interface {
+	// Run executes a command and returns its exit code
+	Run(args []string) int
+	// Preview executes a command in dry-run mode; ok is false when it has none
+	Preview(args []string) (exitCode int, ok bool)
+}
+
+// Runner executes scripts step by step
+type Runner struct {
+	Executor   Executor
+	DryRun     bool
+	BeforeStep func(index int, step Step, command string) // called before a step executes
+	AfterStep  func(result StepResult)                    // called for every step, including skipped ones
+}
+
+// Run executes a script with bound parameter values. Like a shell list, the
+// script succeeds when the last step that ran succeeded.
+func (r *Runner) Run(s *Script, values map[string]string) *RunResult {
+	start := time.Now()
+	result := &RunResult{
+		Script: s.Name,
+		Params: values,
+		DryRun: r.DryRun,
+		Steps:  make([]StepResult, 0, len(s.Steps)),
+	}
+
+	previousOK := true
+	for i, step := range s.Steps {
+		stepResult := StepResult{Index: i + 1, Command: step.Command, When: step.When}
+
+		if !step.ShouldRun(previousOK) {
+			stepResult.Status = StatusSkipped
+			r.finish(result, stepResult)
+			continue
+		}
+
+		args, err := step.Expand(values)
+		if err != nil {
+			stepResult.Status = StatusFailed
+			stepResult.ExitCode = 1
+			stepResult.Error = err.Error()
+			previousOK = false
+			r.finish(result, stepResult)
+			continue
+		}
+		stepResult.Command = quoteArgs(args)
+
+		if r.BeforeStep != nil {
+			r.BeforeStep(i+1, step, stepResult.Command)
+		}
+
+		if r.DryRun {
+			code, previewed := r.Executor.Preview(args)
+			stepResult.ExitCode = code
+			stepResult.Status = StatusPlanned
+			if previewed {
+				stepResult.Status = StatusPreviewed
+			}
+			if code != 0 {
+				stepResult.Status = StatusFailed
+			}
+		} else {
+			stepResult.ExitCode = r.Executor.Run(args)
+			stepResult.Status = StatusOK
+			if stepResult.ExitCode != 0 {
+				stepResult.Status = StatusFailed
+			}
+		}
+
+		previousOK = stepResult.Status != StatusFailed
+		r.finish(result, stepResult)
+	}
+
+	result.OK = previousOK
+	result.Duration = time.Since(start)
+	return result
+}
+
+// finish records a step result and notifies the observer
+func (r *Runner) finish(result *RunResult, stepResult StepResult) {
+	result.Steps = append(result.Steps, stepResult)
+	if r.AfterStep != nil {
+		r.AfterStep(stepResult)
+	}
+}
+
+// quoteArgs renders expanded words as a command line that splits back identically
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = input.Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,226 @@
+/**
+ * Script Definitions
+ *
+ * A script is a named sequence of Ena command lines with parameters. Each step
+ * runs depending on how the previous one ended, like a shell list:
+ *
+ *   file create $name        runs when the previous step succeeded (&& is optional)
+ *   || notify error "failed" runs only when the previous step failed
+ *   ; health                 always runs
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: script.go
+ * Description: Script, step and parameter types with parsing and expansion
+ */
+
+package script
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ena/internal/fault"
+	"ena/internal/input"
+)
+
+// When decides whether a step runs, based on the previous step's outcome
+type When string
+
+const (
+	WhenSuccess When = "success" // previous step succeeded (default)
+	WhenFailure When = "failure" // previous step failed
+	WhenAlways  When = "always"  // regardless of the previous step
+)
+
+// Step is one Ena command line within a script
+type Step struct {
+	Command string `json:"command"`
+	When    When   `json:"when,omitempty"`
+}
+
+// Param is a named script parameter, referenced in steps as $name or ${name}
+type Param struct {
+	Name     string `json:"name"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// Script is a stored sequence of steps
+type Script struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Params      []Param    `json:"params,omitempty"`
+	Steps       []Step     `json:"steps"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	RunCount    int        `json:"run_count"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+}
+
+// ParseStep reads a step written as "[&&|'||'|;] command"
+func ParseStep(text string) (Step, error) {
+	text = strings.TrimSpace(text)
+	step := Step{When: WhenSuccess}
+
+	switch {
+	case strings.HasPrefix(text, "&&"):
+		text = text[2:]
+	case strings.HasPrefix(text, "||"):
+		step.When = WhenFailure
+		text = text[2:]
+	case strings.HasPrefix(text, ";"):
+		step.When = WhenAlways
+		text = text[1:]
+	}
+
+	step.Command = strings.TrimSpace(text)
+	if step.Command == "" {
+		return step, fmt.Errorf("empty step")
+	}
+
+	// Check quoting now rather than halfway through a run
+	lexer := &input.Lexer{Lookup: func(string) (string, bool) { return "", true }, HomeDir: os.UserHomeDir}
+	if _, err := lexer.Lex(step.Command); err != nil {
+		return step, fmt.Errorf("step %q: %w", step.Command, err)
+	}
+
+	return step, nil
+}
+
+// String renders a step in the form ParseStep reads
+func (s Step) String() string {
+	switch s.When {
+	case WhenFailure:
+		return "|| " + s.Command
+	case WhenAlways:
+		return "; " + s.Command
+	default:
+		return s.Command
+	}
+}
+
+// ShouldRun reports whether the step runs after a step that ended with previousOK
+func (s Step) ShouldRun(previousOK bool) bool {
+	switch s.When {
+	case WhenFailure:
+		return !previousOK
+	case WhenAlways:
+		return true
+	default:
+		return previousOK
+	}
+}
+
+// Expand splits the step into command words, substituting parameters and then
+// environment variables
+func (s Step) Expand(values map[string]string) ([]string, error) {
+	lexer := input.NewLexer()
+	lexer.Lookup = func(name string) (string, bool) {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+
+	words, err := lexer.Split(s.Command)
+	if err != nil {
+		return nil, fmt.Errorf("step %q: %w", s.Command, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("step %q expands to nothing", s.Command)
+	}
+	return words, nil
+}
+
+// ParseParam reads a parameter written as "name" (required) or "name=default"
+func ParseParam(spec string) (Param, error) {
+	name, value, hasDefault := strings.Cut(spec, "=")
+	param := Param{Name: strings.TrimSpace(name), Default: value, Required: !hasDefault}
+
+	if !validName(param.Name) || strings.Contains(param.Name, "-") || isPositional(param.Name) {
+		return param, fault.Invalid("invalid parameter name %q", param.Name)
+	}
+	return param, nil
+}
+
+// String renders a parameter in the form ParseParam reads
+func (p Param) String() string {
+	if p.Required {
+		return p.Name
+	}
+	return p.Name + "=" + p.Default
+}
+
+// Bind resolves parameter values from positional arguments and name=value
+// overrides. Positional arguments are also available as $1, $2, ...
+func (s *Script) Bind(args []string, overrides map[string]string) (map[string]string, error) {
+	if len(args) > len(s.Params) && len(s.Params) > 0 {
+		return nil, fmt.Errorf("script %s takes %d parameters, got %d arguments", s.Name, len(s.Params), len(args))
+	}
+
+	values := make(map[string]string)
+	for i, arg := range args {
+		values[strconv.Itoa(i+1)] = arg
+	}
+
+	known := make(map[string]bool)
+	for i, param := range s.Params {
+		known[param.Name] = true
+
+		value, ok := overrides[param.Name]
+		if !ok && i < len(args) {
+			value, ok = args[i], true
+		}
+		if !ok {
+			if param.Required {
+				return nil, fmt.Errorf("missing value for parameter %s", param.Name)
+			}
+			value = param.Default
+		}
+		values[param.Name] = value
+	}
+
+	for name := range overrides {
+		if !known[name] {
+			return nil, fmt.Errorf("script %s has no parameter %s", s.Name, name)
+		}
+	}
+
+	return values, nil
+}
+
+// Usage renders how the script is invoked, e.g. "cleanup <dir> [days=30]"
+func (s *Script) Usage() string {
+	parts := []string{s.Name}
+	for _, param := range s.Params {
+		if param.Required {
+			parts = append(parts, "<"+param.Name+">")
+		} else {
+			parts = append(parts, "["+param.String()+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// validName reports whether a script or parameter name is safe to use
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPositional reports whether a name would shadow a positional argument like $1
+func isPositional(name string) bool {
+	_, err := strconv.Atoi(name)
+	return err == nil
+}
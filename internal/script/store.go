@@ -0,0 +1,152 @@
+/**
+ * Script Store
+ *
+ * Keeps each script as a JSON file in $XDG_DATA_HOME/ena/scripts so scripts
+ * can be shared, versioned or edited by hand.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: store.go
+ * Description: On-disk storage for named scripts
+ */
+
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ena/internal/fault"
+	"ena/internal/paths"
+)
+
+// Store saves and loads scripts from a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store in the data directory
+func NewStore() *Store {
+	return &Store{dir: filepath.Join(paths.DataDir(), "scripts")}
+}
+
+// Dir returns the directory holding the script files
+func (st *Store) Dir() string {
+	return st.dir
+}
+
+// Exists reports whether a script with the given name is stored
+func (st *Store) Exists(name string) bool {
+	_, err := os.Stat(st.path(name))
+	return err == nil
+}
+
+// Save validates and writes a script, keeping its original creation time
+func (st *Store) Save(s *Script) error {
+	if !validName(s.Name) {
+		return fault.Invalid("invalid script name %q (use letters, digits, - and _)", s.Name)
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("script %s has no steps", s.Name)
+	}
+
+	now := time.Now()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+	s.UpdatedAt = now
+
+	if err := os.MkdirAll(st.dir, 0700); err != nil {
+		return fmt.Errorf("Failed to create script directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode script: %w", err)
+	}
+
+	if err := os.WriteFile(st.path(s.Name), data, 0644); err != nil {
+		return fmt.Errorf("Failed to write script: %w", err)
+	}
+	return nil
+}
+
+// Load reads a script by name
+func (st *Store) Load(name string) (*Script, error) {
+	if !validName(name) {
+		return nil, fault.Invalid("invalid script name %q", name)
+	}
+
+	data, err := os.ReadFile(st.path(name))
+	if os.IsNotExist(err) {
+		return nil, fault.NotFound("script %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read script: %w", err)
+	}
+
+	var s Script
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("Failed to parse script %s: %w", name, err)
+	}
+	s.Name = name
+	for i := range s.Steps {
+		if s.Steps[i].When == "" {
+			s.Steps[i].When = WhenSuccess
+		}
+	}
+	return &s, nil
+}
+
+// List returns every stored script sorted by name
+func (st *Store) List() ([]*Script, error) {
+	files, err := filepath.Glob(filepath.Join(st.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make([]*Script, 0, len(files))
+	for _, file := range files {
+		s, err := st.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, s)
+	}
+
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Name < scripts[j].Name
+	})
+	return scripts, nil
+}
+
+// Delete removes a stored script
+func (st *Store) Delete(name string) error {
+	if !st.Exists(name) {
+		return fault.NotFound("script %s not found", name)
+	}
+	return os.Remove(st.path(name))
+}
+
+// RecordRun updates the run statistics of a script
+func (st *Store) RecordRun(s *Script) error {
+	now := time.Now()
+	s.RunCount++
+	s.LastRunAt = &now
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(st.path(s.Name), data, 0644)
+}
+
+// path returns the file a script is stored in
+func (st *Store) path(name string) string {
+	return filepath.Join(st.dir, name+".json")
+}
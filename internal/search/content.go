@@ -0,0 +1,347 @@
+/**
+ * Content Search
+ *
+ * Searches file contents for a regular expression, grep style. A walker
+ * feeds files to a pool of workers that scan them in parallel; each file's
+ * matches are handed back as soon as it is done, so callers can show results
+ * while the search goes on. Binary files, files over a size limit, hidden
+ * files and paths matched by ignore files are skipped.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: content.go
+ * Description: Parallel regular expression search over file contents
+ */
+
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"ena/internal/fault"
+	"ena/internal/vfs"
+)
+
+// binarySniffLength is how much of a file is checked for NUL bytes, as git does
+const binarySniffLength = 8000
+
+// DefaultMaxFileSize is the size above which files are skipped unless told otherwise
+const DefaultMaxFileSize = 10 * 1024 * 1024
+
+// Options controls a content search
+type Options struct {
+	Pattern     *regexp.Regexp
+	Context     int      // lines shown before and after each match
+	MaxFileSize int64    // larger files are skipped; 0 means no limit
+	MaxMatches  int      // matches kept per file; 0 means all
+	Names       []string // base name globs a file must match, when any are given
+	Hidden      bool     // search hidden files and directories too
+	NoIgnore    bool     // do not read .gitignore, .ignore and .enaignore files
+	Workers     int      // files scanned at once; 0 means one per CPU
+}
+
+// Match is one matching line
+type Match struct {
+	Line   int      `json:"line"`
+	Column int      `json:"column"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// FileResult holds the matches found in one file
+type FileResult struct {
+	Path    string  `json:"path"`
+	Matches []Match `json:"matches"`
+}
+
+// Summary counts what a search looked at and skipped
+type Summary struct {
+	FilesSearched int           `json:"files_searched"`
+	FilesMatched  int           `json:"files_matched"`
+	Matches       int           `json:"matches"`
+	SkippedBinary int           `json:"skipped_binary"`
+	SkippedLarge  int           `json:"skipped_large"`
+	Errors        []string      `json:"errors,omitempty"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// Result is a finished content search, with files sorted by path
+type Result struct {
+	Pattern string       `json:"pattern"`
+	Files   []FileResult `json:"files"`
+	Summary Summary      `json:"summary"`
+}
+
+// Paths lists the files that matched
+func (r *Result) Paths() []string {
+	paths := make([]string, 0, len(r.Files))
+	for _, file := range r.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+// scanOutcome is what a worker reports about one file
+type scanOutcome struct {
+	file   FileResult
+	binary bool
+	large  bool
+	err    error
+}
+
+// Content searches the files under roots, calling found from the calling
+// goroutine for each file with matches as soon as it has been scanned
+func Content(fsys vfs.FS, roots []string, options Options, found func(FileResult)) (*Result, error) {
+	if options.Pattern == nil {
+		return nil, fmt.Errorf("no pattern to search for")
+	}
+	for _, root := range roots {
+		if _, err := fsys.Lstat(root); err != nil {
+			return nil, fmt.Errorf("Failed to search %s: %w", root, err)
+		}
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	startTime := time.Now()
+	result := &Result{Pattern: options.Pattern.String(), Files: []FileResult{}}
+
+	paths := make(chan string, workers*4)
+	outcomes := make(chan scanOutcome, workers*4)
+	walkErrors := make(chan []string, 1)
+
+	go func() {
+		defer close(paths)
+		walkErrors <- walkRoots(fsys, roots, options, paths)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				outcomes <- scanFile(fsys, path, options)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	summary := &result.Summary
+	for outcome := range outcomes {
+		switch {
+		case outcome.err != nil:
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", outcome.file.Path, outcome.err))
+			continue
+		case outcome.binary:
+			summary.SkippedBinary++
+			continue
+		case outcome.large:
+			summary.SkippedLarge++
+			continue
+		}
+
+		summary.FilesSearched++
+		if len(outcome.file.Matches) == 0 {
+			continue
+		}
+		summary.FilesMatched++
+		summary.Matches += len(outcome.file.Matches)
+		result.Files = append(result.Files, outcome.file)
+		if found != nil {
+			found(outcome.file)
+		}
+	}
+	summary.Errors = append(<-walkErrors, summary.Errors...)
+
+	sort.Slice(result.Files, func(i, j int) bool { return result.Files[i].Path < result.Files[j].Path })
+	summary.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// walkRoots sends every file under roots that the options let through,
+// returning the directories it could not read
+func walkRoots(fsys vfs.FS, roots []string, options Options, paths chan<- string) []string {
+	var errors []string
+
+	for _, root := range roots {
+		// Each directory inherits its parent's ignore lists and adds its own
+		ignores := map[string][]*ignoreList{}
+
+		vfs.Walk(fsys, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", path, err))
+				return nil
+			}
+
+			inherited := ignores[filepath.Dir(path)]
+			if path != root {
+				if !options.Hidden && strings.HasPrefix(info.Name(), ".") {
+					return skip(info)
+				}
+				if info.IsDir() && info.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				if ignored(inherited, path, info.IsDir()) {
+					return skip(info)
+				}
+			}
+
+			if info.IsDir() {
+				lists := inherited
+				if !options.NoIgnore {
+					if list := loadIgnores(fsys, path); list != nil {
+						lists = append(append([]*ignoreList{}, inherited...), list)
+					}
+				}
+				ignores[path] = lists
+				return nil
+			}
+
+			if !info.Mode().IsRegular() || !matchesNames(info.Name(), options.Names) {
+				return nil
+			}
+			paths <- path
+			return nil
+		})
+	}
+
+	return errors
+}
+
+// skip leaves out a file, or a directory with everything beneath it
+func skip(info os.FileInfo) error {
+	if info.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// matchesNames reports whether name matches one of the globs, or there are none
+func matchesNames(name string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, glob := range globs {
+		if matched, _ := filepath.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFile finds the matching lines of one file
+func scanFile(fsys vfs.FS, path string, options Options) scanOutcome {
+	outcome := scanOutcome{file: FileResult{Path: path}}
+
+	info, err := fsys.Stat(path)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	if options.MaxFileSize > 0 && info.Size() > options.MaxFileSize {
+		outcome.large = true
+		return outcome
+	}
+
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	if bytes.IndexByte(data[:min(len(data), binarySniffLength)], 0) >= 0 {
+		outcome.binary = true
+		return outcome
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		location := options.Pattern.FindStringIndex(line)
+		if location == nil {
+			continue
+		}
+
+		match := Match{
+			Line:   i + 1,
+			Column: utf8.RuneCountInString(line[:location[0]]) + 1,
+			Text:   line,
+		}
+		if options.Context > 0 {
+			match.Before = contextLines(lines, i-options.Context, i)
+			match.After = contextLines(lines, i+1, i+1+options.Context)
+		}
+		outcome.file.Matches = append(outcome.file.Matches, match)
+
+		if options.MaxMatches > 0 && len(outcome.file.Matches) >= options.MaxMatches {
+			break
+		}
+	}
+	return outcome
+}
+
+// contextLines returns lines[from:to], clamped to the file
+func contextLines(lines []string, from, to int) []string {
+	from = max(from, 0)
+	to = min(to, len(lines))
+	if from >= to {
+		return nil
+	}
+	context := make([]string, 0, to-from)
+	for _, line := range lines[from:to] {
+		context = append(context, strings.TrimSuffix(line, "\r"))
+	}
+	return context
+}
+
+// sizeUnits maps size suffixes to bytes
+var sizeUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1024,
+	"kb": 1024,
+	"m":  1024 * 1024,
+	"mb": 1024 * 1024,
+	"g":  1024 * 1024 * 1024,
+	"gb": 1024 * 1024 * 1024,
+	"t":  1024 * 1024 * 1024 * 1024,
+	"tb": 1024 * 1024 * 1024 * 1024,
+}
+
+// sizePattern matches sizes like 512, 100KB or 1.5gb
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-zA-Z]*)$`)
+
+// ParseSize parses a size such as "10MB", "512k" or "2048"
+func ParseSize(value string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fault.Invalid("invalid size %q, e.g. 10MB", value)
+	}
+	multiplier, ok := sizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fault.Invalid("invalid size unit %q in %q", match[2], value)
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fault.Invalid("invalid size %q: %v", value, err)
+	}
+	return int64(number * float64(multiplier)), nil
+}
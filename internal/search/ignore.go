@@ -0,0 +1,169 @@
+/**
+ * Ignore Files
+ *
+ * Reads .gitignore, .ignore and .enaignore files so content searches skip
+ * what a project has already marked as generated or uninteresting. Patterns
+ * follow gitignore rules: a pattern without a slash matches at any depth, a
+ * leading or inner slash anchors it to the ignore file's directory, a trailing
+ * slash matches only directories, ** crosses directories and ! re-includes.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: ignore.go
+ * Description: Gitignore-style pattern parsing and matching
+ */
+
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ena/internal/vfs"
+)
+
+// ignoreFiles are the files in a directory whose patterns apply beneath it
+var ignoreFiles = []string{".gitignore", ".ignore", ".enaignore"}
+
+// ignoreRule is one pattern line of an ignore file
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreList holds the rules read from one directory's ignore files
+type ignoreList struct {
+	dir   string
+	rules []ignoreRule
+}
+
+// loadIgnores reads the ignore files in dir, returning nil when it has none
+func loadIgnores(fsys vfs.FS, dir string) *ignoreList {
+	list := &ignoreList{dir: dir}
+	for _, name := range ignoreFiles {
+		data, err := fsys.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+				list.rules = append(list.rules, rule)
+			}
+		}
+	}
+	if len(list.rules) == 0 {
+		return nil
+	}
+	return list
+}
+
+// parseIgnoreRule parses one line, skipping blanks and comments
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(.*/)?" + expr
+	}
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.pattern = pattern
+	return rule, true
+}
+
+// globToRegexp translates a gitignore glob into a regular expression
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// match reports whether the list decides path, and if so whether it is ignored
+func (l *ignoreList) match(path string, isDir bool) (ignored, decided bool) {
+	rel, err := filepath.Rel(l.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+
+	// The last matching rule wins
+	for i := len(l.rules) - 1; i >= 0; i-- {
+		rule := l.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(rel) {
+			return !rule.negate, true
+		}
+	}
+	return false, false
+}
+
+// ignored reports whether the deepest list that decides path ignores it
+func ignored(lists []*ignoreList, path string, isDir bool) bool {
+	for i := len(lists) - 1; i >= 0; i-- {
+		if ignored, decided := lists[i].match(path, isDir); decided {
+			return ignored
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+/**
+ * State Migration
+ *
+ * Older versions of Ena wrote their state next to wherever they were run, kept
+ * readline history in /tmp and backups in ~/.ena/backups. Migrate moves the
+ * files whose names mark them as Ena's out of the home directory and those
+ * fixed places once; "ena config migrate" moves everything from any other
+ * directory on request. Newer files are never overwritten.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: migrate.go
+ * Description: One-time migration of legacy state files into XDG directories
+ */
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ena/internal/backup"
+	"ena/internal/paths"
+)
+
+// migrationMarker records in the state directory that the automatic migration ran
+const migrationMarker = ".migrated"
+
+// legacyBackupConfig is the per-directory file replaced by the backup section of config.yaml
+const legacyBackupConfig = "backup_config.json"
+
+// legacyFile maps a file or directory from the old layout to its new home
+type legacyFile struct {
+	name   string
+	target func() string
+	shared bool // the name is common enough that another program may own it
+}
+
+// legacyFiles lists the state older versions kept in the working directory
+var legacyFiles = []legacyFile{
+	{"backup_metadata.json", func() string { return filepath.Join(paths.DataDir(), "backup_metadata.json") }, false},
+	{"backup_operations.json", func() string { return filepath.Join(paths.DataDir(), "backup_operations.json") }, false},
+	{"detected_apps.json", func() string { return filepath.Join(paths.DataDir(), "detected_apps.json") }, false},
+	{"organizer_rules.json", func() string { return filepath.Join(paths.DataDir(), "organizer_rules.json") }, false},
+	{"pattern_operations.json", func() string { return filepath.Join(paths.DataDir(), "pattern_operations.json") }, false},
+	{"themes", func() string { return filepath.Join(paths.DataDir(), "themes") }, true},
+	{".ena_undo_backups", func() string { return filepath.Join(paths.DataDir(), "undo_backups") }, false},
+	{"usage_analytics.json", func() string { return filepath.Join(paths.StateDir(), "usage_analytics.json") }, false},
+	{"undo_history.json", func() string { return filepath.Join(paths.StateDir(), "undo_history.json") }, false},
+	{"notifications_history.json", func() string { return filepath.Join(paths.StateDir(), "notifications_history.json") }, false},
+}
+
+// Move is one file or directory a migration moved
+type Move struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MigrationResult lists what a migration moved and what it left alone
+type MigrationResult struct {
+	Moved   []Move   `json:"moved"`
+	Skipped []string `json:"skipped"`
+}
+
+// newMigrationResult creates an empty result that encodes as empty lists
+func newMigrationResult() *MigrationResult {
+	return &MigrationResult{Moved: []Move{}, Skipped: []string{}}
+}
+
+// Migrate runs the automatic migration from the home directory once per user.
+// Only files named as Ena's are moved; the working directory is never touched.
+func Migrate() (*MigrationResult, error) {
+	marker := filepath.Join(paths.StateDir(), migrationMarker)
+	if _, err := os.Stat(marker); err == nil {
+		return newMigrationResult(), nil
+	}
+
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find home directory: %w", err)
+	}
+
+	result, err := migrate(dir, false)
+	if err != nil {
+		return result, err
+	}
+
+	stamp := fmt.Sprintf("migrated from %s at %s\n", dir, time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(paths.StateFile(migrationMarker), []byte(stamp), 0644); err != nil {
+		return result, fmt.Errorf("Failed to record migration: %w", err)
+	}
+	return result, nil
+}
+
+// MigrateFrom moves all legacy state found in dir, including themes, pattern
+// results and backup_config.json, plus the old history file and backup
+// directory, into the XDG directories
+func MigrateFrom(dir string) (*MigrationResult, error) {
+	return migrate(dir, true)
+}
+
+// migrate moves legacy state out of dir; names other programs may use too are
+// only moved when all is set
+func migrate(dir string, all bool) (*MigrationResult, error) {
+	result := newMigrationResult()
+
+	for _, file := range legacyFiles {
+		if file.shared && !all {
+			continue
+		}
+		if err := result.move(filepath.Join(dir, file.name), file.target()); err != nil {
+			return result, err
+		}
+	}
+
+	// Per-run pattern results get a directory of their own
+	if all {
+		matches, _ := filepath.Glob(filepath.Join(dir, "pattern_result_*.json"))
+		for _, match := range matches {
+			target := filepath.Join(paths.StateDir(), "pattern_results", filepath.Base(match))
+			if err := result.move(match, target); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	if err := result.move(filepath.Join(os.TempDir(), "ena_history"), filepath.Join(paths.StateDir(), "history")); err != nil {
+		return result, err
+	}
+
+	if all {
+		if err := result.migrateBackupConfig(filepath.Join(dir, legacyBackupConfig)); err != nil {
+			return result, err
+		}
+	}
+
+	if err := result.migrateBackupDirectory(); err != nil {
+		return result, err
+	}
+
+	// Undo history stored backup paths relative to the old working directory
+	rewritePaths(filepath.Join(paths.StateDir(), "undo_history.json"), map[string]string{
+		filepath.Join(dir, ".ena_undo_backups"): filepath.Join(paths.DataDir(), "undo_backups"),
+		".ena_undo_backups":                     filepath.Join(paths.DataDir(), "undo_backups"),
+	})
+
+	return result, nil
+}
+
+// move relocates a file or directory unless the target already exists
+func (r *MigrationResult) move(source, target string) error {
+	if _, err := os.Lstat(source); err != nil {
+		return nil
+	}
+	if _, err := os.Lstat(target); err == nil {
+		r.Skipped = append(r.Skipped, source)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return fmt.Errorf("Failed to create %s: %w", filepath.Dir(target), err)
+	}
+
+	// Rename fails across filesystems, e.g. from /tmp, so fall back to copying
+	if err := os.Rename(source, target); err != nil {
+		if err := copyTree(source, target); err != nil {
+			return fmt.Errorf("Failed to move %s: %w", source, err)
+		}
+		os.RemoveAll(source)
+	}
+
+	r.Moved = append(r.Moved, Move{From: source, To: target})
+	return nil
+}
+
+// migrateBackupConfig turns a legacy backup_config.json into the backup section of config.yaml
+func (r *MigrationResult) migrateBackupConfig(source string) error {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil
+	}
+	if _, err := os.Stat(Path()); err == nil {
+		r.Skipped = append(r.Skipped, source)
+		return nil
+	}
+
+	config := Default()
+	legacy := config.Backup
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("Failed to read %s: %w", source, err)
+	}
+
+	// The old default location moves to the data directory with everything else
+	if legacy.BackupDirectory == "~/.ena/backups" || legacy.BackupDirectory == legacyBackupDirectory() {
+		legacy.BackupDirectory = config.Backup.BackupDirectory
+	}
+	config.Backup = legacy
+
+	if err := Save(config, Path()); err != nil {
+		return err
+	}
+	os.Remove(source)
+
+	r.Moved = append(r.Moved, Move{From: source, To: Path()})
+	return nil
+}
+
+// migrateBackupDirectory moves ~/.ena/backups when backups use the default location
+func (r *MigrationResult) migrateBackupDirectory() error {
+	source := legacyBackupDirectory()
+	target := backup.DefaultBackupConfig().BackupDirectory
+	if source == "" || Get().Backup.BackupDirectory != target {
+		return nil
+	}
+
+	moved := len(r.Moved)
+	if err := r.move(source, target); err != nil {
+		return err
+	}
+	if len(r.Moved) == moved {
+		return nil
+	}
+
+	// Backup records hold absolute paths into the old directory
+	for _, name := range []string{"backup_metadata.json", "backup_operations.json"} {
+		rewritePaths(filepath.Join(paths.DataDir(), name), map[string]string{source: target})
+	}
+	os.Remove(filepath.Dir(source))
+	return nil
+}
+
+// legacyBackupDirectory returns the backup directory used before XDG support
+func legacyBackupDirectory() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ena", "backups")
+}
+
+// rewritePaths replaces path prefixes inside a JSON state file
+func rewritePaths(file string, replacements map[string]string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	text := string(data)
+	for from, to := range replacements {
+		// Paths appear as JSON strings, so only rewrite them at the start of a value
+		text = strings.ReplaceAll(text, quoteJSON(from+string(filepath.Separator)), quoteJSON(to+string(filepath.Separator)))
+	}
+
+	if text != string(data) {
+		os.WriteFile(file, []byte(text), 0644)
+	}
+}
+
+// quoteJSON encodes a path the way it appears at the start of a JSON string
+func quoteJSON(path string) string {
+	data, _ := json.Marshal(path)
+	return strings.TrimSuffix(string(data), `"`)
+}
+
+// copyTree copies a file or directory, preserving permissions
+func copyTree(source, target string) error {
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(target, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(dest, info.Mode().Perm())
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,368 @@
+/**
+ * Central Configuration
+ *
+ * Loads Ena's single config file, $XDG_CONFIG_HOME/ena/config.yaml, which has
+ * one section per manager. Anything missing from the file keeps the manager's
+ * built-in default, so an empty or absent file behaves exactly like before.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: settings.go
+ * Description: config.yaml loading, validation and rendering
+ */
+
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"ena/internal/backup"
+	"ena/internal/batch"
+	"ena/internal/fault"
+	"ena/internal/intent"
+	"ena/internal/notifications"
+	"ena/internal/paths"
+	"ena/internal/sinks"
+	"ena/internal/undo"
+	"ena/internal/watcher"
+)
+
+// FileName is the name of the config file inside the config directory
+const FileName = "config.yaml"
+
+// Config holds the settings of every configurable manager
+type Config struct {
+	Batch         batch.BatchConfig                `json:"batch" yaml:"batch"`
+	Backup        backup.BackupConfig              `json:"backup" yaml:"backup"`
+	Watch         watcher.WatchConfig              `json:"watch" yaml:"watch"`
+	Notifications notifications.NotificationConfig `json:"notifications" yaml:"notifications"`
+	Undo          undo.UndoConfig                  `json:"undo" yaml:"undo"`
+	Intent        intent.Grammar                   `json:"intent" yaml:"intent"` // words added to the built-in vocabulary
+	Sinks         []sinks.Config                   `json:"sinks" yaml:"sinks"`   // where events are forwarded
+}
+
+var (
+	current     *Config
+	currentOnce sync.Once
+)
+
+// Path returns the location of config.yaml
+func Path() string {
+	return paths.ConfigFile(FileName)
+}
+
+// Default returns the built-in settings of every manager
+func Default() *Config {
+	watch := watcher.DefaultWatchConfig()
+	watch.EventCallbacks = nil
+	watch.EventPriority = nil
+
+	return &Config{
+		Batch:         batch.DefaultBatchConfig(),
+		Backup:        backup.DefaultBackupConfig(),
+		Watch:         *watch,
+		Notifications: *notifications.DefaultNotificationConfig(),
+		Undo:          undo.DefaultUndoConfig(),
+		Intent: intent.Grammar{
+			Verbs:  map[intent.Action][]string{},
+			Kinds:  map[string][]string{},
+			Places: map[string]string{},
+		},
+		Sinks: []sinks.Config{},
+	}
+}
+
+// Get returns the settings loaded from config.yaml, reading the file once per
+// process. A broken file is reported on stderr and the defaults are used.
+func Get() *Config {
+	currentOnce.Do(func() {
+		config, err := Load(Path())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ Ignoring %s: %v\n", Path(), err)
+			config = Default()
+		}
+		current = config
+	})
+	return current
+}
+
+// Load reads a config file over the defaults; a missing file is not an error
+func Load(path string) (*Config, error) {
+	config := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("Failed to parse config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate rejects settings no manager can work with
+func (c *Config) Validate() error {
+	checks := []struct {
+		ok  bool
+		msg string
+	}{
+		{c.Batch.MaxConcurrency >= 1, "batch.max_concurrency must be at least 1"},
+		{c.Batch.RetryCount >= 0, "batch.retry_count must not be negative"},
+		{c.Backup.MaxBackups >= 0, "backup.max_backups must not be negative"},
+		{c.Backup.RetentionDays >= 0, "backup.retention_days must not be negative"},
+		{c.Backup.BackupDirectory != "", "backup.backup_directory must not be empty"},
+		{c.Watch.BatchSize >= 1, "watch.batch_size must be at least 1"},
+		{c.Watch.MaxRetries >= 0, "watch.max_retries must not be negative"},
+		{c.Notifications.MaxHistory >= 1, "notifications.max_history must be at least 1"},
+		{c.Notifications.LongCommandThreshold >= 0, "notifications.long_command_threshold must not be negative"},
+		{c.Undo.MaxHistorySize >= 0, "undo.max_history_size must not be negative"},
+		{c.Undo.MaxSessionAge > 0, "undo.max_session_age must be positive"},
+	}
+
+	for _, check := range checks {
+		if !check.ok {
+			return fault.Invalid("Invalid config: %s", check.msg)
+		}
+	}
+
+	if err := c.Intent.Validate(); err != nil {
+		return fault.Invalid("Invalid config: intent: %w", err)
+	}
+	if err := sinks.ValidateAll(c.Sinks); err != nil {
+		return fault.Invalid("Invalid config: sinks: %w", err)
+	}
+	return nil
+}
+
+// Save writes the settings to a config file, creating its directory
+func Save(config *Config, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("Failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(Render(config)), 0644); err != nil {
+		return fmt.Errorf("Failed to write config: %w", err)
+	}
+	return nil
+}
+
+// Render formats the settings as a commented config.yaml
+func Render(c *Config) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `# Ena configuration
+#
+# Durations use Go syntax (500ms, 5s, 24h) and sizes are in bytes. Remove a
+# setting to fall back to its default.
+#
+# Everything else Ena keeps lives outside this file:
+#   data (backups, rules, themes): %s
+#   state (history, analytics):    %s
+
+`, paths.DataDir(), paths.StateDir())
+
+	fmt.Fprintf(&b, `# Defaults for batch-delete, batch-copy and batch-move
+batch:
+  max_concurrency: %d
+  skip_errors: %t
+  dry_run: %t
+  permanent: %t # delete outright instead of moving to the trash
+  confirm_each: %t
+  progress_interval: %s
+  retry_count: %d
+  retry_delay: %s
+  preserve_timestamps: %t
+  preserve_permissions: %t
+  follow_symlinks: %t
+  exclude_patterns: %s
+  include_patterns: %s
+
+`, c.Batch.MaxConcurrency, c.Batch.SkipErrors, c.Batch.DryRun, c.Batch.Permanent, c.Batch.ConfirmEach,
+		formatDuration(c.Batch.ProgressInterval), c.Batch.RetryCount, formatDuration(c.Batch.RetryDelay),
+		c.Batch.PreserveTimestamps, c.Batch.PreservePermissions, c.Batch.FollowSymlinks,
+		formatValue(c.Batch.ExcludePatterns), formatValue(c.Batch.IncludePatterns))
+
+	fmt.Fprintf(&b, `# Backups created before destructive operations
+backup:
+  enabled: %t
+  max_backups: %d
+  retention_days: %d
+  compression: %t
+  encryption: %t
+  backup_directory: %s
+  auto_cleanup: %t
+  verify_checksums: %t
+  exclude_patterns: %s
+  include_patterns: %s
+  max_backup_size: %d
+  min_free_space: %d
+
+`, c.Backup.Enabled, c.Backup.MaxBackups, c.Backup.RetentionDays, c.Backup.Compression,
+		c.Backup.Encryption, formatValue(c.Backup.BackupDirectory), c.Backup.AutoCleanup,
+		c.Backup.VerifyChecksums, formatValue(c.Backup.ExcludePatterns), formatValue(c.Backup.IncludePatterns),
+		c.Backup.MaxBackupSize, c.Backup.MinFreeSpace)
+
+	fmt.Fprintf(&b, `# File watching started with "ena watch start"
+watch:
+  recursive: %t
+  include_hidden: %t
+  file_extensions: %s
+  exclude_patterns: %s
+  debounce_time: %s
+  debug_mode: %t
+  log_ignored_events: %t
+  batch_events: %t
+  batch_size: %d
+  batch_timeout: %s
+  metrics_enabled: %t
+  error_recovery: %t
+  max_retries: %d
+  retry_delay: %s
+
+`, c.Watch.Recursive, c.Watch.IncludeHidden, formatValue(c.Watch.FileExtensions),
+		formatValue(c.Watch.ExcludePatterns), formatDuration(c.Watch.DebounceTime), c.Watch.DebugMode,
+		c.Watch.LogIgnoredEvents, c.Watch.BatchEvents, c.Watch.BatchSize, formatDuration(c.Watch.BatchTimeout),
+		c.Watch.MetricsEnabled, c.Watch.ErrorRecovery, c.Watch.MaxRetries, formatDuration(c.Watch.RetryDelay))
+
+	fmt.Fprintf(&b, `# Desktop notifications
+notifications:
+  enabled: %t
+  default_duration: %s
+  max_history: %d
+  sound_enabled: %t
+  icon_path: %s
+  timeout: %s
+  # Work that notifies on its own when it finishes or fails
+  automatic:
+    batch: %t
+    patterns: %t     # execute-all
+    backups: %t
+    downloads: %t
+    app_scans: %t
+    long_commands: %t
+  # Interactive commands running at least this long notify when done; 0 never
+  long_command_threshold: %s
+
+`, c.Notifications.Enabled, formatDuration(c.Notifications.DefaultDuration), c.Notifications.MaxHistory,
+		c.Notifications.SoundEnabled, formatValue(c.Notifications.IconPath), formatDuration(c.Notifications.Timeout),
+		c.Notifications.Automatic.Batch, c.Notifications.Automatic.Patterns, c.Notifications.Automatic.Backups,
+		c.Notifications.Automatic.Downloads, c.Notifications.Automatic.AppScans, c.Notifications.Automatic.LongCommands,
+		formatDuration(c.Notifications.LongCommandThreshold))
+
+	fmt.Fprintf(&b, `# Undo history limits
+undo:
+  max_history_size: %d
+  max_session_age: %s
+
+`, c.Undo.MaxHistorySize, formatDuration(c.Undo.MaxSessionAge))
+
+	fmt.Fprintf(&b, `# Extra vocabulary for "ena do", added to the built-in words. For example:
+#   verbs:  {"move": ["stash"], "delete": ["bin"]}
+#   kinds:  {"ebooks": [".epub", ".mobi"]}
+#   places: {"archive": "~/Documents/Archive"}
+intent:
+  verbs: %s
+  kinds: %s
+  places: %s
+
+`, formatValue(c.Intent.Verbs), formatValue(c.Intent.Kinds), formatValue(c.Intent.Places))
+
+	b.WriteString(`# Where events are forwarded; "ena events topics" lists them. For example:
+#   - name: ci
+#     type: webhook                  # POST the event as JSON
+#     url: https://example.com/ena
+#     secret: change-me              # sign the body, see X-Ena-Signature-256
+#     events: ["batch.job_completed", "backup.backup_corrupted"]
+#   - name: log
+#     type: command                  # run with the event's JSON on stdin
+#     command: cat >> ~/ena-events.jsonl
+#     events: ["*.operation_failed", "watcher.error"]
+# Failed deliveries are tried max_attempts times (default 4), waiting
+# retry_delay (default 1s) and doubling it; each attempt may take timeout (10s).
+`)
+	if len(c.Sinks) == 0 {
+		b.WriteString("sinks: []\n")
+		return b.String()
+	}
+
+	b.WriteString("sinks:\n")
+	for _, sink := range c.Sinks {
+		fmt.Fprintf(&b, "  - name: %s\n    type: %s\n", formatValue(sink.Name), sink.Type)
+		if sink.URL != "" {
+			fmt.Fprintf(&b, "    url: %s\n", formatValue(sink.URL))
+		}
+		if sink.Secret != "" {
+			fmt.Fprintf(&b, "    secret: %s\n", formatValue(sink.Secret))
+		}
+		if len(sink.Headers) > 0 {
+			fmt.Fprintf(&b, "    headers: %s\n", formatValue(sink.Headers))
+		}
+		if sink.Command != "" {
+			fmt.Fprintf(&b, "    command: %s\n", formatValue(sink.Command))
+		}
+		fmt.Fprintf(&b, "    events: %s\n", formatValue(sink.Events))
+		if sink.MaxAttempts != 0 {
+			fmt.Fprintf(&b, "    max_attempts: %d\n", sink.MaxAttempts)
+		}
+		if sink.RetryDelay != 0 {
+			fmt.Fprintf(&b, "    retry_delay: %s\n", formatDuration(sink.RetryDelay))
+		}
+		if sink.Timeout != 0 {
+			fmt.Fprintf(&b, "    timeout: %s\n", formatDuration(sink.Timeout))
+		}
+		if sink.Disabled {
+			b.WriteString("    disabled: true\n")
+		}
+	}
+
+	return b.String()
+}
+
+// formatDuration prints a duration without trailing zero units, e.g. 24h instead of 24h0m0s
+func formatDuration(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// formatValue prints strings and lists in JSON syntax, which YAML reads back unchanged
+func formatValue(value interface{}) string {
+	if list, ok := value.([]string); ok && list == nil {
+		value = []string{}
+	}
+
+	// Shell commands are full of <, > and &, which must stay readable
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return `""`
+	}
+	return strings.TrimSuffix(data.String(), "\n")
+}
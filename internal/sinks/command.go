@@ -0,0 +1,63 @@
+/**
+ * Command Sink
+ *
+ * Runs a shell command for each event, with the event's JSON on stdin and its
+ * topic, ID and source in ENA_EVENT_TOPIC, ENA_EVENT_ID and ENA_EVENT_SOURCE.
+ * A non-zero exit status counts as a failed delivery.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: command.go
+ * Description: Local command delivery of events
+ */
+
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// runCommand runs the sink's command once with the event on stdin
+func runCommand(config Config, envelope Envelope, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", config.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", config.Command)
+	}
+
+	var output bytes.Buffer
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(),
+		"ENA_EVENT_TOPIC="+string(envelope.Topic),
+		"ENA_EVENT_ID="+envelope.ID,
+		"ENA_EVENT_SOURCE="+envelope.Source,
+	)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("command timed out after %v", config.Timeout)
+		}
+
+		text := strings.TrimSpace(output.String())
+		if len(text) > 500 {
+			text = text[:500] + "..."
+		}
+		if text == "" {
+			return err
+		}
+		return fmt.Errorf("%v: %s", err, text)
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+/**
+ * Event Sinks
+ *
+ * Forwards events from the bus to the user's own tooling. A webhook sink
+ * POSTs each event as JSON, optionally signed with HMAC-SHA256; a command sink
+ * runs a shell command with the event on stdin. Sinks are configured in the
+ * sinks section of config.yaml, each with the topic patterns it wants, and
+ * retry failed deliveries with exponential backoff.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: sinks.go
+ * Description: Configurable event sinks fed from the event bus
+ */
+
+package sinks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/output"
+)
+
+// Kind is how a sink delivers events
+type Kind string
+
+const (
+	KindWebhook Kind = "webhook"
+	KindCommand Kind = "command"
+)
+
+// TopicTest is the topic of the events "ena events test" sends
+var TopicTest = events.Define("sinks.test", "Sent by \"ena events test\" to check a sink")
+
+// Defaults for settings a sink leaves out
+const (
+	DefaultMaxAttempts = 4
+	DefaultRetryDelay  = time.Second
+	DefaultTimeout     = 10 * time.Second
+)
+
+// Config describes one sink in config.yaml
+type Config struct {
+	Name        string            `json:"name" yaml:"name"`
+	Type        Kind              `json:"type" yaml:"type"`
+	Events      []string          `json:"events" yaml:"events"`                       // topic patterns; none means every event
+	URL         string            `json:"url,omitempty" yaml:"url,omitempty"`         // webhook endpoint
+	Secret      string            `json:"secret,omitempty" yaml:"secret,omitempty"`   // signs webhook bodies when set
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"` // extra webhook headers
+	Command     string            `json:"command,omitempty" yaml:"command,omitempty"` // run by the shell
+	MaxAttempts int               `json:"max_attempts" yaml:"max_attempts"`           // tries per event, 1 for no retries
+	RetryDelay  time.Duration     `json:"retry_delay" yaml:"retry_delay"`             // first backoff, doubled per retry
+	Timeout     time.Duration     `json:"timeout" yaml:"timeout"`                     // per attempt
+	Disabled    bool              `json:"disabled" yaml:"disabled"`
+}
+
+// Validate rejects a sink that could never deliver
+func (c Config) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("sink needs a name")
+	}
+
+	switch c.Type {
+	case KindWebhook:
+		target, err := url.Parse(c.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("sink %s: url must be an http or https URL", c.Name)
+		}
+	case KindCommand:
+		if c.Command == "" {
+			return fmt.Errorf("sink %s: command must not be empty", c.Name)
+		}
+	default:
+		return fmt.Errorf("sink %s: type must be webhook or command", c.Name)
+	}
+
+	for _, pattern := range c.Events {
+		if !events.ValidPattern(pattern) {
+			return fault.Invalid("sink %s: invalid event pattern %q", c.Name, pattern)
+		}
+	}
+	if c.MaxAttempts < 0 || c.RetryDelay < 0 || c.Timeout < 0 {
+		return fmt.Errorf("sink %s: max_attempts, retry_delay and timeout must not be negative", c.Name)
+	}
+	return nil
+}
+
+// ValidateAll checks every sink and that their names are unique
+func ValidateAll(configs []Config) error {
+	names := make(map[string]bool)
+	for _, config := range configs {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		if names[config.Name] {
+			return fmt.Errorf("sink name %s is used twice", config.Name)
+		}
+		names[config.Name] = true
+	}
+	return nil
+}
+
+// withDefaults fills in the settings a sink left out
+func (c Config) withDefaults() Config {
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.RetryDelay == 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// wants reports whether the sink's filter selects topic
+func (c Config) wants(topic events.Topic) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, pattern := range c.Events {
+		if events.Matches(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Envelope is the JSON a sink receives for each event
+type Envelope struct {
+	ID        string       `json:"id"`
+	Topic     events.Topic `json:"topic"`
+	Source    string       `json:"source"`
+	Message   string       `json:"message,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+	Payload   interface{}  `json:"payload,omitempty"`
+}
+
+// Delivery is the outcome of sending one event to one sink
+type Delivery struct {
+	Sink     string        `json:"sink"`
+	ID       string        `json:"id"`
+	Topic    events.Topic  `json:"topic"`
+	Attempts int           `json:"attempts"`
+	Status   int           `json:"status,omitempty"` // HTTP status of the last webhook attempt
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Stats describes a sink and what it has delivered
+type Stats struct {
+	Name         string     `json:"name"`
+	Type         Kind       `json:"type"`
+	Target       string     `json:"target"`
+	Events       []string   `json:"events"`
+	Disabled     bool       `json:"disabled"`
+	Delivered    int64      `json:"delivered"`
+	Failed       int64      `json:"failed"`
+	Dropped      int64      `json:"dropped"` // events the sink fell too far behind to queue
+	LastDelivery *time.Time `json:"last_delivery,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+// sink is a configured sink with its counters
+type sink struct {
+	config       Config
+	subscription *events.Subscription
+	mutex        sync.Mutex
+	delivered    int64
+	failed       int64
+	lastDelivery *time.Time
+	lastError    string
+}
+
+// Manager delivers bus events to the configured sinks
+type Manager struct {
+	sinks    []*sink
+	client   *http.Client
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// errPermanent marks a failure retrying cannot fix, such as a 404
+var errPermanent = errors.New("not retried")
+
+// NewManager creates a manager for the given sinks
+func NewManager(configs []Config) *Manager {
+	m := &Manager{
+		client: &http.Client{},
+		stop:   make(chan struct{}),
+	}
+	for _, config := range configs {
+		m.sinks = append(m.sinks, &sink{config: config.withDefaults()})
+	}
+	return m
+}
+
+// Attach subscribes every enabled sink to the bus. Each sink has its own
+// queue, and drops events rather than slow the managers publishing them.
+func (m *Manager) Attach(bus *events.Bus) error {
+	for _, s := range m.sinks {
+		if s.config.Disabled {
+			continue
+		}
+
+		s := s
+		options := events.SubscribeOptions{Name: "sink " + s.config.Name, Buffer: 1024, Overflow: events.OverflowDrop}
+		subscription, err := bus.SubscribeWithOptions("*", func(event events.Event) {
+			if s.config.wants(event.Topic) {
+				m.deliver(s, event)
+			}
+		}, options)
+		if err != nil {
+			return fmt.Errorf("Failed to attach sink %s: %w", s.config.Name, err)
+		}
+		s.subscription = subscription
+	}
+	return nil
+}
+
+// Stop ends retries in progress; events still queued get one attempt each
+func (m *Manager) Stop() error {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	return nil
+}
+
+// List returns every configured sink with its counters
+func (m *Manager) List() []Stats {
+	var list []Stats
+	for _, s := range m.sinks {
+		list = append(list, s.stats())
+	}
+	return list
+}
+
+// Send delivers event to the named sink straight away, ignoring its filter
+func (m *Manager) Send(name string, event events.Event) (Delivery, error) {
+	for _, s := range m.sinks {
+		if s.config.Name == name {
+			return m.deliver(s, event), nil
+		}
+	}
+	return Delivery{}, fmt.Errorf("no sink named %s", name)
+}
+
+// deliver sends one event to one sink, retrying with backoff
+func (m *Manager) deliver(s *sink, event events.Event) Delivery {
+	start := time.Now()
+	envelope := Envelope{
+		ID:        fmt.Sprintf("event_%d", start.UnixNano()),
+		Topic:     event.Topic,
+		Source:    event.Topic.Source(),
+		Message:   event.Message,
+		Timestamp: event.Timestamp,
+		Payload:   event.Payload,
+	}
+	delivery := Delivery{Sink: s.config.Name, ID: envelope.ID, Topic: event.Topic}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("encoding event: %v", err)
+		s.record(delivery)
+		return delivery
+	}
+
+	delay := s.config.RetryDelay
+	for delivery.Attempts < s.config.MaxAttempts {
+		delivery.Attempts++
+		switch s.config.Type {
+		case KindWebhook:
+			delivery.Status, err = m.post(s.config, envelope, body)
+		default:
+			err = runCommand(s.config, envelope, body)
+		}
+		if err == nil || errors.Is(err, errPermanent) || delivery.Attempts == s.config.MaxAttempts {
+			break
+		}
+
+		// Stopping gives up on retries so exit is never held up by a dead endpoint
+		select {
+		case <-m.stop:
+			delivery.Attempts = s.config.MaxAttempts
+		case <-time.After(delay):
+			delay *= 2
+		}
+	}
+
+	delivery.Duration = time.Since(start)
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	s.record(delivery)
+	return delivery
+}
+
+// record counts a delivery and reports failures on stderr, which is the
+// daemon log when the daemon delivers
+func (s *sink) record(delivery Delivery) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.lastDelivery = &now
+	if delivery.Error == "" {
+		s.delivered++
+		return
+	}
+
+	s.failed++
+	s.lastError = delivery.Error
+	output.Fprintf(os.Stderr, "⚠️  Sink %s could not deliver %s after %d attempts: %s\n",
+		s.config.Name, delivery.Topic, delivery.Attempts, delivery.Error)
+}
+
+// stats snapshots the sink's counters
+func (s *sink) stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := Stats{
+		Name:         s.config.Name,
+		Type:         s.config.Type,
+		Target:       s.config.URL,
+		Events:       s.config.Events,
+		Disabled:     s.config.Disabled,
+		Delivered:    s.delivered,
+		Failed:       s.failed,
+		LastDelivery: s.lastDelivery,
+		LastError:    s.lastError,
+	}
+	if s.config.Type == KindCommand {
+		stats.Target = s.config.Command
+	}
+	if s.subscription != nil {
+		stats.Dropped = s.subscription.Stats().Dropped
+	}
+	return stats
+}
@@ -0,0 +1,182 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ena/internal/events"
+)
+
+// receiver is a webhook endpoint that answers with a scripted list of statuses
+type receiver struct {
+	mutex    sync.Mutex
+	statuses []int // one per request; the last repeats
+	requests []*http.Request
+	bodies   [][]byte
+	times    []time.Time
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	r.times = append(r.times, time.Now())
+
+	status := r.statuses[len(r.statuses)-1]
+	if len(r.requests) <= len(r.statuses) {
+		status = r.statuses[len(r.requests)-1]
+	}
+	w.WriteHeader(status)
+}
+
+// serve starts a receiver and a manager with one webhook sink pointed at it
+func serve(t *testing.T, config Config, statuses ...int) (*receiver, *Manager) {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	config.Name = "hook"
+	config.Type = KindWebhook
+	config.URL = server.URL
+	if err := config.Validate(); err != nil {
+		t.Fatalf("invalid sink: %v", err)
+	}
+	m := NewManager([]Config{config})
+	t.Cleanup(func() { m.Stop() })
+	return r, m
+}
+
+func TestWebhookRetriesServerErrorsWithBackoff(t *testing.T) {
+	delay := 20 * time.Millisecond
+	r, m := serve(t, Config{MaxAttempts: 4, RetryDelay: delay},
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+
+	delivery, err := m.Send("hook", events.New(TopicTest, "hello", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Error != "" || delivery.Status != http.StatusOK {
+		t.Fatalf("delivery failed with status %d: %s", delivery.Status, delivery.Error)
+	}
+	if delivery.Attempts != 4 || len(r.requests) != 4 {
+		t.Fatalf("made %d attempts and %d requests, want 4", delivery.Attempts, len(r.requests))
+	}
+
+	// Each wait doubles the one before it
+	for i := 1; i < len(r.times); i++ {
+		want := delay << (i - 1)
+		if gap := r.times[i].Sub(r.times[i-1]); gap < want {
+			t.Errorf("retry %d came after %v, want at least %v", i, gap, want)
+		}
+	}
+
+	stats := m.List()[0]
+	if stats.Delivered != 1 || stats.Failed != 0 {
+		t.Errorf("stats count %d delivered and %d failed, want 1 and 0", stats.Delivered, stats.Failed)
+	}
+}
+
+func TestWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	r, m := serve(t, Config{MaxAttempts: 3, RetryDelay: time.Millisecond}, http.StatusInternalServerError)
+
+	delivery, _ := m.Send("hook", events.New(TopicTest, "hello", nil))
+	if delivery.Error == "" || delivery.Status != http.StatusInternalServerError {
+		t.Fatalf("delivery = status %d, error %q; want a 500 failure", delivery.Status, delivery.Error)
+	}
+	if len(r.requests) != 3 {
+		t.Errorf("made %d requests, want 3", len(r.requests))
+	}
+	if stats := m.List()[0]; stats.Failed != 1 || stats.LastError == "" {
+		t.Errorf("stats count %d failed with last error %q", stats.Failed, stats.LastError)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		requests int
+	}{
+		{http.StatusBadRequest, 1},
+		{http.StatusNotFound, 1},
+		{http.StatusGone, 1},
+		{http.StatusRequestTimeout, 3},
+		{http.StatusTooManyRequests, 3},
+	}
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			r, m := serve(t, Config{MaxAttempts: 3, RetryDelay: time.Millisecond}, test.status)
+
+			delivery, _ := m.Send("hook", events.New(TopicTest, "hello", nil))
+			if delivery.Status != test.status || delivery.Error == "" {
+				t.Errorf("delivery = status %d, error %q; want a %d failure", delivery.Status, delivery.Error, test.status)
+			}
+			if len(r.requests) != test.requests || delivery.Attempts != test.requests {
+				t.Errorf("made %d requests in %d attempts, want %d", len(r.requests), delivery.Attempts, test.requests)
+			}
+		})
+	}
+}
+
+func TestWebhookSignsBody(t *testing.T) {
+	secret := "s3cret"
+	r, m := serve(t, Config{Secret: secret, Headers: map[string]string{"X-Extra": "yes"}}, http.StatusNoContent)
+
+	event := events.New(TopicTest, "hello", map[string]string{"path": "/tmp/a"})
+	delivery, _ := m.Send("hook", event)
+	if delivery.Error != "" {
+		t.Fatalf("delivery failed: %s", delivery.Error)
+	}
+
+	request, body := r.requests[0], r.bodies[0]
+	if got, want := request.Header.Get(HeaderSignature), Sign(secret, body); got != want {
+		t.Errorf("signature header = %q, want %q", got, want)
+	}
+	if got := request.Header.Get(HeaderSignature); got == Sign("other", body) {
+		t.Errorf("signature %q also matches another secret", got)
+	}
+	if got := request.Header.Get(HeaderEvent); got != string(TopicTest) {
+		t.Errorf("event header = %q, want %q", got, TopicTest)
+	}
+	if got := request.Header.Get(HeaderDelivery); got != delivery.ID {
+		t.Errorf("delivery header = %q, want %q", got, delivery.ID)
+	}
+	if got := request.Header.Get("X-Extra"); got != "yes" {
+		t.Errorf("extra header = %q, want %q", got, "yes")
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("body is not an envelope: %v", err)
+	}
+	if envelope.ID != delivery.ID || envelope.Topic != TopicTest || envelope.Message != "hello" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+}
+
+func TestWebhookUnsignedWithoutSecret(t *testing.T) {
+	r, m := serve(t, Config{}, http.StatusOK)
+
+	if delivery, _ := m.Send("hook", events.New(TopicTest, "hello", nil)); delivery.Error != "" {
+		t.Fatalf("delivery failed: %s", delivery.Error)
+	}
+	if got := r.requests[0].Header.Get(HeaderSignature); got != "" {
+		t.Errorf("unsigned sink sent signature %q", got)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '{"a":1}' | openssl dgst -sha256 -hmac key
+	const want = "sha256=88a67f24bbcdaed0e6c997404bb79a743baf44c6bab2f4c27328e3009d22e342"
+	if got := Sign("key", []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+/**
+ * Webhook Sink
+ *
+ * POSTs events as JSON. With a secret, the body is signed with HMAC-SHA256
+ * and the signature sent as "X-Ena-Signature-256: sha256=<hex>", so the
+ * receiver can check the event came from Ena untouched.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: webhook.go
+ * Description: HTTP delivery of events with HMAC signing
+ */
+
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Headers set on every webhook request
+const (
+	HeaderEvent     = "X-Ena-Event"
+	HeaderDelivery  = "X-Ena-Delivery"
+	HeaderSignature = "X-Ena-Signature-256"
+)
+
+// Sign returns the signature header value for body under secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post sends one attempt, returning the HTTP status. Client errors other
+// than timeouts and rate limits are permanent.
+func (m *Manager) post(config Config, envelope Envelope, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errPermanent, err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "ena")
+	request.Header.Set(HeaderEvent, string(envelope.Topic))
+	request.Header.Set(HeaderDelivery, envelope.ID)
+	for name, value := range config.Headers {
+		request.Header.Set(name, value)
+	}
+	if config.Secret != "" {
+		request.Header.Set(HeaderSignature, Sign(config.Secret, body))
+	}
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response.StatusCode, nil
+	}
+
+	err = fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(reply)))
+	if response.StatusCode >= 400 && response.StatusCode < 500 &&
+		response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests {
+		err = fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	return response.StatusCode, err
+}
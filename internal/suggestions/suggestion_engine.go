@@ -18,6 +18,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"ena/internal/input"
 )
 
 // SuggestionEngine analyzes patterns and generates intelligent suggestions
@@ -620,8 +622,22 @@ func (se *SuggestionEngine) getSequenceFrequency(sequence []string, commandHisto
 }
 
 func (se *SuggestionEngine) generateWorkflowCommand(sequence []string) string {
-	// Generate a simple script command for the sequence
-	return fmt.Sprintf("script create workflow_%d --commands '%s'", len(sequence), strings.Join(sequence, ","))
+	// Name the script after its commands so different sequences do not collide
+	name := "workflow"
+	for _, command := range sequence {
+		name += "_" + strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' {
+				return r
+			}
+			return '-'
+		}, command)
+	}
+
+	line := fmt.Sprintf("script create %s --force", name)
+	for _, command := range sequence {
+		line += " --commands " + input.Quote(command)
+	}
+	return line
 }
 
 func (se *SuggestionEngine) filterAndRankSuggestions(suggestions []SmartSuggestion) []SmartSuggestion {
@@ -20,6 +20,8 @@ import (
 	"sort"
 	"sync"
 	"time"
+
+	"ena/internal/paths"
 )
 
 // CommandUsage represents a single command execution
@@ -94,7 +96,7 @@ func NewUsageAnalytics() *UsageAnalytics {
 		fileOperations:   make([]FileOperation, 0),
 		patterns:         make([]UsagePattern, 0),
 		suggestions:      make([]SmartSuggestion, 0),
-		dataFile:         "usage_analytics.json",
+		dataFile:         paths.StateFile("usage_analytics.json"),
 		maxHistorySize:   10000,
 		patternThreshold: 0.7,
 		suggestionEngine: NewSuggestionEngine(),
@@ -384,7 +386,7 @@ func (ua *UsageAnalytics) loadData() error {
 
 	data, err := os.ReadFile(ua.dataFile)
 	if err != nil {
-		return fmt.Errorf("failed to read analytics data: %v", err)
+		return fmt.Errorf("failed to read analytics data: %w", err)
 	}
 
 	var analyticsData struct {
@@ -395,7 +397,7 @@ func (ua *UsageAnalytics) loadData() error {
 	}
 
 	if err := json.Unmarshal(data, &analyticsData); err != nil {
-		return fmt.Errorf("failed to parse analytics data: %v", err)
+		return fmt.Errorf("failed to parse analytics data: %w", err)
 	}
 
 	ua.commandHistory = analyticsData.CommandHistory
@@ -423,11 +425,11 @@ func (ua *UsageAnalytics) saveData() error {
 
 	data, err := json.MarshalIndent(analyticsData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal analytics data: %v", err)
+		return fmt.Errorf("failed to marshal analytics data: %w", err)
 	}
 
 	if err := os.WriteFile(ua.dataFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write analytics data: %v", err)
+		return fmt.Errorf("failed to write analytics data: %w", err)
 	}
 
 	return nil
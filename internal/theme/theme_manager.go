@@ -24,6 +24,9 @@ import (
 
 	"github.com/fatih/color"
 	gookitcolor "github.com/gookit/color"
+
+	"ena/internal/fault"
+	"ena/internal/paths"
 )
 
 // ColorScheme represents a complete color scheme
@@ -104,7 +107,7 @@ func NewThemeManager() *ThemeManager {
 		colorEnabled: true,
 		autoDetect:   true,
 		colorCache:   make(map[string]string),
-		themePath:    "themes",
+		themePath:    filepath.Join(paths.DataDir(), "themes"),
 		validators:   make([]ThemeValidator, 0),
 	}
 
@@ -529,7 +532,7 @@ func (tm *ThemeManager) SetTheme(themeName string) error {
 	defer tm.mutex.Unlock()
 
 	if _, exists := tm.themes[themeName]; !exists {
-		return fmt.Errorf("theme '%s' does not exist", themeName)
+		return fault.NotFound("theme '%s' does not exist", themeName)
 	}
 
 	tm.currentTheme = themeName
@@ -550,7 +553,7 @@ func (tm *ThemeManager) GetTheme(themeName string) (*ColorScheme, error) {
 
 	theme, exists := tm.themes[themeName]
 	if !exists {
-		return nil, fmt.Errorf("theme '%s' does not exist", themeName)
+		return nil, fault.NotFound("theme '%s' does not exist", themeName)
 	}
 
 	return theme, nil
@@ -685,7 +688,7 @@ func (tm *ThemeManager) GetThemeInfo(themeName string) (map[string]interface{},
 
 	theme, exists := tm.themes[themeName]
 	if !exists {
-		return nil, fmt.Errorf("theme '%s' does not exist", themeName)
+		return nil, fault.NotFound("theme '%s' does not exist", themeName)
 	}
 
 	return map[string]interface{}{
@@ -754,7 +757,7 @@ func (v *HexColorValidator) Validate(theme *ColorScheme) error {
 
 	for colorName, colorValue := range theme.Colors {
 		if !hexRegex.MatchString(colorValue) {
-			return fmt.Errorf("invalid hex color '%s' for color element '%s'", colorValue, colorName)
+			return fault.Invalid("invalid hex color '%s' for color element '%s'", colorValue, colorName)
 		}
 	}
 	return nil
@@ -787,13 +790,13 @@ func (tm *ThemeManager) SetColor(themeName, element, hexValue string) error {
 
 	theme, exists := tm.themes[themeName]
 	if !exists {
-		return fmt.Errorf("theme '%s' does not exist", themeName)
+		return fault.NotFound("theme '%s' does not exist", themeName)
 	}
 
 	// Validate hex color format
 	hexRegex := regexp.MustCompile(`^#([A-Fa-f0-9]{6}|[A-Fa-f0-9]{3})$`)
 	if !hexRegex.MatchString(hexValue) {
-		return fmt.Errorf("invalid hex color format: %s", hexValue)
+		return fault.Invalid("invalid hex color format: %s", hexValue)
 	}
 
 	// Update the color
@@ -825,17 +828,17 @@ func (tm *ThemeManager) SaveTheme(themeName string) error {
 	tm.mutex.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("theme '%s' does not exist", themeName)
+		return fault.NotFound("theme '%s' does not exist", themeName)
 	}
 
 	// Validate theme before saving
 	if err := tm.ValidateTheme(theme); err != nil {
-		return fmt.Errorf("theme validation failed: %v", err)
+		return fmt.Errorf("theme validation failed: %w", err)
 	}
 
 	// Create themes directory if it doesn't exist
 	if err := os.MkdirAll(tm.themePath, 0755); err != nil {
-		return fmt.Errorf("failed to create themes directory: %v", err)
+		return fmt.Errorf("failed to create themes directory: %w", err)
 	}
 
 	// Create theme data for export
@@ -852,11 +855,11 @@ func (tm *ThemeManager) SaveTheme(themeName string) error {
 	filename := filepath.Join(tm.themePath, themeName+".json")
 	data, err := json.MarshalIndent(themeData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal theme data: %v", err)
+		return fmt.Errorf("failed to marshal theme data: %w", err)
 	}
 
 	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write theme file: %v", err)
+		return fmt.Errorf("failed to write theme file: %w", err)
 	}
 
 	return nil
@@ -866,12 +869,12 @@ func (tm *ThemeManager) SaveTheme(themeName string) error {
 func (tm *ThemeManager) LoadTheme(filename string) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to read theme file: %v", err)
+		return fmt.Errorf("failed to read theme file: %w", err)
 	}
 
 	var themeData map[string]interface{}
 	if err := json.Unmarshal(data, &themeData); err != nil {
-		return fmt.Errorf("failed to parse theme file: %v", err)
+		return fmt.Errorf("failed to parse theme file: %w", err)
 	}
 
 	// Extract theme information
@@ -913,7 +916,7 @@ func (tm *ThemeManager) LoadTheme(filename string) error {
 
 	// Validate theme
 	if err := tm.ValidateTheme(theme); err != nil {
-		return fmt.Errorf("loaded theme validation failed: %v", err)
+		return fmt.Errorf("loaded theme validation failed: %w", err)
 	}
 
 	// Add theme to manager
@@ -961,7 +964,7 @@ func (tm *ThemeManager) CreateCustomTheme(name, description string, isDark bool,
 
 	// Validate theme
 	if err := tm.ValidateTheme(theme); err != nil {
-		return fmt.Errorf("theme validation failed: %v", err)
+		return fmt.Errorf("theme validation failed: %w", err)
 	}
 
 	tm.themes[name] = theme
@@ -979,7 +982,7 @@ func (tm *ThemeManager) CreateCustomTheme(name, description string, isDark bool,
 func (tm *ThemeManager) saveThemeToDisk(theme *ColorScheme) error {
 	// Create themes directory if it doesn't exist
 	if err := os.MkdirAll(tm.themePath, 0755); err != nil {
-		return fmt.Errorf("failed to create themes directory: %v", err)
+		return fmt.Errorf("failed to create themes directory: %w", err)
 	}
 
 	// Create theme data for export
@@ -996,11 +999,11 @@ func (tm *ThemeManager) saveThemeToDisk(theme *ColorScheme) error {
 	filename := filepath.Join(tm.themePath, theme.Name+".json")
 	data, err := json.MarshalIndent(themeData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal theme data: %v", err)
+		return fmt.Errorf("failed to marshal theme data: %w", err)
 	}
 
 	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write theme file: %v", err)
+		return fmt.Errorf("failed to write theme file: %w", err)
 	}
 
 	return nil
@@ -1012,7 +1015,7 @@ func (tm *ThemeManager) DeleteTheme(themeName string) error {
 	defer tm.mutex.Unlock()
 
 	if _, exists := tm.themes[themeName]; !exists {
-		return fmt.Errorf("theme '%s' does not exist", themeName)
+		return fault.NotFound("theme '%s' does not exist", themeName)
 	}
 
 	// Don't allow deletion of built-in themes
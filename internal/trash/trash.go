@@ -0,0 +1,579 @@
+/**
+ * Trash
+ *
+ * Moves deleted files into the freedesktop.org trash instead of removing
+ * them, so file managers show them and they can be restored. Files on the
+ * home volume go to $XDG_DATA_HOME/Trash; files on other mounted volumes go
+ * to that volume's .Trash/$uid or .Trash-$uid directory, so deleting never
+ * copies data between disks when it can be avoided. Each trashed file gets a
+ * .trashinfo file recording where it came from and when it was deleted.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: trash.go
+ * Description: Freedesktop.org trash directories, trashing, listing and restoring
+ */
+
+package trash
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"ena/internal/paths"
+	"ena/internal/vfs"
+)
+
+// infoDateLayout is how a .trashinfo file records the deletion time, in local time
+const infoDateLayout = "2006-01-02T15:04:05"
+
+// Item is one file or directory in a trash
+type Item struct {
+	Name      string    `json:"name"` // entry in the trash's files directory
+	Path      string    `json:"path"` // where it was deleted from
+	DeletedAt time.Time `json:"deleted_at"`
+	Size      int64     `json:"size"`
+	IsDir     bool      `json:"is_dir"`
+	Trash     string    `json:"trash"` // trash directory holding it
+
+	dir trashDir
+}
+
+// trashDir is one trash directory. Paths in its info files are relative to
+// top, except in the home trash whose top is empty and paths absolute.
+type trashDir struct {
+	path string
+	top  string
+}
+
+func (d trashDir) filesDir() string { return filepath.Join(d.path, "files") }
+func (d trashDir) infoDir() string  { return filepath.Join(d.path, "info") }
+
+func (d trashDir) infoFile(name string) string {
+	return filepath.Join(d.infoDir(), name+".trashinfo")
+}
+
+// InfoPath returns the .trashinfo file of a path in a trash's files directory
+func InfoPath(trashed string) string {
+	dir := trashDir{path: filepath.Dir(filepath.Dir(trashed))}
+	return dir.infoFile(filepath.Base(trashed))
+}
+
+// Location returns where the item is kept in its trash directory
+func (i *Item) Location() string {
+	return filepath.Join(i.dir.filesDir(), i.Name)
+}
+
+// Can moves files into the trash directories of one user and back out
+type Can struct {
+	fs   vfs.FS
+	home trashDir
+	uid  int
+}
+
+// NewCan creates a trash for the current user working on fs
+func NewCan(fs vfs.FS) *Can {
+	return &Can{
+		fs:   fs,
+		home: trashDir{path: filepath.Join(paths.DataHome(), "Trash")},
+		uid:  os.Getuid(),
+	}
+}
+
+// HomeDir returns the home trash directory
+func (c *Can) HomeDir() string {
+	return c.home.path
+}
+
+// Put moves path, with everything beneath it, into the trash of the volume it
+// is on, falling back to the home trash
+func (c *Can) Put(path string) (*Item, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to resolve %s: %w", path, err)
+	}
+	info, err := c.fs.Lstat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if c.inTrash(abs) {
+		return nil, fmt.Errorf("%s is in a trash directory; use \"ena trash purge\" to remove it", abs)
+	}
+
+	dir, err := c.dirFor(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	size := c.size(abs, info)
+	deletedAt := time.Now()
+	name, err := c.reserve(dir, abs, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.move(abs, filepath.Join(dir.filesDir(), name)); err != nil {
+		c.fs.Remove(dir.infoFile(name))
+		return nil, err
+	}
+
+	return &Item{
+		Name:      name,
+		Path:      abs,
+		DeletedAt: deletedAt,
+		Size:      size,
+		IsDir:     info.IsDir(),
+		Trash:     dir.path,
+		dir:       dir,
+	}, nil
+}
+
+// List returns everything in the home trash and the trash directories of
+// mounted volumes, newest first
+func (c *Can) List() ([]*Item, error) {
+	var items []*Item
+	for _, dir := range c.dirs() {
+		entries, err := c.fs.ReadDir(dir.infoDir())
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".trashinfo")
+			if !ok || entry.IsDir() {
+				continue
+			}
+			// Info files left behind by an interrupted delete have nothing to restore
+			if item, err := c.load(dir, name); err == nil {
+				items = append(items, item)
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Find returns the items named query in their trash directory or deleted
+// from the path query, newest first
+func (c *Can) Find(query string) ([]*Item, error) {
+	items, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	abs, _ := filepath.Abs(query)
+	var found []*Item
+	for _, item := range items {
+		if item.Name == query || item.Path == abs {
+			found = append(found, item)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%s is not in the trash: %w", query, os.ErrNotExist)
+	}
+	return found, nil
+}
+
+// Restore moves an item back to where it was deleted from, or to dest when
+// given; a dest that is a directory receives the item under its old name.
+// It returns the path the item was restored to.
+func (c *Can) Restore(item *Item, dest string, overwrite bool) (string, error) {
+	target := item.Path
+	if dest != "" {
+		target = dest
+		if info, err := c.fs.Stat(dest); err == nil && info.IsDir() {
+			target = filepath.Join(dest, filepath.Base(item.Path))
+		}
+	}
+
+	if _, err := c.fs.Lstat(target); err == nil {
+		if !overwrite {
+			return "", fmt.Errorf("%s already exists: %w", target, os.ErrExist)
+		}
+		if err := c.fs.RemoveAll(target); err != nil {
+			return "", fmt.Errorf("Failed to replace %s: %w", target, err)
+		}
+	}
+
+	if err := c.fs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("Failed to create %s: %w", filepath.Dir(target), err)
+	}
+	if err := c.move(item.Location(), target); err != nil {
+		return "", fmt.Errorf("Failed to restore %s: %w", item.Name, err)
+	}
+
+	c.fs.Remove(item.dir.infoFile(item.Name))
+	return target, nil
+}
+
+// Purge deletes an item from the trash for good
+func (c *Can) Purge(item *Item) error {
+	// The info file goes last, so a failed purge leaves the item listed
+	if err := c.fs.RemoveAll(item.Location()); err != nil {
+		return fmt.Errorf("Failed to purge %s: %w", item.Name, err)
+	}
+	if err := c.fs.Remove(item.dir.infoFile(item.Name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Failed to purge %s: %w", item.Name, err)
+	}
+	return nil
+}
+
+// inTrash reports whether path is a trash directory or lies in one
+func (c *Can) inTrash(path string) bool {
+	if path == c.home.path || strings.HasPrefix(path, c.home.path+string(filepath.Separator)) {
+		return true
+	}
+	volumeTrash := fmt.Sprintf(".Trash-%d", c.uid)
+	for dir := path; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if name := filepath.Base(dir); name == ".Trash" || name == volumeTrash {
+			return true
+		}
+	}
+	return false
+}
+
+// dirFor picks the trash for a path: the home trash when path is on the home
+// volume or its own volume has no usable trash, otherwise the volume's trash
+func (c *Can) dirFor(path string) (trashDir, error) {
+	if err := c.prepare(c.home); err != nil {
+		return trashDir{}, fmt.Errorf("Failed to create trash %s: %w", c.home.path, err)
+	}
+
+	homeDevice, ok := c.device(c.home.path)
+	pathDevice, pathOK := c.device(filepath.Dir(path))
+	if !ok || !pathOK || homeDevice == pathDevice {
+		return c.home, nil
+	}
+
+	if dir, ok := c.volumeDir(c.mountTop(filepath.Dir(path), pathDevice)); ok {
+		return dir, nil
+	}
+	return c.home, nil
+}
+
+// volumeDir finds or creates the trash at the top of a volume: a per-user
+// directory in an administrator's sticky .Trash, or else .Trash-$uid
+func (c *Can) volumeDir(top string) (trashDir, bool) {
+	shared := filepath.Join(top, ".Trash")
+	if info, err := c.fs.Lstat(shared); err == nil && info.IsDir() && info.Mode()&fs.ModeSticky != 0 {
+		dir := trashDir{path: filepath.Join(shared, strconv.Itoa(c.uid)), top: top}
+		if c.prepare(dir) == nil && c.ownDir(dir.path) {
+			return dir, true
+		}
+	}
+
+	dir := trashDir{path: filepath.Join(top, fmt.Sprintf(".Trash-%d", c.uid)), top: top}
+	if c.prepare(dir) == nil && c.ownDir(dir.path) {
+		return dir, true
+	}
+	return trashDir{}, false
+}
+
+// dirs lists the trash directories that exist: the home trash, then one per
+// mounted volume that has one
+func (c *Can) dirs() []trashDir {
+	dirs := []trashDir{c.home}
+	seen := map[string]bool{c.home.path: true}
+
+	for _, top := range mountPoints() {
+		for _, path := range []string{
+			filepath.Join(top, ".Trash", strconv.Itoa(c.uid)),
+			filepath.Join(top, fmt.Sprintf(".Trash-%d", c.uid)),
+		} {
+			if seen[path] || !c.ownDir(path) {
+				continue
+			}
+			seen[path] = true
+			dirs = append(dirs, trashDir{path: path, top: top})
+		}
+	}
+	return dirs
+}
+
+// prepare creates a trash directory's files and info directories
+func (c *Can) prepare(dir trashDir) error {
+	if err := c.fs.MkdirAll(dir.filesDir(), 0700); err != nil {
+		return err
+	}
+	return c.fs.MkdirAll(dir.infoDir(), 0700)
+}
+
+// ownDir reports whether path is a real directory, not a symlink, owned by the user
+func (c *Can) ownDir(path string) bool {
+	info, err := c.fs.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	sys, ok := vfs.Sys(info)
+	return !ok || sys.UID == c.uid
+}
+
+// device returns the device path is on; filesystems without one, such as a
+// dry run's, report false and everything goes to the home trash
+func (c *Can) device(path string) (uint64, bool) {
+	info, err := c.fs.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	sys, ok := vfs.Sys(info)
+	if !ok {
+		return 0, false
+	}
+	return sys.Device, true
+}
+
+// mountTop climbs from dir to the top of the volume it is on
+func (c *Can) mountTop(dir string, device uint64) string {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if parentDevice, ok := c.device(parent); !ok || parentDevice != device {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// reserve picks a free name in a trash for path and writes its info file,
+// adding a number before the extension when the name is taken. The info file
+// is created exclusively, so two processes trashing at once never get the
+// same name.
+func (c *Can) reserve(dir trashDir, path string, deletedAt time.Time) (string, error) {
+	recorded := path
+	if dir.top != "" {
+		if rel, err := filepath.Rel(dir.top, path); err == nil {
+			recorded = rel
+		}
+	}
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: recorded}).EscapedPath(), deletedAt.Format(infoDateLayout))
+
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// A dotfile such as .bashrc is all name
+		stem, ext = base, ""
+	}
+
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		if c.exists(filepath.Join(dir.filesDir(), name)) {
+			continue
+		}
+		err := c.fs.WriteNewFile(dir.infoFile(name), []byte(info), 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("Failed to write trash info: %w", err)
+		}
+		return name, nil
+	}
+}
+
+// load reads one item from a trash directory
+func (c *Can) load(dir trashDir, name string) (*Item, error) {
+	data, err := c.fs.ReadFile(dir.infoFile(name))
+	if err != nil {
+		return nil, err
+	}
+	path, deletedAt, err := parseInfo(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", dir.infoFile(name), err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir.top, path)
+	}
+
+	file := filepath.Join(dir.filesDir(), name)
+	info, err := c.fs.Lstat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Item{
+		Name:      name,
+		Path:      path,
+		DeletedAt: deletedAt,
+		Size:      c.size(file, info),
+		IsDir:     info.IsDir(),
+		Trash:     dir.path,
+		dir:       dir,
+	}, nil
+}
+
+// size returns the bytes held by a file, or by every file beneath a directory
+func (c *Can) size(path string, info fs.FileInfo) int64 {
+	if !info.IsDir() {
+		return info.Size()
+	}
+
+	var total int64
+	vfs.Walk(c.fs, path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// parseInfo reads the original path and deletion time from a .trashinfo file
+func parseInfo(data []byte) (string, time.Time, error) {
+	var path string
+	var deletedAt time.Time
+	inSection := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[Trash Info]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inSection || !ok {
+			continue
+		}
+
+		switch key {
+		case "Path":
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			path = unescaped
+		case "DeletionDate":
+			// A missing or malformed date leaves the item listed as the oldest
+			deletedAt, _ = time.ParseInLocation(infoDateLayout, value, time.Local)
+		}
+	}
+
+	if path == "" {
+		return "", time.Time{}, fmt.Errorf("no Path in [Trash Info]")
+	}
+	return path, deletedAt, nil
+}
+
+// move renames src to dst, copying and deleting when they are on different volumes
+func (c *Can) move(src, dst string) error {
+	err := c.fs.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := c.copyTree(src, dst); err != nil {
+		c.fs.RemoveAll(dst)
+		return err
+	}
+	return c.fs.RemoveAll(src)
+}
+
+// copyTree copies a file or directory tree, keeping modes and modification times
+func (c *Can) copyTree(src, dst string) error {
+	return vfs.Walk(c.fs, src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, strings.TrimPrefix(path, src))
+
+		switch {
+		case info.IsDir():
+			if err := c.fs.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			if err := vfs.CopyFile(c.fs, path, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cannot copy %s between volumes: not a regular file", path)
+		}
+
+		c.fs.Chmod(target, info.Mode().Perm())
+		return c.fs.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// exists reports whether anything is at path
+func (c *Can) exists(path string) bool {
+	_, err := c.fs.Lstat(path)
+	return err == nil
+}
+
+// mountPoints lists the mounted volumes; where /proc is unavailable only the
+// home trash is used
+func mountPoints() []string {
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+
+	var mounts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			mounts = append(mounts, unescapeMount(fields[1]))
+		}
+	}
+	return mounts
+}
+
+// unescapeMount decodes the octal escapes, such as \040 for a space, in a mount path
+func unescapeMount(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if code, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// trashFS is a filesystem whose Trash moves files into a can
+type trashFS struct {
+	vfs.FS
+	can *Can
+}
+
+// Wrap returns fsys with Trash moving files into can. Remove and RemoveAll
+// still delete, so a move that copies and then removes leaves nothing behind.
+func Wrap(fsys vfs.FS, can *Can) vfs.FS {
+	return &trashFS{FS: fsys, can: can}
+}
+
+func (t *trashFS) Trash(path string) error {
+	_, err := t.can.Put(path)
+	return err
+}
+
+func (t *trashFS) Link(oldname, newname string) error {
+	return vfs.Link(t.FS, oldname, newname)
+}
+
+func (t *trashFS) Reflink(src, dst string) error {
+	return vfs.Reflink(t.FS, src, dst)
+}
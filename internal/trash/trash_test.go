@@ -0,0 +1,193 @@
+package trash
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"ena/internal/vfs"
+)
+
+// newCan builds a trash over an empty memory filesystem, with its home trash
+// under /home/u/.local/share
+func newCan(t *testing.T) (*Can, *vfs.Memory) {
+	t.Helper()
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("XDG_DATA_HOME", "/home/u/.local/share")
+
+	fsys := vfs.NewMemory()
+	return NewCan(fsys), fsys
+}
+
+// writeFile writes data to name, making its parent directories
+func writeFile(t *testing.T, fsys vfs.FS, name, data string) {
+	t.Helper()
+	if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	deleted := time.Date(2026, 3, 4, 5, 6, 7, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		info     string
+		wantPath string
+		wantTime time.Time
+		wantErr  bool
+	}{
+		{"absolute path", "[Trash Info]\nPath=/home/u/a.txt\nDeletionDate=2026-03-04T05:06:07\n", "/home/u/a.txt", deleted, false},
+		{"escaped path", "[Trash Info]\nPath=/home/u/my%20notes%25.txt\nDeletionDate=2026-03-04T05:06:07\n", "/home/u/my notes%.txt", deleted, false},
+		{"relative path", "[Trash Info]\nPath=docs/a.txt\nDeletionDate=2026-03-04T05:06:07\n", "docs/a.txt", deleted, false},
+		{"crlf and spaces", "[Trash Info]\r\n  Path=/a\r\nDeletionDate=2026-03-04T05:06:07\r\n", "/a", deleted, false},
+		{"keys outside the section", "Path=/wrong\n[Trash Info]\nPath=/right\n[Other]\nPath=/also-wrong\n", "/right", time.Time{}, false},
+		{"bad date", "[Trash Info]\nPath=/a\nDeletionDate=yesterday\n", "/a", time.Time{}, false},
+		{"no path", "[Trash Info]\nDeletionDate=2026-03-04T05:06:07\n", "", time.Time{}, true},
+		{"no section", "Path=/a\n", "", time.Time{}, true},
+		{"bad escape", "[Trash Info]\nPath=/a%zz\n", "", time.Time{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, deletedAt, err := parseInfo([]byte(test.info))
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("parseInfo succeeded with %q, want an error", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseInfo: %v", err)
+			}
+			if path != test.wantPath {
+				t.Errorf("path = %q, want %q", path, test.wantPath)
+			}
+			if !deletedAt.Equal(test.wantTime) {
+				t.Errorf("deletion date = %v, want %v", deletedAt, test.wantTime)
+			}
+		})
+	}
+}
+
+func TestReserveRecordsPaths(t *testing.T) {
+	can, fsys := newCan(t)
+	deleted := time.Date(2026, 3, 4, 5, 6, 7, 0, time.Local)
+	volume := trashDir{path: "/mnt/usb/.Trash-1000", top: "/mnt/usb"}
+
+	tests := []struct {
+		name     string
+		dir      trashDir
+		path     string
+		wantLine string // Path line written to the info file
+	}{
+		{"home trash keeps absolute paths", can.home, "/home/u/my notes.txt", "Path=/home/u/my%20notes.txt"},
+		{"volume trash records paths from its top", volume, "/mnt/usb/docs/report 1.pdf", "Path=docs/report%201.pdf"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := can.prepare(test.dir); err != nil {
+				t.Fatal(err)
+			}
+			name, err := can.reserve(test.dir, test.path, deleted)
+			if err != nil {
+				t.Fatalf("reserve: %v", err)
+			}
+
+			data, err := fsys.ReadFile(test.dir.infoFile(name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "[Trash Info]\n" + test.wantLine + "\nDeletionDate=2026-03-04T05:06:07\n"
+			if string(data) != want {
+				t.Errorf("info file holds %q, want %q", data, want)
+			}
+
+			// Loading it back gives the absolute path again
+			writeFile(t, fsys, filepath.Join(test.dir.filesDir(), name), "x")
+			item, err := can.load(test.dir, name)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if item.Path != test.path || !item.DeletedAt.Equal(deleted) {
+				t.Errorf("loaded %s deleted at %v, want %s at %v", item.Path, item.DeletedAt, test.path, deleted)
+			}
+		})
+	}
+}
+
+func TestReserveNumbersTakenNames(t *testing.T) {
+	can, _ := newCan(t)
+	if err := can.prepare(can.home); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/a/report.tar.gz", []string{"report.tar.gz", "report.tar.2.gz", "report.tar.3.gz"}},
+		{"/a/.bashrc", []string{".bashrc", ".bashrc.2"}},
+		{"/a/Makefile", []string{"Makefile", "Makefile.2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			var got []string
+			for range test.want {
+				name, err := can.reserve(can.home, test.path, time.Now())
+				if err != nil {
+					t.Fatalf("reserve: %v", err)
+				}
+				got = append(got, name)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("names = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPutFindRestore(t *testing.T) {
+	can, fsys := newCan(t)
+	writeFile(t, fsys, "/home/u/docs/a.txt", "first")
+
+	item, err := can.Put("/home/u/docs/a.txt")
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := fsys.Stat("/home/u/docs/a.txt"); err == nil {
+		t.Error("trashed file is still in place")
+	}
+
+	// A second file of the same name gets its own entry
+	writeFile(t, fsys, "/home/u/docs/a.txt", "second")
+	if _, err := can.Put("/home/u/docs/a.txt"); err != nil {
+		t.Fatalf("put again: %v", err)
+	}
+
+	found, err := can.Find("/home/u/docs/a.txt")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("found %d items, want 2", len(found))
+	}
+	if _, err := can.Put(item.Location()); err == nil {
+		t.Error("putting a file already in the trash succeeded")
+	}
+
+	restored, err := can.Restore(item, "", false)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	data, err := fsys.ReadFile(restored)
+	if err != nil || string(data) != "first" {
+		t.Errorf("restored %s holds %q (%v), want %q", restored, data, err, "first")
+	}
+	if _, err := fsys.Stat(InfoPath(item.Location())); err == nil {
+		t.Error("info file of a restored item is left behind")
+	}
+}
@@ -19,10 +19,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
+	"ena/internal/audit"
+	"ena/internal/events"
+	"ena/internal/fault"
+	"ena/internal/paths"
 	"ena/internal/suggestions"
+	"ena/internal/trash"
+	"ena/internal/vfs"
 )
 
 // OperationType represents the type of file operation
@@ -35,6 +42,8 @@ const (
 	OpMove   OperationType = "move"
 	OpCopy   OperationType = "copy"
 	OpRename OperationType = "rename"
+	OpLink   OperationType = "link"  // replaced by a link to an identical file; tracked without a backup
+	OpTrash  OperationType = "trash" // moved to the trash; tracked afterwards, with NewPath in the trash
 )
 
 // UndoOperation represents a single operation that can be undone
@@ -77,32 +86,58 @@ type UndoManager struct {
 	maxSessionAge  time.Duration
 	backupDir      string
 	analytics      *suggestions.UsageAnalytics
-	eventCallbacks map[string][]UndoEventCallback
+	events         *events.Bus
+	fs             vfs.FS
 }
 
-// UndoEventCallback is a function that gets called on undo events
-type UndoEventCallback func(event UndoEvent)
+// Topics the undo manager publishes; every payload is an UndoEvent
+var (
+	TopicSessionCreated   = events.Define("undo.session_created", "An undo session started recording operations")
+	TopicOperationTracked = events.Define("undo.operation_tracked", "An operation was recorded so it can be undone")
+	TopicOperationUndone  = events.Define("undo.operation_undone", "A single operation was undone")
+	TopicSessionUndone    = events.Define("undo.session_undone", "A whole undo session was undone")
+)
 
-// UndoEvent represents an event that occurred in the undo system
+// UndoEvent is the payload of undo events
 type UndoEvent struct {
-	Type      string                 `json:"type"` // operation_tracked, session_created, operation_undone, session_undone, error
-	SessionID string                 `json:"session_id,omitempty"`
-	Operation *UndoOperation         `json:"operation,omitempty"`
-	Message   string                 `json:"message"`
-	Data      map[string]interface{} `json:"data,omitempty"`
-	Timestamp time.Time              `json:"timestamp"`
+	SessionID string         `json:"session_id,omitempty"`
+	Operation *UndoOperation `json:"operation,omitempty"` // a copy without the saved content
+}
+
+// UndoConfig limits how much undo history is kept
+type UndoConfig struct {
+	MaxHistorySize int           `json:"max_history_size" yaml:"max_history_size"` // Sessions kept before the oldest are dropped
+	MaxSessionAge  time.Duration `json:"max_session_age" yaml:"max_session_age"`   // Sessions older than this are cleaned up
+}
+
+// DefaultUndoConfig returns the limits used when nothing is configured
+func DefaultUndoConfig() UndoConfig {
+	return UndoConfig{
+		MaxHistorySize: 1000,
+		MaxSessionAge:  24 * time.Hour,
+	}
 }
 
 // NewUndoManager creates a new undo manager instance
 func NewUndoManager(analytics *suggestions.UsageAnalytics) *UndoManager {
+	return NewUndoManagerWithConfig(analytics, DefaultUndoConfig())
+}
+
+// NewUndoManagerWithConfig creates an undo manager with the given history limits
+func NewUndoManagerWithConfig(analytics *suggestions.UsageAnalytics, config UndoConfig) *UndoManager {
+	return NewUndoManagerWithFS(vfs.OS{}, analytics, config)
+}
+
+// NewUndoManagerWithFS creates an undo manager that tracks and restores files on the given filesystem
+func NewUndoManagerWithFS(fs vfs.FS, analytics *suggestions.UsageAnalytics, config UndoConfig) *UndoManager {
 	um := &UndoManager{
 		sessions:       make(map[string]*UndoSession),
-		historyFile:    "undo_history.json",
-		maxHistorySize: 1000,
-		maxSessionAge:  24 * time.Hour,
-		backupDir:      ".ena_undo_backups",
+		historyFile:    paths.StateFile("undo_history.json"),
+		maxHistorySize: config.MaxHistorySize,
+		maxSessionAge:  config.MaxSessionAge,
+		backupDir:      paths.DataFile("undo_backups"),
 		analytics:      analytics,
-		eventCallbacks: make(map[string][]UndoEventCallback),
+		fs:             fs,
 	}
 
 	// Load existing history
@@ -135,23 +170,53 @@ func (um *UndoManager) StartSession(name, description string) *UndoSession {
 	// Release lock before triggering event to avoid deadlock
 	um.mutex.Unlock()
 
-	um.triggerEvent(UndoEvent{
-		Type:      "session_created",
-		SessionID: sessionID,
-		Message:   fmt.Sprintf("Started undo session: %s", name),
-		Timestamp: time.Now(),
-	})
+	um.publish(TopicSessionCreated, fmt.Sprintf("Started undo session: %s", name), sessionID, nil)
 
 	return session
 }
 
-// EndSession ends the current session
-func (um *UndoManager) EndSession() {
+// EndSession ends the current session and saves it so it can be undone later;
+// a session that tracked nothing is dropped
+func (um *UndoManager) EndSession() error {
 	um.mutex.Lock()
 	defer um.mutex.Unlock()
 
-	if um.currentSession != nil {
-		um.currentSession = nil
+	session := um.currentSession
+	if session == nil {
+		return nil
+	}
+	um.currentSession = nil
+
+	if len(session.Operations) == 0 {
+		delete(um.sessions, session.ID)
+		return nil
+	}
+	return um.saveHistory()
+}
+
+// Begin opens a session for a group of operations. When one is open already,
+// such as a script's, the operations join it instead and nested is true; end
+// then leaves that session for its owner to close.
+func (um *UndoManager) Begin(name, description string) (session *UndoSession, nested bool, end func() error) {
+	um.mutex.RLock()
+	current := um.currentSession
+	um.mutex.RUnlock()
+
+	if current != nil {
+		return current, true, func() error { return nil }
+	}
+
+	session = um.StartSession(name, description)
+	return session, false, func() error {
+		um.mutex.RLock()
+		open := um.currentSession == session
+		um.mutex.RUnlock()
+
+		// Someone else ended it already, e.g. with "ena end-session"
+		if !open {
+			return nil
+		}
+		return um.EndSession()
 	}
 }
 
@@ -169,10 +234,14 @@ func (um *UndoManager) TrackOperation(opType OperationType, originalPath, newPat
 	um.mutex.Lock()
 	defer um.mutex.Unlock()
 
-	// Get file info
-	info, err := os.Stat(originalPath)
+	// Get file info; a trashed file is only in the trash by now
+	statPath := originalPath
+	if opType == OpTrash {
+		statPath = newPath
+	}
+	info, err := um.fs.Stat(statPath)
 	if err != nil {
-		return fmt.Errorf("error getting file info for %s: %v", originalPath, err)
+		return fmt.Errorf("error getting file info for %s: %w", statPath, err)
 	}
 
 	// Create backup if needed
@@ -180,21 +249,24 @@ func (um *UndoManager) TrackOperation(opType OperationType, originalPath, newPat
 	if opType == OpDelete || opType == OpUpdate || opType == OpMove {
 		backupPath, err = um.createBackup(originalPath)
 		if err != nil {
-			return fmt.Errorf("error creating backup for %s: %v", originalPath, err)
+			return fmt.Errorf("error creating backup for %s: %w", originalPath, err)
 		}
 	}
 
 	// Read content for create/update operations
 	var content []byte
 	if opType == OpCreate || opType == OpUpdate {
-		content, err = os.ReadFile(originalPath)
+		content, err = um.fs.ReadFile(originalPath)
 		if err != nil {
-			return fmt.Errorf("error reading content for %s: %v", originalPath, err)
+			return fmt.Errorf("error reading content for %s: %w", originalPath, err)
 		}
 	}
 
 	// Calculate checksum
-	checksum := um.calculateChecksum(originalPath)
+	checksum := ""
+	if !info.IsDir() {
+		checksum = um.calculateChecksum(statPath)
+	}
 
 	operation := UndoOperation{
 		ID:           fmt.Sprintf("op_%d", time.Now().UnixNano()),
@@ -214,13 +286,57 @@ func (um *UndoManager) TrackOperation(opType OperationType, originalPath, newPat
 
 	um.currentSession.Operations = append(um.currentSession.Operations, operation)
 
-	um.triggerEvent(UndoEvent{
-		Type:      "operation_tracked",
-		SessionID: um.currentSession.ID,
-		Operation: &operation,
-		Message:   fmt.Sprintf("Tracked %s operation: %s", opType, originalPath),
-		Timestamp: time.Now(),
-	})
+	um.publish(TopicOperationTracked, fmt.Sprintf("Tracked %s operation: %s", opType, originalPath), um.currentSession.ID, &operation)
+
+	return nil
+}
+
+// TrackDeletion deletes a file with remove and tracks it as OpDelete only once
+// remove has succeeded. The file is backed up first, and the backup is
+// dropped again when remove fails.
+func (um *UndoManager) TrackDeletion(path string, remove func(string) error) error {
+	um.mutex.RLock()
+	needsSession := um.currentSession == nil
+	um.mutex.RUnlock()
+
+	if needsSession {
+		um.StartSession("Auto Session", "Automatically created session")
+	}
+
+	um.mutex.Lock()
+	defer um.mutex.Unlock()
+
+	info, err := um.fs.Stat(path)
+	if err != nil {
+		return fmt.Errorf("error getting file info for %s: %w", path, err)
+	}
+	backupPath, err := um.createBackup(path)
+	if err != nil {
+		return fmt.Errorf("error creating backup for %s: %w", path, err)
+	}
+	checksum := um.calculateChecksum(path)
+
+	if err := remove(path); err != nil {
+		um.fs.Remove(backupPath)
+		return err
+	}
+
+	operation := UndoOperation{
+		ID:           fmt.Sprintf("op_%d", time.Now().UnixNano()),
+		Type:         OpDelete,
+		Timestamp:    time.Now(),
+		OriginalPath: path,
+		BackupPath:   backupPath,
+		Size:         info.Size(),
+		Permissions:  info.Mode(),
+		ModTime:      info.ModTime(),
+		Checksum:     checksum,
+		Metadata:     make(map[string]interface{}),
+	}
+
+	um.currentSession.Operations = append(um.currentSession.Operations, operation)
+
+	um.publish(TopicOperationTracked, fmt.Sprintf("Tracked %s operation: %s", OpDelete, path), um.currentSession.ID, &operation)
 
 	return nil
 }
@@ -248,7 +364,7 @@ func (um *UndoManager) UndoOperation(operationID string) error {
 	}
 
 	if operation == nil {
-		return fmt.Errorf("operation %s not found", operationID)
+		return fault.NotFound("operation %s not found", operationID)
 	}
 
 	if operation.Undone {
@@ -258,7 +374,7 @@ func (um *UndoManager) UndoOperation(operationID string) error {
 	// Perform undo based on operation type
 	err := um.performUndo(operation)
 	if err != nil {
-		return fmt.Errorf("error undoing operation %s: %v", operationID, err)
+		return fmt.Errorf("error undoing operation %s: %w", operationID, err)
 	}
 
 	// Mark as undone
@@ -266,13 +382,7 @@ func (um *UndoManager) UndoOperation(operationID string) error {
 	operation.Undone = true
 	operation.UndoneAt = &now
 
-	um.triggerEvent(UndoEvent{
-		Type:      "operation_undone",
-		SessionID: session.ID,
-		Operation: operation,
-		Message:   fmt.Sprintf("Undone %s operation: %s", operation.Type, operation.OriginalPath),
-		Timestamp: time.Now(),
-	})
+	um.publish(TopicOperationUndone, fmt.Sprintf("Undone %s operation: %s", operation.Type, operation.OriginalPath), session.ID, operation)
 
 	return nil
 }
@@ -284,7 +394,7 @@ func (um *UndoManager) UndoSession(sessionID string) error {
 
 	session, exists := um.sessions[sessionID]
 	if !exists {
-		return fmt.Errorf("session %s not found", sessionID)
+		return fault.NotFound("session %s not found", sessionID)
 	}
 
 	if session.Undone {
@@ -297,7 +407,7 @@ func (um *UndoManager) UndoSession(sessionID string) error {
 		if !operation.Undone {
 			err := um.performUndo(operation)
 			if err != nil {
-				return fmt.Errorf("error undoing operation %s: %v", operation.ID, err)
+				return fmt.Errorf("error undoing operation %s: %w", operation.ID, err)
 			}
 			now := time.Now()
 			operation.Undone = true
@@ -310,12 +420,7 @@ func (um *UndoManager) UndoSession(sessionID string) error {
 	session.Undone = true
 	session.UndoneAt = &now
 
-	um.triggerEvent(UndoEvent{
-		Type:      "session_undone",
-		SessionID: sessionID,
-		Message:   fmt.Sprintf("Undone session: %s", session.Name),
-		Timestamp: time.Now(),
-	})
+	um.publish(TopicSessionUndone, fmt.Sprintf("Undone session: %s", session.Name), sessionID, nil)
 
 	return nil
 }
@@ -349,12 +454,28 @@ func (um *UndoManager) GetSession(sessionID string) (*UndoSession, error) {
 
 	session, exists := um.sessions[sessionID]
 	if !exists {
-		return nil, fmt.Errorf("session %s not found", sessionID)
+		return nil, fault.NotFound("session %s not found", sessionID)
 	}
 
 	return session, nil
 }
 
+// GetOperation returns a copy of a specific operation
+func (um *UndoManager) GetOperation(operationID string) (*UndoOperation, error) {
+	um.mutex.RLock()
+	defer um.mutex.RUnlock()
+
+	for _, session := range um.sessions {
+		for _, operation := range session.Operations {
+			if operation.ID == operationID {
+				return &operation, nil
+			}
+		}
+	}
+
+	return nil, fault.NotFound("operation %s not found", operationID)
+}
+
 // ClearHistory clears old undo history
 func (um *UndoManager) ClearHistory(olderThan time.Duration) error {
 	um.mutex.Lock()
@@ -370,24 +491,50 @@ func (um *UndoManager) ClearHistory(olderThan time.Duration) error {
 	}
 
 	for _, sessionID := range toDelete {
-		session := um.sessions[sessionID]
-		// Clean up backup files
-		for _, operation := range session.Operations {
-			if operation.BackupPath != "" {
-				os.Remove(operation.BackupPath)
-			}
-		}
-		delete(um.sessions, sessionID)
+		um.removeSession(sessionID)
 	}
 
 	return um.saveHistory()
 }
 
+// removeSession forgets a session and deletes its backup files
+func (um *UndoManager) removeSession(sessionID string) {
+	session := um.sessions[sessionID]
+	// Clean up backup files
+	for _, operation := range session.Operations {
+		if operation.BackupPath != "" {
+			um.fs.Remove(operation.BackupPath)
+		}
+	}
+	delete(um.sessions, sessionID)
+}
+
+// trimHistory drops the oldest sessions beyond the configured history size
+func (um *UndoManager) trimHistory() {
+	if um.maxHistorySize <= 0 || len(um.sessions) <= um.maxHistorySize {
+		return
+	}
+
+	sessions := make([]*UndoSession, 0, len(um.sessions))
+	for _, session := range um.sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	for _, session := range sessions[:len(sessions)-um.maxHistorySize] {
+		if session != um.currentSession {
+			um.removeSession(session.ID)
+		}
+	}
+}
+
 // Private helper methods
 
 func (um *UndoManager) createBackup(filePath string) (string, error) {
 	// Ensure backup directory exists
-	if err := os.MkdirAll(um.backupDir, 0755); err != nil {
+	if err := um.fs.MkdirAll(um.backupDir, 0755); err != nil {
 		return "", err
 	}
 
@@ -396,13 +543,13 @@ func (um *UndoManager) createBackup(filePath string) (string, error) {
 	backupPath := filepath.Join(um.backupDir, backupName)
 
 	// Copy file to backup location
-	sourceFile, err := os.Open(filePath)
+	sourceFile, err := um.fs.Open(filePath)
 	if err != nil {
 		return "", err
 	}
 	defer sourceFile.Close()
 
-	backupFile, err := os.Create(backupPath)
+	backupFile, err := um.fs.Create(backupPath)
 	if err != nil {
 		return "", err
 	}
@@ -416,15 +563,15 @@ func (um *UndoManager) createBackup(filePath string) (string, error) {
 	// Preserve permissions and timestamps
 	info, err := sourceFile.Stat()
 	if err == nil {
-		os.Chmod(backupPath, info.Mode())
-		os.Chtimes(backupPath, info.ModTime(), info.ModTime())
+		um.fs.Chmod(backupPath, info.Mode())
+		um.fs.Chtimes(backupPath, info.ModTime(), info.ModTime())
 	}
 
 	return backupPath, nil
 }
 
 func (um *UndoManager) calculateChecksum(filePath string) string {
-	file, err := os.Open(filePath)
+	file, err := um.fs.Open(filePath)
 	if err != nil {
 		return ""
 	}
@@ -438,11 +585,15 @@ func (um *UndoManager) calculateChecksum(filePath string) string {
 	return fmt.Sprintf("%x", hash.Sum(nil))
 }
 
-func (um *UndoManager) performUndo(operation *UndoOperation) error {
+func (um *UndoManager) performUndo(operation *UndoOperation) (err error) {
+	defer func() {
+		audit.RecordFile("undo", "undo-"+string(operation.Type), operation.OriginalPath, operation.NewPath, err)
+	}()
+
 	switch operation.Type {
 	case OpCreate:
 		// Delete the created file
-		return os.Remove(operation.OriginalPath)
+		return um.fs.Remove(operation.OriginalPath)
 	case OpDelete:
 		// Restore from backup
 		if operation.BackupPath == "" {
@@ -460,13 +611,37 @@ func (um *UndoManager) performUndo(operation *UndoOperation) error {
 		if operation.NewPath == "" {
 			return fmt.Errorf("no new path specified for move/rename operation")
 		}
-		return os.Rename(operation.NewPath, operation.OriginalPath)
+		return um.fs.Rename(operation.NewPath, operation.OriginalPath)
+	case OpLink:
+		// Older entries kept a full copy of the file
+		if operation.BackupPath != "" {
+			if err := um.fs.Remove(operation.OriginalPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return um.restoreFromBackup(operation.BackupPath, operation.OriginalPath, operation.Permissions, operation.ModTime)
+		}
+		return um.unshare(operation.OriginalPath, operation.Permissions, operation.ModTime)
+	case OpTrash:
+		// Take it back out of the trash, dropping its trash info
+		if _, err := um.fs.Lstat(operation.OriginalPath); err == nil {
+			return fmt.Errorf("%s already exists", operation.OriginalPath)
+		}
+		if err := um.fs.MkdirAll(filepath.Dir(operation.OriginalPath), 0755); err != nil {
+			return err
+		}
+		if err := um.fs.Rename(operation.NewPath, operation.OriginalPath); err != nil {
+			return err
+		}
+		if err := um.fs.Remove(trash.InfoPath(operation.NewPath)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
 	case OpCopy:
 		// Delete the copied file
 		if operation.NewPath == "" {
 			return fmt.Errorf("no new path specified for copy operation")
 		}
-		return os.Remove(operation.NewPath)
+		return um.fs.Remove(operation.NewPath)
 	default:
 		return fmt.Errorf("unknown operation type: %s", operation.Type)
 	}
@@ -474,18 +649,18 @@ func (um *UndoManager) performUndo(operation *UndoOperation) error {
 
 func (um *UndoManager) restoreFromBackup(backupPath, originalPath string, permissions os.FileMode, modTime time.Time) error {
 	// Ensure parent directory exists
-	if err := os.MkdirAll(filepath.Dir(originalPath), 0755); err != nil {
+	if err := um.fs.MkdirAll(filepath.Dir(originalPath), 0755); err != nil {
 		return err
 	}
 
 	// Copy backup to original location
-	backupFile, err := os.Open(backupPath)
+	backupFile, err := um.fs.Open(backupPath)
 	if err != nil {
 		return err
 	}
 	defer backupFile.Close()
 
-	originalFile, err := os.Create(originalPath)
+	originalFile, err := um.fs.Create(originalPath)
 	if err != nil {
 		return err
 	}
@@ -497,20 +672,38 @@ func (um *UndoManager) restoreFromBackup(backupPath, originalPath string, permis
 	}
 
 	// Restore permissions and timestamps
-	os.Chmod(originalPath, permissions)
-	os.Chtimes(originalPath, modTime, modTime)
+	um.fs.Chmod(originalPath, permissions)
+	um.fs.Chtimes(originalPath, modTime, modTime)
 
 	return nil
 }
 
+// unshare gives a linked file a copy of the data it shares, so it no longer
+// changes along with the file it was linked to
+func (um *UndoManager) unshare(path string, permissions os.FileMode, modTime time.Time) error {
+	temp := fmt.Sprintf("%s.ena-unlink-%d", path, time.Now().UnixNano())
+	if err := vfs.CopyFile(um.fs, path, temp); err != nil {
+		um.fs.Remove(temp)
+		return err
+	}
+	um.fs.Chmod(temp, permissions)
+	um.fs.Chtimes(temp, modTime, modTime)
+
+	if err := um.fs.Rename(temp, path); err != nil {
+		um.fs.Remove(temp)
+		return err
+	}
+	return nil
+}
+
 func (um *UndoManager) loadHistory() error {
-	if _, err := os.Stat(um.historyFile); os.IsNotExist(err) {
+	if _, err := um.fs.Stat(um.historyFile); os.IsNotExist(err) {
 		return nil // No history file exists yet
 	}
 
-	data, err := os.ReadFile(um.historyFile)
+	data, err := um.fs.ReadFile(um.historyFile)
 	if err != nil {
-		return fmt.Errorf("error reading history file: %v", err)
+		return fmt.Errorf("error reading history file: %w", err)
 	}
 
 	var historyData struct {
@@ -519,7 +712,7 @@ func (um *UndoManager) loadHistory() error {
 	}
 
 	if err := json.Unmarshal(data, &historyData); err != nil {
-		return fmt.Errorf("error parsing history file: %v", err)
+		return fmt.Errorf("error parsing history file: %w", err)
 	}
 
 	for _, session := range historyData.Sessions {
@@ -530,6 +723,8 @@ func (um *UndoManager) loadHistory() error {
 }
 
 func (um *UndoManager) saveHistory() error {
+	um.trimHistory()
+
 	historyData := struct {
 		Sessions []*UndoSession `json:"sessions"`
 		Version  string         `json:"version"`
@@ -546,11 +741,11 @@ func (um *UndoManager) saveHistory() error {
 
 	data, err := json.MarshalIndent(historyData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("error marshaling history: %v", err)
+		return fmt.Errorf("error marshaling history: %w", err)
 	}
 
-	if err := os.WriteFile(um.historyFile, data, 0644); err != nil {
-		return fmt.Errorf("error writing history file: %v", err)
+	if err := um.fs.WriteFile(um.historyFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing history file: %w", err)
 	}
 
 	return nil
@@ -565,20 +760,19 @@ func (um *UndoManager) startCleanupRoutine() {
 	}
 }
 
-func (um *UndoManager) triggerEvent(event UndoEvent) {
-	um.mutex.RLock()
-	callbacks := um.eventCallbacks[event.Type]
-	um.mutex.RUnlock()
-
-	for _, callback := range callbacks {
-		go callback(event) // Run callbacks asynchronously
-	}
+// SetEventBus sets the bus undo events are published on
+func (um *UndoManager) SetEventBus(bus *events.Bus) {
+	um.events = bus
 }
 
-// AddEventCallback adds a callback for undo events
-func (um *UndoManager) AddEventCallback(eventType string, callback UndoEventCallback) {
-	um.mutex.Lock()
-	defer um.mutex.Unlock()
-
-	um.eventCallbacks[eventType] = append(um.eventCallbacks[eventType], callback)
+// publish announces an undo event on the event bus, leaving the saved file
+// content out of the operation
+func (um *UndoManager) publish(topic events.Topic, message, sessionID string, operation *UndoOperation) {
+	event := UndoEvent{SessionID: sessionID}
+	if operation != nil {
+		snapshot := *operation
+		snapshot.Content = nil
+		event.Operation = &snapshot
+	}
+	um.events.Publish(events.New(topic, message, event))
 }
@@ -0,0 +1,470 @@
+/**
+ * Memory Filesystem
+ *
+ * A complete directory tree held in memory, for exercising organizes,
+ * restores and batch jobs over thousands of files without touching the disk.
+ * Relative paths resolve against the process working directory, as they do
+ * on the real filesystem.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: memory.go
+ * Description: In-memory filesystem backend
+ */
+
+package vfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Memory is a filesystem that lives entirely in memory
+type Memory struct {
+	mutex sync.RWMutex
+	nodes map[string]*memNode // keyed by clean absolute path
+}
+
+// memNode is one file or directory
+type memNode struct {
+	dir      bool
+	data     []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children map[string]bool // names of entries, for directories
+	lower    string          // for overlays: contents still live at this path below
+	size     int64           // size of the contents below
+}
+
+// NewMemory creates an empty filesystem holding only the root directory
+func NewMemory() *Memory {
+	return &Memory{
+		nodes: map[string]*memNode{
+			string(filepath.Separator): {dir: true, mode: 0755, modTime: time.Now(), children: make(map[string]bool)},
+		},
+	}
+}
+
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	key := memKey(name)
+	node, ok := m.nodes[key]
+	if !ok {
+		return nil, pathError("stat", name, fs.ErrNotExist)
+	}
+	return node.info(key), nil
+}
+
+// Lstat is Stat; the memory filesystem has no symlinks
+func (m *Memory) Lstat(name string) (fs.FileInfo, error) {
+	return m.Stat(name)
+}
+
+func (m *Memory) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	key := memKey(name)
+	node, ok := m.nodes[key]
+	if !ok {
+		return nil, pathError("open", name, fs.ErrNotExist)
+	}
+	if !node.dir {
+		return nil, pathError("readdirent", name, syscall.ENOTDIR)
+	}
+
+	entries := make([]fs.DirEntry, 0, len(node.children))
+	for child := range node.children {
+		childKey := filepath.Join(key, child)
+		entries = append(entries, fs.FileInfoToDirEntry(m.nodes[childKey].info(childKey)))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *Memory) Open(name string) (File, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	key := memKey(name)
+	node, ok := m.nodes[key]
+	if !ok {
+		return nil, pathError("open", name, fs.ErrNotExist)
+	}
+	if node.lower != "" {
+		return nil, pathError("open", name, fs.ErrInvalid)
+	}
+
+	// Readers see the contents as they were when opened
+	data := append([]byte(nil), node.data...)
+	return &memFile{memory: m, key: key, name: name, info: node.info(key), reader: bytes.NewReader(data)}, nil
+}
+
+func (m *Memory) Create(name string) (File, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key, err := m.writeLocked("open", name, nil, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{memory: m, key: key, name: name}, nil
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	file, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, err := m.writeLocked("open", name, data, perm)
+	return err
+}
+
+func (m *Memory) WriteNewFile(name string, data []byte, perm fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.nodes[memKey(name)]; ok {
+		return pathError("open", name, fs.ErrExist)
+	}
+	_, err := m.writeLocked("open", name, data, perm)
+	return err
+}
+
+func (m *Memory) MkdirAll(path string, perm fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := memKey(path)
+	var missing []string
+	for dir := key; ; dir = filepath.Dir(dir) {
+		if node, ok := m.nodes[dir]; ok {
+			if !node.dir {
+				return pathError("mkdir", dir, syscall.ENOTDIR)
+			}
+			break
+		}
+		missing = append(missing, dir)
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		m.addLocked(missing[i], &memNode{dir: true, mode: perm.Perm(), modTime: time.Now(), children: make(map[string]bool)})
+	}
+	return nil
+}
+
+func (m *Memory) Remove(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := memKey(name)
+	node, ok := m.nodes[key]
+	if !ok {
+		return pathError("remove", name, fs.ErrNotExist)
+	}
+	if node.dir && len(node.children) > 0 {
+		return pathError("remove", name, syscall.ENOTEMPTY)
+	}
+	if key == filepath.Dir(key) {
+		return pathError("remove", name, syscall.EBUSY)
+	}
+	m.removeLocked(key)
+	return nil
+}
+
+func (m *Memory) RemoveAll(path string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := memKey(path)
+	if _, ok := m.nodes[key]; !ok {
+		return nil
+	}
+	if key == filepath.Dir(key) {
+		return pathError("unlinkat", path, syscall.EBUSY)
+	}
+	m.removeLocked(key)
+	return nil
+}
+
+func (m *Memory) Rename(oldpath, newpath string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	oldKey, newKey := memKey(oldpath), memKey(newpath)
+	node, ok := m.nodes[oldKey]
+	if !ok {
+		return linkError(oldpath, newpath, fs.ErrNotExist)
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if node.dir && isWithin(newKey, oldKey) {
+		return linkError(oldpath, newpath, syscall.EINVAL)
+	}
+	if parent, ok := m.nodes[filepath.Dir(newKey)]; !ok {
+		return linkError(oldpath, newpath, fs.ErrNotExist)
+	} else if !parent.dir {
+		return linkError(oldpath, newpath, syscall.ENOTDIR)
+	}
+
+	// Like rename(2): files replace files, directories replace empty directories
+	if existing, ok := m.nodes[newKey]; ok {
+		switch {
+		case existing.dir && !node.dir:
+			return linkError(oldpath, newpath, syscall.EISDIR)
+		case !existing.dir && node.dir:
+			return linkError(oldpath, newpath, syscall.ENOTDIR)
+		case existing.dir && len(existing.children) > 0:
+			return linkError(oldpath, newpath, syscall.ENOTEMPTY)
+		}
+		m.removeLocked(newKey)
+	}
+
+	moved := map[string]*memNode{newKey: node}
+	prefix := oldKey + string(filepath.Separator)
+	for key, child := range m.nodes {
+		if strings.HasPrefix(key, prefix) {
+			moved[filepath.Join(newKey, strings.TrimPrefix(key, prefix))] = child
+			delete(m.nodes, key)
+		}
+	}
+	delete(m.nodes[filepath.Dir(oldKey)].children, filepath.Base(oldKey))
+	delete(m.nodes, oldKey)
+
+	for key, child := range moved {
+		m.nodes[key] = child
+	}
+	m.nodes[filepath.Dir(newKey)].children[filepath.Base(newKey)] = true
+	return nil
+}
+
+func (m *Memory) Chmod(name string, mode fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, ok := m.nodes[memKey(name)]
+	if !ok {
+		return pathError("chmod", name, fs.ErrNotExist)
+	}
+	node.mode = mode.Perm()
+	return nil
+}
+
+func (m *Memory) Chtimes(name string, atime, mtime time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, ok := m.nodes[memKey(name)]
+	if !ok {
+		return pathError("chtimes", name, fs.ErrNotExist)
+	}
+	node.modTime = mtime
+	return nil
+}
+
+// link creates a file whose contents stay at lower in another filesystem
+func (m *Memory) link(name, lower string, info fs.FileInfo) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key, err := m.writeLocked("open", name, nil, info.Mode())
+	if err != nil {
+		return err
+	}
+	node := m.nodes[key]
+	node.lower = lower
+	node.mode = info.Mode().Perm()
+	node.size = info.Size()
+	node.modTime = info.ModTime()
+	return nil
+}
+
+// clone copies a file to a new name, sharing nothing with the original
+func (m *Memory) clone(oldpath, newpath string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, ok := m.nodes[memKey(oldpath)]
+	if !ok {
+		return pathError("open", oldpath, fs.ErrNotExist)
+	}
+	key, err := m.writeLocked("open", newpath, node.data, node.mode)
+	if err != nil {
+		return err
+	}
+	copied := m.nodes[key]
+	copied.lower, copied.size, copied.modTime = node.lower, node.size, node.modTime
+	return nil
+}
+
+// lowerOf reports whether name exists and where its contents live if not here
+func (m *Memory) lowerOf(name string) (string, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	node, ok := m.nodes[memKey(name)]
+	if !ok {
+		return "", false
+	}
+	return node.lower, true
+}
+
+// writeLocked creates or truncates a file and sets its contents
+func (m *Memory) writeLocked(op, name string, data []byte, perm fs.FileMode) (string, error) {
+	key := memKey(name)
+	parent, ok := m.nodes[filepath.Dir(key)]
+	if !ok {
+		return "", pathError(op, name, fs.ErrNotExist)
+	}
+	if !parent.dir {
+		return "", pathError(op, name, syscall.ENOTDIR)
+	}
+
+	if node, ok := m.nodes[key]; ok {
+		if node.dir {
+			return "", pathError(op, name, syscall.EISDIR)
+		}
+		// Existing files keep their permissions, as with os.WriteFile
+		node.data = append([]byte(nil), data...)
+		node.lower, node.size = "", 0
+		node.modTime = time.Now()
+		return key, nil
+	}
+
+	m.addLocked(key, &memNode{data: append([]byte(nil), data...), mode: perm.Perm(), modTime: time.Now()})
+	return key, nil
+}
+
+// addLocked stores a node and lists it in its parent directory
+func (m *Memory) addLocked(key string, node *memNode) {
+	m.nodes[key] = node
+	if parent, ok := m.nodes[filepath.Dir(key)]; ok && parent != node {
+		parent.children[filepath.Base(key)] = true
+	}
+}
+
+// removeLocked deletes a node and everything beneath it
+func (m *Memory) removeLocked(key string) {
+	prefix := key + string(filepath.Separator)
+	for child := range m.nodes {
+		if strings.HasPrefix(child, prefix) {
+			delete(m.nodes, child)
+		}
+	}
+	delete(m.nodes, key)
+	if parent, ok := m.nodes[filepath.Dir(key)]; ok {
+		delete(parent.children, filepath.Base(key))
+	}
+}
+
+// info describes a node as the os package would
+func (n *memNode) info(key string) fs.FileInfo {
+	info := memInfo{name: filepath.Base(key), size: int64(len(n.data)), mode: n.mode, modTime: n.modTime}
+	if n.dir {
+		info.mode |= fs.ModeDir
+		info.size = 0
+	}
+	if n.lower != "" {
+		info.size = n.size
+	}
+	return info
+}
+
+// memInfo is the fs.FileInfo of a memory node
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() interface{}   { return nil }
+
+// memFile is an open memory file, readable if it came from Open and
+// writable if it came from Create
+type memFile struct {
+	memory *Memory
+	key    string
+	name   string
+	info   fs.FileInfo
+	reader *bytes.Reader
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.reader == nil {
+		return 0, pathError("read", f.name, syscall.EBADF)
+	}
+	if f.info.IsDir() {
+		return 0, pathError("read", f.name, syscall.EISDIR)
+	}
+	return f.reader.Read(p)
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.reader != nil {
+		return 0, pathError("write", f.name, syscall.EBADF)
+	}
+
+	f.memory.mutex.Lock()
+	defer f.memory.mutex.Unlock()
+
+	// Writes to a file removed since it was created go nowhere, as on disk
+	if node, ok := f.memory.nodes[f.key]; ok && !node.dir {
+		node.data = append(node.data, p...)
+		node.modTime = time.Now()
+	}
+	return len(p), nil
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	if f.info != nil {
+		return f.info, nil
+	}
+	return f.memory.Stat(f.key)
+}
+
+// memKey turns a name into the clean absolute path nodes are stored under
+func memKey(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return filepath.Clean(string(filepath.Separator) + name)
+}
+
+// pathError builds the error the os package returns for a failed call
+func pathError(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// linkError builds the error the os package returns for a failed rename
+func linkError(oldpath, newpath string, err error) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+}
@@ -0,0 +1,203 @@
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+// newTree builds a memory filesystem holding the given files
+func newTree(t *testing.T, files map[string]string) *Memory {
+	t.Helper()
+	m := NewMemory()
+	for name, data := range files {
+		if err := writeString(m, name, data); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return m
+}
+
+// writeString writes data to name, making its parent directories
+func writeString(fsys FS, name, data string) error {
+	if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return fsys.WriteFile(name, []byte(data), 0644)
+}
+
+// readString reads a whole file, failing the test if it cannot
+func readString(t *testing.T, fsys FS, name string) string {
+	t.Helper()
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+// names lists a directory's entries
+func names(t *testing.T, fsys FS, dir string) []string {
+	t.Helper()
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir %s: %v", dir, err)
+	}
+	var list []string
+	for _, entry := range entries {
+		list = append(list, entry.Name())
+	}
+	return list
+}
+
+func TestMemoryRenameAcrossDirectories(t *testing.T) {
+	m := newTree(t, map[string]string{
+		"/src/a.txt":        "a",
+		"/src/nested/b.txt": "b",
+	})
+	if err := m.MkdirAll("/dst", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Rename("/src/a.txt", "/dst/renamed.txt"); err != nil {
+		t.Fatalf("rename file: %v", err)
+	}
+	if got := readString(t, m, "/dst/renamed.txt"); got != "a" {
+		t.Errorf("renamed file holds %q, want %q", got, "a")
+	}
+	if _, err := m.Stat("/src/a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("old name still exists: %v", err)
+	}
+
+	if err := m.Rename("/src/nested", "/dst/nested"); err != nil {
+		t.Fatalf("rename directory: %v", err)
+	}
+	if got := readString(t, m, "/dst/nested/b.txt"); got != "b" {
+		t.Errorf("moved child holds %q, want %q", got, "b")
+	}
+	if got := names(t, m, "/src"); len(got) != 0 {
+		t.Errorf("source directory still lists %v", got)
+	}
+	if got, want := names(t, m, "/dst"), []string{"nested", "renamed.txt"}; !slices.Equal(got, want) {
+		t.Errorf("destination lists %v, want %v", got, want)
+	}
+}
+
+func TestMemoryRenameErrors(t *testing.T) {
+	m := newTree(t, map[string]string{
+		"/dir/file":     "x",
+		"/full/child":   "y",
+		"/other/target": "z",
+	})
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     error
+	}{
+		{"missing source", "/dir/none", "/dir/new", fs.ErrNotExist},
+		{"missing parent", "/dir/file", "/nowhere/file", fs.ErrNotExist},
+		{"into itself", "/dir", "/dir/sub", syscall.EINVAL},
+		{"file over directory", "/dir/file", "/full", syscall.EISDIR},
+		{"directory over file", "/full", "/other/target", syscall.ENOTDIR},
+		{"directory over non-empty directory", "/dir", "/full", syscall.ENOTEMPTY},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := m.Rename(test.old, test.new); !errors.Is(err, test.want) {
+				t.Errorf("Rename(%s, %s) = %v, want %v", test.old, test.new, err, test.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRenameReplacesFile(t *testing.T) {
+	m := newTree(t, map[string]string{
+		"/a/new": "new",
+		"/b/old": "old",
+	})
+
+	if err := m.Rename("/a/new", "/b/old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if got := readString(t, m, "/b/old"); got != "new" {
+		t.Errorf("replaced file holds %q, want %q", got, "new")
+	}
+}
+
+func TestMemoryRemove(t *testing.T) {
+	m := newTree(t, map[string]string{"/dir/file": "x"})
+
+	if err := m.Remove("/dir"); !errors.Is(err, syscall.ENOTEMPTY) {
+		t.Errorf("Remove of a non-empty directory = %v, want ENOTEMPTY", err)
+	}
+	if err := m.RemoveAll("/dir"); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := m.Stat("/dir/file"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("child survived RemoveAll: %v", err)
+	}
+	if err := m.RemoveAll("/dir"); err != nil {
+		t.Errorf("RemoveAll of a missing path = %v, want nil", err)
+	}
+}
+
+func TestMemoryOpenSnapshotsContents(t *testing.T) {
+	m := newTree(t, map[string]string{"/file": "before"})
+
+	file, err := m.Open("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := m.WriteFile("/file", []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 16)
+	n, _ := file.Read(buf)
+	if got := string(buf[:n]); got != "before" {
+		t.Errorf("open file reads %q, want %q", got, "before")
+	}
+}
+
+func TestWriteNewFileRefusesExistingNames(t *testing.T) {
+	backends := []struct {
+		name string
+		fsys FS
+	}{
+		{"memory", newTree(t, map[string]string{"/dir/taken": "old"})},
+		{"overlay", NewOverlay(newTree(t, map[string]string{"/dir/taken": "old"}))},
+		{"os", OS{}},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			dir := "/dir"
+			if backend.name == "os" {
+				dir = t.TempDir()
+				if err := writeString(backend.fsys, filepath.Join(dir, "taken"), "old"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			taken := filepath.Join(dir, "taken")
+			if err := backend.fsys.WriteNewFile(taken, []byte("new"), 0644); !errors.Is(err, fs.ErrExist) {
+				t.Errorf("WriteNewFile over a file = %v, want ErrExist", err)
+			}
+			if got := readString(t, backend.fsys, taken); got != "old" {
+				t.Errorf("existing file holds %q, want %q", got, "old")
+			}
+
+			free := filepath.Join(dir, "free")
+			if err := backend.fsys.WriteNewFile(free, []byte("new"), 0644); err != nil {
+				t.Fatalf("WriteNewFile of a free name: %v", err)
+			}
+			if got := readString(t, backend.fsys, free); got != "new" {
+				t.Errorf("new file holds %q, want %q", got, "new")
+			}
+		})
+	}
+}
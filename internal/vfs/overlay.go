@@ -0,0 +1,474 @@
+/**
+ * Overlay Filesystem
+ *
+ * Reads through to a base filesystem but keeps every change in memory, so an
+ * operation run against it behaves as it would for real, failing on missing
+ * files and seeing its own earlier moves, while the base stays untouched.
+ * Each intended mutation is recorded in order. A base that vets changes, as
+ * the policy guard does, is asked about each one, but permissions on the base
+ * are not checked, so a dry run can still succeed where the real run would be
+ * denied by the system.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: overlay.go
+ * Description: Copy-on-write overlay that turns any operation into a dry run
+ */
+
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Overlay is a filesystem whose changes never reach the base it reads from
+type Overlay struct {
+	base    FS
+	upper   *Memory
+	hidden  map[string]bool // base paths removed in the overlay, with everything beneath them
+	changes []Change
+	mutex   sync.Mutex
+}
+
+// NewOverlay creates a dry-run view of base
+func NewOverlay(base FS) *Overlay {
+	return &Overlay{
+		base:   base,
+		upper:  NewMemory(),
+		hidden: make(map[string]bool),
+	}
+}
+
+// Changes returns the mutations made so far, oldest first
+func (o *Overlay) Changes() []Change {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]Change(nil), o.changes...)
+}
+
+func (o *Overlay) Stat(name string) (fs.FileInfo, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.stat(name, false)
+}
+
+func (o *Overlay) Lstat(name string) (fs.FileInfo, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.stat(name, true)
+}
+
+func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.readDir(name)
+}
+
+func (o *Overlay) Open(name string) (File, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if lower, ok := o.upper.lowerOf(name); ok {
+		if lower != "" {
+			return o.base.Open(lower)
+		}
+		return o.upper.Open(name)
+	}
+	if o.isHidden(name) {
+		return nil, pathError("open", name, fs.ErrNotExist)
+	}
+	return o.base.Open(name)
+}
+
+func (o *Overlay) Create(name string) (File, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err := o.prepareWrite("open", name); err != nil {
+		return nil, err
+	}
+	if err := o.vet("write", name, ""); err != nil {
+		return nil, err
+	}
+	file, err := o.upper.Create(name)
+	if err == nil {
+		o.record("write", name, "")
+	}
+	return file, err
+}
+
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	file, err := o.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func (o *Overlay) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err := o.prepareWrite("open", name); err != nil {
+		return err
+	}
+	if err := o.vet("write", name, ""); err != nil {
+		return err
+	}
+	err := o.upper.WriteFile(name, data, perm)
+	if err == nil {
+		o.record("write", name, "")
+	}
+	return err
+}
+
+func (o *Overlay) WriteNewFile(name string, data []byte, perm fs.FileMode) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, err := o.stat(name, true); err == nil {
+		return pathError("open", name, fs.ErrExist)
+	}
+	if err := o.prepareWrite("open", name); err != nil {
+		return err
+	}
+	if err := o.vet("write", name, ""); err != nil {
+		return err
+	}
+	err := o.upper.WriteFile(name, data, perm)
+	if err == nil {
+		o.record("write", name, "")
+	}
+	return err
+}
+
+func (o *Overlay) MkdirAll(path string, perm fs.FileMode) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	// The nearest existing ancestor must be a directory
+	for dir := path; ; dir = filepath.Dir(dir) {
+		info, err := o.stat(dir, false)
+		if err == nil {
+			if !info.IsDir() {
+				return pathError("mkdir", dir, syscall.ENOTDIR)
+			}
+			if dir == path {
+				return nil
+			}
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	if err := o.upper.MkdirAll(path, perm); err != nil {
+		return err
+	}
+	o.record("mkdir", path, "")
+	return nil
+}
+
+func (o *Overlay) Remove(name string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	info, err := o.stat(name, true)
+	if err != nil {
+		return pathError("remove", name, fs.ErrNotExist)
+	}
+	if info.IsDir() {
+		if entries, _ := o.readDir(name); len(entries) > 0 {
+			return pathError("remove", name, syscall.ENOTEMPTY)
+		}
+	}
+	if err := o.vet("remove", name, ""); err != nil {
+		return err
+	}
+
+	o.hide(name)
+	o.record("remove", name, "")
+	return nil
+}
+
+func (o *Overlay) RemoveAll(path string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, err := o.stat(path, true); err != nil {
+		return nil
+	}
+	if err := o.vet("remove", path, ""); err != nil {
+		return err
+	}
+	o.hide(path)
+	o.record("remove", path, "")
+	return nil
+}
+
+// Trash hides path like RemoveAll, recording it as trashed when the base keeps
+// a trash. Unlike RemoveAll it fails for a missing path, as the real trash does.
+func (o *Overlay) Trash(path string) error {
+	if _, ok := o.base.(Trasher); !ok {
+		return o.RemoveAll(path)
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, err := o.stat(path, true); err != nil {
+		return pathError("trash", path, fs.ErrNotExist)
+	}
+	if err := o.vet("trash", path, ""); err != nil {
+		return err
+	}
+	o.hide(path)
+	o.record("trash", path, "")
+	return nil
+}
+
+func (o *Overlay) Rename(oldpath, newpath string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	info, err := o.stat(oldpath, true)
+	if err != nil {
+		return linkError(oldpath, newpath, fs.ErrNotExist)
+	}
+	oldKey, newKey := memKey(oldpath), memKey(newpath)
+	if oldKey == newKey {
+		return nil
+	}
+	if info.IsDir() && isWithin(newKey, oldKey) {
+		return linkError(oldpath, newpath, syscall.EINVAL)
+	}
+	if parent, err := o.stat(filepath.Dir(newKey), false); err != nil {
+		return linkError(oldpath, newpath, fs.ErrNotExist)
+	} else if !parent.IsDir() {
+		return linkError(oldpath, newpath, syscall.ENOTDIR)
+	}
+
+	// Like rename(2): files replace files, directories replace empty directories
+	if existing, err := o.stat(newKey, true); err == nil {
+		switch {
+		case existing.IsDir() && !info.IsDir():
+			return linkError(oldpath, newpath, syscall.EISDIR)
+		case !existing.IsDir() && info.IsDir():
+			return linkError(oldpath, newpath, syscall.ENOTDIR)
+		case existing.IsDir():
+			if entries, _ := o.readDir(newKey); len(entries) > 0 {
+				return linkError(oldpath, newpath, syscall.ENOTEMPTY)
+			}
+		}
+	}
+
+	if err := o.vet("rename", oldpath, newpath); err != nil {
+		return err
+	}
+
+	o.hide(newKey)
+	if err := o.upper.MkdirAll(filepath.Dir(newKey), 0755); err != nil {
+		return err
+	}
+	if err := o.copyTree(oldKey, newKey, info); err != nil {
+		return err
+	}
+	o.hide(oldKey)
+	o.record("rename", oldpath, newpath)
+	return nil
+}
+
+func (o *Overlay) Chmod(name string, mode fs.FileMode) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err := o.copyUp("chmod", name); err != nil {
+		return err
+	}
+	if err := o.upper.Chmod(name, mode); err != nil {
+		return err
+	}
+	o.record("chmod", name, "")
+	return nil
+}
+
+func (o *Overlay) Chtimes(name string, atime, mtime time.Time) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err := o.copyUp("chtimes", name); err != nil {
+		return err
+	}
+	if err := o.upper.Chtimes(name, atime, mtime); err != nil {
+		return err
+	}
+	o.record("chtimes", name, "")
+	return nil
+}
+
+// stat finds name in the overlay first, then in the base unless it was removed
+func (o *Overlay) stat(name string, lstat bool) (fs.FileInfo, error) {
+	if info, err := o.upper.Stat(name); err == nil {
+		return info, nil
+	}
+	if o.isHidden(name) {
+		return nil, pathError("stat", name, fs.ErrNotExist)
+	}
+	if lstat {
+		return o.base.Lstat(name)
+	}
+	return o.base.Stat(name)
+}
+
+// readDir merges the overlay's entries over the base's. A directory that was
+// removed and made again hides everything the base had in it.
+func (o *Overlay) readDir(name string) ([]fs.DirEntry, error) {
+	upperInfo, upperErr := o.upper.Stat(name)
+	if upperErr == nil && !upperInfo.IsDir() {
+		return nil, pathError("readdirent", name, syscall.ENOTDIR)
+	}
+
+	merged := make(map[string]fs.DirEntry)
+	if !o.isHidden(name) {
+		entries, err := o.base.ReadDir(name)
+		if err != nil && upperErr != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !o.isHidden(filepath.Join(name, entry.Name())) {
+				merged[entry.Name()] = entry
+			}
+		}
+	} else if upperErr != nil {
+		return nil, pathError("open", name, fs.ErrNotExist)
+	}
+
+	if upperErr == nil {
+		entries, err := o.upper.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			merged[entry.Name()] = entry
+		}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// prepareWrite checks a file can be written at name and makes its parent in the overlay
+func (o *Overlay) prepareWrite(op, name string) error {
+	if info, err := o.stat(name, false); err == nil && info.IsDir() {
+		return pathError(op, name, syscall.EISDIR)
+	}
+	parent, err := o.stat(filepath.Dir(memKey(name)), false)
+	if err != nil {
+		return pathError(op, name, fs.ErrNotExist)
+	}
+	if !parent.IsDir() {
+		return pathError(op, name, syscall.ENOTDIR)
+	}
+	return o.upper.MkdirAll(filepath.Dir(memKey(name)), parent.Mode().Perm())
+}
+
+// copyUp brings a base file or directory into the overlay so it can be changed
+func (o *Overlay) copyUp(op, name string) error {
+	info, err := o.stat(name, false)
+	if err != nil {
+		return pathError(op, name, fs.ErrNotExist)
+	}
+	if _, ok := o.upper.lowerOf(name); ok {
+		return nil
+	}
+
+	key := memKey(name)
+	if err := o.upper.MkdirAll(filepath.Dir(key), 0755); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := o.upper.MkdirAll(key, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return o.upper.Chtimes(key, info.ModTime(), info.ModTime())
+	}
+	return o.upper.link(key, key, info)
+}
+
+// copyTree places what is at src, and everything beneath it, at dst in the overlay
+func (o *Overlay) copyTree(src, dst string, info fs.FileInfo) error {
+	if !info.IsDir() {
+		if _, ok := o.upper.lowerOf(src); ok {
+			return o.upper.clone(src, dst)
+		}
+		return o.upper.link(dst, src, info)
+	}
+
+	if err := o.upper.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	entries, err := o.readDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		childInfo, err := o.stat(filepath.Join(src, entry.Name()), true)
+		if err != nil {
+			return err
+		}
+		if err := o.copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), childInfo); err != nil {
+			return err
+		}
+	}
+	return o.upper.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// hide removes name from the overlay and masks it in the base
+func (o *Overlay) hide(name string) {
+	key := memKey(name)
+	o.upper.RemoveAll(key)
+	o.hidden[key] = true
+}
+
+// isHidden reports whether name or a directory above it was removed
+func (o *Overlay) isHidden(name string) bool {
+	for dir := memKey(name); ; dir = filepath.Dir(dir) {
+		if o.hidden[dir] {
+			return true
+		}
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// vet asks a base that vets changes whether it would make this one
+func (o *Overlay) vet(op, path, target string) error {
+	if vetter, ok := o.base.(Vetter); ok {
+		return vetter.Vet(Change{Op: op, Path: path, Target: target})
+	}
+	return nil
+}
+
+// record notes one mutation
+func (o *Overlay) record(op, path, target string) {
+	o.changes = append(o.changes, Change{Op: op, Path: path, Target: target})
+}
+
+// isWithin reports whether path lies beneath dir
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
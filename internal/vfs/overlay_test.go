@@ -0,0 +1,258 @@
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newOverlay builds an overlay over a memory base holding the given files
+func newOverlay(t *testing.T, files map[string]string) (*Overlay, *Memory) {
+	t.Helper()
+	base := newTree(t, files)
+	return NewOverlay(base), base
+}
+
+func TestOverlayHidesRemovedBaseFiles(t *testing.T) {
+	o, base := newOverlay(t, map[string]string{
+		"/dir/keep.txt":     "keep",
+		"/dir/gone.txt":     "gone",
+		"/dir/sub/deep.txt": "deep",
+		"/other/unrelated":  "x",
+	})
+
+	if err := o.Remove("/dir/gone.txt"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := o.Stat("/dir/gone.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a removed file = %v, want ErrNotExist", err)
+	}
+	if _, err := o.Open("/dir/gone.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open of a removed file = %v, want ErrNotExist", err)
+	}
+	if got, want := names(t, o, "/dir"), []string{"keep.txt", "sub"}; !slices.Equal(got, want) {
+		t.Errorf("overlay lists %v, want %v", got, want)
+	}
+
+	if err := o.RemoveAll("/dir/sub"); err != nil {
+		t.Fatalf("remove all: %v", err)
+	}
+	if _, err := o.Stat("/dir/sub/deep.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("file beneath a removed directory = %v, want ErrNotExist", err)
+	}
+
+	// Making the directory again must not bring back what the base had in it
+	if err := o.MkdirAll("/dir/sub", 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if got := names(t, o, "/dir/sub"); len(got) != 0 {
+		t.Errorf("recreated directory lists %v, want nothing", got)
+	}
+
+	if got := readString(t, base, "/dir/gone.txt"); got != "gone" {
+		t.Errorf("base file holds %q after removal in the overlay, want %q", got, "gone")
+	}
+	if got := readString(t, base, "/dir/sub/deep.txt"); got != "deep" {
+		t.Errorf("base file holds %q after removal in the overlay, want %q", got, "deep")
+	}
+}
+
+func TestOverlayCopiesUpOnWrite(t *testing.T) {
+	o, base := newOverlay(t, map[string]string{
+		"/dir/file.txt":  "original",
+		"/dir/other.txt": "other",
+	})
+
+	if err := o.WriteFile("/dir/file.txt", []byte("changed"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := readString(t, o, "/dir/file.txt"); got != "changed" {
+		t.Errorf("overlay reads %q, want %q", got, "changed")
+	}
+	if got := readString(t, base, "/dir/file.txt"); got != "original" {
+		t.Errorf("base reads %q, want %q", got, "original")
+	}
+
+	// Metadata changes copy the file up but keep reading its contents from the base
+	if err := o.Chmod("/dir/other.txt", 0600); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := o.Chtimes("/dir/other.txt", mtime, mtime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	info, err := o.Stat("/dir/other.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 || !info.ModTime().Equal(mtime) {
+		t.Errorf("overlay shows mode %v and time %v, want 0600 and %v", info.Mode().Perm(), info.ModTime(), mtime)
+	}
+	if info.Size() != int64(len("other")) {
+		t.Errorf("overlay shows size %d, want %d", info.Size(), len("other"))
+	}
+	if got := readString(t, o, "/dir/other.txt"); got != "other" {
+		t.Errorf("overlay reads %q, want %q", got, "other")
+	}
+	baseInfo, err := base.Stat("/dir/other.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseInfo.Mode().Perm() != 0644 || baseInfo.ModTime().Equal(mtime) {
+		t.Errorf("base file changed to mode %v and time %v", baseInfo.Mode().Perm(), baseInfo.ModTime())
+	}
+
+	// A new file in a base directory appears beside the base's entries
+	if err := o.WriteFile("/dir/new.txt", []byte("new"), 0644); err != nil {
+		t.Fatalf("write new: %v", err)
+	}
+	if got, want := names(t, o, "/dir"), []string{"file.txt", "new.txt", "other.txt"}; !slices.Equal(got, want) {
+		t.Errorf("overlay lists %v, want %v", got, want)
+	}
+	if _, err := base.Stat("/dir/new.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("new file reached the base: %v", err)
+	}
+}
+
+func TestOverlayRenameAcrossDirectories(t *testing.T) {
+	o, base := newOverlay(t, map[string]string{
+		"/src/a.txt":        "a",
+		"/src/tree/b.txt":   "b",
+		"/src/tree/c/d.txt": "d",
+		"/dst/existing":     "e",
+	})
+
+	if err := o.Rename("/src/a.txt", "/dst/a.txt"); err != nil {
+		t.Fatalf("rename file: %v", err)
+	}
+	if err := o.Rename("/src/tree", "/dst/tree"); err != nil {
+		t.Fatalf("rename directory: %v", err)
+	}
+
+	if got := readString(t, o, "/dst/a.txt"); got != "a" {
+		t.Errorf("moved file reads %q, want %q", got, "a")
+	}
+	if got := readString(t, o, "/dst/tree/c/d.txt"); got != "d" {
+		t.Errorf("moved nested file reads %q, want %q", got, "d")
+	}
+	if got := names(t, o, "/src"); len(got) != 0 {
+		t.Errorf("source lists %v after the moves, want nothing", got)
+	}
+	if got, want := names(t, o, "/dst"), []string{"a.txt", "existing", "tree"}; !slices.Equal(got, want) {
+		t.Errorf("destination lists %v, want %v", got, want)
+	}
+
+	// Later operations see the earlier moves
+	if err := o.Rename("/dst/tree/b.txt", "/src/b.txt"); err != nil {
+		t.Fatalf("rename back: %v", err)
+	}
+	if got := readString(t, o, "/src/b.txt"); got != "b" {
+		t.Errorf("file moved back reads %q, want %q", got, "b")
+	}
+	if err := o.Rename("/src/a.txt", "/dst/again.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("renaming a moved-away file = %v, want ErrNotExist", err)
+	}
+
+	if got, want := names(t, base, "/src"), []string{"a.txt", "tree"}; !slices.Equal(got, want) {
+		t.Errorf("base source lists %v, want %v", got, want)
+	}
+	if _, err := base.Stat("/dst/tree"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("move reached the base: %v", err)
+	}
+}
+
+func TestOverlayRecordsChanges(t *testing.T) {
+	o, _ := newOverlay(t, map[string]string{
+		"/dir/a.txt": "a",
+		"/dir/b.txt": "b",
+	})
+
+	steps := []func() error{
+		func() error { return o.MkdirAll("/dir/out", 0755) },
+		func() error { return o.WriteFile("/dir/out/c.txt", []byte("c"), 0644) },
+		func() error { return o.Rename("/dir/a.txt", "/dir/out/a.txt") },
+		func() error { return o.Chmod("/dir/b.txt", 0600) },
+		func() error { return o.Remove("/dir/b.txt") },
+		func() error { return o.RemoveAll("/dir/missing") },
+		func() error { return o.Remove("/dir/missing") },
+	}
+	for i, step := range steps {
+		err := step()
+		if wantErr := i == len(steps)-1; (err != nil) != wantErr {
+			t.Fatalf("step %d returned %v", i, err)
+		}
+	}
+
+	want := []Change{
+		{Op: "mkdir", Path: "/dir/out"},
+		{Op: "write", Path: "/dir/out/c.txt"},
+		{Op: "rename", Path: "/dir/a.txt", Target: "/dir/out/a.txt"},
+		{Op: "chmod", Path: "/dir/b.txt"},
+		{Op: "remove", Path: "/dir/b.txt"},
+	}
+	if got := o.Changes(); !slices.Equal(got, want) {
+		t.Errorf("Changes() = %v, want %v", got, want)
+	}
+}
+
+func TestOverlayTrash(t *testing.T) {
+	// A base without a trash records a plain remove
+	o, _ := newOverlay(t, map[string]string{"/file": "x"})
+	if err := Trash(o, "/file"); err != nil {
+		t.Fatalf("trash: %v", err)
+	}
+	if got, want := o.Changes(), []Change{{Op: "remove", Path: "/file"}}; !slices.Equal(got, want) {
+		t.Errorf("Changes() = %v, want %v", got, want)
+	}
+}
+
+// vetting is a base that refuses every change to one path
+type vetting struct {
+	*Memory
+	refused string
+}
+
+func (v *vetting) Vet(change Change) error {
+	if change.Path == v.refused || change.Target == v.refused {
+		return fs.ErrPermission
+	}
+	return nil
+}
+
+func TestOverlayAsksVettingBase(t *testing.T) {
+	base := &vetting{Memory: newTree(t, map[string]string{
+		"/dir/locked": "locked",
+		"/dir/free":   "free",
+	}), refused: "/dir/locked"}
+	o := NewOverlay(base)
+
+	tests := []struct {
+		name   string
+		change func(path string) error
+	}{
+		{"write", func(path string) error { return o.WriteFile(path, []byte("x"), 0644) }},
+		{"remove", o.Remove},
+		{"remove all", o.RemoveAll},
+		{"rename away", func(path string) error { return o.Rename(path, "/dir/moved") }},
+		{"rename over", func(path string) error { return o.Rename("/dir/free", path) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.change("/dir/locked"); !errors.Is(err, fs.ErrPermission) {
+				t.Errorf("refused change = %v, want ErrPermission", err)
+			}
+		})
+	}
+
+	if got := o.Changes(); len(got) != 0 {
+		t.Errorf("refused changes were recorded: %v", got)
+	}
+	if got := readString(t, o, "/dir/locked"); got != "locked" {
+		t.Errorf("refused path holds %q in the overlay, want %q", got, "locked")
+	}
+	if err := o.Remove("/dir/free"); err != nil {
+		t.Errorf("allowed remove: %v", err)
+	}
+}
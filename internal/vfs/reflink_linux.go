@@ -0,0 +1,48 @@
+/**
+ * OS Filesystem Reflinks
+ *
+ * Copy-on-write clones through the FICLONE ioctl, which Btrfs, XFS and other
+ * Linux filesystems that share extents support.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: reflink_linux.go
+ * Description: Reflink support for the OS filesystem on Linux
+ */
+
+package vfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// ficlone is the Linux ioctl that clones one file's extents into another
+const ficlone = 0x40049409
+
+func (OS) Reflink(src, dst string) error {
+	info, err := os.Stat(dst)
+	if err != nil {
+		return err
+	}
+
+	return replaceFile(dst, func(tmp string) error {
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, out.Fd(), ficlone, in.Fd()); errno != 0 {
+			return &os.PathError{Op: "reflink", Path: dst, Err: errno}
+		}
+		// The clone keeps the replaced file's own timestamps
+		return os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	})
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+/**
+ * OS Filesystem Reflinks
+ *
+ * Copy-on-write clones are only made on Linux; elsewhere Reflink reports that
+ * it is unsupported, so callers fall back to a hard link.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: reflink_other.go
+ * Description: Reflink stub for the OS filesystem outside Linux
+ */
+
+package vfs
+
+import "errors"
+
+func (OS) Reflink(src, dst string) error {
+	return pathError("reflink", dst, errors.ErrUnsupported)
+}
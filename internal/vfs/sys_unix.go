@@ -0,0 +1,36 @@
+//go:build !windows
+
+/**
+ * File System Details
+ *
+ * Reads the device, inode, link count, owner and allocated size that Unix
+ * keeps for a file out of its FileInfo.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: sys_unix.go
+ * Description: Unix file details behind FileInfo.Sys
+ */
+
+package vfs
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Sys returns the system's details of a file, or false when info does not
+// come from a real disk
+func Sys(info fs.FileInfo) (SysInfo, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return SysInfo{}, false
+	}
+	return SysInfo{
+		Device: uint64(stat.Dev),
+		Inode:  uint64(stat.Ino),
+		Links:  uint64(stat.Nlink),
+		UID:    int(stat.Uid),
+		Blocks: int64(stat.Blocks),
+	}, true
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+/**
+ * File System Details
+ *
+ * Windows keeps no inode in a FileInfo, so files there have no system details
+ * and are treated as though they came from a filesystem without them.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: sys_windows.go
+ * Description: Windows stub for file details behind FileInfo.Sys
+ */
+
+package vfs
+
+import "io/fs"
+
+// Sys returns the system's details of a file; on Windows it has none
+func Sys(info fs.FileInfo) (SysInfo, bool) {
+	return SysInfo{}, false
+}
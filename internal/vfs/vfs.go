@@ -0,0 +1,178 @@
+/**
+ * Filesystem Abstraction
+ *
+ * The interface every file-handling manager goes through instead of calling
+ * the os package directly, so the same code can run against the real disk,
+ * an in-memory tree, or an overlay that turns any operation into a dry run.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: vfs.go
+ * Description: Filesystem interface and helpers shared by all backends
+ */
+
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FS is a filesystem that files can be read from and changed through
+type FS interface {
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Open(name string) (File, error)
+	Create(name string) (File, error)
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	WriteNewFile(name string, data []byte, perm fs.FileMode) error // like WriteFile, but fails with fs.ErrExist rather than replace a file
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
+	Chmod(name string, mode fs.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+}
+
+// Trasher is a filesystem that can move files to a trash instead of deleting them
+type Trasher interface {
+	Trash(path string) error
+}
+
+// Linker is a filesystem that can make a file share another file's data,
+// replacing what was at the destination in one step
+type Linker interface {
+	Link(oldname, newname string) error // hard link newname to oldname
+	Reflink(src, dst string) error      // copy-on-write clone of src at dst
+}
+
+// Vetter is a filesystem that may refuse changes, such as one guarded by the
+// safety policy; an overlay asks it about each change before recording it
+type Vetter interface {
+	Vet(change Change) error
+}
+
+// File is an open file; files from Open are read-only, files from Create write-only
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+// SysInfo is what the operating system records about a file beyond FileInfo
+type SysInfo struct {
+	Device uint64 // device the file is on
+	Inode  uint64 // file number on that device
+	Links  uint64 // hard links to the file
+	UID    int    // owner
+	Blocks int64  // 512-byte blocks allocated on disk
+}
+
+// Change is one mutation made through a filesystem
+type Change struct {
+	Op     string `json:"op"` // write, mkdir, remove, trash, rename, chmod or chtimes
+	Path   string `json:"path"`
+	Target string `json:"target,omitempty"` // new path of a rename
+}
+
+// Trash moves path, with everything beneath it, to the trash when fsys keeps
+// one and deletes it outright otherwise
+func Trash(fsys FS, path string) error {
+	if trasher, ok := fsys.(Trasher); ok {
+		return trasher.Trash(path)
+	}
+	return fsys.RemoveAll(path)
+}
+
+// Link makes newname a hard link to oldname, replacing the file there
+func Link(fsys FS, oldname, newname string) error {
+	if linker, ok := fsys.(Linker); ok {
+		return linker.Link(oldname, newname)
+	}
+	return pathError("link", newname, errors.ErrUnsupported)
+}
+
+// Reflink makes dst a copy-on-write clone of src, replacing the file there;
+// it fails on filesystems that cannot share data between files
+func Reflink(fsys FS, src, dst string) error {
+	if linker, ok := fsys.(Linker); ok {
+		return linker.Reflink(src, dst)
+	}
+	return pathError("reflink", dst, errors.ErrUnsupported)
+}
+
+// Walk walks the tree rooted at root like filepath.Walk, calling fn for every
+// file and directory in lexical order without following symlinks
+func Walk(fsys FS, root string, fn filepath.WalkFunc) error {
+	info, err := fsys.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walk(fsys, root, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walk visits path and, for directories, everything beneath it
+func walk(fsys FS, path string, info fs.FileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+
+	entries, err := fsys.ReadDir(path)
+	err1 := fn(path, info, err)
+	// A failed read is reported once, then the directory is skipped
+	if err != nil || err1 != nil {
+		return err1
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		childInfo, err := fsys.Lstat(child)
+		if err != nil {
+			if err := fn(child, childInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := walk(fsys, child, childInfo, fn); err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CopyFile copies one file's contents, creating the destination's parent directories
+func CopyFile(fsys FS, src, dst string) error {
+	if err := fsys.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	sourceFile, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := fsys.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, sourceFile)
+	return err
+}
@@ -23,15 +23,34 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"ena/internal/events"
+	"ena/internal/fault"
 )
 
 // FileEvent represents a file system event
 type FileEvent struct {
-	Path      string
-	EventType EventType
-	Timestamp time.Time
-	Size      int64
-	IsDir     bool
+	Path      string    `json:"path"`
+	EventType EventType `json:"event_type"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size"`
+	IsDir     bool      `json:"is_dir"`
+}
+
+// Topics file watchers publish; file events carry a FileEvent and errors a WatchError
+var (
+	TopicCreate = events.Define("watcher.create", "A file appeared under a watched path")
+	TopicModify = events.Define("watcher.modify", "A file under a watched path was written")
+	TopicDelete = events.Define("watcher.delete", "A file under a watched path was removed")
+	TopicRename = events.Define("watcher.rename", "A file under a watched path was renamed")
+	TopicMove   = events.Define("watcher.move", "A file was moved within the watched paths")
+	TopicError  = events.Define("watcher.error", "The file watcher reported an error")
+)
+
+// WatchError is the payload of watcher error events
+type WatchError struct {
+	Error string   `json:"error"`
+	Paths []string `json:"paths"`
 }
 
 // EventType represents the type of file system event
@@ -63,46 +82,89 @@ func (et EventType) String() string {
 	}
 }
 
+// Topic returns the event bus topic for the event type
+func (et EventType) Topic() events.Topic {
+	switch et {
+	case EventCreate:
+		return TopicCreate
+	case EventModify:
+		return TopicModify
+	case EventDelete:
+		return TopicDelete
+	case EventRename:
+		return TopicRename
+	default:
+		return TopicMove
+	}
+}
+
+// MarshalText writes the event type by name, e.g. in event payloads
+func (et EventType) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(et.String())), nil
+}
+
 // EventCallback is a function that gets called when file events occur
 type EventCallback func(event FileEvent)
 
 // WatchConfig holds configuration for file watching
 type WatchConfig struct {
-	Paths            []string
-	Recursive        bool
-	IncludeHidden    bool
-	FileExtensions   []string
-	ExcludePatterns  []string
-	DebounceTime     time.Duration
-	EventCallbacks   map[EventType][]EventCallback
-	DebugMode        bool
-	LogIgnoredEvents bool
-	BatchEvents      bool
-	BatchSize        int
-	BatchTimeout     time.Duration
-	EventPriority    map[EventType]int
-	MetricsEnabled   bool
-	ConfigFile       string
-	HotReload        bool
-	ErrorRecovery    bool
-	MaxRetries       int
-	RetryDelay       time.Duration
+	Paths            []string                      `json:"-" yaml:"-"`
+	Recursive        bool                          `json:"recursive" yaml:"recursive"`
+	IncludeHidden    bool                          `json:"include_hidden" yaml:"include_hidden"`
+	FileExtensions   []string                      `json:"file_extensions" yaml:"file_extensions"`
+	ExcludePatterns  []string                      `json:"exclude_patterns" yaml:"exclude_patterns"`
+	DebounceTime     time.Duration                 `json:"debounce_time" yaml:"debounce_time"`
+	EventCallbacks   map[EventType][]EventCallback `json:"-" yaml:"-"`
+	Events           *events.Bus                   `json:"-" yaml:"-"` // also receives every event
+	DebugMode        bool                          `json:"debug_mode" yaml:"debug_mode"`
+	LogIgnoredEvents bool                          `json:"log_ignored_events" yaml:"log_ignored_events"`
+	BatchEvents      bool                          `json:"batch_events" yaml:"batch_events"`
+	BatchSize        int                           `json:"batch_size" yaml:"batch_size"`
+	BatchTimeout     time.Duration                 `json:"batch_timeout" yaml:"batch_timeout"`
+	EventPriority    map[EventType]int             `json:"-" yaml:"-"`
+	MetricsEnabled   bool                          `json:"metrics_enabled" yaml:"metrics_enabled"`
+	ConfigFile       string                        `json:"-" yaml:"-"`
+	HotReload        bool                          `json:"-" yaml:"-"`
+	ErrorRecovery    bool                          `json:"error_recovery" yaml:"error_recovery"`
+	MaxRetries       int                           `json:"max_retries" yaml:"max_retries"`
+	RetryDelay       time.Duration                 `json:"retry_delay" yaml:"retry_delay"`
+}
+
+// DefaultWatchConfig returns the settings used when nothing is configured
+func DefaultWatchConfig() *WatchConfig {
+	return &WatchConfig{
+		Recursive:        true,
+		IncludeHidden:    false,
+		DebounceTime:     100 * time.Millisecond,
+		EventCallbacks:   make(map[EventType][]EventCallback),
+		DebugMode:        false,
+		LogIgnoredEvents: false,
+		BatchEvents:      false,
+		BatchSize:        10,
+		BatchTimeout:     1 * time.Second,
+		EventPriority:    make(map[EventType]int),
+		MetricsEnabled:   false,
+		HotReload:        false,
+		ErrorRecovery:    true,
+		MaxRetries:       3,
+		RetryDelay:       5 * time.Second,
+	}
 }
 
 // WatcherMetrics holds performance and usage metrics
 type WatcherMetrics struct {
-	EventsProcessed   int64
-	EventsBatched     int64
-	EventsDropped     int64
-	EventsDebounced   int64
-	EventsIgnored     int64
-	PathsWatched      int64
-	ErrorsEncountered int64
-	RetriesAttempted  int64
-	StartTime         time.Time
-	LastEventTime     time.Time
-	AverageLatency    time.Duration
-	PeakLatency       time.Duration
+	EventsProcessed   int64         `json:"events_processed"`
+	EventsBatched     int64         `json:"events_batched"`
+	EventsDropped     int64         `json:"events_dropped"`
+	EventsDebounced   int64         `json:"events_debounced"`
+	EventsIgnored     int64         `json:"events_ignored"`
+	PathsWatched      int64         `json:"paths_watched"`
+	ErrorsEncountered int64         `json:"errors_encountered"`
+	RetriesAttempted  int64         `json:"retries_attempted"`
+	StartTime         time.Time     `json:"start_time"`
+	LastEventTime     time.Time     `json:"last_event_time"`
+	AverageLatency    time.Duration `json:"average_latency"`
+	PeakLatency       time.Duration `json:"peak_latency"`
 }
 
 // EventBatch represents a batch of events for processing
@@ -117,6 +179,7 @@ type FileWatcher struct {
 	watcher        *fsnotify.Watcher
 	config         *WatchConfig
 	callbacks      map[EventType][]EventCallback
+	events         *events.Bus
 	mutex          sync.RWMutex
 	running        bool
 	stopChan       chan bool
@@ -141,27 +204,11 @@ type FileWatcher struct {
 func NewFileWatcher(config *WatchConfig) (*FileWatcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file watcher: %v", err)
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
 	}
 
 	if config == nil {
-		config = &WatchConfig{
-			Recursive:        true,
-			IncludeHidden:    false,
-			DebounceTime:     100 * time.Millisecond,
-			EventCallbacks:   make(map[EventType][]EventCallback),
-			DebugMode:        false,
-			LogIgnoredEvents: false,
-			BatchEvents:      false,
-			BatchSize:        10,
-			BatchTimeout:     1 * time.Second,
-			EventPriority:    make(map[EventType]int),
-			MetricsEnabled:   false,
-			HotReload:        false,
-			ErrorRecovery:    true,
-			MaxRetries:       3,
-			RetryDelay:       5 * time.Second,
-		}
+		config = DefaultWatchConfig()
 	}
 
 	// Set default event priorities
@@ -179,6 +226,7 @@ func NewFileWatcher(config *WatchConfig) (*FileWatcher, error) {
 		watcher:      watcher,
 		config:       config,
 		callbacks:    make(map[EventType][]EventCallback),
+		events:       config.Events,
 		running:      false,
 		stopChan:     make(chan bool, 1),
 		eventChan:    make(chan FileEvent, 100),
@@ -207,13 +255,13 @@ func NewFileWatcher(config *WatchConfig) (*FileWatcher, error) {
 func (fw *FileWatcher) AddPath(path string) error {
 	// Check if path exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("path does not exist: %s", path)
+		return fault.NotFound("path does not exist: %s", path)
 	}
 
 	// Add to watcher
 	err := fw.watcher.Add(path)
 	if err != nil {
-		return fmt.Errorf("failed to add path to watcher: %v", err)
+		return fmt.Errorf("failed to add path to watcher: %w", err)
 	}
 
 	// Track watched path
@@ -229,7 +277,7 @@ func (fw *FileWatcher) AddPath(path string) error {
 	if fw.config.Recursive {
 		err = fw.addRecursivePaths(path)
 		if err != nil {
-			return fmt.Errorf("failed to add recursive paths: %v", err)
+			return fmt.Errorf("failed to add recursive paths: %w", err)
 		}
 	}
 
@@ -328,7 +376,7 @@ func (fw *FileWatcher) Start() error {
 // Stop stops the file watcher
 func (fw *FileWatcher) Stop() error {
 	if !fw.running {
-		return fmt.Errorf("file watcher is not running")
+		return fault.Unavailable("file watcher is not running")
 	}
 
 	fw.running = false
@@ -352,6 +400,7 @@ func (fw *FileWatcher) watchEvents() {
 			if !ok {
 				return
 			}
+			fw.publishError(err)
 			fmt.Printf("File watcher error: %v\n", err)
 
 		case <-fw.stopChan:
@@ -504,8 +553,18 @@ func (fw *FileWatcher) isMoveOperation(path string) bool {
 	return false
 }
 
-// triggerCallbacks calls all registered callbacks for an event
+// publishError announces an error from the underlying watcher on the bus
+func (fw *FileWatcher) publishError(err error) {
+	fw.events.Publish(events.New(TopicError, fmt.Sprintf("File watcher error: %v", err), WatchError{
+		Error: err.Error(),
+		Paths: fw.GetWatchedPaths(),
+	}))
+}
+
+// triggerCallbacks publishes an event on the bus and calls the watcher's own callbacks
 func (fw *FileWatcher) triggerCallbacks(event FileEvent) {
+	fw.events.Publish(events.New(event.EventType.Topic(), fmt.Sprintf("%s %s", event.EventType, event.Path), event))
+
 	fw.mutex.RLock()
 	callbacks, exists := fw.callbacks[event.EventType]
 	fw.mutex.RUnlock()
@@ -572,7 +631,7 @@ func (fw *FileWatcher) AddPathDynamic(path string) error {
 
 	// Check if path exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("path does not exist: %s", path)
+		return fault.NotFound("path does not exist: %s", path)
 	}
 
 	// Add to watcher
@@ -583,7 +642,7 @@ func (fw *FileWatcher) AddPathDynamic(path string) error {
 				return fw.watcher.Add(path)
 			})
 		}
-		return fmt.Errorf("failed to add path to watcher: %v", err)
+		return fmt.Errorf("failed to add path to watcher: %w", err)
 	}
 
 	// Track watched path
@@ -604,7 +663,7 @@ func (fw *FileWatcher) AddPathDynamic(path string) error {
 	if fw.config.Recursive {
 		err = fw.addRecursivePaths(path)
 		if err != nil {
-			return fmt.Errorf("failed to add recursive paths: %v", err)
+			return fmt.Errorf("failed to add recursive paths: %w", err)
 		}
 	}
 
@@ -614,7 +673,7 @@ func (fw *FileWatcher) AddPathDynamic(path string) error {
 // RemovePathDynamic removes a path while the watcher is running
 func (fw *FileWatcher) RemovePathDynamic(path string) error {
 	if !fw.running {
-		return fmt.Errorf("watcher is not running")
+		return fault.Unavailable("watcher is not running")
 	}
 
 	// Remove from watcher
@@ -625,7 +684,7 @@ func (fw *FileWatcher) RemovePathDynamic(path string) error {
 				return fw.watcher.Remove(path)
 			})
 		}
-		return fmt.Errorf("failed to remove path from watcher: %v", err)
+		return fmt.Errorf("failed to remove path from watcher: %w", err)
 	}
 
 	// Remove from tracked paths
@@ -653,13 +712,13 @@ func (fw *FileWatcher) ReloadConfig() error {
 
 	data, err := os.ReadFile(fw.config.ConfigFile)
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %v", err)
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	var newConfig WatchConfig
 	err = json.Unmarshal(data, &newConfig)
 	if err != nil {
-		return fmt.Errorf("failed to parse config file: %v", err)
+		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	// Update configuration
@@ -699,7 +758,7 @@ func (fw *FileWatcher) handleErrorWithRetry(operation string, err error, retryFu
 	retryCount := atomic.LoadInt64(&fw.retryCount)
 	if retryCount >= int64(fw.config.MaxRetries) {
 		atomic.AddInt64(&fw.metrics.ErrorsEncountered, 1)
-		return fmt.Errorf("max retries exceeded for %s: %v", operation, err)
+		return fmt.Errorf("max retries exceeded for %s: %w", operation, err)
 	}
 
 	atomic.AddInt64(&fw.retryCount, 1)
@@ -833,6 +892,7 @@ func (fw *FileWatcher) watchEventsEnhanced() {
 			if !ok {
 				return
 			}
+			fw.publishError(err)
 
 			if fw.config.ErrorRecovery {
 				fw.errorChan <- err
@@ -24,8 +24,9 @@ func setupAppCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Application management commands - handling apps with care ✨
 
 	var appCmd = &cobra.Command{
-		Use:   "app [operation] [args...]",
-		Short: "Application operation commands",
+		Use:     "app [operation] [args...]",
+		GroupID: "app",
+		Short:   "Application operation commands",
 		Long: `Start, stop, restart, list, and display information about applications.
 
 Examples:
@@ -35,13 +36,13 @@ Examples:
   ena app list
   ena app info firefox`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("app", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -51,13 +52,13 @@ Examples:
 		Short: "Start an application",
 		Long:  "Start the specified application.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("app", append([]string{"start"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -67,13 +68,13 @@ Examples:
 		Short: "Stop an application",
 		Long:  "Stop the specified application.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("app", append([]string{"stop"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
@@ -83,13 +84,13 @@ Examples:
 		Short: "Restart an application",
 		Long:  "Restart the specified application.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("app", append([]string{"restart"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -99,13 +100,13 @@ Examples:
 		Short: "List running applications",
 		Long:  "Display a list of currently running applications.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("app", []string{"list"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgCyan).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgCyan).Println(result)
+			return nil
 		},
 	}
 
@@ -115,13 +116,13 @@ Examples:
 		Short: "Show application information",
 		Long:  "Display detailed information about the specified application.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("app", append([]string{"info"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgBlue).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgBlue).Println(result)
+			return nil
 		},
 	}
 
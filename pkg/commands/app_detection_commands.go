@@ -18,32 +18,23 @@ import (
 	"strings"
 	"time"
 
+	"ena/internal/app"
 	"ena/internal/appdetect"
+	"ena/internal/output"
 
 	"github.com/spf13/cobra"
 )
 
-// Global app scanner instance
-var globalAppScanner *appdetect.AppScanner
-
-// getGlobalAppScanner returns the global app scanner instance
-func getGlobalAppScanner() *appdetect.AppScanner {
-	if globalAppScanner == nil {
-		analytics := getGlobalAnalytics()
-		globalAppScanner = appdetect.NewAppScanner(analytics)
-	}
-	return globalAppScanner
-}
-
 // setupAppDetectionCommands adds application detection commands to the root command
-func setupAppDetectionCommands(rootCmd *cobra.Command) {
+func setupAppDetectionCommands(rootCmd *cobra.Command, services *app.App) {
 	// Get app scanner
-	scanner := getGlobalAppScanner()
+	scanner := services.AppScanner
 
 	// Scan apps command
 	scanAppsCmd := &cobra.Command{
-		Use:   "scan-apps",
-		Short: "Scan for installed applications",
+		Use:     "scan-apps",
+		GroupID: "appdetect",
+		Short:   "Scan for installed applications",
 		Long: `Scan the system for installed applications and update the application database.
 Performs comprehensive detection across different platforms and application types.
 
@@ -51,31 +42,31 @@ Examples:
   ena scan-apps
   ena scan-apps --deep
   ena scan-apps --update`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			deepScan, _ := cmd.Flags().GetBool("deep")
 			update, _ := cmd.Flags().GetBool("update")
 
-			fmt.Println("🌸 Scanning for installed applications...")
+			output.Printf("🌸 Scanning for installed applications...\n")
 			if deepScan {
-				fmt.Println("🔍 Deep scan enabled - this may take longer")
+				output.Printf("🔍 Deep scan enabled - this may take longer\n")
 			}
 
 			// Perform scan
 			result, err := scanner.ScanForApps(deepScan)
 			if err != nil {
-				fmt.Printf("❌ Error scanning applications: %v\n", err)
-				return
+				return reportError("❌ Error scanning applications: %v\n", err)
 			}
 
 			// Display results
-			fmt.Printf("✅ Scan completed in %s!\n", result.ScanDuration.String())
-			fmt.Printf("📊 Found %d applications\n", result.AppsFound)
-			fmt.Printf("🔄 Updated %d applications\n", result.AppsUpdated)
-			fmt.Printf("🗑️  Removed %d applications\n", result.AppsRemoved)
-			fmt.Printf("🏷️  Categories found: %d\n", len(result.CategoriesFound))
+			reportResult(result)
+			output.Printf("✅ Scan completed in %s!\n", result.ScanDuration.String())
+			output.Printf("📊 Found %d applications\n", result.AppsFound)
+			output.Printf("🔄 Updated %d applications\n", result.AppsUpdated)
+			output.Printf("🗑️  Removed %d applications\n", result.AppsRemoved)
+			output.Printf("🏷️  Categories found: %d\n", len(result.CategoriesFound))
 
 			if len(result.Errors) > 0 {
-				fmt.Printf("⚠️  Errors encountered: %d\n", len(result.Errors))
+				output.Printf("⚠️  Errors encountered: %d\n", len(result.Errors))
 				if update {
 					for _, err := range result.Errors {
 						fmt.Printf("   - %s\n", err)
@@ -84,7 +75,7 @@ Examples:
 			}
 
 			if update && len(result.Apps) > 0 {
-				fmt.Println("\n🌸 Recently detected applications:")
+				output.Printf("\n🌸 Recently detected applications:\n")
 				// Show first 10 apps
 				limit := 10
 				if len(result.Apps) < limit {
@@ -95,10 +86,10 @@ Examples:
 					app := result.Apps[i]
 					fmt.Printf("%d. %s (%s)\n", i+1, app.DisplayName, app.Category)
 					if app.Version != "" {
-						fmt.Printf("   📦 Version: %s\n", app.Version)
+						output.Printf("   📦 Version: %s\n", app.Version)
 					}
 					if app.Status == appdetect.StatusRunning {
-						fmt.Printf("   🟢 Status: Running\n")
+						output.Printf("   🟢 Status: Running\n")
 					}
 				}
 
@@ -106,6 +97,7 @@ Examples:
 					fmt.Printf("... and %d more applications\n", len(result.Apps)-10)
 				}
 			}
+			return nil
 		},
 	}
 
@@ -114,8 +106,9 @@ Examples:
 
 	// List apps command
 	listAppsCmd := &cobra.Command{
-		Use:   "list-apps",
-		Short: "List detected applications",
+		Use:     "list-apps",
+		GroupID: "appdetect",
+		Short:   "List detected applications",
 		Long: `List all detected applications with filtering options.
 Supports filtering by category, status, and search terms.
 
@@ -124,7 +117,7 @@ Examples:
   ena list-apps --category development
   ena list-apps --running
   ena list-apps --search "code"`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			category, _ := cmd.Flags().GetString("category")
 			status, _ := cmd.Flags().GetString("status")
 			search, _ := cmd.Flags().GetString("search")
@@ -158,46 +151,48 @@ Examples:
 				apps = filteredApps
 			}
 
-			if len(apps) == 0 {
-				fmt.Println("🌸 No applications found matching the criteria")
-				return
-			}
-
 			// Limit results if specified
 			if limit > 0 && limit < len(apps) {
 				apps = apps[:limit]
 			}
+			reportResult(append([]appdetect.AppInfo{}, apps...))
+
+			if len(apps) == 0 {
+				output.Printf("🌸 No applications found matching the criteria\n")
+				return nil
+			}
 
-			fmt.Printf("🌸 Found %d applications (╹◡╹)♡\n", len(apps))
+			output.Printf("🌸 Found %d applications (╹◡╹)♡\n", len(apps))
 			fmt.Println("=====================================")
 
 			for i, app := range apps {
 				fmt.Printf("%d. %s\n", i+1, app.DisplayName)
-				fmt.Printf("   🏷️  Category: %s\n", app.Category)
-				fmt.Printf("   📦 Version: %s\n", app.Version)
-				fmt.Printf("   📂 Path: %s\n", app.ExecutablePath)
+				output.Printf("   🏷️  Category: %s\n", app.Category)
+				output.Printf("   📦 Version: %s\n", app.Version)
+				output.Printf("   📂 Path: %s\n", app.ExecutablePath)
 
-				statusIcon := "⚪"
+				statusIcon := output.Prose("⚪ ")
 				switch app.Status {
 				case appdetect.StatusRunning:
-					statusIcon = "🟢"
+					statusIcon = output.Prose("🟢 ")
 				case appdetect.StatusInstalled:
-					statusIcon = "📦"
+					statusIcon = output.Prose("📦 ")
 				case appdetect.StatusOutdated:
-					statusIcon = "🟡"
+					statusIcon = output.Prose("🟡 ")
 				case appdetect.StatusCorrupted:
-					statusIcon = "🔴"
+					statusIcon = output.Prose("🔴 ")
 				}
-				fmt.Printf("   %s Status: %s\n", statusIcon, app.Status)
+				fmt.Printf("   %sStatus: %s\n", statusIcon, app.Status)
 
 				if app.Description != "" {
-					fmt.Printf("   📝 Description: %s\n", app.Description)
+					output.Printf("   📝 Description: %s\n", app.Description)
 				}
 				if app.IsDefaultApp {
-					fmt.Printf("   ⭐ Default application\n")
+					output.Printf("   ⭐ Default application\n")
 				}
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
@@ -209,116 +204,121 @@ Examples:
 
 	// App info command
 	appInfoCmd := &cobra.Command{
-		Use:   "app-info <app-id>",
-		Short: "Show detailed information about an application",
+		Use:     "app-info <app-id>",
+		GroupID: "appdetect",
+		Short:   "Show detailed information about an application",
 		Long: `Show comprehensive information about a specific application
 including metadata, file associations, and system integration details.
 
 Examples:
   ena app-info app_firefox_1234567890
   ena app-info app_vscode_1234567890`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(appCandidates(scanner), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			appID := args[0]
 
 			app, err := scanner.GetAppByID(appID)
 			if err != nil {
-				fmt.Printf("❌ Error finding application: %v\n", err)
-				return
+				return reportError("❌ Error finding application: %v\n", err)
 			}
 
-			fmt.Printf("🌸 Application Information (╹◡╹)♡\n")
+			reportResult(app)
+			output.Printf("🌸 Application Information (╹◡╹)♡\n")
 			fmt.Printf("===============================\n")
-			fmt.Printf("🆔 ID: %s\n", app.ID)
-			fmt.Printf("📱 Name: %s\n", app.DisplayName)
-			fmt.Printf("🏷️  Category: %s\n", app.Category)
-			fmt.Printf("📦 Version: %s\n", app.Version)
+			output.Printf("🆔 ID: %s\n", app.ID)
+			output.Printf("📱 Name: %s\n", app.DisplayName)
+			output.Printf("🏷️  Category: %s\n", app.Category)
+			output.Printf("📦 Version: %s\n", app.Version)
 
-			statusIcon := "⚪"
+			statusIcon := output.Prose("⚪ ")
 			switch app.Status {
 			case appdetect.StatusRunning:
-				statusIcon = "🟢"
+				statusIcon = output.Prose("🟢 ")
 			case appdetect.StatusInstalled:
-				statusIcon = "📦"
+				statusIcon = output.Prose("📦 ")
 			case appdetect.StatusOutdated:
-				statusIcon = "🟡"
+				statusIcon = output.Prose("🟡 ")
 			case appdetect.StatusCorrupted:
-				statusIcon = "🔴"
+				statusIcon = output.Prose("🔴 ")
 			}
-			fmt.Printf("%s Status: %s\n", statusIcon, app.Status)
+			fmt.Printf("%sStatus: %s\n", statusIcon, app.Status)
 
-			fmt.Printf("📂 Executable: %s\n", app.ExecutablePath)
-			fmt.Printf("📁 Install Path: %s\n", app.InstallPath)
+			output.Printf("📂 Executable: %s\n", app.ExecutablePath)
+			output.Printf("📁 Install Path: %s\n", app.InstallPath)
 
 			if app.IconPath != "" {
-				fmt.Printf("🎨 Icon: %s\n", app.IconPath)
+				output.Printf("🎨 Icon: %s\n", app.IconPath)
 			}
 			if app.Description != "" {
-				fmt.Printf("📝 Description: %s\n", app.Description)
+				output.Printf("📝 Description: %s\n", app.Description)
 			}
 			if app.Author != "" {
-				fmt.Printf("👤 Author: %s\n", app.Author)
+				output.Printf("👤 Author: %s\n", app.Author)
 			}
 			if app.Website != "" {
-				fmt.Printf("🌐 Website: %s\n", app.Website)
+				output.Printf("🌐 Website: %s\n", app.Website)
 			}
 			if app.License != "" {
-				fmt.Printf("📄 License: %s\n", app.License)
+				output.Printf("📄 License: %s\n", app.License)
 			}
 
-			fmt.Printf("📊 Size: %s\n", formatBytesAppDetection(app.Size))
-			fmt.Printf("📅 Detected: %s\n", app.DetectedAt.Format("2006-01-02 15:04:05"))
-			fmt.Printf("🔄 Updated: %s\n", app.UpdatedAt.Format("2006-01-02 15:04:05"))
+			output.Printf("📊 Size: %s\n", formatBytesAppDetection(app.Size))
+			output.Printf("📅 Detected: %s\n", app.DetectedAt.Format("2006-01-02 15:04:05"))
+			output.Printf("🔄 Updated: %s\n", app.UpdatedAt.Format("2006-01-02 15:04:05"))
 
 			if app.InstallDate != nil {
-				fmt.Printf("📦 Installed: %s\n", app.InstallDate.Format("2006-01-02 15:04:05"))
+				output.Printf("📦 Installed: %s\n", app.InstallDate.Format("2006-01-02 15:04:05"))
 			}
 			if app.LastUsed != nil {
-				fmt.Printf("🕒 Last Used: %s\n", app.LastUsed.Format("2006-01-02 15:04:05"))
+				output.Printf("🕒 Last Used: %s\n", app.LastUsed.Format("2006-01-02 15:04:05"))
 			}
 
 			if app.IsDefaultApp {
-				fmt.Printf("⭐ Default Application\n")
+				output.Printf("⭐ Default Application\n")
 			}
 
 			if len(app.FileAssociations) > 0 {
-				fmt.Printf("📎 File Associations: %s\n", strings.Join(app.FileAssociations, ", "))
+				output.Printf("📎 File Associations: %s\n", strings.Join(app.FileAssociations, ", "))
 			}
 
 			if len(app.CommandLineArgs) > 0 {
-				fmt.Printf("⚙️  Command Line Args: %s\n", strings.Join(app.CommandLineArgs, ", "))
+				output.Printf("⚙️  Command Line Args: %s\n", strings.Join(app.CommandLineArgs, ", "))
 			}
+			return nil
 		},
 	}
 
 	// App stats command
 	appStatsCmd := &cobra.Command{
-		Use:   "app-stats",
-		Short: "Show application detection statistics",
+		Use:     "app-stats",
+		GroupID: "appdetect",
+		Short:   "Show application detection statistics",
 		Long: `Show comprehensive statistics about detected applications
 including category distribution, platform information, and system health.
 
 Examples:
   ena app-stats`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			stats := scanner.GetAppStats()
+			reportResult(stats)
 
-			fmt.Println("🌸 Application Detection Statistics (╹◡╹)♡")
+			output.Printf("🌸 Application Detection Statistics (╹◡╹)♡\n")
 			fmt.Println("=========================================")
 
-			fmt.Printf("📊 Total Applications: %v\n", stats["total_apps"])
-			fmt.Printf("🟢 Running Applications: %v\n", stats["running_apps"])
-			fmt.Printf("⭐ Default Applications: %v\n", stats["default_apps"])
-			fmt.Printf("💾 Total Size: %s\n", formatBytesAppDetection(stats["total_size"].(int64)))
-			fmt.Printf("🖥️  Platform: %s\n", stats["platform"])
+			output.Printf("📊 Total Applications: %v\n", stats["total_apps"])
+			output.Printf("🟢 Running Applications: %v\n", stats["running_apps"])
+			output.Printf("⭐ Default Applications: %v\n", stats["default_apps"])
+			output.Printf("💾 Total Size: %s\n", formatBytesAppDetection(stats["total_size"].(int64)))
+			output.Printf("🖥️  Platform: %s\n", stats["platform"])
 
 			if lastScan, ok := stats["last_scan"].(time.Time); ok && !lastScan.IsZero() {
-				fmt.Printf("🔄 Last Scan: %s\n", lastScan.Format("2006-01-02 15:04:05"))
+				output.Printf("🔄 Last Scan: %s\n", lastScan.Format("2006-01-02 15:04:05"))
 			}
 
 			// Category distribution
 			if categories, ok := stats["categories"].(map[string]int); ok && len(categories) > 0 {
-				fmt.Println("\n📈 Category Distribution:")
+				output.Printf("\n📈 Category Distribution:\n")
 				// Sort categories by count
 				type categoryCount struct {
 					name  string
@@ -339,7 +339,7 @@ Examples:
 
 			// Status distribution
 			if statusCounts, ok := stats["status_counts"].(map[string]int); ok && len(statusCounts) > 0 {
-				fmt.Println("\n📊 Status Distribution:")
+				output.Printf("\n📊 Status Distribution:\n")
 				for status, count := range statusCounts {
 					fmt.Printf("   %s: %d\n", status, count)
 				}
@@ -347,75 +347,82 @@ Examples:
 
 			// Time information
 			if oldestInstall, ok := stats["oldest_install"].(time.Time); ok && !oldestInstall.IsZero() {
-				fmt.Printf("\n⏰ Oldest Installation: %s\n", oldestInstall.Format("2006-01-02"))
+				output.Printf("\n⏰ Oldest Installation: %s\n", oldestInstall.Format("2006-01-02"))
 			}
 			if newestInstall, ok := stats["newest_install"].(time.Time); ok && !newestInstall.IsZero() {
-				fmt.Printf("⏰ Newest Installation: %s\n", newestInstall.Format("2006-01-02"))
+				output.Printf("⏰ Newest Installation: %s\n", newestInstall.Format("2006-01-02"))
 			}
+			return nil
 		},
 	}
 
 	// Running apps command
 	runningAppsCmd := &cobra.Command{
-		Use:   "running-apps",
-		Short: "Show currently running applications",
+		Use:     "running-apps",
+		GroupID: "appdetect",
+		Short:   "Show currently running applications",
 		Long: `Show all applications that are currently running
 with their process information and resource usage.
 
 Examples:
   ena running-apps`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			apps := scanner.GetRunningApps()
+			reportResult(append([]appdetect.AppInfo{}, apps...))
 
 			if len(apps) == 0 {
-				fmt.Println("🌸 No running applications detected")
-				return
+				output.Printf("🌸 No running applications detected\n")
+				return nil
 			}
 
-			fmt.Printf("🌸 Running Applications (╹◡╹)♡\n")
+			output.Printf("🌸 Running Applications (╹◡╹)♡\n")
 			fmt.Printf("===============================\n")
 
 			for i, app := range apps {
 				fmt.Printf("%d. %s\n", i+1, app.DisplayName)
-				fmt.Printf("   🏷️  Category: %s\n", app.Category)
-				fmt.Printf("   📦 Version: %s\n", app.Version)
-				fmt.Printf("   📂 Path: %s\n", app.ExecutablePath)
-				fmt.Printf("   🕒 Last Updated: %s\n", app.UpdatedAt.Format("2006-01-02 15:04:05"))
+				output.Printf("   🏷️  Category: %s\n", app.Category)
+				output.Printf("   📦 Version: %s\n", app.Version)
+				output.Printf("   📂 Path: %s\n", app.ExecutablePath)
+				output.Printf("   🕒 Last Updated: %s\n", app.UpdatedAt.Format("2006-01-02 15:04:05"))
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
 	// Default apps command
 	defaultAppsCmd := &cobra.Command{
-		Use:   "default-apps",
-		Short: "Show default applications for file types",
+		Use:     "default-apps",
+		GroupID: "appdetect",
+		Short:   "Show default applications for file types",
 		Long: `Show applications that are set as defaults for various file types
 and system operations.
 
 Examples:
   ena default-apps`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			apps := scanner.GetDefaultApps()
+			reportResult(append([]appdetect.AppInfo{}, apps...))
 
 			if len(apps) == 0 {
-				fmt.Println("🌸 No default applications configured")
-				return
+				output.Printf("🌸 No default applications configured\n")
+				return nil
 			}
 
-			fmt.Printf("🌸 Default Applications (╹◡╹)♡\n")
+			output.Printf("🌸 Default Applications (╹◡╹)♡\n")
 			fmt.Printf("===============================\n")
 
 			for i, app := range apps {
 				fmt.Printf("%d. %s\n", i+1, app.DisplayName)
-				fmt.Printf("   🏷️  Category: %s\n", app.Category)
-				fmt.Printf("   📂 Path: %s\n", app.ExecutablePath)
+				output.Printf("   🏷️  Category: %s\n", app.Category)
+				output.Printf("   📂 Path: %s\n", app.ExecutablePath)
 
 				if len(app.FileAssociations) > 0 {
-					fmt.Printf("   📎 File Types: %s\n", strings.Join(app.FileAssociations, ", "))
+					output.Printf("   📎 File Types: %s\n", strings.Join(app.FileAssociations, ", "))
 				}
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
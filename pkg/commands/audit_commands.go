@@ -0,0 +1,193 @@
+/**
+ * Audit Commands
+ *
+ * Provides commands for reading the audit log of deletes, moves, restores,
+ * app kills and other mutating actions, and for checking that it has not
+ * been tampered with.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: audit_commands.go
+ * Description: Audit log query and verification command definitions
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/audit"
+	"ena/internal/fault"
+	"ena/internal/output"
+)
+
+// auditVerification is the result of checking the audit log's hash chain
+type auditVerification struct {
+	Path    string            `json:"path"`
+	Entries int               `json:"entries"`
+	Intact  bool              `json:"intact"`
+	Break   *audit.ChainError `json:"break,omitempty"`
+}
+
+// setupAuditCommands sets up audit log commands
+func setupAuditCommands(rootCmd *cobra.Command) {
+	// Audit listing command
+	auditCmd := &cobra.Command{
+		Use:     "audit",
+		GroupID: "audit",
+		Short:   "Show the log of actions that changed files, apps or the system",
+		Long: fmt.Sprintf(`Every delete, move, copy, rename, restore, backup deletion, app start or
+kill and system shutdown Ena performs is appended to %s,
+together with the command that caused it and whether it worked. Dry runs are
+not logged.
+
+Each entry includes the hash of the entry before it, so editing or removing
+lines is detected by "ena audit verify".
+
+Times for --since and --until are ages (30m, 24h, 7d, 2w), dates (2006-01-02)
+or RFC 3339 timestamps.
+
+Examples:
+  ena audit
+  ena audit --since 24h --outcome failure
+  ena audit --path ~/Documents --action delete
+  ena audit --command batch-move --limit 0
+  ena audit verify`, audit.Default().Path()),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := auditFilterFromFlags(cmd)
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			entries, err := audit.Default().Entries()
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			selected := filter.Select(entries)
+			if limit > 0 && len(selected) > limit {
+				selected = selected[len(selected)-limit:]
+			}
+			reportResult(selected)
+
+			if len(selected) == 0 {
+				output.Printf("🌸 No audit entries found\n")
+				return nil
+			}
+
+			output.Printf("🧾 Audit Log (%d of %d entries)\n", len(selected), len(entries))
+			fmt.Println("========================")
+			for _, entry := range selected {
+				showAuditEntry(entry)
+			}
+			return nil
+		},
+	}
+
+	auditCmd.Flags().String("since", "", "Only show entries at or after this time")
+	auditCmd.Flags().String("until", "", "Only show entries at or before this time")
+	auditCmd.Flags().String("path", "", "Only show entries for this path or anything beneath it")
+	auditCmd.Flags().String("command", "", "Only show entries caused by commands starting with this")
+	auditCmd.Flags().String("action", "", "Only show this action (delete, move, copy, restore, kill, ...)")
+	auditCmd.Flags().String("source", "", "Only show entries from this component (file, batch, undo, backup, pattern, organizer, app, system)")
+	auditCmd.Flags().String("outcome", "", "Only show success or failure")
+	auditCmd.Flags().Int("limit", 50, "Show at most this many of the newest entries (0 = all)")
+
+	// Audit verify command
+	auditVerifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that the audit log has not been modified",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := audit.Default()
+			entries, err := log.Entries()
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			result := auditVerification{Path: log.Path(), Entries: len(entries), Intact: true}
+			var chainErr *audit.ChainError
+			if err := audit.Verify(entries); errors.As(err, &chainErr) {
+				result.Intact = false
+				result.Break = chainErr
+			}
+			reportResult(result)
+
+			if !result.Intact {
+				return reportError("❌ %v\n", chainErr)
+			}
+			output.Printf("✅ Audit log intact: %d entries verified\n", len(entries))
+			output.Printf("📁 %s\n", log.Path())
+			return nil
+		},
+	}
+
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+// auditFilterFromFlags builds an audit filter from the audit command's flags
+func auditFilterFromFlags(cmd *cobra.Command) (audit.Filter, error) {
+	var filter audit.Filter
+	now := time.Now()
+
+	since, _ := cmd.Flags().GetString("since")
+	if since != "" {
+		t, err := audit.ParseTime(since, now)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = t
+	}
+
+	until, _ := cmd.Flags().GetString("until")
+	if until != "" {
+		t, err := audit.ParseTime(until, now)
+		if err != nil {
+			return filter, err
+		}
+		filter.Until = t
+	}
+
+	outcome, _ := cmd.Flags().GetString("outcome")
+	switch audit.Outcome(outcome) {
+	case "", audit.OutcomeSuccess, audit.OutcomeFailure:
+		filter.Outcome = audit.Outcome(outcome)
+	default:
+		return filter, fault.Invalid("invalid outcome %q (expected success or failure)", outcome)
+	}
+
+	filter.Path, _ = cmd.Flags().GetString("path")
+	filter.Command, _ = cmd.Flags().GetString("command")
+	filter.Action, _ = cmd.Flags().GetString("action")
+	filter.Source, _ = cmd.Flags().GetString("source")
+	return filter, nil
+}
+
+// showAuditEntry prints one audit entry
+func showAuditEntry(entry audit.Entry) {
+	icon := output.Prose("✅ ")
+	if entry.Outcome == audit.OutcomeFailure {
+		icon = output.Prose("❌ ")
+	}
+
+	subject := entry.Path
+	if entry.Target != "" {
+		subject = fmt.Sprintf(output.Prose("%s → %s"), entry.Path, entry.Target)
+	}
+
+	fmt.Printf("%s#%d %s %s/%s %s\n", icon, entry.Seq, entry.Time.Format("2006-01-02 15:04:05"),
+		entry.Source, entry.Action, subject)
+	if entry.Command != "" {
+		output.Printf("   💻 ena %s\n", entry.Command)
+	}
+	if entry.Error != "" {
+		output.Printf("   ⚠️ %s\n", entry.Error)
+	}
+}
@@ -19,32 +19,23 @@ import (
 	"strings"
 	"time"
 
+	"ena/internal/app"
 	"ena/internal/backup"
+	"ena/internal/output"
 
 	"github.com/spf13/cobra"
 )
 
-// Global backup engine instance
-var globalBackupEngine *backup.BackupEngine
-
-// getGlobalBackupEngine returns the global backup engine instance
-func getGlobalBackupEngine() *backup.BackupEngine {
-	if globalBackupEngine == nil {
-		analytics := getGlobalAnalytics()
-		globalBackupEngine = backup.NewBackupEngine(analytics)
-	}
-	return globalBackupEngine
-}
-
 // setupBackupCommands adds backup management commands to the root command
-func setupBackupCommands(rootCmd *cobra.Command) {
+func setupBackupCommands(rootCmd *cobra.Command, services *app.App) {
 	// Get backup engine
-	engine := getGlobalBackupEngine()
+	engine := services.Backups
 
 	// Create backup command
 	createBackupCmd := &cobra.Command{
-		Use:   "create-backup <path>",
-		Short: "Create a backup of a file or directory",
+		Use:     "create-backup <path>",
+		GroupID: "backup",
+		Short:   "Create a backup of a file or directory",
 		Long: `Create a backup of the specified file or directory.
 The backup will be stored with metadata and checksums for integrity verification.
 
@@ -53,7 +44,7 @@ Examples:
   ena create-backup ~/Projects/my-app
   ena create-backup /etc/config --description "System config backup"`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			sourcePath := args[0]
 			description, _ := cmd.Flags().GetString("description")
 			tags, _ := cmd.Flags().GetStringSlice("tags")
@@ -61,35 +52,35 @@ Examples:
 
 			// Validate source path
 			if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-				fmt.Printf("❌ Source path does not exist: %s\n", sourcePath)
-				return
+				return reportErrorCode(exitNotFound, "❌ Source path does not exist: %s\n", sourcePath)
 			}
 
 			if description == "" {
 				description = fmt.Sprintf("Manual backup of %s", filepath.Base(sourcePath))
 			}
 
-			fmt.Printf("🌸 Creating backup of: %s\n", sourcePath)
+			output.Printf("🌸 Creating backup of: %s\n", sourcePath)
 			if len(tags) > 0 {
-				fmt.Printf("🏷️  Tags: %s\n", strings.Join(tags, ", "))
+				output.Printf("🏷️  Tags: %s\n", strings.Join(tags, ", "))
 			}
 
 			// Create backup
 			metadata, err := engine.CreateBackup(sourcePath, operationID, description, tags)
 			if err != nil {
-				fmt.Printf("❌ Error creating backup: %v\n", err)
-				return
+				return reportError("❌ Error creating backup: %v\n", err)
 			}
 
-			fmt.Printf("✅ Backup created successfully!\n")
-			fmt.Printf("🆔 Backup ID: %s\n", filepath.Base(metadata.BackupPath))
-			fmt.Printf("📂 Backup Path: %s\n", metadata.BackupPath)
-			fmt.Printf("📊 Size: %s\n", formatBytesBackup(metadata.Size))
-			fmt.Printf("🔍 Checksum: %s\n", metadata.Checksum)
-			fmt.Printf("📅 Created: %s\n", metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+			reportResult(metadata)
+			output.Printf("✅ Backup created successfully!\n")
+			output.Printf("🆔 Backup ID: %s\n", filepath.Base(metadata.BackupPath))
+			output.Printf("📂 Backup Path: %s\n", metadata.BackupPath)
+			output.Printf("📊 Size: %s\n", formatBytesBackup(metadata.Size))
+			output.Printf("🔍 Checksum: %s\n", metadata.Checksum)
+			output.Printf("📅 Created: %s\n", metadata.CreatedAt.Format("2006-01-02 15:04:05"))
 			if metadata.ExpiresAt != nil {
-				fmt.Printf("⏰ Expires: %s\n", metadata.ExpiresAt.Format("2006-01-02 15:04:05"))
+				output.Printf("⏰ Expires: %s\n", metadata.ExpiresAt.Format("2006-01-02 15:04:05"))
 			}
+			return nil
 		},
 	}
 
@@ -98,8 +89,9 @@ Examples:
 
 	// List backups command
 	listBackupsCmd := &cobra.Command{
-		Use:   "list-backups",
-		Short: "List all backups",
+		Use:     "list-backups",
+		GroupID: "backup",
+		Short:   "List all backups",
 		Long: `List all backups with their details.
 Supports filtering by operation, type, status, and tags.
 
@@ -107,7 +99,7 @@ Examples:
   ena list-backups
   ena list-backups --operation-id manual_1234567890
   ena list-backups --type file --status verified`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			operationID, _ := cmd.Flags().GetString("operation-id")
 			backupType, _ := cmd.Flags().GetString("type")
 			status, _ := cmd.Flags().GetString("status")
@@ -130,38 +122,41 @@ Examples:
 			}
 
 			backups := engine.ListBackups(filter)
-			if len(backups) == 0 {
-				fmt.Println("🌸 No backups found matching the criteria")
-				return
-			}
 
 			// Limit results if specified
 			if limit > 0 && limit < len(backups) {
 				backups = backups[:limit]
 			}
+			reportResult(append([]backup.BackupMetadata{}, backups...))
 
-			fmt.Printf("🌸 Found %d backups (╹◡╹)♡\n", len(backups))
+			if len(backups) == 0 {
+				output.Printf("🌸 No backups found matching the criteria\n")
+				return nil
+			}
+
+			output.Printf("🌸 Found %d backups (╹◡╹)♡\n", len(backups))
 			fmt.Println("=====================================")
 
 			for i, backup := range backups {
 				fmt.Printf("%d. %s\n", i+1, filepath.Base(backup.BackupPath))
-				fmt.Printf("   📂 Original: %s\n", backup.OriginalPath)
-				fmt.Printf("   💾 Backup: %s\n", backup.BackupPath)
-				fmt.Printf("   📊 Size: %s\n", formatBytesBackup(backup.Size))
-				fmt.Printf("   🏷️  Type: %s | Status: %s\n", backup.Type, backup.Status)
-				fmt.Printf("   📅 Created: %s\n", backup.CreatedAt.Format("2006-01-02 15:04:05"))
+				output.Printf("   📂 Original: %s\n", backup.OriginalPath)
+				output.Printf("   💾 Backup: %s\n", backup.BackupPath)
+				output.Printf("   📊 Size: %s\n", formatBytesBackup(backup.Size))
+				output.Printf("   🏷️  Type: %s | Status: %s\n", backup.Type, backup.Status)
+				output.Printf("   📅 Created: %s\n", backup.CreatedAt.Format("2006-01-02 15:04:05"))
 				if backup.ExpiresAt != nil {
-					fmt.Printf("   ⏰ Expires: %s\n", backup.ExpiresAt.Format("2006-01-02 15:04:05"))
+					output.Printf("   ⏰ Expires: %s\n", backup.ExpiresAt.Format("2006-01-02 15:04:05"))
 				}
 				if len(backup.Tags) > 0 {
-					fmt.Printf("   🏷️  Tags: %s\n", strings.Join(backup.Tags, ", "))
+					output.Printf("   🏷️  Tags: %s\n", strings.Join(backup.Tags, ", "))
 				}
 				if backup.Description != "" {
-					fmt.Printf("   📝 Description: %s\n", backup.Description)
+					output.Printf("   📝 Description: %s\n", backup.Description)
 				}
-				fmt.Printf("   🔍 Checksum: %s\n", backup.Checksum)
+				output.Printf("   🔍 Checksum: %s\n", backup.Checksum)
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
@@ -173,8 +168,9 @@ Examples:
 
 	// Restore backup command
 	restoreBackupCmd := &cobra.Command{
-		Use:   "restore-backup <backup-id> [destination]",
-		Short: "Restore a backup to its original location or a new location",
+		Use:     "restore-backup <backup-id> [destination]",
+		GroupID: "backup",
+		Short:   "Restore a backup to its original location or a new location",
 		Long: `Restore a backup to its original location or a specified destination.
 The backup will be verified before restoration.
 
@@ -182,8 +178,9 @@ Examples:
   ena restore-backup backup_1234567890
   ena restore-backup backup_1234567890 ~/restored-file.txt
   ena restore-backup backup_1234567890 --overwrite`,
-		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeFirstArg(backupCandidates(engine), cobra.ShellCompDirectiveDefault),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			backupID := args[0]
 			var destinationPath string
 			if len(args) > 1 {
@@ -191,22 +188,22 @@ Examples:
 			}
 			overwrite, _ := cmd.Flags().GetBool("overwrite")
 
-			fmt.Printf("🌸 Restoring backup: %s\n", backupID)
+			output.Printf("🌸 Restoring backup: %s\n", backupID)
 			if destinationPath != "" {
-				fmt.Printf("📂 Destination: %s\n", destinationPath)
+				output.Printf("📂 Destination: %s\n", destinationPath)
 			}
 			if overwrite {
-				fmt.Println("⚠️  Overwrite mode enabled")
+				output.Printf("⚠️  Overwrite mode enabled\n")
 			}
 
 			// Restore backup
 			err := engine.RestoreBackup(backupID, destinationPath, overwrite)
 			if err != nil {
-				fmt.Printf("❌ Error restoring backup: %v\n", err)
-				return
+				return reportError("❌ Error restoring backup: %v\n", err)
 			}
 
-			fmt.Printf("✅ Backup restored successfully!\n")
+			output.Printf("✅ Backup restored successfully!\n")
+			return nil
 		},
 	}
 
@@ -214,39 +211,40 @@ Examples:
 
 	// Delete backup command
 	deleteBackupCmd := &cobra.Command{
-		Use:   "delete-backup <backup-id>",
-		Short: "Delete a backup and its associated files",
+		Use:     "delete-backup <backup-id>",
+		GroupID: "backup",
+		Short:   "Delete a backup and its associated files",
 		Long: `Delete a backup and remove all associated files.
 This action cannot be undone.
 
 Examples:
   ena delete-backup backup_1234567890`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(backupCandidates(engine), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			backupID := args[0]
 			confirm, _ := cmd.Flags().GetBool("confirm")
 
 			if !confirm {
-				fmt.Printf("⚠️  This will permanently delete backup %s\n", backupID)
+				output.Printf("⚠️  This will permanently delete backup %s\n", backupID)
 				fmt.Print("Type 'yes' to confirm: ")
 				var response string
 				fmt.Scanln(&response)
 				if strings.ToLower(response) != "yes" {
-					fmt.Println("❌ Operation cancelled")
-					return
+					return reportError("❌ Operation cancelled\n")
 				}
 			}
 
-			fmt.Printf("🌸 Deleting backup: %s\n", backupID)
+			output.Printf("🌸 Deleting backup: %s\n", backupID)
 
 			// Delete backup
 			err := engine.DeleteBackup(backupID)
 			if err != nil {
-				fmt.Printf("❌ Error deleting backup: %v\n", err)
-				return
+				return reportError("❌ Error deleting backup: %v\n", err)
 			}
 
-			fmt.Printf("✅ Backup deleted successfully!\n")
+			output.Printf("✅ Backup deleted successfully!\n")
+			return nil
 		},
 	}
 
@@ -254,26 +252,28 @@ Examples:
 
 	// Backup stats command
 	backupStatsCmd := &cobra.Command{
-		Use:   "backup-stats",
-		Short: "Show backup statistics and system information",
+		Use:     "backup-stats",
+		GroupID: "backup",
+		Short:   "Show backup statistics and system information",
 		Long: `Show comprehensive backup statistics including total backups,
 storage usage, status distribution, and system health.
 
 Examples:
   ena backup-stats`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			stats := engine.GetBackupStats()
+			reportResult(map[string]interface{}{"stats": stats, "config": engine.GetConfig()})
 
-			fmt.Println("🌸 Backup System Statistics (╹◡╹)♡")
+			output.Printf("🌸 Backup System Statistics (╹◡╹)♡\n")
 			fmt.Println("=====================================")
 
-			fmt.Printf("📊 Total Backups: %v\n", stats["total_backups"])
-			fmt.Printf("⚙️  Total Operations: %v\n", stats["total_operations"])
-			fmt.Printf("💾 Total Size: %s\n", formatBytesBackup(stats["total_size"].(int64)))
+			output.Printf("📊 Total Backups: %v\n", stats["total_backups"])
+			output.Printf("⚙️  Total Operations: %v\n", stats["total_operations"])
+			output.Printf("💾 Total Size: %s\n", formatBytesBackup(stats["total_size"].(int64)))
 
 			// Status distribution
 			if statusCounts, ok := stats["status_counts"].(map[string]int); ok {
-				fmt.Println("\n📈 Status Distribution:")
+				output.Printf("\n📈 Status Distribution:\n")
 				for status, count := range statusCounts {
 					fmt.Printf("   %s: %d\n", status, count)
 				}
@@ -281,7 +281,7 @@ Examples:
 
 			// Type distribution
 			if typeCounts, ok := stats["type_counts"].(map[string]int); ok {
-				fmt.Println("\n📁 Type Distribution:")
+				output.Printf("\n📁 Type Distribution:\n")
 				for backupType, count := range typeCounts {
 					fmt.Printf("   %s: %d\n", backupType, count)
 				}
@@ -289,15 +289,15 @@ Examples:
 
 			// Time information
 			if oldestBackup, ok := stats["oldest_backup"].(time.Time); ok && !oldestBackup.IsZero() {
-				fmt.Printf("\n⏰ Oldest Backup: %s\n", oldestBackup.Format("2006-01-02 15:04:05"))
+				output.Printf("\n⏰ Oldest Backup: %s\n", oldestBackup.Format("2006-01-02 15:04:05"))
 			}
 			if newestBackup, ok := stats["newest_backup"].(time.Time); ok && !newestBackup.IsZero() {
-				fmt.Printf("⏰ Newest Backup: %s\n", newestBackup.Format("2006-01-02 15:04:05"))
+				output.Printf("⏰ Newest Backup: %s\n", newestBackup.Format("2006-01-02 15:04:05"))
 			}
 
 			// Configuration
 			config := engine.GetConfig()
-			fmt.Println("\n⚙️  Configuration:")
+			output.Printf("\n⚙️  Configuration:\n")
 			fmt.Printf("   Enabled: %v\n", config.Enabled)
 			fmt.Printf("   Max Backups: %d\n", config.MaxBackups)
 			fmt.Printf("   Retention Days: %d\n", config.RetentionDays)
@@ -305,32 +305,35 @@ Examples:
 			fmt.Printf("   Encryption: %v\n", config.Encryption)
 			fmt.Printf("   Backup Directory: %s\n", config.BackupDirectory)
 			fmt.Printf("   Auto Cleanup: %v\n", config.AutoCleanup)
+			return nil
 		},
 	}
 
 	// Cleanup command
 	cleanupCmd := &cobra.Command{
-		Use:   "backup-cleanup",
-		Short: "Clean up expired backups",
+		Use:     "backup-cleanup",
+		GroupID: "backup",
+		Short:   "Clean up expired backups",
 		Long: `Remove expired backups based on the retention policy.
 This helps free up disk space and maintain system performance.
 
 Examples:
   ena backup-cleanup`,
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("🌸 Cleaning up expired backups...")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output.Printf("🌸 Cleaning up expired backups...\n")
 
 			cleanedCount, err := engine.CleanupExpiredBackups()
 			if err != nil {
-				fmt.Printf("❌ Error during cleanup: %v\n", err)
-				return
+				return reportError("❌ Error during cleanup: %v\n", err)
 			}
 
+			reportResult(map[string]int{"cleaned": cleanedCount})
 			if cleanedCount == 0 {
-				fmt.Println("✅ No expired backups found - system is clean!")
+				output.Printf("✅ No expired backups found - system is clean!\n")
 			} else {
-				fmt.Printf("✅ Cleaned up %d expired backups\n", cleanedCount)
+				output.Printf("✅ Cleaned up %d expired backups\n", cleanedCount)
 			}
+			return nil
 		},
 	}
 
@@ -13,51 +13,53 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
+	"ena/internal/app"
 	"ena/internal/batch"
+	"ena/internal/core"
+	"ena/internal/daemon"
+	"ena/internal/output"
+	"ena/internal/vfs"
 
 	"github.com/spf13/cobra"
 )
 
-// Global batch manager instance
-var globalBatchManager *batch.BatchManager
-
-// getGlobalBatchManager returns the global batch manager instance
-func getGlobalBatchManager() *batch.BatchManager {
-	if globalBatchManager == nil {
-		analytics := getGlobalAnalytics()
-		globalBatchManager = batch.NewBatchManager(analytics)
-	}
-	return globalBatchManager
-}
-
 // setupBatchCommands adds batch operation commands to the root command
-func setupBatchCommands(rootCmd *cobra.Command) {
+func setupBatchCommands(rootCmd *cobra.Command, services *app.App) {
+	// Flags default to the batch section of config.yaml
+	defaults := services.Config.Batch
+
 	// Batch delete command
 	batchDeleteCmd := &cobra.Command{
-		Use:   "batch-delete <path1> [path2] [path3] ...",
-		Short: "Delete multiple files and folders in batch",
+		Use:     "batch-delete <path1> [path2] [path3] ...",
+		GroupID: "batch",
+		Short:   "Delete multiple files and folders in batch",
 		Long: `Delete multiple files and folders efficiently with progress tracking.
 Supports wildcards and recursive deletion with comprehensive error handling.
+Deleted items go to the trash, where "ena trash" can restore them, unless
+--permanent is given.
 
 Examples:
   ena batch-delete file1.txt file2.txt folder1/
   ena batch-delete *.tmp --dry-run
+  ena batch-delete ~/.cache/thumbnails --permanent
   ena batch-delete /tmp/old_files --confirm-each
   ena batch-delete folder1 folder2 --max-concurrency 8`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			batchManager := getGlobalBatchManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			batchManager := services.Batch
 
 			// Parse flags
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 			confirmEach, _ := cmd.Flags().GetBool("confirm-each")
 			maxConcurrency, _ := cmd.Flags().GetInt("max-concurrency")
 			skipErrors, _ := cmd.Flags().GetBool("skip-errors")
+			permanent, _ := cmd.Flags().GetBool("permanent")
 
 			// Expand wildcards
 			var expandedPaths []string
@@ -65,7 +67,7 @@ Examples:
 				if strings.Contains(arg, "*") || strings.Contains(arg, "?") {
 					matches, err := filepath.Glob(arg)
 					if err != nil {
-						fmt.Printf("❌ Error expanding pattern %s: %v\n", arg, err)
+						reportError("❌ Error expanding pattern %s: %v\n", arg, err)
 						continue
 					}
 					expandedPaths = append(expandedPaths, matches...)
@@ -75,63 +77,67 @@ Examples:
 			}
 
 			if len(expandedPaths) == 0 {
-				fmt.Println("❌ No files or folders found to delete")
-				return
+				return reportError("❌ No files or folders found to delete\n")
 			}
 
 			// Create batch config
-			config := batch.BatchConfig{
-				MaxConcurrency:   maxConcurrency,
-				SkipErrors:       skipErrors,
-				DryRun:           dryRun,
-				ConfirmEach:      confirmEach,
-				ProgressInterval: 500 * time.Millisecond,
-				RetryCount:       2,
-				RetryDelay:       1 * time.Second,
+			config := defaults
+			config.MaxConcurrency = maxConcurrency
+			config.SkipErrors = skipErrors
+			config.DryRun = dryRun
+			config.ConfirmEach = confirmEach
+			config.Permanent = permanent
+
+			// Let a running daemon own the job so it outlives this process
+			if !dryRun && !confirmEach && submitToDaemon(services, "delete", expandedPaths, "", config) {
+				return nil
 			}
 
 			// Create batch job
 			job, err := batchManager.BatchDelete(expandedPaths, config)
 			if err != nil {
-				fmt.Printf("❌ Error creating batch delete job: %v\n", err)
-				return
+				return reportError("❌ Error creating batch delete job: %v\n", err)
 			}
 
-			fmt.Printf("🌸 Created batch delete job: %s\n", job.Name)
-			fmt.Printf("📊 Total items: %d | Total size: %s\n",
+			output.Printf("🌸 Created batch delete job: %s\n", job.Name)
+			output.Printf("📊 Total items: %d | Total size: %s\n",
 				len(job.Operations), formatBytes(job.TotalSize))
 
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be deleted")
+				output.Printf("🔍 Dry run mode - no files will be deleted\n")
 			}
 
 			// Execute job
-			fmt.Println("🚀 Starting batch delete operation...")
+			output.Printf("🚀 Starting batch delete operation...\n")
 			err = batchManager.ExecuteBatchJob(job.ID)
 			if err != nil {
-				fmt.Printf("❌ Error executing batch delete: %v\n", err)
-				return
+				return reportError("❌ Error executing batch delete: %v\n", err)
 			}
 
 			// Show results
 			finalJob, _ := batchManager.GetJobStatus(job.ID)
-			fmt.Printf("✅ Batch delete completed!\n")
-			fmt.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
+			reportResult(finalJob)
+			output.Printf("✅ Batch delete completed!\n")
+			output.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
 				finalJob.SuccessCount, finalJob.ErrorCount, finalJob.SkippedCount)
-			fmt.Printf("⏱️  Duration: %s\n", finalJob.Duration.String())
+			output.Printf("⏱️  Duration: %s\n", finalJob.Duration.String())
+			showChanges("", finalJob.Changes)
+			return reportJobErrors(finalJob)
 		},
 	}
 
 	// Add flags
-	batchDeleteCmd.Flags().Bool("dry-run", false, "Preview what would be deleted without actually deleting")
-	batchDeleteCmd.Flags().Bool("confirm-each", false, "Confirm each deletion individually")
-	batchDeleteCmd.Flags().Int("max-concurrency", 4, "Maximum concurrent operations")
-	batchDeleteCmd.Flags().Bool("skip-errors", true, "Continue on errors")
+	batchDeleteCmd.Flags().Bool("dry-run", defaults.DryRun, "Preview what would be deleted without actually deleting")
+	batchDeleteCmd.Flags().Bool("confirm-each", defaults.ConfirmEach, "Confirm each deletion individually")
+	batchDeleteCmd.Flags().Int("max-concurrency", defaults.MaxConcurrency, "Maximum concurrent operations")
+	batchDeleteCmd.Flags().Bool("skip-errors", defaults.SkipErrors, "Continue on errors")
+	batchDeleteCmd.Flags().Bool("permanent", defaults.Permanent, "Delete outright instead of moving to the trash")
 
 	// Batch copy command
 	batchCopyCmd := &cobra.Command{
-		Use:   "batch-copy <source1> [source2] ... <destination>",
-		Short: "Copy multiple files and folders recursively",
+		Use:     "batch-copy <source1> [source2] ... <destination>",
+		GroupID: "batch",
+		Short:   "Copy multiple files and folders recursively",
 		Long: `Copy multiple files and folders recursively with progress tracking.
 Supports wildcards and preserves file permissions and timestamps.
 
@@ -141,8 +147,8 @@ Examples:
   ena batch-copy *.txt /backup/ --preserve-permissions
   ena batch-copy source/ /dest/ --exclude "*.tmp" --exclude "*.log"`,
 		Args: cobra.MinimumNArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
-			batchManager := getGlobalBatchManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			batchManager := services.Batch
 
 			// Parse arguments
 			sources := args[:len(args)-1]
@@ -162,7 +168,7 @@ Examples:
 				if strings.Contains(source, "*") || strings.Contains(source, "?") {
 					matches, err := filepath.Glob(source)
 					if err != nil {
-						fmt.Printf("❌ Error expanding pattern %s: %v\n", source, err)
+						reportError("❌ Error expanding pattern %s: %v\n", source, err)
 						continue
 					}
 					expandedSources = append(expandedSources, matches...)
@@ -172,71 +178,70 @@ Examples:
 			}
 
 			if len(expandedSources) == 0 {
-				fmt.Println("❌ No source files or folders found")
-				return
+				return reportError("❌ No source files or folders found\n")
 			}
 
 			// Create batch config
-			config := batch.BatchConfig{
-				MaxConcurrency:      maxConcurrency,
-				SkipErrors:          true,
-				DryRun:              dryRun,
-				ConfirmEach:         false,
-				ProgressInterval:    500 * time.Millisecond,
-				RetryCount:          2,
-				RetryDelay:          1 * time.Second,
-				PreserveTimestamps:  preserveTimestamps,
-				PreservePermissions: preservePermissions,
-				FollowSymlinks:      false,
-				ExcludePatterns:     excludePatterns,
-				IncludePatterns:     includePatterns,
+			config := defaults
+			config.MaxConcurrency = maxConcurrency
+			config.DryRun = dryRun
+			config.PreserveTimestamps = preserveTimestamps
+			config.PreservePermissions = preservePermissions
+			config.ExcludePatterns = excludePatterns
+			config.IncludePatterns = includePatterns
+
+			// Let a running daemon own the job so it outlives this process
+			if !dryRun && submitToDaemon(services, "copy", expandedSources, destination, config) {
+				return nil
 			}
 
 			// Create batch job
 			job, err := batchManager.BatchCopy(expandedSources, destination, config)
 			if err != nil {
-				fmt.Printf("❌ Error creating batch copy job: %v\n", err)
-				return
+				return reportError("❌ Error creating batch copy job: %v\n", err)
 			}
 
-			fmt.Printf("🌸 Created batch copy job: %s\n", job.Name)
-			fmt.Printf("📊 Total items: %d | Total size: %s\n",
+			output.Printf("🌸 Created batch copy job: %s\n", job.Name)
+			output.Printf("📊 Total items: %d | Total size: %s\n",
 				len(job.Operations), formatBytes(job.TotalSize))
-			fmt.Printf("📁 Destination: %s\n", destination)
+			output.Printf("📁 Destination: %s\n", destination)
 
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be copied")
+				output.Printf("🔍 Dry run mode - no files will be copied\n")
 			}
 
 			// Execute job
-			fmt.Println("🚀 Starting batch copy operation...")
+			output.Printf("🚀 Starting batch copy operation...\n")
 			err = batchManager.ExecuteBatchJob(job.ID)
 			if err != nil {
-				fmt.Printf("❌ Error executing batch copy: %v\n", err)
-				return
+				return reportError("❌ Error executing batch copy: %v\n", err)
 			}
 
 			// Show results
 			finalJob, _ := batchManager.GetJobStatus(job.ID)
-			fmt.Printf("✅ Batch copy completed!\n")
-			fmt.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
+			reportResult(finalJob)
+			output.Printf("✅ Batch copy completed!\n")
+			output.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
 				finalJob.SuccessCount, finalJob.ErrorCount, finalJob.SkippedCount)
-			fmt.Printf("⏱️  Duration: %s\n", finalJob.Duration.String())
+			output.Printf("⏱️  Duration: %s\n", finalJob.Duration.String())
+			showChanges("", finalJob.Changes)
+			return reportJobErrors(finalJob)
 		},
 	}
 
 	// Add flags
-	batchCopyCmd.Flags().Bool("dry-run", false, "Preview what would be copied without actually copying")
-	batchCopyCmd.Flags().Int("max-concurrency", 4, "Maximum concurrent operations")
-	batchCopyCmd.Flags().Bool("preserve-permissions", true, "Preserve file permissions")
-	batchCopyCmd.Flags().Bool("preserve-timestamps", true, "Preserve file timestamps")
-	batchCopyCmd.Flags().StringSlice("exclude", []string{}, "Exclude patterns (e.g., *.tmp, *.log)")
-	batchCopyCmd.Flags().StringSlice("include", []string{}, "Include patterns (e.g., *.txt, *.go)")
+	batchCopyCmd.Flags().Bool("dry-run", defaults.DryRun, "Preview what would be copied without actually copying")
+	batchCopyCmd.Flags().Int("max-concurrency", defaults.MaxConcurrency, "Maximum concurrent operations")
+	batchCopyCmd.Flags().Bool("preserve-permissions", defaults.PreservePermissions, "Preserve file permissions")
+	batchCopyCmd.Flags().Bool("preserve-timestamps", defaults.PreserveTimestamps, "Preserve file timestamps")
+	batchCopyCmd.Flags().StringSlice("exclude", defaults.ExcludePatterns, "Exclude patterns (e.g., *.tmp, *.log)")
+	batchCopyCmd.Flags().StringSlice("include", defaults.IncludePatterns, "Include patterns (e.g., *.txt, *.go)")
 
 	// Batch move command
 	batchMoveCmd := &cobra.Command{
-		Use:   "batch-move <source1> [source2] ... <destination>",
-		Short: "Move multiple files and folders",
+		Use:     "batch-move <source1> [source2] ... <destination>",
+		GroupID: "batch",
+		Short:   "Move multiple files and folders",
 		Long: `Move multiple files and folders efficiently with progress tracking.
 Uses rename when possible, falls back to copy+delete for cross-filesystem moves.
 
@@ -245,8 +250,8 @@ Examples:
   ena batch-move folder1/ folder2/ /new_location/
   ena batch-move *.tmp /trash/ --dry-run`,
 		Args: cobra.MinimumNArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
-			batchManager := getGlobalBatchManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			batchManager := services.Batch
 
 			// Parse arguments
 			sources := args[:len(args)-1]
@@ -264,7 +269,7 @@ Examples:
 				if strings.Contains(source, "*") || strings.Contains(source, "?") {
 					matches, err := filepath.Glob(source)
 					if err != nil {
-						fmt.Printf("❌ Error expanding pattern %s: %v\n", source, err)
+						reportError("❌ Error expanding pattern %s: %v\n", source, err)
 						continue
 					}
 					expandedSources = append(expandedSources, matches...)
@@ -274,132 +279,150 @@ Examples:
 			}
 
 			if len(expandedSources) == 0 {
-				fmt.Println("❌ No source files or folders found")
-				return
+				return reportError("❌ No source files or folders found\n")
 			}
 
 			// Create batch config
-			config := batch.BatchConfig{
-				MaxConcurrency:      maxConcurrency,
-				SkipErrors:          true,
-				DryRun:              dryRun,
-				ConfirmEach:         false,
-				ProgressInterval:    500 * time.Millisecond,
-				RetryCount:          2,
-				RetryDelay:          1 * time.Second,
-				PreserveTimestamps:  preserveTimestamps,
-				PreservePermissions: preservePermissions,
-				FollowSymlinks:      false,
-				ExcludePatterns:     []string{},
-				IncludePatterns:     []string{},
+			config := defaults
+			config.MaxConcurrency = maxConcurrency
+			config.DryRun = dryRun
+			config.PreserveTimestamps = preserveTimestamps
+			config.PreservePermissions = preservePermissions
+
+			// Let a running daemon own the job so it outlives this process
+			if !dryRun && submitToDaemon(services, "move", expandedSources, destination, config) {
+				return nil
 			}
 
 			// Create batch job
 			job, err := batchManager.BatchMove(expandedSources, destination, config)
 			if err != nil {
-				fmt.Printf("❌ Error creating batch move job: %v\n", err)
-				return
+				return reportError("❌ Error creating batch move job: %v\n", err)
 			}
 
-			fmt.Printf("🌸 Created batch move job: %s\n", job.Name)
-			fmt.Printf("📊 Total items: %d | Total size: %s\n",
+			output.Printf("🌸 Created batch move job: %s\n", job.Name)
+			output.Printf("📊 Total items: %d | Total size: %s\n",
 				len(job.Operations), formatBytes(job.TotalSize))
-			fmt.Printf("📁 Destination: %s\n", destination)
+			output.Printf("📁 Destination: %s\n", destination)
 
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be moved")
+				output.Printf("🔍 Dry run mode - no files will be moved\n")
 			}
 
 			// Execute job
-			fmt.Println("🚀 Starting batch move operation...")
+			output.Printf("🚀 Starting batch move operation...\n")
 			err = batchManager.ExecuteBatchJob(job.ID)
 			if err != nil {
-				fmt.Printf("❌ Error executing batch move: %v\n", err)
-				return
+				return reportError("❌ Error executing batch move: %v\n", err)
 			}
 
 			// Show results
 			finalJob, _ := batchManager.GetJobStatus(job.ID)
-			fmt.Printf("✅ Batch move completed!\n")
-			fmt.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
+			reportResult(finalJob)
+			output.Printf("✅ Batch move completed!\n")
+			output.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
 				finalJob.SuccessCount, finalJob.ErrorCount, finalJob.SkippedCount)
-			fmt.Printf("⏱️  Duration: %s\n", finalJob.Duration.String())
+			output.Printf("⏱️  Duration: %s\n", finalJob.Duration.String())
+			showChanges("", finalJob.Changes)
+			return reportJobErrors(finalJob)
 		},
 	}
 
 	// Add flags
-	batchMoveCmd.Flags().Bool("dry-run", false, "Preview what would be moved without actually moving")
-	batchMoveCmd.Flags().Int("max-concurrency", 4, "Maximum concurrent operations")
-	batchMoveCmd.Flags().Bool("preserve-permissions", true, "Preserve file permissions")
-	batchMoveCmd.Flags().Bool("preserve-timestamps", true, "Preserve file timestamps")
+	batchMoveCmd.Flags().Bool("dry-run", defaults.DryRun, "Preview what would be moved without actually moving")
+	batchMoveCmd.Flags().Int("max-concurrency", defaults.MaxConcurrency, "Maximum concurrent operations")
+	batchMoveCmd.Flags().Bool("preserve-permissions", defaults.PreservePermissions, "Preserve file permissions")
+	batchMoveCmd.Flags().Bool("preserve-timestamps", defaults.PreserveTimestamps, "Preserve file timestamps")
 
 	// Batch status command
 	batchStatusCmd := &cobra.Command{
-		Use:   "batch-status [job-id]",
-		Short: "Show status of batch operations",
+		Use:     "batch-status [job-id]",
+		GroupID: "batch",
+		Short:   "Show status of batch operations",
 		Long: `Show status of batch operations. If job-id is provided,
 shows detailed status of that specific job. Otherwise shows all jobs.
 
 Examples:
   ena batch-status
   ena batch-status batch_1234567890`,
-		Args: cobra.MaximumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			batchManager := getGlobalBatchManager()
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeFirstArg(jobCandidates(services.Batch, false), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Jobs submitted to a running daemon live there
+			if client := daemon.Connect(); client != nil {
+				defer client.Close()
+
+				if len(args) == 1 {
+					job, err := client.GetJob(args[0])
+					if err != nil {
+						return reportError("❌ Error getting job status: %v\n", err)
+					}
+					showJobDetails(job)
+					return nil
+				}
+
+				jobs, err := client.ListJobs()
+				if err != nil {
+					return reportError("❌ Error listing jobs: %v\n", err)
+				}
+				showJobList(jobs)
+				return nil
+			}
+
+			batchManager := services.Batch
 
 			if len(args) == 1 {
 				// Show specific job
 				jobID := args[0]
 				job, err := batchManager.GetJobStatus(jobID)
 				if err != nil {
-					fmt.Printf("❌ Error getting job status: %v\n", err)
-					return
+					return reportError("❌ Error getting job status: %v\n", err)
 				}
 
 				showJobDetails(job)
 			} else {
 				// Show all jobs
-				jobs := batchManager.ListJobs()
-				if len(jobs) == 0 {
-					fmt.Println("🌸 No batch jobs found")
-					return
-				}
-
-				fmt.Println("🌸 Batch Jobs Status (╹◡╹)♡")
-				fmt.Println("================================")
-
-				for i, job := range jobs {
-					fmt.Printf("%d. %s (%s)\n", i+1, job.Name, job.Status)
-					fmt.Printf("   📊 Progress: %.1f%% | Items: %d/%d\n",
-						job.Progress*100, job.SuccessCount+job.ErrorCount, len(job.Operations))
-					fmt.Printf("   ⏱️  Duration: %s\n", job.Duration.String())
-					fmt.Println()
-				}
+				showJobList(batchManager.ListJobs())
 			}
+			return nil
 		},
 	}
 
 	// Batch cancel command
 	batchCancelCmd := &cobra.Command{
-		Use:   "batch-cancel <job-id>",
-		Short: "Cancel a running batch operation",
+		Use:     "batch-cancel <job-id>",
+		GroupID: "batch",
+		Short:   "Cancel a running batch operation",
 		Long: `Cancel a running batch operation. This will stop the operation
 and mark it as cancelled.
 
 Examples:
   ena batch-cancel batch_1234567890`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			batchManager := getGlobalBatchManager()
-
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(jobCandidates(services.Batch, true), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			jobID := args[0]
+
+			// Jobs submitted to a running daemon are cancelled there
+			if client := daemon.Connect(); client != nil {
+				defer client.Close()
+
+				if _, err := client.CancelJob(jobID); err != nil {
+					return reportError("❌ Error cancelling job: %v\n", err)
+				}
+
+				output.Printf("✅ Successfully cancelled batch job: %s\n", jobID)
+				return nil
+			}
+
+			batchManager := services.Batch
 			err := batchManager.CancelJob(jobID)
 			if err != nil {
-				fmt.Printf("❌ Error cancelling job: %v\n", err)
-				return
+				return reportError("❌ Error cancelling job: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully cancelled batch job: %s\n", jobID)
+			output.Printf("✅ Successfully cancelled batch job: %s\n", jobID)
+			return nil
 		},
 	}
 
@@ -413,29 +436,153 @@ Examples:
 
 // Helper functions
 
+// submitToDaemon hands a batch job to a running daemon, reporting whether it took it
+func submitToDaemon(services *app.App, jobType string, sources []string, destination string, config batch.BatchConfig) bool {
+	// Policy overrides only hold in this process
+	if services.Policy.Overridden() {
+		return false
+	}
+
+	client := daemon.Connect()
+	if client == nil {
+		return false
+	}
+	defer client.Close()
+
+	// The daemon has its own working directory, so send absolute paths
+	absSources := make([]string, 0, len(sources))
+	for _, source := range sources {
+		absSource, err := filepath.Abs(source)
+		if err != nil {
+			absSource = source
+		}
+		absSources = append(absSources, absSource)
+	}
+	if destination != "" {
+		if absDestination, err := filepath.Abs(destination); err == nil {
+			destination = absDestination
+		}
+	}
+
+	job, err := client.SubmitJob(jobType, absSources, destination, config)
+	if err != nil {
+		if errors.Is(err, core.ErrForwardUnavailable) {
+			// Daemon vanished mid-call - run the job here instead
+			return false
+		}
+		reportError("❌ Error creating batch %s job: %v\n", jobType, err)
+		return true
+	}
+
+	reportResult(job)
+	output.Printf("🌸 Submitted batch %s job to daemon: %s\n", jobType, job.Name)
+	output.Printf("📊 Total items: %d | Total size: %s\n",
+		len(job.Operations), formatBytes(job.TotalSize))
+	output.Printf("🆔 Job ID: %s - follow it with: ena batch-status %s\n", job.ID, job.ID)
+	return true
+}
+
+// reportJobErrors marks the command as failed when any operation in a finished
+// job failed, listing each failure. A job refused only by the policy was
+// denied; one where others succeeded failed only partly.
+func reportJobErrors(job *batch.BatchJob) error {
+	if job.ErrorCount == 0 {
+		return nil
+	}
+
+	denied := true
+	for _, operation := range job.Operations {
+		if operation.Status == "failed" && operation.Code != exitDenied {
+			denied = false
+		}
+	}
+	code := exitFailure
+	switch {
+	case denied:
+		code = exitDenied
+	case job.SuccessCount > 0:
+		code = exitPartial
+	}
+
+	err := reportErrorCode(code, "❌ %d of %d operations failed\n", job.ErrorCount, len(job.Operations))
+	if !structured() {
+		for _, operation := range job.Operations {
+			if operation.Status == "failed" {
+				output.Fprintf(os.Stderr, "   %s: %s\n", operation.Source, operation.Error)
+			}
+		}
+	}
+	return err
+}
+
+// showChanges lists the first changes a dry run would have made
+func showChanges(indent string, changes []vfs.Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	output.Printf("%s🔍 Would make %d changes:\n", indent, len(changes))
+	for i, change := range changes {
+		if i == previewLimit {
+			fmt.Printf("%s   ... and %d more\n", indent, len(changes)-previewLimit)
+			break
+		}
+		if change.Target != "" {
+			output.Printf("%s   %s %s → %s\n", indent, change.Op, change.Path, change.Target)
+		} else {
+			fmt.Printf("%s   %s %s\n", indent, change.Op, change.Path)
+		}
+	}
+}
+
+func showJobList(jobs []*batch.BatchJob) {
+	if jobs == nil {
+		jobs = []*batch.BatchJob{}
+	}
+	reportResult(jobs)
+
+	if len(jobs) == 0 {
+		output.Printf("🌸 No batch jobs found\n")
+		return
+	}
+
+	output.Printf("🌸 Batch Jobs Status (╹◡╹)♡\n")
+	fmt.Println("================================")
+
+	for i, job := range jobs {
+		fmt.Printf("%d. %s (%s)\n", i+1, job.Name, job.Status)
+		output.Printf("   🆔 %s\n", job.ID)
+		output.Printf("   📊 Progress: %.1f%% | Items: %d/%d\n",
+			job.Progress*100, job.SuccessCount+job.ErrorCount, len(job.Operations))
+		output.Printf("   ⏱️  Duration: %s\n", job.Duration.String())
+		fmt.Println()
+	}
+}
+
 func showJobDetails(job *batch.BatchJob) {
-	fmt.Printf("🌸 Batch Job Details: %s (╹◡╹)♡\n", job.Name)
+	reportResult(job)
+	output.Printf("🌸 Batch Job Details: %s (╹◡╹)♡\n", job.Name)
 	fmt.Println("=====================================")
-	fmt.Printf("📋 Description: %s\n", job.Description)
-	fmt.Printf("🆔 Job ID: %s\n", job.ID)
-	fmt.Printf("📊 Status: %s\n", job.Status)
-	fmt.Printf("📈 Progress: %.1f%%\n", job.Progress*100)
-	fmt.Printf("📁 Total Items: %d\n", len(job.Operations))
-	fmt.Printf("✅ Success: %d\n", job.SuccessCount)
-	fmt.Printf("❌ Errors: %d\n", job.ErrorCount)
-	fmt.Printf("⏭️  Skipped: %d\n", job.SkippedCount)
-	fmt.Printf("💾 Total Size: %s\n", formatBytes(job.TotalSize))
-	fmt.Printf("💾 Processed: %s\n", formatBytes(job.ProcessedSize))
-	fmt.Printf("⏱️  Duration: %s\n", job.Duration.String())
+	output.Printf("📋 Description: %s\n", job.Description)
+	output.Printf("🆔 Job ID: %s\n", job.ID)
+	output.Printf("📊 Status: %s\n", job.Status)
+	output.Printf("📈 Progress: %.1f%%\n", job.Progress*100)
+	output.Printf("📁 Total Items: %d\n", len(job.Operations))
+	output.Printf("✅ Success: %d\n", job.SuccessCount)
+	output.Printf("❌ Errors: %d\n", job.ErrorCount)
+	output.Printf("⏭️  Skipped: %d\n", job.SkippedCount)
+	output.Printf("💾 Total Size: %s\n", formatBytes(job.TotalSize))
+	output.Printf("💾 Processed: %s\n", formatBytes(job.ProcessedSize))
+	output.Printf("⏱️  Duration: %s\n", job.Duration.String())
 
 	if !job.StartTime.IsZero() {
-		fmt.Printf("🚀 Started: %s\n", job.StartTime.Format("2006-01-02 15:04:05"))
+		output.Printf("🚀 Started: %s\n", job.StartTime.Format("2006-01-02 15:04:05"))
 	}
 	if !job.EndTime.IsZero() {
-		fmt.Printf("🏁 Ended: %s\n", job.EndTime.Format("2006-01-02 15:04:05"))
+		output.Printf("🏁 Ended: %s\n", job.EndTime.Format("2006-01-02 15:04:05"))
 	}
 
-	fmt.Println("\n📋 Operations:")
+	output.Printf("\n📋 Operations:\n")
 	for i, op := range job.Operations {
 		if i >= 10 { // Limit display
 			fmt.Printf("   ... and %d more operations\n", len(job.Operations)-10)
@@ -443,7 +590,7 @@ func showJobDetails(job *batch.BatchJob) {
 		}
 		fmt.Printf("   %d. %s %s (%s)\n", i+1, op.Type, op.Source, op.Status)
 		if op.Error != "" {
-			fmt.Printf("      ❌ Error: %s\n", op.Error)
+			output.Printf("      ❌ Error: %s\n", op.Error)
 		}
 	}
 }
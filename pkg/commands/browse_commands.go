@@ -0,0 +1,49 @@
+/**
+ * Browse Commands
+ *
+ * Provides the interactive file browser as a command so it is reachable
+ * from both the command line and the interactive mode.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: browse_commands.go
+ * Description: Interactive file browser command definition
+ */
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"ena/internal/core"
+)
+
+// setupBrowseCommands sets up the file browser command
+func setupBrowseCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
+	// Browse command
+	var browseCmd = &cobra.Command{
+		Use:     "browse [path]",
+		GroupID: "browse",
+		Short:   "Interactive file browser",
+		Long: `Navigate directories interactively and pick a file:
+  • j/k to move, Enter to open a directory or select a file
+  • h for the parent directory, f to toggle hidden files, q to quit
+  • Prints the selected path when you are done
+
+Examples:
+  ena browse             # Browse the current directory
+  ena browse ~/Documents # Start somewhere else`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := assistant.ProcessCommand("browse", args)
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			cmd.Println(result)
+			return nil
+		},
+	}
+
+	rootCmd.AddCommand(browseCmd)
+}
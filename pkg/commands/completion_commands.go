@@ -0,0 +1,289 @@
+/**
+ * Completion Commands
+ *
+ * Generates shell completion scripts from the command tree and completes the
+ * values commands take from Ena's live state: backup IDs with the paths they
+ * hold, undo operations and sessions, batch jobs, pattern operations, rule
+ * names, themes and detected apps. The interactive prompt asks the same
+ * command tree, so it completes exactly what the shell does.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: completion_commands.go
+ * Description: Completion script generation and argument completion functions
+ */
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/appdetect"
+	"ena/internal/backup"
+	"ena/internal/batch"
+	"ena/internal/core"
+	"ena/internal/daemon"
+	"ena/internal/organizer"
+	"ena/internal/patterns"
+	"ena/internal/theme"
+	"ena/internal/undo"
+)
+
+// completionShells are the shells a completion script can be generated for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionFunc completes the arguments of a command
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// addCompletionCommand registers the completion command, replacing cobra's default
+func addCompletionCommand(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for your shell. Besides commands and flags it
+completes live values: backup IDs with the path each one holds, undo
+operations and sessions, batch job IDs (from the daemon when it runs),
+pattern operation IDs, organization rule names, theme names and detected
+app IDs.
+
+Bash (needs the bash-completion package):
+  source <(ena completion bash)
+  ena completion bash > ~/.local/share/bash-completion/completions/ena
+
+Zsh (needs compinit):
+  ena completion zsh > "${fpath[1]}/_ena"
+
+Fish:
+  ena completion fish > ~/.config/fish/completions/ena.fish
+
+PowerShell:
+  ena completion powershell | Out-String | Invoke-Expression
+
+Open a new shell after installing a script.`,
+		ValidArgs: completionShells,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			switch args[0] {
+			case "bash":
+				err = cmd.Root().GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				err = cmd.Root().GenZshCompletion(os.Stdout)
+			case "fish":
+				err = cmd.Root().GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				err = cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			if err != nil {
+				return reportError("❌ Error generating %s completion: %v\n", args[0], err)
+			}
+			return nil
+		},
+	})
+}
+
+// completionCommand reports whether cmd writes completions for a shell to read
+func completionCommand(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	case "completion":
+		return cmd.Parent() == cmd.Root()
+	}
+	return false
+}
+
+// completeFirstArg completes the first argument from candidates, each an
+// "id\tdescription" pair; later arguments get the rest directive
+func completeFirstArg(candidates func() []string, rest cobra.ShellCompDirective) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, rest
+		}
+		return matchCandidates(candidates(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeValues completes a flag value, or any argument, from candidates
+func completeValues(candidates func() []string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return matchCandidates(candidates(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// matchCandidates keeps the candidates whose value starts with prefix
+func matchCandidates(candidates []string, prefix string) []string {
+	var matches []string
+	for _, entry := range candidates {
+		if strings.HasPrefix(entry, prefix) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// candidate formats a completion value with a one-line description
+func candidate(value, description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	if description == "" {
+		return value
+	}
+	return value + "\t" + description
+}
+
+// backupCandidates lists backup IDs, newest first, described by the path each holds
+func backupCandidates(engine *backup.BackupEngine) func() []string {
+	return func() []string {
+		backups := engine.BackupIDs()
+		ids := make([]string, 0, len(backups))
+		for id := range backups {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return backups[ids[i]].CreatedAt.After(backups[ids[j]].CreatedAt)
+		})
+
+		var candidates []string
+		for _, id := range ids {
+			candidates = append(candidates, candidate(id, backups[id].OriginalPath))
+		}
+		return candidates
+	}
+}
+
+// undoOperationCandidates lists operations that can still be undone, newest first
+func undoOperationCandidates(undoManager *undo.UndoManager) func() []string {
+	return func() []string {
+		var candidates []string
+		for _, session := range undoManager.GetHistory() {
+			if session.Undone {
+				continue
+			}
+			operations := session.Operations
+			for j := len(operations) - 1; j >= 0; j-- {
+				operation := operations[j]
+				candidates = append(candidates, candidate(operation.ID, fmt.Sprintf("%s %s", operation.Type, operation.OriginalPath)))
+			}
+		}
+		return candidates
+	}
+}
+
+// undoSessionCandidates lists sessions that can still be undone, newest first
+func undoSessionCandidates(undoManager *undo.UndoManager) func() []string {
+	return func() []string {
+		var candidates []string
+		for _, session := range undoManager.GetHistory() {
+			if !session.Undone {
+				candidates = append(candidates, candidate(session.ID, session.Name))
+			}
+		}
+		return candidates
+	}
+}
+
+// jobCandidates lists batch jobs from the daemon when it runs, otherwise from
+// this process; with activeOnly, finished jobs are left out
+func jobCandidates(batchManager *batch.BatchManager, activeOnly bool) func() []string {
+	return func() []string {
+		var jobs []*batch.BatchJob
+		if client := daemon.Connect(); client != nil {
+			defer client.Close()
+			jobs, _ = client.ListJobs()
+		} else {
+			jobs = batchManager.ListJobs()
+		}
+
+		sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+		var candidates []string
+		for _, job := range jobs {
+			if activeOnly && job.Status != "pending" && job.Status != "running" {
+				continue
+			}
+			candidates = append(candidates, candidate(job.ID, fmt.Sprintf("%s (%s)", job.Name, job.Status)))
+		}
+		return candidates
+	}
+}
+
+// patternCandidates lists pattern operation IDs described by their names
+func patternCandidates(engine *patterns.PatternEngine) func() []string {
+	return func() []string {
+		var candidates []string
+		for _, operation := range engine.GetOperations() {
+			candidates = append(candidates, candidate(operation.ID, operation.Name))
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+}
+
+// ruleCandidates lists organization rule names described by where they file things
+func ruleCandidates(fileOrganizer *organizer.FileOrganizer) func() []string {
+	return func() []string {
+		var candidates []string
+		for _, rule := range fileOrganizer.GetRules() {
+			candidates = append(candidates, candidate(rule.Name, "→ "+rule.DestPath))
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+}
+
+// themeCandidates lists theme names
+func themeCandidates(themeManager *theme.ThemeManager) func() []string {
+	return func() []string {
+		themes := themeManager.GetAvailableThemes()
+		sort.Strings(themes)
+		return themes
+	}
+}
+
+// appCandidates lists detected application IDs described by their names
+func appCandidates(scanner *appdetect.AppScanner) func() []string {
+	return func() []string {
+		var candidates []string
+		for _, detected := range scanner.GetApps(nil) {
+			name := detected.DisplayName
+			if name == "" {
+				name = detected.Name
+			}
+			candidates = append(candidates, candidate(detected.ID, name))
+		}
+		return candidates
+	}
+}
+
+// completeLine asks the command tree what may follow words, the way the shell
+// scripts do, so the interactive prompt completes the same values
+func completeLine(assistant *core.Assistant, words []string, prefix string) []string {
+	rootCmd := SetupRootCommand(assistant.App, assistant)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs(append(append([]string{cobra.ShellCompNoDescRequestCmd}, words...), prefix))
+	if err := rootCmd.Execute(); err != nil {
+		return nil
+	}
+
+	// Candidates come one per line, then a ":directive" line
+	var candidates []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, ":") {
+			break
+		}
+		if line != "" {
+			candidates = append(candidates, line)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,167 @@
+/**
+ * Configuration Commands
+ *
+ * Provides commands for inspecting and creating Ena's config file and for
+ * moving state left behind by older versions into the XDG directories.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: config_commands.go
+ * Description: Config file and state directory command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/output"
+	"ena/internal/paths"
+	"ena/internal/settings"
+)
+
+// configLocations describes where Ena keeps its files
+type configLocations struct {
+	Config string `json:"config"`
+	Data   string `json:"data"`
+	State  string `json:"state"`
+}
+
+// setupConfigCommands sets up configuration commands
+func setupConfigCommands(rootCmd *cobra.Command) {
+	// Config parent command
+	configCmd := &cobra.Command{
+		Use:     "config",
+		GroupID: "config",
+		Short:   "Show and create Ena's configuration",
+		Long: `Ena reads its settings from a single file, $XDG_CONFIG_HOME/ena/config.yaml
+(~/.config/ena/config.yaml by default), with a section for batch operations,
+backups, file watching, notifications and undo history. Missing settings keep
+their defaults, so the file only needs what you want to change.
+
+Backups, rules and themes live in $XDG_DATA_HOME/ena (~/.local/share/ena);
+history, analytics and logs in $XDG_STATE_HOME/ena (~/.local/state/ena).
+The first time Ena runs it moves state files older versions left in your home
+directory, ~/.ena/backups and /tmp/ena_history there, listing each one. Files
+with common names, such as a themes folder, and files in other directories
+are only moved by "ena config migrate <dir>".
+
+Examples:
+  ena config path              # Show where files live
+  ena config init              # Write a config file with every default
+  ena config show              # Show the settings in effect
+  ena config migrate ~/work    # Move old state files out of ~/work`,
+	}
+
+	// Path command
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Show the config file and state directories",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			locations := configLocations{
+				Config: settings.Path(),
+				Data:   paths.DataDir(),
+				State:  paths.StateDir(),
+			}
+
+			reportResult(locations)
+			output.Printf("⚙️  Config: %s\n", locations.Config)
+			output.Printf("📂 Data:   %s\n", locations.Data)
+			output.Printf("📊 State:  %s\n", locations.State)
+			return nil
+		},
+	}
+
+	// Show command
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the settings in effect",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := settings.Load(settings.Path())
+			if err != nil {
+				return reportError("❌ Error in %s: %v\n", settings.Path(), err)
+			}
+
+			reportResult(config)
+			fmt.Print(settings.Render(config))
+			return nil
+		},
+	}
+
+	// Init command
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a config file containing every default",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			path := settings.Path()
+
+			if _, err := os.Stat(path); err == nil && !force {
+				return reportError("❌ %s already exists (use --force to overwrite)\n", path)
+			}
+
+			if err := settings.Save(settings.Default(), path); err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(configLocations{Config: path, Data: paths.DataDir(), State: paths.StateDir()})
+			output.Printf("✅ Wrote default configuration to %s\n", path)
+			return nil
+		},
+	}
+	initCmd.Flags().Bool("force", false, "Overwrite an existing config file")
+
+	// Migrate command
+	migrateCmd := &cobra.Command{
+		Use:   "migrate [dir]",
+		Short: "Move state files left by older versions in a directory into the XDG directories",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return reportError("❌ Error resolving %s: %v\n", dir, err)
+			}
+
+			result, err := settings.MigrateFrom(absDir)
+			if result != nil {
+				reportResult(result)
+				printMigration(result)
+			}
+			if err != nil {
+				return reportError("❌ Error migrating %s: %v\n", absDir, err)
+			}
+			return nil
+		},
+	}
+
+	configCmd.AddCommand(pathCmd, showCmd, initCmd, migrateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// printMigration summarises which legacy files were moved
+func printMigration(result *settings.MigrationResult) {
+	if len(result.Moved) == 0 && len(result.Skipped) == 0 {
+		output.Printf("🌸 No old state files found\n")
+		return
+	}
+
+	for _, move := range result.Moved {
+		output.Printf("📦 Moved %s → %s\n", move.From, move.To)
+	}
+	for _, path := range result.Skipped {
+		output.Printf("⚠️  Kept %s (already exists in the new location)\n", path)
+	}
+	output.Printf("✅ Data is now in %s and state in %s\n", paths.DataDir(), paths.StateDir())
+}
@@ -0,0 +1,244 @@
+/**
+ * Daemon Commands
+ *
+ * Provides commands for running Ena as a background daemon that keeps file
+ * watchers, batch jobs and backup cleanup alive between invocations.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: daemon_commands.go
+ * Description: Daemon lifecycle, status and job inspection command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/core"
+	"ena/internal/daemon"
+	"ena/internal/output"
+	"ena/internal/policy"
+)
+
+// setupDaemonCommands sets up daemon management commands
+func setupDaemonCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
+	// Daemon parent command
+	daemonCmd := &cobra.Command{
+		Use:     "daemon",
+		GroupID: "daemon",
+		Short:   "Run and manage the Ena background daemon",
+		Long: `Run Ena as a background daemon on a local Unix socket.
+
+While the daemon is running, every other ena command is forwarded to it, so
+file watchers, batch jobs and backup cleanup keep running after the command
+that started them exits. Without a daemon, commands run in-process as usual.
+The daemon also fires the jobs added with "ena schedule".
+
+The socket lives at $ENA_SOCKET, $XDG_RUNTIME_DIR/ena.sock or a per-user
+path in the temp directory, in that order.
+
+Examples:
+  ena daemon start               # Run the daemon in the foreground
+  ena daemon start --background  # Detach and log to a file
+  ena daemon status              # Show daemon status
+  ena daemon jobs                # List batch jobs hosted by the daemon
+  ena daemon stop                # Stop the daemon`,
+	}
+
+	// Start command
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the daemon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			background, _ := cmd.Flags().GetBool("background")
+
+			if background {
+				return startDaemonInBackground()
+			}
+			return runDaemon(assistant)
+		},
+	}
+	startCmd.Flags().Bool("background", false, "Detach from the terminal and log to a file")
+
+	// Stop command
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running daemon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := daemon.Connect()
+			if client == nil {
+				output.Printf("🌸 No Ena daemon is running\n")
+				return nil
+			}
+			defer client.Close()
+
+			if err := client.Shutdown(); err != nil {
+				return reportError("❌ Error stopping daemon: %v\n", err)
+			}
+
+			output.Printf("✅ Ena daemon stopped\n")
+			return nil
+		},
+	}
+
+	// Status command
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show daemon status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := daemon.Connect()
+			if client == nil {
+				output.Printf("🌸 No Ena daemon is running - commands run in-process\n")
+				return nil
+			}
+			defer client.Close()
+
+			status, err := client.Status()
+			if err != nil {
+				return reportError("❌ Error getting daemon status: %v\n", err)
+			}
+
+			reportResult(status)
+			output.Printf("🌸 Ena Daemon Status (╹◡╹)♡\n")
+			fmt.Println("================================")
+			output.Printf("🆔 PID: %d\n", status.PID)
+			output.Printf("🔌 Socket: %s\n", status.SocketPath)
+			output.Printf("⏱️  Uptime: %s\n", status.Uptime.Round(time.Second))
+			output.Printf("👀 Watching: %t\n", status.Watching)
+			output.Printf("📦 Batch jobs: %d (%d running)\n", status.JobCount, status.RunningJobs)
+			output.Printf("📣 Events: %d published, %d subscribers\n", status.Events.Published, len(status.Events.Subscriptions))
+			for _, sub := range status.Events.Subscriptions {
+				if sub.Dropped > 0 {
+					output.Printf("   ⚠️  %s dropped %d events\n", sub.Name, sub.Dropped)
+				}
+			}
+
+			if watcher, err := client.WatcherStatus(); err == nil && watcher.Running {
+				output.Printf("📁 Watched paths:\n")
+				for _, path := range watcher.Paths {
+					fmt.Printf("   - %s\n", path)
+				}
+			}
+			return nil
+		},
+	}
+
+	// Jobs command
+	jobsCmd := &cobra.Command{
+		Use:               "jobs [job-id]",
+		Short:             "Show batch jobs hosted by the daemon",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeFirstArg(jobCandidates(assistant.App.Batch, false), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := daemon.Connect()
+			if client == nil {
+				output.Printf("🌸 No Ena daemon is running\n")
+				return nil
+			}
+			defer client.Close()
+
+			if len(args) == 1 {
+				job, err := client.GetJob(args[0])
+				if err != nil {
+					return reportError("❌ Error getting job status: %v\n", err)
+				}
+				showJobDetails(job)
+				return nil
+			}
+
+			jobs, err := client.ListJobs()
+			if err != nil {
+				return reportError("❌ Error listing jobs: %v\n", err)
+			}
+			showJobList(jobs)
+			return nil
+		},
+	}
+
+	daemonCmd.AddCommand(startCmd, stopCmd, statusCmd, jobsCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// runDaemon serves the assistant in the foreground until stopped
+func runDaemon(assistant *core.Assistant) error {
+	// The daemon always executes commands itself, and nobody at its terminal
+	// answers policy questions for its clients
+	assistant.Forwarder = nil
+	assistant.App.Policy.Confirm = func(policy.Decision) bool { return false }
+
+	server := daemon.NewServer(assistant, daemon.SocketPath())
+	if err := server.Start(); err != nil {
+		return reportError("❌ Error starting daemon: %v\n", err)
+	}
+
+	output.Printf("🌸 Ena daemon listening on %s (pid %d)\n", daemon.SocketPath(), os.Getpid())
+
+	// Scheduled jobs fire only while the daemon runs; the app stops them on exit
+	assistant.App.Scheduler.Start()
+
+	// Survive the launching terminal closing, stop cleanly on interrupt
+	signal.Ignore(syscall.SIGHUP)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-signals:
+		server.Stop()
+	case <-server.Done():
+	}
+
+	output.Printf("✨ Ena daemon stopped\n")
+	return nil
+}
+
+// startDaemonInBackground re-launches ena as a detached daemon process
+func startDaemonInBackground() error {
+	if client := daemon.Connect(); client != nil {
+		client.Close()
+		output.Printf("🌸 Ena daemon is already running on %s\n", daemon.SocketPath())
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return reportError("❌ Error locating ena executable: %v\n", err)
+	}
+
+	logFile, err := os.OpenFile(daemon.LogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return reportError("❌ Error opening daemon log: %v\n", err)
+	}
+	defer logFile.Close()
+
+	process := exec.Command(executable, "daemon", "start")
+	process.Stdout = logFile
+	process.Stderr = logFile
+	if err := process.Start(); err != nil {
+		return reportError("❌ Error starting daemon: %v\n", err)
+	}
+
+	// Wait for the socket to come up before reporting success
+	for i := 0; i < 50; i++ {
+		if client := daemon.Connect(); client != nil {
+			client.Close()
+			output.Printf("✅ Ena daemon started (pid %d)\n", process.Process.Pid)
+			output.Printf("📝 Logging to %s\n", daemon.LogPath())
+			process.Process.Release()
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return reportErrorCode(exitUnavailable, "❌ Daemon did not come up - see %s\n", daemon.LogPath())
+}
@@ -0,0 +1,311 @@
+/**
+ * Diff Commands
+ *
+ * Provides the diff command, which shows what changed between two files as a
+ * unified diff, between two directory trees as a list of added, removed and
+ * modified entries, or between a backup and the file it was taken of, so a
+ * restore can be checked before it overwrites anything.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: diff_commands.go
+ * Description: File, directory and backup comparison command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"ena/internal/app"
+	"ena/internal/diff"
+	"ena/internal/output"
+)
+
+// backupComparison is a backed up copy and the file it would be restored over
+type backupComparison struct {
+	backup  string
+	current string
+}
+
+// setupDiffCommands sets up diff commands
+func setupDiffCommands(rootCmd *cobra.Command, services *app.App) {
+	diffCmd := &cobra.Command{
+		Use:     "diff <old> <new> | --backup <id> [path]",
+		GroupID: "diff",
+		Short:   "Show what changed between files, directories or a backup",
+		Long: `Compare two files, two directories or a backup with the current file.
+
+Two text files are shown as a unified diff, like diff -u: lines starting with
+- are only in <old>, lines starting with + only in <new>. Files holding NUL
+bytes are binary and only reported as the same or different.
+
+Two directories are compared recursively, listing what was added, removed or
+modified. Files count as modified when their size or modification time differ;
+with --hash, only when their contents differ. Add --patch to see the diff of
+every modified text file.
+
+With --backup, the copy kept by a backup ("ena list-backups"), an undo
+operation or every operation of an undo session is compared with the file it
+was taken of, or with [path]. Lines starting with - are in the backup and
+would come back on restore; lines starting with + would be lost.
+
+Like diff(1), the exit code is 0 when the inputs are the same and 1 when they
+differ. The count of changed lines is shown only when stdout is a terminal, so
+redirected output is a patch that applies as is.
+
+Examples:
+  ena diff notes.txt notes-old.txt
+  ena diff ~/project ~/project-copy --hash
+  ena diff ~/site ~/site-backup --patch
+  ena diff --backup backup_1234567890
+  ena diff --backup session_1234567890 -U 1`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			context, _ := cmd.Flags().GetInt("context")
+			hash, _ := cmd.Flags().GetBool("hash")
+			patch, _ := cmd.Flags().GetBool("patch")
+			backupID, _ := cmd.Flags().GetString("backup")
+
+			if context < 0 {
+				return reportErrorCode(exitUsage, "❌ Invalid --context: %d is negative\n", context)
+			}
+			if backupID != "" {
+				if len(args) > 1 {
+					return reportErrorCode(exitUsage, "❌ Invalid arguments: --backup takes at most one path\n")
+				}
+				return diffBackup(services, backupID, args, context)
+			}
+			if len(args) != 2 {
+				return reportErrorCode(exitUsage, "❌ Invalid arguments: diff takes two paths, or --backup <id>\n")
+			}
+
+			oldInfo, err := services.FS.Stat(args[0])
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+			newInfo, err := services.FS.Stat(args[1])
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			switch {
+			case oldInfo.IsDir() && newInfo.IsDir():
+				result, err := diff.Dirs(services.FS, args[0], args[1], hash)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				reportResult(result)
+				showDirDiff(result)
+				if patch {
+					showDirPatches(services, result, context)
+				}
+				if len(result.Entries) > 0 {
+					reportExitCode(exitFailure)
+				}
+			case !oldInfo.IsDir() && !newInfo.IsDir():
+				result, err := diff.Files(services.FS, args[0], args[1], context)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				reportResult(result)
+				showFileDiff(result)
+				if !result.Identical {
+					reportExitCode(exitFailure)
+				}
+			default:
+				return reportErrorCode(exitUsage, "❌ Invalid arguments: %s and %s must both be files or both be directories\n", args[0], args[1])
+			}
+			return nil
+		},
+	}
+
+	diffCmd.Flags().IntP("context", "U", diff.DefaultContext, "Unchanged lines shown around each change")
+	diffCmd.Flags().Bool("hash", false, "Compare directory entries by SHA-256 instead of size and modification time")
+	diffCmd.Flags().BoolP("patch", "p", false, "Show the diff of each modified text file when comparing directories")
+	diffCmd.Flags().String("backup", "", "Backup, undo operation or undo session ID to compare with the current file")
+
+	diffCmd.RegisterFlagCompletionFunc("backup", completeValues(func() []string {
+		candidates := backupCandidates(services.Backups)()
+		candidates = append(candidates, undoSessionCandidates(services.Undo)()...)
+		return append(candidates, undoOperationCandidates(services.Undo)()...)
+	}))
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+// diffBackup compares the copies kept under id with the files they were taken
+// of; path, when given, replaces the file of a single copy
+func diffBackup(services *app.App, id string, args []string, context int) error {
+	comparisons, restore, err := findBackups(services, id)
+	if err != nil {
+		return reportError("❌ Error: %v\n", err)
+	}
+	if len(args) > 0 {
+		if len(comparisons) > 1 {
+			return reportErrorCode(exitUsage, "❌ Invalid arguments: %s holds %d files, so no path can be given\n", id, len(comparisons))
+		}
+		comparisons[0].current = args[0]
+	}
+
+	var results []*diff.FileDiff
+	for _, comparison := range comparisons {
+		output.Printf("💾 %s → %s\n", comparison.backup, comparison.current)
+
+		var result *diff.FileDiff
+		// A restore recreates a missing file, but a path given to compare with must exist
+		if _, err := services.FS.Stat(comparison.current); os.IsNotExist(err) && len(args) == 0 {
+			data, err := services.FS.ReadFile(comparison.backup)
+			if err != nil {
+				return reportError("❌ Error reading backup: %v\n", err)
+			}
+			output.Printf("🆕 %s no longer exists; restoring brings it back\n", comparison.current)
+			result = diff.Bytes(comparison.backup, data, os.DevNull, nil, context)
+		} else {
+			result, err = diff.Files(services.FS, comparison.backup, comparison.current, context)
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+		}
+
+		results = append(results, result)
+		showFileDiff(result)
+		if !result.Identical {
+			reportExitCode(exitFailure)
+		}
+	}
+
+	reportResult(results)
+	output.Printf("💡 Restore with: %s\n", restore)
+	return nil
+}
+
+// findBackups resolves a backup, undo operation or undo session ID to the
+// copies it keeps and the command that restores them
+func findBackups(services *app.App, id string) ([]backupComparison, string, error) {
+	if metadata, ok := services.Backups.BackupIDs()[id]; ok {
+		return []backupComparison{{backup: metadata.BackupPath, current: metadata.OriginalPath}},
+			fmt.Sprintf("ena restore-backup %s --overwrite", id), nil
+	}
+
+	if operation, err := services.Undo.GetOperation(id); err == nil {
+		if operation.BackupPath == "" {
+			return nil, "", fmt.Errorf("undo operation %s (%s %s) keeps no copy to compare", id, operation.Type, operation.OriginalPath)
+		}
+		return []backupComparison{{backup: operation.BackupPath, current: operation.OriginalPath}},
+			fmt.Sprintf("ena undo-operation %s", id), nil
+	}
+
+	if session, err := services.Undo.GetSession(id); err == nil {
+		var comparisons []backupComparison
+		for _, operation := range session.Operations {
+			if operation.BackupPath != "" && !operation.Undone {
+				comparisons = append(comparisons, backupComparison{backup: operation.BackupPath, current: operation.OriginalPath})
+			}
+		}
+		if len(comparisons) == 0 {
+			return nil, "", fmt.Errorf("undo session %s keeps no copies to compare", id)
+		}
+		return comparisons, fmt.Sprintf("ena undo-session %s", id), nil
+	}
+
+	return nil, "", fmt.Errorf("no backup, undo operation or undo session %s: %w", id, os.ErrNotExist)
+}
+
+// showFileDiff prints a file diff, colouring removed and added lines
+func showFileDiff(result *diff.FileDiff) {
+	switch {
+	case result.Identical:
+		output.Printf("✅ %s and %s are identical\n", result.Old, result.New)
+		return
+	case result.Binary:
+		output.Printf("📦 Binary files %s and %s differ\n", result.Old, result.New)
+		return
+	}
+
+	header := color.New(color.Bold)
+	hunk := color.New(color.FgCyan)
+	removed := color.New(color.FgRed)
+	added := color.New(color.FgGreen)
+	for _, line := range result.Unified() {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			header.Println(line)
+		case strings.HasPrefix(line, "@@"):
+			hunk.Println(line)
+		case strings.HasPrefix(line, "-"):
+			removed.Println(line)
+		case strings.HasPrefix(line, "+"):
+			added.Println(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+	// Piped or redirected, stdout holds nothing but the patch
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		output.Printf("📊 %d lines added, %d removed in %d hunks\n", result.Added, result.Removed, len(result.Hunks))
+	}
+}
+
+// showDirDiff prints the entries that differ between two trees
+func showDirDiff(result *diff.DirDiff) {
+	output.Printf("📁 %s → %s (by %s)\n", result.Old, result.New, result.Compare)
+	if len(result.Entries) == 0 {
+		output.Printf("✅ No differences among %d files\n", result.Unchanged)
+	}
+
+	for _, entry := range result.Entries {
+		name := entry.Path
+		if entry.IsDir {
+			name += string(filepath.Separator)
+		}
+		switch entry.Change {
+		case diff.ChangeAdded:
+			color.New(color.FgGreen).Printf("  + %s", name)
+			if !entry.IsDir {
+				fmt.Printf("  (%s)", formatBytes(entry.NewSize))
+			}
+		case diff.ChangeRemoved:
+			color.New(color.FgRed).Printf("  - %s", name)
+			if !entry.IsDir {
+				fmt.Printf("  (%s)", formatBytes(entry.OldSize))
+			}
+		case diff.ChangeType:
+			color.New(color.FgYellow).Printf("  ~ %s", name)
+			fmt.Print("  (file replaced by directory or the other way round)")
+		default:
+			color.New(color.FgYellow).Printf("  ~ %s", name)
+			output.Printf("  (%s → %s, %s → %s)", formatBytes(entry.OldSize), formatBytes(entry.NewSize),
+				entry.OldModTime.Format("2006-01-02 15:04"), entry.NewModTime.Format("2006-01-02 15:04"))
+		}
+		fmt.Println()
+	}
+
+	output.Printf("📊 %d added, %d removed, %d modified, %d unchanged (%s)\n",
+		result.Added, result.Removed, result.Modified, result.Unchanged, result.Duration.String())
+	if len(result.Errors) > 0 {
+		output.Printf("⚠️  %d entries could not be compared\n", len(result.Errors))
+	}
+}
+
+// showDirPatches prints the diff of every file modified between two trees
+func showDirPatches(services *app.App, result *diff.DirDiff, context int) {
+	for _, entry := range result.Entries {
+		if entry.Change != diff.ChangeModified {
+			continue
+		}
+		fileDiff, err := diff.Files(services.FS, filepath.Join(result.Old, entry.Path), filepath.Join(result.New, entry.Path), context)
+		if err != nil {
+			output.Printf("⚠️ %v\n", err)
+			continue
+		}
+		fmt.Println()
+		showFileDiff(fileDiff)
+	}
+}
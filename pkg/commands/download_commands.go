@@ -21,8 +21,9 @@ import (
 func setupDownloadCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Download command
 	var downloadCmd = &cobra.Command{
-		Use:   "download <url> <filename>",
-		Short: "Download a file with progress bar",
+		Use:     "download <url> <filename>",
+		GroupID: "download",
+		Short:   "Download a file with progress bar",
 		Long: `Download a file from a URL with a beautiful progress bar showing:
   • Download progress percentage
   • Transfer speed
@@ -32,14 +33,14 @@ func setupDownloadCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 Example:
   ena download "https://example.com/file.zip" "downloaded_file.zip"`,
 		Args: cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("download", args)
 			if err != nil {
-				cmd.PrintErrln("❌ Error:", err)
-				return
+				return reportError("❌ Error: %v\n", err)
 			}
 
 			cmd.Println(result)
+			return nil
 		},
 	}
 
@@ -0,0 +1,274 @@
+/**
+ * Disk Usage Commands
+ *
+ * Provides the du command, which shows what takes up space under a directory:
+ * the biggest entries with their share of the total, and breakdowns by file
+ * type and age. Its interactive view, ncdu style, lists entries biggest first,
+ * lets the user drill into directories and trash or delete what they find,
+ * with each removal undoable.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: du_commands.go
+ * Description: Disk usage reporting and interactive view command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/app"
+	"ena/internal/browser"
+	"ena/internal/diskusage"
+	"ena/internal/output"
+)
+
+// duBarWidth is how many characters a full usage bar takes
+const duBarWidth = 20
+
+// setupDiskUsageCommands sets up disk usage commands
+func setupDiskUsageCommands(rootCmd *cobra.Command, services *app.App) {
+	analyzer := services.DiskUsage
+
+	duCmd := &cobra.Command{
+		Use:     "du [path]",
+		GroupID: "du",
+		Short:   "Show what takes up space under a directory",
+		Long: `Scan a directory tree, reading directories in parallel, and show its biggest
+entries with the space they take on disk and their share of the total, then
+how the space splits by file type and by how long ago files last changed.
+Hard links of one file count once. Without a path, the current directory is
+scanned.
+
+With --interactive, the entries are shown in a browser sorted by size:
+Enter opens a directory, h goes back up, d moves the selected entry to the
+trash and D deletes it for good. Trashed entries, and deleted files, can be
+put back with "ena undo-session <id>"; deleted directories cannot.
+
+Examples:
+  ena du
+  ena du ~/Downloads --depth 2
+  ena du / --one-file-system --top 10
+  ena du ~ --interactive`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			depth, _ := cmd.Flags().GetInt("depth")
+			top, _ := cmd.Flags().GetInt("top")
+			apparent, _ := cmd.Flags().GetBool("apparent")
+			oneFileSystem, _ := cmd.Flags().GetBool("one-file-system")
+			workers, _ := cmd.Flags().GetInt("workers")
+			interactive, _ := cmd.Flags().GetBool("interactive")
+
+			if interactive && structured() {
+				return reportErrorCode(exitUsage, "❌ --interactive cannot be combined with structured output\n")
+			}
+
+			root := "."
+			if len(args) > 0 {
+				root = args[0]
+			}
+
+			output.Printf("📊 Scanning %s\n", root)
+			report, err := analyzer.Scan(root, diskusage.Options{OneFileSystem: oneFileSystem, Workers: workers})
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			if interactive {
+				return browseDiskUsage(analyzer, report, apparent)
+			}
+
+			summary := *report
+			summary.Root = report.Root.Summary(depth, top)
+			reportResult(&summary)
+			showDiskUsage(report, depth, top, apparent)
+			return nil
+		},
+	}
+
+	duCmd.Flags().Int("depth", 1, "Directory levels to show")
+	duCmd.Flags().Int("top", 20, "Biggest entries to show per directory and file types to list (0 = all)")
+	duCmd.Flags().Bool("apparent", false, "Show apparent sizes instead of space used on disk")
+	duCmd.Flags().BoolP("one-file-system", "x", false, "Skip directories on other filesystems")
+	duCmd.Flags().Int("workers", 0, "Directories read at once (0 = two per CPU)")
+	duCmd.Flags().BoolP("interactive", "i", false, "Browse the results and trash or delete entries")
+
+	rootCmd.AddCommand(duCmd)
+}
+
+// duSize returns the size of node that is being shown
+func duSize(node *diskusage.Node, apparent bool) int64 {
+	if apparent {
+		return node.Size
+	}
+	return node.DiskSize
+}
+
+// duChildren returns node's children biggest first by the size being shown
+func duChildren(node *diskusage.Node, apparent bool) []*diskusage.Node {
+	children := append([]*diskusage.Node{}, node.Children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		return duSize(children[i], apparent) > duSize(children[j], apparent)
+	})
+	return children
+}
+
+// duBar draws size as a share of total, followed by the percentage
+func duBar(size, total int64) string {
+	share := 0.0
+	if total > 0 {
+		share = float64(size) / float64(total)
+	}
+	filled := int(share*duBarWidth + 0.5)
+	return fmt.Sprintf("[%s%s] %5.1f%%", strings.Repeat("#", filled), strings.Repeat(" ", duBarWidth-filled), share*100)
+}
+
+// showDiskUsage prints the biggest entries down to depth and the breakdowns
+func showDiskUsage(report *diskusage.Report, depth, top int, apparent bool) {
+	root := report.Root
+	total := duSize(root, apparent)
+
+	output.Printf("📊 Disk Usage: %s\n", root.Path)
+	fmt.Println("============")
+	showDiskUsageLevel(root, total, depth, top, apparent, "")
+
+	kind := "on disk"
+	if apparent {
+		kind = "apparent"
+	}
+	output.Printf("💾 %s %s in %d files and %d directories (%s)\n",
+		formatBytes(total), kind, root.Files, root.Dirs, report.Duration.String())
+	if report.Errors > 0 {
+		output.Printf("⚠️  %d entries could not be read\n", report.Errors)
+	}
+
+	if len(report.Types) > 0 {
+		output.Printf("🗂️ By file type (apparent size)\n")
+		for i, usage := range report.Types {
+			if top > 0 && i >= top {
+				fmt.Printf("   ... and %d more types\n", len(report.Types)-top)
+				break
+			}
+			fmt.Printf("   %-12s %10s %s  %d files\n", usage.Type, formatBytes(usage.Size), duBar(usage.Size, root.Size), usage.Files)
+		}
+	}
+
+	if root.Files > 0 {
+		output.Printf("🕰️ By last change (apparent size)\n")
+		for _, usage := range report.Ages {
+			fmt.Printf("   %-12s %10s %s  %d files\n", usage.Age, formatBytes(usage.Size), duBar(usage.Size, root.Size), usage.Files)
+		}
+	}
+}
+
+// showDiskUsageLevel prints a directory's biggest children, then theirs down to depth
+func showDiskUsageLevel(node *diskusage.Node, total int64, depth, top int, apparent bool, indent string) {
+	if depth <= 0 {
+		return
+	}
+	children := duChildren(node, apparent)
+	for i, child := range children {
+		if top > 0 && i >= top {
+			fmt.Printf("%s   ... and %d more\n", indent, len(children)-top)
+			break
+		}
+		size := duSize(child, apparent)
+		icon, name := output.Prose("📄 "), child.Name
+		if child.IsDir {
+			icon, name = output.Prose("📁 "), child.Name+"/"
+		}
+		fmt.Printf("%s%10s %s %s%s\n", indent, formatBytes(size), duBar(size, total), icon, name)
+		if child.IsDir {
+			showDiskUsageLevel(child, total, depth-1, top, apparent, indent+"   ")
+		}
+	}
+}
+
+// browseDiskUsage opens the interactive view of a scan
+func browseDiskUsage(analyzer *diskusage.Analyzer, report *diskusage.Report, apparent bool) error {
+	root := report.Root
+	if !root.IsDir {
+		return reportErrorCode(exitUsage, "❌ Invalid path: %s is not a directory\n", root.Path)
+	}
+
+	list := func(path string) ([]browser.FileItem, error) {
+		node := root.Find(path)
+		if node == nil {
+			return nil, fmt.Errorf("%s is not part of the scan", path)
+		}
+		total := duSize(node, apparent)
+		var items []browser.FileItem
+		for _, child := range duChildren(node, apparent) {
+			size := duSize(child, apparent)
+			items = append(items, browser.FileItem{
+				Name:     child.Name,
+				Path:     child.Path,
+				IsDir:    child.IsDir,
+				Size:     size,
+				ModTime:  child.ModTime,
+				IsHidden: strings.HasPrefix(child.Name, "."),
+				Detail:   fmt.Sprintf("%10s %s", formatBytes(size), duBar(size, total)),
+			})
+		}
+		return items, nil
+	}
+
+	trashEntry := func(fb *browser.FileBrowser, item browser.FileItem) (string, error) {
+		node := root.Find(item.Path)
+		if node == nil || !fb.Confirm(fmt.Sprintf("🗑️  Move %s (%s) to the trash?", item.Name, formatBytes(item.Size))) {
+			return output.Prose("Cancelled 😅"), nil
+		}
+		sessionID, err := analyzer.Trash(node)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✅ Moved %s to the trash ↩️  undo with: ena undo-session %s", item.Name, sessionID), nil
+	}
+
+	deleteEntry := func(fb *browser.FileBrowser, item browser.FileItem) (string, error) {
+		node := root.Find(item.Path)
+		question := fmt.Sprintf("⚠️  Delete %s (%s) for good?", item.Name, formatBytes(item.Size))
+		if item.IsDir {
+			question = fmt.Sprintf("⚠️  Delete %s (%s) and everything in it for good? This cannot be undone", item.Name, formatBytes(item.Size))
+		}
+		if node == nil || !fb.Confirm(question) {
+			return output.Prose("Cancelled 😅"), nil
+		}
+		sessionID, err := analyzer.Delete(node)
+		if err != nil {
+			return "", err
+		}
+		message := fmt.Sprintf("✅ Deleted %s, freeing %s", item.Name, formatBytes(item.Size))
+		if sessionID != "" {
+			message += fmt.Sprintf(" ↩️  undo with: ena undo-session %s", sessionID)
+		}
+		return message, nil
+	}
+
+	fb, err := browser.NewFileBrowserWithOptions(root.Path, browser.Options{
+		Title: fmt.Sprintf("📊 Ena Disk Usage - %s in %d files", formatBytes(duSize(root, apparent)), root.Files),
+		Root:  root.Path,
+		List:  list,
+		Actions: []browser.Action{
+			{Key: "d", Label: "Move to trash", Run: trashEntry},
+			{Key: "D", Label: "Delete for good", Run: deleteEntry},
+		},
+	})
+	if err != nil {
+		return reportError("❌ Failed to start the disk usage view: %v\n", err)
+	}
+	defer fb.Close()
+
+	selected, err := fb.Start()
+	if err != nil {
+		// Quitting is the usual way out
+		return nil
+	}
+	output.Printf("📄 %s\n", selected)
+	return nil
+}
@@ -0,0 +1,267 @@
+/**
+ * Duplicate Commands
+ *
+ * Provides the dupes command, which reports files with identical contents and
+ * how much space they waste, and deduplicates them: keeping one copy of each
+ * and deleting, linking or moving the rest, as a previewable plan whose
+ * changes can all be undone together.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: dupes_commands.go
+ * Description: Duplicate file reporting and deduplication command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/app"
+	"ena/internal/dupes"
+	"ena/internal/input"
+	"ena/internal/output"
+	"ena/internal/search"
+)
+
+// setupDupesCommands sets up duplicate file commands
+func setupDupesCommands(rootCmd *cobra.Command, services *app.App) {
+	finder := services.Dupes
+
+	dupesCmd := &cobra.Command{
+		Use:     "dupes <path1> [path2] ...",
+		GroupID: "dupes",
+		Short:   "Find duplicate files and reclaim their space",
+		Long: `Find files with identical contents under the given paths and report how much
+space keeping a single copy of each would reclaim. Files are compared by
+size, then by a hash of their first 16KB, then by a full SHA-256, so only
+likely duplicates are read in full. Hard links of one file count once.
+
+With --action, one file of each group is kept, chosen by --keep (newest,
+oldest or shortest path), and the others are:
+
+  delete     moved to the trash, or deleted outright with --permanent
+  hardlink   replaced with hard links to the kept file (same volume only)
+  reflink    replaced with copy-on-write clones of it (Btrfs, XFS and the like),
+             or hard links where clones are not supported
+  move       moved into the folder given with --to
+
+Use --dry-run to see the plan first. Every change is tracked in one undo
+session, so "ena undo-session <id>" puts everything back. Undoing a link
+gives the file its own copy of the data again. A --permanent delete keeps a
+copy in the undo history, so it frees no space until "ena clear-undo-history"
+drops it.
+
+Examples:
+  ena dupes ~/Downloads
+  ena dupes ~/Pictures ~/Backups/photos --min-size 1MB
+  ena dupes ~/Downloads --action delete --keep oldest --dry-run
+  ena dupes ~/Pictures --action hardlink --yes
+  ena dupes ~/Music --action move --to ~/Music/duplicates --keep shortest`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			minSize, _ := cmd.Flags().GetString("min-size")
+			hidden, _ := cmd.Flags().GetBool("hidden")
+			limit, _ := cmd.Flags().GetInt("limit")
+			action, _ := cmd.Flags().GetString("action")
+			keep, _ := cmd.Flags().GetString("keep")
+			dest, _ := cmd.Flags().GetString("to")
+			permanent, _ := cmd.Flags().GetBool("permanent")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			minBytes, err := search.ParseSize(minSize)
+			if err != nil {
+				return reportErrorCode(exitUsage, "❌ Invalid --min-size: %v\n", err)
+			}
+
+			output.Printf("🔍 Looking for duplicates in %s\n", strings.Join(args, ", "))
+			report, err := finder.Find(args, dupes.Options{MinSize: minBytes, Hidden: hidden})
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			if action == "" {
+				report.Keep = dupes.Keep(keep)
+				reportResult(report)
+				showDupesReport(report, "", limit)
+				if len(report.Groups) > 0 {
+					output.Printf("💡 Deduplicate with: ena dupes %s --action delete --dry-run\n", strings.Join(args, " "))
+				}
+				return nil
+			}
+
+			plan, err := finder.Plan(report.Groups, dupes.Action(action), dupes.Keep(keep), dest, permanent)
+			if err != nil {
+				return reportErrorCode(exitUsage, "❌ Error: %v\n", err)
+			}
+
+			showDupesReport(report, plan.Keep, limit)
+			if len(plan.Steps) == 0 {
+				reportResult(plan)
+				return nil
+			}
+			showDupesPlan(plan, limit)
+
+			if !proceedDupes(plan, yes, dryRun) {
+				reportResult(plan)
+				return nil
+			}
+
+			result, err := finder.Apply(plan)
+			reportResult(result)
+			if err != nil {
+				output.Printf("⚠️ Warning: %v\n", err)
+			}
+			return showDupesResult(result)
+		},
+	}
+
+	dupesCmd.Flags().String("min-size", "1", "Ignore files smaller than this (e.g., 100KB, 1MB)")
+	dupesCmd.Flags().Bool("hidden", false, "Scan hidden files and directories too")
+	dupesCmd.Flags().Int("limit", 20, "Groups and plan steps to show (0 = all)")
+	dupesCmd.Flags().String("action", "", "What to do with the extra copies: delete, hardlink, reflink or move")
+	dupesCmd.Flags().String("keep", string(dupes.KeepNewest), "Copy to keep: newest, oldest or shortest (path)")
+	dupesCmd.Flags().String("to", "", "Folder extra copies go to with --action move")
+	dupesCmd.Flags().Bool("permanent", false, "Delete outright instead of moving to the trash")
+	dupesCmd.Flags().Bool("dry-run", false, "Show the plan without changing anything")
+	dupesCmd.Flags().BoolP("yes", "y", false, "Run without asking for confirmation")
+
+	dupesCmd.RegisterFlagCompletionFunc("action", cobra.FixedCompletions(
+		[]string{"delete", "hardlink", "reflink", "move"}, cobra.ShellCompDirectiveNoFileComp))
+	dupesCmd.RegisterFlagCompletionFunc("keep", cobra.FixedCompletions(
+		[]string{"newest", "oldest", "shortest"}, cobra.ShellCompDirectiveNoFileComp))
+
+	rootCmd.AddCommand(dupesCmd)
+}
+
+// showDupesReport prints the groups found, marking the kept file when keep is set
+func showDupesReport(report *dupes.Report, keep dupes.Keep, limit int) {
+	if len(report.Groups) == 0 {
+		output.Printf("🌸 No duplicates among %d files (%s)\n", report.FilesScanned, report.Duration.String())
+		return
+	}
+
+	output.Printf("👯 Duplicate Files\n")
+	fmt.Println("=================")
+	for i, group := range report.Groups {
+		if limit > 0 && i >= limit {
+			fmt.Printf("... and %d more groups\n", len(report.Groups)-limit)
+			break
+		}
+
+		output.Printf("📦 %d copies of %s (%s wasted)\n", len(group.Files), formatBytes(group.Size), formatBytes(group.Reclaimable()))
+		kept := ""
+		if keep != "" {
+			kept = group.Keeper(keep).Path
+		}
+		for _, file := range group.Files {
+			marker := "   "
+			if file.Path == kept && output.PlainMode() {
+				marker = "*  "
+			} else if file.Path == kept {
+				marker = "✅ "
+			}
+			fmt.Printf("   %s%s  (%s)\n", marker, file.Path, file.ModTime.Format("2006-01-02 15:04"))
+			for _, link := range file.Links {
+				output.Printf("      🔗 %s\n", link)
+			}
+		}
+	}
+
+	output.Printf("💾 %s reclaimable from %d duplicates in %d groups\n",
+		formatBytes(report.Reclaimable), report.Duplicates, len(report.Groups))
+	output.Printf("📊 Scanned %d files, hashed %d, in %s\n", report.FilesScanned, report.FilesHashed, report.Duration.String())
+	if len(report.Errors) > 0 {
+		output.Printf("⚠️  %d files could not be read\n", len(report.Errors))
+	}
+}
+
+// showDupesPlan prints what applying a plan would change
+func showDupesPlan(plan *dupes.Plan, limit int) {
+	output.Printf("📋 Plan: %s %d extra copies, keeping the %s\n", plan.Action, len(plan.Steps), plan.Keep)
+	for i, step := range plan.Steps {
+		if limit > 0 && i >= limit {
+			fmt.Printf("   ... and %d more\n", len(plan.Steps)-limit)
+			break
+		}
+		switch step.Action {
+		case dupes.ActionDelete:
+			output.Printf("   🗑️  %s\n", step.Path)
+		case dupes.ActionMove:
+			output.Printf("   📦 %s → %s\n", step.Path, step.Target)
+		default:
+			output.Printf("   🔗 %s → %s\n", step.Path, step.Keep)
+		}
+	}
+
+	if plan.Action == dupes.ActionMove {
+		return
+	}
+	output.Printf("💾 Reclaims %s", formatBytes(plan.Reclaimable))
+	if plan.Action == dupes.ActionDelete && !plan.Permanent {
+		fmt.Print(" once the trash is emptied")
+	}
+	fmt.Println()
+}
+
+// proceedDupes decides whether a plan is applied, asking the user unless told not to
+func proceedDupes(plan *dupes.Plan, yes, dryRun bool) bool {
+	if dryRun {
+		output.Printf("🔍 Dry run - nothing was changed\n")
+		return false
+	}
+	if yes {
+		return true
+	}
+
+	// Structured output has no one to answer a prompt
+	if structured() {
+		reportError("❌ Add --yes to apply the plan, or --dry-run to preview it\n")
+		return false
+	}
+
+	output.Printf("⚠️  %s %d duplicate files? (y/N): ", strings.ToUpper(string(plan.Action[:1]))+string(plan.Action[1:]), len(plan.Steps))
+	response, err := input.Answer()
+	if err != nil {
+		reportError("❌ %v\n", err)
+		return false
+	}
+
+	if response != "y" && response != "yes" {
+		output.Printf("Cancelled 😅\n")
+		return false
+	}
+	return true
+}
+
+// showDupesResult prints how applying a plan went; a plan where some steps
+// failed and others succeeded failed only partly
+func showDupesResult(result *dupes.Result) error {
+	for _, step := range result.Steps {
+		if step.Error != "" {
+			output.Printf("   ❌ %s: %s\n", step.Path, step.Error)
+		}
+	}
+
+	if result.Plan.Action == dupes.ActionMove {
+		output.Printf("✅ Moved %d duplicate files\n", result.Succeeded)
+	} else {
+		output.Printf("✅ Deduplicated %d files, reclaiming %s\n", result.Succeeded, formatBytes(result.Reclaimed))
+	}
+	if result.UndoSessionID != "" && result.Succeeded > 0 {
+		output.Printf("↩️  Undo with: ena undo-session %s\n", result.UndoSessionID)
+	}
+
+	if result.Failed == 0 {
+		return nil
+	}
+	code := exitFailure
+	if result.Succeeded > 0 {
+		code = exitPartial
+	}
+	return reportErrorCode(code, "❌ %d of %d steps failed\n", result.Failed, len(result.Steps))
+}
@@ -0,0 +1,161 @@
+/**
+ * Event Commands
+ *
+ * Provides commands for discovering the events Ena's managers publish on its
+ * event bus, so subscribers know which topics and wildcards to ask for, and
+ * for checking the webhook and command sinks events are forwarded to.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: event_commands.go
+ * Description: Event topic and sink command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/app"
+	"ena/internal/events"
+	"ena/internal/output"
+	"ena/internal/settings"
+	"ena/internal/sinks"
+)
+
+// setupEventCommands sets up event bus commands
+func setupEventCommands(rootCmd *cobra.Command, services *app.App) {
+	// Events parent command
+	eventsCmd := &cobra.Command{
+		Use:     "events",
+		GroupID: "events",
+		Short:   "Explore the events Ena publishes and where they go",
+		Long: fmt.Sprintf(`Batch jobs, undo, backups, pattern operations, the organizer, app scans and
+file watchers announce what they do on one event bus. Each event has a topic
+of the form "source.name", such as batch.job_completed, and subscribers pick
+topics with shell wildcards: "*" for everything, "backup.*" for every backup
+event, "*.operation_completed" for that event from any source.
+
+Sinks in the sinks section of %s forward events to your own tooling:
+a webhook sink POSTs each event as JSON, signed with HMAC-SHA256 when it has
+a secret, and a command sink runs a shell command with the event on stdin.
+While the daemon runs it delivers them; "ena daemon status" shows how many
+events it has published and whether any sink fell behind.
+
+Examples:
+  ena events topics
+  ena events topics "batch.*"
+  ena events sinks
+  ena events test ci --topic batch.job_completed`, settings.Path()),
+	}
+
+	// Topics command
+	topicsCmd := &cobra.Command{
+		Use:   "topics [pattern]",
+		Short: "List event topics, optionally only those matching a pattern",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pattern := ""
+			if len(args) > 0 {
+				pattern = args[0]
+				if !events.ValidPattern(pattern) {
+					return reportErrorCode(exitUsage, "❌ Invalid topic pattern %q\n", pattern)
+				}
+			}
+
+			topics := events.Topics(pattern)
+			reportResult(topics)
+
+			if len(topics) == 0 {
+				output.Printf("🌸 No topics match %q\n", pattern)
+				return nil
+			}
+
+			output.Printf("📣 Event topics (%d):\n", len(topics))
+			source := ""
+			for _, topic := range topics {
+				if topic.Topic.Source() != source {
+					source = topic.Topic.Source()
+					fmt.Printf("\n%s\n", source)
+				}
+				fmt.Printf("   %-36s %s\n", topic.Topic, topic.Description)
+			}
+			return nil
+		},
+	}
+
+	// Sinks command
+	sinksCmd := &cobra.Command{
+		Use:   "sinks",
+		Short: "List the configured event sinks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list := services.Sinks.List()
+			reportResult(list)
+
+			if len(list) == 0 {
+				output.Printf("🌸 No event sinks. Add them to the sinks section of %s\n", settings.Path())
+				return nil
+			}
+
+			output.Printf("📤 Event sinks (%d):\n", len(list))
+			for _, sink := range list {
+				status := output.Prose("✅ ")
+				if sink.Disabled {
+					status = output.Prose("⏸️  ")
+				}
+				output.Printf("\n%s%s (%s) → %s\n", status, sink.Name, sink.Type, sink.Target)
+				filter := "every event"
+				if len(sink.Events) > 0 {
+					filter = fmt.Sprintf("%v", sink.Events)
+				}
+				output.Printf("   📣 %s\n", filter)
+				if sink.Delivered+sink.Failed+sink.Dropped > 0 {
+					output.Printf("   📊 %d delivered, %d failed, %d dropped\n", sink.Delivered, sink.Failed, sink.Dropped)
+				}
+				if sink.LastError != "" {
+					output.Printf("   ⚠️ %s\n", sink.LastError)
+				}
+			}
+			return nil
+		},
+	}
+
+	// Test command
+	testCmd := &cobra.Command{
+		Use:   "test <sink>",
+		Short: "Send a test event to a sink and show how it went",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			topic, _ := cmd.Flags().GetString("topic")
+
+			event := events.New(events.Topic(topic), "Test event from ena events test", map[string]interface{}{"test": true})
+			output.Printf("📤 Sending %s to %s...\n", topic, args[0])
+			delivery, err := services.Sinks.Send(args[0], event)
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(delivery)
+			if delivery.Error != "" {
+				return reportError("❌ Delivery failed after %d attempts (%v): %s\n",
+					delivery.Attempts, delivery.Duration.Round(time.Millisecond), delivery.Error)
+			}
+
+			status := ""
+			if delivery.Status != 0 {
+				status = fmt.Sprintf(", HTTP %d", delivery.Status)
+			}
+			output.Printf("✅ Delivered %s in %v (%d attempts%s)\n", delivery.ID,
+				delivery.Duration.Round(time.Millisecond), delivery.Attempts, status)
+			return nil
+		},
+	}
+	testCmd.Flags().String("topic", string(sinks.TopicTest), "Topic the test event carries")
+
+	eventsCmd.AddCommand(topicsCmd, sinksCmd, testCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
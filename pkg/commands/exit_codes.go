@@ -0,0 +1,88 @@
+/**
+ * Exit Codes
+ *
+ * Every command returns its outcome from RunE as one of a small, stable set of
+ * exit codes, so scripts can tell a missing file from a refused operation or a
+ * batch that only partly worked. The table is shown by "ena exit-codes".
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: exit_codes.go
+ * Description: Exit code table and the errors commands return
+ */
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"ena/internal/fault"
+)
+
+// Exit codes, documented by exitCodesHelp; the table lives in fault so the
+// daemon can send them back with its errors
+const (
+	exitOK          = fault.CodeOK
+	exitFailure     = fault.CodeFailure
+	exitUsage       = fault.CodeUsage
+	exitNotFound    = fault.CodeNotFound
+	exitDenied      = fault.CodeDenied
+	exitPartial     = fault.CodePartial
+	exitUnavailable = fault.CodeUnavailable
+)
+
+// exitCodesHelp documents the exit code table
+const exitCodesHelp = `Every command exits with one of these codes:
+
+  0  ok           the command did what was asked
+  1  failure      the command failed, or diff found differences
+  2  usage        unknown command or flag, or invalid arguments
+  3  not found    a file, backup, job or other named thing does not exist
+  4  denied       refused by the safety policy or by file permissions
+  5  partial      some items of a batch failed and the rest succeeded
+  6  unavailable  the daemon or another service the command needs is not running
+
+Error messages go to stderr, so stdout holds only the command's output. With
+--output json, yaml or table, ok is false exactly when the command reports an
+error, so a diff that found differences exits 1 with ok true.
+
+Examples:
+  ena batch-delete old/*.log || echo "failed with $?"
+  ena --plain list-backups > backups.txt`
+
+// commandError is returned from RunE by a command that reported an error
+type commandError struct {
+	code    int
+	message string
+}
+
+func (e *commandError) Error() string {
+	return e.message
+}
+
+// ExitCode returns the code the command exits with
+func (e *commandError) ExitCode() int {
+	return e.code
+}
+
+// addExitCodesTopic registers the help topic listing exit codes
+func addExitCodesTopic(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "exit-codes",
+		Short: "Exit codes commands return to scripts",
+		Long:  exitCodesHelp,
+	})
+}
+
+// exitCodeOf picks the exit code for an error message from the class of the
+// first classified error it was formatted with
+func exitCodeOf(args []interface{}) int {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if code := fault.Code(err); code != exitFailure {
+				return code
+			}
+		}
+	}
+	return exitFailure
+}
@@ -26,8 +26,9 @@ func setupFileCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// File operation commands - handling files with care ✨
 
 	var fileCmd = &cobra.Command{
-		Use:   "file [operation] [args...]",
-		Short: "File operation commands",
+		Use:     "file [operation] [args...]",
+		GroupID: "file",
+		Short:   "File operation commands",
 		Long: `Create, read, write, copy, move, delete, and display information about files.
 
 Examples:
@@ -39,13 +40,13 @@ Examples:
   ena file delete /path/to/file.txt
   ena file info /path/to/file.txt`,
 		Args: cobra.MinimumNArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("file", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -55,13 +56,13 @@ Examples:
 		Short: "Create a file",
 		Long:  "Create a new file at the specified path.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("file", append([]string{"create"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -71,13 +72,13 @@ Examples:
 		Short: "Read a file",
 		Long:  "Read and display the contents of the specified file.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("file", append([]string{"read"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgCyan).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgCyan).Println(result)
+			return nil
 		},
 	}
 
@@ -87,15 +88,15 @@ Examples:
 		Short: "Write to a file",
 		Long:  "Write content to the specified file.",
 		Args:  cobra.MinimumNArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			path := args[0]
 			content := strings.Join(args[1:], " ")
 			result, err := assistant.ProcessCommand("file", []string{"write", path, content})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -105,13 +106,13 @@ Examples:
 		Short: "Copy a file",
 		Long:  "Copy the specified file to a new location.",
 		Args:  cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("file", append([]string{"copy"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -121,13 +122,13 @@ Examples:
 		Short: "Move a file",
 		Long:  "Move the specified file to a new location.",
 		Args:  cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("file", append([]string{"move"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -135,31 +136,42 @@ Examples:
 	var deleteCmd = &cobra.Command{
 		Use:   "delete <path> [--force]",
 		Short: "Delete a file",
-		Long:  "Delete the specified file. Use --force flag to delete without confirmation.",
+		Long:  "Move the specified file to the trash, or delete it outright with --permanent. Use --force flag to delete without confirmation.",
 		Args:  cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			if force {
+				args = append(args, "--force")
+			}
+			if permanent, _ := cmd.Flags().GetBool("permanent"); permanent {
+				args = append(args, "--permanent")
+			}
+
 			result, err := assistant.ProcessCommand("file", append([]string{"delete"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
+	deleteCmd.Flags().Bool("force", false, "Force deletion without confirmation")
+	deleteCmd.Flags().Bool("permanent", false, "Delete outright instead of moving to the trash")
+
 	// File info command
 	var infoCmd = &cobra.Command{
 		Use:   "info <path>",
 		Short: "Show file information",
 		Long:  "Display detailed information about the specified file.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("file", append([]string{"info"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgBlue).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgBlue).Println(result)
+			return nil
 		},
 	}
 
@@ -24,8 +24,9 @@ func setupFolderCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Folder operation commands - keeping things organized ✨
 
 	var folderCmd = &cobra.Command{
-		Use:   "folder [operation] [args...]",
-		Short: "Folder operation commands",
+		Use:     "folder [operation] [args...]",
+		GroupID: "folder",
+		Short:   "Folder operation commands",
 		Long: `Create, list, delete, and display information about folders.
 
 Examples:
@@ -34,13 +35,13 @@ Examples:
   ena folder delete /path/to/folder
   ena folder info /path/to/folder`,
 		Args: cobra.MinimumNArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("folder", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -50,13 +51,13 @@ Examples:
 		Short: "Create a folder",
 		Long:  "Create a new folder at the specified path.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("folder", append([]string{"create"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -66,45 +67,56 @@ Examples:
 		Short: "List folder contents",
 		Long:  "Display the contents of the specified folder.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("folder", append([]string{"list"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgCyan).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgCyan).Println(result)
+			return nil
 		},
 	}
 
 	// Delete folder command
 	var deleteCmd = &cobra.Command{
-		Use:   "delete <path>",
+		Use:   "delete <path> --force",
 		Short: "Delete a folder",
-		Long:  "Delete the specified folder and its contents.",
+		Long:  "Move the specified folder and its contents to the trash, or delete them outright with --permanent. Requires --force for safety.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			if force {
+				args = append(args, "--force")
+			}
+			if permanent, _ := cmd.Flags().GetBool("permanent"); permanent {
+				args = append(args, "--permanent")
+			}
+
 			result, err := assistant.ProcessCommand("folder", append([]string{"delete"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
+	deleteCmd.Flags().Bool("force", false, "Confirm deletion of the folder and all its contents")
+	deleteCmd.Flags().Bool("permanent", false, "Delete outright instead of moving to the trash")
+
 	// Folder info command
 	var infoCmd = &cobra.Command{
 		Use:   "info <path>",
 		Short: "Show folder information",
 		Long:  "Display detailed information about the specified folder.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("folder", append([]string{"info"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgBlue).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgBlue).Println(result)
+			return nil
 		},
 	}
 
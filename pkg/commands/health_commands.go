@@ -24,8 +24,9 @@ func setupHealthCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Health check commands - monitoring system health with care ✨
 
 	var healthCmd = &cobra.Command{
-		Use:   "health",
-		Short: "Check system health status",
+		Use:     "health",
+		GroupID: "health",
+		Short:   "Check system health status",
 		Long: `Generate a comprehensive system health status report.
 
 Includes the following information:
@@ -38,13 +39,19 @@ Includes the following information:
 Example:
   ena health`,
 		Args: cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Scripts get the typed snapshot instead of the report text
+			if structured() {
+				reportResult(assistant.Health.GetHealthSnapshot())
+				return nil
+			}
+
 			result, err := assistant.ProcessCommand("health", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -0,0 +1,254 @@
+/**
+ * Natural Language Commands
+ *
+ * Lets users ask for file operations in plain English with "ena do". The
+ * request is compiled into a pattern operation or batch job, previewed, and
+ * only run once the user confirms.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: intent_commands.go
+ * Description: Natural-language request command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/app"
+	"ena/internal/batch"
+	"ena/internal/input"
+	"ena/internal/intent"
+	"ena/internal/output"
+	"ena/internal/patterns"
+	"ena/internal/settings"
+)
+
+// previewLimit is how many matched files are listed before asking to go ahead
+const previewLimit = 10
+
+// intentResult is what "ena do" reports: the understood plan and what it did
+type intentResult struct {
+	Plan      *intent.Plan               `json:"plan"`
+	Operation *patterns.PatternOperation `json:"operation,omitempty"`
+	Executed  bool                       `json:"executed"`
+	Pattern   *patterns.PatternResult    `json:"pattern_result,omitempty"`
+	Job       *batch.BatchJob            `json:"batch_job,omitempty"`
+}
+
+// intentGrammar returns the built-in vocabulary extended by config.yaml
+func intentGrammar(config *settings.Config) intent.Grammar {
+	return intent.DefaultGrammar().Merge(config.Intent)
+}
+
+// setupIntentCommands adds the natural-language command to the root command
+func setupIntentCommands(rootCmd *cobra.Command, services *app.App) {
+	doCmd := &cobra.Command{
+		Use:     "do <request...>",
+		GroupID: "ask",
+		Short:   "Describe a file operation in plain English",
+		Long: `Describe what you want done and Ena turns it into an operation, shows the
+plan and the files it matches, and asks before changing anything.
+
+A request starts with move, copy, delete or list (or a synonym such as mv,
+remove or find) followed by any of:
+
+  kinds       pdfs, images, logs, videos, archives, *.txt, "md files"
+  age         older than a month, newer than 3 days, today
+  size        larger than 100MB, smaller than 1 kb
+  name        named invoice, containing TODO
+  sources     from Downloads, in ~/projects and Desktop (default: here)
+  destination to Documents/Archive
+  depth       recursively, including subfolders
+
+Place names like Downloads, Desktop and Documents expand to folders in your
+home directory. Naming files instead of describing them (move a.txt b.txt to
+old) runs a batch job. Extra verbs, kinds and places can be added in the
+intent section of config.yaml.
+
+In interactive mode a request can be typed without the "do".
+
+Examples:
+  ena do move pdfs older than a month from Downloads to Documents/Archive
+  ena do delete logs older than 2 weeks in tmp --dry-run
+  ena do copy images larger than 5MB from Desktop to Pictures --yes
+  ena do list videos in home recursively`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			plan, err := intentGrammar(services.Config).Parse(args)
+			if err != nil {
+				return reportError("❌ %v\n", err)
+			}
+
+			output.Printf("💬 I understood: %s\n", plan.Summary())
+
+			if plan.IsBatch() {
+				return runBatchIntent(services, plan, yes, dryRun)
+			}
+			return runPatternIntent(services, plan, yes, dryRun)
+		},
+	}
+
+	doCmd.Flags().BoolP("yes", "y", false, "Run without asking for confirmation")
+	doCmd.Flags().Bool("dry-run", false, "Show the plan and matching files without changing anything")
+
+	rootCmd.AddCommand(doCmd)
+}
+
+// runPatternIntent previews and runs a plan that describes its files
+func runPatternIntent(services *app.App, plan *intent.Plan, yes, dryRun bool) error {
+	engine := services.Patterns
+	operation := plan.Operation()
+	showOperationPlan(operation)
+
+	preview, err := engine.Execute(operation, true)
+	if err != nil {
+		return reportError("❌ Error: %v\n", err)
+	}
+
+	result := intentResult{Plan: plan, Operation: operation, Pattern: preview}
+	if preview.FilesMatched == 0 {
+		reportResult(result)
+		output.Printf("🌸 No files match that request\n")
+		return nil
+	}
+
+	files := make([]string, 0, len(preview.Details))
+	for _, detail := range preview.Details {
+		files = append(files, detail.FilePath)
+	}
+
+	// Listing changes nothing, so it needs no confirmation
+	if plan.Action == intent.ActionList {
+		reportResult(result)
+		output.Printf("🔍 %d matching files:\n", len(files))
+		for _, file := range files {
+			output.Printf("   📄 %s\n", file)
+		}
+		return nil
+	}
+
+	showPreview(files)
+	if ok, err := proceed(plan, len(files), yes, dryRun); !ok {
+		reportResult(result)
+		return err
+	}
+
+	executed, err := engine.Execute(operation, false)
+	if err != nil {
+		return reportError("❌ Error: %v\n", err)
+	}
+
+	reportResult(intentResult{Plan: plan, Operation: operation, Executed: true, Pattern: executed})
+	showPatternResults([]patterns.PatternResult{*executed}, false, previewLimit)
+	if executed.FilesFailed == 0 {
+		return nil
+	}
+	code := exitFailure
+	if executed.FilesFailed < executed.FilesMatched {
+		code = exitPartial
+	}
+	return reportErrorCode(code, "❌ %d of %d files failed\n", executed.FilesFailed, executed.FilesMatched)
+}
+
+// runBatchIntent previews and runs a plan that names its files
+func runBatchIntent(services *app.App, plan *intent.Plan, yes, dryRun bool) error {
+	showPreview(plan.Files)
+
+	result := intentResult{Plan: plan}
+	if ok, err := proceed(plan, len(plan.Files), yes, dryRun); !ok {
+		reportResult(result)
+		return err
+	}
+
+	batchManager := services.Batch
+	job, err := plan.BatchJob(batchManager, services.Config.Batch)
+	if err != nil {
+		return reportError("❌ Error creating batch job: %v\n", err)
+	}
+
+	output.Printf("🚀 Starting batch %s operation...\n", plan.Action)
+	if err := batchManager.ExecuteBatchJob(job.ID); err != nil {
+		return reportError("❌ Error executing batch %s: %v\n", plan.Action, err)
+	}
+
+	finalJob, _ := batchManager.GetJobStatus(job.ID)
+	result.Executed = true
+	result.Job = finalJob
+	reportResult(result)
+	output.Printf("✅ Batch %s completed!\n", plan.Action)
+	output.Printf("📊 Success: %d | Errors: %d | Skipped: %d\n",
+		finalJob.SuccessCount, finalJob.ErrorCount, finalJob.SkippedCount)
+	return reportJobErrors(finalJob)
+}
+
+// showOperationPlan prints the filters and actions a request compiled to
+func showOperationPlan(operation *patterns.PatternOperation) {
+	output.Printf("🧭 Plan:\n")
+	for _, path := range operation.Paths {
+		output.Printf("   📁 Look in %s", path)
+		if operation.Recursive {
+			fmt.Printf(" (recursive)")
+		}
+		fmt.Println()
+	}
+	for _, filter := range operation.Filters {
+		output.Printf("   🔎 %s %s %v\n", filter.Type, filter.Operator, filter.Value)
+	}
+	for _, action := range operation.Actions {
+		if action.Destination != "" {
+			output.Printf("   ⚡ %s → %s\n", action.Type, action.Destination)
+		} else {
+			output.Printf("   ⚡ %s\n", action.Type)
+		}
+	}
+}
+
+// showPreview lists the first files a request will touch
+func showPreview(files []string) {
+	output.Printf("📋 %d files:\n", len(files))
+	for i, file := range files {
+		if i == previewLimit {
+			fmt.Printf("   ... and %d more\n", len(files)-previewLimit)
+			break
+		}
+		output.Printf("   📄 %s\n", file)
+	}
+}
+
+// proceed decides whether a previewed request runs, asking the user unless
+// told not to; the error is set when the request cannot be asked about
+func proceed(plan *intent.Plan, count int, yes, dryRun bool) (bool, error) {
+	if dryRun {
+		output.Printf("🔍 Dry run - nothing was changed\n")
+		return false, nil
+	}
+	if yes {
+		return true, nil
+	}
+
+	// Structured output has no one to answer a prompt
+	if structured() {
+		return false, reportErrorCode(exitUsage, "❌ Add --yes to run this request, or --dry-run to preview it\n")
+	}
+
+	action := string(plan.Action)
+	output.Printf("⚠️  %s%s %d files? (y/N): ", strings.ToUpper(action[:1]), action[1:], count)
+	response, err := input.Answer()
+	if err != nil {
+		return false, reportError("❌ %v\n", err)
+	}
+
+	if response != "y" && response != "yes" {
+		output.Printf("Cancelled 😅\n")
+		return false, nil
+	}
+	return true, nil
+}
@@ -21,8 +21,9 @@ import (
 func setupMultiCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Multi-progress command
 	var multiCmd = &cobra.Command{
-		Use:   "multi <operation> [files...]",
-		Short: "Process multiple files with multiple progress bars",
+		Use:     "multi <operation> [files...]",
+		GroupID: "multi",
+		Short:   "Process multiple files with multiple progress bars",
 		Long: `Process multiple files simultaneously with individual progress bars showing:
   • Multiple concurrent progress bars
   • Individual file processing status
@@ -34,14 +35,14 @@ Example:
   ena multi "Processing" file1.txt file2.txt file3.txt
   ena multi "Converting" *.jpg`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("multi", args)
 			if err != nil {
-				cmd.PrintErrln("❌ Error:", err)
-				return
+				return reportError("❌ Error: %v\n", err)
 			}
 
 			cmd.Println(result)
+			return nil
 		},
 	}
 
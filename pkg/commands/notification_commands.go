@@ -21,8 +21,9 @@ import (
 func setupNotificationCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Notification command
 	var notifyCmd = &cobra.Command{
-		Use:   "notify <operation> [args...]",
-		Short: "Manage desktop notifications with cross-platform support",
+		Use:     "notify <operation> [args...]",
+		GroupID: "notify",
+		Short:   "Manage desktop notifications with cross-platform support",
 		Long: `Cross-platform desktop notification system with comprehensive features:
   • Send notifications for completed tasks and system events
   • Support for different notification types (success, error, warning, info, task)
@@ -41,6 +42,12 @@ Operations:
   config                  - Show notification configuration
   demo                    - Demonstrate different notification types
 
+Automatic Notifications:
+  Batch jobs, execute-all, backups, downloads and app scans notify when they
+  finish or fail, and interactive commands notify when they run longer than
+  long_command_threshold. Switch each off under notifications.automatic in
+  config.yaml.
+
 Notification Types:
   success                 - Success notifications (green)
   error                   - Error notifications (red)
@@ -60,15 +67,16 @@ Examples:
   ena notify status                         # Check notification system status
   ena notify demo                           # Demonstrate all notification types
   ena notify history                        # View notification history`,
-		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		ValidArgs: []string{"test", "send", "status", "history", "clear", "enable", "disable", "config", "demo"},
+		Args:      cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("notify", args)
 			if err != nil {
-				cmd.PrintErrln("❌ Error:", err)
-				return
+				return reportError("❌ Error: %v\n", err)
 			}
 
 			cmd.Println(result)
+			return nil
 		},
 	}
 
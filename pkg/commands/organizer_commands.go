@@ -14,11 +14,12 @@ package commands
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
+	"ena/internal/app"
 	"ena/internal/organizer"
+	"ena/internal/output"
 
 	"github.com/spf13/cobra"
 )
@@ -28,27 +29,16 @@ type OrganizationRule = organizer.OrganizationRule
 type OrganizationResult = organizer.OrganizationResult
 type RuleAction = organizer.RuleAction
 
-// Global file organizer instance
-var globalFileOrganizer *organizer.FileOrganizer
-
-// getGlobalFileOrganizer returns the global file organizer instance
-func getGlobalFileOrganizer() *organizer.FileOrganizer {
-	if globalFileOrganizer == nil {
-		analytics := getGlobalAnalytics()
-		globalFileOrganizer = organizer.NewFileOrganizer(analytics)
-	}
-	return globalFileOrganizer
-}
-
 // setupOrganizerCommands adds file organization commands to the root command
-func setupOrganizerCommands(rootCmd *cobra.Command) {
+func setupOrganizerCommands(rootCmd *cobra.Command, services *app.App) {
 	// Get file organizer from system hooks
-	organizer := getGlobalFileOrganizer()
+	organizer := services.Organizer
 
 	// Organize command
 	organizeCmd := &cobra.Command{
-		Use:   "organize <path> [paths...]",
-		Short: "Organize files using smart organization rules",
+		Use:     "organize <path> [paths...]",
+		GroupID: "organize",
+		Short:   "Organize files using smart organization rules",
 		Long: `Organize files in specified directories using configured organization rules.
 Files will be automatically sorted, moved, copied, or renamed based on their type and rules.
 
@@ -57,39 +47,39 @@ Examples:
   ena organize ~/Desktop ~/Documents          # Organize multiple folders
   ena organize ~/Downloads --dry-run          # Preview organization without making changes`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 			verbose, _ := cmd.Flags().GetBool("verbose")
 
 			// Validate paths
 			var validPaths []string
 			for _, path := range args {
-				if _, err := os.Stat(path); err != nil {
-					fmt.Printf("⚠️ Warning: Path %s does not exist, skipping\n", path)
+				if _, err := services.FS.Stat(path); err != nil {
+					output.Printf("⚠️ Warning: Path %s does not exist, skipping\n", path)
 					continue
 				}
 				validPaths = append(validPaths, path)
 			}
 
 			if len(validPaths) == 0 {
-				fmt.Println("❌ No valid paths provided")
-				return
+				return reportError("❌ No valid paths provided\n")
 			}
 
-			fmt.Printf("🌸 Starting file organization for %d path(s)...\n", len(validPaths))
+			output.Printf("🌸 Starting file organization for %d path(s)...\n", len(validPaths))
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be moved")
+				output.Printf("🔍 Dry run mode - no files will be moved\n")
 			}
 
 			// Organize files
 			results, err := organizer.OrganizeFiles(validPaths, dryRun)
 			if err != nil {
-				fmt.Printf("❌ Error organizing files: %v\n", err)
-				return
+				return reportError("❌ Error organizing files: %v\n", err)
 			}
 
 			// Display results
+			reportResult(results)
 			showOrganizationResults(results, verbose)
+			return nil
 		},
 	}
 
@@ -98,8 +88,9 @@ Examples:
 
 	// Add rule command
 	addRuleCmd := &cobra.Command{
-		Use:   "add-rule <name>",
-		Short: "Add a new organization rule",
+		Use:     "add-rule <name>",
+		GroupID: "organize",
+		Short:   "Add a new organization rule",
 		Long: `Add a new organization rule for automatic file sorting.
 This command will prompt for rule configuration details.
 
@@ -107,7 +98,7 @@ Examples:
   ena add-rule "Documents Organization"
   ena add-rule "Image Sorting"`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			ruleName := args[0]
 
 			// Create a basic rule
@@ -129,35 +120,46 @@ Examples:
 
 			err := organizer.AddRule(rule)
 			if err != nil {
-				fmt.Printf("❌ Error adding rule: %v\n", err)
-				return
+				return reportError("❌ Error adding rule: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully added organization rule: %s\n", rule.Name)
-			fmt.Printf("🆔 Rule ID: %s\n", rule.ID)
+			reportResult(rule)
+			output.Printf("✅ Successfully added organization rule: %s\n", rule.Name)
+			output.Printf("🆔 Rule ID: %s\n", rule.ID)
+			return nil
 		},
 	}
 
 	// List rules command
 	listRulesCmd := &cobra.Command{
-		Use:   "list-rules",
-		Short: "List all organization rules",
+		Use:     "list-rules",
+		GroupID: "organize",
+		Short:   "List all organization rules",
 		Long: `List all configured organization rules with their details.
 Shows rule names, priorities, file types, and status.
 
 Examples:
   ena list-rules
   ena list-rules --enabled-only`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			enabledOnly, _ := cmd.Flags().GetBool("enabled-only")
 
 			rules := organizer.GetRules()
+
+			listed := []*OrganizationRule{}
+			for _, rule := range rules {
+				if !enabledOnly || rule.Enabled {
+					listed = append(listed, rule)
+				}
+			}
+			reportResult(listed)
+
 			if len(rules) == 0 {
-				fmt.Println("🌸 No organization rules configured")
-				return
+				output.Printf("🌸 No organization rules configured\n")
+				return nil
 			}
 
-			fmt.Println("🌸 Organization Rules (╹◡╹)♡")
+			output.Printf("🌸 Organization Rules (╹◡╹)♡\n")
 			fmt.Println("================================")
 
 			for i, rule := range rules {
@@ -165,61 +167,106 @@ Examples:
 					continue
 				}
 
-				status := "❌ Disabled"
+				status := output.Prose("❌ Disabled")
 				if rule.Enabled {
-					status = "✅ Enabled"
+					status = output.Prose("✅ Enabled")
 				}
 
 				fmt.Printf("%d. %s (%s)\n", i+1, rule.Name, status)
-				fmt.Printf("   📝 Description: %s\n", rule.Description)
-				fmt.Printf("   🆔 ID: %s\n", rule.ID)
-				fmt.Printf("   📊 Priority: %d\n", rule.Priority)
-				fmt.Printf("   📁 Source Paths: %s\n", strings.Join(rule.SourcePaths, ", "))
-				fmt.Printf("   📂 Destination: %s\n", rule.DestPath)
-				fmt.Printf("   🏷️  File Types: %s\n", strings.Join(rule.FileTypes, ", "))
-				fmt.Printf("   📅 Created: %s\n", rule.CreatedAt.Format("2006-01-02 15:04:05"))
+				output.Printf("   📝 Description: %s\n", rule.Description)
+				output.Printf("   🆔 ID: %s\n", rule.ID)
+				output.Printf("   📊 Priority: %d\n", rule.Priority)
+				output.Printf("   📁 Source Paths: %s\n", strings.Join(rule.SourcePaths, ", "))
+				output.Printf("   📂 Destination: %s\n", rule.DestPath)
+				output.Printf("   🏷️  File Types: %s\n", strings.Join(rule.FileTypes, ", "))
+				output.Printf("   📅 Created: %s\n", rule.CreatedAt.Format("2006-01-02 15:04:05"))
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
 	listRulesCmd.Flags().Bool("enabled-only", false, "Show only enabled rules")
 
+	// Remove rule command
+	removeRuleCmd := &cobra.Command{
+		Use:     "remove-rule <name|id>",
+		GroupID: "organize",
+		Short:   "Remove an organization rule",
+		Long: `Remove an organization rule by its name or ID.
+
+Examples:
+  ena remove-rule "Image Sorting"
+  ena remove-rule rule_1234567890`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(ruleCandidates(organizer), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// An ID match wins over a rule that happens to be named like one
+			var rule *OrganizationRule
+			for _, candidate := range organizer.GetRules() {
+				if candidate.ID == args[0] {
+					rule = candidate
+					break
+				}
+				if candidate.Name == args[0] && rule == nil {
+					rule = candidate
+				}
+			}
+			if rule == nil {
+				return reportErrorCode(exitNotFound, "❌ Rule %s not found\n", args[0])
+			}
+
+			if err := organizer.RemoveRule(rule.ID); err != nil {
+				return reportError("❌ Error removing rule: %v\n", err)
+			}
+
+			reportResult(rule)
+			output.Printf("✅ Removed organization rule: %s\n", rule.Name)
+			return nil
+		},
+	}
+
 	// Get watched paths command
 	watchedPathsCmd := &cobra.Command{
-		Use:   "watched-paths",
-		Short: "Show paths being watched by organization rules",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:     "watched-paths",
+		GroupID: "organize",
+		Short:   "Show paths being watched by organization rules",
+		RunE: func(cmd *cobra.Command, args []string) error {
 			paths := organizer.GetWatchedPaths()
+			reportResult(append([]string{}, paths...))
 			if len(paths) == 0 {
-				fmt.Println("🌸 No paths being watched")
-				return
+				output.Printf("🌸 No paths being watched\n")
+				return nil
 			}
 
-			fmt.Println("🌸 Watched Paths (╹◡╹)♡")
+			output.Printf("🌸 Watched Paths (╹◡╹)♡\n")
 			fmt.Println("========================")
 			for i, path := range paths {
 				fmt.Printf("%d. %s\n", i+1, path)
 			}
+			return nil
 		},
 	}
 
 	// Get file extensions command
 	extensionsCmd := &cobra.Command{
-		Use:   "file-extensions",
-		Short: "Show all supported file extensions",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:     "file-extensions",
+		GroupID: "organize",
+		Short:   "Show all supported file extensions",
+		RunE: func(cmd *cobra.Command, args []string) error {
 			extensions := organizer.GetAllFileExtensions()
+			reportResult(append([]string{}, extensions...))
 			if len(extensions) == 0 {
-				fmt.Println("🌸 No file extensions configured")
-				return
+				output.Printf("🌸 No file extensions configured\n")
+				return nil
 			}
 
-			fmt.Println("🌸 Supported File Extensions (╹◡╹)♡")
+			output.Printf("🌸 Supported File Extensions (╹◡╹)♡\n")
 			fmt.Println("====================================")
 			for i, ext := range extensions {
 				fmt.Printf("%d. %s\n", i+1, ext)
 			}
+			return nil
 		},
 	}
 
@@ -227,6 +274,7 @@ Examples:
 	rootCmd.AddCommand(organizeCmd)
 	rootCmd.AddCommand(addRuleCmd)
 	rootCmd.AddCommand(listRulesCmd)
+	rootCmd.AddCommand(removeRuleCmd)
 	rootCmd.AddCommand(watchedPathsCmd)
 	rootCmd.AddCommand(extensionsCmd)
 }
@@ -234,7 +282,7 @@ Examples:
 // Helper function to display organization results
 func showOrganizationResults(results []OrganizationResult, verbose bool) {
 	if len(results) == 0 {
-		fmt.Println("🌸 No organization rules applied")
+		output.Printf("🌸 No organization rules applied\n")
 		return
 	}
 
@@ -245,7 +293,7 @@ func showOrganizationResults(results []OrganizationResult, verbose bool) {
 	totalDeleted := 0
 	totalErrors := 0
 
-	fmt.Println("🌸 Organization Results (╹◡╹)♡")
+	output.Printf("🌸 Organization Results (╹◡╹)♡\n")
 	fmt.Println("================================")
 
 	for i, result := range results {
@@ -257,15 +305,15 @@ func showOrganizationResults(results []OrganizationResult, verbose bool) {
 		totalErrors += len(result.Errors)
 
 		fmt.Printf("Rule %d (%s):\n", i+1, result.RuleID)
-		fmt.Printf("  📊 Files Processed: %d\n", result.FilesProcessed)
-		fmt.Printf("  📁 Files Moved: %d\n", result.FilesMoved)
-		fmt.Printf("  📋 Files Copied: %d\n", result.FilesCopied)
-		fmt.Printf("  ✏️  Files Renamed: %d\n", result.FilesRenamed)
-		fmt.Printf("  🗑️  Files Deleted: %d\n", result.FilesDeleted)
-		fmt.Printf("  ⏱️  Duration: %s\n", result.Duration.String())
+		output.Printf("  📊 Files Processed: %d\n", result.FilesProcessed)
+		output.Printf("  📁 Files Moved: %d\n", result.FilesMoved)
+		output.Printf("  📋 Files Copied: %d\n", result.FilesCopied)
+		output.Printf("  ✏️  Files Renamed: %d\n", result.FilesRenamed)
+		output.Printf("  🗑️  Files Deleted: %d\n", result.FilesDeleted)
+		output.Printf("  ⏱️  Duration: %s\n", result.Duration.String())
 
 		if len(result.Errors) > 0 {
-			fmt.Printf("  ❌ Errors: %d\n", len(result.Errors))
+			output.Printf("  ❌ Errors: %d\n", len(result.Errors))
 			if verbose {
 				for _, err := range result.Errors {
 					fmt.Printf("    - %s\n", err)
@@ -273,32 +321,34 @@ func showOrganizationResults(results []OrganizationResult, verbose bool) {
 			}
 		}
 
+		showChanges("  ", result.Changes)
+
 		if verbose && len(result.Details) > 0 {
-			fmt.Println("  📋 Details:")
+			output.Printf("  📋 Details:\n")
 			for _, detail := range result.Details {
-				status := "✅"
+				status := output.Prose("✅ ")
 				if !detail.Success {
-					status = "❌"
+					status = output.Prose("❌ ")
 				}
-				fmt.Printf("    %s %s -> %s\n", status, detail.Action, filepath.Base(detail.FilePath))
+				fmt.Printf("    %s%s -> %s\n", status, detail.Action, filepath.Base(detail.FilePath))
 				if detail.Destination != "" {
-					fmt.Printf("      📂 %s\n", detail.Destination)
+					output.Printf("      📂 %s\n", detail.Destination)
 				}
 				if detail.Error != "" {
-					fmt.Printf("      ❌ %s\n", detail.Error)
+					output.Printf("      ❌ %s\n", detail.Error)
 				}
 			}
 		}
 		fmt.Println()
 	}
 
-	fmt.Println("📊 Summary:")
-	fmt.Printf("  📁 Total Files Processed: %d\n", totalFiles)
-	fmt.Printf("  📁 Total Files Moved: %d\n", totalMoved)
-	fmt.Printf("  📋 Total Files Copied: %d\n", totalCopied)
-	fmt.Printf("  ✏️  Total Files Renamed: %d\n", totalRenamed)
-	fmt.Printf("  🗑️  Total Files Deleted: %d\n", totalDeleted)
+	output.Printf("📊 Summary:\n")
+	output.Printf("  📁 Total Files Processed: %d\n", totalFiles)
+	output.Printf("  📁 Total Files Moved: %d\n", totalMoved)
+	output.Printf("  📋 Total Files Copied: %d\n", totalCopied)
+	output.Printf("  ✏️  Total Files Renamed: %d\n", totalRenamed)
+	output.Printf("  🗑️  Total Files Deleted: %d\n", totalDeleted)
 	if totalErrors > 0 {
-		fmt.Printf("  ❌ Total Errors: %d\n", totalErrors)
+		output.Printf("  ❌ Total Errors: %d\n", totalErrors)
 	}
 }
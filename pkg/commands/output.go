@@ -0,0 +1,377 @@
+/**
+ * Command Output
+ *
+ * Implements the global --output and --plain flags. In text mode commands print
+ * their usual prose; in json, yaml and table modes that prose is captured and
+ * replaced by a single structured document built from the command's typed
+ * result. Plain mode drops emoji, kaomoji and colour from Ena's messages, for
+ * scripts and screen readers, while file contents and other data are printed
+ * unchanged.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: output.go
+ * Description: Output format selection, result reporting and exit codes
+ */
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"ena/internal/audit"
+	"ena/internal/output"
+)
+
+// outputSession collects what one command invocation reports
+type outputSession struct {
+	format   output.Format
+	stdout   *os.File
+	colorOut io.Writer
+	noColor  bool
+	pipe     *os.File
+	captured chan string
+	data     interface{}
+	hasData  bool
+	errors   []string
+	code     int  // exit code of the first error reported
+	plain    bool // this command turned plain prose on
+}
+
+// currentOutput is the session of the command being executed
+var currentOutput = &outputSession{format: output.FormatText}
+
+// outputHelp documents the structured output schemas
+const outputHelp = `Every command accepts --output (-o) with one of: text, json, yaml, table.
+
+text is the default, friendly output. The other formats print exactly one
+document on stdout and nothing else:
+
+  command  full command path, e.g. "batch-status"
+  ok       false when the command reported an error
+  data     the command's payload (see below)
+  error    error message, only present when ok is false
+
+The exit code is 0 when ok is true; "ena exit-codes" lists the others. Field
+names are snake_case; sizes are bytes, durations are nanoseconds and times are RFC 3339.
+Table output shows scalar fields only - use json or yaml for nested data.
+
+Payloads:
+  health                                 health snapshot (cpu, memory, disk, runtime, issues)
+  watch status, watch metrics            {running, paths, stats, metrics}
+  batch-delete, batch-copy, batch-move   batch job
+  batch-status, daemon jobs              batch job, or list of batch jobs
+  daemon status                          daemon status
+  undo-history                           list of undo sessions (one session with --session)
+  undo-session                           the undone session
+  restore-file                           the undone operation
+  find, execute-operation                pattern result
+  execute-all                            list of pattern results
+  search --content                       {pattern, files, summary}
+  create-operation, list-operations      pattern operation(s)
+  create-backup                          backup metadata
+  list-backups                           list of backup metadata
+  backup-stats                           {stats, config}
+  backup-cleanup                         {cleaned}
+  trash list                             list of trash items
+  trash restore                          {restored}
+  trash empty, trash purge               {purged, freed}
+  dupes                                  duplicate groups, plan with --action, or its result
+  du                                     {root, types, ages} with the tree cut to --depth
+  diff                                   file diff, directory diff, or list of file diffs with --backup
+  scan-apps                              app detection result
+  list-apps, running-apps, default-apps  list of app info
+  app-info                               app info
+  app-stats                              app statistics
+  organize                               list of organization results
+  add-rule, list-rules, remove-rule      organization rule(s)
+  watched-paths, file-extensions         list of strings
+  suggest, workflow, optimize            list of suggestions
+  stats                                  usage statistics
+  config path, config init               {config, data, state}
+  config show                            settings from config.yaml
+  config migrate                         {moved, skipped}
+  audit                                  list of audit entries
+  audit verify                           {path, entries, intact, break}
+  policy path, policy init               {policy}
+  policy show                            {rules}
+  policy check                           {source, operation, path, target, effect, rule}
+  schedule add, list, remove             scheduled job(s)
+  schedule run-now                       {job_id, job_name, trigger, status, summary, error}
+  schedule history                       list of scheduled runs
+  events topics                          list of {topic, description}
+  events sinks                           list of sinks with delivery counts
+  events test                            {sink, id, topic, attempts, status, duration, error}
+  script create, show, list              script(s)
+  script run                             {script, params, ok, undo_session_id, steps}
+  record start, stop, status             {recording, file, commands}
+  replay                                 {session, dry_run, ok, rebases, undo_session_id, steps}
+  do                                     {plan, operation, executed, pattern_result, batch_job}
+  plugins                                list of plugins
+  <plugin>                               the JSON the plugin printed, if any
+  anything else                          {"message": "..."} with the usual text
+
+Plain text:
+  --plain, or ENA_PLAIN=1, keeps the text format but drops emoji, kaomoji,
+  colour and drawn progress bars from Ena's messages, for scripts, logs and
+  screen readers. File contents, diffs and plugin output are never changed.
+  It is on by default when stdout is not a terminal; --plain=false or
+  ENA_PLAIN=0 turns it off. Errors always go to stderr.
+
+Examples:
+  ena health -o json
+  ena batch-status -o table
+  ena list-backups -o yaml
+  ena --plain list-backups`
+
+// addOutputFlag registers --output, --plain and the help topic describing them
+func addOutputFlag(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringP("output", "o", string(output.FormatText), "Output format: text, json, yaml or table")
+	rootCmd.PersistentFlags().Bool("plain", false, "Plain text without emoji or colour (default when stdout is not a terminal, or ENA_PLAIN is set)")
+	rootCmd.PersistentPreRunE = beginOutput
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	// Help is all Ena's own text, so plain mode strips the whole of it
+	help := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if !plainMode(cmd) {
+			help(cmd, args)
+			return
+		}
+		var text bytes.Buffer
+		cmd.SetOut(&text)
+		help(cmd, args)
+		cmd.SetOut(nil)
+		fmt.Print(output.Plain(text.String()))
+	})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "output",
+		Short: "Machine-readable output formats and schemas",
+		Long:  outputHelp,
+	})
+}
+
+// outputFormatOf reads the --output flag of a parsed command tree
+func outputFormatOf(cmd *cobra.Command) (output.Format, error) {
+	name, err := cmd.Flags().GetString("output")
+	if err != nil || name == "" {
+		return output.FormatText, nil
+	}
+	return output.ParseFormat(name)
+}
+
+// beginOutput validates --output and starts capturing prose for structured formats
+func beginOutput(cmd *cobra.Command, args []string) error {
+	format, err := outputFormatOf(cmd)
+	if err != nil {
+		return err
+	}
+
+	if format.Structured() && !cmd.HasParent() {
+		return fmt.Errorf("--output %s needs a command; interactive mode only supports text", format)
+	}
+
+	// Completion scripts and candidates are read by the shell, so they go out untouched
+	if completionCommand(cmd) {
+		return nil
+	}
+
+	// Files this command changes are attributed to it in the audit log
+	audit.SetCommand(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "))
+
+	currentOutput = &outputSession{format: format}
+	if format.Structured() {
+		return currentOutput.startCapture()
+	}
+	if !output.PlainMode() && plainMode(cmd) {
+		currentOutput.startPlain()
+	}
+	return nil
+}
+
+// plainMode reports whether prose should be plain: --plain wins, then
+// ENA_PLAIN, then whether stdout is a terminal
+func plainMode(cmd *cobra.Command) bool {
+	if flag := cmd.Flags().Lookup("plain"); flag != nil && flag.Changed {
+		plain, _ := cmd.Flags().GetBool("plain")
+		return plain
+	}
+	if value := os.Getenv("ENA_PLAIN"); value != "" {
+		plain, err := strconv.ParseBool(value)
+		return err != nil || plain
+	}
+	return !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// structured reports whether the current command prints a document instead of prose
+func structured() bool {
+	return currentOutput.format.Structured()
+}
+
+// reportResult records the typed result of the current command
+func reportResult(data interface{}) {
+	currentOutput.data = data
+	currentOutput.hasData = true
+}
+
+// reportError prints an error message to stderr in text mode, records the
+// failure and returns it for RunE, with an exit code chosen from the class of
+// the errors it is formatted with
+func reportError(format string, args ...interface{}) error {
+	return reportErrorCode(exitCodeOf(args), format, args...)
+}
+
+// reportErrorCode is reportError with an explicit exit code
+func reportErrorCode(code int, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+
+	if !structured() {
+		color.New(color.FgRed).Fprintf(os.Stderr, output.Prose(format), args...)
+	}
+
+	message = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), "❌"))
+	message = strings.TrimPrefix(message, "Error: ")
+	currentOutput.errors = append(currentOutput.errors, message)
+	if currentOutput.code == exitOK {
+		currentOutput.code = code
+	}
+	return &commandError{code: code, message: message}
+}
+
+// reportExitCode sets the exit code of a command that worked but whose outcome
+// scripts test, as diff exits 1 when its inputs differ
+func reportExitCode(code int) {
+	if currentOutput.code == exitOK {
+		currentOutput.code = code
+	}
+}
+
+// Execute runs the command tree and returns the process exit code
+func Execute(rootCmd *cobra.Command) int {
+	code, _ := executeResult(rootCmd)
+	return code
+}
+
+// executeResult runs the command tree and returns the exit code and the
+// command's typed result, if it reported one
+func executeResult(rootCmd *cobra.Command) (int, interface{}) {
+	executed, err := rootCmd.ExecuteC()
+	if executed == nil {
+		executed = rootCmd
+	}
+
+	session := currentOutput
+	currentOutput = &outputSession{format: output.FormatText}
+	captured := session.stopCapture()
+	defer session.stopPlain()
+
+	// Commands report their own errors; anything else is cobra rejecting the command line
+	var commandErr *commandError
+	if err != nil && !errors.As(err, &commandErr) {
+		session.errors = append(session.errors, err.Error())
+		session.code = exitUsage
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if !strings.Contains(err.Error(), "--help") {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", executed.CommandPath())
+		}
+	}
+
+	// Flag or argument errors happen before the session starts, so re-read the format
+	format := session.format
+	if session.pipe == nil {
+		if parsed, parseErr := outputFormatOf(executed); parseErr == nil {
+			format = parsed
+		}
+	}
+
+	if format.Structured() {
+		doc := output.Document{
+			Command: strings.TrimPrefix(executed.CommandPath(), rootCmd.Name()+" "),
+			OK:      len(session.errors) == 0,
+			Error:   strings.Join(session.errors, "; "),
+		}
+
+		if session.hasData {
+			doc.Data = session.data
+		} else if text := strings.TrimSpace(output.StripANSI(captured)); text != "" {
+			doc.Data = output.Message{Message: text}
+		}
+
+		if writeErr := output.Write(os.Stdout, format, doc); writeErr != nil {
+			output.Fprintf(os.Stderr, "❌ Error: %v\n", writeErr)
+			return exitFailure, session.data
+		}
+	}
+
+	return session.code, session.data
+}
+
+// startCapture redirects stdout so prose does not mix with the document
+func (s *outputSession) startCapture() error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("Failed to capture output: %w", err)
+	}
+
+	s.stdout = os.Stdout
+	s.colorOut = color.Output
+	s.noColor = color.NoColor
+	s.pipe = writer
+	s.captured = make(chan string, 1)
+
+	os.Stdout = writer
+	color.Output = writer
+	color.NoColor = true
+
+	go func() {
+		text, _ := io.ReadAll(reader)
+		reader.Close()
+		s.captured <- string(text)
+	}()
+
+	return nil
+}
+
+// stopCapture restores stdout and returns everything printed meanwhile
+func (s *outputSession) stopCapture() string {
+	if s.pipe == nil {
+		return ""
+	}
+
+	os.Stdout = s.stdout
+	color.Output = s.colorOut
+	color.NoColor = s.noColor
+	s.pipe.Close()
+
+	return <-s.captured
+}
+
+// startPlain drops colour and decoration from messages until the session
+// ends; what commands print as data is left alone
+func (s *outputSession) startPlain() {
+	s.noColor = color.NoColor
+	s.plain = true
+	color.NoColor = true
+	output.SetPlain(true)
+}
+
+// stopPlain ends the plain session this command started, if any
+func (s *outputSession) stopPlain() {
+	if s.plain {
+		output.SetPlain(false)
+		color.NoColor = s.noColor
+		s.plain = false
+	}
+}
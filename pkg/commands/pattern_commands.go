@@ -20,32 +20,23 @@ import (
 	"strings"
 	"time"
 
+	"ena/internal/app"
+	"ena/internal/output"
 	"ena/internal/patterns"
 
 	"github.com/spf13/cobra"
 )
 
-// Global pattern engine instance
-var globalPatternEngine *patterns.PatternEngine
-
-// getGlobalPatternEngine returns the global pattern engine instance
-func getGlobalPatternEngine() *patterns.PatternEngine {
-	if globalPatternEngine == nil {
-		analytics := getGlobalAnalytics()
-		globalPatternEngine = patterns.NewPatternEngine(analytics)
-	}
-	return globalPatternEngine
-}
-
 // setupPatternCommands adds pattern-based operation commands to the root command
-func setupPatternCommands(rootCmd *cobra.Command) {
+func setupPatternCommands(rootCmd *cobra.Command, services *app.App) {
 	// Get pattern engine
-	engine := getGlobalPatternEngine()
+	engine := services.Patterns
 
 	// Find command - execute pattern-based file finding
 	findCmd := &cobra.Command{
-		Use:   "find <pattern> [paths...]",
-		Short: "Find files matching pattern criteria",
+		Use:     "find <pattern> [paths...]",
+		GroupID: "pattern",
+		Short:   "Find files matching pattern criteria",
 		Long: `Find files using advanced pattern matching and filtering.
 Supports filtering by extension, age, size, content, and more.
 
@@ -55,7 +46,7 @@ Examples:
   ena find "*.jpg created today" ~/Pictures
   ena find "files containing 'TODO'" ~/Projects`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			pattern := args[0]
 			var searchPaths []string
 
@@ -72,24 +63,24 @@ Examples:
 			// Create temporary operation for this search
 			operation := createOperationFromPattern(pattern, searchPaths)
 			if operation == nil {
-				fmt.Println("❌ Invalid pattern format")
-				return
+				return reportErrorCode(exitUsage, "❌ Invalid pattern format\n")
 			}
 
-			fmt.Printf("🌸 Searching for: %s\n", operation.Description)
+			output.Printf("🌸 Searching for: %s\n", operation.Description)
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be modified")
+				output.Printf("🔍 Dry run mode - no files will be modified\n")
 			}
 
 			// Execute the search
-			result, err := engine.ExecuteOperation(operation.ID, dryRun)
+			result, err := engine.Execute(operation, dryRun)
 			if err != nil {
-				fmt.Printf("❌ Error executing search: %v\n", err)
-				return
+				return reportError("❌ Error executing search: %v\n", err)
 			}
 
 			// Display results
+			reportResult(result)
 			showPatternResults([]patterns.PatternResult{*result}, verbose, limit)
+			return nil
 		},
 	}
 
@@ -99,8 +90,9 @@ Examples:
 
 	// Create operation command
 	createCmd := &cobra.Command{
-		Use:   "create-operation <name>",
-		Short: "Create a new pattern operation",
+		Use:     "create-operation <name>",
+		GroupID: "pattern",
+		Short:   "Create a new pattern operation",
 		Long: `Create a new pattern operation with custom filters and actions.
 This will prompt for operation configuration details.
 
@@ -108,7 +100,7 @@ Examples:
   ena create-operation "Clean Downloads"
   ena create-operation "Archive Old Files"`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 
 			// Create a sample operation
@@ -118,37 +110,48 @@ Examples:
 
 			err := engine.AddOperation(operation)
 			if err != nil {
-				fmt.Printf("❌ Error creating operation: %v\n", err)
-				return
+				return reportError("❌ Error creating operation: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully created pattern operation: %s\n", operation.Name)
-			fmt.Printf("🆔 Operation ID: %s\n", operation.ID)
-			fmt.Printf("📋 Filters: %d\n", len(operation.Filters))
-			fmt.Printf("📁 Paths: %s\n", strings.Join(operation.Paths, ", "))
+			reportResult(operation)
+			output.Printf("✅ Successfully created pattern operation: %s\n", operation.Name)
+			output.Printf("🆔 Operation ID: %s\n", operation.ID)
+			output.Printf("📋 Filters: %d\n", len(operation.Filters))
+			output.Printf("📁 Paths: %s\n", strings.Join(operation.Paths, ", "))
+			return nil
 		},
 	}
 
 	// List operations command
 	listCmd := &cobra.Command{
-		Use:   "list-operations",
-		Short: "List all pattern operations",
+		Use:     "list-operations",
+		GroupID: "pattern",
+		Short:   "List all pattern operations",
 		Long: `List all configured pattern operations with their details.
 Shows operation names, filters, paths, and status.
 
 Examples:
   ena list-operations
   ena list-operations --enabled-only`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			enabledOnly, _ := cmd.Flags().GetBool("enabled-only")
 
 			operations := engine.GetOperations()
+
+			listed := []*patterns.PatternOperation{}
+			for _, operation := range operations {
+				if !enabledOnly || operation.Enabled {
+					listed = append(listed, operation)
+				}
+			}
+			reportResult(listed)
+
 			if len(operations) == 0 {
-				fmt.Println("🌸 No pattern operations configured")
-				return
+				output.Printf("🌸 No pattern operations configured\n")
+				return nil
 			}
 
-			fmt.Println("🌸 Pattern Operations (╹◡╹)♡")
+			output.Printf("🌸 Pattern Operations (╹◡╹)♡\n")
 			fmt.Println("================================")
 
 			for i, operation := range operations {
@@ -156,24 +159,25 @@ Examples:
 					continue
 				}
 
-				status := "❌ Disabled"
+				status := output.Prose("❌ Disabled")
 				if operation.Enabled {
-					status = "✅ Enabled"
+					status = output.Prose("✅ Enabled")
 				}
 
 				fmt.Printf("%d. %s (%s)\n", i+1, operation.Name, status)
-				fmt.Printf("   📝 Description: %s\n", operation.Description)
-				fmt.Printf("   🆔 ID: %s\n", operation.ID)
-				fmt.Printf("   📊 Priority: %d\n", operation.Priority)
-				fmt.Printf("   📁 Paths: %s\n", strings.Join(operation.Paths, ", "))
-				fmt.Printf("   🔍 Filters: %d\n", len(operation.Filters))
-				fmt.Printf("   ⚡ Actions: %d\n", len(operation.Actions))
-				fmt.Printf("   📅 Created: %s\n", operation.CreatedAt.Format("2006-01-02 15:04:05"))
+				output.Printf("   📝 Description: %s\n", operation.Description)
+				output.Printf("   🆔 ID: %s\n", operation.ID)
+				output.Printf("   📊 Priority: %d\n", operation.Priority)
+				output.Printf("   📁 Paths: %s\n", strings.Join(operation.Paths, ", "))
+				output.Printf("   🔍 Filters: %d\n", len(operation.Filters))
+				output.Printf("   ⚡ Actions: %d\n", len(operation.Actions))
+				output.Printf("   📅 Created: %s\n", operation.CreatedAt.Format("2006-01-02 15:04:05"))
 				if operation.LastRun != nil {
-					fmt.Printf("   🏃 Last Run: %s\n", operation.LastRun.Format("2006-01-02 15:04:05"))
+					output.Printf("   🏃 Last Run: %s\n", operation.LastRun.Format("2006-01-02 15:04:05"))
 				}
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
@@ -181,16 +185,20 @@ Examples:
 
 	// Execute operation command
 	executeCmd := &cobra.Command{
-		Use:   "execute-operation <id>",
-		Short: "Execute a specific pattern operation",
+		Use:     "execute-operation <id> [paths...]",
+		GroupID: "pattern",
+		Short:   "Execute a specific pattern operation",
 		Long: `Execute a specific pattern operation by ID.
-Applies all filters and actions defined in the operation.
+Applies all filters and actions defined in the operation, to the paths
+given after the ID instead of the operation's own paths when there are any.
 
 Examples:
   ena execute-operation pattern_1234567890
-  ena execute-operation pattern_1234567890 --dry-run`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+  ena execute-operation pattern_1234567890 --dry-run
+  ena execute-operation pattern_1234567890 notes/a.txt notes/b.txt`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeFirstArg(patternCandidates(engine), cobra.ShellCompDirectiveDefault),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			operationID := args[0]
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 			verbose, _ := cmd.Flags().GetBool("verbose")
@@ -198,24 +206,29 @@ Examples:
 			// Get operation details
 			operation, err := engine.GetOperationByID(operationID)
 			if err != nil {
-				fmt.Printf("❌ Error getting operation: %v\n", err)
-				return
+				return reportError("❌ Error getting operation: %v\n", err)
 			}
 
-			fmt.Printf("🌸 Executing operation: %s\n", operation.Name)
+			output.Printf("🌸 Executing operation: %s\n", operation.Name)
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be modified")
+				output.Printf("🔍 Dry run mode - no files will be modified\n")
 			}
 
-			// Execute the operation
-			result, err := engine.ExecuteOperation(operationID, dryRun)
+			// Execute the operation, on the given paths when there are any
+			if len(args) > 1 {
+				onPaths := *operation
+				onPaths.Paths = args[1:]
+				operation = &onPaths
+			}
+			result, err := engine.Execute(operation, dryRun)
 			if err != nil {
-				fmt.Printf("❌ Error executing operation: %v\n", err)
-				return
+				return reportError("❌ Error executing operation: %v\n", err)
 			}
 
 			// Display results
+			reportResult(result)
 			showPatternResults([]patterns.PatternResult{*result}, verbose, 0)
+			return nil
 		},
 	}
 
@@ -224,32 +237,34 @@ Examples:
 
 	// Execute all operations command
 	executeAllCmd := &cobra.Command{
-		Use:   "execute-all",
-		Short: "Execute all enabled pattern operations",
+		Use:     "execute-all",
+		GroupID: "pattern",
+		Short:   "Execute all enabled pattern operations",
 		Long: `Execute all enabled pattern operations in priority order.
 Each operation will be executed independently.
 
 Examples:
   ena execute-all
   ena execute-all --dry-run`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 			verbose, _ := cmd.Flags().GetBool("verbose")
 
-			fmt.Println("🌸 Executing all enabled pattern operations...")
+			output.Printf("🌸 Executing all enabled pattern operations...\n")
 			if dryRun {
-				fmt.Println("🔍 Dry run mode - no files will be modified")
+				output.Printf("🔍 Dry run mode - no files will be modified\n")
 			}
 
 			// Execute all operations
 			results, err := engine.ExecuteAllOperations(dryRun)
 			if err != nil {
-				fmt.Printf("❌ Error executing operations: %v\n", err)
-				return
+				return reportError("❌ Error executing operations: %v\n", err)
 			}
 
 			// Display results
+			reportResult(results)
 			showPatternResults(results, verbose, 0)
+			return nil
 		},
 	}
 
@@ -258,36 +273,37 @@ Examples:
 
 	// Remove operation command
 	removeCmd := &cobra.Command{
-		Use:   "remove-operation <id>",
-		Short: "Remove a pattern operation",
+		Use:     "remove-operation <id>",
+		GroupID: "pattern",
+		Short:   "Remove a pattern operation",
 		Long: `Remove a pattern operation by ID.
 This will permanently delete the operation and its configuration.
 
 Examples:
   ena remove-operation pattern_1234567890`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(patternCandidates(engine), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			operationID := args[0]
 			confirm, _ := cmd.Flags().GetBool("confirm")
 
 			if !confirm {
-				fmt.Printf("⚠️  This will permanently remove operation %s\n", operationID)
+				output.Printf("⚠️  This will permanently remove operation %s\n", operationID)
 				fmt.Print("Type 'yes' to confirm: ")
 				var response string
 				fmt.Scanln(&response)
 				if strings.ToLower(response) != "yes" {
-					fmt.Println("❌ Operation cancelled")
-					return
+					return reportError("❌ Operation cancelled\n")
 				}
 			}
 
 			err := engine.RemoveOperation(operationID)
 			if err != nil {
-				fmt.Printf("❌ Error removing operation: %v\n", err)
-				return
+				return reportError("❌ Error removing operation: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully removed operation: %s\n", operationID)
+			output.Printf("✅ Successfully removed operation: %s\n", operationID)
+			return nil
 		},
 	}
 
@@ -361,7 +377,7 @@ func createOperationFromPattern(pattern string, paths []string) *patterns.Patter
 
 	// Generic wildcard pattern matching
 	if strings.HasPrefix(pattern, "*.") {
-		ext := strings.TrimPrefix(pattern, "*")
+		ext := strings.TrimPrefix(strings.Fields(pattern)[0], "*")
 		operation.Filters = append(operation.Filters, patterns.FileFilter{
 			Type:     patterns.PatternFileExtension,
 			Operator: patterns.OpEquals,
@@ -435,7 +451,7 @@ func createOperationFromPattern(pattern string, paths []string) *patterns.Patter
 // Helper function to display pattern operation results
 func showPatternResults(results []patterns.PatternResult, verbose bool, limit int) {
 	if len(results) == 0 {
-		fmt.Println("🌸 No pattern operations executed")
+		output.Printf("🌸 No pattern operations executed\n")
 		return
 	}
 
@@ -445,7 +461,7 @@ func showPatternResults(results []patterns.PatternResult, verbose bool, limit in
 	totalFailed := 0
 	totalErrors := 0
 
-	fmt.Println("🌸 Pattern Operation Results (╹◡╹)♡")
+	output.Printf("🌸 Pattern Operation Results (╹◡╹)♡\n")
 	fmt.Println("=====================================")
 
 	for i, result := range results {
@@ -456,20 +472,20 @@ func showPatternResults(results []patterns.PatternResult, verbose bool, limit in
 		totalErrors += len(result.Errors)
 
 		fmt.Printf("Operation %d (%s):\n", i+1, result.OperationID)
-		fmt.Printf("  🔍 Files Matched: %d\n", result.FilesMatched)
-		fmt.Printf("  ✅ Files Processed: %d\n", result.FilesProcessed)
-		fmt.Printf("  ⏭️  Files Skipped: %d\n", result.FilesSkipped)
-		fmt.Printf("  ❌ Files Failed: %d\n", result.FilesFailed)
-		fmt.Printf("  ⏱️  Duration: %s\n", result.Duration.String())
+		output.Printf("  🔍 Files Matched: %d\n", result.FilesMatched)
+		output.Printf("  ✅ Files Processed: %d\n", result.FilesProcessed)
+		output.Printf("  ⏭️  Files Skipped: %d\n", result.FilesSkipped)
+		output.Printf("  ❌ Files Failed: %d\n", result.FilesFailed)
+		output.Printf("  ⏱️  Duration: %s\n", result.Duration.String())
 
 		// Show summary statistics
 		if result.Summary.TotalSize > 0 {
-			fmt.Printf("  📊 Total Size: %s\n", formatBytesPattern(result.Summary.TotalSize))
-			fmt.Printf("  📊 Average Size: %s\n", formatBytesPattern(result.Summary.TotalSize/int64(result.FilesProcessed)))
+			output.Printf("  📊 Total Size: %s\n", formatBytesPattern(result.Summary.TotalSize))
+			output.Printf("  📊 Average Size: %s\n", formatBytesPattern(result.Summary.TotalSize/int64(result.FilesProcessed)))
 		}
 
 		if len(result.Errors) > 0 {
-			fmt.Printf("  ❌ Errors: %d\n", len(result.Errors))
+			output.Printf("  ❌ Errors: %d\n", len(result.Errors))
 			if verbose {
 				for _, err := range result.Errors {
 					fmt.Printf("    - %s\n", err)
@@ -477,8 +493,10 @@ func showPatternResults(results []patterns.PatternResult, verbose bool, limit in
 			}
 		}
 
+		showChanges("  ", result.Changes)
+
 		if verbose && len(result.Details) > 0 {
-			fmt.Println("  📋 Details:")
+			output.Printf("  📋 Details:\n")
 			displayCount := len(result.Details)
 			if limit > 0 && limit < displayCount {
 				displayCount = limit
@@ -486,11 +504,11 @@ func showPatternResults(results []patterns.PatternResult, verbose bool, limit in
 
 			for j := 0; j < displayCount; j++ {
 				detail := result.Details[j]
-				status := "✅"
+				status := output.Prose("✅ ")
 				if !detail.Success {
-					status = "❌"
+					status = output.Prose("❌ ")
 				}
-				fmt.Printf("    %s %s\n", status, filepath.Base(detail.FilePath))
+				fmt.Printf("    %s%s\n", status, filepath.Base(detail.FilePath))
 				if detail.Action != "" {
 					fmt.Printf("      Action: %s\n", detail.Action)
 				}
@@ -512,13 +530,13 @@ func showPatternResults(results []patterns.PatternResult, verbose bool, limit in
 		fmt.Println()
 	}
 
-	fmt.Println("📊 Summary:")
-	fmt.Printf("  🔍 Total Files Matched: %d\n", totalFiles)
-	fmt.Printf("  ✅ Total Files Processed: %d\n", totalProcessed)
-	fmt.Printf("  ⏭️  Total Files Skipped: %d\n", totalSkipped)
-	fmt.Printf("  ❌ Total Files Failed: %d\n", totalFailed)
+	output.Printf("📊 Summary:\n")
+	output.Printf("  🔍 Total Files Matched: %d\n", totalFiles)
+	output.Printf("  ✅ Total Files Processed: %d\n", totalProcessed)
+	output.Printf("  ⏭️  Total Files Skipped: %d\n", totalSkipped)
+	output.Printf("  ❌ Total Files Failed: %d\n", totalFailed)
 	if totalErrors > 0 {
-		fmt.Printf("  ❌ Total Errors: %d\n", totalErrors)
+		output.Printf("  ❌ Total Errors: %d\n", totalErrors)
 	}
 }
 
@@ -21,8 +21,9 @@ import (
 func setupPauseCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Pause command
 	var pauseCmd = &cobra.Command{
-		Use:   "pause <operation>",
-		Short: "Pause/resume progress bars and test terminal compatibility",
+		Use:     "pause <operation>",
+		GroupID: "pause",
+		Short:   "Pause/resume progress bars and test terminal compatibility",
 		Long: `Control progress bar pause/resume functionality and test terminal capabilities:
   • Demo pause/resume functionality with visual feedback
   • Test terminal compatibility (colors, cursor control, etc.)
@@ -36,15 +37,16 @@ Operations:
 Example:
   ena pause demo
   ena pause test`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		ValidArgs: []string{"demo", "test", "state", "adaptive", "theme", "events", "http"},
+		Args:      cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("pause", args)
 			if err != nil {
-				cmd.PrintErrln("❌ Error:", err)
-				return
+				return reportError("❌ Error: %v\n", err)
 			}
 
 			cmd.Println(result)
+			return nil
 		},
 	}
 
@@ -0,0 +1,315 @@
+/**
+ * Interactive Pipelines
+ *
+ * Runs REPL lines containing |, &&, || and ; inside Ena. Commands joined by |
+ * exchange the paths in their typed results rather than text, and $last or $_
+ * refer to the result of the previous command.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: pipeline.go
+ * Description: Pipeline execution and result references for interactive mode
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"ena/internal/backup"
+	"ena/internal/batch"
+	"ena/internal/core"
+	"ena/internal/diff"
+	"ena/internal/diskusage"
+	"ena/internal/dupes"
+	"ena/internal/input"
+	"ena/internal/organizer"
+	"ena/internal/output"
+	"ena/internal/patterns"
+	"ena/internal/search"
+)
+
+// lastResult holds the values of the previous interactive command's result
+var lastResult []string
+
+// resultRefs are the variable names that refer to lastResult
+var resultRefs = map[string]bool{"last": true, "_": true}
+
+var (
+	// wholeResultRef matches a word that is nothing but $last, $_ or their ${} forms
+	wholeResultRef = regexp.MustCompile(`^\$(\{(last|_)\}|last|_)$`)
+	// anyResultRef matches a result reference anywhere in a word
+	anyResultRef = regexp.MustCompile(`\$(\{(last|_)\}|(last|_)\b)`)
+)
+
+// pipesHelp documents pipelines and result references
+const pipesHelp = `Interactive mode runs several commands on one line, like a shell, without
+leaving Ena:
+
+  a | b      run b with the paths from a's result as its first arguments
+  a && b     run b only if a succeeded
+  a || b     run b only if a failed
+  a ; b      run b after a either way
+
+A pipeline fails as soon as one of its commands fails, and b is skipped when
+a produced no paths. Quote the operators to use them literally.
+
+$last and $_ expand to the result of the previous command: as separate
+arguments when the word is just $last, joined by spaces inside a larger word.
+A command in a pipeline that mentions $last gets no extra arguments.
+
+Results provide these values:
+  find, execute-operation, execute-all   matched or resulting file paths
+  search --content                       paths of files with matches
+  dupes                                  paths of the extra copies, not the one --keep keeps
+  du                                     paths of the entries shown, biggest first
+  diff (directories)                     added and modified paths in the new tree
+  do                                     matched or resulting file paths
+  organize                               organized file paths
+  batch-delete, batch-copy, batch-move   processed paths (destinations for copy and move)
+  batch-status, daemon jobs              job IDs
+  create-backup, list-backups            backup file paths
+  watched-paths, file-extensions         the listed strings
+  anything else                          nothing
+
+Examples:
+  find "*.log older than 7d" ~/tmp | batch-delete --dry-run
+  find "*.jpg" ~/Downloads | batch-move ~/Pictures
+  search --content "TODO" ~/notes | batch-copy ~/todo
+  create-backup notes.txt && file write notes.txt "fresh start"
+  find "files > 100MB" ~ ; notify send info "Big files" "$last"`
+
+// addPipesTopic registers the help topic describing pipelines
+func addPipesTopic(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "pipes",
+		Short: "Pipelines, chaining and $last in interactive mode",
+		Long:  pipesHelp,
+	})
+}
+
+// newREPLLexer creates the lexer for interactive lines, which knows $last and $_
+func newREPLLexer() *input.Lexer {
+	lexer := input.NewLexer()
+	lexer.Operators = true
+	lexer.Lookup = func(name string) (string, bool) {
+		if resultRefs[name] {
+			return strings.Join(lastResult, " "), true
+		}
+		return os.LookupEnv(name)
+	}
+	return lexer
+}
+
+// runPipelines runs a line's pipelines with shell list semantics
+func runPipelines(assistant *core.Assistant, pipelines []input.Pipeline) bool {
+	ok := true
+	for _, pipeline := range pipelines {
+		if pipeline.ShouldRun(ok) {
+			ok = runPipeline(assistant, pipeline)
+		}
+	}
+	return ok
+}
+
+// runPipeline runs commands joined by |, passing each result's paths to the next
+func runPipeline(assistant *core.Assistant, pipeline input.Pipeline) bool {
+	var piped []string
+
+	for i, stage := range pipeline.Stages {
+		args, usesLast, err := expandStage(stage)
+		if err != nil {
+			color.New(color.FgRed).Fprintf(os.Stderr, output.Prose("❌ Error: %v\n"), err)
+			return false
+		}
+
+		// A command that is not one may be a request in plain English
+		if i == 0 || usesLast {
+			args = asRequest(assistant, args)
+		}
+
+		if i > 0 && !usesLast {
+			if len(piped) == 0 {
+				output.Printf("🌸 Nothing to pass to %s\n", args[0])
+				return true
+			}
+			args = withPipedArgs(assistant, args, piped)
+		}
+
+		start := time.Now()
+		code, data := executeLineResult(assistant, args)
+		recordCommand(stage, args, start, code, data)
+		lastResult = resultValues(data)
+		if code != 0 {
+			return false
+		}
+		piped = lastResult
+	}
+
+	return true
+}
+
+// expandStage lexes one command just before it runs, so $last is current.
+// It also reports whether the command refers to the previous result.
+func expandStage(stage string) ([]string, bool, error) {
+	tokens, err := newREPLLexer().Lex(stage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var args []string
+	usesLast := false
+	for _, token := range tokens {
+		raw := stage[token.Start:token.End]
+		if wholeResultRef.MatchString(raw) {
+			args = append(args, lastResult...)
+			usesLast = true
+			continue
+		}
+		if anyResultRef.MatchString(raw) {
+			usesLast = true
+		}
+		args = append(args, token.Value)
+	}
+
+	if len(args) == 0 {
+		return nil, usesLast, fmt.Errorf("%s expands to nothing", stage)
+	}
+	return args, usesLast, nil
+}
+
+// asRequest turns words that start with a request verb rather than a command
+// into an "ena do" command line
+func asRequest(assistant *core.Assistant, args []string) []string {
+	if !intentGrammar(assistant.App.Config).IsVerb(args[0]) {
+		return args
+	}
+	rootCmd := SetupRootCommand(assistant.App, assistant)
+	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
+		return args
+	}
+	return append([]string{"do"}, args...)
+}
+
+// withPipedArgs inserts piped values right after the command path
+func withPipedArgs(assistant *core.Assistant, args []string, piped []string) []string {
+	cmd, rest, err := SetupRootCommand(assistant.App, assistant).Find(args)
+	if err != nil || !cmd.HasParent() {
+		return append(append([]string{}, args...), piped...)
+	}
+
+	commandPath := strings.Fields(cmd.CommandPath())[1:]
+	return append(append(commandPath, piped...), rest...)
+}
+
+// resultValues extracts the values a result passes down a pipeline
+func resultValues(data interface{}) []string {
+	var values []string
+
+	switch result := data.(type) {
+	case *patterns.PatternResult:
+		values = patternPaths(result.Details)
+	case []patterns.PatternResult:
+		for _, r := range result {
+			values = append(values, patternPaths(r.Details)...)
+		}
+	case intentResult:
+		if result.Pattern != nil {
+			values = patternPaths(result.Pattern.Details)
+		} else if result.Job != nil {
+			values = resultValues(result.Job)
+		} else {
+			values = append(values, result.Plan.Files...)
+		}
+	case []organizer.OrganizationResult:
+		for _, r := range result {
+			for _, detail := range r.Details {
+				if detail.Error != "" {
+					continue
+				}
+				if detail.Destination != "" {
+					values = append(values, detail.Destination)
+				} else {
+					values = append(values, detail.FilePath)
+				}
+			}
+		}
+	case *batch.BatchJob:
+		for _, operation := range result.Operations {
+			if operation.Status == "failed" {
+				continue
+			}
+			if operation.Destination != "" {
+				values = append(values, operation.Destination)
+			} else {
+				values = append(values, operation.Source)
+			}
+		}
+	case []*batch.BatchJob:
+		for _, job := range result {
+			values = append(values, job.ID)
+		}
+	case *backup.BackupMetadata:
+		values = append(values, result.BackupPath)
+	case []backup.BackupMetadata:
+		for _, metadata := range result {
+			values = append(values, metadata.BackupPath)
+		}
+	case *search.Result:
+		values = result.Paths()
+	case *dupes.Report:
+		// Only the extra copies, so piping to a delete leaves one of each group
+		for _, group := range result.Groups {
+			for _, file := range group.Extras(result.Keep) {
+				values = append(values, file.Path)
+			}
+		}
+	case *diskusage.Report:
+		values = diskUsagePaths(result.Root)
+	case *diff.DirDiff:
+		for _, entry := range result.Entries {
+			if entry.Change != diff.ChangeRemoved {
+				values = append(values, filepath.Join(result.New, entry.Path))
+			}
+		}
+	case []string:
+		values = append(values, result...)
+	}
+
+	return values
+}
+
+// diskUsagePaths lists the entries beneath a cut-down scan, each directory
+// followed by its own entries
+func diskUsagePaths(node *diskusage.Node) []string {
+	var values []string
+	for _, child := range node.Children {
+		values = append(values, child.Path)
+		values = append(values, diskUsagePaths(child)...)
+	}
+	return values
+}
+
+// patternPaths lists where the files of a pattern result are now
+func patternPaths(details []patterns.FileOperationDetail) []string {
+	var values []string
+	for _, detail := range details {
+		if detail.Error != "" {
+			continue
+		}
+		if detail.Destination != "" {
+			values = append(values, detail.Destination)
+		} else {
+			values = append(values, detail.FilePath)
+		}
+	}
+	return values
+}
@@ -0,0 +1,252 @@
+/**
+ * Plugin Commands
+ *
+ * Registers external plugins as subcommands so they appear in help and tab
+ * completion like built-in commands, and provides a listing of what was found.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: plugin_commands.go
+ * Description: External plugin registration and listing command definitions
+ */
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"ena/internal/core"
+	"ena/internal/daemon"
+	"ena/internal/output"
+	"ena/internal/paths"
+	"ena/internal/plugin"
+)
+
+// Plugins are discovered once per process since a tree is built for every line
+var (
+	pluginsOnce       sync.Once
+	discoveredPlugins []*plugin.Plugin
+	pluginErrors      []error
+)
+
+// reservedNames are commands cobra adds at execution time
+var reservedNames = []string{"help", "completion"}
+
+// pluginEntry is a discovered plugin as shown by the plugins command
+type pluginEntry struct {
+	*plugin.Plugin
+	Hidden bool `json:"hidden"` // a built-in command has the same name
+}
+
+// loadPlugins discovers plugins the first time it is called
+func loadPlugins() ([]*plugin.Plugin, []error) {
+	pluginsOnce.Do(func() {
+		discoveredPlugins, pluginErrors = plugin.Discover()
+	})
+	return discoveredPlugins, pluginErrors
+}
+
+// setupPluginCommands registers discovered plugins; call it after every built-in
+func setupPluginCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
+	taken := make(map[string]bool)
+	for _, name := range reservedNames {
+		taken[name] = true
+	}
+	for _, cmd := range rootCmd.Commands() {
+		taken[cmd.Name()] = true
+		for _, alias := range cmd.Aliases {
+			taken[alias] = true
+		}
+	}
+
+	plugins, errs := loadPlugins()
+	entries := make([]pluginEntry, 0, len(plugins))
+
+	for _, p := range plugins {
+		if taken[p.Name] {
+			entries = append(entries, pluginEntry{Plugin: p, Hidden: true})
+			continue
+		}
+		entries = append(entries, pluginEntry{Plugin: p})
+		rootCmd.AddCommand(newPluginCommand(p, taken, assistant))
+	}
+
+	// Plugins listing command
+	pluginsCmd := &cobra.Command{
+		Use:     "plugins",
+		GroupID: "plugin",
+		Short:   "List external plugin commands",
+		Long: fmt.Sprintf(`Plugins add commands to Ena without changing it. Ena finds them in two places:
+
+  • executables named ena-<name> on your PATH, like git subcommands
+  • directories in %s containing a plugin.json manifest:
+
+      {
+        "name": "deploy",
+        "description": "Deploy the current project",
+        "usage": "deploy <env>",
+        "executable": "deploy.sh",
+        "aliases": ["ship"],
+        "args": ["staging", "production"]
+      }
+
+A manifest plugin wins over a PATH executable of the same name, and built-in
+commands win over both. Plugins get their arguments as given and a JSON
+context on stdin with the fields version, plugin, args, cwd, theme, color,
+output, ena, ena_version, socket, config_dir, data_dir and state_dir.
+
+Ena consumes --output (-o) before a "--"; pass it after "--" to hand it to the
+plugin. With --output json, yaml or table a plugin may print one JSON value,
+which becomes the data of the result.`, plugin.Dir()),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reportResult(entries)
+
+			for _, err := range errs {
+				output.Printf("⚠️ Warning: %v\n", err)
+			}
+
+			if len(entries) == 0 {
+				output.Printf("🧩 No plugins found. Add ena-<name> executables to your PATH\n")
+				fmt.Printf("   or manifest plugins to %s\n", plugin.Dir())
+				return nil
+			}
+
+			output.Printf("🧩 Plugins (%d)\n", len(entries))
+			fmt.Println("========================")
+			for _, entry := range entries {
+				output.Printf("🔌 %s - %s\n", entry.UsageLine(), entry.Summary())
+				output.Printf("   📁 %s (%s)\n", entry.Path, entry.Source)
+				if entry.Hidden {
+					output.Printf("   ⚠️ Hidden by the built-in %s command\n", entry.Name)
+				}
+			}
+			return nil
+		},
+	}
+
+	rootCmd.AddCommand(pluginsCmd)
+}
+
+// newPluginCommand wraps a plugin in a command that passes every argument through
+func newPluginCommand(p *plugin.Plugin, taken map[string]bool, assistant *core.Assistant) *cobra.Command {
+	var aliases []string
+	for _, alias := range p.Aliases {
+		if !taken[alias] && plugin.ValidName(alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return &cobra.Command{
+		Use:                p.UsageLine(),
+		Aliases:            aliases,
+		GroupID:            "plugin",
+		Short:              p.Summary(),
+		Long:               fmt.Sprintf("%s\n\nPlugin: %s (%s)\nRun 'ena help plugins' for how plugins are called.", p.Summary(), p.Path, p.Source),
+		ValidArgs:          p.Args,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(p, args, assistant)
+		},
+	}
+}
+
+// runPlugin executes a plugin, turning its JSON output into the result in structured modes
+func runPlugin(p *plugin.Plugin, args []string, assistant *core.Assistant) error {
+	// Flag parsing is off for plugins, so --output has to be picked out by hand
+	formatName, pluginArgs, err := splitOutputFlag(args)
+	if err != nil {
+		return reportError("❌ Error: %v\n", err)
+	}
+
+	format := output.FormatText
+	if formatName != "" {
+		if format, err = output.ParseFormat(formatName); err != nil {
+			return reportError("❌ Error: %v\n", err)
+		}
+	}
+
+	if format.Structured() {
+		currentOutput = &outputSession{format: format}
+		if err := currentOutput.startCapture(); err != nil {
+			return reportError("❌ Error: %v\n", err)
+		}
+	}
+
+	cwd, _ := os.Getwd()
+	executable, _ := os.Executable()
+	ctx := plugin.Context{
+		Cwd:        cwd,
+		Theme:      assistant.SystemHooks.ThemeManager.GetCurrentTheme(),
+		Color:      !color.NoColor,
+		Output:     string(format),
+		Ena:        executable,
+		EnaVersion: assistant.Version,
+		Socket:     daemon.SocketPath(),
+		ConfigDir:  paths.ConfigDir(),
+		DataDir:    paths.DataDir(),
+		StateDir:   paths.StateDir(),
+	}
+
+	// In structured modes the plugin's output is the result, not prose
+	var captured bytes.Buffer
+	var stdout io.Writer = os.Stdout
+	if format.Structured() {
+		stdout = &captured
+	}
+
+	code, err := p.Run(pluginArgs, ctx, stdout)
+	if err != nil {
+		return reportError("❌ Error: %v\n", err)
+	}
+
+	if format.Structured() {
+		if text := bytes.TrimSpace(captured.Bytes()); json.Valid(text) {
+			reportResult(json.RawMessage(text))
+		} else {
+			os.Stdout.Write(captured.Bytes())
+		}
+	}
+
+	if code != 0 {
+		return reportError("❌ Plugin %s exited with code %d\n", p.Name, code)
+	}
+	return nil
+}
+
+// splitOutputFlag removes Ena's --output flag from plugin arguments before any "--"
+func splitOutputFlag(args []string) (string, []string, error) {
+	var format string
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return format, append(rest, args[i+1:]...), nil
+		case arg == "-o" || arg == "--output":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--output="):
+			format = strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "-o="):
+			format = strings.TrimPrefix(arg, "-o=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	return format, rest, nil
+}
@@ -0,0 +1,210 @@
+/**
+ * Policy Commands
+ *
+ * Provides commands for inspecting and creating the safety policy, the
+ * global --override-policy flag, and the prompt shown when a rule asks for
+ * confirmation.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: policy_commands.go
+ * Description: Safety policy command definitions and confirmation prompt
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/app"
+	"ena/internal/input"
+	"ena/internal/output"
+	"ena/internal/policy"
+)
+
+// policyLocation is where the policy file lives
+type policyLocation struct {
+	Policy string `json:"policy"`
+}
+
+// addPolicyFlag registers --override-policy and, unless something else already
+// answers confirmations, has the engine ask on stdin
+func addPolicyFlag(rootCmd *cobra.Command, services *app.App) {
+	rootCmd.PersistentFlags().Bool("override-policy", false, "Ignore the safety policy for this command (each override is audited)")
+	if services.Policy.Confirm == nil {
+		services.Policy.Confirm = confirmPolicy
+	}
+
+	begin := rootCmd.PersistentPreRunE
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Every command sets the override afresh so it never outlives the command
+		override, _ := cmd.Flags().GetBool("override-policy")
+		services.Policy.SetOverride(override)
+		return begin(cmd, args)
+	}
+}
+
+// confirmPolicy asks whether an operation a confirm rule matched may go ahead
+func confirmPolicy(decision policy.Decision) bool {
+	// Structured output has no one to answer a prompt
+	if structured() {
+		return false
+	}
+
+	output.Printf("⚠️  Policy rule \"%s\" asks before you %s %s. Continue? (y/N): ",
+		decision.Rule, decision.Operation, decision.Path)
+	// Without an answer the operation is refused, as with no
+	response, _ := input.Answer()
+	return response == "y" || response == "yes"
+}
+
+// setupPolicyCommands sets up safety policy commands
+func setupPolicyCommands(rootCmd *cobra.Command, services *app.App) {
+	// Policy parent command
+	policyCmd := &cobra.Command{
+		Use:     "policy",
+		GroupID: "policy",
+		Short:   "Show and test the safety policy",
+		Long: fmt.Sprintf(`Before file, folder, batch, pattern, organizer and undo commands delete, move
+or overwrite anything, Ena checks the rules in %s.
+The first rule that matches allows the operation, asks you to confirm it, or
+denies it. Without a policy file, SSH and GnuPG keys can never be deleted,
+moved or overwritten, and git repository roots can never be deleted.
+
+Add --override-policy to any command to ignore the rules for that command
+only; overrides are written to the audit log, as are refusals.
+
+Examples:
+  ena policy init                          # Write the built-in rules to edit
+  ena policy show                          # Show the rules in effect
+  ena policy check delete ~/.ssh/id_rsa    # See what a rule would decide
+  ena batch-delete ~/old-repo --override-policy`, policy.Path()),
+	}
+
+	// Path command
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Show where the policy file lives",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reportResult(policyLocation{Policy: policy.Path()})
+			output.Printf("🛡️  Policy: %s\n", policy.Path())
+			return nil
+		},
+	}
+
+	// Show command
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the rules in effect",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules := services.Policy.Policy()
+			reportResult(rules)
+
+			if len(rules.Rules) == 0 {
+				output.Printf("🛡️  No policy rules - every operation is allowed\n")
+				return nil
+			}
+
+			output.Printf("🛡️  Policy rules (%d), first match wins:\n", len(rules.Rules))
+			for i, rule := range rules.Rules {
+				fmt.Printf("%d. %s%s\n", i+1, effectIcon(rule.Effect), rule.Name)
+				showRuleCondition("operations", strings.Join(rule.Operations, ", "))
+				showRuleCondition("paths", strings.Join(rule.Paths, ", "))
+				showRuleCondition("sources", strings.Join(rule.Sources, ", "))
+				showRuleCondition("contains", strings.Join(rule.Contains, ", "))
+				if rule.MinSize > 0 {
+					showRuleCondition("min size", formatBytes(rule.MinSize))
+				}
+				if rule.MinFiles > 0 {
+					showRuleCondition("min files", fmt.Sprintf("%d", rule.MinFiles))
+				}
+			}
+			return nil
+		},
+	}
+
+	// Init command
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a policy file containing the built-in rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			path := policy.Path()
+
+			if _, err := os.Stat(path); err == nil && !force {
+				return reportError("❌ %s already exists (use --force to overwrite)\n", path)
+			}
+
+			if err := policy.Save(policy.Default(), path); err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(policyLocation{Policy: path})
+			output.Printf("✅ Wrote the built-in policy to %s\n", path)
+			return nil
+		},
+	}
+	initCmd.Flags().Bool("force", false, "Overwrite an existing policy file")
+
+	// Check command
+	checkCmd := &cobra.Command{
+		Use:       "check <delete|move|overwrite> <path> [target]",
+		Short:     "Show what the policy decides for an operation",
+		Args:      cobra.RangeArgs(2, 3),
+		ValidArgs: []string{policy.OpDelete, policy.OpMove, policy.OpOverwrite},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, _ := cmd.Flags().GetString("source")
+
+			request := policy.Request{Source: source, Operation: args[0], Path: args[1]}
+			if len(args) > 2 {
+				request.Target = args[2]
+			}
+			switch request.Operation {
+			case policy.OpDelete, policy.OpMove, policy.OpOverwrite:
+			default:
+				return reportErrorCode(exitUsage, "❌ Unknown operation %q - use delete, move or overwrite\n", request.Operation)
+			}
+
+			decision := services.Policy.Evaluate(request)
+			reportResult(decision)
+
+			if decision.Rule == "" {
+				fmt.Printf("%sNo rule matches - %s of %s is allowed\n", effectIcon(decision.Effect), decision.Operation, decision.Path)
+				return nil
+			}
+			fmt.Printf("%sRule \"%s\" decides %s for %s of %s\n",
+				effectIcon(decision.Effect), decision.Rule, decision.Effect, decision.Operation, decision.Path)
+			return nil
+		},
+	}
+	checkCmd.Flags().String("source", "file", "Component making the operation: file, batch, pattern, organizer or undo")
+
+	policyCmd.AddCommand(pathCmd, showCmd, initCmd, checkCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+// showRuleCondition prints one condition of a rule when it is set
+func showRuleCondition(name, value string) {
+	if value != "" {
+		fmt.Printf("   %-12s %s\n", name+":", value)
+	}
+}
+
+// effectIcon marks an effect in listings, followed by a space
+func effectIcon(effect policy.Effect) string {
+	switch effect {
+	case policy.EffectDeny:
+		return output.Prose("⛔ ")
+	case policy.EffectConfirm:
+		return output.Prose("⚠️  ")
+	default:
+		return output.Prose("✅ ")
+	}
+}
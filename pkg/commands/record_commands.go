@@ -0,0 +1,316 @@
+/**
+ * Record Commands
+ *
+ * Provides commands for recording the commands typed at the interactive prompt
+ * into a session file and replaying it elsewhere, with dry-run, step-through
+ * and path rebasing.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: record_commands.go
+ * Description: Session recording and replay command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/core"
+	"ena/internal/input"
+	"ena/internal/output"
+	"ena/internal/recording"
+	"ena/internal/script"
+	"ena/internal/suggestions"
+)
+
+// recordCommand adds a command run at the prompt to the active recording.
+// Recording and replay commands are left out so a replay cannot replay itself.
+func recordCommand(line string, args []string, start time.Time, code int, data interface{}) {
+	if _, active := recording.Active(); !active || args[0] == "record" || args[0] == "replay" {
+		return
+	}
+
+	workingDir, _ := os.Getwd()
+	entry := recording.Entry{
+		CommandUsage: suggestions.CommandUsage{
+			Command:    args[0],
+			Args:       args[1:],
+			Timestamp:  start,
+			Duration:   time.Since(start),
+			Success:    code == 0,
+			WorkingDir: workingDir,
+		},
+		Line:     line,
+		ExitCode: code,
+		Result:   data,
+	}
+	if err := recording.Record(entry); err != nil {
+		output.Printf("⚠️ Warning: Failed to record %s: %v\n", args[0], err)
+	}
+}
+
+// setupRecordCommands sets up session recording and replay commands
+func setupRecordCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
+	// Record parent command
+	recordCmd := &cobra.Command{
+		Use:     "record",
+		GroupID: "record",
+		Short:   "Record the commands you type into a session file",
+		Long: fmt.Sprintf(`While recording, every command typed at the interactive prompt is saved with
+its arguments, timing, result and exit status. Arguments are saved after
+expansion, so variables, $last and piped paths replay exactly. Sessions go
+to %s unless you name a file.
+
+A recording started from the shell is picked up by the next "ena" prompt,
+and keeps going until "ena record stop".
+
+Examples:
+  ena record start
+  ena record start tidy-session.json
+  ena record status
+  ena record stop
+  ena replay tidy-session.json --dry-run`, recording.Dir()),
+	}
+
+	// Start command
+	startCmd := &cobra.Command{
+		Use:   "start [file]",
+		Short: "Start recording into a new session file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := recording.DefaultPath()
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			if _, err := recording.Start(path); err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			active, _ := recording.Active()
+			reportResult(map[string]interface{}{"recording": true, "file": active})
+			output.Printf("⏺️  Recording to %s\n", active)
+			output.Printf("💡 Stop with: ena record stop\n")
+			return nil
+		},
+	}
+
+	// Stop command
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop recording and save the session",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session, path, err := recording.Stop()
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(map[string]interface{}{"recording": false, "file": path, "commands": len(session.Entries)})
+			output.Printf("⏹️  Recorded %d commands to %s\n", len(session.Entries), path)
+			output.Printf("🔁 Replay with: ena replay %s\n", path)
+			return nil
+		},
+	}
+
+	// Status command
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether a recording is running",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, active := recording.Active()
+			if !active {
+				reportResult(map[string]interface{}{"recording": false})
+				output.Printf("🌸 Not recording\n")
+				return nil
+			}
+
+			session, err := recording.Load(path)
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(map[string]interface{}{"recording": true, "file": path, "commands": len(session.Entries)})
+			output.Printf("⏺️  Recording to %s\n", path)
+			output.Printf("📊 %d commands since %s\n", len(session.Entries), session.StartedAt.Format("2006-01-02 15:04:05"))
+			return nil
+		},
+	}
+
+	recordCmd.AddCommand(startCmd, stopCmd, statusCmd)
+	rootCmd.AddCommand(recordCmd)
+
+	// Replay command
+	replayCmd := &cobra.Command{
+		Use:     "replay <file>",
+		GroupID: "record",
+		Short:   "Run a recorded session again",
+		Long: `Run the commands of a recorded session in order. Paths under the directory
+the recording started in, and under the recording user's home, are rewritten
+to the current directory and home; add more mappings with --rebase old=new.
+
+Replay stops at the first command that fails where the recording succeeded,
+unless --keep-going is given. Commands that failed in the recording too are
+run and reported but do not stop it. A real replay is one undo session, so
+"ena undo-session <id>" reverts all of it.
+
+Examples:
+  ena replay ~/tidy-session.json --dry-run
+  ena replay tidy-session.json --step
+  ena replay tidy-session.json --rebase /mnt/old-disk=/mnt/new-disk`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			step, _ := cmd.Flags().GetBool("step")
+			keepGoing, _ := cmd.Flags().GetBool("keep-going")
+			noRebase, _ := cmd.Flags().GetBool("no-rebase")
+			rebaseSpecs, _ := cmd.Flags().GetStringArray("rebase")
+
+			session, err := recording.Load(args[0])
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+			if len(session.Entries) == 0 {
+				return reportError("❌ Session %s has no commands\n", args[0])
+			}
+
+			var rebases []recording.Rebase
+			for _, spec := range rebaseSpecs {
+				rebase, err := recording.ParseRebase(spec)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				rebases = append(rebases, rebase)
+			}
+			if !noRebase {
+				rebases = append(rebases, session.DefaultRebases()...)
+			}
+
+			if step && structured() {
+				return reportError("❌ --step needs someone to answer; use it without --output\n")
+			}
+
+			replayer := &recording.Replayer{
+				Executor:  scriptExecutor{assistant: assistant},
+				DryRun:    dryRun,
+				KeepGoing: keepGoing,
+				Rebases:   rebases,
+				BeforeStep: func(index int, entry recording.Entry, command string) {
+					output.Printf("▶️  %d/%d: %s\n", index, len(session.Entries), command)
+				},
+				AfterStep: func(result recording.Step) {
+					switch {
+					case result.Status == script.StatusSkipped:
+						output.Printf("⏭️  %d skipped\n", result.Index)
+					case result.Status == script.StatusPlanned:
+						output.Printf("📝 %d would run (no dry-run mode)\n", result.Index)
+					case result.ExitCode != 0 && result.RecordedExitCode == 0:
+						output.Printf("❌ %d failed with exit code %d; it succeeded when recorded\n", result.Index, result.ExitCode)
+					case result.ExitCode != 0:
+						output.Printf("⚠️  %d failed with exit code %d, as it did when recorded\n", result.Index, result.ExitCode)
+					}
+				},
+			}
+			if step {
+				replayer.Confirm = stepConfirm()
+			}
+
+			for _, rebase := range rebases {
+				output.Printf("🔀 %s → %s\n", rebase.From, rebase.To)
+			}
+			result := replaySession(assistant, replayer, session, args[0])
+			reportResult(result)
+
+			printReplaySummary(result)
+			if !result.OK {
+				return reportError("❌ Replay of %s diverged from the recording\n", args[0])
+			}
+			return nil
+		},
+	}
+	replayCmd.Flags().Bool("dry-run", false, "Show what would run, previewing commands that support --dry-run")
+	replayCmd.Flags().Bool("step", false, "Ask before each command")
+	replayCmd.Flags().Bool("keep-going", false, "Carry on after a command fails that succeeded when recorded")
+	replayCmd.Flags().StringArray("rebase", []string{}, "Rewrite paths under old to new, as old=new; repeat for more")
+	replayCmd.Flags().Bool("no-rebase", false, "Keep the recorded working and home directories in paths")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+// stepConfirm asks before each replayed command
+func stepConfirm() func(index int, command string) (bool, bool) {
+	return func(index int, command string) (bool, bool) {
+		output.Printf("❓ %d: %s\n   Run it? (Y/n/q): ", index, command)
+		response, err := input.Answer()
+		if err != nil {
+			// Stop rather than run the rest unasked
+			reportError("❌ %v\n", err)
+			return false, true
+		}
+		switch response {
+		case "n", "no":
+			return false, false
+		case "q", "quit":
+			return false, true
+		default:
+			return true, false
+		}
+	}
+}
+
+// replaySession replays a session, wrapping a real replay in a single undo session
+func replaySession(assistant *core.Assistant, replayer *recording.Replayer, session *recording.Session, path string) *recording.Result {
+	if replayer.DryRun {
+		output.Printf("🔍 Dry run of %s - nothing will be changed\n", path)
+		return replayer.Replay(session, path)
+	}
+
+	// Run commands locally so every change is tracked by this process's undo session
+	forwarder := assistant.Forwarder
+	assistant.Forwarder = nil
+	defer func() { assistant.Forwarder = forwarder }()
+
+	undoManager := assistant.SystemHooks.UndoManager
+	undoSession, nested, end := undoManager.Begin("replay "+path, fmt.Sprintf("Replay of %d recorded commands", len(session.Entries)))
+	undoSession.Metadata["replay"] = path
+
+	output.Printf("🔁 Replaying %d commands from %s\n", len(session.Entries), path)
+	result := replayer.Replay(session, path)
+
+	if err := end(); err != nil {
+		output.Printf("⚠️ Warning: Failed to save undo session: %v\n", err)
+	} else if !nested && len(undoSession.Operations) > 0 {
+		result.UndoSessionID = undoSession.ID
+	}
+
+	return result
+}
+
+// printReplaySummary prints the outcome of a replay
+func printReplaySummary(result *recording.Result) {
+	counts := make(map[script.StepStatus]int)
+	for _, step := range result.Steps {
+		counts[step.Status]++
+	}
+
+	fmt.Println()
+	if result.DryRun {
+		output.Printf("🔍 Dry run: %d previewed, %d planned, %d skipped, %d failed\n",
+			counts[script.StatusPreviewed], counts[script.StatusPlanned], counts[script.StatusSkipped], counts[script.StatusFailed])
+		return
+	}
+
+	if result.OK {
+		output.Printf("✅ Replay finished in %v\n", result.Duration.Round(time.Millisecond))
+	}
+	output.Printf("📊 %d ok, %d failed, %d skipped\n", counts[script.StatusOK], counts[script.StatusFailed], counts[script.StatusSkipped])
+	if result.UndoSessionID != "" {
+		output.Printf("↩️  Undo everything with: ena undo-session %s\n", result.UndoSessionID)
+	}
+}
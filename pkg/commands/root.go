@@ -14,13 +14,18 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"ena/internal/app"
+	"ena/internal/audit"
 	"ena/internal/core"
 	"ena/internal/input"
+	"ena/internal/output"
 )
 
 // HelpEntry represents a single help entry
@@ -30,124 +35,122 @@ type HelpEntry struct {
 	Desc     string
 }
 
-// GetHelpEntries returns all available help entries
-func GetHelpEntries() []HelpEntry {
-	// Centralized help entries - easy to maintain
-	return []HelpEntry{
-		{"📁 File Operations", "file create <path>", "Create a file"},
-		{"📁 File Operations", "file read <path>", "Read a file"},
-		{"📁 File Operations", "file write <path> <content>", "Write to a file"},
-		{"📁 File Operations", "file copy <src> <dest>", "Copy a file"},
-		{"📁 File Operations", "file move <src> <dest>", "Move a file"},
-		{"📁 File Operations", "file delete <path> [--force]", "Delete a file"},
-		{"📁 File Operations", "file info <path>", "Show file information"},
-		{"📂 Folder Operations", "folder create <path>", "Create a folder"},
-		{"📂 Folder Operations", "folder list <path>", "List folder contents"},
-		{"📂 Folder Operations", "folder delete <path>", "Delete a folder"},
-		{"📂 Folder Operations", "folder info <path>", "Show folder information"},
-		{"🖥️  Terminal Operations", "terminal open", "Open a new terminal"},
-		{"🖥️  Terminal Operations", "terminal close", "Close terminal"},
-		{"🖥️  Terminal Operations", "terminal execute <command>", "Execute a command"},
-		{"🖥️  Terminal Operations", "terminal cd <directory>", "Change directory"},
-		{"📱 Application Operations", "app start <app_name>", "Start an application"},
-		{"📱 Application Operations", "app stop <app_name>", "Stop an application"},
-		{"📱 Application Operations", "app restart <app_name>", "Restart an application"},
-		{"📱 Application Operations", "app list", "List running applications"},
-		{"📱 Application Operations", "app info <app_name>", "Show application information"},
-		{"⚡ System Operations", "system restart", "Restart system"},
-		{"⚡ System Operations", "system shutdown", "Shutdown system"},
-		{"⚡ System Operations", "system sleep", "Put system to sleep"},
-		{"⚡ System Operations", "system info", "Show system information"},
-		{"🏥 System Health Check", "health", "Check system health"},
-		{"🔍 Search & Delete", "search <pattern> <directory>", "Search for files"},
-		{"🔍 Search & Delete", "delete <path> [--force]", "Delete a file"},
-		{"🗂️ File Browser", "browse [path]", "Interactive file browser"},
-		{"📥 Download", "download <url> <filename>", "Download file with progress bar"},
-		{"📊 Multi-Progress", "multi <operation> [files...]", "Process multiple files with multiple progress bars"},
-		{"⏸️ Pause/Resume", "pause demo", "Demonstrate pause/resume functionality"},
-		{"⏸️ Pause/Resume", "pause test", "Test terminal compatibility"},
-		{"⏸️ Pause/Resume", "pause state", "Test JSON state persistence"},
-		{"⏸️ Pause/Resume", "pause adaptive", "Test adaptive refresh functionality"},
-		{"⏸️ Pause/Resume", "pause theme", "Test custom progress bar themes"},
-		{"⏸️ Pause/Resume", "pause events", "Test event hooks and callbacks"},
-		{"⏸️ Pause/Resume", "pause http", "Test real HTTP download functionality"},
-		{"👀 File Watching", "watch start [paths...]", "Start real-time file system monitoring"},
-		{"👀 File Watching", "watch stop", "Stop file watching session"},
-		{"👀 File Watching", "watch status", "Show file watching status"},
-		{"👀 File Watching", "watch demo", "Demonstrate file watching capabilities"},
-		{"👀 File Watching", "watch debug", "Test enhanced debug features with detailed logging"},
-		{"👀 File Watching", "watch advanced", "Test enterprise-grade features: batching, prioritization, metrics, error recovery"},
-		{"👀 File Watching", "watch add <path>", "Add path dynamically to running watcher"},
-		{"👀 File Watching", "watch remove <path>", "Remove path dynamically from running watcher"},
-		{"👀 File Watching", "watch metrics", "Show detailed performance metrics"},
-		{"👀 File Watching", "watch reload", "Reload configuration without restart"},
-		{"🎨 Theme Management", "theme list", "List all available themes"},
-		{"🎨 Theme Management", "theme current", "Show current theme information"},
-		{"🎨 Theme Management", "theme set <name>", "Set active theme"},
-		{"🎨 Theme Management", "theme preview <name>", "Preview theme with color samples"},
-		{"🎨 Theme Management", "theme toggle", "Toggle between light and dark modes"},
-		{"🎨 Theme Management", "theme demo", "Demonstrate all themes"},
-		{"🎨 Theme Management", "theme create <name> <desc> <mode>", "Create custom theme"},
-		{"🎨 Theme Management", "theme delete <name>", "Delete custom theme"},
-		{"🎨 Theme Management", "theme save <name>", "Save theme to disk"},
-		{"🎨 Theme Management", "theme load <file>", "Load theme from disk"},
-		{"🎨 Theme Management", "theme setcolor <theme> <element> <hex>", "Set specific color"},
-		{"🎨 Theme Management", "theme validate <name>", "Validate theme"},
-		{"🎨 Theme Management", "theme cache <clear|stats>", "Manage color cache"},
-		{"🔔 Desktop Notifications", "notify test", "Send test notification"},
-		{"🔔 Desktop Notifications", "notify send <type> <title> <message>", "Send custom notification"},
-		{"🔔 Desktop Notifications", "notify status", "Show notification system status"},
-		{"🔔 Desktop Notifications", "notify history", "Show notification history"},
-		{"🔔 Desktop Notifications", "notify demo", "Demonstrate all notification types"},
-		{"🧠 Smart Suggestions", "suggest", "Get intelligent suggestions based on usage patterns"},
-		{"🧠 Smart Suggestions", "stats", "Show usage statistics and analytics"},
-		{"🧠 Smart Suggestions", "feedback <id> <type>", "Provide feedback on suggestions"},
-		{"🧠 Smart Suggestions", "workflow", "Show workflow optimization suggestions"},
-		{"🧠 Smart Suggestions", "optimize", "Show system optimization suggestions"},
-		{"📦 Batch Operations", "batch-delete <paths...>", "Delete multiple files/folders with progress tracking"},
-		{"📦 Batch Operations", "batch-copy <sources...> <dest>", "Copy multiple files/folders recursively"},
-		{"📦 Batch Operations", "batch-move <sources...> <dest>", "Move multiple files/folders efficiently"},
-		{"📦 Batch Operations", "batch-status [job-id]", "Show status of batch operations"},
-		{"📦 Batch Operations", "batch-cancel <job-id>", "Cancel a running batch operation"},
-		{"↩️ Undo Operations", "undo-history", "Show undo history and available operations"},
-		{"↩️ Undo Operations", "undo-operation <id>", "Undo a specific operation"},
-		{"↩️ Undo Operations", "undo-session <id>", "Undo all operations in a session"},
-		{"↩️ Undo Operations", "restore-file <path>", "Restore a file from undo history"},
-		{"↩️ Undo Operations", "start-session <name>", "Start a new undo session"},
-		{"↩️ Undo Operations", "end-session", "End the current undo session"},
-		{"🗂️ Smart Organization", "organize <paths...>", "Organize files using smart rules"},
-		{"🗂️ Smart Organization", "organize-file <path>", "Organize a single file"},
-		{"🗂️ Smart Organization", "add-rule <name>", "Add new organization rule"},
-		{"🗂️ Smart Organization", "list-rules", "List all organization rules"},
-		{"🗂️ Smart Organization", "file-type <path>", "Get file type information"},
-		{"🗂️ Smart Organization", "start-watching", "Start real-time file organization"},
-		{"🗂️ Smart Organization", "stop-watching", "Stop real-time file organization"},
-		{"🔍 Pattern Operations", "find <pattern> [paths...]", "Find files matching pattern criteria"},
-		{"🔍 Pattern Operations", "create-operation <name>", "Create new pattern operation"},
-		{"🔍 Pattern Operations", "list-operations", "List all pattern operations"},
-		{"🔍 Pattern Operations", "execute-operation <id>", "Execute specific pattern operation"},
-		{"🔍 Pattern Operations", "execute-all", "Execute all enabled pattern operations"},
-		{"🔍 Pattern Operations", "remove-operation <id>", "Remove pattern operation"},
-		{"💾 Backup Operations", "create-backup <path>", "Create a backup of a file or directory"},
-		{"💾 Backup Operations", "list-backups", "List all backups with details"},
-		{"💾 Backup Operations", "restore-backup <id> [dest]", "Restore a backup to original or new location"},
-		{"💾 Backup Operations", "delete-backup <id>", "Delete a backup and its files"},
-		{"💾 Backup Operations", "backup-stats", "Show backup system statistics"},
-		{"💾 Backup Operations", "backup-cleanup", "Clean up expired backups"},
-		{"📱 App Detection", "scan-apps", "Scan for installed applications"},
-		{"📱 App Detection", "list-apps", "List detected applications with filters"},
-		{"📱 App Detection", "app-info <id>", "Show detailed application information"},
-		{"📱 App Detection", "app-stats", "Show application detection statistics"},
-		{"📱 App Detection", "running-apps", "Show currently running applications"},
-		{"📱 App Detection", "default-apps", "Show default applications for file types"},
-		{"💡 Other", "help", "Show this help"},
-		{"💡 Other", "status", "Show Ena's status"},
-		{"💡 Other", "exit", "Say goodbye to Ena"},
+// otherCategory collects commands that do not belong to a group
+const otherCategory = "💡 Other"
+
+// commandGroups defines the help categories in display order
+var commandGroups = []*cobra.Group{
+	{ID: "file", Title: "📁 File Operations"},
+	{ID: "folder", Title: "📂 Folder Operations"},
+	{ID: "terminal", Title: "🖥️  Terminal Operations"},
+	{ID: "app", Title: "📱 Application Operations"},
+	{ID: "system", Title: "⚡ System Operations"},
+	{ID: "health", Title: "🏥 System Health Check"},
+	{ID: "search", Title: "🔍 Search & Delete"},
+	{ID: "browse", Title: "🗂️ File Browser"},
+	{ID: "download", Title: "📥 Download"},
+	{ID: "multi", Title: "📊 Multi-Progress"},
+	{ID: "pause", Title: "⏸️ Pause/Resume"},
+	{ID: "watch", Title: "👀 File Watching"},
+	{ID: "theme", Title: "🎨 Theme Management"},
+	{ID: "notify", Title: "🔔 Desktop Notifications"},
+	{ID: "suggest", Title: "🧠 Smart Suggestions"},
+	{ID: "batch", Title: "📦 Batch Operations"},
+	{ID: "undo", Title: "↩️ Undo Operations"},
+	{ID: "organize", Title: "🗂️ Smart Organization"},
+	{ID: "pattern", Title: "🔍 Pattern Operations"},
+	{ID: "backup", Title: "💾 Backup Operations"},
+	{ID: "trash", Title: "🗑️ Trash"},
+	{ID: "dupes", Title: "👯 Duplicate Files"},
+	{ID: "du", Title: "📊 Disk Usage"},
+	{ID: "diff", Title: "🔀 Diff"},
+	{ID: "appdetect", Title: "📱 App Detection"},
+	{ID: "daemon", Title: "🌙 Daemon"},
+	{ID: "config", Title: "⚙️ Configuration"},
+	{ID: "audit", Title: "🧾 Audit Log"},
+	{ID: "policy", Title: "🛡️ Safety Policy"},
+	{ID: "schedule", Title: "⏰ Scheduler"},
+	{ID: "events", Title: "📣 Events"},
+	{ID: "script", Title: "📜 Scripts"},
+	{ID: "record", Title: "⏺️ Recording"},
+	{ID: "ask", Title: "💬 Natural Language"},
+	{ID: "plugin", Title: "🧩 Plugins"},
+}
+
+// replBuiltins are handled by interactive mode itself rather than the command tree
+var replBuiltins = []HelpEntry{
+	{otherCategory, "help", "Show this help"},
+	{otherCategory, "status", "Show Ena's status"},
+	{otherCategory, "exit", "Say goodbye to Ena"},
+}
+
+// GetHelpEntries builds the help entries from the command tree
+func GetHelpEntries(rootCmd *cobra.Command) []HelpEntry {
+	var entries []HelpEntry
+
+	// Grouped commands first, in category order
+	for _, group := range rootCmd.Groups() {
+		for _, cmd := range rootCmd.Commands() {
+			if cmd.GroupID == group.ID && cmd.IsAvailableCommand() {
+				entries = append(entries, commandHelpEntries(group.Title, "", cmd)...)
+			}
+		}
+	}
+
+	// Anything ungrouped (e.g. completion) goes under Other; help is a builtin
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.GroupID == "" && cmd.IsAvailableCommand() && cmd.Name() != "help" {
+			entries = append(entries, commandHelpEntries(otherCategory, "", cmd)...)
+		}
 	}
+
+	return append(entries, replBuiltins...)
 }
 
-// SetupRootCommand creates and configures the root command
-func SetupRootCommand(assistant *core.Assistant) *cobra.Command {
+// commandHelpEntries lists a command, or its subcommands when it has any
+func commandHelpEntries(category, prefix string, cmd *cobra.Command) []HelpEntry {
+	if !cmd.HasAvailableSubCommands() {
+		return []HelpEntry{{category, prefix + cmd.Use, cmd.Short}}
+	}
+
+	var entries []HelpEntry
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			entries = append(entries, commandHelpEntries(category, prefix+cmd.Name()+" ", sub)...)
+		}
+	}
+	return entries
+}
+
+// GetCompletionWords lists the command paths offered by tab completion
+func GetCompletionWords(rootCmd *cobra.Command) []string {
+	var words []string
+
+	for _, cmd := range rootCmd.Commands() {
+		if !cmd.IsAvailableCommand() {
+			continue
+		}
+
+		words = append(words, cmd.Name())
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() {
+				words = append(words, cmd.Name()+" "+sub.Name())
+			}
+		}
+		for _, arg := range cmd.ValidArgs {
+			words = append(words, cmd.Name()+" "+arg)
+		}
+	}
+
+	for _, builtin := range replBuiltins {
+		words = append(words, builtin.Command)
+	}
+
+	return words
+}
+
+// SetupRootCommand creates and configures the root command from one service container
+func SetupRootCommand(services *app.App, assistant *core.Assistant) *cobra.Command {
 	// Command line interface configuration
 	var rootCmd = &cobra.Command{
 		Use:   "ena",
@@ -163,11 +166,18 @@ What I can do:
   ⚡ System restart and shutdown
 
 Let's make your computer life fun and easy together! (╹◡╹)♡`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Default behavior: start interactive mode
-			startInteractiveMode(assistant)
+			startInteractiveMode(cmd, assistant)
+			return nil
 		},
 	}
+	rootCmd.AddGroup(commandGroups...)
+	addOutputFlag(rootCmd)
+	addExitCodesTopic(rootCmd)
+	addCompletionCommand(rootCmd)
+	addPolicyFlag(rootCmd, services)
+	addPipesTopic(rootCmd)
 
 	// Add subcommands
 	setupFileCommands(rootCmd, assistant)
@@ -177,48 +187,76 @@ Let's make your computer life fun and easy together! (╹◡╹)♡`,
 	setupSystemCommands(rootCmd, assistant)
 	setupHealthCommands(rootCmd, assistant)
 	setupSearchCommands(rootCmd, assistant)
+	setupBrowseCommands(rootCmd, assistant)
 	setupDownloadCommands(rootCmd, assistant)
 	setupMultiCommands(rootCmd, assistant)
 	setupPauseCommands(rootCmd, assistant)
 	setupWatchCommands(rootCmd, assistant)
 	setupThemeCommands(rootCmd, assistant)
 	setupNotificationCommands(rootCmd, assistant)
-	setupSuggestionsCommands(rootCmd)
-	setupBatchCommands(rootCmd)
-	setupUndoCommands(rootCmd)
-	setupOrganizerCommands(rootCmd)
-	setupPatternCommands(rootCmd)
-	setupBackupCommands(rootCmd)
-	setupAppDetectionCommands(rootCmd)
+	setupSuggestionsCommands(rootCmd, assistant)
+	setupBatchCommands(rootCmd, services)
+	setupUndoCommands(rootCmd, services)
+	setupOrganizerCommands(rootCmd, services)
+	setupPatternCommands(rootCmd, services)
+	setupBackupCommands(rootCmd, services)
+	setupTrashCommands(rootCmd, services)
+	setupDupesCommands(rootCmd, services)
+	setupDiskUsageCommands(rootCmd, services)
+	setupDiffCommands(rootCmd, services)
+	setupAppDetectionCommands(rootCmd, services)
+	setupDaemonCommands(rootCmd, assistant)
+	setupConfigCommands(rootCmd)
+	setupAuditCommands(rootCmd)
+	setupPolicyCommands(rootCmd, services)
+	setupScheduleCommands(rootCmd, assistant)
+	setupEventCommands(rootCmd, services)
+	setupScriptCommands(rootCmd, assistant)
+	setupRecordCommands(rootCmd, assistant)
+	setupIntentCommands(rootCmd, services)
+
+	// Plugins go last so built-in commands keep their names
+	setupPluginCommands(rootCmd, assistant)
 
 	return rootCmd
 }
 
 // startInteractiveMode starts the interactive command mode
-func startInteractiveMode(assistant *core.Assistant) {
+func startInteractiveMode(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Interactive mode for user interaction
-	color.New(color.FgMagenta, color.Bold).Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	color.New(color.FgMagenta, color.Bold).Println("🌸 Ena - Your Gentle Virtual Assistant 🌸")
-	color.New(color.FgMagenta, color.Bold).Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	color.New(color.FgMagenta, color.Bold).Print(output.Prose("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
+	color.New(color.FgMagenta, color.Bold).Print(output.Prose("🌸 Ena - Your Gentle Virtual Assistant 🌸\n"))
+	color.New(color.FgMagenta, color.Bold).Print(output.Prose("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
 
-	fmt.Println(assistant.Greet())
+	fmt.Println(output.Prose(assistant.Greet()))
 	fmt.Println()
 
-	color.New(color.FgCyan).Println("💡 Tip: Type 'help' to see what I can do!")
-	color.New(color.FgCyan).Println("💡 Tip: Type 'exit' to say goodbye...")
-	color.New(color.FgCyan).Println("💡 Tip: Press TAB for command completion!")
-	color.New(color.FgCyan).Println("💡 Tip: Try typing 'fil' and press TAB for fuzzy search!")
+	color.New(color.FgCyan).Print(output.Prose("💡 Tip: Type 'help' to see what I can do!\n"))
+	color.New(color.FgCyan).Print(output.Prose("💡 Tip: Type 'exit' to say goodbye...\n"))
+	color.New(color.FgCyan).Print(output.Prose("💡 Tip: Press TAB for command completion!\n"))
+	color.New(color.FgCyan).Print(output.Prose("💡 Tip: Quote paths with spaces, e.g. file read \"My Notes.txt\"\n"))
+	color.New(color.FgCyan).Print(output.Prose("💡 Tip: Chain commands with |, &&, || and ; - $last is the previous result (see 'pipes --help')\n"))
+	color.New(color.FgCyan).Print(output.Prose("💡 Tip: Or just ask, e.g. move pdfs older than a month from Downloads to Documents\n"))
 	fmt.Println()
 
+	// Line editing redraws the terminal, which plain output is not for
+	if output.PlainMode() {
+		startBasicInteractiveMode(rootCmd, assistant)
+		return
+	}
+
 	// Initialize terminal input with completion support
-	terminalInput, err := input.NewTerminalInput()
+	terminalInput, err := input.NewTerminalInput(GetCompletionWords(rootCmd))
 	if err != nil {
-		color.New(color.FgRed).Printf("❌ Failed to initialize terminal input: %v\n", err)
+		color.New(color.FgRed).Fprintf(os.Stderr, output.Prose("❌ Failed to initialize terminal input: %v\n"), err)
 		color.New(color.FgYellow).Println("Falling back to basic input mode...")
-		startBasicInteractiveMode(assistant)
+		startBasicInteractiveMode(rootCmd, assistant)
 		return
 	}
 	defer terminalInput.Close()
+	terminalInput.Arguments = func(words []string, prefix string) []string {
+		return completeLine(assistant, words, prefix)
+	}
 
 	for {
 		// Read line with completion support
@@ -227,7 +265,7 @@ func startInteractiveMode(assistant *core.Assistant) {
 			if err.Error() == "EOF" || err.Error() == "interrupt" {
 				break
 			}
-			color.New(color.FgRed).Printf("❌ Error reading input: %v\n", err)
+			color.New(color.FgRed).Fprintf(os.Stderr, output.Prose("❌ Error reading input: %v\n"), err)
 			continue
 		}
 
@@ -238,98 +276,102 @@ func startInteractiveMode(assistant *core.Assistant) {
 		// Add to history
 		terminalInput.AddToHistory(inputStr)
 
-		if strings.ToLower(inputStr) == "exit" {
-			assistant.Shutdown()
+		if !handleLine(rootCmd, assistant, inputStr) {
 			break
 		}
-
-		if strings.ToLower(inputStr) == "help" {
-			showHelp()
-			continue
-		}
-
-		if strings.ToLower(inputStr) == "status" {
-			showStatus(assistant)
-			continue
-		}
-
-		// Parse and execute command
-		parts := strings.Fields(inputStr)
-		if len(parts) == 0 {
-			continue
-		}
-
-		command := parts[0]
-		args := parts[1:]
-
-		result, err := assistant.ProcessCommand(command, args)
-		if err != nil {
-			color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-		} else {
-			color.New(color.FgGreen).Println(result)
-		}
-
-		fmt.Println()
 	}
 }
 
 // startBasicInteractiveMode starts basic interactive mode without completion
-func startBasicInteractiveMode(assistant *core.Assistant) {
-	// Basic interactive mode fallback
+func startBasicInteractiveMode(rootCmd *cobra.Command, assistant *core.Assistant) {
+	// Basic interactive mode fallback; prompts read from the same reader
 	for {
 		fmt.Print("Ena> ")
 
-		var inputStr string
-		fmt.Scanln(&inputStr)
-		inputStr = strings.TrimSpace(inputStr)
+		line, err := input.ReadLine()
+		if err != nil {
+			break
+		}
+		inputStr := strings.TrimSpace(line)
 
 		if inputStr == "" {
 			continue
 		}
 
-		if strings.ToLower(inputStr) == "exit" {
-			assistant.Shutdown()
+		if !handleLine(rootCmd, assistant, inputStr) {
 			break
 		}
+	}
+}
 
-		if strings.ToLower(inputStr) == "help" {
-			showHelp()
-			continue
-		}
+// handleLine parses and runs one interactive line, returning false on exit
+func handleLine(rootCmd *cobra.Command, assistant *core.Assistant, inputStr string) bool {
+	// Split the line like a shell would: operators, quotes, escapes, variables, comments
+	pipelines, err := newREPLLexer().ParseLine(inputStr)
+	if err != nil {
+		color.New(color.FgRed).Fprintf(os.Stderr, output.Prose("❌ Error: %v\n"), err)
+		return true
+	}
 
-		if strings.ToLower(inputStr) == "status" {
-			showStatus(assistant)
-			continue
-		}
+	if len(pipelines) == 0 {
+		return true
+	}
 
-		// Parse and execute command
-		parts := strings.Fields(inputStr)
-		if len(parts) == 0 {
-			continue
+	// Builtins only apply to a line holding a single word
+	if len(pipelines) == 1 && len(pipelines[0].Stages) == 1 {
+		switch strings.ToLower(pipelines[0].Stages[0]) {
+		case "exit":
+			assistant.Shutdown()
+			return false
+		case "help":
+			showHelp(rootCmd)
+			return true
+		case "status":
+			showStatus(assistant)
+			return true
 		}
+	}
 
-		command := parts[0]
-		args := parts[1:]
+	// A line left running long enough that the user may have looked away notifies when done
+	start := time.Now()
+	ok := runPipelines(assistant, pipelines)
+	assistant.App.Notifications.NotifyIfSlow(inputStr, time.Since(start), ok)
+	fmt.Println()
+	return true
+}
 
-		result, err := assistant.ProcessCommand(command, args)
-		if err != nil {
-			color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-		} else {
-			color.New(color.FgGreen).Println(result)
-		}
+// executeLine runs one line through a fresh command tree, exactly like the CLI,
+// and returns its exit code
+func executeLine(assistant *core.Assistant, args []string) int {
+	code, _ := executeLineResult(assistant, args)
+	return code
+}
 
-		fmt.Println()
-	}
+// executeLineResult is executeLine that also returns the command's typed result
+func executeLineResult(assistant *core.Assistant, args []string) (int, interface{}) {
+	// A new tree per line keeps flag values from leaking between commands
+	rootCmd := SetupRootCommand(assistant.App, assistant)
+	rootCmd.SetArgs(args)
+
+	// The enclosing command (interactive mode or a script) keeps its own output session
+	outer, outerCommand := currentOutput, audit.Command()
+	defer func() {
+		currentOutput = outer
+		audit.SetCommand(outerCommand)
+	}()
+
+	// Errors, usage hints and exit codes match running ena from the shell
+	return executeResult(rootCmd)
 }
 
 // showHelp displays the help information
-func showHelp() {
+func showHelp(rootCmd *cobra.Command) {
 	// Display comprehensive help information
-	color.New(color.FgCyan, color.Bold).Println("🌸 Ena's Command List 🌸")
-	color.New(color.FgCyan, color.Bold).Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	color.New(color.FgCyan, color.Bold).Print(output.Prose("🌸 Ena's Command List 🌸\n"))
+	color.New(color.FgCyan, color.Bold).Print(output.Prose("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
 	fmt.Println()
 
-	entries := GetHelpEntries()
+	entries := GetHelpEntries(rootCmd)
 	currentCategory := ""
 
 	for _, entry := range entries {
@@ -338,7 +380,7 @@ func showHelp() {
 			if currentCategory != "" {
 				fmt.Println()
 			}
-			color.New(color.FgYellow, color.Bold).Printf("%s:\n", entry.Category)
+			color.New(color.FgYellow, color.Bold).Printf("%s:\n", output.Prose(entry.Category))
 			currentCategory = entry.Category
 		}
 
@@ -348,8 +390,8 @@ func showHelp() {
 	}
 
 	fmt.Println()
-	color.New(color.FgCyan, color.Bold).Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	color.New(color.FgMagenta).Println("Let's have fun managing your system together! (╹◡╹)♡")
+	color.New(color.FgCyan, color.Bold).Print(output.Prose("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
+	color.New(color.FgMagenta).Print(output.Prose("Let's have fun managing your system together! (╹◡╹)♡\n"))
 }
 
 // showStatus displays the assistant status
@@ -357,8 +399,8 @@ func showStatus(assistant *core.Assistant) {
 	// Show Ena's current status
 	status := assistant.GetStatus()
 
-	color.New(color.FgMagenta, color.Bold).Println("🌸 Ena's Status 🌸")
-	color.New(color.FgMagenta, color.Bold).Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	color.New(color.FgMagenta, color.Bold).Print(output.Prose("🌸 Ena's Status 🌸\n"))
+	color.New(color.FgMagenta, color.Bold).Print(output.Prose("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
 
 	// Name
 	color.New(color.FgWhite).Print("Name: ")
@@ -371,9 +413,9 @@ func showStatus(assistant *core.Assistant) {
 	// Running status
 	color.New(color.FgWhite).Print("Running: ")
 	if status["running"] == "true" {
-		color.New(color.FgGreen, color.Bold).Println("✓ Yes")
+		color.New(color.FgGreen, color.Bold).Print(output.Prose("✓ Yes\n"))
 	} else {
-		color.New(color.FgRed).Println("✗ No")
+		color.New(color.FgRed).Print(output.Prose("✗ No\n"))
 	}
 
 	// Uptime
@@ -384,6 +426,6 @@ func showStatus(assistant *core.Assistant) {
 	color.New(color.FgWhite).Print("Start Time: ")
 	color.New(color.FgCyan).Println(status["startTime"])
 
-	color.New(color.FgMagenta, color.Bold).Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	color.New(color.FgGreen).Println("I'm doing great! (๑˃̵ᴗ˂̵)")
+	color.New(color.FgMagenta, color.Bold).Print(output.Prose("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
+	color.New(color.FgGreen).Print(output.Prose("I'm doing great! (๑˃̵ᴗ˂̵)\n"))
 }
@@ -0,0 +1,322 @@
+/**
+ * Schedule Commands
+ *
+ * Provides commands for adding, listing, removing and manually running cron
+ * jobs that trigger pattern operations, organizer runs, backups, backup
+ * cleanup and scripts, and for reading their run history.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: schedule_commands.go
+ * Description: Scheduled job command definitions and job execution
+ */
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/audit"
+	"ena/internal/core"
+	"ena/internal/output"
+	"ena/internal/schedule"
+	"ena/internal/script"
+)
+
+// scheduleExecutor carries out scheduled jobs with the assistant's managers
+type scheduleExecutor struct {
+	assistant *core.Assistant
+}
+
+// Execute runs one job's action, attributing its changes to the job in the audit log
+func (e scheduleExecutor) Execute(job *schedule.Job) (string, error) {
+	services := e.assistant.App
+
+	outerCommand := audit.Command()
+	audit.SetCommand("schedule " + job.Name)
+	defer audit.SetCommand(outerCommand)
+
+	switch job.Action {
+	case schedule.ActionPattern:
+		result, err := services.Patterns.ExecuteOperation(job.Args[0], false)
+		if err != nil {
+			return "", err
+		}
+		summary := fmt.Sprintf("%d files matched, %d processed", result.FilesMatched, result.FilesProcessed)
+		if result.FilesFailed > 0 {
+			return summary, fmt.Errorf("%d files failed: %s", result.FilesFailed, strings.Join(result.Errors, "; "))
+		}
+		return summary, nil
+
+	case schedule.ActionOrganize:
+		results, err := services.Organizer.OrganizeFiles(job.Args, false)
+		if err != nil {
+			return "", err
+		}
+		processed, moved := 0, 0
+		var errs []string
+		for _, result := range results {
+			processed += result.FilesProcessed
+			moved += result.FilesMoved
+			errs = append(errs, result.Errors...)
+		}
+		summary := fmt.Sprintf("%d rules applied, %d files processed, %d moved", len(results), processed, moved)
+		if len(errs) > 0 {
+			return summary, fmt.Errorf("%s", strings.Join(errs, "; "))
+		}
+		return summary, nil
+
+	case schedule.ActionBackup:
+		description := fmt.Sprintf("Scheduled backup of %s (%s)", filepath.Base(job.Args[0]), job.Name)
+		metadata, err := services.Backups.CreateScheduledBackup(job.Args[0], job.ID, description)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Backed up %s to %s (%s)", job.Args[0], metadata.BackupPath, formatBytesBackup(metadata.Size)), nil
+
+	case schedule.ActionBackupCleanup:
+		cleaned, err := services.Backups.CleanupExpiredBackups()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Removed %d expired backups", cleaned), nil
+
+	case schedule.ActionScript:
+		// Scripts are command lines, so they run through a command tree like any other
+		code, data := executeLineResult(e.assistant, append([]string{"script", "run"}, job.Args...))
+		summary := ""
+		if result, ok := data.(*script.RunResult); ok {
+			summary = fmt.Sprintf("Ran %d steps in %v", len(result.Steps), result.Duration.Round(time.Millisecond))
+		}
+		if code != 0 {
+			return summary, fmt.Errorf("script %s failed with exit code %d", job.Args[0], code)
+		}
+		return summary, nil
+	}
+
+	return "", fmt.Errorf("unknown action %q", job.Action)
+}
+
+// setupScheduleCommands sets up scheduled job commands
+func setupScheduleCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
+	scheduler := assistant.App.Scheduler
+	scheduler.Executor = scheduleExecutor{assistant: assistant}
+
+	actionNames := make([]string, len(schedule.Actions))
+	for i, action := range schedule.Actions {
+		actionNames[i] = string(action)
+	}
+
+	// Schedule parent command
+	scheduleCmd := &cobra.Command{
+		Use:     "schedule",
+		GroupID: "schedule",
+		Short:   "Run operations on a cron schedule",
+		Long: `Run pattern operations, organizer runs, backups, backup cleanup and scripts
+on a schedule. Schedules use cron syntax - minute, hour, day of month, month
+and day of week - or @hourly, @daily, @weekly, @monthly and @yearly.
+
+Jobs fire while the daemon is running ("ena daemon start"). A run that fell
+due while it was not is skipped, or with --missed run, made once when the
+daemon next starts. Every run is kept in the history with its result.
+
+Actions and their arguments:
+  pattern <operation-id>        execute a pattern operation
+  organize <path> [paths...]    apply the organization rules
+  backup <path>                 back up a file
+  backup-cleanup                remove expired backups
+  script <name> [args...]       run a script
+
+Examples:
+  ena schedule add "0 3 * * *" backup ~/notes.md --name nightly-notes
+  ena schedule add @weekly backup-cleanup --missed run
+  ena schedule add "*/30 9-17 * * mon-fri" organize ~/Downloads
+  ena schedule list
+  ena schedule run-now nightly-notes
+  ena schedule history nightly-notes`,
+	}
+
+	// Add command
+	addCmd := &cobra.Command{
+		Use:       "add <cron> <action> [args...]",
+		Short:     "Add a scheduled job",
+		Args:      cobra.MinimumNArgs(2),
+		ValidArgs: actionNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			missed, _ := cmd.Flags().GetString("missed")
+
+			job := &schedule.Job{
+				Name:   name,
+				Cron:   args[0],
+				Action: schedule.Action(args[1]),
+				Args:   args[2:],
+				Missed: schedule.MissedPolicy(missed),
+			}
+
+			// The daemon has its own working directory, so store absolute paths
+			if job.Action == schedule.ActionOrganize || job.Action == schedule.ActionBackup {
+				for i, path := range job.Args {
+					if absPath, err := filepath.Abs(path); err == nil {
+						job.Args[i] = absPath
+					}
+				}
+			}
+
+			if err := scheduler.Add(job); err != nil {
+				return reportError("❌ Error adding scheduled job: %v\n", err)
+			}
+
+			reportResult(job)
+			output.Printf("✅ Scheduled %s: %s\n", job.Name, describeJob(job))
+			output.Printf("⏰ Next run: %s\n", job.NextRun.Format("2006-01-02 15:04"))
+			return nil
+		},
+	}
+	addCmd.Flags().String("name", "", "Name to refer to the job by (defaults to its ID)")
+	addCmd.Flags().String("missed", string(schedule.MissedSkip), "What to do with runs missed while the daemon was down: skip or run")
+
+	// List command
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List scheduled jobs, soonest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs, err := scheduler.List()
+			if err != nil {
+				return reportError("❌ Error listing scheduled jobs: %v\n", err)
+			}
+
+			reportResult(jobs)
+			if len(jobs) == 0 {
+				output.Printf("🌸 No scheduled jobs. Add one with 'ena schedule add'!\n")
+				return nil
+			}
+
+			output.Printf("🗓️  Scheduled jobs (%d):\n", len(jobs))
+			for _, job := range jobs {
+				output.Printf("\n⏰ %s [%s]\n", job.Name, job.Cron)
+				output.Printf("   📋 %s\n", describeJob(job))
+				output.Printf("   ⏭️  Next run: %s | Missed runs: %s\n", job.NextRun.Format("2006-01-02 15:04"), job.Missed)
+				if job.LastRun != nil {
+					output.Printf("   🏃 Last run: %s\n", job.LastRun.Format("2006-01-02 15:04:05"))
+				}
+				if job.Name != job.ID {
+					output.Printf("   🆔 %s\n", job.ID)
+				}
+			}
+			return nil
+		},
+	}
+
+	// Remove command
+	removeCmd := &cobra.Command{
+		Use:   "remove <id|name>",
+		Short: "Remove a scheduled job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := scheduler.Remove(args[0])
+			if err != nil {
+				return reportError("❌ Error removing scheduled job: %v\n", err)
+			}
+
+			reportResult(job)
+			output.Printf("🗑️  Removed scheduled job %s\n", job.Name)
+			return nil
+		},
+	}
+
+	// Run-now command
+	runNowCmd := &cobra.Command{
+		Use:   "run-now <id|name>",
+		Short: "Run a scheduled job straight away",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := scheduler.Get(args[0])
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			output.Printf("🚀 Running %s: %s\n", job.Name, describeJob(job))
+			run, err := scheduler.RunNow(job.ID)
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(run)
+			showRun(*run)
+			if run.Status == schedule.RunFailed {
+				return reportError("❌ Scheduled job %s failed\n", job.Name)
+			}
+			return nil
+		},
+	}
+
+	// History command
+	historyCmd := &cobra.Command{
+		Use:   "history [id|name]",
+		Short: "Show recent runs of scheduled jobs",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+
+			runs, err := scheduler.History(filter, limit)
+			if err != nil {
+				return reportError("❌ Error reading schedule history: %v\n", err)
+			}
+
+			reportResult(runs)
+			if len(runs) == 0 {
+				output.Printf("🌸 No scheduled runs yet\n")
+				return nil
+			}
+
+			output.Printf("📜 Scheduled runs (%d):\n", len(runs))
+			for _, run := range runs {
+				showRun(run)
+			}
+			return nil
+		},
+	}
+	historyCmd.Flags().Int("limit", 20, "Number of runs to show (0 for all)")
+
+	scheduleCmd.AddCommand(addCmd, listCmd, removeCmd, runNowCmd, historyCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+// describeJob says what a job does in a few words
+func describeJob(job *schedule.Job) string {
+	if len(job.Args) == 0 {
+		return string(job.Action)
+	}
+	return fmt.Sprintf("%s %s", job.Action, strings.Join(job.Args, " "))
+}
+
+// showRun prints one run of a scheduled job
+func showRun(run schedule.Run) {
+	icon := output.Prose("✅ ")
+	switch run.Status {
+	case schedule.RunFailed:
+		icon = output.Prose("❌ ")
+	case schedule.RunSkipped:
+		icon = output.Prose("⏭️  ")
+	}
+
+	fmt.Printf("%s%s %s (%s, %v)\n", icon, run.StartTime.Format("2006-01-02 15:04:05"), run.JobName,
+		run.Trigger, run.Duration.Round(time.Millisecond))
+	if run.Summary != "" {
+		output.Printf("   📊 %s\n", run.Summary)
+	}
+	if run.Error != "" {
+		output.Printf("   ⚠️ %s\n", run.Error)
+	}
+}
@@ -0,0 +1,334 @@
+/**
+ * Script Commands
+ *
+ * Provides commands for creating, inspecting and running scripts: named
+ * sequences of Ena commands with parameters and exit-status conditionals.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: script_commands.go
+ * Description: Script creation, listing and execution command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/core"
+	"ena/internal/output"
+	"ena/internal/script"
+)
+
+// runningScripts holds the scripts currently executing, to stop a script calling itself
+var runningScripts = make(map[string]bool)
+
+// scriptExecutor runs script steps through fresh command trees
+type scriptExecutor struct {
+	assistant *core.Assistant
+}
+
+// Run executes a step like a command typed at the prompt
+func (e scriptExecutor) Run(args []string) int {
+	return executeLine(e.assistant, args)
+}
+
+// Preview executes a step with --dry-run when its command supports it
+func (e scriptExecutor) Preview(args []string) (int, bool) {
+	cmd, _, err := SetupRootCommand(e.assistant.App, e.assistant).Find(args)
+	if err != nil || cmd.Flags().Lookup("dry-run") == nil {
+		return 0, false
+	}
+
+	previewArgs := append(append([]string{}, args...), "--dry-run")
+	return executeLine(e.assistant, previewArgs), true
+}
+
+// setupScriptCommands sets up script management commands
+func setupScriptCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
+	store := script.NewStore()
+
+	// Script parent command
+	scriptCmd := &cobra.Command{
+		Use:     "script",
+		GroupID: "script",
+		Short:   "Create and run scripts of Ena commands",
+		Long: `Scripts are named sequences of Ena commands stored in
+$XDG_DATA_HOME/ena/scripts. Steps may use parameters as $name or ${name},
+positional arguments as $1, $2, ... and environment variables.
+
+Each step runs depending on how the previous one ended:
+  command        runs when the previous step succeeded (the default)
+  || command     runs only when the previous step failed
+  ; command      always runs
+
+A run succeeds when the last step that ran succeeded. All file changes made
+by a run are grouped into a single undo session, so "ena undo-session <id>"
+reverts the whole script. Steps run in this process rather than in the
+daemon so that their changes land in that session.
+
+Examples:
+  ena script create tidy --param dir=. --step 'organize $dir' --step '|| notify error "Tidy failed"'
+  ena script create workflow_1 --commands health --commands list-backups
+  ena script run tidy ~/Downloads
+  ena script run tidy --param dir=~/Desktop --dry-run
+  ena script list`,
+	}
+
+	// Create command
+	createCmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create or replace a script",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			commandSteps, _ := cmd.Flags().GetStringArray("commands")
+			stepTexts, _ := cmd.Flags().GetStringArray("step")
+			paramSpecs, _ := cmd.Flags().GetStringArray("param")
+			description, _ := cmd.Flags().GetString("description")
+			force, _ := cmd.Flags().GetBool("force")
+
+			if store.Exists(name) && !force {
+				return reportError("❌ Script %s already exists (use --force to replace it)\n", name)
+			}
+
+			// --commands is the form workflow suggestions use; its steps come first
+			stepTexts = append(commandSteps, stepTexts...)
+			if len(stepTexts) == 0 {
+				return reportError("❌ A script needs at least one --step or --commands\n")
+			}
+
+			s := &script.Script{Name: name, Description: description}
+			for _, text := range stepTexts {
+				step, err := script.ParseStep(text)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				s.Steps = append(s.Steps, step)
+			}
+			for _, spec := range paramSpecs {
+				param, err := script.ParseParam(spec)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				s.Params = append(s.Params, param)
+			}
+
+			if err := store.Save(s); err != nil {
+				return reportError("❌ Error saving script: %v\n", err)
+			}
+
+			reportResult(s)
+			output.Printf("✅ Created script %s with %d steps\n", s.Name, len(s.Steps))
+			output.Printf("🚀 Run it with: ena script run %s\n", s.Usage())
+			return nil
+		},
+	}
+	createCmd.Flags().StringArray("commands", []string{}, "A step, as written by workflow suggestions; repeat for more")
+	createCmd.Flags().StringArray("step", []string{}, "A step; repeat for more (prefix with || or ; for conditionals)")
+	createCmd.Flags().StringArray("param", []string{}, "A parameter as name or name=default; repeat for more")
+	createCmd.Flags().String("description", "", "Description of the script")
+	createCmd.Flags().Bool("force", false, "Replace an existing script")
+
+	// Run command
+	runCmd := &cobra.Command{
+		Use:   "run <name> [args...]",
+		Short: "Run a script",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			paramPairs, _ := cmd.Flags().GetStringArray("param")
+
+			s, err := store.Load(args[0])
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			overrides := make(map[string]string)
+			for _, pair := range paramPairs {
+				name, value, ok := strings.Cut(pair, "=")
+				if !ok {
+					return reportError("❌ --param expects name=value, got %q\n", pair)
+				}
+				overrides[name] = value
+			}
+
+			values, err := s.Bind(args[1:], overrides)
+			if err != nil {
+				return reportError("❌ Error: %v\nUsage: ena script run %s\n", err, s.Usage())
+			}
+
+			if runningScripts[s.Name] {
+				return reportError("❌ Script %s is already running and cannot call itself\n", s.Name)
+			}
+			runningScripts[s.Name] = true
+			defer delete(runningScripts, s.Name)
+
+			result := runScript(assistant, s, values, dryRun)
+			reportResult(result)
+
+			if !dryRun {
+				if err := store.RecordRun(s); err != nil {
+					output.Printf("⚠️ Warning: Failed to record script run: %v\n", err)
+				}
+			}
+
+			printScriptSummary(result)
+			if !result.OK {
+				return reportError("❌ Script %s failed\n", s.Name)
+			}
+			return nil
+		},
+	}
+	runCmd.Flags().Bool("dry-run", false, "Show what would run, previewing steps that support --dry-run")
+	runCmd.Flags().StringArray("param", []string{}, "Set a parameter as name=value; repeat for more")
+
+	// List command
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List stored scripts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scripts, err := store.List()
+			if err != nil {
+				return reportError("❌ Error listing scripts: %v\n", err)
+			}
+
+			reportResult(scripts)
+			if len(scripts) == 0 {
+				output.Printf("🌸 No scripts yet. Create one with 'ena script create'!\n")
+				return nil
+			}
+
+			output.Printf("🌸 Scripts (%d) (╹◡╹)♡\n", len(scripts))
+			fmt.Println("========================")
+			for _, s := range scripts {
+				output.Printf("📜 %s - %d steps, run %d times\n", s.Usage(), len(s.Steps), s.RunCount)
+				if s.Description != "" {
+					output.Printf("   📝 %s\n", s.Description)
+				}
+			}
+			return nil
+		},
+	}
+
+	// Show command
+	showCmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a script's steps",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := store.Load(args[0])
+			if err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+
+			reportResult(s)
+			output.Printf("📜 %s\n", s.Usage())
+			if s.Description != "" {
+				output.Printf("📝 %s\n", s.Description)
+			}
+			for i, step := range s.Steps {
+				fmt.Printf("  %d. %s\n", i+1, step)
+			}
+			if s.LastRunAt != nil {
+				output.Printf("⏰ Last run: %s (%d runs)\n", s.LastRunAt.Format("2006-01-02 15:04:05"), s.RunCount)
+			}
+			return nil
+		},
+	}
+
+	// Delete command
+	deleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a script",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := store.Delete(args[0]); err != nil {
+				return reportError("❌ Error: %v\n", err)
+			}
+			output.Printf("🗑️ Deleted script %s\n", args[0])
+			return nil
+		},
+	}
+
+	scriptCmd.AddCommand(createCmd, runCmd, listCmd, showCmd, deleteCmd)
+	rootCmd.AddCommand(scriptCmd)
+}
+
+// runScript executes a script, wrapping a real run in a single undo session
+func runScript(assistant *core.Assistant, s *script.Script, values map[string]string, dryRun bool) *script.RunResult {
+	runner := &script.Runner{
+		Executor: scriptExecutor{assistant: assistant},
+		DryRun:   dryRun,
+		BeforeStep: func(index int, step script.Step, command string) {
+			output.Printf("▶️  Step %d/%d: %s\n", index, len(s.Steps), command)
+		},
+		AfterStep: func(result script.StepResult) {
+			switch result.Status {
+			case script.StatusSkipped:
+				output.Printf("⏭️  Step %d skipped (runs on %s)\n", result.Index, result.When)
+			case script.StatusFailed:
+				if result.Error != "" {
+					output.Printf("❌ Step %d failed: %s\n", result.Index, result.Error)
+				} else {
+					output.Printf("❌ Step %d failed with exit code %d\n", result.Index, result.ExitCode)
+				}
+			case script.StatusPlanned:
+				output.Printf("📝 Step %d would run (no dry-run mode)\n", result.Index)
+			}
+		},
+	}
+
+	if dryRun {
+		output.Printf("🔍 Dry run of script %s - nothing will be changed\n", s.Name)
+		return runner.Run(s, values)
+	}
+
+	// Run steps locally so every change is tracked by this process's undo session
+	forwarder := assistant.Forwarder
+	assistant.Forwarder = nil
+	defer func() { assistant.Forwarder = forwarder }()
+
+	undoManager := assistant.SystemHooks.UndoManager
+	session, nested, end := undoManager.Begin("script "+s.Name, s.Description)
+	session.Metadata["script"] = s.Name
+
+	output.Printf("🚀 Running script %s\n", s.Name)
+	result := runner.Run(s, values)
+
+	if err := end(); err != nil {
+		output.Printf("⚠️ Warning: Failed to save undo session: %v\n", err)
+	} else if !nested && len(session.Operations) > 0 {
+		result.UndoSessionID = session.ID
+	}
+
+	return result
+}
+
+// printScriptSummary prints the outcome of a script run
+func printScriptSummary(result *script.RunResult) {
+	counts := make(map[script.StepStatus]int)
+	for _, step := range result.Steps {
+		counts[step.Status]++
+	}
+
+	fmt.Println()
+	if result.DryRun {
+		output.Printf("🔍 Dry run: %d previewed, %d planned, %d skipped, %d failed\n",
+			counts[script.StatusPreviewed], counts[script.StatusPlanned], counts[script.StatusSkipped], counts[script.StatusFailed])
+		return
+	}
+
+	if result.OK {
+		output.Printf("✅ Script %s finished in %v\n", result.Script, result.Duration.Round(time.Millisecond))
+	}
+	output.Printf("📊 %d ok, %d failed, %d skipped\n", counts[script.StatusOK], counts[script.StatusFailed], counts[script.StatusSkipped])
+	if result.UndoSessionID != "" {
+		output.Printf("↩️  Undo everything with: ena undo-session %s\n", result.UndoSessionID)
+	}
+}
@@ -2,7 +2,8 @@
  * Search Commands Package
  *
  * Provides search and deletion command definitions for the Ena virtual assistant,
- * including file searching and safe file deletion with confirmation.
+ * including file searching by name or content and safe file deletion with
+ * confirmation.
  *
  * Author: KleaSCM
  * Email: KleaSCM@gmail.com
@@ -13,10 +14,18 @@
 package commands
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"ena/internal/core"
+	"ena/internal/output"
+	"ena/internal/search"
+	"ena/pkg/system"
 )
 
 // setupSearchCommands sets up all search and deletion related commands
@@ -25,53 +34,216 @@ func setupSearchCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 
 	// File search command
 	var searchCmd = &cobra.Command{
-		Use:   "search <pattern> <directory>",
-		Short: "Search for files",
-		Long: `Search for files matching the specified pattern.
+		Use:     "search <pattern> <directory> | search --content <regex> [paths...]",
+		GroupID: "search",
+		Short:   "Search for files by name or by content",
+		Long: `Search for files whose names match the specified pattern, or with --content
+for files whose contents match a regular expression.
+
+A content search scans files in parallel and shows each file's matching
+lines, with line and column, as soon as the file is done. Binary files,
+files over --max-size, hidden files and paths listed in .gitignore, .ignore
+or .enaignore files are skipped. Its result holds the matching paths, so
+interactive pipelines can hand them to batch commands, and $last can hand
+them to a pattern operation.
 
 Examples:
   ena search "*.txt" /home/user
   ena search "*.go" /home/user/projects
-  ena search "config" /etc`,
-		Args: cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+  ena search "config" /etc
+  ena search --content "TODO|FIXME" ~/Projects --name "*.go" --context 2
+  ena search --content "api[_-]?key" . --ignore-case --hidden
+  ena search --content "DEBUG" logs --files-with-matches | xargs wc -l
+  search --content "deprecated" ~/notes | batch-move ~/notes/archive
+  search --content "draft" ~/notes ; execute-operation pattern_1234567890 $last`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if content, _ := cmd.Flags().GetString("content"); content != "" {
+				return nil
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if content, _ := cmd.Flags().GetString("content"); content != "" {
+				return searchContent(cmd, assistant.App.Files, content, args)
+			}
 			result, err := assistant.ProcessCommand("search", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgCyan).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgCyan).Println(result)
+			return nil
 		},
 	}
 
+	searchCmd.Flags().String("content", "", "Search file contents for this regular expression")
+	searchCmd.Flags().Bool("ignore-case", false, "Match the regular expression case-insensitively")
+	searchCmd.Flags().Int("context", 0, "Lines of context to show around each match")
+	searchCmd.Flags().StringSlice("name", nil, "Only search files whose names match these patterns (e.g., *.go)")
+	searchCmd.Flags().String("max-size", "10MB", "Skip files larger than this (0 = no limit)")
+	searchCmd.Flags().Int("max-count", 0, "Stop after this many matches in one file (0 = no limit)")
+	searchCmd.Flags().Bool("hidden", false, "Search hidden files and directories too")
+	searchCmd.Flags().Bool("no-ignore", false, "Search paths listed in .gitignore, .ignore and .enaignore files")
+	searchCmd.Flags().Int("workers", 0, "Files to scan at once (0 = one per CPU)")
+	searchCmd.Flags().Bool("files-with-matches", false, "Print only the paths of matching files")
+
 	// File deletion command
 	var deleteCmd = &cobra.Command{
-		Use:   "delete <path> [--force]",
-		Short: "Delete files",
-		Long: `Delete the specified file.
+		Use:     "delete <path> [--force]",
+		GroupID: "search",
+		Short:   "Delete files",
+		Long: `Move the specified file to the trash, where "ena trash" can restore it.
+Use --permanent to delete it outright.
 
 ⚠️  Note: Confirmation prompt will be displayed unless --force flag is used.
 
 Examples:
   ena delete /path/to/file.txt
-  ena delete /path/to/file.txt --force`,
+  ena delete /path/to/file.txt --force
+  ena delete /path/to/file.txt --force --permanent`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			force, _ := cmd.Flags().GetBool("force")
 			if force {
 				args = append(args, "--force")
 			}
+			if permanent, _ := cmd.Flags().GetBool("permanent"); permanent {
+				args = append(args, "--permanent")
+			}
 			result, err := assistant.ProcessCommand("delete", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
 	deleteCmd.Flags().Bool("force", false, "Force deletion without confirmation")
+	deleteCmd.Flags().Bool("permanent", false, "Delete outright instead of moving to the trash")
 
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(deleteCmd)
 }
+
+// searchContent runs a content search, printing each file's matches as it is found
+func searchContent(cmd *cobra.Command, files *system.FileManager, expr string, roots []string) error {
+	ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+	contextLines, _ := cmd.Flags().GetInt("context")
+	names, _ := cmd.Flags().GetStringSlice("name")
+	maxSize, _ := cmd.Flags().GetString("max-size")
+	maxCount, _ := cmd.Flags().GetInt("max-count")
+	hidden, _ := cmd.Flags().GetBool("hidden")
+	noIgnore, _ := cmd.Flags().GetBool("no-ignore")
+	workers, _ := cmd.Flags().GetInt("workers")
+	filesOnly, _ := cmd.Flags().GetBool("files-with-matches")
+
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return reportErrorCode(exitUsage, "❌ Invalid regular expression: %v\n", err)
+	}
+	maxFileSize, err := search.ParseSize(maxSize)
+	if err != nil {
+		return reportErrorCode(exitUsage, "❌ Invalid --max-size: %v\n", err)
+	}
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	options := search.Options{
+		Pattern:     pattern,
+		Context:     contextLines,
+		MaxFileSize: maxFileSize,
+		MaxMatches:  maxCount,
+		Names:       names,
+		Hidden:      hidden,
+		NoIgnore:    noIgnore,
+		Workers:     workers,
+	}
+
+	result, err := files.SearchContent(roots, options, func(file search.FileResult) {
+		if filesOnly {
+			fmt.Println(file.Path)
+			return
+		}
+		showContentMatches(file, pattern)
+	})
+	if err != nil {
+		return reportError("❌ Error: %v\n", err)
+	}
+
+	reportResult(result)
+	if filesOnly {
+		return nil
+	}
+
+	summary := result.Summary
+	if summary.FilesMatched == 0 {
+		output.Printf("😅 No matches for \"%s\" in %d files\n", pattern, summary.FilesSearched)
+	} else {
+		output.Printf("🔍 %d matches in %d of %d files (%s)\n", summary.Matches, summary.FilesMatched,
+			summary.FilesSearched, summary.Duration.String())
+	}
+	if summary.SkippedBinary > 0 {
+		output.Printf("⏭️  Skipped %d binary files\n", summary.SkippedBinary)
+	}
+	if summary.SkippedLarge > 0 {
+		output.Printf("⏭️  Skipped %d files over %s\n", summary.SkippedLarge, maxSize)
+	}
+	if len(summary.Errors) > 0 {
+		output.Printf("⚠️  %d paths could not be read\n", len(summary.Errors))
+	}
+	return nil
+}
+
+// showContentMatches prints a file's matching lines with their context,
+// merging context that overlaps and marking gaps with --
+func showContentMatches(file search.FileResult, pattern *regexp.Regexp) {
+	lines := map[int]string{}
+	columns := map[int]int{}
+	for _, match := range file.Matches {
+		first := match.Line - len(match.Before)
+		for i, text := range match.Before {
+			lines[first+i] = text
+		}
+		for i, text := range match.After {
+			lines[match.Line+1+i] = text
+		}
+	}
+	for _, match := range file.Matches {
+		lines[match.Line] = match.Text
+		columns[match.Line] = match.Column
+	}
+
+	numbers := make([]int, 0, len(lines))
+	for number := range lines {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	highlight := color.New(color.FgRed, color.Bold)
+	color.New(color.FgCyan).Printf(output.Prose("📄 %s\n"), file.Path)
+	for i, number := range numbers {
+		if i > 0 && number > numbers[i-1]+1 {
+			fmt.Println("   --")
+		}
+		column, matched := columns[number]
+		if !matched {
+			fmt.Printf("   %d-   %s\n", number, lines[number])
+			continue
+		}
+
+		text := lines[number]
+		var shown strings.Builder
+		last := 0
+		for _, location := range pattern.FindAllStringIndex(text, -1) {
+			shown.WriteString(text[last:location[0]])
+			shown.WriteString(highlight.Sprint(text[location[0]:location[1]]))
+			last = location[1]
+		}
+		shown.WriteString(text[last:])
+		fmt.Printf("   %d:%d: %s\n", number, column, shown.String())
+	}
+}
@@ -16,28 +16,21 @@ import (
 	"fmt"
 	"strings"
 
+	"ena/internal/core"
+	"ena/internal/input"
+	"ena/internal/output"
 	"ena/internal/suggestions"
 
 	"github.com/spf13/cobra"
 )
 
-// Global analytics instance
-var globalAnalytics *suggestions.UsageAnalytics
-
-// getGlobalAnalytics returns the global analytics instance
-func getGlobalAnalytics() *suggestions.UsageAnalytics {
-	if globalAnalytics == nil {
-		globalAnalytics = suggestions.NewUsageAnalytics()
-	}
-	return globalAnalytics
-}
-
 // setupSuggestionsCommands adds suggestion-related commands to the root command
-func setupSuggestionsCommands(rootCmd *cobra.Command) {
+func setupSuggestionsCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Main suggestions command
 	suggestCmd := &cobra.Command{
-		Use:   "suggest",
-		Short: "Get intelligent suggestions based on your usage patterns",
+		Use:     "suggest",
+		GroupID: "suggest",
+		Short:   "Get intelligent suggestions based on your usage patterns",
 		Long: `Ena's smart suggestion system analyzes your command history and usage patterns
 to provide intelligent recommendations for improved productivity and workflow optimization.
 
@@ -46,8 +39,8 @@ Examples:
   ena suggest --limit 5          # Show top 5 suggestions
   ena suggest --category safety  # Show safety-related suggestions
   ena suggest --type workflow    # Show workflow suggestions`,
-		Run: func(cmd *cobra.Command, args []string) {
-			analytics := getGlobalAnalytics()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			analytics := assistant.App.Analytics
 
 			limit, _ := cmd.Flags().GetInt("limit")
 			category, _ := cmd.Flags().GetString("category")
@@ -76,24 +69,26 @@ Examples:
 				}
 				suggestionsList = filtered
 			}
+			reportResult(append([]suggestions.SmartSuggestion{}, suggestionsList...))
 
 			if len(suggestionsList) == 0 {
-				fmt.Println("🌸 No suggestions available right now. Keep using Ena and I'll learn your patterns!")
-				return
+				output.Printf("🌸 No suggestions available right now. Keep using Ena and I'll learn your patterns!\n")
+				return nil
 			}
 
-			fmt.Printf("🌸 Here are my smart suggestions for you! (╹◡╹)♡\n\n")
+			output.Printf("🌸 Here are my smart suggestions for you! (╹◡╹)♡\n\n")
 
 			for i, suggestion := range suggestionsList {
 				fmt.Printf("%d. %s\n", i+1, suggestion.Title)
 				fmt.Printf("   %s\n", suggestion.Description)
 				if suggestion.Command != "" {
-					fmt.Printf("   💡 Try: %s\n", suggestion.Command)
+					output.Printf("   💡 Try: %s\n", suggestion.Command)
 				}
-				fmt.Printf("   📊 Confidence: %.0f%% | Priority: %d/10 | Category: %s\n",
+				output.Printf("   📊 Confidence: %.0f%% | Priority: %d/10 | Category: %s\n",
 					suggestion.Confidence*100, suggestion.Priority, suggestion.Category)
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
@@ -104,8 +99,9 @@ Examples:
 
 	// Stats command
 	statsCmd := &cobra.Command{
-		Use:   "stats",
-		Short: "Show usage statistics and analytics",
+		Use:     "stats",
+		GroupID: "suggest",
+		Short:   "Show usage statistics and analytics",
 		Long: `Display comprehensive usage statistics including command frequency,
 file operations, patterns discovered, and performance metrics.
 
@@ -113,33 +109,34 @@ Examples:
   ena stats                    # Show all statistics
   ena stats --commands         # Show command statistics only
   ena stats --patterns        # Show discovered patterns only`,
-		Run: func(cmd *cobra.Command, args []string) {
-			analytics := getGlobalAnalytics()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			analytics := assistant.App.Analytics
 
 			showCommands, _ := cmd.Flags().GetBool("commands")
 			showPatterns, _ := cmd.Flags().GetBool("patterns")
 			showFileOps, _ := cmd.Flags().GetBool("fileops")
 
 			stats := analytics.GetUsageStats()
+			reportResult(stats)
 
-			fmt.Println("🌸 Ena's Analytics Dashboard (╹◡╹)♡")
+			output.Printf("🌸 Ena's Analytics Dashboard (╹◡╹)♡\n")
 			fmt.Println("=====================================")
 
 			if !showCommands && !showPatterns && !showFileOps {
 				// Show all stats
-				fmt.Printf("📊 Total Commands Executed: %v\n", stats["total_commands"])
-				fmt.Printf("📁 Total File Operations: %v\n", stats["total_file_operations"])
-				fmt.Printf("⏱️  Average Command Duration: %v\n", stats["average_command_duration"])
-				fmt.Printf("✅ Success Rate: %.1f%%\n", stats["success_rate"])
-				fmt.Printf("💾 Total File Size Processed: %v\n", stats["total_file_size_processed"])
-				fmt.Printf("🔍 Patterns Discovered: %v\n", stats["patterns_discovered"])
-				fmt.Printf("💡 Suggestions Generated: %v\n", stats["suggestions_generated"])
-				fmt.Printf("📅 Analysis Period: %v\n", stats["analysis_period"])
+				output.Printf("📊 Total Commands Executed: %v\n", stats["total_commands"])
+				output.Printf("📁 Total File Operations: %v\n", stats["total_file_operations"])
+				output.Printf("⏱️  Average Command Duration: %v\n", stats["average_command_duration"])
+				output.Printf("✅ Success Rate: %.1f%%\n", stats["success_rate"])
+				output.Printf("💾 Total File Size Processed: %v\n", stats["total_file_size_processed"])
+				output.Printf("🔍 Patterns Discovered: %v\n", stats["patterns_discovered"])
+				output.Printf("💡 Suggestions Generated: %v\n", stats["suggestions_generated"])
+				output.Printf("📅 Analysis Period: %v\n", stats["analysis_period"])
 				fmt.Println()
 
 				// Most used commands
 				if mostUsed, ok := stats["most_used_commands"].([]map[string]interface{}); ok {
-					fmt.Println("🔥 Most Used Commands:")
+					output.Printf("🔥 Most Used Commands:\n")
 					for i, cmd := range mostUsed {
 						if i >= 5 {
 							break
@@ -151,7 +148,7 @@ Examples:
 
 				// Most common file operations
 				if mostFileOps, ok := stats["most_common_file_operations"].([]map[string]interface{}); ok {
-					fmt.Println("📁 Most Common File Operations:")
+					output.Printf("📁 Most Common File Operations:\n")
 					for i, op := range mostFileOps {
 						if i >= 5 {
 							break
@@ -163,12 +160,12 @@ Examples:
 			} else {
 				// Show specific stats
 				if showCommands {
-					fmt.Printf("📊 Total Commands: %v\n", stats["total_commands"])
-					fmt.Printf("⏱️  Average Duration: %v\n", stats["average_command_duration"])
-					fmt.Printf("✅ Success Rate: %.1f%%\n", stats["success_rate"])
+					output.Printf("📊 Total Commands: %v\n", stats["total_commands"])
+					output.Printf("⏱️  Average Duration: %v\n", stats["average_command_duration"])
+					output.Printf("✅ Success Rate: %.1f%%\n", stats["success_rate"])
 
 					if mostUsed, ok := stats["most_used_commands"].([]map[string]interface{}); ok {
-						fmt.Println("\n🔥 Most Used Commands:")
+						output.Printf("\n🔥 Most Used Commands:\n")
 						for i, cmd := range mostUsed {
 							fmt.Printf("   %d. %s (%v times)\n", i+1, cmd["name"], cmd["count"])
 						}
@@ -176,11 +173,11 @@ Examples:
 				}
 
 				if showFileOps {
-					fmt.Printf("📁 Total File Operations: %v\n", stats["total_file_operations"])
-					fmt.Printf("💾 Total Size Processed: %v\n", stats["total_file_size_processed"])
+					output.Printf("📁 Total File Operations: %v\n", stats["total_file_operations"])
+					output.Printf("💾 Total Size Processed: %v\n", stats["total_file_size_processed"])
 
 					if mostFileOps, ok := stats["most_common_file_operations"].([]map[string]interface{}); ok {
-						fmt.Println("\n📁 Most Common File Operations:")
+						output.Printf("\n📁 Most Common File Operations:\n")
 						for i, op := range mostFileOps {
 							fmt.Printf("   %d. %s (%v times)\n", i+1, op["name"], op["count"])
 						}
@@ -188,10 +185,11 @@ Examples:
 				}
 
 				if showPatterns {
-					fmt.Printf("🔍 Patterns Discovered: %v\n", stats["patterns_discovered"])
-					fmt.Printf("💡 Suggestions Generated: %v\n", stats["suggestions_generated"])
+					output.Printf("🔍 Patterns Discovered: %v\n", stats["patterns_discovered"])
+					output.Printf("💡 Suggestions Generated: %v\n", stats["suggestions_generated"])
 				}
 			}
+			return nil
 		},
 	}
 
@@ -202,8 +200,9 @@ Examples:
 
 	// Feedback command
 	feedbackCmd := &cobra.Command{
-		Use:   "feedback <suggestion_id> <feedback>",
-		Short: "Provide feedback on a suggestion",
+		Use:     "feedback <suggestion_id> <feedback>",
+		GroupID: "suggest",
+		Short:   "Provide feedback on a suggestion",
 		Long: `Provide feedback on suggestions to help Ena learn and improve.
 Valid feedback values: helpful, not_helpful, dismiss
 
@@ -212,8 +211,8 @@ Examples:
   ena feedback workflow_456 not_helpful
   ena feedback optimization_789 dismiss`,
 		Args: cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
-			analytics := getGlobalAnalytics()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			analytics := assistant.App.Analytics
 
 			suggestionID := args[0]
 			feedback := args[1]
@@ -221,61 +220,70 @@ Examples:
 			// Validate feedback
 			validFeedback := []string{"helpful", "not_helpful", "dismiss"}
 			if !contains(validFeedback, feedback) {
-				fmt.Printf("❌ Invalid feedback. Must be one of: %s\n", strings.Join(validFeedback, ", "))
-				return
+				return reportErrorCode(exitUsage, "❌ Invalid feedback. Must be one of: %s\n", strings.Join(validFeedback, ", "))
 			}
 
 			err := analytics.ProvideFeedback(suggestionID, feedback)
 			if err != nil {
-				fmt.Printf("❌ Error providing feedback: %v\n", err)
-				return
+				return reportError("❌ Error providing feedback: %v\n", err)
 			}
 
-			fmt.Printf("🌸 Thank you for the feedback! I'll use this to improve my suggestions. (╹◡╹)♡\n")
+			output.Printf("🌸 Thank you for the feedback! I'll use this to improve my suggestions. (╹◡╹)♡\n")
+			return nil
 		},
 	}
 
 	// Workflow command
 	workflowCmd := &cobra.Command{
-		Use:   "workflow",
-		Short: "Show workflow optimization suggestions",
+		Use:     "workflow",
+		GroupID: "suggest",
+		Short:   "Show workflow optimization suggestions",
 		Long: `Display workflow suggestions based on your command patterns.
 Ena analyzes your command sequences to suggest workflow optimizations.
 
 Examples:
   ena workflow                    # Show workflow suggestions
-  ena workflow --create          # Create a script from common workflow`,
-		Run: func(cmd *cobra.Command, args []string) {
-			analytics := getGlobalAnalytics()
+  ena workflow --create          # Save each suggested workflow as a script`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			analytics := assistant.App.Analytics
 
 			createScript, _ := cmd.Flags().GetBool("create")
 
 			suggestionsList := analytics.GetWorkflowSuggestions()
+			reportResult(append([]suggestions.SmartSuggestion{}, suggestionsList...))
 
 			if len(suggestionsList) == 0 {
-				fmt.Println("🌸 No workflow patterns detected yet. Keep using Ena and I'll discover your workflows!")
-				return
+				output.Printf("🌸 No workflow patterns detected yet. Keep using Ena and I'll discover your workflows!\n")
+				return nil
 			}
 
-			fmt.Println("🌸 Workflow Optimization Suggestions (╹◡╹)♡")
+			output.Printf("🌸 Workflow Optimization Suggestions (╹◡╹)♡\n")
 			fmt.Println("==========================================")
 
 			for i, suggestion := range suggestionsList {
 				fmt.Printf("%d. %s\n", i+1, suggestion.Title)
 				fmt.Printf("   %s\n", suggestion.Description)
 				if suggestion.Command != "" {
-					fmt.Printf("   💡 Command: %s\n", suggestion.Command)
+					output.Printf("   💡 Command: %s\n", suggestion.Command)
 				}
-				fmt.Printf("   📊 Confidence: %.0f%% | Priority: %d/10\n",
+				output.Printf("   📊 Confidence: %.0f%% | Priority: %d/10\n",
 					suggestion.Confidence*100, suggestion.Priority)
 
 				if createScript && suggestion.Command != "" {
-					fmt.Printf("   🚀 Creating script...\n")
-					// Here you would implement script creation
-					fmt.Printf("   ✅ Script created successfully!\n")
+					// Suggestions are ready-made "script create" command lines
+					output.Printf("   🚀 Creating script...\n   ")
+					words, err := input.Split(suggestion.Command)
+					if err != nil {
+						reportError("❌ Error reading suggestion: %v\n", err)
+						continue
+					}
+					if executeLine(assistant, words) != 0 {
+						reportError("❌ Failed to create script for suggestion %s\n", suggestion.ID)
+					}
 				}
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
@@ -283,45 +291,48 @@ Examples:
 
 	// Optimization command
 	optimizeCmd := &cobra.Command{
-		Use:   "optimize",
-		Short: "Show system optimization suggestions",
+		Use:     "optimize",
+		GroupID: "suggest",
+		Short:   "Show system optimization suggestions",
 		Long: `Display system optimization suggestions based on your usage patterns.
 Ena analyzes your command history to suggest performance improvements.
 
 Examples:
   ena optimize                   # Show optimization suggestions
   ena optimize --apply           # Apply suggested optimizations`,
-		Run: func(cmd *cobra.Command, args []string) {
-			analytics := getGlobalAnalytics()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			analytics := assistant.App.Analytics
 
 			apply, _ := cmd.Flags().GetBool("apply")
 
 			suggestionsList := analytics.GetOptimizationSuggestions()
+			reportResult(append([]suggestions.SmartSuggestion{}, suggestionsList...))
 
 			if len(suggestionsList) == 0 {
-				fmt.Println("🌸 Your system is already optimized! Great job! (╹◡╹)♡")
-				return
+				output.Printf("🌸 Your system is already optimized! Great job! (╹◡╹)♡\n")
+				return nil
 			}
 
-			fmt.Println("🌸 System Optimization Suggestions (╹◡╹)♡")
+			output.Printf("🌸 System Optimization Suggestions (╹◡╹)♡\n")
 			fmt.Println("=======================================")
 
 			for i, suggestion := range suggestionsList {
 				fmt.Printf("%d. %s\n", i+1, suggestion.Title)
 				fmt.Printf("   %s\n", suggestion.Description)
 				if suggestion.Command != "" {
-					fmt.Printf("   💡 Command: %s\n", suggestion.Command)
+					output.Printf("   💡 Command: %s\n", suggestion.Command)
 				}
-				fmt.Printf("   📊 Confidence: %.0f%% | Priority: %d/10\n",
+				output.Printf("   📊 Confidence: %.0f%% | Priority: %d/10\n",
 					suggestion.Confidence*100, suggestion.Priority)
 
 				if apply && suggestion.Command != "" {
-					fmt.Printf("   🚀 Applying optimization...\n")
+					output.Printf("   🚀 Applying optimization...\n")
 					// Here you would implement optimization application
-					fmt.Printf("   ✅ Optimization applied successfully!\n")
+					output.Printf("   ✅ Optimization applied successfully!\n")
 				}
 				fmt.Println()
 			}
+			return nil
 		},
 	}
 
@@ -17,6 +17,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"ena/internal/core"
+	"ena/internal/output"
 )
 
 // setupSystemCommands sets up all system-related commands
@@ -24,8 +25,9 @@ func setupSystemCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// System operation commands - managing system with care ✨
 
 	var systemCmd = &cobra.Command{
-		Use:   "system [operation]",
-		Short: "System operation commands",
+		Use:     "system [operation]",
+		GroupID: "system",
+		Short:   "System operation commands",
 		Long: `Restart, shutdown, sleep, and display information about the system.
 
 ⚠️  Warning: These commands affect the entire system. Use with caution.
@@ -36,13 +38,13 @@ Examples:
   ena system sleep
   ena system info`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("system", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -52,16 +54,16 @@ Examples:
 		Short: "Restart the system",
 		Long:  "⚠️  Restart the entire system. Save your work before executing.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
-			color.New(color.FgRed, color.Bold).Println("⚠️  Warning: System will restart!")
-			color.New(color.FgRed, color.Bold).Println("⚠️  Unsaved work will be lost!")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			color.New(color.FgRed, color.Bold).Print(output.Prose("⚠️  Warning: System will restart!\n"))
+			color.New(color.FgRed, color.Bold).Print(output.Prose("⚠️  Unsaved work will be lost!\n"))
 
 			result, err := assistant.ProcessCommand("system", []string{"restart"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
@@ -71,16 +73,16 @@ Examples:
 		Short: "Shutdown the system",
 		Long:  "⚠️  Shutdown the entire system. Save your work before executing.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
-			color.New(color.FgRed, color.Bold).Println("⚠️  Warning: System will shutdown!")
-			color.New(color.FgRed, color.Bold).Println("⚠️  Unsaved work will be lost!")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			color.New(color.FgRed, color.Bold).Print(output.Prose("⚠️  Warning: System will shutdown!\n"))
+			color.New(color.FgRed, color.Bold).Print(output.Prose("⚠️  Unsaved work will be lost!\n"))
 
 			result, err := assistant.ProcessCommand("system", []string{"shutdown"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
@@ -90,13 +92,13 @@ Examples:
 		Short: "Put system to sleep",
 		Long:  "Put the system into sleep mode.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("system", []string{"sleep"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgBlue).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgBlue).Println(result)
+			return nil
 		},
 	}
 
@@ -106,13 +108,13 @@ Examples:
 		Short: "Show system information",
 		Long:  "Display detailed information about the current system.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("system", []string{"info"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgCyan).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgCyan).Println(result)
+			return nil
 		},
 	}
 
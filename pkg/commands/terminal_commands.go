@@ -26,8 +26,9 @@ func setupTerminalCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Terminal operation commands - handling command line with care ✨
 
 	var terminalCmd = &cobra.Command{
-		Use:   "terminal [operation] [args...]",
-		Short: "Terminal operation commands",
+		Use:     "terminal [operation] [args...]",
+		GroupID: "terminal",
+		Short:   "Terminal operation commands",
 		Long: `Open, close terminals, execute commands, and change directories.
 
 Examples:
@@ -36,13 +37,13 @@ Examples:
   ena terminal execute "ls -la"
   ena terminal cd /home/user`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("terminal", args)
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -52,13 +53,13 @@ Examples:
 		Short: "Open a new terminal",
 		Long:  "Open a new terminal window.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("terminal", []string{"open"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
@@ -68,13 +69,13 @@ Examples:
 		Short: "Close the terminal",
 		Long:  "Close the current terminal session.",
 		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("terminal", []string{"close"})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgYellow).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgYellow).Println(result)
+			return nil
 		},
 	}
 
@@ -84,14 +85,14 @@ Examples:
 		Short: "Execute a command",
 		Long:  "Execute the specified command in the terminal.",
 		Args:  cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			command := strings.Join(args, " ")
 			result, err := assistant.ProcessCommand("terminal", []string{"execute", command})
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgCyan).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgCyan).Println(result)
+			return nil
 		},
 	}
 
@@ -101,13 +102,13 @@ Examples:
 		Short: "Change directory",
 		Long:  "Change to the specified directory.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("terminal", append([]string{"cd"}, args...))
 			if err != nil {
-				color.New(color.FgRed).Printf("❌ Error: %v\n", err)
-			} else {
-				color.New(color.FgGreen).Println(result)
+				return reportError("❌ Error: %v\n", err)
 			}
+			color.New(color.FgGreen).Println(result)
+			return nil
 		},
 	}
 
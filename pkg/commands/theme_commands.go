@@ -15,14 +15,25 @@ import (
 	"github.com/spf13/cobra"
 
 	"ena/internal/core"
+	"ena/internal/theme"
 )
 
+// themeOperations are the operations the theme command takes
+var themeOperations = []string{"list", "current", "set", "preview", "info", "export", "demo", "toggle", "create", "delete", "save", "load", "setcolor", "validate", "cache"}
+
+// themeNameOperations are the operations whose next argument is a theme name
+var themeNameOperations = map[string]bool{
+	"set": true, "preview": true, "info": true, "export": true,
+	"delete": true, "save": true, "setcolor": true, "validate": true,
+}
+
 // setupThemeCommands sets up theme management commands
 func setupThemeCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Theme command
 	var themeCmd = &cobra.Command{
-		Use:   "theme <operation> [theme_name]",
-		Short: "Manage themes and color schemes with dark/light mode support",
+		Use:     "theme <operation> [theme_name]",
+		GroupID: "theme",
+		Short:   "Manage themes and color schemes with dark/light mode support",
 		Long: `Comprehensive theme management system with multiple color schemes and modes:
   • List all available themes with descriptions
   • Set and preview themes with live color samples
@@ -62,17 +73,35 @@ Examples:
   ena theme preview monokai         # Preview Monokai theme
   ena theme toggle                  # Toggle light/dark mode
   ena theme demo                    # Show all themes`,
-		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		ValidArgsFunction: completeTheme(assistant.App.Themes),
+		Args:              cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			result, err := assistant.ProcessCommand("theme", args)
 			if err != nil {
-				cmd.PrintErrln("❌ Error:", err)
-				return
+				return reportError("❌ Error: %v\n", err)
 			}
 
 			cmd.Println(result)
+			return nil
 		},
 	}
 
 	rootCmd.AddCommand(themeCmd)
 }
+
+// completeTheme completes the theme operation, then the theme it acts on
+func completeTheme(themeManager *theme.ThemeManager) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case len(args) == 0:
+			return matchCandidates(themeOperations, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case len(args) == 1 && themeNameOperations[args[0]]:
+			return matchCandidates(themeCandidates(themeManager)(), toComplete), cobra.ShellCompDirectiveNoFileComp
+		case len(args) == 1 && args[0] == "load":
+			return nil, cobra.ShellCompDirectiveDefault
+		case len(args) == 1 && args[0] == "cache":
+			return matchCandidates([]string{"clear", "stats"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,272 @@
+/**
+ * Trash Commands
+ *
+ * Provides commands for the freedesktop.org trash that deleted files go to:
+ * listing what is there, restoring items to where they came from, and
+ * emptying or purging it for good.
+ *
+ * Author: KleaSCM
+ * Email: KleaSCM@gmail.com
+ * File: trash_commands.go
+ * Description: Trash listing, restore, empty and purge command definitions
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ena/internal/app"
+	"ena/internal/audit"
+	"ena/internal/output"
+	"ena/internal/trash"
+)
+
+// setupTrashCommands sets up trash management commands
+func setupTrashCommands(rootCmd *cobra.Command, services *app.App) {
+	can := services.Trash
+
+	// Trash parent command
+	trashCmd := &cobra.Command{
+		Use:     "trash",
+		GroupID: "trash",
+		Short:   "List, restore and empty the trash",
+		Long: fmt.Sprintf(`Files deleted by Ena go to the freedesktop.org trash, shared with your file
+manager, instead of being removed. Files on the home volume go to %s;
+files on other volumes go to that volume's .Trash-<uid> directory.
+
+Pass --permanent to delete, folder delete, batch-delete and friends to skip
+the trash. Pattern and organizer rules do the same with a delete action whose
+parameters include permanent: "true".
+
+Examples:
+  ena trash list
+  ena trash restore notes.txt
+  ena trash restore ~/Documents/notes.txt --to ~/Desktop
+  ena trash purge --older-than 30d
+  ena trash empty`, can.HomeDir()),
+	}
+
+	// List command
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List what is in the trash, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			items, err := can.List()
+			if err != nil {
+				return reportError("❌ Error listing trash: %v\n", err)
+			}
+			if limit > 0 && len(items) > limit {
+				items = items[:limit]
+			}
+
+			reportResult(append([]*trash.Item{}, items...))
+			if len(items) == 0 {
+				output.Printf("🌸 The trash is empty\n")
+				return nil
+			}
+
+			var total int64
+			for _, item := range items {
+				total += item.Size
+			}
+			output.Printf("🗑️  %d items in the trash (%s)\n", len(items), formatBytes(total))
+			fmt.Println("================================")
+			for _, item := range items {
+				showTrashItem(item)
+			}
+			output.Printf("💡 Restore with: ena trash restore <name>\n")
+			return nil
+		},
+	}
+
+	listCmd.Flags().Int("limit", 0, "Limit number of results")
+
+	// Restore command
+	restoreCmd := &cobra.Command{
+		Use:   "restore <name|path> [name|path...]",
+		Short: "Put items back where they were deleted from",
+		Long: `Restore items from the trash to where they were deleted from, or into the
+directory given with --to. An item is named by its name in "ena trash list"
+or by the path it was deleted from; when a path was deleted more than once,
+the newest copy comes back.
+
+Examples:
+  ena trash restore notes.txt
+  ena trash restore ~/Documents/report.pdf
+  ena trash restore notes.txt notes.2.txt --to ~/Desktop
+  ena trash restore notes.txt --overwrite`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeValues(trashCandidates(can)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dest, _ := cmd.Flags().GetString("to")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+			restored := []map[string]string{}
+			for _, query := range args {
+				items, err := can.Find(query)
+				if err != nil {
+					reportError("❌ Error: %v\n", err)
+					continue
+				}
+
+				item := items[0]
+				target, err := can.Restore(item, dest, overwrite)
+				audit.RecordFile("trash", "restore", item.Trash, target, err)
+				if err != nil {
+					reportError("❌ Error restoring %s: %v\n", query, err)
+					continue
+				}
+
+				restored = append(restored, map[string]string{"name": item.Name, "path": target})
+				output.Printf("✅ Restored %s → %s\n", item.Name, target)
+			}
+
+			reportResult(map[string]interface{}{"restored": restored})
+			return nil
+		},
+	}
+
+	restoreCmd.Flags().String("to", "", "Restore into this directory, or to this path, instead")
+	restoreCmd.Flags().Bool("overwrite", false, "Replace whatever is at the restore path")
+
+	// Empty command
+	emptyCmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Delete everything in the trash for good",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			confirm, _ := cmd.Flags().GetBool("confirm")
+
+			items, err := can.List()
+			if err != nil {
+				return reportError("❌ Error listing trash: %v\n", err)
+			}
+			if len(items) == 0 {
+				reportResult(map[string]interface{}{"purged": 0})
+				output.Printf("🌸 The trash is already empty\n")
+				return nil
+			}
+
+			if !confirm {
+				output.Printf("⚠️  This will permanently delete %d items from the trash\n", len(items))
+				fmt.Print("Type 'yes' to confirm: ")
+				var response string
+				fmt.Scanln(&response)
+				if strings.ToLower(response) != "yes" {
+					return reportError("❌ Operation cancelled\n")
+				}
+			}
+
+			return purgeTrashItems(can, items)
+		},
+	}
+
+	emptyCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+
+	// Purge command
+	purgeCmd := &cobra.Command{
+		Use:   "purge [name|path...] [--older-than age]",
+		Short: "Delete chosen or old items from the trash for good",
+		Long: `Delete items from the trash for good, either the ones named or every item
+deleted before --older-than, given as an age (30d, 2w, 12h) or a date
+(2006-01-02).
+
+Examples:
+  ena trash purge --older-than 30d
+  ena trash purge --older-than 2026-01-01
+  ena trash purge notes.txt old-project`,
+		ValidArgsFunction: completeValues(trashCandidates(can)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			olderThan, _ := cmd.Flags().GetString("older-than")
+			if olderThan == "" && len(args) == 0 {
+				return reportErrorCode(exitUsage, "❌ Invalid purge: name items or give --older-than\n")
+			}
+
+			var items []*trash.Item
+			if olderThan != "" {
+				cutoff, err := audit.ParseTime(olderThan, time.Now())
+				if err != nil {
+					return reportErrorCode(exitUsage, "❌ Invalid --older-than: %v\n", err)
+				}
+				all, err := can.List()
+				if err != nil {
+					return reportError("❌ Error listing trash: %v\n", err)
+				}
+				for _, item := range all {
+					if item.DeletedAt.Before(cutoff) {
+						items = append(items, item)
+					}
+				}
+			}
+			for _, query := range args {
+				found, err := can.Find(query)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				items = append(items, found...)
+			}
+
+			if len(items) == 0 {
+				reportResult(map[string]interface{}{"purged": 0})
+				output.Printf("🌸 Nothing in the trash to purge\n")
+				return nil
+			}
+			return purgeTrashItems(can, items)
+		},
+	}
+
+	purgeCmd.Flags().String("older-than", "", "Purge items deleted before this age or date")
+
+	trashCmd.AddCommand(listCmd, restoreCmd, emptyCmd, purgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
+
+// showTrashItem prints one line for an item in the trash
+func showTrashItem(item *trash.Item) {
+	icon := output.Prose("📄 ")
+	if item.IsDir {
+		icon = output.Prose("📁 ")
+	}
+	fmt.Printf("%s%s  (%s, deleted %s)\n", icon, item.Name, formatBytes(item.Size), item.DeletedAt.Format("2006-01-02 15:04"))
+	output.Printf("   📂 From: %s\n", item.Path)
+}
+
+// purgeTrashItems deletes items from the trash for good and reports how many went
+func purgeTrashItems(can *trash.Can, items []*trash.Item) error {
+	purged := 0
+	var freed int64
+	for _, item := range items {
+		err := can.Purge(item)
+		audit.RecordFile("trash", "purge", item.Path, "", err)
+		if err != nil {
+			reportError("❌ Error purging %s: %v\n", item.Name, err)
+			continue
+		}
+		purged++
+		freed += item.Size
+	}
+
+	reportResult(map[string]interface{}{"purged": purged, "freed": freed})
+	output.Printf("✅ Purged %d items from the trash, freeing %s\n", purged, formatBytes(freed))
+	return nil
+}
+
+// trashCandidates lists trash item names described by where they came from
+func trashCandidates(can *trash.Can) func() []string {
+	return func() []string {
+		items, _ := can.List()
+		var candidates []string
+		for _, item := range items {
+			candidates = append(candidates, candidate(item.Name, item.Path))
+		}
+		return candidates
+	}
+}
@@ -17,29 +17,20 @@ import (
 	"strings"
 	"time"
 
+	"ena/internal/app"
+	"ena/internal/output"
 	"ena/internal/undo"
 
 	"github.com/spf13/cobra"
 )
 
-// Global undo manager instance
-var globalUndoManager *undo.UndoManager
-
-// getGlobalUndoManager returns the global undo manager instance
-func getGlobalUndoManager() *undo.UndoManager {
-	if globalUndoManager == nil {
-		analytics := getGlobalAnalytics()
-		globalUndoManager = undo.NewUndoManager(analytics)
-	}
-	return globalUndoManager
-}
-
 // setupUndoCommands adds undo-related commands to the root command
-func setupUndoCommands(rootCmd *cobra.Command) {
+func setupUndoCommands(rootCmd *cobra.Command, services *app.App) {
 	// Undo history command
 	undoHistoryCmd := &cobra.Command{
-		Use:   "undo-history",
-		Short: "Show undo history and available operations",
+		Use:     "undo-history",
+		GroupID: "undo",
+		Short:   "Show undo history and available operations",
 		Long: `Display the undo history showing all tracked operations and sessions.
 Shows operations that can be undone with detailed information.
 
@@ -47,8 +38,8 @@ Examples:
   ena undo-history                    # Show all history
   ena undo-history --limit 10         # Show last 10 sessions
   ena undo-history --session <id>     # Show specific session details`,
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
 			limit, _ := cmd.Flags().GetInt("limit")
 			sessionID, _ := cmd.Flags().GetString("session")
@@ -57,74 +48,78 @@ Examples:
 				// Show specific session
 				session, err := undoManager.GetSession(sessionID)
 				if err != nil {
-					fmt.Printf("❌ Error getting session: %v\n", err)
-					return
+					return reportError("❌ Error getting session: %v\n", err)
 				}
 				showSessionDetails(session)
 			} else {
 				// Show all history
 				sessions := undoManager.GetHistory()
 
-				if len(sessions) == 0 {
-					fmt.Println("🌸 No undo history available")
-					return
-				}
-
-				fmt.Println("🌸 Undo History (╹◡╹)♡")
-				fmt.Println("========================")
-
 				displayCount := len(sessions)
 				if limit > 0 && limit < len(sessions) {
 					displayCount = limit
 				}
+				reportResult(append([]*undo.UndoSession{}, sessions[:displayCount]...))
+
+				if len(sessions) == 0 {
+					output.Printf("🌸 No undo history available\n")
+					return nil
+				}
+
+				output.Printf("🌸 Undo History (╹◡╹)♡\n")
+				fmt.Println("========================")
 
 				for i := 0; i < displayCount; i++ {
 					session := sessions[i]
 					fmt.Printf("%d. %s (%s)\n", i+1, session.Name, session.ID)
-					fmt.Printf("   📅 Created: %s\n", session.CreatedAt.Format("2006-01-02 15:04:05"))
-					fmt.Printf("   📊 Operations: %d | Undone: %t\n", len(session.Operations), session.Undone)
+					output.Printf("   📅 Created: %s\n", session.CreatedAt.Format("2006-01-02 15:04:05"))
+					output.Printf("   📊 Operations: %d | Undone: %t\n", len(session.Operations), session.Undone)
 					if session.Description != "" {
-						fmt.Printf("   📝 Description: %s\n", session.Description)
+						output.Printf("   📝 Description: %s\n", session.Description)
 					}
 					fmt.Println()
 				}
 			}
+			return nil
 		},
 	}
 
 	// Add flags
 	undoHistoryCmd.Flags().Int("limit", 0, "Limit number of sessions to show")
 	undoHistoryCmd.Flags().String("session", "", "Show specific session details")
+	undoHistoryCmd.RegisterFlagCompletionFunc("session", completeValues(undoSessionCandidates(services.Undo)))
 
 	// Undo operation command
 	undoOpCmd := &cobra.Command{
-		Use:   "undo-operation <operation-id>",
-		Short: "Undo a specific operation",
+		Use:     "undo-operation <operation-id>",
+		GroupID: "undo",
+		Short:   "Undo a specific operation",
 		Long: `Undo a specific operation by its ID. This will restore the file
 to its previous state before the operation was performed.
 
 Examples:
   ena undo-operation op_1234567890
   ena undo-operation op_1234567890 --dry-run`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(undoOperationCandidates(services.Undo), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
 			operationID := args[0]
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 			if dryRun {
-				fmt.Printf("🔍 Dry run: Would undo operation %s\n", operationID)
-				return
+				output.Printf("🔍 Dry run: Would undo operation %s\n", operationID)
+				return nil
 			}
 
 			err := undoManager.UndoOperation(operationID)
 			if err != nil {
-				fmt.Printf("❌ Error undoing operation: %v\n", err)
-				return
+				return reportError("❌ Error undoing operation: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully undone operation: %s\n", operationID)
+			output.Printf("✅ Successfully undone operation: %s\n", operationID)
+			return nil
 		},
 	}
 
@@ -132,33 +127,38 @@ Examples:
 
 	// Undo session command
 	undoSessionCmd := &cobra.Command{
-		Use:   "undo-session <session-id>",
-		Short: "Undo all operations in a session",
+		Use:     "undo-session <session-id>",
+		GroupID: "undo",
+		Short:   "Undo all operations in a session",
 		Long: `Undo all operations in a session. This will restore all files
 in the session to their previous states.
 
 Examples:
   ena undo-session session_1234567890
   ena undo-session session_1234567890 --dry-run`,
-		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstArg(undoSessionCandidates(services.Undo), cobra.ShellCompDirectiveNoFileComp),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
 			sessionID := args[0]
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 			if dryRun {
-				fmt.Printf("🔍 Dry run: Would undo session %s\n", sessionID)
-				return
+				output.Printf("🔍 Dry run: Would undo session %s\n", sessionID)
+				return nil
 			}
 
 			err := undoManager.UndoSession(sessionID)
 			if err != nil {
-				fmt.Printf("❌ Error undoing session: %v\n", err)
-				return
+				return reportError("❌ Error undoing session: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully undone session: %s\n", sessionID)
+			if session, err := undoManager.GetSession(sessionID); err == nil {
+				reportResult(session)
+			}
+			output.Printf("✅ Successfully undone session: %s\n", sessionID)
+			return nil
 		},
 	}
 
@@ -166,8 +166,9 @@ Examples:
 
 	// Start session command
 	startSessionCmd := &cobra.Command{
-		Use:   "start-session <name> [description]",
-		Short: "Start a new undo session",
+		Use:     "start-session <name> [description]",
+		GroupID: "undo",
+		Short:   "Start a new undo session",
 		Long: `Start a new undo session to group related operations.
 All subsequent operations will be tracked in this session.
 
@@ -175,8 +176,8 @@ Examples:
   ena start-session "File cleanup"
   ena start-session "Backup creation" "Creating backup of important files"`,
 		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
 			name := args[0]
 			description := ""
@@ -186,35 +187,41 @@ Examples:
 
 			session := undoManager.StartSession(name, description)
 
-			fmt.Printf("🌸 Started undo session: %s\n", session.Name)
-			fmt.Printf("🆔 Session ID: %s\n", session.ID)
+			output.Printf("🌸 Started undo session: %s\n", session.Name)
+			output.Printf("🆔 Session ID: %s\n", session.ID)
 			if description != "" {
-				fmt.Printf("📝 Description: %s\n", description)
+				output.Printf("📝 Description: %s\n", description)
 			}
+			return nil
 		},
 	}
 
 	// End session command
 	endSessionCmd := &cobra.Command{
-		Use:   "end-session",
-		Short: "End the current undo session",
+		Use:     "end-session",
+		GroupID: "undo",
+		Short:   "End the current undo session",
 		Long: `End the current undo session. This will stop tracking
 operations in the current session.
 
 Examples:
   ena end-session`,
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
-			undoManager.EndSession()
-			fmt.Println("🌸 Ended current undo session")
+			if err := undoManager.EndSession(); err != nil {
+				return reportError("❌ Error saving undo session: %v\n", err)
+			}
+			output.Printf("🌸 Ended current undo session\n")
+			return nil
 		},
 	}
 
 	// Clear history command
 	clearHistoryCmd := &cobra.Command{
-		Use:   "clear-undo-history [older-than]",
-		Short: "Clear old undo history",
+		Use:     "clear-undo-history [older-than]",
+		GroupID: "undo",
+		Short:   "Clear old undo history",
 		Long: `Clear undo history older than the specified duration.
 This will permanently remove old undo data and backup files.
 
@@ -223,8 +230,8 @@ Examples:
   ena clear-undo-history 7d           # Clear history older than 7 days
   ena clear-undo-history --all       # Clear all history`,
 		Args: cobra.MaximumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
 			clearAll, _ := cmd.Flags().GetBool("all")
 
@@ -235,8 +242,7 @@ Examples:
 				var err error
 				duration, err = time.ParseDuration(args[0])
 				if err != nil {
-					fmt.Printf("❌ Invalid duration: %v\n", err)
-					return
+					return reportErrorCode(exitUsage, "❌ Invalid duration: %v\n", err)
 				}
 			} else {
 				duration = 24 * time.Hour // Default to 24 hours
@@ -244,15 +250,15 @@ Examples:
 
 			err := undoManager.ClearHistory(duration)
 			if err != nil {
-				fmt.Printf("❌ Error clearing history: %v\n", err)
-				return
+				return reportError("❌ Error clearing history: %v\n", err)
 			}
 
 			if clearAll {
-				fmt.Println("✅ Cleared all undo history")
+				output.Printf("✅ Cleared all undo history\n")
 			} else {
-				fmt.Printf("✅ Cleared undo history older than %s\n", duration.String())
+				output.Printf("✅ Cleared undo history older than %s\n", duration.String())
 			}
+			return nil
 		},
 	}
 
@@ -260,8 +266,9 @@ Examples:
 
 	// Restore file command
 	restoreFileCmd := &cobra.Command{
-		Use:   "restore-file <file-path>",
-		Short: "Restore a file from undo history",
+		Use:     "restore-file <file-path>",
+		GroupID: "undo",
+		Short:   "Restore a file from undo history",
 		Long: `Restore a file from the most recent backup in undo history.
 This will find the most recent operation that affected the file
 and restore it from backup.
@@ -270,8 +277,8 @@ Examples:
   ena restore-file /path/to/file.txt
   ena restore-file /path/to/file.txt --dry-run`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			undoManager := getGlobalUndoManager()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undoManager := services.Undo
 
 			filePath := args[0]
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
@@ -291,22 +298,22 @@ Examples:
 			}
 
 			if latestOperation == nil {
-				fmt.Printf("❌ No undo history found for file: %s\n", filePath)
-				return
+				return reportError("❌ No undo history found for file: %s\n", filePath)
 			}
 
 			if dryRun {
-				fmt.Printf("🔍 Dry run: Would restore %s from operation %s\n", filePath, latestOperation.ID)
-				return
+				output.Printf("🔍 Dry run: Would restore %s from operation %s\n", filePath, latestOperation.ID)
+				return nil
 			}
 
 			err := undoManager.UndoOperation(latestOperation.ID)
 			if err != nil {
-				fmt.Printf("❌ Error restoring file: %v\n", err)
-				return
+				return reportError("❌ Error restoring file: %v\n", err)
 			}
 
-			fmt.Printf("✅ Successfully restored file: %s\n", filePath)
+			reportResult(latestOperation)
+			output.Printf("✅ Successfully restored file: %s\n", filePath)
+			return nil
 		},
 	}
 
@@ -325,30 +332,31 @@ Examples:
 // Helper functions
 
 func showSessionDetails(session *undo.UndoSession) {
-	fmt.Printf("🌸 Session Details: %s (╹◡╹)♡\n", session.Name)
+	reportResult(session)
+	output.Printf("🌸 Session Details: %s (╹◡╹)♡\n", session.Name)
 	fmt.Println("=====================================")
-	fmt.Printf("🆔 Session ID: %s\n", session.ID)
-	fmt.Printf("📝 Description: %s\n", session.Description)
-	fmt.Printf("📅 Created: %s\n", session.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("📊 Total Operations: %d\n", len(session.Operations))
-	fmt.Printf("✅ Undone: %t\n", session.Undone)
+	output.Printf("🆔 Session ID: %s\n", session.ID)
+	output.Printf("📝 Description: %s\n", session.Description)
+	output.Printf("📅 Created: %s\n", session.CreatedAt.Format("2006-01-02 15:04:05"))
+	output.Printf("📊 Total Operations: %d\n", len(session.Operations))
+	output.Printf("✅ Undone: %t\n", session.Undone)
 
 	if session.UndoneAt != nil {
-		fmt.Printf("🔄 Undone At: %s\n", session.UndoneAt.Format("2006-01-02 15:04:05"))
+		output.Printf("🔄 Undone At: %s\n", session.UndoneAt.Format("2006-01-02 15:04:05"))
 	}
 
-	fmt.Println("\n📋 Operations:")
+	output.Printf("\n📋 Operations:\n")
 	for i, op := range session.Operations {
 		fmt.Printf("   %d. %s %s\n", i+1, op.Type, op.OriginalPath)
-		fmt.Printf("      🆔 ID: %s\n", op.ID)
-		fmt.Printf("      📅 Time: %s\n", op.Timestamp.Format("2006-01-02 15:04:05"))
-		fmt.Printf("      📊 Size: %s\n", formatBytes(op.Size))
-		fmt.Printf("      ✅ Undone: %t\n", op.Undone)
+		output.Printf("      🆔 ID: %s\n", op.ID)
+		output.Printf("      📅 Time: %s\n", op.Timestamp.Format("2006-01-02 15:04:05"))
+		output.Printf("      📊 Size: %s\n", formatBytes(op.Size))
+		output.Printf("      ✅ Undone: %t\n", op.Undone)
 		if op.NewPath != "" {
-			fmt.Printf("      📁 New Path: %s\n", op.NewPath)
+			output.Printf("      📁 New Path: %s\n", op.NewPath)
 		}
 		if op.BackupPath != "" {
-			fmt.Printf("      💾 Backup: %s\n", op.BackupPath)
+			output.Printf("      💾 Backup: %s\n", op.BackupPath)
 		}
 		fmt.Println()
 	}
@@ -15,14 +15,16 @@ import (
 	"github.com/spf13/cobra"
 
 	"ena/internal/core"
+	"ena/internal/daemon"
 )
 
 // setupWatchCommands sets up file watching commands
 func setupWatchCommands(rootCmd *cobra.Command, assistant *core.Assistant) {
 	// Watch command
 	var watchCmd = &cobra.Command{
-		Use:   "watch <operation> [paths...]",
-		Short: "Real-time file system monitoring with live updates",
+		Use:     "watch <operation> [paths...]",
+		GroupID: "watch",
+		Short:   "Real-time file system monitoring with live updates",
 		Long: `Monitor file system changes in real-time with live updates and notifications:
   • Start monitoring specific paths or directories
   • Stop current file watching session
@@ -41,17 +43,45 @@ Examples:
   ena watch stop                     # Stop watching
   ena watch status                   # Show status
   ena watch demo                     # Run demonstration`,
-		Args: cobra.MinimumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		ValidArgs: []string{"start", "stop", "status", "demo", "debug", "advanced", "add", "remove", "metrics", "reload"},
+		Args:      cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Status and metrics have a typed form for scripts
+			if structured() && (args[0] == "status" || args[0] == "metrics") {
+				status, err := watcherStatus(assistant)
+				if err != nil {
+					return reportError("❌ Error: %v\n", err)
+				}
+				reportResult(status)
+				return nil
+			}
+
 			result, err := assistant.ProcessCommand("watch", args)
 			if err != nil {
-				cmd.PrintErrln("❌ Error:", err)
-				return
+				return reportError("❌ Error: %v\n", err)
 			}
 
 			cmd.Println(result)
+			return nil
 		},
 	}
 
 	rootCmd.AddCommand(watchCmd)
 }
+
+// watcherStatus reads the file watcher state from the daemon, or this process without one
+func watcherStatus(assistant *core.Assistant) (*daemon.WatcherReply, error) {
+	if client := daemon.Connect(); client != nil {
+		defer client.Close()
+		return client.WatcherStatus()
+	}
+
+	status := &daemon.WatcherReply{Paths: []string{}}
+	if fileWatcher := assistant.SystemHooks.FileWatcher; fileWatcher != nil {
+		status.Running = fileWatcher.IsRunning()
+		status.Paths = fileWatcher.GetWatchedPaths()
+		status.Stats = fileWatcher.GetStats()
+		status.Metrics = fileWatcher.GetMetrics()
+	}
+	return status, nil
+}
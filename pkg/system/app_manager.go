@@ -20,6 +20,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"ena/internal/audit"
 )
 
 // AppManager handles all application-related operations
@@ -78,8 +80,9 @@ func (am *AppManager) StartApplication(appName string) (string, error) {
 	cmd := exec.Command(parts[0], parts[1:]...)
 
 	err := cmd.Start()
+	audit.Record("app", "start", appName, "", err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to start application \"%s\": %v", appName, err)
+		return "", fmt.Errorf("Failed to start application \"%s\": %w", appName, err)
 	}
 
 	// Record application information
@@ -115,9 +118,11 @@ func (am *AppManager) StopApplication(appName string) (string, error) {
 		cmd := exec.Command("kill", fmt.Sprintf("%d", appInfo.PID))
 		err = cmd.Run()
 		if err != nil {
-			return "", fmt.Errorf("Failed to stop application \"%s\": %v", appName, err)
+			audit.Record("app", "stop", appSubject(appName, appInfo.PID), "", err)
+			return "", fmt.Errorf("Failed to stop application \"%s\": %w", appName, err)
 		}
 	}
+	audit.Record("app", "stop", appSubject(appName, appInfo.PID), "", nil)
 
 	// Remove application information
 	delete(am.RunningApps, appName)
@@ -132,7 +137,7 @@ func (am *AppManager) RestartApplication(appName string) (string, error) {
 	if _, exists := am.RunningApps[appName]; exists {
 		_, err := am.StopApplication(appName)
 		if err != nil {
-			return "", fmt.Errorf("Failed to stop application \"%s\": %v", appName, err)
+			return "", fmt.Errorf("Failed to stop application \"%s\": %w", appName, err)
 		}
 
 		// Wait a bit
@@ -142,7 +147,7 @@ func (am *AppManager) RestartApplication(appName string) (string, error) {
 	// Restart
 	_, err := am.StartApplication(appName)
 	if err != nil {
-		return "", fmt.Errorf("Failed to restart application \"%s\": %v", appName, err)
+		return "", fmt.Errorf("Failed to restart application \"%s\": %w", appName, err)
 	}
 
 	return fmt.Sprintf("Restarted application \"%s\"! ✨", appName), nil
@@ -430,9 +435,11 @@ func (am *AppManager) KillAllApplications() (string, error) {
 			cmd := exec.Command("kill", fmt.Sprintf("%d", appInfo.PID))
 			err = cmd.Run()
 			if err != nil {
+				audit.Record("app", "kill", appSubject(appName, appInfo.PID), "", err)
 				continue // Continue even with errors
 			}
 		}
+		audit.Record("app", "kill", appSubject(appName, appInfo.PID), "", nil)
 
 		// Remove application information
 		delete(am.RunningApps, appName)
@@ -446,6 +453,11 @@ func (am *AppManager) KillAllApplications() (string, error) {
 	return fmt.Sprintf("Stopped the following applications: %s 💤", strings.Join(stoppedApps, ", ")), nil
 }
 
+// appSubject names an application process in the audit log
+func appSubject(appName string, pid int) string {
+	return fmt.Sprintf("%s (pid %d)", appName, pid)
+}
+
 // GetSystemProcesses returns a list of system processes
 func (am *AppManager) GetSystemProcesses() (string, error) {
 	// Show system process list
@@ -464,7 +476,7 @@ func (am *AppManager) GetSystemProcesses() (string, error) {
 
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("Failed to get process list: %v", err)
+		return "", fmt.Errorf("Failed to get process list: %w", err)
 	}
 
 	return fmt.Sprintf("🖥️  System Process List:\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n%s", string(output)), nil
@@ -13,25 +13,35 @@
 package system
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"ena/internal/audit"
+	"ena/internal/input"
 	"ena/internal/progress"
+	"ena/internal/search"
+	"ena/internal/vfs"
 )
 
 // FileManager handles all file and directory operations
 type FileManager struct {
 	SafeMode bool // Safe mode - protecting important files
+	fs       vfs.FS
 }
 
 // NewFileManager creates a new file manager instance
 func NewFileManager() *FileManager {
+	return NewFileManagerWithFS(vfs.OS{})
+}
+
+// NewFileManagerWithFS creates a file manager that works on the given filesystem
+func NewFileManagerWithFS(fs vfs.FS) *FileManager {
 	// File management with care and attention ✨
 	return &FileManager{
 		SafeMode: true, // Enable safe mode by default
+		fs:       fs,
 	}
 }
 
@@ -39,13 +49,14 @@ func NewFileManager() *FileManager {
 func (fm *FileManager) CreateFile(path string) (string, error) {
 	// Create new file gently
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("Failed to create directory: %v", err)
+	if err := fm.fs.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("Failed to create directory: %w", err)
 	}
 
-	file, err := os.Create(path)
+	file, err := fm.fs.Create(path)
+	audit.RecordFile("file", "create", path, "", err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create file: %v", err)
+		return "", fmt.Errorf("Failed to create file: %w", err)
 	}
 	defer file.Close()
 
@@ -55,9 +66,9 @@ func (fm *FileManager) CreateFile(path string) (string, error) {
 // ReadFile reads and returns the contents of a file
 func (fm *FileManager) ReadFile(path string) (string, error) {
 	// Read file contents gently
-	content, err := os.ReadFile(path)
+	content, err := fm.fs.ReadFile(path)
 	if err != nil {
-		return "", fmt.Errorf("Failed to read file: %v", err)
+		return "", fmt.Errorf("Failed to read file: %w", err)
 	}
 
 	return fmt.Sprintf("File \"%s\" contents:\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n%s",
@@ -68,13 +79,14 @@ func (fm *FileManager) ReadFile(path string) (string, error) {
 func (fm *FileManager) WriteFile(path string, content string) (string, error) {
 	// Write to file gently
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("Failed to create directory: %v", err)
+	if err := fm.fs.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("Failed to create directory: %w", err)
 	}
 
-	err := os.WriteFile(path, []byte(content), 0644)
+	err := fm.fs.WriteFile(path, []byte(content), 0644)
+	audit.RecordFile("file", "write", path, "", err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to write to file: %v", err)
+		return "", fmt.Errorf("Failed to write to file: %w", err)
 	}
 
 	return fmt.Sprintf("Wrote to file \"%s\"! ✨", path), nil
@@ -83,9 +95,10 @@ func (fm *FileManager) WriteFile(path string, content string) (string, error) {
 // CopyFile copies a file from source to destination
 func (fm *FileManager) CopyFile(src, dest string) (string, error) {
 	// Copy file gently with progress bar
-	err := progress.CopyFileWithProgress(src, dest)
+	err := fm.copyWithProgress(src, dest)
+	audit.RecordFile("file", "copy", src, dest, err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to copy file: %v", err)
+		return "", fmt.Errorf("Failed to copy file: %w", err)
 	}
 
 	return fmt.Sprintf("Copied file \"%s\" to \"%s\"! ✨", src, dest), nil
@@ -95,47 +108,83 @@ func (fm *FileManager) CopyFile(src, dest string) (string, error) {
 func (fm *FileManager) MoveFile(src, dest string) (string, error) {
 	// Move file gently to new location
 	destDir := filepath.Dir(dest)
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return "", fmt.Errorf("移動先Failed to create directory: %v", err)
+	if err := fm.fs.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("移動先Failed to create directory: %w", err)
 	}
 
-	err := os.Rename(src, dest)
+	err := fm.fs.Rename(src, dest)
+	audit.RecordFile("file", "move", src, dest, err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to move file: %v", err)
+		return "", fmt.Errorf("Failed to move file: %w", err)
 	}
 
 	return fmt.Sprintf("Moved file \"%s\" to \"%s\"! ✨", src, dest), nil
 }
 
-// DeleteFile deletes a file with optional force flag
-func (fm *FileManager) DeleteFile(path string, force bool) (string, error) {
+// copyWithProgress copies one file through the file manager's filesystem
+func (fm *FileManager) copyWithProgress(src, dest string) error {
+	srcFile, err := fm.fs.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get source file info: %w", err)
+	}
+
+	destFile, err := fm.fs.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer destFile.Close()
+
+	return progress.CopyWithProgress(destFile, srcFile, srcInfo.Size(), src, dest)
+}
+
+// DeleteFile moves a file to the trash, or deletes it when permanent, with
+// optional force flag
+func (fm *FileManager) DeleteFile(path string, force, permanent bool) (string, error) {
 	// Delete file safely with care
 	if fm.SafeMode && !force {
 		// Request confirmation in safe mode
 		fmt.Printf("⚠️  Delete file \"%s\"? (y/N): ", path)
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
+		response, err := input.Answer()
+		if err != nil {
+			return "", err
+		}
 
 		if response != "y" && response != "yes" {
 			return "Deletion cancelled 😅", nil
 		}
 	}
 
-	err := os.Remove(path)
+	if !permanent {
+		err := vfs.Trash(fm.fs, path)
+		audit.RecordFile("file", "trash", path, "", err)
+		if err != nil {
+			return "", fmt.Errorf("Failed to move file to the trash: %w", err)
+		}
+		return fmt.Sprintf("Moved file \"%s\" to the trash 🗑️", path), nil
+	}
+
+	err := fm.fs.Remove(path)
+	audit.RecordFile("file", "delete", path, "", err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to delete file: %v", err)
+		return "", fmt.Errorf("Failed to delete file: %w", err)
 	}
 
-	return fmt.Sprintf("Deleted file \"%s\" 🗑️", path), nil
+	return fmt.Sprintf("Deleted file \"%s\" permanently 🗑️", path), nil
 }
 
 // CreateFolder creates a new directory
 func (fm *FileManager) CreateFolder(path string) (string, error) {
 	// Create new folder - organization is important
-	err := os.MkdirAll(path, 0755)
+	err := fm.fs.MkdirAll(path, 0755)
+	audit.RecordFile("file", "create-folder", path, "", err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to create folder: %v", err)
+		return "", fmt.Errorf("Failed to create folder: %w", err)
 	}
 
 	return fmt.Sprintf("Created folder \"%s\"! ✨", path), nil
@@ -144,9 +193,9 @@ func (fm *FileManager) CreateFolder(path string) (string, error) {
 // ListFolder lists the contents of a directory
 func (fm *FileManager) ListFolder(path string) (string, error) {
 	// Look inside folder gently - showing contents
-	entries, err := os.ReadDir(path)
+	entries, err := fm.fs.ReadDir(path)
 	if err != nil {
-		return "", fmt.Errorf("Failed to read folder: %v", err)
+		return "", fmt.Errorf("Failed to read folder: %w", err)
 	}
 
 	if len(entries) == 0 {
@@ -181,26 +230,38 @@ func (fm *FileManager) ListFolder(path string) (string, error) {
 	return strings.Join(result, "\n"), nil
 }
 
-// DeleteFolder deletes a directory and all its contents
-func (fm *FileManager) DeleteFolder(path string) (string, error) {
+// DeleteFolder moves a directory and all its contents to the trash, or
+// deletes them when permanent
+func (fm *FileManager) DeleteFolder(path string, permanent bool) (string, error) {
 	// Delete folder with caution - all contents will be removed
 	if fm.SafeMode {
 		fmt.Printf("⚠️  Delete folder \"%s\" and all its contents? (y/N): ", path)
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
+		response, err := input.Answer()
+		if err != nil {
+			return "", err
+		}
 
 		if response != "y" && response != "yes" {
 			return "Deletion cancelled 😅", nil
 		}
 	}
 
-	err := os.RemoveAll(path)
+	if !permanent {
+		err := vfs.Trash(fm.fs, path)
+		audit.RecordFile("file", "trash-folder", path, "", err)
+		if err != nil {
+			return "", fmt.Errorf("Failed to move folder to the trash: %w", err)
+		}
+		return fmt.Sprintf("Moved folder \"%s\" to the trash 🗑️", path), nil
+	}
+
+	err := fm.fs.RemoveAll(path)
+	audit.RecordFile("file", "delete-folder", path, "", err)
 	if err != nil {
-		return "", fmt.Errorf("Failed to delete folder: %v", err)
+		return "", fmt.Errorf("Failed to delete folder: %w", err)
 	}
 
-	return fmt.Sprintf("Deleted folder \"%s\" 🗑️", path), nil
+	return fmt.Sprintf("Deleted folder \"%s\" permanently 🗑️", path), nil
 }
 
 // SearchFiles searches for files matching a pattern in a directory
@@ -208,7 +269,7 @@ func (fm *FileManager) SearchFiles(pattern, directory string) (string, error) {
 	// Search for files - finding what you need ✨
 	var matches []string
 
-	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+	err := vfs.Walk(fm.fs, directory, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Continue even with errors
 		}
@@ -225,7 +286,7 @@ func (fm *FileManager) SearchFiles(pattern, directory string) (string, error) {
 	})
 
 	if err != nil {
-		return "", fmt.Errorf("Error occurred during search: %v", err)
+		return "", fmt.Errorf("Error occurred during search: %w", err)
 	}
 
 	if len(matches) == 0 {
@@ -244,12 +305,18 @@ func (fm *FileManager) SearchFiles(pattern, directory string) (string, error) {
 	return strings.Join(result, "\n"), nil
 }
 
+// SearchContent searches file contents under roots for a regular expression,
+// calling found for each matching file as soon as it has been scanned
+func (fm *FileManager) SearchContent(roots []string, options search.Options, found func(search.FileResult)) (*search.Result, error) {
+	return search.Content(fm.fs, roots, options, found)
+}
+
 // GetFileInfo returns detailed information about a file
 func (fm *FileManager) GetFileInfo(path string) (string, error) {
 	// Get detailed file information
-	info, err := os.Stat(path)
+	info, err := fm.fs.Stat(path)
 	if err != nil {
-		return "", fmt.Errorf("Failed to get file information: %v", err)
+		return "", fmt.Errorf("Failed to get file information: %w", err)
 	}
 
 	icon := "📄"
@@ -20,6 +20,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"ena/internal/fault"
 )
 
 // TerminalManager handles all terminal-related operations
@@ -90,7 +92,7 @@ func (tm *TerminalManager) OpenTerminal() (string, error) {
 
 	err := cmd.Start()
 	if err != nil {
-		return "", fmt.Errorf("Failed to start terminal: %v", err)
+		return "", fmt.Errorf("Failed to start terminal: %w", err)
 	}
 
 	tm.addToHistory(fmt.Sprintf("open-terminal (dir: %s)", tm.CurrentDir))
@@ -114,7 +116,7 @@ func (tm *TerminalManager) ExecuteCommand(command string) (string, error) {
 	// Sanitize command (safety first!)
 	sanitizedCommand := tm.sanitizeCommand(command)
 	if sanitizedCommand == "" {
-		return "", fmt.Errorf("Command is invalid or empty 😅")
+		return "", fault.Invalid("Command is invalid or empty 😅")
 	}
 
 	// Check for dangerous commands
@@ -155,7 +157,7 @@ func (tm *TerminalManager) ChangeDirectory(directory string) (string, error) {
 		if strings.HasPrefix(directory, "~") {
 			home, err := os.UserHomeDir()
 			if err != nil {
-				return "", fmt.Errorf("Failed to get home directory: %v", err)
+				return "", fmt.Errorf("Failed to get home directory: %w", err)
 			}
 			newPath = strings.Replace(directory, "~", home, 1)
 		}
@@ -172,7 +174,7 @@ func (tm *TerminalManager) ChangeDirectory(directory string) (string, error) {
 	// Actually change directory
 	err := os.Chdir(newPath)
 	if err != nil {
-		return "", fmt.Errorf("Failed to change directory: %v", err)
+		return "", fmt.Errorf("Failed to change directory: %w", err)
 	}
 
 	tm.CurrentDir = newPath